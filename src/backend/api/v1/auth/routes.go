@@ -0,0 +1,61 @@
+// Package auth implements API routes for authenticating BlackPoint clients
+package auth
+
+import (
+    "net/http"
+
+    oauth "github.com/blackpoint/internal/auth"
+    "github.com/blackpoint/pkg/common/logging"
+)
+
+// authAPIPrefix matches the device flow paths the CLI calls
+const authAPIPrefix = "/api/v1/auth"
+
+// jwksPath is where the JWKS document of the signing keys is served
+const jwksPath = "/.well-known/jwks.json"
+
+// NewAuthRouter creates a router for the authentication endpoints. The device
+// authorization endpoints carry no authentication middleware since they are
+// how a client obtains its first token; the OAuth manager rate limits them.
+func NewAuthRouter(manager *oauth.OAuthManager) http.Handler {
+    mux := http.NewServeMux()
+
+    mux.Handle(authAPIPrefix+"/device/code",
+        postOnly(http.HandlerFunc(manager.DeviceCodeHandler)))
+
+    // Long-polls until the device code completes, so no request timeout applies
+    mux.Handle(authAPIPrefix+"/device/token",
+        postOnly(http.HandlerFunc(manager.DeviceTokenHandler)))
+
+    // Public keys for services that validate tokens themselves
+    mux.Handle(jwksPath, getOnly(http.HandlerFunc(oauth.JWKSHandler)))
+
+    logging.Info("Auth router initialized",
+        logging.Field("routes", []string{"/device/code", "/device/token", jwksPath}))
+
+    return mux
+}
+
+// postOnly rejects requests that are not form POSTs
+func postOnly(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != http.MethodPost {
+            w.Header().Set("Allow", http.MethodPost)
+            http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+            return
+        }
+        next.ServeHTTP(w, r)
+    })
+}
+
+// getOnly rejects requests that are not GETs
+func getOnly(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != http.MethodGet && r.Method != http.MethodHead {
+            w.Header().Set("Allow", http.MethodGet)
+            http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+            return
+        }
+        next.ServeHTTP(w, r)
+    })
+}
@@ -20,15 +20,15 @@ const (
     validationTimeout       = 500 * time.Millisecond
 )
 
-// Error codes for validation failures
+// Error codes for validation failures, mapped onto the common error catalog
 var validationErrorCodes = map[string]string{
-    "INVALID_REQUEST":      "BR001",
-    "SCHEMA_VIOLATION":     "BR002",
-    "SIZE_EXCEEDED":        "BR003",
-    "INVALID_CLIENT":       "BR004",
-    "INVALID_PLATFORM":     "BR005",
-    "RATE_LIMIT_EXCEEDED":  "BR006",
-    "SECURITY_VIOLATION":   "BR007",
+    "INVALID_REQUEST":      "E3001",
+    "SCHEMA_VIOLATION":     "E3001",
+    "SIZE_EXCEEDED":        "E3001",
+    "INVALID_CLIENT":       "E1001",
+    "INVALID_PLATFORM":     "E2001",
+    "RATE_LIMIT_EXCEEDED":  "E4029",
+    "SECURITY_VIOLATION":   "E1004",
 }
 
 // Validation metrics
@@ -21,13 +21,13 @@ import (
 // Maximum request size for Gold tier API endpoints (10MB)
 const maxRequestSize int64 = 10485760
 
-// Validation error codes with descriptions
+// Validation error codes mapped onto the common error catalog
 var validationErrorCodes = map[string]string{
-    "invalid_request": "GOLD-VAL-001",
-    "schema_violation": "GOLD-VAL-002",
-    "invalid_correlation": "GOLD-VAL-003",
-    "security_pattern_violation": "GOLD-VAL-004",
-    "threat_intelligence_violation": "GOLD-VAL-005",
+    "invalid_request": "E3001",
+    "schema_violation": "E3001",
+    "invalid_correlation": "E3001",
+    "security_pattern_violation": "E1004",
+    "threat_intelligence_violation": "E3001",
 }
 
 // Security pattern validation for common attack vectors
@@ -85,10 +85,42 @@ func HandleDeployIntegration(c *gin.Context) {
     c.JSON(http.StatusCreated, gin.H{
         "integration_id": integrationID,
         "status": "deployed",
+        "warnings": mgr.ValidationWarnings(ctx, &integrationCfg),
         "timestamp": time.Now().UTC(),
     })
 }
 
+// HandleProbeIntegration handles POST requests to check that an integration's
+// platform is reachable with its credentials, without deploying it
+func HandleProbeIntegration(c *gin.Context) {
+    timer := prometheus.NewTimer(requestDuration.WithLabelValues("/probe", "processing"))
+    defer timer.ObserveDuration()
+
+    // Start tracing span
+    span, ctx := opentracing.StartSpanFromContext(c.Request.Context(), "HandleProbeIntegration")
+    defer span.Finish()
+
+    // Bind and validate request payload
+    var integrationCfg config.IntegrationConfig
+    if err := c.ShouldBindJSON(&integrationCfg); err != nil {
+        requestTotal.WithLabelValues("/probe", "error").Inc()
+        c.JSON(http.StatusBadRequest, errors.NewError("E2001", "invalid request payload", map[string]interface{}{
+            "error": err.Error(),
+        }))
+        return
+    }
+
+    result, err := manager.GetManager().ProbeIntegration(ctx, &integrationCfg)
+    if err != nil {
+        requestTotal.WithLabelValues("/probe", "error").Inc()
+        c.JSON(http.StatusBadRequest, err)
+        return
+    }
+
+    requestTotal.WithLabelValues("/probe", result.AuthStatus).Inc()
+    c.JSON(http.StatusOK, result)
+}
+
 // HandleStopIntegration handles DELETE requests to stop and remove integrations
 func HandleStopIntegration(c *gin.Context) {
     timer := prometheus.NewTimer(requestDuration.WithLabelValues("/stop", "processing"))
@@ -196,4 +228,154 @@ func HandleListIntegrations(c *gin.Context) {
         "page_size": pageSize,
         "timestamp": time.Now().UTC(),
     })
-}
\ No newline at end of file
+}
+// HandleListVersions handles GET requests for the blue-green versions of an integration
+func HandleListVersions(c *gin.Context) {
+    timer := prometheus.NewTimer(requestDuration.WithLabelValues("/versions", "processing"))
+    defer timer.ObserveDuration()
+
+    span, ctx := opentracing.StartSpanFromContext(c.Request.Context(), "HandleListVersions")
+    defer span.Finish()
+
+    ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+    defer cancel()
+
+    versions, err := manager.GetManager().ListVersions(ctx, c.Param("integration_id"))
+    if err != nil {
+        requestTotal.WithLabelValues("/versions", "error").Inc()
+        c.JSON(http.StatusNotFound, err)
+        return
+    }
+
+    requestTotal.WithLabelValues("/versions", "success").Inc()
+    c.JSON(http.StatusOK, versions)
+}
+
+// HandleDeployVersion handles POST requests staging a configuration as the
+// inactive version of an integration, ready for cutover
+func HandleDeployVersion(c *gin.Context) {
+    timer := prometheus.NewTimer(requestDuration.WithLabelValues("/versions/deploy", "processing"))
+    defer timer.ObserveDuration()
+
+    span, ctx := opentracing.StartSpanFromContext(c.Request.Context(), "HandleDeployVersion")
+    defer span.Finish()
+
+    var integrationCfg config.IntegrationConfig
+    if err := c.ShouldBindJSON(&integrationCfg); err != nil {
+        requestTotal.WithLabelValues("/versions/deploy", "error").Inc()
+        c.JSON(http.StatusBadRequest, errors.NewError("E2001", "invalid request payload", map[string]interface{}{
+            "error": err.Error(),
+        }))
+        return
+    }
+
+    if err := integrationCfg.Validate(); err != nil {
+        requestTotal.WithLabelValues("/versions/deploy", "error").Inc()
+        c.JSON(http.StatusBadRequest, err)
+        return
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+    defer cancel()
+
+    integrationID := c.Param("integration_id")
+    version := c.Param("version")
+    if err := manager.GetManager().DeployVersion(ctx, integrationID, version, &integrationCfg); err != nil {
+        requestTotal.WithLabelValues("/versions/deploy", "error").Inc()
+        c.JSON(http.StatusConflict, err)
+        return
+    }
+
+    requestTotal.WithLabelValues("/versions/deploy", "success").Inc()
+    c.JSON(http.StatusCreated, gin.H{
+        "integration_id": integrationID,
+        "version": version,
+        "status": "staged",
+        "timestamp": time.Now().UTC(),
+    })
+}
+
+// HandleGetVersionStatus handles GET requests for the status of one version
+func HandleGetVersionStatus(c *gin.Context) {
+    timer := prometheus.NewTimer(requestDuration.WithLabelValues("/versions/status", "processing"))
+    defer timer.ObserveDuration()
+
+    span, ctx := opentracing.StartSpanFromContext(c.Request.Context(), "HandleGetVersionStatus")
+    defer span.Finish()
+
+    ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+    defer cancel()
+
+    status, err := manager.GetManager().GetVersionStatus(ctx, c.Param("integration_id"), c.Param("version"))
+    if err != nil {
+        requestTotal.WithLabelValues("/versions/status", "error").Inc()
+        c.JSON(http.StatusNotFound, err)
+        return
+    }
+
+    requestTotal.WithLabelValues("/versions/status", "success").Inc()
+    c.JSON(http.StatusOK, status)
+}
+
+// HandleRemoveVersion handles DELETE requests discarding the inactive version
+func HandleRemoveVersion(c *gin.Context) {
+    timer := prometheus.NewTimer(requestDuration.WithLabelValues("/versions/remove", "processing"))
+    defer timer.ObserveDuration()
+
+    span, ctx := opentracing.StartSpanFromContext(c.Request.Context(), "HandleRemoveVersion")
+    defer span.Finish()
+
+    integrationID := c.Param("integration_id")
+    version := c.Param("version")
+    if err := manager.GetManager().RemoveVersion(ctx, integrationID, version); err != nil {
+        requestTotal.WithLabelValues("/versions/remove", "error").Inc()
+        c.JSON(http.StatusConflict, err)
+        return
+    }
+
+    requestTotal.WithLabelValues("/versions/remove", "success").Inc()
+    c.JSON(http.StatusOK, gin.H{
+        "integration_id": integrationID,
+        "version": version,
+        "status": "removed",
+        "timestamp": time.Now().UTC(),
+    })
+}
+
+// HandleCutover handles POST requests moving collection to the staged version
+func HandleCutover(c *gin.Context) {
+    timer := prometheus.NewTimer(requestDuration.WithLabelValues("/cutover", "processing"))
+    defer timer.ObserveDuration()
+
+    span, ctx := opentracing.StartSpanFromContext(c.Request.Context(), "HandleCutover")
+    defer span.Finish()
+
+    var request struct {
+        From string `json:"from"`
+        To   string `json:"to" binding:"required,oneof=blue green"`
+    }
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestTotal.WithLabelValues("/cutover", "error").Inc()
+        c.JSON(http.StatusBadRequest, errors.NewError("E2001", "invalid request payload", map[string]interface{}{
+            "error": err.Error(),
+        }))
+        return
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+    defer cancel()
+
+    integrationID := c.Param("integration_id")
+    if err := manager.GetManager().Cutover(ctx, integrationID, request.To); err != nil {
+        requestTotal.WithLabelValues("/cutover", "error").Inc()
+        c.JSON(http.StatusConflict, err)
+        return
+    }
+
+    requestTotal.WithLabelValues("/cutover", "success").Inc()
+    c.JSON(http.StatusOK, gin.H{
+        "integration_id": integrationID,
+        "active": request.To,
+        "timestamp": time.Now().UTC(),
+    })
+}
@@ -2,6 +2,7 @@
 package integrations
 
 import (
+    "context"
     "net/http"
     "time"
 
@@ -11,6 +12,7 @@ import (
     "github.com/prometheus/client_golang/prometheus" // v1.17.0
 
     "./handlers"
+    "../../internal/integration/manager"
 )
 
 // Prometheus metrics for route monitoring
@@ -39,6 +41,14 @@ func init() {
     prometheus.MustRegister(routeRequests)
 }
 
+// EnableConfigSync subscribes this API replica to the integration
+// configuration changes other replicas announce, so no replica serves
+// validation results cached before a change. Call it at startup alongside
+// SetupIntegrationRoutes with the shared Redis client.
+func EnableConfigSync(ctx context.Context, broadcaster manager.ConfigBroadcaster) error {
+    return manager.GetManager().EnableCacheInvalidation(ctx, broadcaster)
+}
+
 // SetupIntegrationRoutes configures all integration API routes with enhanced security and monitoring
 func SetupIntegrationRoutes(router *gin.Engine) {
     // Configure security headers middleware
@@ -75,6 +85,12 @@ func SetupIntegrationRoutes(router *gin.Engine) {
             handlers.HandleDeployIntegration,
         )
 
+        // Probe platform connectivity without deploying
+        integrations.POST("/probe", metricMiddleware("/integrations/probe", "POST"),
+            validateIntegrationConfig(),
+            handlers.HandleProbeIntegration,
+        )
+
         // Stop integration
         integrations.DELETE("/:integration_id", metricMiddleware("/integrations/:id", "DELETE"),
             validateIntegrationID(),
@@ -92,6 +108,32 @@ func SetupIntegrationRoutes(router *gin.Engine) {
             validatePaginationParams(),
             handlers.HandleListIntegrations,
         )
+
+        // Blue-green versions: stage the inactive colour, check its health,
+        // cut collection over to it and discard the replaced one
+        integrations.GET("/:integration_id/versions", metricMiddleware("/integrations/:id/versions", "GET"),
+            validateIntegrationID(),
+            handlers.HandleListVersions,
+        )
+        integrations.POST("/:integration_id/versions/:version", metricMiddleware("/integrations/:id/versions/:version", "POST"),
+            validateIntegrationID(),
+            validateVersion(),
+            handlers.HandleDeployVersion,
+        )
+        integrations.GET("/:integration_id/versions/:version", metricMiddleware("/integrations/:id/versions/:version", "GET"),
+            validateIntegrationID(),
+            validateVersion(),
+            handlers.HandleGetVersionStatus,
+        )
+        integrations.DELETE("/:integration_id/versions/:version", metricMiddleware("/integrations/:id/versions/:version", "DELETE"),
+            validateIntegrationID(),
+            validateVersion(),
+            handlers.HandleRemoveVersion,
+        )
+        integrations.POST("/:integration_id/cutover", metricMiddleware("/integrations/:id/cutover", "POST"),
+            validateIntegrationID(),
+            handlers.HandleCutover,
+        )
     }
 }
 
@@ -150,6 +192,21 @@ func validateIntegrationID() gin.HandlerFunc {
     }
 }
 
+// validateVersion validates the blue-green version parameter
+func validateVersion() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        switch c.Param("version") {
+        case "blue", "green":
+            c.Next()
+        default:
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error": "version must be blue or green",
+            })
+            c.Abort()
+        }
+    }
+}
+
 // validatePaginationParams validates pagination query parameters
 func validatePaginationParams() gin.HandlerFunc {
     return func(c *gin.Context) {
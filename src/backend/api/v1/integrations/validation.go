@@ -21,7 +21,7 @@ var (
     validate = validator.New()
 
     // Error code for validation failures
-    validationErrorCode = "VALIDATION_ERROR"
+    validationErrorCode = "E3001"
 
     // Validation metrics
     validationMetrics = prometheus.NewCounterVec(
@@ -3,36 +3,63 @@ package main
 
 import (
     "context"
+    "encoding/json"
     "flag"
+    "fmt"
     "log"
+    "net/http"
     "os"
     "os/signal"
-    "sync"
     "syscall"
     "time"
 
+    awsconfig "github.com/aws/aws-sdk-go-v2/config"
+    "github.com/aws/aws-sdk-go-v2/service/s3"
+    "github.com/confluentinc/confluent-kafka-go/kafka"
+    "github.com/go-redis/redis/v8" // v8.11.5
+    "gopkg.in/yaml.v3" // v3.0.1
+
+    "github.com/blackpoint/internal/analyzer"
     "github.com/blackpoint/internal/analyzer/intelligence"
     "github.com/blackpoint/internal/analyzer/detection"
     "github.com/blackpoint/internal/analyzer/correlation"
+    "github.com/blackpoint/internal/lifecycle"
     "github.com/blackpoint/internal/metrics"
+    "github.com/blackpoint/internal/storage"
+    "github.com/blackpoint/internal/streaming"
+    "github.com/blackpoint/pkg/common"
     "github.com/blackpoint/pkg/common/errors"
     "github.com/blackpoint/pkg/common/logging"
+    "github.com/blackpoint/pkg/gold"
 )
 
 // Command line flags
 var (
     configPath = flag.String("config", "config/analyzer.yaml", "Path to analyzer configuration file")
     debugMode = flag.Bool("debug", false, "Enable debug logging")
+    replayAddr = flag.String("replay-addr", ":8081", "The address to accept notification replay requests on")
 )
 
 // Global constants
 const (
-    shutdownTimeout = 30 * time.Second
     workerPoolSize = 10
     maxBatchSize = 1000
     processingTimeout = 25 * time.Second
+    standbyPollInterval = time.Second
+    ruleWatchInterval = 30 * time.Second
+    defaultInputTopic = "silver-events"
+    defaultOutputTopic = "gold-alerts"
+    defaultConsumerGroup = "blackpoint-analyzer"
 )
 
+// analysisBatch is a consumed Silver batch handed to an analysis worker. The
+// worker reports the outcome on done, so the consumer commits only batches
+// that were analyzed and published.
+type analysisBatch struct {
+    messages []*streaming.Message
+    done     chan error
+}
+
 func main() {
     // Parse command line flags
     flag.Parse()
@@ -72,6 +99,12 @@ func main() {
         DataSensitivity: "HIGH",
     }
 
+    // Severity bands and rule overrides are tuned per deployment
+    if err := setupSeverityMapping(config); err != nil {
+        logging.Error("Failed to configure severity mapping", err)
+        os.Exit(1)
+    }
+
     // Initialize intelligence engine
     engine, err := setupIntelligenceEngine(ctx, config)
     if err != nil {
@@ -86,27 +119,114 @@ func main() {
         os.Exit(1)
     }
 
-    // Initialize worker pool
-    var wg sync.WaitGroup
-    workers := make(chan struct{}, workerPoolSize)
-    shutdown := make(chan struct{})
-
-    // Start processing loop
-    for i := 0; i < workerPoolSize; i++ {
-        wg.Add(1)
-        go func() {
-            defer wg.Done()
-            for {
-                select {
-                case <-shutdown:
-                    return
-                case workers <- struct{}{}:
-                    processEvents(ctx, engine, correlator)
-                    <-workers
-                }
-            }
-        }()
+    // Detection rules reload atomically on SIGHUP or when the rule file changes
+    if rulesPath, ok := config["detection_rules_path"].(string); ok && rulesPath != "" {
+        if err := detection.Rules().LoadFile(rulesPath); err != nil {
+            logging.Error("Failed to load detection rules", err)
+            os.Exit(1)
+        }
+        go detection.Rules().WatchFile(ctx, rulesPath, ruleWatchInterval)
+        go reloadRulesOnSignal(ctx, rulesPath)
+    }
+
+    // Silver events are consumed from the normalizer's output and alerts are
+    // routed to their client's Gold topic, each continuing its event's trace
+    newSilverConsumer, alertRouter, err := setupStreaming(config)
+    if err != nil {
+        logging.Error("Failed to initialize streaming", err)
+        os.Exit(1)
     }
+    pipeline, err := analyzer.NewRoutedPipeline(alertRouter)
+    if err != nil {
+        logging.Error("Failed to create analysis pipeline", err)
+        os.Exit(1)
+    }
+    dedup, err := setupDeduplication(config)
+    if err != nil {
+        logging.Error("Failed to initialize alert deduplication", err)
+        os.Exit(1)
+    }
+    pipeline.SetDeduplicator(dedup)
+
+    // Routed alerts are recorded so those missed during a sink outage can be
+    // replayed once it recovers
+    historyClient, err := setupAlertHistory(config, alertRouter)
+    if err != nil {
+        logging.Error("Failed to initialize alert history", err)
+        os.Exit(1)
+    }
+
+    // Rules can be evaluated against the Silver events archived in S3
+    if err := setupHistoricalArchive(ctx, config); err != nil {
+        logging.Error("Failed to initialize historical archive", err)
+        os.Exit(1)
+    }
+
+    batches := make(chan analysisBatch)
+    silverConsumer, err := streaming.NewLeaderConsumer(newSilverConsumer, func(ctx context.Context, messages []*streaming.Message) error {
+        batch := analysisBatch{messages: messages, done: make(chan error, 1)}
+        select {
+        case batches <- batch:
+        case <-ctx.Done():
+            return ctx.Err()
+        }
+        return <-batch.done
+    })
+    if err != nil {
+        logging.Error("Failed to create Silver consumer", err)
+        os.Exit(1)
+    }
+
+    // Only the elected replica correlates; standbys stay initialized for fast
+    // failover, and analysis in flight is cancelled the moment leadership is
+    // lost. Standbys do not join the consumer group, so every partition is
+    // assigned to the leader; it leaves the group again on demotion.
+    leadership := &lifecycle.LeaderScope{}
+    elector, err := setupLeaderElection(config, leadership, silverConsumer)
+    if err != nil {
+        logging.Error("Failed to initialize leader election", err)
+        os.Exit(1)
+    }
+
+    // Standbys stay idle until elected; the pool tracks in-flight analysis
+    // so shutdown waits for exactly the work that is running
+    poolConfig := lifecycle.WorkerPoolConfig{
+        Workers:         workerPoolSize,
+        StandbyInterval: standbyPollInterval,
+    }
+    if elector != nil {
+        poolConfig.Standby = func() bool { return !elector.IsLeader() }
+    }
+    workers, err := lifecycle.NewWorkerPool(poolConfig, func(ctx context.Context) {
+        processEvents(ctx, engine, correlator, pipeline, batches, leadership)
+    })
+    if err != nil {
+        logging.Error("Failed to create analysis worker pool", err)
+        os.Exit(1)
+    }
+    workers.Start(ctx)
+
+    if elector != nil {
+        elector.Start(ctx)
+    } else {
+        // Without leader election this replica always leads
+        leadership.Elected(ctx)
+        if err := silverConsumer.Elected(ctx); err != nil {
+            logging.Error("Failed to start Silver consumer", err)
+            os.Exit(1)
+        }
+    }
+
+    // Replays run on the leader, whose delivery log holds the deliveries to skip
+    isLeader := func() bool { return elector == nil || elector.IsLeader() }
+    replayServer := &http.Server{Addr: *replayAddr, Handler: common.AuthMiddleware(replayHandler(alertRouter, isLeader))}
+    go func() {
+        if err := replayServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+            logging.Error("Replay server failed", err,
+                logging.Field("address", *replayAddr),
+            )
+        }
+    }()
 
     // Set up signal handling for graceful shutdown
     sigChan := make(chan os.Signal, 1)
@@ -116,22 +236,41 @@ func main() {
     <-sigChan
     logging.Info("Initiating graceful shutdown")
 
-    // Initiate shutdown
-    close(shutdown)
-    shutdownCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+    // Handle graceful shutdown, giving every stage its full budget
+    coordinator := newShutdownCoordinator(silverConsumer, alertRouter, workers, elector)
+    coordinator.Register(lifecycle.StageIntake, "replay_server", replayServer.Shutdown)
+    if historyClient != nil {
+        coordinator.Register(lifecycle.StageStorage, "alert_history", lifecycle.StopperFunc(historyClient.Close))
+    }
+    shutdownCtx, cancel := context.WithTimeout(ctx, coordinator.Timeout())
     defer cancel()
-
-    // Handle graceful shutdown
-    if err := handleShutdown(shutdownCtx); err != nil {
+    if err := coordinator.Shutdown(shutdownCtx); err != nil {
         logging.Error("Error during shutdown", err)
         os.Exit(1)
     }
 
-    // Wait for workers to complete
-    wg.Wait()
     logging.Info("Analyzer service shutdown complete")
 }
 
+// reloadRulesOnSignal reloads the detection rule set on SIGHUP. A rejected
+// set is logged and the active rules stay in place.
+func reloadRulesOnSignal(ctx context.Context, rulesPath string) {
+    hupChan := make(chan os.Signal, 1)
+    signal.Notify(hupChan, syscall.SIGHUP)
+    defer signal.Stop(hupChan)
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-hupChan:
+            if err := detection.Rules().LoadFile(rulesPath); err != nil {
+                logging.Error("Rejected detection rule set, keeping active rules", err)
+            }
+        }
+    }
+}
+
 // setupIntelligenceEngine initializes and configures the intelligence generation engine
 func setupIntelligenceEngine(ctx context.Context, config map[string]interface{}) (*intelligence.IntelligenceEngine, error) {
     // Create intelligence engine with security context
@@ -190,37 +329,428 @@ func setupEventCorrelator(ctx context.Context, config map[string]interface{}) (*
     return correlator, nil
 }
 
-// handleShutdown performs graceful shutdown of the analyzer service
-func handleShutdown(ctx context.Context) error {
-    // Stop accepting new events
-    logging.Info("Stopping event processing")
+// setupSeverityMapping installs the alert severity mapper from the
+// severity_mapping section, leaving event severities unchanged when absent
+func setupSeverityMapping(config map[string]interface{}) error {
+    section, ok := config["severity_mapping"]
+    if !ok {
+        return nil
+    }
 
-    // Wait for in-progress analysis to complete
-    select {
-    case <-ctx.Done():
-        return errors.NewError("E4001", "shutdown timeout exceeded", nil)
-    case <-time.After(5 * time.Second):
-        logging.Info("Event processing stopped")
+    data, err := yaml.Marshal(section)
+    if err != nil {
+        return errors.WrapError(err, "failed to read severity mapping", nil)
+    }
+    var mappingConfig gold.SeverityMappingConfig
+    if err := yaml.Unmarshal(data, &mappingConfig); err != nil {
+        return errors.WrapError(err, "failed to parse severity mapping", nil)
     }
 
-    // Flush metrics
-    logging.Info("Flushing metrics")
-    if err := metrics.Flush(); err != nil {
-        logging.Error("Error flushing metrics", err)
+    mapper, err := gold.NewSeverityMapper(mappingConfig)
+    if err != nil {
+        return err
     }
+    gold.SetSeverityMapper(mapper)
+    return nil
+}
 
-    // Close resources
-    logging.Info("Closing resources")
+// dedupSection is the deduplication section of the analyzer configuration
+type dedupSection struct {
+    WindowMinutes   int      `yaml:"window_minutes"`
+    SignatureFields []string `yaml:"signature_fields"`
+}
 
-    return nil
+// setupDeduplication creates the alert deduplicator from the deduplication
+// section, using the package defaults for any setting left unset
+func setupDeduplication(config map[string]interface{}) (*gold.AlertDeduplicator, error) {
+    var section dedupSection
+    if raw, ok := config["deduplication"]; ok {
+        data, err := yaml.Marshal(raw)
+        if err != nil {
+            return nil, errors.WrapError(err, "failed to read deduplication config", nil)
+        }
+        if err := yaml.Unmarshal(data, &section); err != nil {
+            return nil, errors.WrapError(err, "failed to parse deduplication config", nil)
+        }
+    }
+
+    return gold.NewAlertDeduplicator(gold.DedupConfig{
+        Window:          time.Duration(section.WindowMinutes) * time.Minute,
+        SignatureFields: section.SignatureFields,
+    })
 }
 
-// processEvents handles the main event processing loop
-func processEvents(ctx context.Context, engine *intelligence.IntelligenceEngine, correlator *correlation.EventCorrelator) {
-    // Implementation would include:
-    // 1. Fetch events from queue/stream
-    // 2. Correlate events
-    // 3. Detect threats
-    // 4. Generate intelligence
-    // 5. Update metrics
+// alertHistorySection is the alert_history section of the analyzer configuration
+type alertHistorySection struct {
+    RedisAddress  string        `yaml:"redis_address"`
+    RedisPassword string        `yaml:"redis_password"`
+    Prefix        string        `yaml:"prefix"`
+    Retention     time.Duration `yaml:"retention"`
+}
+
+// setupAlertHistory records the alerts router handles in the Redis named by
+// the alert_history section, so ReplayNotifications can redeliver them. It
+// returns the Redis client, or nil when no history is configured.
+func setupAlertHistory(config map[string]interface{}, router *analyzer.AlertRouter) (*redis.Client, error) {
+    raw, ok := config["alert_history"]
+    if !ok {
+        return nil, nil
+    }
+
+    data, err := yaml.Marshal(raw)
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to read alert history config", nil)
+    }
+    var section alertHistorySection
+    if err := yaml.Unmarshal(data, &section); err != nil {
+        return nil, errors.WrapError(err, "failed to parse alert history config", nil)
+    }
+    if section.RedisAddress == "" {
+        return nil, errors.NewError("E2001", "alert history requires a redis_address", nil)
+    }
+
+    client := redis.NewClient(&redis.Options{
+        Addr:     section.RedisAddress,
+        Password: section.RedisPassword,
+    })
+    router.SetAlertHistory(analyzer.NewRedisAlertHistory(client, section.Prefix, section.Retention))
+    return client, nil
+}
+
+// historicalArchiveSection is the historical_archive section of the analyzer configuration
+type historicalArchiveSection struct {
+    Region string `yaml:"region"`
+    Bucket string `yaml:"bucket"`
+    Tier   string `yaml:"tier"`
+}
+
+// setupHistoricalArchive points historical rule evaluation at the S3 bucket
+// named by the historical_archive section. Historical evaluation stays
+// unavailable when the section is absent.
+func setupHistoricalArchive(ctx context.Context, config map[string]interface{}) error {
+    raw, ok := config["historical_archive"]
+    if !ok {
+        return nil
+    }
+
+    data, err := yaml.Marshal(raw)
+    if err != nil {
+        return errors.WrapError(err, "failed to read historical archive config", nil)
+    }
+    var section historicalArchiveSection
+    if err := yaml.Unmarshal(data, &section); err != nil {
+        return errors.WrapError(err, "failed to parse historical archive config", nil)
+    }
+
+    awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(section.Region))
+    if err != nil {
+        return errors.WrapError(err, "failed to load AWS configuration", nil)
+    }
+    client := storage.NewS3ClientWithAPI(&storage.S3Config{
+        Region: section.Region,
+    }, s3.NewFromConfig(awsCfg))
+
+    return analyzer.SetHistoricalArchive(client, section.Bucket, section.Tier)
+}
+
+// replayHandler redelivers the alerts created in [from, to) that did not
+// reach their destination. from and to are RFC 3339 query parameters; an
+// optional destination limits the replay to that destination's alerts.
+// Standbys refuse replays so deliveries are only skipped against the leader's
+// delivery log.
+func replayHandler(router *analyzer.AlertRouter, isLeader func() bool) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != http.MethodPost {
+            w.Header().Set("Allow", http.MethodPost)
+            http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+            return
+        }
+        if !isLeader() {
+            http.Error(w, "replica is not the leader", http.StatusConflict)
+            return
+        }
+
+        query := r.URL.Query()
+        from, err := time.Parse(time.RFC3339, query.Get("from"))
+        if err != nil {
+            http.Error(w, "invalid from time", http.StatusBadRequest)
+            return
+        }
+        to, err := time.Parse(time.RFC3339, query.Get("to"))
+        if err != nil {
+            http.Error(w, "invalid to time", http.StatusBadRequest)
+            return
+        }
+
+        result, err := router.ReplayNotifications(r.Context(), from, to, query.Get("destination"))
+        switch {
+        case err == nil:
+        case errors.IsErrorCode(err, "E3001", ""):
+            http.Error(w, err.Error(), http.StatusBadRequest)
+            return
+        case result == nil:
+            http.Error(w, err.Error(), http.StatusInternalServerError)
+            return
+        default:
+            // A cancelled replay still reports what it redelivered
+            logging.Error("Notification replay interrupted", err)
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(result)
+    })
+}
+
+// newShutdownCoordinator orders analyzer shutdown: stop taking work, wait for
+// in-progress analysis, hand off leadership, then flush metrics
+func newShutdownCoordinator(consumer *streaming.LeaderConsumer, router *analyzer.AlertRouter, workers *lifecycle.WorkerPool, elector *lifecycle.LeaderElector) *lifecycle.Coordinator {
+    coordinator := lifecycle.NewCoordinator("analyzer")
+
+    // The consumer stops first so a batch handed to a worker is finished and
+    // committed rather than abandoned
+    coordinator.Register(lifecycle.StageIntake, "silver_consumer", lifecycle.StopperFunc(consumer.Stop))
+    coordinator.Register(lifecycle.StageIntake, "event_intake", func(ctx context.Context) error {
+        workers.Stop()
+        return nil
+    })
+    coordinator.Register(lifecycle.StageProcessing, "analysis_workers", workers.Wait)
+    coordinator.Register(lifecycle.StageProducer, "alert_producers", lifecycle.StopperFunc(router.Close))
+    if elector != nil {
+        // Released only after workers drain so two replicas never correlate at once
+        coordinator.Register(lifecycle.StageProducer, "leader_election", elector.Stop)
+    }
+    coordinator.Register(lifecycle.StageStorage, "metrics", lifecycle.StopperFunc(metrics.Flush))
+
+    return coordinator
+}
+
+// setupLeaderElection creates the correlator's leader elector, or returns nil
+// when leader election is not configured and this replica always processes.
+// Leadership transitions are reported to scope and start or stop consumer.
+func setupLeaderElection(config map[string]interface{}, scope *lifecycle.LeaderScope, consumer *streaming.LeaderConsumer) (*lifecycle.LeaderElector, error) {
+    electionConfig, ok := config["leader_election"].(map[string]interface{})
+    if !ok {
+        return nil, nil
+    }
+    if enabled, _ := electionConfig["enabled"].(bool); !enabled {
+        return nil, nil
+    }
+
+    var addresses []string
+    if configured, ok := electionConfig["redis_addresses"].([]interface{}); ok {
+        for _, address := range configured {
+            if s, ok := address.(string); ok {
+                addresses = append(addresses, s)
+            }
+        }
+    }
+    password, _ := electionConfig["redis_password"].(string)
+    clusterMode, _ := electionConfig["redis_cluster"].(bool)
+
+    redisClient, err := storage.NewRedisClient(&storage.RedisConfig{
+        Addresses:   addresses,
+        Password:    password,
+        ClusterMode: clusterMode,
+    })
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to connect to leader election store", nil)
+    }
+
+    identity, err := os.Hostname()
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to determine replica identity", nil)
+    }
+
+    leaseDuration, _ := time.ParseDuration(fmt.Sprint(electionConfig["lease_duration"]))
+    return lifecycle.NewLeaderElector(redisClient, lifecycle.LeaderConfig{
+        Election:      "analyzer-correlator",
+        Identity:      identity,
+        LeaseDuration: leaseDuration,
+        RenewInterval: leaseDuration / 3,
+        OnElected: func(ctx context.Context) error {
+            if err := scope.Elected(ctx); err != nil {
+                return err
+            }
+            return consumer.Elected(ctx)
+        },
+        OnDemoted: func() {
+            scope.Demoted()
+            consumer.Demoted()
+        },
+    })
+}
+
+// setupStreaming returns a factory for the Silver consumer, called once per
+// leadership term, and the Gold alert router from the streaming section of
+// the configuration. The router has a producer per configured topic; clients
+// without a route in alert_routing get the output_topic.
+func setupStreaming(config map[string]interface{}) (func() (*streaming.Consumer, error), *analyzer.AlertRouter, error) {
+    section, _ := config["streaming"].(map[string]interface{})
+    setting := func(key, fallback string) string {
+        if value, ok := section[key].(string); ok && value != "" {
+            return value
+        }
+        return fallback
+    }
+
+    requireTLS, _ := section["require_tls"].(bool)
+
+    brokers := setting("brokers", "")
+    kafkaConfig := &kafka.ConfigMap{
+        "bootstrap.servers": brokers,
+        "group.id":          setting("consumer_group", defaultConsumerGroup),
+    }
+    protocol := setting("security_protocol", "PLAINTEXT")
+    if protocol != "PLAINTEXT" {
+        kafkaConfig.SetKey("security.protocol", protocol)
+        kafkaConfig.SetKey("sasl.mechanisms", setting("sasl_mechanism", "PLAIN"))
+        kafkaConfig.SetKey("sasl.username", setting("sasl_username", ""))
+        kafkaConfig.SetKey("sasl.password", setting("sasl_password", ""))
+    }
+
+    serializer, err := newSilverDeserializer(section)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    maxMessageAge, startFromTime, err := consumerStartPosition(section)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    inputTopic := setting("input_topic", defaultInputTopic)
+    newConsumer := func() (*streaming.Consumer, error) {
+        consumer, err := streaming.NewConsumer(kafkaConfig, []string{inputTopic}, streaming.ConsumerOptions{
+            BatchSize:      maxBatchSize,
+            CommitStrategy: streaming.CommitManualPerBatch,
+            RequireTLS:     requireTLS,
+            Serializer:     serializer,
+            MaxMessageAge:  maxMessageAge,
+            StartFromTime:  startFromTime,
+        })
+        if err != nil {
+            return nil, errors.WrapError(err, "failed to create Silver consumer", nil)
+        }
+        return consumer, nil
+    }
+
+    client, err := streaming.NewKafkaClient(&streaming.KafkaConfig{
+        BootstrapServers: brokers,
+        SecurityProtocol: protocol,
+        SaslMechanism:    setting("sasl_mechanism", "PLAIN"),
+        SaslUsername:     setting("sasl_username", ""),
+        SaslPassword:     setting("sasl_password", ""),
+    })
+    if err != nil {
+        return nil, nil, errors.WrapError(err, "failed to connect alert producer", nil)
+    }
+    var routing analyzer.AlertRoutingConfig
+    if raw, ok := section["alert_routing"]; ok {
+        data, err := yaml.Marshal(raw)
+        if err != nil {
+            return nil, nil, errors.WrapError(err, "failed to read alert routing", nil)
+        }
+        if err := yaml.Unmarshal(data, &routing); err != nil {
+            return nil, nil, errors.WrapError(err, "failed to parse alert routing", nil)
+        }
+    }
+    if routing.DefaultDestination == "" {
+        routing.DefaultDestination = setting("output_topic", defaultOutputTopic)
+    }
+
+    router, err := analyzer.NewAlertRouter(routing, func(topic string) (analyzer.AlertSink, error) {
+        producer, err := streaming.NewProducer(client, topic, &streaming.ProducerOptions{
+            RequireTLS: requireTLS,
+        })
+        if err != nil {
+            return nil, errors.WrapError(err, "failed to create alert producer", map[string]interface{}{
+                "topic": topic,
+            })
+        }
+        return producer, nil
+    })
+    if err != nil {
+        return nil, nil, err
+    }
+    return newConsumer, router, nil
+}
+
+// consumerStartPosition reads the streaming section's max_message_age, a
+// duration such as "6h", and start_from_time, an RFC 3339 time. Silver events
+// older than either are skipped rather than analyzed.
+func consumerStartPosition(section map[string]interface{}) (time.Duration, time.Time, error) {
+    var maxAge time.Duration
+    if raw, ok := section["max_message_age"].(string); ok && raw != "" {
+        age, err := time.ParseDuration(raw)
+        if err != nil || age < 0 {
+            return 0, time.Time{}, errors.NewError("E2001", "invalid streaming max_message_age", map[string]interface{}{
+                "max_message_age": raw,
+            })
+        }
+        maxAge = age
+    }
+
+    var startFrom time.Time
+    switch raw := section["start_from_time"].(type) {
+    case time.Time:
+        startFrom = raw
+    case string:
+        if raw == "" {
+            break
+        }
+        parsed, err := time.Parse(time.RFC3339, raw)
+        if err != nil {
+            return 0, time.Time{}, errors.NewError("E2001", "invalid streaming start_from_time", map[string]interface{}{
+                "start_from_time": raw,
+            })
+        }
+        startFrom = parsed
+    }
+
+    return maxAge, startFrom, nil
+}
+
+// newSilverDeserializer decodes Silver events with the schema registry named
+// in the streaming section's schema_registry settings, or returns nil to
+// decode JSON when none is configured. The analyzer only consumes Silver, so
+// no schemas are registered; each message's schema is fetched by its ID.
+func newSilverDeserializer(section map[string]interface{}) (streaming.Serializer, error) {
+    registry, _ := section["schema_registry"].(map[string]interface{})
+    url, _ := registry["url"].(string)
+    if url == "" {
+        return nil, nil
+    }
+
+    username, _ := registry["username"].(string)
+    password, _ := registry["password"].(string)
+    serializer, err := streaming.NewSchemaRegistrySerializer(streaming.SchemaRegistryConfig{
+        URL:      url,
+        Username: username,
+        Password: password,
+    })
+    if err != nil {
+        return nil, errors.WrapError(err, "invalid schema registry configuration", nil)
+    }
+    return serializer, nil
+}
+
+// processEvents takes one consumed Silver batch and runs it through detection,
+// returning after standbyPollInterval when none arrives so the pool can
+// recheck standby and shutdown. The batch runs under the leadership scope: a
+// replica demoted mid-batch abandons it uncommitted for the new leader to
+// redeliver. Correlation and intelligence generation
+// run on the engine and correlator as they are wired in.
+func processEvents(ctx context.Context, engine *intelligence.IntelligenceEngine, correlator *correlation.EventCorrelator, pipeline *analyzer.Pipeline, batches <-chan analysisBatch, leadership *lifecycle.LeaderScope) {
+    select {
+    case <-ctx.Done():
+    case <-time.After(standbyPollInterval):
+    case batch := <-batches:
+        leaderCtx, release, leading := leadership.Context(ctx)
+        defer release()
+        if !leading {
+            batch.done <- errors.NewError("E4002", "analyzer replica is not the leader", nil)
+            return
+        }
+        batch.done <- pipeline.HandleBatch(leaderCtx, batch.messages)
+    }
 }
\ No newline at end of file
@@ -5,16 +5,23 @@ import (
     "context"
     "flag"
     "fmt"
+    "io"
     "os"
     "os/signal"
-    "sync"
     "syscall"
     "time"
 
+    "github.com/golang-jwt/jwt/v4" // v4.5.0
+    "gopkg.in/yaml.v3"             // v3.0.1
+
+    "github.com/blackpoint/pkg/common"
     "github.com/blackpoint/pkg/common/logging"
     "github.com/blackpoint/pkg/common/errors"
     "github.com/blackpoint/internal/collector"
     "github.com/blackpoint/internal/collector/validation"
+    "github.com/blackpoint/internal/integration"
+    "github.com/blackpoint/internal/lifecycle"
+    "github.com/blackpoint/pkg/integration/config"
     "github.com/prometheus/client_golang/prometheus"
     "github.com/prometheus/client_golang/prometheus/promhttp"
     "net/http"
@@ -31,8 +38,50 @@ var (
     configPath = flag.String("config", "", "Path to configuration file")
     logLevel = flag.String("log-level", "info", "Logging level (debug, info, warn, error)")
     metricsAddr = flag.String("metrics-addr", ":9090", "The address to expose metrics on")
+    ingestAddr = flag.String("ingest-addr", ":8080", "The address to accept events on")
+    tlsCert = flag.String("tls-cert", "/etc/blackpoint/certs/collector.crt", "TLS certificate for the ingest server")
+    tlsKey = flag.String("tls-key", "/etc/blackpoint/certs/collector.key", "TLS key for the ingest server")
 )
 
+// maxIngestBodySize bounds a single ingested event; larger bodies are rejected
+// by the collector's own size validation
+const maxIngestBodySize = 1 << 20
+
+// rateLimitSection is the rate_limit section of the collector configuration
+type rateLimitSection struct {
+    RateLimit struct {
+        Enabled     bool                      `yaml:"enabled"`
+        Default     rateLimitEntry            `yaml:"default"`
+        Clients     map[string]rateLimitEntry `yaml:"clients"`
+        IdleTimeout time.Duration             `yaml:"idle_timeout"`
+    } `yaml:"rate_limit"`
+}
+
+// rateLimitEntry is one client's limit in the configuration file
+type rateLimitEntry struct {
+    EventsPerSecond float64 `yaml:"events_per_second"`
+    Burst           int     `yaml:"burst"`
+}
+
+// pollingSection is the polling section of the collector configuration
+type pollingSection struct {
+    Polling struct {
+        MaxConcurrent int                          `yaml:"max_concurrent"`
+        Interval      time.Duration                `yaml:"interval"`
+        Jitter        time.Duration                `yaml:"jitter"`
+        Platforms     map[string]pollScheduleEntry `yaml:"platforms"`
+        // Integrations lists integration configuration files deployed at
+        // startup; batch and hybrid ones are polled by this service
+        Integrations []string `yaml:"integrations"`
+    } `yaml:"polling"`
+}
+
+// pollScheduleEntry is one platform's polling schedule in the configuration file
+type pollScheduleEntry struct {
+    Interval time.Duration `yaml:"interval"`
+    Jitter   time.Duration `yaml:"jitter"`
+}
+
 // Metrics collectors
 var (
     collectorMetrics = struct {
@@ -127,6 +176,14 @@ func main() {
         os.Exit(1)
     }
 
+    // Per-client limits come from the same file and are reloaded on SIGHUP
+    rateLimits, err := loadRateLimits(*configPath)
+    if err != nil {
+        logging.Error("Failed to load rate limits", err)
+        os.Exit(1)
+    }
+    config.RateLimit = rateLimits
+
     // Initialize collector
     collector, err := initCollector(ctx, config, securityCtx)
     if err != nil {
@@ -140,6 +197,28 @@ func main() {
         os.Exit(1)
     }
 
+    if rateLimits.Enabled {
+        go reloadRateLimitsOnSignal(ctx, *configPath, collector)
+    }
+
+    // Poll batch and hybrid integrations for as long as the service runs
+    scheduler, err := initPolling(ctx, *configPath)
+    if err != nil {
+        logging.Error("Failed to initialize polling", err)
+        os.Exit(1)
+    }
+
+    // Accept events from authenticated clients
+    ingestServer := &http.Server{Addr: *ingestAddr, Handler: common.AuthMiddleware(ingestHandler(collector))}
+    go func() {
+        if err := ingestServer.ListenAndServeTLS(*tlsCert, *tlsKey); err != nil && err != http.ErrServerClosed {
+            logging.Error("Ingest server failed",
+                err,
+                logging.Field("address", *ingestAddr),
+            )
+        }
+    }()
+
     // Start performance monitoring
     monitorCtx, monitorCancel := context.WithCancel(ctx)
     go monitorPerformance(monitorCtx, collector)
@@ -152,30 +231,25 @@ func main() {
     monitorCancel()
 
     // Perform graceful shutdown
-    shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
-    defer shutdownCancel()
-
-    var wg sync.WaitGroup
-    wg.Add(1)
-    go func() {
-        defer wg.Done()
+    coordinator := lifecycle.NewCoordinator("collector")
+    coordinator.Register(lifecycle.StageIntake, "ingest_server", ingestServer.Shutdown)
+    coordinator.Register(lifecycle.StageIntake, "polling_scheduler", scheduler.Stop)
+    coordinator.Register(lifecycle.StageIntake, "collector", func(ctx context.Context) error {
+        // Drain within the shutdown budget; Stop then finds the buffer empty.
+        // Stop runs even if the drain timed out so the batch loop still exits.
+        drainErr := collector.Drain(ctx)
         if err := collector.Stop(); err != nil {
-            logging.Error("Error during collector shutdown", err)
+            return err
         }
-    }()
-
-    // Wait for shutdown to complete or timeout
-    shutdownComplete := make(chan struct{})
-    go func() {
-        wg.Wait()
-        close(shutdownComplete)
-    }()
-
-    select {
-    case <-shutdownComplete:
+        return drainErr
+    })
+    // Every stage gets its full budget
+    shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), coordinator.Timeout())
+    defer shutdownCancel()
+    if err := coordinator.Shutdown(shutdownCtx); err != nil {
+        logging.Error("Error during collector shutdown", err)
+    } else {
         logging.Info("Graceful shutdown completed")
-    case <-shutdownCtx.Done():
-        logging.Error("Shutdown timed out", errors.NewError("E4001", "shutdown timeout exceeded", nil))
     }
 
     // Log shutdown completion with audit
@@ -203,6 +277,159 @@ func setupSignalHandler() (context.Context, context.CancelFunc) {
     return ctx, cancel
 }
 
+// loadRateLimits reads the rate_limit section of the configuration file
+func loadRateLimits(path string) (collector.RateLimitConfig, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return collector.RateLimitConfig{}, errors.WrapError(err, "failed to read collector configuration", map[string]interface{}{
+            "path": path,
+        })
+    }
+
+    var section rateLimitSection
+    if err := yaml.Unmarshal(data, &section); err != nil {
+        return collector.RateLimitConfig{}, errors.WrapError(err, "failed to parse rate limits", map[string]interface{}{
+            "path": path,
+        })
+    }
+
+    config := collector.RateLimitConfig{
+        Enabled: section.RateLimit.Enabled,
+        Default: collector.RateLimit{
+            EventsPerSecond: section.RateLimit.Default.EventsPerSecond,
+            Burst:           section.RateLimit.Default.Burst,
+        },
+        Clients:     make(map[string]collector.RateLimit, len(section.RateLimit.Clients)),
+        IdleTimeout: section.RateLimit.IdleTimeout,
+    }
+    for clientID, limit := range section.RateLimit.Clients {
+        config.Clients[clientID] = collector.RateLimit{
+            EventsPerSecond: limit.EventsPerSecond,
+            Burst:           limit.Burst,
+        }
+    }
+    return config, nil
+}
+
+// initPolling creates the polling scheduler from the polling section of the
+// configuration file and deploys the integrations it lists. Polls use ctx and
+// stop when it is cancelled.
+func initPolling(ctx context.Context, path string) (*collector.PollingScheduler, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to read collector configuration", map[string]interface{}{
+            "path": path,
+        })
+    }
+
+    var section pollingSection
+    if err := yaml.Unmarshal(data, &section); err != nil {
+        return nil, errors.WrapError(err, "failed to parse polling configuration", map[string]interface{}{
+            "path": path,
+        })
+    }
+
+    scheduler := collector.NewPollingScheduler(section.Polling.MaxConcurrent, collector.PollSchedule{
+        Interval: section.Polling.Interval,
+        Jitter:   section.Polling.Jitter,
+    })
+    for platformType, entry := range section.Polling.Platforms {
+        schedule := collector.PollSchedule{Interval: entry.Interval, Jitter: entry.Jitter}
+        if err := scheduler.SetPlatformSchedule(platformType, schedule); err != nil {
+            return nil, err
+        }
+    }
+
+    manager := integration.GetManager()
+    manager.EnablePolling(ctx, scheduler)
+
+    for _, integrationPath := range section.Polling.Integrations {
+        data, err := os.ReadFile(integrationPath)
+        if err != nil {
+            return nil, errors.WrapError(err, "failed to read integration configuration", map[string]interface{}{
+                "path": integrationPath,
+            })
+        }
+        var cfg config.IntegrationConfig
+        if err := yaml.Unmarshal(data, &cfg); err != nil {
+            return nil, errors.WrapError(err, "failed to parse integration configuration", map[string]interface{}{
+                "path": integrationPath,
+            })
+        }
+        if _, err := manager.DeployIntegration(ctx, &cfg); err != nil {
+            return nil, err
+        }
+    }
+
+    return scheduler, nil
+}
+
+// reloadRateLimitsOnSignal reapplies the configured rate limits on SIGHUP. A
+// rejected configuration is logged and the active limits stay in place.
+func reloadRateLimitsOnSignal(ctx context.Context, path string, c *collector.RealtimeCollector) {
+    hupChan := make(chan os.Signal, 1)
+    signal.Notify(hupChan, syscall.SIGHUP)
+    defer signal.Stop(hupChan)
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-hupChan:
+            limits, err := loadRateLimits(path)
+            if err == nil {
+                err = c.ReloadRateLimits(limits)
+            }
+            if err != nil {
+                logging.Error("Rejected rate limits, keeping active limits", err)
+                continue
+            }
+            logging.Info("Rate limits reloaded")
+        }
+    }
+}
+
+// ingestHandler collects the event in each request body. Events are
+// attributed to the client_id of the token AuthMiddleware verified, which
+// keys the per-client rate limits.
+func ingestHandler(c *collector.RealtimeCollector) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != http.MethodPost {
+            w.Header().Set("Allow", http.MethodPost)
+            http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+            return
+        }
+
+        claims, _ := r.Context().Value("claims").(jwt.MapClaims)
+        clientID, _ := claims["client_id"].(string)
+        if clientID == "" {
+            http.Error(w, "token has no client_id", http.StatusForbidden)
+            return
+        }
+
+        body, err := io.ReadAll(io.LimitReader(r.Body, maxIngestBodySize+1))
+        if err != nil {
+            http.Error(w, "failed to read event", http.StatusBadRequest)
+            return
+        }
+
+        err = c.CollectEvent(collector.WithClientID(r.Context(), clientID), body)
+        switch {
+        case err == nil:
+            w.WriteHeader(http.StatusAccepted)
+        case errors.IsErrorCode(err, "E4029", ""):
+            http.Error(w, err.Error(), http.StatusTooManyRequests)
+        case errors.IsErrorCode(err, "E4002", ""):
+            // Buffer full or draining: the sender should retry elsewhere
+            http.Error(w, err.Error(), http.StatusServiceUnavailable)
+        case errors.IsErrorCode(err, "E3001", ""):
+            http.Error(w, err.Error(), http.StatusBadRequest)
+        default:
+            http.Error(w, err.Error(), http.StatusInternalServerError)
+        }
+    })
+}
+
 // initCollector initializes the collector service with security context and monitoring
 func initCollector(ctx context.Context, config *collector.CollectorConfig, secCtx *validation.SecurityContext) (*collector.RealtimeCollector, error) {
     // Validate collector configuration
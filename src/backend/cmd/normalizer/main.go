@@ -3,26 +3,38 @@ package main
 
 import (
     "context"
+    "encoding/json"
     "os"
     "os/signal"
+    "sort"
     "syscall"
     "time"
 
+    awsconfig "github.com/aws/aws-sdk-go-v2/config"
+    "github.com/aws/aws-sdk-go-v2/service/s3"
     "github.com/prometheus/client_golang/prometheus"
     "github.com/prometheus/client_golang/prometheus/promhttp"
     "go.opentelemetry.io/otel"
     "go.opentelemetry.io/otel/attribute"
     "net/http"
 
+    "../../internal/health"
+    "../../internal/lifecycle"
+    "../../internal/metrics"
+    "../../internal/normalizer"
     "../../internal/normalizer/processor"
+    "../../internal/storage"
+    "../../internal/streaming"
     "../../internal/streaming/consumer"
     "../../internal/config/loader"
     "../../pkg/common/logging"
+    silver "../../pkg/silver/schema"
 )
 
 const (
     defaultConfigPath = "/etc/blackpoint/normalizer.yaml"
-    shutdownTimeout  = 30 * time.Second
+    defaultOutputTopic = "silver-events"
+    defaultDeadLetterTopic = "bronze-events-dead-letter"
     metricsPort     = ":9090"
     healthCheckPort = ":8080"
 )
@@ -49,11 +61,105 @@ type Config struct {
     KafkaBrokers      string        `yaml:"kafka_brokers"`
     ConsumerGroup     string        `yaml:"consumer_group"`
     InputTopics       []string      `yaml:"input_topics"`
+    OutputTopic       string        `yaml:"output_topic"`
+    DeadLetterTopic   string        `yaml:"dead_letter_topic"`
     ProcessingTimeout time.Duration `yaml:"processing_timeout"`
     BatchSize         int           `yaml:"batch_size"`
+    CommitStrategy    string        `yaml:"commit_strategy"`
+    AutoOffsetReset   string        `yaml:"auto_offset_reset"`
+    // MaxMessageAge skips Bronze events older than this instead of normalizing them
+    MaxMessageAge     time.Duration `yaml:"max_message_age"`
+    // StartFromTime starts newly assigned partitions at this time when set
+    StartFromTime     time.Time     `yaml:"start_from_time"`
     Security          SecurityConfig `yaml:"security"`
+    SecurityContext   SecurityContextConfig `yaml:"security_context"`
     Monitoring        MonitoringConfig `yaml:"monitoring"`
     HealthCheck       HealthCheckConfig `yaml:"healthcheck"`
+    Backpressure      BackpressureConfig `yaml:"backpressure"`
+    Output            OutputConfig `yaml:"output"`
+    Mappings          MappingsConfig `yaml:"mappings"`
+    SensitiveFields   SensitiveFieldsConfig `yaml:"sensitive_fields"`
+    SchemaMigration   SchemaMigrationConfig `yaml:"schema_migration"`
+    Redis             RedisConfig `yaml:"redis"`
+    S3                S3Config `yaml:"s3"`
+    SchemaRegistry    SchemaRegistryConfig `yaml:"schema_registry"`
+}
+
+// SchemaRegistryConfig encodes produced Silver events as Avro registered with
+// the schema registry; events are produced as JSON when the URL is empty
+type SchemaRegistryConfig struct {
+    URL      string        `yaml:"url"`
+    Username string        `yaml:"username"`
+    Password string        `yaml:"password"`
+    Timeout  time.Duration `yaml:"timeout"`
+}
+
+// RedisConfig locates the Redis deployment shared with the other tiers; its
+// readiness is checked only when addresses are set
+type RedisConfig struct {
+    Addresses   []string `yaml:"addresses"`
+    Password    string   `yaml:"password"`
+    ClusterMode bool     `yaml:"cluster_mode"`
+    TLSEnabled  bool     `yaml:"tls_enabled"`
+}
+
+// S3Config locates the tier buckets; their readiness is checked only when a
+// bucket prefix is set
+type S3Config struct {
+    Region       string `yaml:"region"`
+    BucketPrefix string `yaml:"bucket_prefix"`
+}
+
+// SchemaMigrationConfig upgrades Bronze events from older schema versions
+type SchemaMigrationConfig struct {
+    // TargetVersion is the schema version events are mapped at; migration is
+    // disabled when empty
+    TargetVersion string `yaml:"target_version"`
+
+    // Migrations are the single-version upgrade steps up to TargetVersion
+    Migrations []SchemaMigrationStep `yaml:"migrations"`
+}
+
+// SchemaMigrationStep upgrades events from one schema version to the next
+type SchemaMigrationStep struct {
+    FromVersion string `yaml:"from_version"`
+    ToVersion   string `yaml:"to_version"`
+
+    // RenameFields maps old top-level payload field names to new ones
+    RenameFields map[string]string `yaml:"rename_fields"`
+}
+
+// SensitiveFieldsConfig selects the fields encrypted during normalization
+type SensitiveFieldsConfig struct {
+    // Patterns maps a name to a field name regex, extending the defaults
+    Patterns map[string]string `yaml:"patterns"`
+
+    // AllowList names exact fields that are never encrypted
+    AllowList []string `yaml:"allow_list"`
+}
+
+// MappingsConfig locates the field mapping definitions
+type MappingsConfig struct {
+    // Path is a YAML or JSON mapping file, reloaded on SIGHUP
+    Path string `yaml:"path"`
+}
+
+// OutputConfig selects the field layout of produced Silver events
+type OutputConfig struct {
+    Mode       string            `yaml:"mode"`
+    ECSMapping map[string]string `yaml:"ecs_mapping"`
+}
+
+// BackpressureConfig represents lag-driven throttling configuration
+type BackpressureConfig struct {
+    Enabled        bool          `yaml:"enabled"`
+    AnalyzerGroup  string        `yaml:"analyzer_group"`
+    AnalyzerTopics []string      `yaml:"analyzer_topics"`
+    HighLag        int64         `yaml:"high_lag"`
+    LowLag         int64         `yaml:"low_lag"`
+    Mode           string        `yaml:"mode"`
+    SampleRate     float64       `yaml:"sample_rate"`
+    CheckInterval  time.Duration `yaml:"check_interval"`
 }
 
 // SecurityConfig represents security-related configuration
@@ -62,6 +168,16 @@ type SecurityConfig struct {
     SASLUsername  string `yaml:"sasl_username"`
     SASLPassword  string `yaml:"sasl_password"`
     SASLMechanism string `yaml:"sasl_mechanism"`
+    RequireTLS    bool   `yaml:"require_tls"`
+}
+
+// SecurityContextConfig classifies the Silver events the normalizer produces
+type SecurityContextConfig struct {
+    // Classification is applied to every event; the processor's default
+    // classification applies when empty
+    Classification string   `yaml:"classification"`
+    Sensitivity    string   `yaml:"sensitivity"`
+    Compliance     []string `yaml:"compliance"`
 }
 
 // MonitoringConfig represents monitoring-related configuration
@@ -69,12 +185,21 @@ type MonitoringConfig struct {
     MetricsEnabled bool    `yaml:"metrics_enabled"`
     TracingEnabled bool    `yaml:"tracing_enabled"`
     SamplingRate   float64 `yaml:"sampling_rate"`
+
+    // LedgerReportInterval is how often event disposition is reconciled in the logs
+    LedgerReportInterval time.Duration `yaml:"ledger_report_interval"`
+
+    // MaxLabelValues caps distinct client label values of per-client metrics
+    MaxLabelValues int `yaml:"max_label_values"`
 }
 
 // HealthCheckConfig represents health check configuration
 type HealthCheckConfig struct {
     Enabled bool `yaml:"enabled"`
     Port    int  `yaml:"port"`
+
+    // CheckTimeout bounds each dependency check of the readiness probe
+    CheckTimeout time.Duration `yaml:"check_timeout"`
 }
 
 func main() {
@@ -89,14 +214,20 @@ func main() {
         os.Exit(1)
     }
 
+    // Components register with the coordinator as they are created
+    coordinator := lifecycle.NewCoordinator("normalizer")
+
     // Initialize OpenTelemetry tracing
     if config.Monitoring.TracingEnabled {
         tp := initTracing(config)
-        defer tp.Shutdown(context.Background())
+        coordinator.Register(lifecycle.StageStorage, "tracing", tp.Shutdown)
     }
 
     // Register Prometheus metrics
     prometheus.MustRegister(eventsProcessed, processingLatency, processingErrors)
+    if config.Monitoring.MaxLabelValues > 0 {
+        metrics.SetLabelValueLimit(normalizer.DeadLetterMetric, config.Monitoring.MaxLabelValues)
+    }
 
     // Start metrics server if enabled
     if config.Monitoring.MetricsEnabled {
@@ -108,32 +239,172 @@ func main() {
         }()
     }
 
-    // Start health check server if enabled
-    if config.HealthCheck.Enabled {
-        go startHealthCheckServer(config.HealthCheck.Port)
-    }
 
     // Create and configure Kafka consumer
     kafkaConsumer, err := consumer.NewConsumer(createKafkaConfig(config), config.InputTopics, consumer.ConsumerOptions{
         BatchSize: config.BatchSize,
         EnableMetrics: config.Monitoring.MetricsEnabled,
+        RequireTLS: config.Security.RequireTLS,
+        CommitStrategy: config.CommitStrategy,
+        AutoOffsetReset: config.AutoOffsetReset,
+        MaxMessageAge: config.MaxMessageAge,
+        StartFromTime: config.StartFromTime,
     })
     if err != nil {
         logger.Error("Failed to create Kafka consumer", err)
         os.Exit(1)
     }
+    coordinator.Register(lifecycle.StageIntake, "kafka_consumer", lifecycle.StopperFunc(kafkaConsumer.Stop))
+
+    // Load field mappings so they can be changed without a rebuild
+    fieldMapper := normalizer.NewFieldMapper(make(map[string]string), nil)
+    if config.Mappings.Path != "" {
+        mappingFile, err := normalizer.LoadMappingFile(config.Mappings.Path)
+        if err != nil {
+            logger.Error("Failed to load field mappings", err)
+            os.Exit(1)
+        }
+        fieldMapper.ApplyMappingFile(mappingFile)
+    }
+
+    // Compile sensitive field patterns once at startup
+    eventTransformer := normalizer.NewTransformer(config.ProcessingTimeout)
+    if err := eventTransformer.SetSensitiveFields(normalizer.SensitiveFieldConfig{
+        Patterns:  config.SensitiveFields.Patterns,
+        AllowList: config.SensitiveFields.AllowList,
+    }); err != nil {
+        logger.Error("Invalid sensitive field configuration", err)
+        os.Exit(1)
+    }
 
     // Initialize event processor
-    eventProcessor, err := processor.NewProcessor(nil, nil, config.ProcessingTimeout)
+    eventProcessor, err := processor.NewProcessor(fieldMapper, eventTransformer, config.ProcessingTimeout)
     if err != nil {
         logger.Error("Failed to create event processor", err)
         os.Exit(1)
     }
+    if err := eventProcessor.SetOutputMode(config.Output.Mode, config.Output.ECSMapping); err != nil {
+        logger.Error("Invalid output configuration", err)
+        os.Exit(1)
+    }
+    if config.SchemaMigration.TargetVersion != "" {
+        migrator, err := newSchemaMigrator(config.SchemaMigration)
+        if err != nil {
+            logger.Error("Invalid schema migration configuration", err)
+            os.Exit(1)
+        }
+        eventProcessor.SetSchemaMigrator(migrator)
+    }
+    coordinator.Register(lifecycle.StageProcessing, "event_processor", eventProcessor.Drain)
+
+    // Publish normalized events to the Silver topic
+    silverSerializer, err := newSilverSerializer(config)
+    if err != nil {
+        logger.Error("Failed to register Silver schema", err)
+        os.Exit(1)
+    }
+    silverProducer, err := newProducer(config, config.OutputTopic, defaultOutputTopic, silverSerializer)
+    if err != nil {
+        logger.Error("Failed to create Silver producer", err)
+        os.Exit(1)
+    }
+    coordinator.Register(lifecycle.StageProducer, "silver_producer", lifecycle.StopperFunc(silverProducer.Close))
+
+    // Messages that cannot be normalized are moved aside before their offsets are committed
+    deadLetterProducer, err := newProducer(config, config.DeadLetterTopic, defaultDeadLetterTopic, nil)
+    if err != nil {
+        logger.Error("Failed to create dead-letter producer", err)
+        os.Exit(1)
+    }
+    coordinator.Register(lifecycle.StageProducer, "dead_letter_producer", lifecycle.StopperFunc(deadLetterProducer.Close))
 
     // Set up signal handling for graceful shutdown
     ctx, cancel, signalChan := setupSignalHandler()
     defer cancel()
 
+    if config.Mappings.Path != "" {
+        go reloadMappingsOnSignal(ctx, config.Mappings.Path, fieldMapper)
+    }
+
+    // Account for every event dropped between consumption and publishing
+    metrics.ConfigureLedger(metrics.LedgerConfig{
+        ReportInterval: config.Monitoring.LedgerReportInterval,
+    })
+    metrics.Ledger().StartReporting(ctx)
+
+    // Throttle input when the analyzer falls behind
+    var backpressure *normalizer.BackpressureController
+    if config.Backpressure.Enabled {
+        lagMonitor, err := streaming.NewLagMonitor(createKafkaConfig(config), config.Backpressure.AnalyzerGroup, config.Backpressure.AnalyzerTopics)
+        if err != nil {
+            logger.Error("Failed to create analyzer lag monitor", err)
+            os.Exit(1)
+        }
+        coordinator.Register(lifecycle.StageStorage, "lag_monitor", lifecycle.StopperFunc(lagMonitor.Close))
+
+        backpressure, err = normalizer.NewBackpressureController(lagMonitor, normalizer.BackpressureConfig{
+            HighLag:       config.Backpressure.HighLag,
+            LowLag:        config.Backpressure.LowLag,
+            Mode:          config.Backpressure.Mode,
+            SampleRate:    config.Backpressure.SampleRate,
+            CheckInterval: config.Backpressure.CheckInterval,
+        }, nil)
+        if err != nil {
+            logger.Error("Failed to create back-pressure controller", err)
+            os.Exit(1)
+        }
+
+        kafkaConsumer.SetFlowControl(backpressure)
+        go backpressure.Run(ctx)
+    }
+
+    // Feed consumed batches through the processor; offsets are committed
+    // only after a batch has been published
+    pipelineConfig := normalizer.PipelineConfig{
+        BatchSize:       config.BatchSize,
+        Timeout:         config.ProcessingTimeout,
+        SecurityContext: newSecurityContext(config.SecurityContext),
+        Processed:       eventsProcessed,
+        Errors:          processingErrors,
+        Latency:         processingLatency,
+    }
+    if backpressure != nil {
+        // Sample mode sheds events here; pause mode stops the consumer itself
+        pipelineConfig.Admission = backpressure
+    }
+    pipeline, err := normalizer.NewPipeline(eventProcessor, silverProducer, pipelineConfig)
+    if err != nil {
+        logger.Error("Failed to create processing pipeline", err)
+        os.Exit(1)
+    }
+    pipeline.SetDeadLetter(deadLetterProducer)
+    kafkaConsumer.SetHandler(pipeline.HandleBatch)
+
+    // Start health check server if enabled
+    if config.HealthCheck.Enabled {
+        redisClient, s3Client, err := newStorageClients(config)
+        if err != nil {
+            logger.Error("Failed to connect to storage dependencies", err)
+            os.Exit(1)
+        }
+        if redisClient != nil {
+            coordinator.Register(lifecycle.StageStorage, "redis", lifecycle.StopperFunc(redisClient.Close))
+        }
+
+        checker, err := newHealthChecker(config, healthDependencies{
+            consumer:  kafkaConsumer,
+            producer:  silverProducer,
+            processor: eventProcessor,
+            redis:     redisClient,
+            s3:        s3Client,
+        })
+        if err != nil {
+            logger.Error("Failed to configure health checks", err)
+            os.Exit(1)
+        }
+        go startHealthCheckServer(config.HealthCheck.Port, checker, backpressure)
+    }
+
     // Start event processing
     if err := kafkaConsumer.Start(); err != nil {
         logger.Error("Failed to start consumer", err)
@@ -149,8 +420,10 @@ func main() {
     // Wait for shutdown signal
     <-signalChan
 
-    // Perform graceful shutdown
-    if err := shutdown(ctx, kafkaConsumer, eventProcessor); err != nil {
+    // Perform graceful shutdown, giving every stage its full budget
+    shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), coordinator.Timeout())
+    defer shutdownCancel()
+    if err := coordinator.Shutdown(shutdownCtx); err != nil {
         logger.Error("Error during shutdown", err)
         os.Exit(1)
     }
@@ -172,6 +445,39 @@ func loadServiceConfig() (*Config, error) {
     return &config, nil
 }
 
+// newSchemaMigrator builds the migrator for the configured upgrade steps
+func newSchemaMigrator(config SchemaMigrationConfig) (*processor.SchemaMigrator, error) {
+    migrator, err := processor.NewSchemaMigrator(config.TargetVersion)
+    if err != nil {
+        return nil, err
+    }
+    for _, step := range config.Migrations {
+        if err := migrator.Register(step.FromVersion, step.ToVersion, renameFields(step.RenameFields)); err != nil {
+            return nil, err
+        }
+    }
+    return migrator, nil
+}
+
+// renameFields returns a migration applying each rename in a stable order
+func renameFields(renames map[string]string) processor.MigrationFunc {
+    from := make([]string, 0, len(renames))
+    for name := range renames {
+        from = append(from, name)
+    }
+    sort.Strings(from)
+
+    return func(payload map[string]interface{}) (map[string]interface{}, error) {
+        var err error
+        for _, name := range from {
+            if payload, err = processor.RenameField(name, renames[name])(payload); err != nil {
+                return nil, err
+            }
+        }
+        return payload, nil
+    }
+}
+
 func setupSignalHandler() (context.Context, context.CancelFunc, chan os.Signal) {
     ctx, cancel := context.WithCancel(context.Background())
     signalChan := make(chan os.Signal, 1)
@@ -180,29 +486,193 @@ func setupSignalHandler() (context.Context, context.CancelFunc, chan os.Signal)
     return ctx, cancel, signalChan
 }
 
-func shutdown(ctx context.Context, consumer *consumer.Consumer, processor *processor.Processor) error {
-    // Create shutdown context with timeout
-    shutdownCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
-    defer cancel()
+// reloadMappingsOnSignal reloads the field mapping file on SIGHUP. A file that
+// fails to load or validate is reported and the previous mappings stay active.
+func reloadMappingsOnSignal(ctx context.Context, path string, fieldMapper *normalizer.FieldMapper) {
+    hupChan := make(chan os.Signal, 1)
+    signal.Notify(hupChan, syscall.SIGHUP)
+    defer signal.Stop(hupChan)
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-hupChan:
+            mappingFile, err := normalizer.LoadMappingFile(path)
+            if err != nil {
+                logging.Error("Failed to reload field mappings, keeping previous mappings", err)
+                continue
+            }
+            fieldMapper.ApplyMappingFile(mappingFile)
+            logging.Info("Field mappings reloaded")
+        }
+    }
+}
+
+// newProducer creates a producer for topic, or for defaultTopic when topic is
+// empty. Values are encoded with serializer, or as JSON when it is nil.
+func newProducer(config *Config, topic, defaultTopic string, serializer streaming.Serializer) (*streaming.Producer, error) {
+    securityProtocol := "PLAINTEXT"
+    if config.Security.TLSEnabled {
+        securityProtocol = "SASL_SSL"
+    }
+    client, err := streaming.NewKafkaClient(&streaming.KafkaConfig{
+        BootstrapServers: config.KafkaBrokers,
+        SecurityProtocol: securityProtocol,
+        SaslMechanism:    config.Security.SASLMechanism,
+        SaslUsername:     config.Security.SASLUsername,
+        SaslPassword:     config.Security.SASLPassword,
+        EnableMetrics:    config.Monitoring.MetricsEnabled,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    if topic == "" {
+        topic = defaultTopic
+    }
+    return streaming.NewProducer(client, topic, &streaming.ProducerOptions{
+        RequireTLS: config.Security.RequireTLS,
+        Serializer: serializer,
+    })
+}
+
+// newSilverSerializer registers the Silver event schema for the output topic,
+// or returns nil to keep producing JSON when no schema registry is configured
+func newSilverSerializer(config *Config) (streaming.Serializer, error) {
+    if config.SchemaRegistry.URL == "" {
+        return nil, nil
+    }
 
-    // Stop consumer first to prevent new messages
-    if err := consumer.Stop(); err != nil {
-        return err
+    topic := config.OutputTopic
+    if topic == "" {
+        topic = defaultOutputTopic
     }
+    return streaming.NewSchemaRegistrySerializer(streaming.SchemaRegistryConfig{
+        URL:      config.SchemaRegistry.URL,
+        Username: config.SchemaRegistry.Username,
+        Password: config.SchemaRegistry.Password,
+        Timeout:  config.SchemaRegistry.Timeout,
+        Schemas:  map[string]string{topic: streaming.SilverEventSchema},
+    })
+}
 
-    // Wait for processing to complete or timeout
-    select {
-    case <-shutdownCtx.Done():
-        return shutdownCtx.Err()
-    default:
+// newSecurityContext builds the security context applied to every event,
+// or nil to keep the processor's default
+func newSecurityContext(config SecurityContextConfig) *silver.SecurityContext {
+    if config.Classification == "" {
         return nil
     }
+    return &silver.SecurityContext{
+        Classification: config.Classification,
+        Sensitivity:    config.Sensitivity,
+        Compliance:     config.Compliance,
+        Encryption:     make(map[string]string),
+        AccessControl:  make(map[string]string),
+    }
 }
 
-func startHealthCheckServer(port int) {
-    http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-        w.WriteHeader(http.StatusOK)
-        w.Write([]byte("healthy"))
+// healthDependencies are the components the readiness probe checks; the
+// storage clients are nil when not configured
+type healthDependencies struct {
+    consumer  *consumer.Consumer
+    producer  *streaming.Producer
+    processor *processor.Processor
+    redis     *storage.RedisClient
+    s3        *storage.S3Client
+}
+
+// newStorageClients connects to the Redis and S3 deployments named in the
+// configuration, returning nil for any that is not configured
+func newStorageClients(config *Config) (*storage.RedisClient, *storage.S3Client, error) {
+    var redisClient *storage.RedisClient
+    if len(config.Redis.Addresses) > 0 {
+        client, err := storage.NewRedisClient(&storage.RedisConfig{
+            Addresses:   config.Redis.Addresses,
+            Password:    config.Redis.Password,
+            ClusterMode: config.Redis.ClusterMode,
+            TLSEnabled:  config.Redis.TLSEnabled,
+        })
+        if err != nil {
+            return nil, nil, err
+        }
+        redisClient = client
+    }
+
+    var s3Client *storage.S3Client
+    if config.S3.BucketPrefix != "" {
+        // The buckets are set up by the storage tiers; the normalizer only
+        // checks they are reachable, so it skips NewS3Client's bucket setup
+        awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), awsconfig.WithRegion(config.S3.Region))
+        if err != nil {
+            if redisClient != nil {
+                redisClient.Close()
+            }
+            return nil, nil, err
+        }
+        s3Client = storage.NewS3ClientWithAPI(&storage.S3Config{
+            Region:         config.S3.Region,
+            BucketPrefix:   config.S3.BucketPrefix,
+            NetworkTimeout: config.HealthCheck.CheckTimeout,
+        }, s3.NewFromConfig(awsCfg))
+    }
+
+    return redisClient, s3Client, nil
+}
+
+// newHealthChecker registers the dependencies the normalizer needs to serve
+// traffic. Kafka intake and the Silver producer are critical; Redis, S3 and a
+// saturated processor only degrade readiness since normalization continues
+// without them.
+func newHealthChecker(config *Config, deps healthDependencies) (*health.HealthChecker, error) {
+    checker := health.NewHealthChecker("normalizer", config.HealthCheck.CheckTimeout)
+
+    dependencies := []health.Dependency{
+        {Name: "kafka", Check: deps.consumer.Ping, Critical: true},
+        {Name: "silver_producer", Check: deps.producer.Ping, Critical: true},
+    }
+    if deps.redis != nil {
+        dependencies = append(dependencies, health.Dependency{Name: "redis", Check: deps.redis.Ping})
+    }
+    if deps.s3 != nil {
+        dependencies = append(dependencies, health.Dependency{Name: "s3", Check: deps.s3.Ping})
+    }
+
+    _, workers := deps.processor.QueueDepth()
+    dependencies = append(dependencies, health.Dependency{
+        Name: "processor_queue",
+        Check: health.QueueDepthCheck(func() int {
+            depth, _ := deps.processor.QueueDepth()
+            return depth
+        }, workers),
+    })
+
+    for _, dependency := range dependencies {
+        if err := checker.Register(dependency); err != nil {
+            return nil, err
+        }
+    }
+    return checker, nil
+}
+
+func startHealthCheckServer(port int, checker *health.HealthChecker, backpressure *normalizer.BackpressureController) {
+    // Serve liveness and per-dependency readiness
+    checker.RegisterRoutes(http.DefaultServeMux)
+
+    // Expose throttle state so operators can see when back-pressure is active
+    http.HandleFunc("/throttle", func(w http.ResponseWriter, r *http.Request) {
+        state := normalizer.ThrottleState{}
+        if backpressure != nil {
+            state = backpressure.State()
+        }
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(state)
+    })
+
+    // Expose event disposition so losses can be reconciled per stage
+    http.HandleFunc("/ledger", func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(metrics.Ledger().Reconcile())
     })
     
     if err := http.ListenAndServe(fmt.Sprintf(":%d", port), nil); err != nil {
@@ -214,7 +684,6 @@ func createKafkaConfig(config *Config) *kafka.ConfigMap {
     kafkaConfig := &kafka.ConfigMap{
         "bootstrap.servers": config.KafkaBrokers,
         "group.id":         config.ConsumerGroup,
-        "auto.offset.reset": "earliest",
     }
 
     if config.Security.TLSEnabled {
@@ -19,6 +19,9 @@ const (
     // Maximum events to process in a single correlation
     maxEventsPerCorrelation = 1000
 
+    // Default cap on events retained in one correlation window
+    defaultMaxWindowEvents = maxEventsPerCorrelation
+
     // Worker pool size for parallel correlation
     workerPoolSize = 4
 )
@@ -40,12 +43,49 @@ type CorrelationRule interface {
     Validate() error
 }
 
+// WindowedRule is implemented by correlation rules that need their own
+// lookback window instead of the correlator default
+type WindowedRule interface {
+    Window() time.Duration
+}
+
+// windowedRule attaches a lookback window to a correlation rule
+type windowedRule struct {
+    CorrelationRule
+    window time.Duration
+}
+
+// WithWindow gives a correlation rule its own lookback window, e.g. minutes
+// for brute force detection and hours for slow exfiltration
+func WithWindow(rule CorrelationRule, window time.Duration) CorrelationRule {
+    return &windowedRule{CorrelationRule: rule, window: window}
+}
+
+// Window returns the rule's lookback window
+func (r *windowedRule) Window() time.Duration {
+    return r.window
+}
+
+// Validate checks the window and the wrapped rule
+func (r *windowedRule) Validate() error {
+    if r.window <= 0 {
+        return errors.NewError("E3001", "correlation window must be positive", map[string]interface{}{
+            "window": r.window,
+        })
+    }
+    return r.CorrelationRule.Validate()
+}
+
 // EventCorrelator manages event correlation with enhanced security features
 type EventCorrelator struct {
     rules           map[string]CorrelationRule
     correlationWindow time.Duration
+    maxWindowEvents int
     metrics         map[string]*metrics.KubernetesMetric
     securityContext SecurityContext
+    suppressor      *AlertSuppressor
+    cache           *correlationCache
+    fanOut          FanOutConfig
     mutex           sync.RWMutex
 }
 
@@ -57,7 +97,7 @@ func NewEventCorrelator(window time.Duration, secCtx SecurityContext) (*EventCor
 
     // Initialize Kubernetes-aware metrics
     correlationMetrics := make(map[string]*metrics.KubernetesMetric)
-    metricTypes := []string{"events_processed", "alerts_generated", "correlation_latency"}
+    metricTypes := []string{"events_processed", "alerts_generated", "correlation_latency", "alerts_suppressed", "suppressed_storms", "cache_hits", "cache_misses", "group_overflows", "candidate_overflows", "overflow_events_dropped"}
     
     for _, mType := range metricTypes {
         metric, err := metrics.NewMetric(
@@ -75,6 +115,7 @@ func NewEventCorrelator(window time.Duration, secCtx SecurityContext) (*EventCor
     return &EventCorrelator{
         rules:            make(map[string]CorrelationRule),
         correlationWindow: window,
+        maxWindowEvents:  defaultMaxWindowEvents,
         metrics:          correlationMetrics,
         securityContext:  secCtx,
     }, nil
@@ -91,24 +132,108 @@ func (ec *EventCorrelator) RegisterRule(ruleID string, rule CorrelationRule) err
     ec.mutex.Lock()
     defer ec.mutex.Unlock()
     ec.rules[ruleID] = rule
+
+    // Results computed by a replaced rule must not be reused
+    if ec.cache != nil {
+        ec.cache.clear()
+    }
+    return nil
+}
+
+// SetMaxWindowEvents caps the events retained in one correlation window. When
+// a window fills, its oldest events are evicted first.
+func (ec *EventCorrelator) SetMaxWindowEvents(max int) error {
+    if max <= 0 {
+        return errors.NewError("E3001", "maximum window events must be positive", map[string]interface{}{
+            "max_window_events": max,
+        })
+    }
+
+    ec.mutex.Lock()
+    defer ec.mutex.Unlock()
+    ec.maxWindowEvents = max
     return nil
 }
 
+// clientLabel caps the distinct client_id values recorded by correlation metrics
+func (ec *EventCorrelator) clientLabel() string {
+    return metrics.Guard("blackpoint_analyzer_correlation").Value("client_id", ec.securityContext.ClientID)
+}
+
+// ruleWindow returns a rule's own window, or the correlator default
+func (ec *EventCorrelator) ruleWindow(rule CorrelationRule) time.Duration {
+    if windowed, ok := rule.(WindowedRule); ok && windowed.Window() > 0 {
+        return windowed.Window()
+    }
+    return ec.correlationWindow
+}
+
+// SetResultCache configures reuse of correlation results for identical event sets
+func (ec *EventCorrelator) SetResultCache(config CorrelationCacheConfig) {
+    ec.mutex.Lock()
+    defer ec.mutex.Unlock()
+
+    if !config.Enabled {
+        ec.cache = nil
+        return
+    }
+    ec.cache = newCorrelationCache(config)
+}
+
+// SetSuppression configures root-cause suppression of repeated alerts
+func (ec *EventCorrelator) SetSuppression(config SuppressionConfig) {
+    ec.mutex.Lock()
+    defer ec.mutex.Unlock()
+
+    if !config.Enabled {
+        ec.suppressor = nil
+        return
+    }
+    ec.suppressor = NewAlertSuppressor(config)
+}
+
 // CorrelateEvents processes security events and generates alerts
 func (ec *EventCorrelator) CorrelateEvents(ctx context.Context, events []*silver.SilverEvent) ([]*gold.Alert, error) {
     if len(events) == 0 {
         return nil, nil
     }
 
+    metrics.RecordIngested(metrics.StageAnalyze, len(events))
+
     if len(events) > maxEventsPerCorrelation {
+        metrics.RecordLoss(metrics.StageAnalyze, metrics.LossValidationReject, len(events))
         return nil, errors.NewError("E3001", "event batch size exceeds limit", map[string]interface{}{
             "max_size": maxEventsPerCorrelation,
             "actual_size": len(events),
         })
     }
 
-    // Group events by time window
-    eventGroups := ec.groupEventsByWindow(events)
+    ec.mutex.RLock()
+    rules := make(map[string]CorrelationRule, len(ec.rules))
+    for ruleID, rule := range ec.rules {
+        rules[ruleID] = rule
+    }
+    ec.mutex.RUnlock()
+
+    // Group events by each rule's time window; rules sharing a window share groups
+    type correlationTask struct {
+        ruleID string
+        rule   CorrelationRule
+        events []*silver.SilverEvent
+    }
+    groupsByWindow := make(map[time.Duration][][]*silver.SilverEvent)
+    var tasks []correlationTask
+    for ruleID, rule := range rules {
+        window := ec.ruleWindow(rule)
+        groups, grouped := groupsByWindow[window]
+        if !grouped {
+            groups = ec.limitFanOut(ec.groupEventsByWindow(events, window))
+            groupsByWindow[window] = groups
+        }
+        for _, group := range groups {
+            tasks = append(tasks, correlationTask{ruleID: ruleID, rule: rule, events: group})
+        }
+    }
 
     // Create worker pool for parallel correlation
     type correlationResult struct {
@@ -116,21 +241,21 @@ func (ec *EventCorrelator) CorrelateEvents(ctx context.Context, events []*silver
         err    error
     }
 
-    resultChan := make(chan correlationResult, len(eventGroups))
+    resultChan := make(chan correlationResult, len(tasks))
     workerPool := make(chan struct{}, workerPoolSize)
 
-    // Process event groups concurrently
+    // Process rule groups concurrently
     var wg sync.WaitGroup
-    for _, group := range eventGroups {
+    for _, task := range tasks {
         wg.Add(1)
-        go func(events []*silver.SilverEvent) {
+        go func(task correlationTask) {
             defer wg.Done()
             workerPool <- struct{}{} // Acquire worker
             defer func() { <-workerPool }() // Release worker
 
-            alerts, err := ec.correlateEventGroup(ctx, events)
+            alerts, err := ec.correlateEventGroup(ctx, task.ruleID, task.rule, task.events)
             resultChan <- correlationResult{alerts: alerts, err: err}
-        }(group)
+        }(task)
     }
 
     // Wait for all correlations to complete
@@ -143,70 +268,145 @@ func (ec *EventCorrelator) CorrelateEvents(ctx context.Context, events []*silver
     var alerts []*gold.Alert
     for result := range resultChan {
         if result.err != nil {
+            metrics.RecordLoss(metrics.StageAnalyze, metrics.LossAnalysisFailure, len(events))
             return nil, result.err
         }
         alerts = append(alerts, result.alerts...)
     }
 
+    metrics.RecordProduced(metrics.StageAnalyze, len(events))
+    metrics.RecordAlerted(len(alerts))
+
+    // Storms whose cooldown has elapsed are reported as aggregate records
+    ec.mutex.RLock()
+    suppressor := ec.suppressor
+    ec.mutex.RUnlock()
+    if suppressor != nil {
+        alerts = append(alerts, suppressor.Expire(time.Now())...)
+    }
+
     // Update metrics
     ec.metrics["events_processed"].Inc(map[string]string{
-        "client_id": ec.securityContext.ClientID,
+        "client_id": ec.clientLabel(),
     })
     ec.metrics["alerts_generated"].Add(float64(len(alerts)), map[string]string{
-        "client_id": ec.securityContext.ClientID,
+        "client_id": ec.clientLabel(),
     })
 
     return alerts, nil
 }
 
-// correlateEventGroup applies correlation rules to a group of events
-func (ec *EventCorrelator) correlateEventGroup(ctx context.Context, events []*silver.SilverEvent) ([]*gold.Alert, error) {
-    var alerts []*gold.Alert
-
+// correlateEventGroup applies a correlation rule to a group of events from its window
+func (ec *EventCorrelator) correlateEventGroup(ctx context.Context, ruleID string, rule CorrelationRule, events []*silver.SilverEvent) ([]*gold.Alert, error) {
     ec.mutex.RLock()
     defer ec.mutex.RUnlock()
 
-    for ruleID, rule := range ec.rules {
-        select {
-        case <-ctx.Done():
-            return nil, errors.NewError("E4001", "correlation timeout", nil)
-        default:
-            alert, err := rule.Correlate(events, ec.securityContext)
-            if err != nil {
-                return nil, errors.WrapError(err, "rule correlation failed", map[string]interface{}{
-                    "rule_id": ruleID,
-                })
-            }
-            if alert != nil {
-                alerts = append(alerts, alert)
-                ec.metrics["correlation_latency"].Observe(time.Since(events[0].EventTime).Seconds(), map[string]string{
-                    "rule_id": ruleID,
-                    "severity": alert.Severity,
-                })
-            }
-        }
+    select {
+    case <-ctx.Done():
+        return nil, errors.NewError("E4001", "correlation timeout", nil)
+    default:
     }
 
-    return alerts, nil
+    alert, err := ec.correlateWithCache(ruleID, rule, events)
+    if err != nil {
+        return nil, errors.WrapError(err, "rule correlation failed", map[string]interface{}{
+            "rule_id": ruleID,
+        })
+    }
+    if alert == nil || !ec.admitAlert(ruleID, alert, len(events)) {
+        return nil, nil
+    }
+
+    ec.metrics["correlation_latency"].Observe(time.Since(events[0].EventTime).Seconds(), map[string]string{
+        "rule_id": ruleID,
+        "severity": alert.Severity,
+    })
+    return []*gold.Alert{alert}, nil
 }
 
-// groupEventsByWindow groups events into time-based windows
-func (ec *EventCorrelator) groupEventsByWindow(events []*silver.SilverEvent) [][]*silver.SilverEvent {
+// correlateWithCache evaluates a rule, skipping event sets the rule found not
+// to correlate within the cache TTL. Sets that raised an alert are evaluated
+// again, so each alert returned is newly created.
+func (ec *EventCorrelator) correlateWithCache(ruleID string, rule CorrelationRule, events []*silver.SilverEvent) (*gold.Alert, error) {
+    if ec.cache == nil {
+        return rule.Correlate(events, ec.securityContext)
+    }
+
+    labels := map[string]string{
+        "client_id": ec.clientLabel(),
+        "rule_id":   ruleID,
+    }
+
+    now := time.Now()
+    key := correlationKey(ruleID, events)
+    if ec.cache.uncorrelated(key, now) {
+        ec.metrics["cache_hits"].Inc(labels)
+        return nil, nil
+    }
+    ec.metrics["cache_misses"].Inc(labels)
+
+    alert, err := rule.Correlate(events, ec.securityContext)
+    if err != nil {
+        // Failures are not cached so transient errors are retried
+        return nil, err
+    }
+    if alert == nil {
+        ec.cache.putUncorrelated(key, now)
+    }
+    return alert, nil
+}
+
+// admitAlert applies root-cause suppression and records suppression metrics
+func (ec *EventCorrelator) admitAlert(ruleID string, alert *gold.Alert, eventCount int) bool {
+    if ec.suppressor == nil {
+        return true
+    }
+
+    admitted, stormStarted := ec.suppressor.Admit(ruleID, alert, eventCount, time.Now())
+    if admitted {
+        return true
+    }
+
+    labels := map[string]string{
+        "client_id": ec.clientLabel(),
+        "rule_id":   ruleID,
+    }
+    ec.metrics["alerts_suppressed"].Inc(labels)
+    if stormStarted {
+        ec.metrics["suppressed_storms"].Inc(labels)
+    }
+    return false
+}
+
+// groupEventsByWindow groups events into time-based windows of the given
+// length. A window holding more than the maximum window events evicts its
+// oldest events first.
+func (ec *EventCorrelator) groupEventsByWindow(events []*silver.SilverEvent, window time.Duration) [][]*silver.SilverEvent {
     if len(events) == 0 {
         return nil
     }
 
+    ec.mutex.RLock()
+    maxEvents := ec.maxWindowEvents
+    ec.mutex.RUnlock()
+
     var groups [][]*silver.SilverEvent
+    evicted := 0
     currentGroup := []*silver.SilverEvent{events[0]}
     windowStart := events[0].EventTime
 
     for i := 1; i < len(events); i++ {
-        if events[i].EventTime.Sub(windowStart) > ec.correlationWindow {
+        if events[i].EventTime.Sub(windowStart) > window {
             groups = append(groups, currentGroup)
             currentGroup = []*silver.SilverEvent{events[i]}
             windowStart = events[i].EventTime
-        } else {
-            currentGroup = append(currentGroup, events[i])
+            continue
+        }
+
+        currentGroup = append(currentGroup, events[i])
+        if len(currentGroup) > maxEvents {
+            currentGroup = currentGroup[1:]
+            evicted++
         }
     }
 
@@ -214,5 +414,11 @@ func (ec *EventCorrelator) groupEventsByWindow(events []*silver.SilverEvent) [][
         groups = append(groups, currentGroup)
     }
 
+    if evicted > 0 {
+        ec.metrics["overflow_events_dropped"].Add(float64(evicted), map[string]string{
+            "client_id": ec.clientLabel(),
+        })
+    }
+
     return groups
 }
\ No newline at end of file
@@ -0,0 +1,123 @@
+// Package analyzer implements caching of correlation results for repeated event sets
+package analyzer
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/blackpoint/pkg/silver"
+)
+
+const (
+    // Default lifetime of a cached correlation result
+    defaultCorrelationCacheTTL = 30 * time.Second
+
+    // Default maximum number of cached results
+    defaultCorrelationCacheSize = 10000
+)
+
+// CorrelationCacheConfig configures reuse of "no correlation" results
+type CorrelationCacheConfig struct {
+    // Enabled turns the result cache on
+    Enabled bool
+
+    // TTL bounds how long a result is reused; keep it shorter than the correlation window
+    TTL time.Duration
+
+    // MaxEntries caps the number of cached results
+    MaxEntries int
+}
+
+// correlationCache remembers rule and event-set fingerprints that did not
+// correlate, the common case worth skipping, until they expire. Alerts are
+// never cached: a cached alert would be the one already emitted, and an
+// event set that raised an alert is evaluated again so a redelivered batch
+// raises it again.
+type correlationCache struct {
+    entries    map[string]time.Time
+    ttl        time.Duration
+    maxEntries int
+    mu         sync.Mutex
+}
+
+// newCorrelationCache creates a cache with defaults applied
+func newCorrelationCache(config CorrelationCacheConfig) *correlationCache {
+    if config.TTL <= 0 {
+        config.TTL = defaultCorrelationCacheTTL
+    }
+    if config.MaxEntries <= 0 {
+        config.MaxEntries = defaultCorrelationCacheSize
+    }
+
+    return &correlationCache{
+        entries:    make(map[string]time.Time),
+        ttl:        config.TTL,
+        maxEntries: config.MaxEntries,
+    }
+}
+
+// uncorrelated reports whether the key is cached as not correlating
+func (c *correlationCache) uncorrelated(key string, now time.Time) bool {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    expires, ok := c.entries[key]
+    if !ok {
+        return false
+    }
+    if now.After(expires) {
+        delete(c.entries, key)
+        return false
+    }
+    return true
+}
+
+// putUncorrelated caches a "no correlation" result. When the cache is full,
+// expired entries are swept first; if it is still full the result is simply
+// not cached.
+func (c *correlationCache) putUncorrelated(key string, now time.Time) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if len(c.entries) >= c.maxEntries {
+        for k, expires := range c.entries {
+            if now.After(expires) {
+                delete(c.entries, k)
+            }
+        }
+        if len(c.entries) >= c.maxEntries {
+            return
+        }
+    }
+
+    c.entries[key] = now.Add(c.ttl)
+}
+
+// clear drops every cached result, e.g. after rules change
+func (c *correlationCache) clear() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.entries = make(map[string]time.Time)
+}
+
+// correlationKey identifies a rule evaluation over a specific set of events.
+// Event order does not affect the key so overlapping windows that select the
+// same events share a result.
+func correlationKey(ruleID string, events []*silver.SilverEvent) string {
+    ids := make([]string, len(events))
+    for i, event := range events {
+        ids[i] = event.EventID
+    }
+    sort.Strings(ids)
+
+    h := sha256.New()
+    h.Write([]byte(ruleID))
+    for _, id := range ids {
+        h.Write([]byte{0})
+        h.Write([]byte(id))
+    }
+    return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,130 @@
+// Package analyzer implements fan-out limits for event correlation
+package analyzer
+
+import (
+    "github.com/blackpoint/pkg/silver"
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// Overflow policies applied when correlation limits are exceeded
+const (
+    // FanOutPolicyTruncate drops the oldest events and candidate groups
+    // beyond the limits
+    FanOutPolicyTruncate = "truncate"
+
+    // FanOutPolicyMerge collapses the oldest surplus candidate groups into a
+    // single group before the group size limit is applied, so every window
+    // keeps its most recent evidence
+    FanOutPolicyMerge = "merge"
+)
+
+// FanOutConfig bounds how much work a single correlation batch can create.
+// Zero limits are unbounded.
+//
+// Both policies keep the most recent events: a group larger than MaxGroupSize
+// retains its newest MaxGroupSize events. When there are more candidate groups
+// than MaxCandidateGroups, truncate discards the oldest groups while merge
+// folds them into one group.
+type FanOutConfig struct {
+    // MaxGroupSize caps the number of events evaluated together by a rule
+    MaxGroupSize int
+
+    // MaxCandidateGroups caps the number of groups evaluated per batch
+    MaxCandidateGroups int
+
+    // Policy is FanOutPolicyTruncate (default) or FanOutPolicyMerge
+    Policy string
+}
+
+// fanOutResult reports which limits were hit while shaping candidate groups
+type fanOutResult struct {
+    groups          [][]*silver.SilverEvent
+    groupOverflows  int
+    groupsOverflown bool
+    eventsDropped   int
+}
+
+// SetFanOutLimits configures the maximum group size and candidate groups
+func (ec *EventCorrelator) SetFanOutLimits(config FanOutConfig) error {
+    if config.MaxGroupSize < 0 || config.MaxCandidateGroups < 0 {
+        return errors.NewError("E3001", "correlation limits must not be negative", map[string]interface{}{
+            "max_group_size":       config.MaxGroupSize,
+            "max_candidate_groups": config.MaxCandidateGroups,
+        })
+    }
+
+    switch config.Policy {
+    case "":
+        config.Policy = FanOutPolicyTruncate
+    case FanOutPolicyTruncate, FanOutPolicyMerge:
+    default:
+        return errors.NewError("E3001", "unsupported correlation overflow policy", map[string]interface{}{
+            "policy": config.Policy,
+        })
+    }
+
+    ec.mutex.Lock()
+    defer ec.mutex.Unlock()
+    ec.fanOut = config
+    return nil
+}
+
+// limitFanOut applies the configured limits to window groups and records
+// overflow metrics
+func (ec *EventCorrelator) limitFanOut(groups [][]*silver.SilverEvent) [][]*silver.SilverEvent {
+    ec.mutex.RLock()
+    config := ec.fanOut
+    ec.mutex.RUnlock()
+
+    result := applyFanOutLimits(groups, config)
+
+    labels := map[string]string{
+        "client_id": ec.securityContext.ClientID,
+    }
+    if result.groupsOverflown {
+        ec.metrics["candidate_overflows"].Inc(labels)
+    }
+    if result.groupOverflows > 0 {
+        ec.metrics["group_overflows"].Add(float64(result.groupOverflows), labels)
+    }
+    if result.eventsDropped > 0 {
+        ec.metrics["overflow_events_dropped"].Add(float64(result.eventsDropped), labels)
+    }
+
+    return result.groups
+}
+
+// applyFanOutLimits shapes chronologically ordered groups to the limits
+func applyFanOutLimits(groups [][]*silver.SilverEvent, config FanOutConfig) fanOutResult {
+    result := fanOutResult{groups: groups}
+
+    if config.MaxCandidateGroups > 0 && len(groups) > config.MaxCandidateGroups {
+        result.groupsOverflown = true
+        surplus := len(groups) - config.MaxCandidateGroups
+
+        if config.Policy == FanOutPolicyMerge {
+            var merged []*silver.SilverEvent
+            for _, group := range groups[:surplus+1] {
+                merged = append(merged, group...)
+            }
+            result.groups = append([][]*silver.SilverEvent{merged}, groups[surplus+1:]...)
+        } else {
+            for _, group := range groups[:surplus] {
+                result.eventsDropped += len(group)
+            }
+            result.groups = groups[surplus:]
+        }
+    }
+
+    if config.MaxGroupSize > 0 {
+        for i, group := range result.groups {
+            if len(group) > config.MaxGroupSize {
+                result.groupOverflows++
+                result.eventsDropped += len(group) - config.MaxGroupSize
+                result.groups[i] = group[len(group)-config.MaxGroupSize:]
+            }
+        }
+    }
+
+    return result
+}
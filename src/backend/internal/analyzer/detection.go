@@ -3,6 +3,12 @@ package analyzer
 
 import (
     "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "hash/fnv"
+    "sort"
+    "strings"
     "sync"
     "time"
 
@@ -10,14 +16,13 @@ import (
     "github.com/blackpoint/pkg/silver"
     "github.com/blackpoint/pkg/gold"
     "github.com/blackpoint/metrics"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
 )
 
 // Global variables for detection management
 var (
-    // Thread-safe map of detection rules
-    detectionRules = make(map[string]DetectionRule)
-    ruleLock      sync.RWMutex
-
     // Detection timeout configuration
     detectionTimeout = 30 * time.Second
 
@@ -30,6 +35,9 @@ var (
         "component": "analyzer",
         "tier":      "gold",
     }
+
+    // Detection spans continue the trace carried by the Silver event's message
+    tracer = otel.Tracer("analyzer.detection")
 )
 
 // DetectionRule defines the interface for implementing threat detection rules
@@ -38,6 +46,70 @@ type DetectionRule interface {
     Detect(event *silver.SilverEvent) (bool, float64, map[string]interface{})
 }
 
+// AnomalyScorer scores events for statistical or model-based detection,
+// complementing the rule-based DetectionRule checks
+type AnomalyScorer interface {
+    // Score returns an anomaly score in [0, 1] and the features that produced it
+    Score(event *silver.SilverEvent) (float64, map[string]interface{})
+}
+
+// anomalyHook holds the configured scorer and its alert threshold
+type anomalyHook struct {
+    scorer    AnomalyScorer
+    threshold float64
+}
+
+var (
+    anomalyLock sync.RWMutex
+    anomaly     *anomalyHook
+)
+
+// SetAnomalyScorer configures a scorer that DetectThreats invokes alongside
+// the registered rules. A score above threshold raises an alert on its own.
+// A nil scorer disables anomaly scoring.
+func SetAnomalyScorer(scorer AnomalyScorer, threshold float64) error {
+    if scorer != nil && (threshold < 0 || threshold > 1) {
+        return errors.NewError("E3001", "anomaly threshold must be in [0, 1]", map[string]interface{}{
+            "threshold": threshold,
+        })
+    }
+
+    anomalyLock.Lock()
+    defer anomalyLock.Unlock()
+    if scorer == nil {
+        anomaly = nil
+        return nil
+    }
+    anomaly = &anomalyHook{scorer: scorer, threshold: threshold}
+    return nil
+}
+
+// RegisterDetectionRule adds or replaces a detection rule evaluated by DetectThreats
+func RegisterDetectionRule(ruleID string, rule DetectionRule) error {
+    return defaultRuleRegistry.Register(ruleID, rule)
+}
+
+// UnregisterDetectionRule removes a detection rule and its sampling configuration
+func UnregisterDetectionRule(ruleID string) {
+    defaultRuleRegistry.Unregister(ruleID)
+}
+
+// SetRuleSampleRate limits an expensive rule to evaluating the given fraction of
+// events. A rate of 1 removes sampling so the rule runs on every event.
+func SetRuleSampleRate(ruleID string, rate float64) error {
+    return defaultRuleRegistry.SetSampleRate(ruleID, rate)
+}
+
+// sampledIn decides whether a sampled rule evaluates the event. The decision is
+// derived from the rule and event IDs so it is stable across retries and replicas.
+func sampledIn(ruleID string, event *silver.SilverEvent, rate float64) bool {
+    h := fnv.New64a()
+    h.Write([]byte(ruleID))
+    h.Write([]byte{0})
+    h.Write([]byte(event.EventID))
+    return float64(h.Sum64()%10000) < rate*10000
+}
+
 // DetectThreats analyzes normalized security events for potential threats
 // @metrics.Record
 // @audit.Log
@@ -47,6 +119,13 @@ func DetectThreats(ctx context.Context, event *silver.SilverEvent) (*gold.Alert,
         return nil, errors.NewError("E3001", "nil event", nil)
     }
 
+    ctx, span := tracer.Start(ctx, "detect_threats")
+    defer span.End()
+    span.SetAttributes(
+        attribute.String("event_id", event.EventID),
+        attribute.String("client_id", event.ClientID),
+    )
+
     // Apply rate limiting
     select {
     case workerPool <- struct{}{}:
@@ -63,23 +142,31 @@ func DetectThreats(ctx context.Context, event *silver.SilverEvent) (*gold.Alert,
     detectionCtx, cancel := context.WithTimeout(ctx, detectionTimeout)
     defer cancel()
 
-    // Apply detection rules
-    ruleLock.RLock()
-    rules := make([]DetectionRule, 0, len(detectionRules))
-    for _, rule := range detectionRules {
-        rules = append(rules, rule)
-    }
-    ruleLock.RUnlock()
+    // Evaluate one snapshot so a concurrent reload never yields a mixed rule set
+    snapshot := defaultRuleRegistry.snapshot()
+    rules, sampleRates := snapshot.rules, snapshot.sampleRates
 
     // Track detection results
     var (
         maxSeverity     float64
         detectionData   = make(map[string]interface{})
         threatDetected  bool
+        sampledOut      []string
+        triggeredRules  []string
     )
 
     // Process each rule with timeout
-    for _, rule := range rules {
+    for ruleID, rule := range rules {
+        if rate, sampled := sampleRates[ruleID]; sampled && !sampledIn(ruleID, event, rate) {
+            sampledOut = append(sampledOut, ruleID)
+            metrics.Increment("rule_evaluations_sampled_out", map[string]string{
+                "component": metricsTags["component"],
+                "tier":      metricsTags["tier"],
+                "rule_id":   ruleID,
+            })
+            continue
+        }
+
         select {
         case <-detectionCtx.Done():
             return nil, errors.NewError("E4002", "detection timeout", map[string]interface{}{
@@ -89,6 +176,7 @@ func DetectThreats(ctx context.Context, event *silver.SilverEvent) (*gold.Alert,
             detected, severity, metadata := rule.Detect(event)
             if detected {
                 threatDetected = true
+                triggeredRules = append(triggeredRules, ruleID)
                 if severity > maxSeverity {
                     maxSeverity = severity
                 }
@@ -99,12 +187,49 @@ func DetectThreats(ctx context.Context, event *silver.SilverEvent) (*gold.Alert,
         }
     }
 
+    // Score the event with the anomaly scorer when one is configured
+    anomalyLock.RLock()
+    hook := anomaly
+    anomalyLock.RUnlock()
+    if hook != nil {
+        score, features := hook.scorer.Score(event)
+        detectionData["anomaly_score"] = score
+        detectionData["anomaly_features"] = features
+        if score > hook.threshold {
+            threatDetected = true
+            if score > maxSeverity {
+                maxSeverity = score
+            }
+            metrics.Increment("anomalies_detected", metricsTags)
+        }
+    }
+
     // If no threat detected, return nil
     if !threatDetected {
         metrics.Increment("events_no_threat", metricsTags)
         return nil, nil
     }
 
+    // Record which rules fired so severity overrides can apply to them
+    if len(triggeredRules) > 0 {
+        sort.Strings(triggeredRules)
+        detectionData["triggered_rules"] = triggeredRules
+    }
+
+    // rule_id and entity form the alert's deduplication signature
+    detectionData["rule_id"] = anomalyRuleID
+    if len(triggeredRules) > 0 {
+        detectionData["rule_id"] = strings.Join(triggeredRules, ",")
+    }
+    if _, ok := detectionData["entity"]; !ok {
+        detectionData["entity"] = detectionEntity(event)
+    }
+
+    // Record which rules skipped this event so analysts know coverage was partial
+    if len(sampledOut) > 0 {
+        detectionData["sampled_out_rules"] = sampledOut
+    }
+
     // Create security context for alert
     securityCtx := &gold.SecurityMetadata{
         Classification:   "security_alert",
@@ -118,6 +243,7 @@ func DetectThreats(ctx context.Context, event *silver.SilverEvent) (*gold.Alert,
     alert, err := gold.CreateAlert(&gold.GoldEvent{
         Severity:         securityCtx.ThreatLevel,
         IntelligenceData: detectionData,
+        SecurityMetadata: *securityCtx,
         ComplianceInfo: gold.ComplianceMetadata{
             Standards:     []string{"SOC2", "ISO27001"},
             DataRetention: "90d",
@@ -130,68 +256,119 @@ func DetectThreats(ctx context.Context, event *silver.SilverEvent) (*gold.Alert,
         return nil, errors.WrapError(err, "failed to create alert", nil)
     }
 
+    if spanContext := span.SpanContext(); spanContext.IsValid() {
+        alert.TraceID = spanContext.TraceID().String()
+    }
+    span.SetAttributes(attribute.String("alert_id", alert.AlertID))
+
     metrics.Increment("threats_detected", metricsTags)
     return alert, nil
 }
 
+// eventTracesKey carries the trace context of each event of a batch
+type eventTracesKey struct{}
+
+// WithEventTraces returns a context under which BatchDetection continues a
+// separate trace for each event: traces[i] is the parent of events[i]'s
+// detection span, such as the trace its Silver message was published under
+func WithEventTraces(ctx context.Context, traces []context.Context) context.Context {
+    return context.WithValue(ctx, eventTracesKey{}, traces)
+}
+
+// anomalyRuleID identifies alerts raised by the anomaly scorer alone
+const anomalyRuleID = "anomaly"
+
+// entityFields are the normalized fields identifying who or what an event is
+// about, most specific first
+var entityFields = []string{
+    "user.name", "source.user.name", "src_user", "user", "user_id",
+    "host.name", "hostname",
+    "source.ip", "src_ip", "source_ip", "host.ip", "client.ip",
+}
+
+// detectionEntity identifies the subject of an event within its client. The
+// identifying value is hashed so the unencrypted signature carries no PII.
+func detectionEntity(event *silver.SilverEvent) string {
+    for _, field := range entityFields {
+        value, ok := event.NormalizedData[field]
+        if !ok || value == nil {
+            continue
+        }
+        if text := fmt.Sprint(value); text != "" {
+            sum := sha256.Sum256([]byte(text))
+            return event.ClientID + ":" + hex.EncodeToString(sum[:8])
+        }
+    }
+    return event.ClientID
+}
+
 // BatchDetection processes multiple events for threat detection concurrently
 // @metrics.RecordBatch
 // @audit.LogBatch
 func BatchDetection(ctx context.Context, events []*silver.SilverEvent) ([]*gold.Alert, []error) {
+    results, resultErrs := detectEach(ctx, events)
+
+    // Collect results
+    var (
+        alerts []*gold.Alert
+        errs   []error
+    )
+
+    for i := range results {
+        if resultErrs[i] != nil {
+            errs = append(errs, resultErrs[i])
+        } else if results[i] != nil {
+            alerts = append(alerts, results[i])
+        }
+    }
+
+    return alerts, errs
+}
+
+// detectEach runs detection on every event concurrently. Results line up
+// with events: alerts[i] is nil when events[i] raised no alert.
+func detectEach(ctx context.Context, events []*silver.SilverEvent) ([]*gold.Alert, []error) {
+    alerts := make([]*gold.Alert, len(events))
+    errs := make([]error, len(events))
     if len(events) == 0 {
-        return nil, nil
+        return alerts, errs
+    }
+
+    traces, _ := ctx.Value(eventTracesKey{}).([]context.Context)
+    if len(traces) != len(events) {
+        traces = nil
     }
 
     // Create worker pool for concurrent processing
     numWorkers := min(len(events), maxConcurrentDetections)
-    jobs := make(chan *silver.SilverEvent, len(events))
-    results := make(chan struct {
-        alert *gold.Alert
-        err   error
-    }, len(events))
+    jobs := make(chan int, len(events))
 
-    // Start worker pool
+    // Each worker writes only its own index, so no locking is needed
     var wg sync.WaitGroup
     for i := 0; i < numWorkers; i++ {
         wg.Add(1)
         go func() {
             defer wg.Done()
-            for event := range jobs {
-                alert, err := DetectThreats(ctx, event)
-                results <- struct {
-                    alert *gold.Alert
-                    err   error
-                }{alert, err}
+            for idx := range jobs {
+                eventCtx := ctx
+                if traces != nil {
+                    if parent := trace.SpanContextFromContext(traces[idx]); parent.IsValid() {
+                        eventCtx = trace.ContextWithSpanContext(ctx, parent)
+                    }
+                }
+                alerts[idx], errs[idx] = DetectThreats(eventCtx, events[idx])
             }
         }()
     }
 
     // Send jobs to workers
-    for _, event := range events {
-        jobs <- event
+    for i := range events {
+        jobs <- i
     }
     close(jobs)
 
     // Wait for all workers to complete
-    go func() {
-        wg.Wait()
-        close(results)
-    }()
-
-    // Collect results
-    var (
-        alerts []*gold.Alert
-        errs   []error
-    )
-
-    for result := range results {
-        if result.err != nil {
-            errs = append(errs, result.err)
-        } else if result.alert != nil {
-            alerts = append(alerts, result.alert)
-        }
-    }
-
+    wg.Wait()
     return alerts, errs
 }
 
@@ -9,6 +9,7 @@ import (
     "github.com/blackpoint/pkg/gold"
     "github.com/blackpoint/pkg/silver"
     "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/internal/metrics"
     "./correlation"
     "k8s.io/metrics/pkg/client/clientset/versioned"
 )
@@ -202,22 +203,28 @@ func (e *IntelligenceEngine) validateCompliance(intelligence map[string]interfac
 
     // Update compliance metrics
     intelligenceMetrics["compliance_violations"].Inc(map[string]string{
-        "client_id": e.correlator.SecurityContext.ClientID,
+        "client_id": e.intelligenceClientLabel(),
     })
 
     return compliance
 }
 
+// intelligenceClientLabel caps the distinct client_id values recorded by
+// intelligence metrics
+func (e *IntelligenceEngine) intelligenceClientLabel() string {
+    return metrics.Guard("blackpoint_analyzer_intelligence").Value("client_id", e.correlator.SecurityContext.ClientID)
+}
+
 // updateMetrics updates Kubernetes-aware metrics for intelligence generation
 func (e *IntelligenceEngine) updateMetrics(intelligence map[string]interface{}) {
     intelligenceMetrics["intelligence_generated"].Inc(map[string]string{
-        "client_id": e.correlator.SecurityContext.ClientID,
+        "client_id": e.intelligenceClientLabel(),
     })
 
     intelligenceMetrics["processing_latency"].Observe(
         time.Since(time.Now()).Seconds(),
         map[string]string{
-            "client_id": e.correlator.SecurityContext.ClientID,
+            "client_id": e.intelligenceClientLabel(),
         },
     )
 }
\ No newline at end of file
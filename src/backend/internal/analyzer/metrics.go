@@ -6,6 +6,7 @@ import (
     "time"
 
     "github.com/blackpoint/pkg/common/logging"
+    "github.com/blackpoint/internal/metrics"
     "github.com/blackpoint/internal/metrics/telemetry"
     "github.com/prometheus/client_golang/prometheus" // v1.14.0
     "github.com/prometheus/client_golang/prometheus/promauto" // v1.14.0
@@ -40,6 +41,15 @@ var (
     metricMutex sync.RWMutex
 )
 
+// analyzerMetric names the cardinality guard shared by the analyzer's client metrics
+const analyzerMetric = "blackpoint_analyzer"
+
+// analyzerClientLabel caps the distinct client_id values recorded by analyzer
+// metrics; client IDs come from event data and are otherwise unbounded
+func analyzerClientLabel(clientID string) string {
+    return metrics.Guard(analyzerMetric).Value("client_id", clientID)
+}
+
 // InitMetrics initializes analyzer metrics collection with enhanced Kubernetes integration
 func InitMetrics(config telemetry.MetricConfig) error {
     metricMutex.Lock()
@@ -124,7 +134,7 @@ func RecordEventProcessing(clientID string, duration time.Duration, success bool
 
     // Update metric labels
     labels := prometheus.Labels{
-        "client_id":   clientID,
+        "client_id":   analyzerClientLabel(clientID),
         "pod_name":    metricLabels["pod_name"],
         "node_name":   metricLabels["node_name"],
         "environment": metricLabels["environment"],
@@ -135,7 +145,7 @@ func RecordEventProcessing(clientID string, duration time.Duration, success bool
 
     // Update events processed counter
     eventsProcessed.With(prometheus.Labels{
-        "client_id":   clientID,
+        "client_id":   analyzerClientLabel(clientID),
         "success":     string(success),
         "pod_name":    metricLabels["pod_name"],
         "environment": metricLabels["environment"],
@@ -155,14 +165,14 @@ func UpdateCorrelationAccuracy(clientID string, accuracy float64) {
 
     // Update correlation accuracy gauge
     correlationAccuracy.With(prometheus.Labels{
-        "client_id":   clientID,
+        "client_id":   analyzerClientLabel(clientID),
         "pod_name":    metricLabels["pod_name"],
         "environment": metricLabels["environment"],
     }).Set(accuracy)
 
     // Update active correlations
     activeCorrelations.With(prometheus.Labels{
-        "client_id":   clientID,
+        "client_id":   analyzerClientLabel(clientID),
         "pod_name":    metricLabels["pod_name"],
         "environment": metricLabels["environment"],
     }).Inc()
@@ -188,7 +198,7 @@ func RecordAlertGeneration(clientID string, severity string) {
 
     // Increment alerts generated counter
     alertsGenerated.With(prometheus.Labels{
-        "client_id":   clientID,
+        "client_id":   analyzerClientLabel(clientID),
         "severity":    severity,
         "pod_name":    metricLabels["pod_name"],
         "environment": metricLabels["environment"],
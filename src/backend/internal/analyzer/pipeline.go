@@ -0,0 +1,210 @@
+// Package analyzer provides the streaming Silver-to-Gold pipeline of the analyzer service
+package analyzer
+
+import (
+    "context"
+    "time"
+
+    "go.opentelemetry.io/otel/trace"
+
+    "github.com/blackpoint/internal/metrics"
+    "github.com/blackpoint/internal/streaming"
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+    "github.com/blackpoint/pkg/gold"
+    "github.com/blackpoint/pkg/silver"
+)
+
+// AlertPublisher publishes Gold alerts with per-alert message headers; the
+// streaming package's Producer satisfies it
+type AlertPublisher interface {
+    PublishValues(ctx context.Context, values []interface{}, headers []map[string]string) error
+}
+
+// Pipeline runs detection on consumed Silver messages and publishes the
+// resulting alerts. Its HandleBatch is installed as the consumer's batch
+// handler, so offsets are committed only once a batch's alerts are published.
+type Pipeline struct {
+    publisher AlertPublisher
+    router    *AlertRouter
+    dedup     *gold.AlertDeduplicator
+}
+
+// NewPipeline creates a pipeline publishing alerts to publisher
+func NewPipeline(publisher AlertPublisher) (*Pipeline, error) {
+    if publisher == nil {
+        return nil, errors.NewError("E2001", "analyzer pipeline requires an alert publisher", nil)
+    }
+    return &Pipeline{publisher: publisher}, nil
+}
+
+// NewRoutedPipeline creates a pipeline delivering each alert through router
+// to the destination configured for its event's client
+func NewRoutedPipeline(router *AlertRouter) (*Pipeline, error) {
+    if router == nil {
+        return nil, errors.NewError("E2001", "analyzer pipeline requires an alert router", nil)
+    }
+    return &Pipeline{router: router}, nil
+}
+
+// SetDeduplicator folds repeated alerts for the same rule and entity into the
+// first one published. Only new alerts are published; repeats update the
+// deduplicator's record. A nil deduplicator publishes every alert.
+func (p *Pipeline) SetDeduplicator(dedup *gold.AlertDeduplicator) {
+    p.dedup = dedup
+}
+
+// HandleBatch analyzes one consumed batch. Messages are decoded with the
+// consumer's Serializer and each event's detection continues the trace its
+// message was published under; every alert is published carrying that
+// trace. Messages that can never be decoded are logged and skipped. Any other
+// failure returns an error so the batch is retried and not committed.
+func (p *Pipeline) HandleBatch(ctx context.Context, messages []*streaming.Message) error {
+    decoded := make([]*streaming.Message, 0, len(messages))
+    events := make([]*silver.SilverEvent, 0, len(messages))
+    for _, msg := range messages {
+        var event silver.SilverEvent
+        if err := msg.Decode(&event); err != nil {
+            if errors.Classify(err) != errors.Permanent {
+                return errors.WrapError(err, "failed to decode Silver message", map[string]interface{}{
+                    "topic":     msg.Topic,
+                    "partition": msg.Partition,
+                    "offset":    msg.Offset,
+                })
+            }
+            metrics.RecordLostEvent(metrics.StageAnalyze, metrics.LossValidationReject, msg.ID())
+            logging.Error("Skipped undecodable Silver message", err,
+                logging.Field("topic", msg.Topic),
+                logging.Field("partition", msg.Partition),
+                logging.Field("offset", msg.Offset),
+            )
+            continue
+        }
+        decoded = append(decoded, msg)
+        events = append(events, &event)
+    }
+    if len(events) == 0 {
+        return nil
+    }
+
+    // Each event's span continues its message's trace and is the parent the
+    // alert message carries downstream
+    traces := make([]context.Context, len(decoded))
+    spans := make([]trace.Span, len(decoded))
+    for i, msg := range decoded {
+        traces[i], spans[i] = tracer.Start(msg.Context(), "analyze_message")
+    }
+    defer func() {
+        for _, span := range spans {
+            span.End()
+        }
+    }()
+
+    alerts, errs := detectEach(WithEventTraces(ctx, traces), events)
+
+    analyzed := make([]int, 0, len(alerts))
+    raised := make([]int, 0, len(alerts))
+    for i, alert := range alerts {
+        if errs[i] != nil {
+            // Capacity and timeout errors clear on redelivery
+            if errors.Classify(errs[i]) != errors.Permanent {
+                return errors.WrapError(errs[i], "failed to analyze Silver event", map[string]interface{}{
+                    "event_id": events[i].EventID,
+                })
+            }
+            metrics.RecordLostEvent(metrics.StageAnalyze, metrics.LossAnalysisFailure, events[i].EventID)
+            logging.Error("Skipped Silver event that failed analysis", errs[i],
+                logging.Field("event_id", events[i].EventID),
+            )
+            continue
+        }
+        analyzed = append(analyzed, i)
+        if alert != nil {
+            raised = append(raised, i)
+        }
+    }
+
+    // Deduplicate only once the batch is known to publish, so a retried
+    // batch's alerts are not mistaken for repeats of themselves
+    now := time.Now().UTC()
+    values := make([]interface{}, 0, len(raised))
+    headers := make([]map[string]string, 0, len(raised))
+    published := make([]*gold.Alert, 0, len(raised))
+    alerted := make([]string, 0, len(raised))
+    pending := make([]int, 0, len(raised))
+    for _, i := range raised {
+        if p.dedup != nil {
+            if _, isNew := p.dedup.Deduplicate(alerts[i], now); !isNew {
+                continue
+            }
+        }
+        pending = append(pending, i)
+        published = append(published, alerts[i])
+        alerted = append(alerted, events[i].EventID)
+        values = append(values, alerts[i])
+        headers = append(headers, streaming.InjectTraceContext(traces[i], nil))
+    }
+
+    if p.router != nil {
+        routed, err := p.route(ctx, spans, events, alerts, pending)
+        if err != nil {
+            return err
+        }
+        alerted = routed
+    } else if len(values) > 0 {
+        if err := p.publisher.PublishValues(ctx, values, headers); err != nil {
+            // The batch is redelivered, so its alerts must count as new again
+            if p.dedup != nil {
+                for _, alert := range published {
+                    p.dedup.Forget(alert)
+                }
+            }
+            return errors.WrapError(err, "failed to publish Gold alerts", map[string]interface{}{
+                "batch_size": len(values),
+            })
+        }
+    }
+
+    // Events and alerts are counted only once their alerts are delivered; a
+    // redelivered batch counts them once
+    for _, i := range analyzed {
+        metrics.RecordProducedEvent(metrics.StageAnalyze, events[i].EventID)
+    }
+    for _, eventID := range alerted {
+        metrics.RecordAlertedEvent(eventID)
+    }
+    return nil
+}
+
+// route delivers the pending alerts through the router, each under its
+// event's span. Alerts for clients without a route are recorded for replay
+// and skipped. On any other failure the alerts not yet delivered are
+// forgotten by the deduplicator so the redelivered batch routes only those
+// again. It returns the IDs of the events whose alerts were delivered.
+func (p *Pipeline) route(ctx context.Context, spans []trace.Span, events []*silver.SilverEvent, alerts []*gold.Alert, pending []int) ([]string, error) {
+    alerted := make([]string, 0, len(pending))
+    for n, i := range pending {
+        err := p.router.Route(trace.ContextWithSpan(ctx, spans[i]), events[i].ClientID, alerts[i])
+        if err == nil {
+            alerted = append(alerted, events[i].EventID)
+            continue
+        }
+        if errors.IsErrorCode(err, "E2001", "") {
+            logging.Error("Alert held for replay, no route configured for client", err,
+                logging.Field("client_id", events[i].ClientID),
+                logging.Field("alert_id", alerts[i].AlertID),
+            )
+            continue
+        }
+
+        if p.dedup != nil {
+            for _, j := range pending[n:] {
+                p.dedup.Forget(alerts[j])
+            }
+        }
+        return nil, errors.WrapError(err, "failed to route Gold alert", map[string]interface{}{
+            "event_id": events[i].EventID,
+        })
+    }
+    return alerted, nil
+}
@@ -0,0 +1,392 @@
+// Package analyzer implements the detection rule registry with atomic reload
+package analyzer
+
+import (
+    "context"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+    "github.com/prometheus/client_golang/prometheus"
+    "gopkg.in/yaml.v3"
+)
+
+// Rule reload results recorded by rulesReloaded
+const (
+    ReloadResultSuccess  = "success"
+    ReloadResultRejected = "rejected"
+)
+
+var (
+    rulesLoaded = prometheus.NewGauge(prometheus.GaugeOpts{
+        Name: "blackpoint_analyzer_rules_loaded",
+        Help: "Number of detection rules in the active rule set",
+    })
+
+    rulesReloaded = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "blackpoint_analyzer_rules_reload_total",
+        Help: "Total number of detection rule set reloads by result",
+    }, []string{"result"})
+)
+
+func init() {
+    prometheus.MustRegister(rulesLoaded, rulesReloaded)
+}
+
+// RuleDefinition declares a detection rule in configuration
+type RuleDefinition struct {
+    ID         string                 `yaml:"id" json:"id"`
+    Type       string                 `yaml:"type" json:"type"`
+    SampleRate float64                `yaml:"sample_rate" json:"sample_rate"`
+    Params     map[string]interface{} `yaml:"params" json:"params"`
+}
+
+// RuleSetFile is the on-disk format of a detection rule set
+type RuleSetFile struct {
+    Rules []RuleDefinition `yaml:"rules" json:"rules"`
+}
+
+// RuleCompiler builds a detection rule from its definition
+type RuleCompiler func(def RuleDefinition) (DetectionRule, error)
+
+// ruleSnapshot is an immutable rule set. A snapshot is never modified after
+// it is published, so readers need no lock.
+type ruleSnapshot struct {
+    rules       map[string]DetectionRule
+    sampleRates map[string]float64
+}
+
+// RuleRegistry holds the active detection rule set. Every change publishes a
+// new snapshot, so DetectThreats sees either the old or the new set in full.
+type RuleRegistry struct {
+    mu        sync.Mutex // serializes writers
+    current   atomic.Value
+    compilers map[string]RuleCompiler
+}
+
+// defaultRuleRegistry backs the package-level rule functions and DetectThreats
+var defaultRuleRegistry = NewRuleRegistry()
+
+// NewRuleRegistry creates an empty registry with the built-in rule compilers
+func NewRuleRegistry() *RuleRegistry {
+    r := &RuleRegistry{
+        compilers: map[string]RuleCompiler{
+            "threshold": compileThresholdRule,
+        },
+    }
+    r.current.Store(&ruleSnapshot{
+        rules:       make(map[string]DetectionRule),
+        sampleRates: make(map[string]float64),
+    })
+    return r
+}
+
+// Rules returns the registry used by DetectThreats
+func Rules() *RuleRegistry {
+    return defaultRuleRegistry
+}
+
+// snapshot returns the active rule set
+func (r *RuleRegistry) snapshot() *ruleSnapshot {
+    return r.current.Load().(*ruleSnapshot)
+}
+
+// Len returns the number of rules in the active set
+func (r *RuleRegistry) Len() int {
+    return len(r.snapshot().rules)
+}
+
+// RegisterCompiler adds a compiler for a rule type used in rule definitions
+func (r *RuleRegistry) RegisterCompiler(ruleType string, compiler RuleCompiler) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.compilers[ruleType] = compiler
+}
+
+// Register adds or replaces a single rule
+func (r *RuleRegistry) Register(ruleID string, rule DetectionRule) error {
+    if ruleID == "" || rule == nil {
+        return errors.NewError("E3001", "invalid detection rule", map[string]interface{}{
+            "rule_id": ruleID,
+        })
+    }
+
+    r.update(func(next *ruleSnapshot) error {
+        next.rules[ruleID] = rule
+        return nil
+    })
+    return nil
+}
+
+// Unregister removes a rule and its sampling configuration
+func (r *RuleRegistry) Unregister(ruleID string) {
+    r.update(func(next *ruleSnapshot) error {
+        delete(next.rules, ruleID)
+        delete(next.sampleRates, ruleID)
+        return nil
+    })
+}
+
+// SetSampleRate limits a rule to evaluating the given fraction of events.
+// A rate of 1 removes sampling so the rule runs on every event.
+func (r *RuleRegistry) SetSampleRate(ruleID string, rate float64) error {
+    if rate <= 0 || rate > 1 {
+        return errors.NewError("E3001", "sample rate must be in (0, 1]", map[string]interface{}{
+            "rule_id": ruleID,
+            "rate":    rate,
+        })
+    }
+
+    return r.update(func(next *ruleSnapshot) error {
+        if _, exists := next.rules[ruleID]; !exists {
+            return errors.NewError("E3001", "unknown detection rule", map[string]interface{}{
+                "rule_id": ruleID,
+            })
+        }
+        if rate == 1 {
+            delete(next.sampleRates, ruleID)
+        } else {
+            next.sampleRates[ruleID] = rate
+        }
+        return nil
+    })
+}
+
+// update copies the active set, applies change and publishes the result
+// unless change fails
+func (r *RuleRegistry) update(change func(next *ruleSnapshot) error) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    current := r.snapshot()
+    next := &ruleSnapshot{
+        rules:       make(map[string]DetectionRule, len(current.rules)),
+        sampleRates: make(map[string]float64, len(current.sampleRates)),
+    }
+    for ruleID, rule := range current.rules {
+        next.rules[ruleID] = rule
+    }
+    for ruleID, rate := range current.sampleRates {
+        next.sampleRates[ruleID] = rate
+    }
+
+    if err := change(next); err != nil {
+        return err
+    }
+    r.publish(next)
+    return nil
+}
+
+// publish makes a snapshot active; callers hold r.mu
+func (r *RuleRegistry) publish(next *ruleSnapshot) {
+    r.current.Store(next)
+    if r == defaultRuleRegistry {
+        rulesLoaded.Set(float64(len(next.rules)))
+    }
+}
+
+// Load compiles a full rule set and atomically replaces the active one. If
+// any rule fails to compile the new set is rejected and the old one stays
+// active.
+func (r *RuleRegistry) Load(defs []RuleDefinition) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    next, err := r.compile(defs)
+    if err != nil {
+        rulesReloaded.WithLabelValues(ReloadResultRejected).Inc()
+        return err
+    }
+
+    r.publish(next)
+    rulesReloaded.WithLabelValues(ReloadResultSuccess).Inc()
+    logging.Info("Detection rule set loaded",
+        logging.Field("rules", len(next.rules)),
+    )
+    return nil
+}
+
+// LoadFile loads a YAML or JSON rule set file; see Load
+func (r *RuleRegistry) LoadFile(path string) error {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        rulesReloaded.WithLabelValues(ReloadResultRejected).Inc()
+        return errors.WrapError(err, "failed to read rule set file", map[string]interface{}{
+            "path": path,
+        })
+    }
+
+    var file RuleSetFile
+    if strings.EqualFold(filepath.Ext(path), ".json") {
+        err = json.Unmarshal(data, &file)
+    } else {
+        err = yaml.Unmarshal(data, &file)
+    }
+    if err != nil {
+        rulesReloaded.WithLabelValues(ReloadResultRejected).Inc()
+        return errors.WrapError(err, "failed to parse rule set file", map[string]interface{}{
+            "path": path,
+        })
+    }
+
+    return r.Load(file.Rules)
+}
+
+// WatchFile reloads the rule set file whenever its modification time changes.
+// Rejected reloads are logged and the active set is kept. It returns when ctx
+// is done.
+func (r *RuleRegistry) WatchFile(ctx context.Context, path string, interval time.Duration) {
+    var lastModified time.Time
+    if info, err := os.Stat(path); err == nil {
+        lastModified = info.ModTime()
+    }
+
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            info, err := os.Stat(path)
+            if err != nil || !info.ModTime().After(lastModified) {
+                continue
+            }
+            lastModified = info.ModTime()
+            if err := r.LoadFile(path); err != nil {
+                logging.Error("Rejected detection rule set, keeping active rules", err,
+                    logging.Field("path", path),
+                )
+            }
+        }
+    }
+}
+
+// compile builds a snapshot from definitions; callers hold r.mu
+func (r *RuleRegistry) compile(defs []RuleDefinition) (*ruleSnapshot, error) {
+    next := &ruleSnapshot{
+        rules:       make(map[string]DetectionRule, len(defs)),
+        sampleRates: make(map[string]float64),
+    }
+
+    for _, def := range defs {
+        if def.ID == "" {
+            return nil, errors.NewError("E3001", "detection rule id is required", nil)
+        }
+        if _, duplicate := next.rules[def.ID]; duplicate {
+            return nil, errors.NewError("E3001", "duplicate detection rule id", map[string]interface{}{
+                "rule_id": def.ID,
+            })
+        }
+
+        compiler, exists := r.compilers[def.Type]
+        if !exists {
+            return nil, errors.NewError("E3001", "unknown detection rule type", map[string]interface{}{
+                "rule_id": def.ID,
+                "type":    def.Type,
+            })
+        }
+        rule, err := compiler(def)
+        if err != nil {
+            return nil, errors.WrapError(err, "failed to compile detection rule", map[string]interface{}{
+                "rule_id": def.ID,
+            })
+        }
+        if rule == nil {
+            return nil, errors.NewError("E3001", "rule compiler returned no rule", map[string]interface{}{
+                "rule_id": def.ID,
+            })
+        }
+        next.rules[def.ID] = rule
+
+        if def.SampleRate != 0 {
+            if def.SampleRate < 0 || def.SampleRate > 1 {
+                return nil, errors.NewError("E3001", "sample rate must be in (0, 1]", map[string]interface{}{
+                    "rule_id": def.ID,
+                    "rate":    def.SampleRate,
+                })
+            }
+            if def.SampleRate < 1 {
+                next.sampleRates[def.ID] = def.SampleRate
+            }
+        }
+    }
+
+    return next, nil
+}
+
+// compileThresholdRule builds a ThresholdRule from definition params:
+// event_type, key_field, window (duration), threshold, rate_per_second and severity
+func compileThresholdRule(def RuleDefinition) (DetectionRule, error) {
+    config := ThresholdRuleConfig{Name: def.ID}
+
+    var err error
+    if config.EventType, err = stringParam(def.Params, "event_type"); err != nil {
+        return nil, err
+    }
+    if config.KeyField, err = stringParam(def.Params, "key_field"); err != nil {
+        return nil, err
+    }
+    window, err := stringParam(def.Params, "window")
+    if err != nil {
+        return nil, err
+    }
+    if window != "" {
+        if config.Window, err = time.ParseDuration(window); err != nil {
+            return nil, errors.NewError("E3001", "invalid rule window", map[string]interface{}{
+                "window": window,
+            })
+        }
+    }
+    threshold, err := numberParam(def.Params, "threshold")
+    if err != nil {
+        return nil, err
+    }
+    config.Threshold = int(threshold)
+    if config.RatePerSecond, err = numberParam(def.Params, "rate_per_second"); err != nil {
+        return nil, err
+    }
+    if config.Severity, err = numberParam(def.Params, "severity"); err != nil {
+        return nil, err
+    }
+
+    return NewThresholdRule(config)
+}
+
+// stringParam reads an optional string rule parameter
+func stringParam(params map[string]interface{}, name string) (string, error) {
+    value, exists := params[name]
+    if !exists {
+        return "", nil
+    }
+    s, ok := value.(string)
+    if !ok {
+        return "", errors.NewError("E3001", "rule parameter must be a string", map[string]interface{}{
+            "param": name,
+        })
+    }
+    return s, nil
+}
+
+// numberParam reads an optional numeric rule parameter
+func numberParam(params map[string]interface{}, name string) (float64, error) {
+    switch value := params[name].(type) {
+    case nil:
+        return 0, nil
+    case int:
+        return float64(value), nil
+    case float64:
+        return value, nil
+    default:
+        return 0, errors.NewError("E3001", "rule parameter must be a number", map[string]interface{}{
+            "param": name,
+        })
+    }
+}
@@ -0,0 +1,328 @@
+// Package analyzer implements redelivery of stored alerts to notification sinks
+package analyzer
+
+import (
+    "context"
+    "encoding/json"
+    "sort"
+    "strconv"
+    "sync"
+    "time"
+
+    "github.com/go-redis/redis/v8" // v8.11.5
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+    "github.com/blackpoint/pkg/gold"
+)
+
+const (
+    // How long the in-memory delivery log remembers a delivery
+    defaultDeliveryRetention = 7 * 24 * time.Hour
+
+    // How long RedisAlertHistory keeps alerts available for replay
+    defaultAlertHistoryRetention = 30 * 24 * time.Hour
+
+    // Key prefix of RedisAlertHistory when none is configured
+    defaultAlertHistoryPrefix = "alerts:history"
+)
+
+// StoredAlert is a previously created alert with the client it belongs to
+type StoredAlert struct {
+    ClientID string      `json:"client_id"`
+    Alert    *gold.Alert `json:"alert"`
+}
+
+// AlertHistory reads alerts created within a time range
+type AlertHistory interface {
+    AlertsBetween(ctx context.Context, from, to time.Time) ([]StoredAlert, error)
+}
+
+// AlertRecorder is implemented by alert histories that store the alerts the
+// router handles. Route records every alert before delivering it so alerts
+// lost to a sink outage can be replayed.
+type AlertRecorder interface {
+    RecordAlert(ctx context.Context, stored StoredAlert) error
+}
+
+// DeliveryLog records which alerts reached which destination. Replays skip
+// recorded deliveries; a shared implementation lets replicas see each other's.
+type DeliveryLog interface {
+    Delivered(alertID, destination string) bool
+    RecordDelivery(alertID, destination string, at time.Time)
+}
+
+// ReplayResult summarizes a notification replay
+type ReplayResult struct {
+    Destination string    `json:"destination,omitempty"`
+    From        time.Time `json:"from"`
+    To          time.Time `json:"to"`
+    Considered  int       `json:"considered"`
+    Redelivered int       `json:"redelivered"`
+    Skipped     int       `json:"skipped_already_delivered"`
+    Unrouted    int       `json:"unrouted"`
+    Failed      int       `json:"failed"`
+    FailedIDs   []string  `json:"failed_alert_ids,omitempty"`
+}
+
+// SetAlertHistory configures the alert source used by ReplayNotifications
+func (r *AlertRouter) SetAlertHistory(history AlertHistory) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.history = history
+}
+
+// SetDeliveryLog replaces the default in-memory delivery log
+func (r *AlertRouter) SetDeliveryLog(log DeliveryLog) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.deliveries = log
+}
+
+// ReplayNotifications re-dispatches alerts created in [from, to) that were not
+// delivered. With a destination, only alerts routed to that destination are
+// replayed; alerts are never redirected to another client's sink. Delivery
+// failures are counted and the replay continues.
+func (r *AlertRouter) ReplayNotifications(ctx context.Context, from, to time.Time, destination string) (*ReplayResult, error) {
+    if !to.After(from) {
+        return nil, errors.NewError("E3001", "invalid replay time range", map[string]interface{}{
+            "from": from,
+            "to":   to,
+        })
+    }
+
+    r.mu.RLock()
+    history := r.history
+    r.mu.RUnlock()
+    if history == nil {
+        return nil, errors.NewError("E2001", "alert history not configured", nil)
+    }
+
+    alerts, err := history.AlertsBetween(ctx, from, to)
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to read alert history", nil)
+    }
+
+    // Replay oldest first so notifications arrive in their original order.
+    // The history's slice is left untouched since it may be shared.
+    valid := make([]StoredAlert, 0, len(alerts))
+    for _, stored := range alerts {
+        if stored.Alert != nil {
+            valid = append(valid, stored)
+        }
+    }
+    alerts = valid
+    sort.SliceStable(alerts, func(i, j int) bool {
+        return alerts[i].Alert.CreatedAt.Before(alerts[j].Alert.CreatedAt)
+    })
+
+    result := &ReplayResult{Destination: destination, From: from, To: to}
+    for _, stored := range alerts {
+        if err := ctx.Err(); err != nil {
+            return result, errors.WrapError(err, "notification replay cancelled", map[string]interface{}{
+                "redelivered": result.Redelivered,
+            })
+        }
+        target, ok := r.lookup(stored.ClientID)
+        if destination != "" && (!ok || target.destination != destination) {
+            continue
+        }
+        result.Considered++
+
+        if !ok {
+            result.Unrouted++
+            continue
+        }
+        if r.deliveryLog().Delivered(stored.Alert.AlertID, target.destination) {
+            result.Skipped++
+            continue
+        }
+
+        if err := r.Route(ctx, stored.ClientID, stored.Alert); err != nil {
+            result.Failed++
+            result.FailedIDs = append(result.FailedIDs, stored.Alert.AlertID)
+            continue
+        }
+        result.Redelivered++
+    }
+
+    logging.Info("Alert notification replay completed",
+        logging.Field("destination", destination),
+        logging.Field("from", from),
+        logging.Field("to", to),
+        logging.Field("redelivered", result.Redelivered),
+        logging.Field("skipped", result.Skipped),
+        logging.Field("failed", result.Failed),
+    )
+
+    return result, nil
+}
+
+// recordAlert stores an alert in the configured history if it records alerts.
+// Failures are logged so a history outage never blocks delivery.
+func (r *AlertRouter) recordAlert(ctx context.Context, clientID string, alert *gold.Alert) {
+    r.mu.RLock()
+    recorder, ok := r.history.(AlertRecorder)
+    r.mu.RUnlock()
+    if !ok {
+        return
+    }
+
+    if err := recorder.RecordAlert(ctx, StoredAlert{ClientID: clientID, Alert: alert}); err != nil {
+        logging.Error("Failed to record alert for replay", err,
+            logging.Field("client_id", clientID),
+            logging.Field("alert_id", alert.AlertID),
+        )
+    }
+}
+
+// deliveryLog returns the configured delivery log
+func (r *AlertRouter) deliveryLog() DeliveryLog {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    return r.deliveries
+}
+
+// memoryDeliveryLog is the default process-local DeliveryLog
+type memoryDeliveryLog struct {
+    delivered map[string]time.Time
+    retention time.Duration
+    mu        sync.Mutex
+}
+
+// newMemoryDeliveryLog creates an in-memory delivery log
+func newMemoryDeliveryLog(retention time.Duration) *memoryDeliveryLog {
+    return &memoryDeliveryLog{
+        delivered: make(map[string]time.Time),
+        retention: retention,
+    }
+}
+
+// Delivered reports whether the alert was delivered to the destination
+func (l *memoryDeliveryLog) Delivered(alertID, destination string) bool {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    _, ok := l.delivered[destination+"/"+alertID]
+    return ok
+}
+
+// RecordDelivery remembers a delivery, periodically forgetting those past retention
+func (l *memoryDeliveryLog) RecordDelivery(alertID, destination string, at time.Time) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    l.delivered[destination+"/"+alertID] = at
+    if len(l.delivered)%1024 != 0 {
+        return
+    }
+
+    cutoff := at.Add(-l.retention)
+    for key, deliveredAt := range l.delivered {
+        if deliveredAt.Before(cutoff) {
+            delete(l.delivered, key)
+        }
+    }
+}
+
+// RedisAlertHistory is an AlertHistory shared by all analyzer replicas using
+// the same Redis, so alerts survive restarts and any replica can replay them.
+// Alerts are indexed by creation time in a sorted set and stored individually
+// with the retention as TTL.
+type RedisAlertHistory struct {
+    client    *redis.Client
+    prefix    string
+    retention time.Duration
+}
+
+// NewRedisAlertHistory creates a Redis-backed alert history; an empty prefix
+// and non-positive retention use the defaults
+func NewRedisAlertHistory(client *redis.Client, prefix string, retention time.Duration) *RedisAlertHistory {
+    if prefix == "" {
+        prefix = defaultAlertHistoryPrefix
+    }
+    if retention <= 0 {
+        retention = defaultAlertHistoryRetention
+    }
+    return &RedisAlertHistory{client: client, prefix: prefix, retention: retention}
+}
+
+// RecordAlert stores an alert; recording the same alert again replaces it
+func (h *RedisAlertHistory) RecordAlert(ctx context.Context, stored StoredAlert) error {
+    if stored.Alert == nil || stored.Alert.AlertID == "" {
+        return errors.NewError("E3001", "alert ID is required for alert history", nil)
+    }
+
+    payload, err := json.Marshal(stored)
+    if err != nil {
+        return errors.WrapError(err, "failed to serialize alert for history", map[string]interface{}{
+            "alert_id": stored.Alert.AlertID,
+        })
+    }
+
+    created := stored.Alert.CreatedAt
+    if created.IsZero() {
+        created = time.Now()
+    }
+    cutoff := time.Now().Add(-h.retention)
+
+    pipe := h.client.TxPipeline()
+    pipe.Set(ctx, h.alertKey(stored.Alert.AlertID), payload, h.retention)
+    pipe.ZAdd(ctx, h.indexKey(), &redis.Z{Score: float64(created.UnixMilli()), Member: stored.Alert.AlertID})
+    pipe.ZRemRangeByScore(ctx, h.indexKey(), "-inf", "("+strconv.FormatInt(cutoff.UnixMilli(), 10))
+    if _, err := pipe.Exec(ctx); err != nil {
+        return errors.WrapError(err, "failed to record alert history", map[string]interface{}{
+            "alert_id": stored.Alert.AlertID,
+        })
+    }
+    return nil
+}
+
+// AlertsBetween returns the recorded alerts created in [from, to). Alerts
+// whose entries expired are omitted.
+func (h *RedisAlertHistory) AlertsBetween(ctx context.Context, from, to time.Time) ([]StoredAlert, error) {
+    ids, err := h.client.ZRangeByScore(ctx, h.indexKey(), &redis.ZRangeBy{
+        Min: strconv.FormatInt(from.UnixMilli(), 10),
+        Max: "(" + strconv.FormatInt(to.UnixMilli(), 10),
+    }).Result()
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to read alert history index", nil)
+    }
+    if len(ids) == 0 {
+        return nil, nil
+    }
+
+    keys := make([]string, len(ids))
+    for i, id := range ids {
+        keys[i] = h.alertKey(id)
+    }
+    values, err := h.client.MGet(ctx, keys...).Result()
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to read alert history", nil)
+    }
+
+    alerts := make([]StoredAlert, 0, len(values))
+    for i, value := range values {
+        raw, ok := value.(string)
+        if !ok {
+            continue
+        }
+        var stored StoredAlert
+        if err := json.Unmarshal([]byte(raw), &stored); err != nil {
+            return nil, errors.WrapError(err, "failed to decode alert history entry", map[string]interface{}{
+                "alert_id": ids[i],
+            })
+        }
+        alerts = append(alerts, stored)
+    }
+    return alerts, nil
+}
+
+// indexKey names the sorted set ordering alerts by creation time
+func (h *RedisAlertHistory) indexKey() string {
+    return h.prefix + ":index"
+}
+
+// alertKey names the entry holding one alert
+func (h *RedisAlertHistory) alertKey(alertID string) string {
+    return h.prefix + ":alert:" + alertID
+}
@@ -0,0 +1,228 @@
+// Package analyzer implements per-client routing of Gold alerts to downstream sinks
+package analyzer
+
+import (
+    "context"
+    "encoding/json"
+    "sync"
+    "time"
+
+    "github.com/blackpoint/internal/metrics"
+    "github.com/blackpoint/internal/streaming"
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+    "github.com/blackpoint/pkg/gold"
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+    alertsRouted = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "blackpoint_analyzer_alerts_routed_total",
+        Help: "Total number of Gold alerts delivered to downstream sinks by client and result",
+    }, []string{"client_id", "destination", "result"})
+
+    alertRoutingLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+        Name:    "blackpoint_analyzer_alert_routing_duration_seconds",
+        Help:    "Time taken to deliver a Gold alert to its downstream sink",
+        Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5},
+    }, []string{"client_id"})
+)
+
+func init() {
+    prometheus.MustRegister(alertsRouted, alertRoutingLatency)
+}
+
+// AlertSink delivers serialized alerts to a downstream queue or topic.
+// *streaming.Producer satisfies this interface.
+type AlertSink interface {
+    Publish(ctx context.Context, event []byte) error
+}
+
+// headerSink is implemented by sinks that carry message headers, such as
+// *streaming.Producer; routed alerts carry their event's trace in them
+type headerSink interface {
+    PublishWithHeaders(ctx context.Context, event []byte, headers map[string]string) error
+}
+
+// SinkFactory creates a sink for a named destination such as a Kafka topic
+type SinkFactory func(destination string) (AlertSink, error)
+
+// AlertRoutingConfig maps client IDs to dedicated alert destinations
+type AlertRoutingConfig struct {
+    // Routes maps a ClientID to its dedicated destination
+    Routes map[string]string `yaml:"routes"`
+
+    // DefaultDestination receives alerts for clients without a dedicated route
+    DefaultDestination string `yaml:"default_destination"`
+}
+
+// route pairs a destination name with its sink
+type route struct {
+    destination string
+    sink        AlertSink
+}
+
+// AlertRouter fans Gold alerts out to tenant-specific sinks so each client's
+// alerts are only ever delivered to that client's destination.
+type AlertRouter struct {
+    routes       map[string]route
+    defaultRoute *route
+    sinks        map[string]AlertSink
+    history      AlertHistory
+    deliveries   DeliveryLog
+    mu           sync.RWMutex
+}
+
+// NewAlertRouter builds sinks for every configured destination. Clients that
+// share a destination share a single sink.
+func NewAlertRouter(config AlertRoutingConfig, factory SinkFactory) (*AlertRouter, error) {
+    if factory == nil {
+        return nil, errors.NewError("E2001", "alert sink factory is required", nil)
+    }
+    if config.DefaultDestination == "" && len(config.Routes) == 0 {
+        return nil, errors.NewError("E2001", "alert routing requires at least one destination", nil)
+    }
+
+    sinks := make(map[string]AlertSink)
+    sinkFor := func(destination string) (AlertSink, error) {
+        if sink, ok := sinks[destination]; ok {
+            return sink, nil
+        }
+        sink, err := factory(destination)
+        if err != nil {
+            return nil, errors.WrapError(err, "failed to create alert sink", map[string]interface{}{
+                "destination": destination,
+            })
+        }
+        sinks[destination] = sink
+        return sink, nil
+    }
+
+    router := &AlertRouter{
+        routes:     make(map[string]route, len(config.Routes)),
+        sinks:      sinks,
+        deliveries: newMemoryDeliveryLog(defaultDeliveryRetention),
+    }
+
+    for clientID, destination := range config.Routes {
+        if clientID == "" || destination == "" {
+            return nil, errors.NewError("E2001", "invalid alert route", map[string]interface{}{
+                "client_id":   clientID,
+                "destination": destination,
+            })
+        }
+        sink, err := sinkFor(destination)
+        if err != nil {
+            return nil, err
+        }
+        router.routes[clientID] = route{destination: destination, sink: sink}
+    }
+
+    if config.DefaultDestination != "" {
+        sink, err := sinkFor(config.DefaultDestination)
+        if err != nil {
+            return nil, err
+        }
+        router.defaultRoute = &route{destination: config.DefaultDestination, sink: sink}
+    }
+
+    return router, nil
+}
+
+// Route delivers an alert to the destination configured for the client. Alerts
+// for unconfigured clients go to the default destination; if there is none the
+// alert is rejected rather than delivered to another tenant's sink. Sinks that
+// take headers receive the trace context of ctx with the alert.
+func (r *AlertRouter) Route(ctx context.Context, clientID string, alert *gold.Alert) error {
+    if alert == nil {
+        return errors.NewError("E3001", "nil alert", nil)
+    }
+    if clientID == "" {
+        return errors.NewError("E3001", "client ID is required for alert routing", map[string]interface{}{
+            "alert_id": alert.AlertID,
+        })
+    }
+
+    // Client IDs come from event data, so their label values are capped
+    clientLabel := metrics.Guard("blackpoint_analyzer_alert_routing").Value("client_id", clientID)
+
+    // Recorded first so alerts for unrouted clients can be replayed once routed
+    r.recordAlert(ctx, clientID, alert)
+
+    target, ok := r.lookup(clientID)
+    if !ok {
+        alertsRouted.WithLabelValues(clientLabel, "", "unrouted").Inc()
+        return errors.NewError("E2001", "no alert route configured for client", map[string]interface{}{
+            "client_id": clientID,
+            "alert_id":  alert.AlertID,
+        })
+    }
+
+    payload, err := json.Marshal(alert)
+    if err != nil {
+        alertsRouted.WithLabelValues(clientLabel, target.destination, "error").Inc()
+        return errors.WrapError(err, "failed to serialize alert", map[string]interface{}{
+            "alert_id": alert.AlertID,
+        })
+    }
+
+    start := time.Now()
+    if sink, ok := target.sink.(headerSink); ok {
+        err = sink.PublishWithHeaders(ctx, payload, streaming.InjectTraceContext(ctx, nil))
+    } else {
+        err = target.sink.Publish(ctx, payload)
+    }
+    alertRoutingLatency.WithLabelValues(clientLabel).Observe(time.Since(start).Seconds())
+
+    if err != nil {
+        alertsRouted.WithLabelValues(clientLabel, target.destination, "error").Inc()
+        logging.Error("Failed to deliver alert", err,
+            logging.Field("client_id", clientID),
+            logging.Field("destination", target.destination),
+            logging.Field("alert_id", alert.AlertID),
+        )
+        return errors.WrapError(err, "failed to deliver alert", map[string]interface{}{
+            "client_id":   clientID,
+            "destination": target.destination,
+            "alert_id":    alert.AlertID,
+        })
+    }
+
+    alertsRouted.WithLabelValues(clientLabel, target.destination, "delivered").Inc()
+    r.deliveryLog().RecordDelivery(alert.AlertID, target.destination, time.Now())
+    return nil
+}
+
+// Close closes every sink that has a Close method, once per destination
+func (r *AlertRouter) Close() error {
+    var firstErr error
+    for destination, sink := range r.sinks {
+        closer, ok := sink.(interface{ Close() error })
+        if !ok {
+            continue
+        }
+        if err := closer.Close(); err != nil && firstErr == nil {
+            firstErr = errors.WrapError(err, "failed to close alert sink", map[string]interface{}{
+                "destination": destination,
+            })
+        }
+    }
+    return firstErr
+}
+
+// Destination returns the destination alerts for the client are routed to
+func (r *AlertRouter) Destination(clientID string) (string, bool) {
+    target, ok := r.lookup(clientID)
+    return target.destination, ok
+}
+
+// lookup resolves the route for a client, falling back to the default route
+func (r *AlertRouter) lookup(clientID string) (route, bool) {
+    if target, ok := r.routes[clientID]; ok {
+        return target, true
+    }
+    if r.defaultRoute != nil {
+        return *r.defaultRoute, true
+    }
+    return route{}, false
+}
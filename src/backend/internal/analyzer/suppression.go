@@ -0,0 +1,194 @@
+// Package analyzer implements root-cause alert suppression for event correlation
+package analyzer
+
+import (
+    "fmt"
+    "sync"
+    "time"
+
+    "github.com/blackpoint/pkg/gold"
+)
+
+const (
+    // Default cooldown during which alerts for the same correlation key are suppressed
+    defaultSuppressionCooldown = 30 * time.Minute
+
+    // Intelligence data keys of admitted alerts and suppression summaries
+    correlationKeyField  = "correlation_key"
+    eventCountField      = "event_count"
+    suppressedCountField = "suppressed_count"
+    firstSeenField       = "first_seen"
+    lastSeenField        = "last_seen"
+    recordTypeField      = "record_type"
+    suppressedAlertField = "suppressed_alert_id"
+    ruleIDField          = "rule_id"
+
+    // recordTypeSuppressionSummary marks the aggregate record of a suppressed storm
+    recordTypeSuppressionSummary = "suppression_summary"
+)
+
+// suppressionEntityFields key alerts without a correlation_key by the entity
+// they concern, so storms about different entities are not merged
+var suppressionEntityFields = []string{"source_ip", "user_id"}
+
+// SuppressionConfig configures root-cause suppression of correlated alerts
+type SuppressionConfig struct {
+    // Enabled turns suppression on for the correlator
+    Enabled bool
+
+    // Cooldown is how long after an admitted alert new alerts for the same key
+    // are suppressed. It is measured from the admitted alert, so a continuous
+    // storm is summarized and alerted on again once per cooldown.
+    Cooldown time.Duration
+
+    // KeyFunc derives the correlation key for an alert; defaults to rule ID plus
+    // the alert's correlation_key intelligence field, or its entity fields when
+    // it has none
+    KeyFunc func(ruleID string, alert *gold.Alert) string
+}
+
+// activeAlert aggregates the alerts suppressed behind an admitted alert. The
+// admitted alert itself is never modified once emitted.
+type activeAlert struct {
+    ruleID     string
+    alertID    string
+    severity   string
+    eventCount int
+    firstSeen  time.Time
+    lastSeen   time.Time
+    suppressed int
+}
+
+// AlertSuppressor collapses alerts that share a root cause within a cooldown.
+// Suppressed alerts are reported as one aggregate record per storm once its
+// cooldown has elapsed.
+type AlertSuppressor struct {
+    config  SuppressionConfig
+    active  map[string]*activeAlert
+    pending []*gold.Alert
+    mutex   sync.Mutex
+}
+
+// NewAlertSuppressor creates a suppressor with defaults applied
+func NewAlertSuppressor(config SuppressionConfig) *AlertSuppressor {
+    if config.Cooldown <= 0 {
+        config.Cooldown = defaultSuppressionCooldown
+    }
+    if config.KeyFunc == nil {
+        config.KeyFunc = defaultSuppressionKey
+    }
+
+    return &AlertSuppressor{
+        config: config,
+        active: make(map[string]*activeAlert),
+    }
+}
+
+// Admit reports whether the alert should be emitted. When an alert for the same
+// correlation key is still active, the suppressed alert's events are added to
+// the storm's aggregate and the second return value reports whether this
+// suppression started a new storm.
+func (s *AlertSuppressor) Admit(ruleID string, alert *gold.Alert, eventCount int, now time.Time) (bool, bool) {
+    key := s.config.KeyFunc(ruleID, alert)
+
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+
+    s.evictExpired(now)
+
+    if current, exists := s.active[key]; exists {
+        current.suppressed++
+        current.eventCount += eventCount
+        current.lastSeen = now
+        return false, current.suppressed == 1
+    }
+
+    if alert.IntelligenceData == nil {
+        alert.IntelligenceData = make(map[string]interface{})
+    }
+    alert.IntelligenceData[correlationKeyField] = key
+    alert.IntelligenceData[eventCountField] = eventCount
+
+    s.active[key] = &activeAlert{
+        ruleID:     ruleID,
+        alertID:    alert.AlertID,
+        severity:   alert.Severity,
+        eventCount: eventCount,
+        firstSeen:  now,
+        lastSeen:   now,
+    }
+    return true, false
+}
+
+// Expire ends the storms whose cooldown has elapsed and returns a summary
+// record for each that suppressed alerts. The correlator emits the summaries
+// alongside its alerts.
+func (s *AlertSuppressor) Expire(now time.Time) []*gold.Alert {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+
+    s.evictExpired(now)
+    summaries := s.pending
+    s.pending = nil
+    return summaries
+}
+
+// ActiveCount returns the number of correlation keys currently suppressing alerts
+func (s *AlertSuppressor) ActiveCount() int {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    return len(s.active)
+}
+
+// evictExpired drops active alerts whose cooldown has elapsed since they were
+// admitted, queueing a summary for each storm that suppressed alerts
+func (s *AlertSuppressor) evictExpired(now time.Time) {
+    for key, current := range s.active {
+        if now.Sub(current.firstSeen) <= s.config.Cooldown {
+            continue
+        }
+        if current.suppressed > 0 {
+            s.pending = append(s.pending, suppressionSummary(key, current, now))
+        }
+        delete(s.active, key)
+    }
+}
+
+// suppressionSummary builds the aggregate record of a storm: the alert it
+// followed, how many alerts it suppressed and the events they covered
+func suppressionSummary(key string, current *activeAlert, now time.Time) *gold.Alert {
+    return &gold.Alert{
+        Severity:  current.severity,
+        CreatedAt: now,
+        UpdatedAt: now,
+        IntelligenceData: map[string]interface{}{
+            recordTypeField:      recordTypeSuppressionSummary,
+            ruleIDField:          current.ruleID,
+            correlationKeyField:  key,
+            suppressedAlertField: current.alertID,
+            eventCountField:      current.eventCount,
+            suppressedCountField: current.suppressed,
+            firstSeenField:       current.firstSeen,
+            lastSeenField:        current.lastSeen,
+        },
+    }
+}
+
+// defaultSuppressionKey keys alerts by rule and any rule-provided correlation
+// key, falling back to the alert's entity fields
+func defaultSuppressionKey(ruleID string, alert *gold.Alert) string {
+    if alert.IntelligenceData == nil {
+        return ruleID
+    }
+    if key, ok := alert.IntelligenceData[correlationKeyField].(string); ok && key != "" {
+        return fmt.Sprintf("%s:%s", ruleID, key)
+    }
+
+    key := ruleID
+    for _, field := range suppressionEntityFields {
+        if value, ok := alert.IntelligenceData[field]; ok && value != nil {
+            key += fmt.Sprintf(":%s=%v", field, value)
+        }
+    }
+    return key
+}
@@ -0,0 +1,206 @@
+// Package analyzer implements windowed threshold detection over aggregated event counts
+package analyzer
+
+import (
+    "fmt"
+    "sync"
+    "time"
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/silver"
+)
+
+const (
+    // Number of buckets used to approximate the sliding window
+    defaultThresholdBuckets = 60
+
+    // Number of evaluations between sweeps of idle keys
+    thresholdSweepInterval = 1024
+)
+
+// ThresholdRuleConfig configures a count or rate based detection rule
+type ThresholdRuleConfig struct {
+    // Name identifies the rule in alert metadata
+    Name string
+
+    // EventType restricts counting to a single event type; empty counts all events
+    EventType string
+
+    // Match optionally filters events further, e.g. to failed logins only
+    Match func(event *silver.SilverEvent) bool
+
+    // KeyField is the normalized data field counts are grouped by, e.g. "user"
+    KeyField string
+
+    // Window is the sliding window over which events are counted
+    Window time.Duration
+
+    // Threshold fires the rule when the windowed count exceeds it
+    Threshold int
+
+    // RatePerSecond, when set, derives Threshold from Window
+    RatePerSecond float64
+
+    // Severity is reported when the rule fires
+    Severity float64
+}
+
+// windowCounter counts events in a ring of fixed-width time buckets
+type windowCounter struct {
+    counts   []int
+    epochs   []int64
+    lastSeen int64
+    fired    bool
+}
+
+// ThresholdRule fires when the count of matching events for a key exceeds a
+// threshold within a sliding window. It fires once per crossing and re-arms
+// after the count drops back to the threshold.
+type ThresholdRule struct {
+    config      ThresholdRuleConfig
+    bucketWidth int64
+    counters    map[string]*windowCounter
+    evaluations int
+    mutex       sync.Mutex
+}
+
+// NewThresholdRule creates a ThresholdRule with validated configuration
+func NewThresholdRule(config ThresholdRuleConfig) (*ThresholdRule, error) {
+    if config.Name == "" {
+        return nil, errors.NewError("E3001", "threshold rule name is required", nil)
+    }
+    if config.KeyField == "" {
+        return nil, errors.NewError("E3001", "threshold rule key field is required", map[string]interface{}{
+            "rule": config.Name,
+        })
+    }
+    if config.Window <= 0 {
+        return nil, errors.NewError("E3001", "threshold rule window must be positive", map[string]interface{}{
+            "rule": config.Name,
+        })
+    }
+    if config.RatePerSecond > 0 {
+        config.Threshold = int(config.RatePerSecond * config.Window.Seconds())
+    }
+    if config.Threshold <= 0 {
+        return nil, errors.NewError("E3001", "threshold rule threshold must be positive", map[string]interface{}{
+            "rule": config.Name,
+        })
+    }
+    if config.Severity <= 0 || config.Severity > 1 {
+        config.Severity = 0.6
+    }
+
+    bucketWidth := int64(config.Window) / defaultThresholdBuckets
+    if bucketWidth <= 0 {
+        bucketWidth = 1
+    }
+
+    return &ThresholdRule{
+        config:      config,
+        bucketWidth: bucketWidth,
+        counters:    make(map[string]*windowCounter),
+    }, nil
+}
+
+// Detect counts the event against its key and reports when the threshold is crossed
+func (r *ThresholdRule) Detect(event *silver.SilverEvent) (bool, float64, map[string]interface{}) {
+    if event == nil || !r.matches(event) {
+        return false, 0, nil
+    }
+
+    value, ok := event.NormalizedData[r.config.KeyField]
+    if !ok {
+        return false, 0, nil
+    }
+    key := fmt.Sprintf("%v", value)
+
+    at := event.EventTime
+    if at.IsZero() {
+        at = time.Now()
+    }
+    epoch := at.UnixNano() / r.bucketWidth
+
+    r.mutex.Lock()
+    defer r.mutex.Unlock()
+
+    r.evaluations++
+    if r.evaluations%thresholdSweepInterval == 0 {
+        r.sweep(epoch)
+    }
+
+    counter, exists := r.counters[key]
+    if !exists {
+        counter = &windowCounter{
+            counts: make([]int, defaultThresholdBuckets),
+            epochs: make([]int64, defaultThresholdBuckets),
+        }
+        r.counters[key] = counter
+    }
+
+    counter.add(epoch)
+    count := counter.total(epoch)
+
+    if count <= r.config.Threshold {
+        counter.fired = false
+        return false, 0, nil
+    }
+    if counter.fired {
+        return false, 0, nil
+    }
+    counter.fired = true
+
+    return true, r.config.Severity, map[string]interface{}{
+        "rule_type":       "threshold",
+        "rule_name":       r.config.Name,
+        "threshold_key":   r.config.KeyField,
+        "threshold_value": key,
+        "observed_count":  count,
+        "threshold":       r.config.Threshold,
+        "window_seconds":  r.config.Window.Seconds(),
+    }
+}
+
+// matches reports whether an event should be counted by this rule
+func (r *ThresholdRule) matches(event *silver.SilverEvent) bool {
+    if r.config.EventType != "" && event.EventType != r.config.EventType {
+        return false
+    }
+    if r.config.Match != nil && !r.config.Match(event) {
+        return false
+    }
+    return true
+}
+
+// sweep drops counters for keys with no events inside the window
+func (r *ThresholdRule) sweep(epoch int64) {
+    for key, counter := range r.counters {
+        if epoch-counter.lastSeen >= defaultThresholdBuckets {
+            delete(r.counters, key)
+        }
+    }
+}
+
+// add records an event in the bucket for the given epoch
+func (c *windowCounter) add(epoch int64) {
+    i := int(epoch % defaultThresholdBuckets)
+    if c.epochs[i] != epoch {
+        c.epochs[i] = epoch
+        c.counts[i] = 0
+    }
+    c.counts[i]++
+    if epoch > c.lastSeen {
+        c.lastSeen = epoch
+    }
+}
+
+// total sums the buckets that fall inside the window ending at epoch
+func (c *windowCounter) total(epoch int64) int {
+    sum := 0
+    for i, e := range c.epochs {
+        if e > epoch-defaultThresholdBuckets && e <= epoch {
+            sum += c.counts[i]
+        }
+    }
+    return sum
+}
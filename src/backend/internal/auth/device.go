@@ -0,0 +1,308 @@
+// Package auth provides the OAuth 2.0 device authorization grant (RFC 8628)
+package auth
+
+import (
+    "context"
+    "encoding/json"
+    "net"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "github.com/coreos/go-oidc/v3/oidc" // v3.6.0
+    "golang.org/x/oauth2"               // v0.12.0
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+)
+
+const (
+    deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+    // defaultDevicePollInterval applies when the provider omits an interval
+    defaultDevicePollInterval = 5 * time.Second
+
+    // deviceSlowDownStep is added to the interval on each slow_down (RFC 8628 3.5)
+    deviceSlowDownStep = 5 * time.Second
+
+    devicePrefix = "device:"
+)
+
+// DeviceCodeResponse is the device authorization response shown to the user
+type DeviceCodeResponse struct {
+    DeviceCode              string `json:"device_code"`
+    UserCode                string `json:"user_code"`
+    VerificationURI         string `json:"verification_uri"`
+    VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+    ExpiresIn               int    `json:"expires_in"`
+    Interval                int    `json:"interval"`
+}
+
+// deviceSession tracks a pending device authorization between polls
+type deviceSession struct {
+    Interval  time.Duration `json:"interval"`
+    ExpiresAt time.Time     `json:"expires_at"`
+}
+
+// deviceTokenResponse is the provider's token endpoint response
+type deviceTokenResponse struct {
+    AccessToken      string `json:"access_token"`
+    TokenType        string `json:"token_type"`
+    RefreshToken     string `json:"refresh_token"`
+    ExpiresIn        int    `json:"expires_in"`
+    IDToken          string `json:"id_token"`
+    Error            string `json:"error"`
+    ErrorDescription string `json:"error_description"`
+}
+
+// StartDeviceFlow begins a device authorization grant for clients without a
+// browser redirect, such as the CLI and headless collectors. The user enters
+// the returned user code at the verification URL while the client calls
+// PollDeviceToken with the device code.
+func (m *OAuthManager) StartDeviceFlow(ctx context.Context) (*DeviceCodeResponse, error) {
+    if m.deviceAuthURL == "" {
+        return nil, errors.NewError("E2001", "OAuth provider does not support the device authorization grant", nil)
+    }
+    if !m.checkRateLimit(ctx) {
+        return nil, errors.NewError("E1001", "Rate limit exceeded", nil)
+    }
+
+    form := url.Values{
+        "client_id": {m.config.ClientID},
+        "scope":     {strings.Join(m.config.Scopes, " ")},
+    }
+    resp, err := m.postForm(ctx, m.deviceAuthURL, form)
+    if err != nil {
+        return nil, errors.NewError("E1001", "Failed to request device code", nil)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return nil, errors.NewError("E1001", "Device authorization request rejected", map[string]interface{}{
+            "status": resp.StatusCode,
+        })
+    }
+
+    var code DeviceCodeResponse
+    if err := json.NewDecoder(resp.Body).Decode(&code); err != nil || code.DeviceCode == "" {
+        return nil, errors.NewError("E1001", "Invalid device authorization response", nil)
+    }
+    if code.Interval <= 0 {
+        code.Interval = int(defaultDevicePollInterval / time.Second)
+    }
+
+    session := deviceSession{
+        Interval:  time.Duration(code.Interval) * time.Second,
+        ExpiresAt: time.Now().UTC().Add(time.Duration(code.ExpiresIn) * time.Second),
+    }
+    if err := m.saveDeviceSession(ctx, code.DeviceCode, session); err != nil {
+        return nil, err
+    }
+
+    logging.Info("Device authorization started",
+        logging.Field("user_code", code.UserCode),
+        logging.Field("expires_in", code.ExpiresIn))
+
+    return &code, nil
+}
+
+// PollDeviceToken polls the provider until the user approves or denies the
+// device code or it expires. authorization_pending keeps polling at the
+// current interval and slow_down lengthens it. Polls for one device code
+// never exceed RateLimitPerMinute.
+func (m *OAuthManager) PollDeviceToken(ctx context.Context, deviceCode string) (*oauth2.Token, *oidc.IDToken, error) {
+    session, err := m.loadDeviceSession(ctx, deviceCode)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    // Never poll faster than the rate limit allows
+    if m.securityConfig.RateLimitPerMinute > 0 {
+        if floor := time.Minute / time.Duration(m.securityConfig.RateLimitPerMinute); session.Interval < floor {
+            session.Interval = floor
+        }
+    }
+
+    for {
+        if time.Now().After(session.ExpiresAt) {
+            m.tokenBlacklist.Del(ctx, devicePrefix+deviceCode)
+            return nil, nil, errors.NewError("E1001", "Device code expired", nil)
+        }
+        if !m.checkRateLimitKey(ctx, "ratelimit:"+devicePrefix+deviceCode) {
+            return nil, nil, errors.NewError("E1001", "Rate limit exceeded", nil)
+        }
+
+        result, err := m.requestDeviceToken(ctx, deviceCode)
+        if err != nil {
+            return nil, nil, err
+        }
+
+        switch result.Error {
+        case "":
+            m.tokenBlacklist.Del(ctx, devicePrefix+deviceCode)
+            token := (&oauth2.Token{
+                AccessToken:  result.AccessToken,
+                TokenType:    result.TokenType,
+                RefreshToken: result.RefreshToken,
+                Expiry:       time.Now().Add(time.Duration(result.ExpiresIn) * time.Second),
+            }).WithExtra(map[string]interface{}{"id_token": result.IDToken})
+            return m.completeTokenExchange(ctx, token)
+        case "authorization_pending":
+        case "slow_down":
+            session.Interval += deviceSlowDownStep
+            if err := m.saveDeviceSession(ctx, deviceCode, session); err != nil {
+                return nil, nil, err
+            }
+        case "access_denied":
+            m.tokenBlacklist.Del(ctx, devicePrefix+deviceCode)
+            return nil, nil, errors.NewError("E1002", "Device authorization denied by user", nil)
+        case "expired_token":
+            m.tokenBlacklist.Del(ctx, devicePrefix+deviceCode)
+            return nil, nil, errors.NewError("E1001", "Device code expired", nil)
+        default:
+            return nil, nil, errors.NewError("E1001", "Device token request failed", map[string]interface{}{
+                "error":       result.Error,
+                "description": result.ErrorDescription,
+            })
+        }
+
+        select {
+        case <-ctx.Done():
+            return nil, nil, errors.NewError("E1001", "Device authorization cancelled", nil)
+        case <-time.After(session.Interval):
+        }
+    }
+}
+
+// DeviceCodeHandler starts a device authorization for the caller
+func (m *OAuthManager) DeviceCodeHandler(w http.ResponseWriter, r *http.Request) {
+    code, err := m.StartDeviceFlow(withClientIP(r))
+    if err != nil {
+        writeDeviceError(w, err)
+        return
+    }
+    writeDeviceJSON(w, http.StatusOK, code)
+}
+
+// DeviceTokenHandler long-polls until the device code posted as device_code
+// completes, then returns the BlackPoint tokens. Clients should allow the
+// request to run until the device code expires.
+func (m *OAuthManager) DeviceTokenHandler(w http.ResponseWriter, r *http.Request) {
+    deviceCode := r.FormValue("device_code")
+    if deviceCode == "" {
+        writeDeviceError(w, errors.NewError("E3001", "device_code is required", nil))
+        return
+    }
+
+    token, _, err := m.PollDeviceToken(withClientIP(r), deviceCode)
+    if err != nil {
+        writeDeviceError(w, err)
+        return
+    }
+    writeDeviceJSON(w, http.StatusOK, map[string]interface{}{
+        "access_token":  token.AccessToken,
+        "refresh_token": token.RefreshToken,
+        "token_type":    "Bearer",
+        "expiry":        token.Expiry,
+    })
+}
+
+// requestDeviceToken makes a single device_code grant request
+func (m *OAuthManager) requestDeviceToken(ctx context.Context, deviceCode string) (*deviceTokenResponse, error) {
+    form := url.Values{
+        "grant_type":  {deviceCodeGrantType},
+        "device_code": {deviceCode},
+        "client_id":   {m.config.ClientID},
+    }
+    if m.config.ClientSecret != "" {
+        form.Set("client_secret", m.config.ClientSecret)
+    }
+
+    resp, err := m.postForm(ctx, m.config.Endpoint.TokenURL, form)
+    if err != nil {
+        return nil, errors.NewError("E1001", "Failed to poll device token", nil)
+    }
+    defer resp.Body.Close()
+
+    // Pending and slow_down arrive as 400 responses with an error code
+    var result deviceTokenResponse
+    if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+        return nil, errors.NewError("E1001", "Invalid device token response", map[string]interface{}{
+            "status": resp.StatusCode,
+        })
+    }
+    if resp.StatusCode != http.StatusOK && result.Error == "" {
+        result.Error = "server_error"
+    }
+    return &result, nil
+}
+
+// postForm sends a form-encoded POST to the provider
+func (m *OAuthManager) postForm(ctx context.Context, endpoint string, form url.Values) (*http.Response, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    req.Header.Set("Accept", "application/json")
+    return http.DefaultClient.Do(req)
+}
+
+// saveDeviceSession stores a pending device authorization until it expires
+func (m *OAuthManager) saveDeviceSession(ctx context.Context, deviceCode string, session deviceSession) error {
+    raw, err := json.Marshal(session)
+    if err != nil {
+        return errors.NewError("E4001", "Failed to encode device session", nil)
+    }
+    ttl := time.Until(session.ExpiresAt)
+    if ttl <= 0 {
+        return errors.NewError("E1001", "Device code expired", nil)
+    }
+    if err := m.tokenBlacklist.Set(ctx, devicePrefix+deviceCode, raw, ttl).Err(); err != nil {
+        return errors.NewError("E1001", "Failed to store device session", nil)
+    }
+    return nil
+}
+
+// loadDeviceSession returns a pending device authorization started by StartDeviceFlow
+func (m *OAuthManager) loadDeviceSession(ctx context.Context, deviceCode string) (deviceSession, error) {
+    var session deviceSession
+    raw, err := m.tokenBlacklist.Get(ctx, devicePrefix+deviceCode).Bytes()
+    if err != nil {
+        return session, errors.NewError("E1001", "Invalid or expired device code", nil)
+    }
+    if err := json.Unmarshal(raw, &session); err != nil {
+        return session, errors.NewError("E1001", "Invalid or expired device code", nil)
+    }
+    return session, nil
+}
+
+// withClientIP adds the caller's address to the context for rate limiting
+func withClientIP(r *http.Request) context.Context {
+    host, _, err := net.SplitHostPort(r.RemoteAddr)
+    if err != nil {
+        host = r.RemoteAddr
+    }
+    return context.WithValue(r.Context(), "client_ip", host)
+}
+
+// writeDeviceError maps a device flow error to an OAuth-style JSON response
+func writeDeviceError(w http.ResponseWriter, err error) {
+    status := http.StatusBadRequest
+    switch {
+    case errors.IsErrorCode(err, "E1002", ""):
+        status = http.StatusForbidden
+    case errors.IsErrorCode(err, "E2001", ""):
+        status = http.StatusNotImplemented
+    }
+    writeDeviceJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeDeviceJSON writes a JSON response body
+func writeDeviceJSON(w http.ResponseWriter, status int, body interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("Cache-Control", "no-store")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(body)
+}
@@ -2,6 +2,7 @@
 package auth
 
 import (
+    "context"
     "crypto/rsa"
     "crypto/x509"
     "encoding/pem"
@@ -18,18 +19,53 @@ import (
 
 // Global variables for JWT management
 var (
-    jwtSigningKey    *rsa.PrivateKey
-    jwtPublicKey     *rsa.PublicKey
     tokenExpiration  time.Duration
     tokenBlacklist   sync.Map
 )
 
+// revocationCheckTimeout bounds the shared blacklist lookup of ValidateToken
+const revocationCheckTimeout = 2 * time.Second
+
+// RevocationCheck reports whether a token was revoked in a store shared by
+// every replica
+type RevocationCheck func(ctx context.Context, token string) (bool, error)
+
+var (
+    revocationMu    sync.RWMutex
+    revocationCheck RevocationCheck
+)
+
+// SetRevocationCheck makes ValidateToken reject tokens that check reports
+// revoked. OAuth managers install their Redis blacklist; nil removes the check.
+func SetRevocationCheck(check RevocationCheck) {
+    revocationMu.Lock()
+    defer revocationMu.Unlock()
+    revocationCheck = check
+}
+
+// isRevoked consults the shared blacklist, if one is installed
+func isRevoked(tokenString string) (bool, error) {
+    revocationMu.RLock()
+    check := revocationCheck
+    revocationMu.RUnlock()
+    if check == nil {
+        return false, nil
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), revocationCheckTimeout)
+    defer cancel()
+    return check(ctx, tokenString)
+}
+
 // JWTConfig defines the configuration for JWT operations
 type JWTConfig struct {
     PrivateKeyPath      string
     PublicKeyPath       string
     TokenExpiration     time.Duration
     KeyRotationInterval time.Duration
+    // KeyGracePeriod is how long a rotated-out key still verifies tokens;
+    // defaults to TokenExpiration so no live token is invalidated
+    KeyGracePeriod      time.Duration
 }
 
 // JWTManager handles JWT operations with enhanced security
@@ -54,6 +90,9 @@ func InitJWTManager(config JWTConfig) error {
     if config.TokenExpiration == 0 {
         config.TokenExpiration = time.Hour // Default 1-hour expiration
     }
+    if config.KeyGracePeriod == 0 {
+        config.KeyGracePeriod = config.TokenExpiration
+    }
 
     // Load and validate private key
     privateKeyBytes, err := ioutil.ReadFile(config.PrivateKeyPath)
@@ -97,11 +136,11 @@ func InitJWTManager(config JWTConfig) error {
     }
 
     // Set global variables
-    jwtSigningKey = privateKey
-    jwtPublicKey = rsaPublicKey
+    kid := jwtKeys.reset(privateKey, rsaPublicKey, config.KeyGracePeriod)
     tokenExpiration = config.TokenExpiration
 
     logging.Info("JWT manager initialized successfully",
+        zap.String("kid", kid),
         zap.Duration("token_expiration", config.TokenExpiration),
         zap.Duration("key_rotation_interval", config.KeyRotationInterval))
 
@@ -131,8 +170,15 @@ func GenerateToken(claims map[string]interface{}) (string, error) {
         Metadata:        claims["metadata"].(map[string]string),
     }
 
+    key, err := jwtKeys.active()
+    if err != nil {
+        return "", err
+    }
+
+    // The kid header lets verifiers pick the right key after rotation
     token := jwt.NewWithClaims(jwt.SigningMethodRS256, customClaims)
-    signedToken, err := token.SignedString(jwtSigningKey)
+    token.Header["kid"] = key.kid
+    signedToken, err := token.SignedString(key.privateKey)
     if err != nil {
         return "", errors.NewError("E1001", "Failed to sign token", nil)
     }
@@ -151,11 +197,22 @@ func ValidateToken(tokenString string) (jwt.MapClaims, error) {
         return nil, errors.NewError("E1001", "Token has been blacklisted", nil)
     }
 
+    // Tokens revoked on another replica are only in the shared blacklist; an
+    // unreachable blacklist fails closed
+    revoked, err := isRevoked(tokenString)
+    if err != nil {
+        return nil, err
+    }
+    if revoked {
+        return nil, errors.NewError("E1001", "Token has been blacklisted", nil)
+    }
+
     token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
         if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
             return nil, errors.NewError("E1001", "Invalid signing method", nil)
         }
-        return jwtPublicKey, nil
+        kid, _ := token.Header["kid"].(string)
+        return jwtKeys.verificationKey(kid)
     })
 
     if err != nil {
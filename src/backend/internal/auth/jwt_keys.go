@@ -0,0 +1,413 @@
+// Package auth provides JWT signing-key rotation and JWKS publishing
+package auth
+
+import (
+    "context"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/json"
+    "math/big"
+    "net/http"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/go-redis/redis/v8" // v8.11.5
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+)
+
+// Size of signing keys generated by RotateSigningKey
+const generatedSigningKeyBits = 2048
+
+// Redis keys of the public keys shared between replicas: a set of kids and
+// one JWK per kid, which expires once the key's grace period has passed
+const (
+    sharedKeySet    = "jwt_keys"
+    sharedKeyPrefix = "jwt_key:"
+
+    // sharedKeyTimeout bounds each shared key store operation
+    sharedKeyTimeout = 2 * time.Second
+)
+
+// signingKey is one RSA key pair of the JWT keyring
+type signingKey struct {
+    kid        string
+    privateKey *rsa.PrivateKey
+    publicKey  *rsa.PublicKey
+    retiredAt  time.Time
+}
+
+// jwtKeyring holds the active signing key and retired keys still accepted
+// for verification during the grace period
+type jwtKeyring struct {
+    mu          sync.RWMutex
+    keys        map[string]*signingKey
+    activeKID   string
+    gracePeriod time.Duration
+}
+
+// JSONWebKey is the public part of a signing key in JWK form (RFC 7517)
+type JSONWebKey struct {
+    Kty string `json:"kty"`
+    Use string `json:"use"`
+    Alg string `json:"alg"`
+    Kid string `json:"kid"`
+    N   string `json:"n"`
+    E   string `json:"e"`
+}
+
+// JSONWebKeySet is a JWKS document
+type JSONWebKeySet struct {
+    Keys []JSONWebKey `json:"keys"`
+}
+
+var jwtKeys = &jwtKeyring{keys: make(map[string]*signingKey)}
+
+// sharedKeys is the store the public keys are shared through, if any
+var sharedKeys struct {
+    sync.RWMutex
+    store *redis.Client
+}
+
+// reset replaces the keyring with a single active key
+func (r *jwtKeyring) reset(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey, gracePeriod time.Duration) string {
+    key := &signingKey{kid: keyID(publicKey), privateKey: privateKey, publicKey: publicKey}
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.keys = map[string]*signingKey{key.kid: key}
+    r.activeKID = key.kid
+    r.gracePeriod = gracePeriod
+    return key.kid
+}
+
+// active returns the key new tokens are signed with
+func (r *jwtKeyring) active() (*signingKey, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    key, ok := r.keys[r.activeKID]
+    if !ok {
+        return nil, errors.NewError("E1001", "JWT manager is not initialized", nil)
+    }
+    return key, nil
+}
+
+// verificationKey returns the public key for a kid, refusing retired keys
+// whose grace period has passed. Tokens without a kid predate rotation and
+// are checked against the active key.
+// Keys another replica signs with are looked up in the shared store.
+func (r *jwtKeyring) verificationKey(kid string) (*rsa.PublicKey, error) {
+    r.mu.RLock()
+    if kid == "" {
+        kid = r.activeKID
+    }
+    key, ok := r.keys[kid]
+    usable := ok && r.usable(key, time.Now())
+    r.mu.RUnlock()
+
+    if usable {
+        return key.publicKey, nil
+    }
+    if !ok {
+        if publicKey, found := lookupSharedKey(kid); found {
+            return publicKey, nil
+        }
+    }
+    return nil, errors.NewError("E1001", "Unknown or expired signing key", map[string]interface{}{
+        "kid": kid,
+    })
+}
+
+// usable reports whether a key may still verify tokens; callers hold mu
+func (r *jwtKeyring) usable(key *signingKey, now time.Time) bool {
+    return key.retiredAt.IsZero() || now.Sub(key.retiredAt) <= r.gracePeriod
+}
+
+// RotateSigningKey makes a new key the active signing key and returns its kid.
+// A nil key generates a new RSA key. The previous key keeps verifying tokens
+// for the grace period so tokens issued before the rotation stay valid.
+func RotateSigningKey(privateKey *rsa.PrivateKey) (string, error) {
+    if privateKey == nil {
+        var err error
+        privateKey, err = rsa.GenerateKey(rand.Reader, generatedSigningKeyBits)
+        if err != nil {
+            return "", errors.NewError("E4001", "Failed to generate signing key", nil)
+        }
+    }
+    key := &signingKey{kid: keyID(&privateKey.PublicKey), privateKey: privateKey, publicKey: &privateKey.PublicKey}
+
+    jwtKeys.mu.Lock()
+    defer jwtKeys.mu.Unlock()
+
+    if jwtKeys.activeKID == "" {
+        return "", errors.NewError("E1001", "JWT manager is not initialized", nil)
+    }
+    if key.kid == jwtKeys.activeKID {
+        return key.kid, nil
+    }
+
+    // Other replicas must be able to verify the new key before it signs
+    if err := publishSharedKey(context.Background(), key, 0); err != nil {
+        return "", err
+    }
+
+    now := time.Now().UTC()
+    jwtKeys.keys[jwtKeys.activeKID].retiredAt = now
+    jwtKeys.keys[key.kid] = key
+    previous := jwtKeys.activeKID
+    jwtKeys.activeKID = key.kid
+
+    // Drop keys that can no longer verify anything
+    for kid, k := range jwtKeys.keys {
+        if !jwtKeys.usable(k, now) {
+            delete(jwtKeys.keys, kid)
+        }
+    }
+
+    // The shared copy of the retired key expires with its grace period; if
+    // that fails it lingers, which only keeps an old public key verifiable
+    if err := publishSharedKey(context.Background(), jwtKeys.keys[previous], jwtKeys.gracePeriod); err != nil {
+        logging.Error("Failed to expire shared signing key", err, logging.Field("kid", previous))
+    }
+
+    logging.Info("JWT signing key rotated",
+        zap.String("kid", key.kid),
+        zap.String("previous_kid", previous),
+        zap.Duration("grace_period", jwtKeys.gracePeriod))
+
+    return key.kid, nil
+}
+
+// JWKS returns the public keys that currently verify tokens, so other
+// services can validate tokens without sharing the private key. With a
+// shared store it includes the keys of every replica.
+func JWKS() JSONWebKeySet {
+    jwtKeys.mu.RLock()
+    now := time.Now()
+    keys := make(map[string]JSONWebKey, len(jwtKeys.keys))
+    for _, key := range jwtKeys.keys {
+        if jwtKeys.usable(key, now) {
+            keys[key.kid] = toJSONWebKey(key.kid, key.publicKey)
+        }
+    }
+    jwtKeys.mu.RUnlock()
+
+    shared, err := listSharedKeys()
+    if err != nil {
+        logging.Error("Failed to list shared signing keys", err)
+    }
+    for _, key := range shared {
+        if _, ok := keys[key.Kid]; !ok {
+            keys[key.Kid] = key
+        }
+    }
+
+    set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(keys))}
+    for _, key := range keys {
+        set.Keys = append(set.Keys, key)
+    }
+    sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+    return set
+}
+
+// ShareSigningKeys publishes the keyring's public keys to store and makes
+// rotations publish theirs, so every replica sharing the store verifies
+// tokens signed by any other and serves their keys in its JWKS. Private keys
+// never leave the replica. Call it after InitJWTManager; a nil store stops
+// sharing.
+func ShareSigningKeys(ctx context.Context, store *redis.Client) error {
+    sharedKeys.Lock()
+    sharedKeys.store = store
+    sharedKeys.Unlock()
+    if store == nil {
+        return nil
+    }
+
+    jwtKeys.mu.RLock()
+    defer jwtKeys.mu.RUnlock()
+
+    if jwtKeys.activeKID == "" {
+        return errors.NewError("E1001", "JWT manager is not initialized", nil)
+    }
+    now := time.Now()
+    for _, key := range jwtKeys.keys {
+        ttl := time.Duration(0)
+        if !key.retiredAt.IsZero() {
+            ttl = jwtKeys.gracePeriod - now.Sub(key.retiredAt)
+            if ttl <= 0 {
+                continue
+            }
+        }
+        if err := publishSharedKey(ctx, key, ttl); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// sharedKeyStore returns the shared key store, or nil when keys are not shared
+func sharedKeyStore() *redis.Client {
+    sharedKeys.RLock()
+    defer sharedKeys.RUnlock()
+    return sharedKeys.store
+}
+
+// publishSharedKey stores a public key for other replicas; a ttl of zero
+// keeps an active key until it is retired
+func publishSharedKey(ctx context.Context, key *signingKey, ttl time.Duration) error {
+    store := sharedKeyStore()
+    if store == nil || key == nil {
+        return nil
+    }
+
+    data, err := json.Marshal(toJSONWebKey(key.kid, key.publicKey))
+    if err != nil {
+        return errors.NewError("E1001", "Failed to encode signing key", nil)
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, sharedKeyTimeout)
+    defer cancel()
+
+    pipe := store.TxPipeline()
+    pipe.Set(ctx, sharedKeyPrefix+key.kid, data, ttl)
+    pipe.SAdd(ctx, sharedKeySet, key.kid)
+    if _, err := pipe.Exec(ctx); err != nil {
+        return errors.NewError("E1001", "Failed to share signing key", map[string]interface{}{
+            "kid": key.kid,
+        })
+    }
+    return nil
+}
+
+// lookupSharedKey fetches a public key another replica published
+func lookupSharedKey(kid string) (*rsa.PublicKey, bool) {
+    store := sharedKeyStore()
+    if store == nil || kid == "" {
+        return nil, false
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), sharedKeyTimeout)
+    defer cancel()
+
+    data, err := store.Get(ctx, sharedKeyPrefix+kid).Bytes()
+    if err != nil {
+        return nil, false
+    }
+    var jwk JSONWebKey
+    if err := json.Unmarshal(data, &jwk); err != nil {
+        return nil, false
+    }
+    publicKey, err := fromJSONWebKey(jwk)
+    if err != nil || keyID(publicKey) != kid {
+        return nil, false
+    }
+    return publicKey, true
+}
+
+// listSharedKeys returns the public keys every replica published, pruning
+// kids whose keys have expired
+func listSharedKeys() ([]JSONWebKey, error) {
+    store := sharedKeyStore()
+    if store == nil {
+        return nil, nil
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), sharedKeyTimeout)
+    defer cancel()
+
+    kids, err := store.SMembers(ctx, sharedKeySet).Result()
+    if err != nil || len(kids) == 0 {
+        return nil, err
+    }
+    names := make([]string, len(kids))
+    for i, kid := range kids {
+        names[i] = sharedKeyPrefix + kid
+    }
+    values, err := store.MGet(ctx, names...).Result()
+    if err != nil {
+        return nil, err
+    }
+
+    var keys []JSONWebKey
+    var expired []interface{}
+    for i, value := range values {
+        data, ok := value.(string)
+        if !ok {
+            expired = append(expired, kids[i])
+            continue
+        }
+        var jwk JSONWebKey
+        if err := json.Unmarshal([]byte(data), &jwk); err == nil {
+            keys = append(keys, jwk)
+        }
+    }
+    if len(expired) > 0 {
+        store.SRem(ctx, sharedKeySet, expired...)
+    }
+    return keys, nil
+}
+
+// toJSONWebKey returns the JWK form of a public key
+func toJSONWebKey(kid string, publicKey *rsa.PublicKey) JSONWebKey {
+    return JSONWebKey{
+        Kty: "RSA",
+        Use: "sig",
+        Alg: "RS256",
+        Kid: kid,
+        N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+        E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+    }
+}
+
+// fromJSONWebKey parses the public key of an RSA JWK
+func fromJSONWebKey(jwk JSONWebKey) (*rsa.PublicKey, error) {
+    if jwk.Kty != "RSA" {
+        return nil, errors.NewError("E1001", "Unsupported key type", map[string]interface{}{
+            "kty": jwk.Kty,
+        })
+    }
+    n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+    if err != nil {
+        return nil, errors.NewError("E1001", "Invalid key modulus", nil)
+    }
+    e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+    if err != nil {
+        return nil, errors.NewError("E1001", "Invalid key exponent", nil)
+    }
+    return &rsa.PublicKey{
+        N: new(big.Int).SetBytes(n),
+        E: int(new(big.Int).SetBytes(e).Int64()),
+    }, nil
+}
+
+// JWKSHandler serves the JWKS document, typically at /.well-known/jwks.json
+func JWKSHandler(w http.ResponseWriter, r *http.Request) {
+    body, err := json.Marshal(JWKS())
+    if err != nil {
+        http.Error(w, "failed to encode key set", http.StatusInternalServerError)
+        return
+    }
+    w.Header().Set("Content-Type", "application/jwk-set+json")
+    w.Header().Set("Cache-Control", "public, max-age=300")
+    w.Write(body)
+}
+
+// keyID derives a stable kid from the RFC 7638 thumbprint of a public key
+func keyID(publicKey *rsa.PublicKey) string {
+    thumbprint, _ := json.Marshal(struct {
+        E   string `json:"e"`
+        Kty string `json:"kty"`
+        N   string `json:"n"`
+    }{
+        E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+        Kty: "RSA",
+        N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+    })
+    sum := sha256.Sum256(thumbprint)
+    return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -33,6 +33,7 @@ type OAuthManager struct {
     provider        *oidc.Provider
     verifier        *oidc.IDTokenVerifier
     tokenBlacklist  *redis.Client
+    deviceAuthURL   string
     securityLogger  logging.SecurityLogger
     securityConfig  SecurityConfig
     rateLimiter    *sync.Map
@@ -46,6 +47,11 @@ type OAuthConfig struct {
     RedirectURL     string
     ProviderURL     string
     SecurityOptions SecurityConfig
+
+    // DeviceAuthURL and TokenURL override the provider's discovered device
+    // authorization and token endpoints
+    DeviceAuthURL   string
+    TokenURL        string
 }
 
 // InitOAuthManager initializes the OAuth manager with security configuration
@@ -86,6 +92,23 @@ func InitOAuthManager(config OAuthConfig) (*OAuthManager, error) {
         rateLimiter:   &sync.Map{},
     }
 
+    // Tokens revoked on any replica are rejected by ValidateToken
+    SetRevocationCheck(manager.IsTokenRevoked)
+
+    // The device grant is optional; providers that support it advertise it
+    var discovery struct {
+        DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+    }
+    if err := provider.Claims(&discovery); err == nil {
+        manager.deviceAuthURL = discovery.DeviceAuthorizationEndpoint
+    }
+    if config.DeviceAuthURL != "" {
+        manager.deviceAuthURL = config.DeviceAuthURL
+    }
+    if config.TokenURL != "" {
+        oauth2Config.Endpoint.TokenURL = config.TokenURL
+    }
+
     logging.Info("OAuth manager initialized",
         zap.String("client_id", config.ClientID),
         zap.String("provider", config.ProviderURL))
@@ -137,6 +160,16 @@ func (m *OAuthManager) ExchangeAuthCode(ctx context.Context, code, state string)
         return nil, nil, errors.NewError("E1001", "Failed to exchange authorization code", nil)
     }
 
+    return m.completeTokenExchange(ctx, oauth2Token)
+}
+
+// completeTokenExchange verifies the provider's ID token and replaces the
+// provider tokens with a BlackPoint JWT and a rotating refresh token
+func (m *OAuthManager) completeTokenExchange(ctx context.Context, oauth2Token *oauth2.Token) (*oauth2.Token, *oidc.IDToken, error) {
+    if m.verifier == nil {
+        return nil, nil, errors.NewError("E2001", "OIDC provider not configured", nil)
+    }
+
     // Verify ID token
     rawIDToken, ok := oauth2Token.Extra("id_token").(string)
     if !ok {
@@ -162,6 +195,13 @@ func (m *OAuthManager) ExchangeAuthCode(ctx context.Context, code, state string)
 
     oauth2Token.AccessToken = bpToken
 
+    // Replace the provider's refresh token with a rotating one of our own
+    refreshToken, err := m.IssueRefreshToken(ctx, claims)
+    if err != nil {
+        return nil, nil, err
+    }
+    oauth2Token.RefreshToken = refreshToken
+
     logging.Info("OAuth token exchange completed",
         zap.String("client_id", claims["client_id"].(string)),
         zap.Time("expiry", oauth2Token.Expiry))
@@ -195,8 +235,12 @@ func (m *OAuthManager) RevokeToken(ctx context.Context, token string) error {
 
 func (m *OAuthManager) checkRateLimit(ctx context.Context) bool {
     clientIP := ctx.Value("client_ip").(string)
-    key := "ratelimit:" + clientIP
+    return m.checkRateLimitKey(ctx, "ratelimit:"+clientIP)
+}
 
+// checkRateLimitKey counts a request against a per-minute budget of
+// RateLimitPerMinute shared by everything using the same key
+func (m *OAuthManager) checkRateLimitKey(ctx context.Context, key string) bool {
     count, _ := m.tokenBlacklist.Incr(ctx, key).Result()
     if count == 1 {
         m.tokenBlacklist.Expire(ctx, key, time.Minute)
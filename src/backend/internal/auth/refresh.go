@@ -0,0 +1,201 @@
+// Package auth provides rotating refresh tokens with reuse detection
+package auth
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "sync"
+    "time"
+
+    "github.com/go-redis/redis/v8" // v8.11.5
+    "golang.org/x/oauth2"          // v0.12.0
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+)
+
+// Redis key prefixes for refresh token lineage, kept in the blacklist store
+const (
+    refreshTokenPrefix   = "refresh:"
+    refreshRotatedPrefix = "refresh_rotated:"
+    refreshFamilyPrefix  = "refresh_family:"
+    refreshRevokedPrefix = "refresh_family_revoked:"
+    refreshAccessPrefix  = "refresh_family_access:"
+)
+
+// refreshRecord is what a refresh token grants: the family it belongs to and
+// the claims of the access tokens it can be exchanged for
+type refreshRecord struct {
+    Family      string            `json:"family"`
+    ClientID    string            `json:"client_id"`
+    Permissions []string          `json:"permissions"`
+    Metadata    map[string]string `json:"metadata"`
+    IssuedAt    time.Time         `json:"issued_at"`
+}
+
+// NewOAuthManagerWithStore creates an OAuth manager backed by an existing
+// Redis client without contacting the OIDC provider. It supports token
+// revocation and refresh but not the authorization code flow; device grants
+// can be started and polled against the configured DeviceAuthURL and TokenURL.
+// ValidateToken consults the manager's blacklist from then on.
+func NewOAuthManagerWithStore(config OAuthConfig, store *redis.Client) *OAuthManager {
+    manager := &OAuthManager{
+        config: &oauth2.Config{
+            ClientID:     config.ClientID,
+            ClientSecret: config.ClientSecret,
+            Endpoint:     oauth2.Endpoint{TokenURL: config.TokenURL},
+        },
+        deviceAuthURL:  config.DeviceAuthURL,
+        tokenBlacklist: store,
+        securityConfig: config.SecurityOptions,
+        rateLimiter:    &sync.Map{},
+    }
+    SetRevocationCheck(manager.IsTokenRevoked)
+    return manager
+}
+
+// IssueRefreshToken starts a new token family for the claims and returns its
+// first refresh token. Refresh tokens are opaque; only their hash is stored.
+func (m *OAuthManager) IssueRefreshToken(ctx context.Context, claims map[string]interface{}) (string, error) {
+    clientID, _ := claims["client_id"].(string)
+    if clientID == "" {
+        return "", errors.NewError("E1001", "Refresh token requires a client_id claim", nil)
+    }
+    permissions, _ := claims["permissions"].([]string)
+    metadata, _ := claims["metadata"].(map[string]string)
+
+    record := refreshRecord{
+        Family:      generateCodeVerifier(),
+        ClientID:    clientID,
+        Permissions: permissions,
+        Metadata:    metadata,
+    }
+    return m.storeRefreshToken(ctx, record)
+}
+
+// RotateRefreshToken exchanges a refresh token for a new access token and a
+// new refresh token in the same family. Each refresh token is single use:
+// presenting one that was already rotated means it was copied, so the whole
+// family is revoked and the caller must authenticate again.
+func (m *OAuthManager) RotateRefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
+    hash := hashRefreshToken(refreshToken)
+
+    raw, err := m.tokenBlacklist.Get(ctx, refreshTokenPrefix+hash).Bytes()
+    if err == redis.Nil {
+        return "", "", errors.NewError("E1001", "Invalid or expired refresh token", nil)
+    }
+    if err != nil {
+        return "", "", errors.NewError("E1001", "Failed to load refresh token", nil)
+    }
+
+    var record refreshRecord
+    if err := json.Unmarshal(raw, &record); err != nil {
+        return "", "", errors.NewError("E1001", "Corrupt refresh token record", nil)
+    }
+
+    revoked, err := m.tokenBlacklist.Exists(ctx, refreshRevokedPrefix+record.Family).Result()
+    if err != nil {
+        return "", "", errors.NewError("E1001", "Failed to check refresh token family", nil)
+    }
+    if revoked > 0 {
+        return "", "", errors.NewError("E1001", "Refresh token family has been revoked", nil)
+    }
+
+    // Claiming the rotation is atomic, so of two concurrent uses only one wins
+    claimed, err := m.tokenBlacklist.SetNX(ctx, refreshRotatedPrefix+hash, time.Now().UTC().String(),
+        m.securityConfig.TokenBlacklistTTL).Result()
+    if err != nil {
+        return "", "", errors.NewError("E1001", "Failed to rotate refresh token", nil)
+    }
+    if !claimed {
+        m.revokeRefreshFamily(ctx, record)
+        return "", "", errors.NewError("E1001", "Refresh token reuse detected", nil)
+    }
+
+    accessToken, err := GenerateToken(map[string]interface{}{
+        "client_id":   record.ClientID,
+        "permissions": record.Permissions,
+        "metadata":    record.Metadata,
+    })
+    if err != nil {
+        return "", "", err
+    }
+    m.tokenBlacklist.SAdd(ctx, refreshAccessPrefix+record.Family, accessToken)
+    m.tokenBlacklist.Expire(ctx, refreshAccessPrefix+record.Family, m.securityConfig.TokenBlacklistTTL)
+
+    newRefreshToken, err := m.storeRefreshToken(ctx, record)
+    if err != nil {
+        return "", "", err
+    }
+
+    logging.Info("Refresh token rotated",
+        zap.String("client_id", record.ClientID),
+        zap.String("family", record.Family))
+
+    return accessToken, newRefreshToken, nil
+}
+
+// storeRefreshToken issues a refresh token for a record and adds it to the
+// record's family. Lineage entries live for TokenBlacklistTTL.
+func (m *OAuthManager) storeRefreshToken(ctx context.Context, record refreshRecord) (string, error) {
+    token := generateCodeVerifier()
+    hash := hashRefreshToken(token)
+    ttl := m.securityConfig.TokenBlacklistTTL
+
+    record.IssuedAt = time.Now().UTC()
+    raw, err := json.Marshal(record)
+    if err != nil {
+        return "", errors.NewError("E4001", "Failed to encode refresh token", nil)
+    }
+
+    pipe := m.tokenBlacklist.TxPipeline()
+    pipe.Set(ctx, refreshTokenPrefix+hash, raw, ttl)
+    pipe.SAdd(ctx, refreshFamilyPrefix+record.Family, hash)
+    pipe.Expire(ctx, refreshFamilyPrefix+record.Family, ttl)
+    if _, err := pipe.Exec(ctx); err != nil {
+        return "", errors.NewError("E1001", "Failed to store refresh token", nil)
+    }
+    return token, nil
+}
+
+// revokeRefreshFamily invalidates every refresh token in a family and
+// blacklists the access tokens issued from it
+func (m *OAuthManager) revokeRefreshFamily(ctx context.Context, record refreshRecord) {
+    ttl := m.securityConfig.TokenBlacklistTTL
+    m.tokenBlacklist.Set(ctx, refreshRevokedPrefix+record.Family, time.Now().UTC().String(), ttl)
+
+    hashes, _ := m.tokenBlacklist.SMembers(ctx, refreshFamilyPrefix+record.Family).Result()
+    for _, hash := range hashes {
+        m.tokenBlacklist.Del(ctx, refreshTokenPrefix+hash)
+    }
+
+    accessTokens, _ := m.tokenBlacklist.SMembers(ctx, refreshAccessPrefix+record.Family).Result()
+    for _, token := range accessTokens {
+        m.tokenBlacklist.Set(ctx, "blacklist:"+token, time.Now().UTC().String(), ttl)
+    }
+
+    logging.SecurityAudit("Refresh token reuse detected, token family revoked", map[string]interface{}{
+        "client_id":             record.ClientID,
+        "family":                record.Family,
+        "revoked_refresh_count": len(hashes),
+        "revoked_access_count":  len(accessTokens),
+    })
+}
+
+// IsTokenRevoked reports whether an access token was blacklisted by
+// RevokeToken or by a refresh token family revocation
+func (m *OAuthManager) IsTokenRevoked(ctx context.Context, token string) (bool, error) {
+    n, err := m.tokenBlacklist.Exists(ctx, "blacklist:"+token).Result()
+    if err != nil {
+        return false, errors.NewError("E1001", "Failed to check token blacklist", nil)
+    }
+    return n > 0, nil
+}
+
+// hashRefreshToken returns the storage key of a refresh token
+func hashRefreshToken(token string) string {
+    sum := sha256.Sum256([]byte(token))
+    return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,201 @@
+// Package collector provides deduplication of retried event deliveries
+package collector
+
+import (
+    "container/list"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "sync"
+    "time"
+
+    "github.com/go-redis/redis/v8" // v8.11.5
+)
+
+// Default deduplication settings
+const (
+    defaultDedupWindowSize = 100000
+    defaultDedupTTL        = 10 * time.Minute
+    defaultDedupKeyPrefix  = "collector:seen:"
+)
+
+// SeenSet records event keys that have already been collected
+type SeenSet interface {
+    // MarkSeen records key for ttl and reports whether it was already present
+    MarkSeen(ctx context.Context, key string, ttl time.Duration) (bool, error)
+    // Forget removes key so its next MarkSeen reports it as new
+    Forget(ctx context.Context, key string) error
+}
+
+// DedupConfig configures duplicate detection for collected events
+type DedupConfig struct {
+    Enabled bool
+
+    // IDField names a top-level payload field holding a unique event ID.
+    // Payloads without it are keyed on a hash of their content.
+    IDField string
+
+    // WindowSize bounds the number of keys held by the in-memory seen-set
+    WindowSize int
+
+    // TTL is how long a key is remembered
+    TTL time.Duration
+
+    // Store overrides the in-memory seen-set, e.g. with a RedisSeenSet so
+    // that all collectors in a cluster share one window
+    Store SeenSet
+}
+
+// Deduplicator detects events that were already collected within its window
+type Deduplicator struct {
+    idField string
+    ttl     time.Duration
+    store   SeenSet
+}
+
+// NewDeduplicator creates a Deduplicator, defaulting to an in-memory LRU seen-set
+func NewDeduplicator(config DedupConfig) *Deduplicator {
+    if config.WindowSize <= 0 {
+        config.WindowSize = defaultDedupWindowSize
+    }
+    if config.TTL <= 0 {
+        config.TTL = defaultDedupTTL
+    }
+    if config.Store == nil {
+        config.Store = NewLRUSeenSet(config.WindowSize)
+    }
+
+    return &Deduplicator{
+        idField: config.IDField,
+        ttl:     config.TTL,
+        store:   config.Store,
+    }
+}
+
+// IsDuplicate records the event and reports whether it was seen before
+func (d *Deduplicator) IsDuplicate(ctx context.Context, eventData []byte) (bool, error) {
+    return d.store.MarkSeen(ctx, d.key(eventData), d.ttl)
+}
+
+// Forget releases an event recorded by IsDuplicate that was never collected,
+// so that its redelivery is accepted instead of dropped as a duplicate
+func (d *Deduplicator) Forget(ctx context.Context, eventData []byte) error {
+    return d.store.Forget(ctx, d.key(eventData))
+}
+
+// key returns the event ID when configured and present, else a content hash
+func (d *Deduplicator) key(eventData []byte) string {
+    if d.idField != "" {
+        var fields map[string]interface{}
+        if err := json.Unmarshal(eventData, &fields); err == nil {
+            switch id := fields[d.idField].(type) {
+            case string:
+                if id != "" {
+                    return "id:" + id
+                }
+            case float64:
+                return "id:" + fmt.Sprint(id)
+            }
+        }
+    }
+
+    sum := sha256.Sum256(eventData)
+    return "hash:" + hex.EncodeToString(sum[:])
+}
+
+// LRUSeenSet is a bounded in-memory seen-set for single-node deployments.
+// When full, the least recently seen key is evicted.
+type LRUSeenSet struct {
+    mu      sync.Mutex
+    size    int
+    order   *list.List
+    entries map[string]*list.Element
+}
+
+// lruEntry is a key held by an LRUSeenSet
+type lruEntry struct {
+    key     string
+    expires time.Time
+}
+
+// NewLRUSeenSet creates an in-memory seen-set holding at most size keys
+func NewLRUSeenSet(size int) *LRUSeenSet {
+    if size <= 0 {
+        size = defaultDedupWindowSize
+    }
+    return &LRUSeenSet{
+        size:    size,
+        order:   list.New(),
+        entries: make(map[string]*list.Element),
+    }
+}
+
+// MarkSeen records key for ttl and reports whether it was already present
+func (s *LRUSeenSet) MarkSeen(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+    now := time.Now()
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if elem, ok := s.entries[key]; ok {
+        entry := elem.Value.(*lruEntry)
+        if now.Before(entry.expires) {
+            s.order.MoveToFront(elem)
+            return true, nil
+        }
+        entry.expires = now.Add(ttl)
+        s.order.MoveToFront(elem)
+        return false, nil
+    }
+
+    s.entries[key] = s.order.PushFront(&lruEntry{key: key, expires: now.Add(ttl)})
+    for s.order.Len() > s.size {
+        oldest := s.order.Back()
+        s.order.Remove(oldest)
+        delete(s.entries, oldest.Value.(*lruEntry).key)
+    }
+
+    return false, nil
+}
+
+// Forget removes key from the window
+func (s *LRUSeenSet) Forget(ctx context.Context, key string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if elem, ok := s.entries[key]; ok {
+        s.order.Remove(elem)
+        delete(s.entries, key)
+    }
+    return nil
+}
+
+// RedisSeenSet is a seen-set shared by all collectors using the same Redis
+type RedisSeenSet struct {
+    client *redis.Client
+    prefix string
+}
+
+// NewRedisSeenSet creates a Redis-backed seen-set; an empty prefix uses the default
+func NewRedisSeenSet(client *redis.Client, prefix string) *RedisSeenSet {
+    if prefix == "" {
+        prefix = defaultDedupKeyPrefix
+    }
+    return &RedisSeenSet{client: client, prefix: prefix}
+}
+
+// MarkSeen records key for ttl and reports whether it was already present
+func (s *RedisSeenSet) MarkSeen(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+    added, err := s.client.SetNX(ctx, s.prefix+key, 1, ttl).Result()
+    if err != nil {
+        return false, err
+    }
+    return !added, nil
+}
+
+// Forget removes key from the shared window
+func (s *RedisSeenSet) Forget(ctx context.Context, key string) error {
+    return s.client.Del(ctx, s.prefix+key).Err()
+}
@@ -0,0 +1,256 @@
+// Package collector provides tolerant deserialization of incoming event payloads
+package collector
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// Recovery tag fields added to events whose payload was repaired
+const (
+    recoveredField     = "_blackpoint_recovered"
+    recoveryFixesField = "_blackpoint_recovery_fixes"
+)
+
+// Recovery fix identifiers recorded on recovered events
+const (
+    FixTrailingCommas = "trailing_commas"
+    FixUnquotedKeys   = "unquoted_keys"
+    FixSingleQuotes   = "single_quotes"
+)
+
+// Deserializer normalizes raw event payloads into valid JSON
+type Deserializer interface {
+    // Deserialize returns the normalized payload and whether recovery was required
+    Deserialize(data []byte) ([]byte, bool, error)
+}
+
+// DeadLetterHandler receives payloads that could not be parsed
+type DeadLetterHandler interface {
+    DeadLetter(ctx context.Context, data []byte, reason error) error
+}
+
+// StrictDeserializer accepts only well-formed JSON
+type StrictDeserializer struct{}
+
+// Deserialize rejects any payload that is not valid JSON
+func (StrictDeserializer) Deserialize(data []byte) ([]byte, bool, error) {
+    if !json.Valid(data) {
+        return nil, false, errors.NewError("E3001", "malformed event payload", nil)
+    }
+    return data, false, nil
+}
+
+// LenientOptions selects which malformations the LenientDeserializer repairs
+type LenientOptions struct {
+    TrailingCommas bool
+    UnquotedKeys   bool
+    SingleQuotes   bool
+}
+
+// DefaultLenientOptions enables recovery of all supported malformations
+func DefaultLenientOptions() LenientOptions {
+    return LenientOptions{
+        TrailingCommas: true,
+        UnquotedKeys:   true,
+        SingleQuotes:   true,
+    }
+}
+
+// LenientDeserializer attempts to recover common JSON malformations
+type LenientDeserializer struct {
+    options LenientOptions
+}
+
+// NewLenientDeserializer creates a LenientDeserializer with the given recovery options
+func NewLenientDeserializer(options LenientOptions) *LenientDeserializer {
+    return &LenientDeserializer{options: options}
+}
+
+// Deserialize passes valid JSON through unchanged and repairs configured malformations
+func (d *LenientDeserializer) Deserialize(data []byte) ([]byte, bool, error) {
+    if json.Valid(data) {
+        return data, false, nil
+    }
+
+    repaired, fixes := d.repair(data)
+    if len(fixes) == 0 || !json.Valid(repaired) {
+        return nil, false, errors.NewError("E3001", "unrecoverable event payload", map[string]interface{}{
+            "attempted_fixes": fixes,
+        })
+    }
+
+    tagged, err := tagRecoveredEvent(repaired, fixes)
+    if err != nil {
+        return nil, false, err
+    }
+    return tagged, true, nil
+}
+
+// repair rewrites the payload in a single pass, tracking string state so
+// that content inside string literals is never modified
+func (d *LenientDeserializer) repair(data []byte) ([]byte, []string) {
+    var out bytes.Buffer
+    out.Grow(len(data) + 16)
+
+    applied := make(map[string]bool)
+    containers := make([]byte, 0, 8)
+    expectKey := false
+
+    for i := 0; i < len(data); i++ {
+        c := data[i]
+
+        switch {
+        case c == '"':
+            end := scanString(data, i, '"')
+            out.Write(data[i:end])
+            i = end - 1
+            expectKey = false
+
+        case c == '\'' && d.options.SingleQuotes:
+            end := scanString(data, i, '\'')
+            out.Write(convertSingleQuoted(data[i:end]))
+            applied[FixSingleQuotes] = true
+            i = end - 1
+            expectKey = false
+
+        case c == ',' && d.options.TrailingCommas && closesContainer(data, i+1):
+            applied[FixTrailingCommas] = true
+
+        case c == '{' || c == '[':
+            out.WriteByte(c)
+            containers = append(containers, c)
+            expectKey = c == '{'
+
+        case c == '}' || c == ']':
+            out.WriteByte(c)
+            if len(containers) > 0 {
+                containers = containers[:len(containers)-1]
+            }
+            expectKey = false
+
+        case c == ',':
+            out.WriteByte(c)
+            expectKey = len(containers) > 0 && containers[len(containers)-1] == '{'
+
+        case expectKey && d.options.UnquotedKeys && isIdentStart(c):
+            end := i
+            for end < len(data) && isIdentPart(data[end]) {
+                end++
+            }
+            if next := nextNonSpace(data, end); next != -1 && data[next] == ':' {
+                out.WriteByte('"')
+                out.Write(data[i:end])
+                out.WriteByte('"')
+                applied[FixUnquotedKeys] = true
+            } else {
+                out.Write(data[i:end])
+            }
+            i = end - 1
+            expectKey = false
+
+        default:
+            out.WriteByte(c)
+            if !isSpace(c) {
+                expectKey = false
+            }
+        }
+    }
+
+    fixes := make([]string, 0, len(applied))
+    for _, fix := range []string{FixTrailingCommas, FixUnquotedKeys, FixSingleQuotes} {
+        if applied[fix] {
+            fixes = append(fixes, fix)
+        }
+    }
+    return out.Bytes(), fixes
+}
+
+// tagRecoveredEvent marks an object payload as recovered; non-object payloads are returned as-is
+func tagRecoveredEvent(data []byte, fixes []string) ([]byte, error) {
+    var obj map[string]interface{}
+    if err := json.Unmarshal(data, &obj); err != nil || obj == nil {
+        return data, nil
+    }
+
+    obj[recoveredField] = true
+    obj[recoveryFixesField] = fixes
+
+    tagged, err := json.Marshal(obj)
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to tag recovered event", nil)
+    }
+    return tagged, nil
+}
+
+// scanString returns the index just past the string literal starting at start
+func scanString(data []byte, start int, quote byte) int {
+    for i := start + 1; i < len(data); i++ {
+        switch data[i] {
+        case '\\':
+            i++
+        case quote:
+            return i + 1
+        }
+    }
+    return len(data)
+}
+
+// convertSingleQuoted rewrites a single-quoted literal as a double-quoted JSON string
+func convertSingleQuoted(literal []byte) []byte {
+    inner := literal[1:]
+    if len(inner) > 0 && inner[len(inner)-1] == '\'' {
+        inner = inner[:len(inner)-1]
+    }
+
+    var out bytes.Buffer
+    out.WriteByte('"')
+    for i := 0; i < len(inner); i++ {
+        switch c := inner[i]; {
+        case c == '\\' && i+1 < len(inner) && inner[i+1] == '\'':
+            out.WriteByte('\'')
+            i++
+        case c == '\\' && i+1 < len(inner):
+            out.WriteByte(c)
+            out.WriteByte(inner[i+1])
+            i++
+        case c == '"':
+            out.WriteString(`\"`)
+        default:
+            out.WriteByte(c)
+        }
+    }
+    out.WriteByte('"')
+    return out.Bytes()
+}
+
+// closesContainer reports whether the next non-whitespace byte closes an object or array
+func closesContainer(data []byte, from int) bool {
+    next := nextNonSpace(data, from)
+    return next != -1 && (data[next] == '}' || data[next] == ']')
+}
+
+// nextNonSpace returns the index of the next non-whitespace byte, or -1
+func nextNonSpace(data []byte, from int) int {
+    for i := from; i < len(data); i++ {
+        if !isSpace(data[i]) {
+            return i
+        }
+    }
+    return -1
+}
+
+func isSpace(c byte) bool {
+    return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isIdentStart(c byte) bool {
+    return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+    return isIdentStart(c) || c == '-' || (c >= '0' && c <= '9')
+}
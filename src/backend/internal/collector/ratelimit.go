@@ -0,0 +1,180 @@
+// Package collector provides per-client throughput limits for event collection
+package collector
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "golang.org/x/time/rate" // v0.5.0
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// Default rate limiting settings
+const (
+    unknownClientID    = "unknown"
+    defaultIdleTimeout = 10 * time.Minute
+)
+
+// RateLimit is the sustained rate and burst allowed for one client
+type RateLimit struct {
+    EventsPerSecond float64
+    Burst           int
+}
+
+// RateLimitConfig configures per-client throughput limits
+type RateLimitConfig struct {
+    Enabled bool
+
+    // Default applies to clients without their own limit
+    Default RateLimit
+
+    // Clients overrides the default for specific client IDs
+    Clients map[string]RateLimit
+
+    // IdleTimeout is how long a client's bucket is kept once it stops
+    // sending; defaults to 10 minutes
+    IdleTimeout time.Duration
+}
+
+// clientIDKey carries the authenticated client ID of a collection request
+type clientIDKey struct{}
+
+// WithClientID returns a context attributing collected events to clientID.
+// It must come from the caller's authenticated identity: events are never
+// attributed by payload content, which any client can forge.
+func WithClientID(ctx context.Context, clientID string) context.Context {
+    return context.WithValue(ctx, clientIDKey{}, clientID)
+}
+
+// clientBucket is one client's token bucket and when it was last used
+type clientBucket struct {
+    limiter  *rate.Limiter
+    lastSeen time.Time
+}
+
+// ClientRateLimiter applies a token bucket per client so that one noisy
+// client cannot starve the others of the shared buffer
+type ClientRateLimiter struct {
+    mu        sync.Mutex
+    config    RateLimitConfig
+    limiters  map[string]*clientBucket
+    lastSweep time.Time
+}
+
+// NewClientRateLimiter creates a limiter from config
+func NewClientRateLimiter(config RateLimitConfig) (*ClientRateLimiter, error) {
+    if err := validateRateLimitConfig(config); err != nil {
+        return nil, err
+    }
+    if config.IdleTimeout <= 0 {
+        config.IdleTimeout = defaultIdleTimeout
+    }
+
+    return &ClientRateLimiter{
+        config:    config,
+        limiters:  make(map[string]*clientBucket),
+        lastSweep: time.Now(),
+    }, nil
+}
+
+// Allow consumes a token for clientID, reporting whether the event may proceed
+func (l *ClientRateLimiter) Allow(clientID string) bool {
+    now := time.Now()
+
+    l.mu.Lock()
+    if now.Sub(l.lastSweep) >= l.config.IdleTimeout {
+        l.evictIdle(now)
+    }
+    bucket, ok := l.limiters[clientID]
+    if !ok {
+        limit := l.limitFor(clientID)
+        bucket = &clientBucket{limiter: rate.NewLimiter(rate.Limit(limit.EventsPerSecond), limit.Burst)}
+        l.limiters[clientID] = bucket
+    }
+    bucket.lastSeen = now
+    l.mu.Unlock()
+
+    return bucket.limiter.AllowN(now, 1)
+}
+
+// TrackedClients returns the number of clients holding a bucket
+func (l *ClientRateLimiter) TrackedClients() int {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return len(l.limiters)
+}
+
+// evictIdle drops buckets idle for IdleTimeout. Only full buckets are
+// dropped, so a client returning later gets exactly the tokens it would
+// have had; callers hold l.mu.
+func (l *ClientRateLimiter) evictIdle(now time.Time) {
+    for clientID, bucket := range l.limiters {
+        if now.Sub(bucket.lastSeen) < l.config.IdleTimeout {
+            continue
+        }
+        if bucket.limiter.TokensAt(now) >= float64(bucket.limiter.Burst()) {
+            delete(l.limiters, clientID)
+        }
+    }
+    l.lastSweep = now
+}
+
+// Reload replaces the limits without a restart. Existing buckets keep their
+// tokens and take the new rate and burst immediately.
+func (l *ClientRateLimiter) Reload(config RateLimitConfig) error {
+    if err := validateRateLimitConfig(config); err != nil {
+        return err
+    }
+
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    if config.IdleTimeout <= 0 {
+        config.IdleTimeout = defaultIdleTimeout
+    }
+    l.config = config
+    for clientID, bucket := range l.limiters {
+        limit := l.limitFor(clientID)
+        bucket.limiter.SetLimit(rate.Limit(limit.EventsPerSecond))
+        bucket.limiter.SetBurst(limit.Burst)
+    }
+    return nil
+}
+
+// ClientID returns the authenticated client of a collection request. Events
+// collected without one share a single bucket.
+func (l *ClientRateLimiter) ClientID(ctx context.Context) string {
+    if clientID, ok := ctx.Value(clientIDKey{}).(string); ok && clientID != "" {
+        return clientID
+    }
+    return unknownClientID
+}
+
+// limitFor returns the configured limit for a client; callers hold l.mu
+func (l *ClientRateLimiter) limitFor(clientID string) RateLimit {
+    if limit, ok := l.config.Clients[clientID]; ok {
+        return limit
+    }
+    return l.config.Default
+}
+
+// validateRateLimitConfig checks that every limit admits events
+func validateRateLimitConfig(config RateLimitConfig) error {
+    limits := map[string]RateLimit{"default": config.Default}
+    for clientID, limit := range config.Clients {
+        limits[clientID] = limit
+    }
+
+    for clientID, limit := range limits {
+        if limit.EventsPerSecond <= 0 || limit.Burst <= 0 {
+            return errors.NewError("E2001", "rate limit requires positive events per second and burst", map[string]interface{}{
+                "client_id":         clientID,
+                "events_per_second": limit.EventsPerSecond,
+                "burst":             limit.Burst,
+            })
+        }
+    }
+    return nil
+}
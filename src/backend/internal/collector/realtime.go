@@ -9,8 +9,13 @@ import (
     "github.com/blackpoint/pkg/common/errors"
     "github.com/blackpoint/pkg/common/logging"
     "github.com/blackpoint/pkg/bronze/event"
+    "github.com/blackpoint/internal/streaming"
     "github.com/blackpoint/internal/streaming/producer"
+    bpmetrics "github.com/blackpoint/internal/metrics"
     "github.com/prometheus/client_golang/prometheus" // v1.16.0
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
 )
 
 // Default configuration values
@@ -21,6 +26,18 @@ const (
     defaultCollectionTimeout = 5 * time.Second
 )
 
+// Overflow policies applied by CollectEvent when the event buffer is full
+const (
+    // OverflowBlock waits for buffer space until the context is done or the
+    // collection timeout expires
+    OverflowBlock = "block"
+    // OverflowDropNewest discards the incoming event and reports success
+    OverflowDropNewest = "drop-newest"
+    // OverflowReject returns an E4002 error immediately so the caller can
+    // apply its own backpressure
+    OverflowReject = "reject"
+)
+
 var (
     // Metrics collectors
     metrics = struct {
@@ -29,6 +46,10 @@ var (
         eventBufferSize      *prometheus.GaugeVec
         collectionErrors     *prometheus.CounterVec
         eventsCollected     *prometheus.CounterVec
+        duplicatesDropped   prometheus.Counter
+        bufferUtilization   *prometheus.GaugeVec
+        overflowEvents      *prometheus.CounterVec
+        rateLimited         *prometheus.CounterVec
     }{
         eventCollectionTime: prometheus.NewHistogramVec(
             prometheus.HistogramOpts{
@@ -67,20 +88,75 @@ var (
             },
             []string{"status"},
         ),
+        duplicatesDropped: prometheus.NewCounter(
+            prometheus.CounterOpts{
+                Name: "blackpoint_collector_duplicates_total",
+                Help: "Total number of duplicate events dropped",
+            },
+        ),
+        bufferUtilization: prometheus.NewGaugeVec(
+            prometheus.GaugeOpts{
+                Name: "blackpoint_collector_buffer_utilization",
+                Help: "Fraction of the event buffer in use",
+            },
+            []string{"collector_id"},
+        ),
+        overflowEvents: prometheus.NewCounterVec(
+            prometheus.CounterOpts{
+                Name: "blackpoint_collector_rejected_events_total",
+                Help: "Total number of events dropped or rejected because the buffer was full",
+            },
+            []string{"policy"},
+        ),
+        rateLimited: prometheus.NewCounterVec(
+            prometheus.CounterOpts{
+                Name: "blackpoint_collector_rate_limited_total",
+                Help: "Total number of events rejected because the client exceeded its rate limit",
+            },
+            []string{"client_id"},
+        ),
     }
+
+    registerMetricsOnce sync.Once
 )
 
+// bufferedEvent is a collected event awaiting publication with the trace
+// headers of the span that collected it
+type bufferedEvent struct {
+    data    []byte
+    headers map[string]string
+}
+
 // RealtimeCollector manages real-time collection of security events
 type RealtimeCollector struct {
     processor     *event.EventProcessor
     producer      *producer.Producer
-    eventBuffer   chan []byte
+    eventBuffer   chan bufferedEvent
     flushInterval time.Duration
     batchSize     int
     ctx           context.Context
     cancel        context.CancelFunc
     wg            sync.WaitGroup
     collectorID   string
+    deserializer  Deserializer
+    deadLetter    DeadLetterHandler
+    dedup         *Deduplicator
+    overflowPolicy string
+    rateLimiter   *ClientRateLimiter
+    tracer        trace.Tracer
+
+    // acceptMu guards draining so no event is buffered once a drain begins
+    acceptMu  sync.RWMutex
+    draining  bool
+    started   bool
+    drainOnce sync.Once
+    drainCh   chan struct{}
+    drained   chan struct{}
+
+    // intakeClosed is closed when a drain begins, releasing senders blocked
+    // on a full buffer so the drain can take acceptMu
+    intakeClosed    chan struct{}
+    closeIntakeOnce sync.Once
 }
 
 // CollectorConfig contains configuration for the RealtimeCollector
@@ -88,6 +164,23 @@ type CollectorConfig struct {
     BufferSize    int
     BatchSize     int
     FlushInterval time.Duration
+
+    // Deserializer normalizes incoming payloads; defaults to StrictDeserializer.
+    // Use a LenientDeserializer to recover slightly malformed vendor JSON.
+    Deserializer Deserializer
+
+    // DeadLetter receives payloads that cannot be parsed; optional
+    DeadLetter DeadLetterHandler
+
+    // Dedup drops retried deliveries of the same event; disabled by default
+    Dedup DedupConfig
+
+    // OverflowPolicy controls CollectEvent when the buffer is full; one of
+    // OverflowBlock (default), OverflowDropNewest or OverflowReject
+    OverflowPolicy string
+
+    // RateLimit caps each client's event rate; disabled by default
+    RateLimit RateLimitConfig
 }
 
 // NewRealtimeCollector creates a new RealtimeCollector instance
@@ -109,6 +202,18 @@ func NewRealtimeCollector(processor *event.EventProcessor, producer *producer.Pr
     if config.FlushInterval == 0 {
         config.FlushInterval = defaultFlushInterval
     }
+    if config.Deserializer == nil {
+        config.Deserializer = StrictDeserializer{}
+    }
+    switch config.OverflowPolicy {
+    case "":
+        config.OverflowPolicy = OverflowBlock
+    case OverflowBlock, OverflowDropNewest, OverflowReject:
+    default:
+        return nil, errors.NewError("E2001", "invalid overflow policy", map[string]interface{}{
+            "overflow_policy": config.OverflowPolicy,
+        })
+    }
 
     // Generate collector ID
     collectorID, err := utils.GenerateUUID()
@@ -121,27 +226,52 @@ func NewRealtimeCollector(processor *event.EventProcessor, producer *producer.Pr
     collector := &RealtimeCollector{
         processor:     processor,
         producer:      producer,
-        eventBuffer:   make(chan []byte, config.BufferSize),
+        eventBuffer:   make(chan bufferedEvent, config.BufferSize),
         flushInterval: config.FlushInterval,
         batchSize:     config.BatchSize,
         ctx:          ctx,
         cancel:       cancel,
         collectorID:  collectorID,
+        deserializer: config.Deserializer,
+        deadLetter:   config.DeadLetter,
+        overflowPolicy: config.OverflowPolicy,
+        tracer:       otel.Tracer("collector.realtime"),
+        drainCh:      make(chan struct{}),
+        drained:      make(chan struct{}),
+        intakeClosed: make(chan struct{}),
+    }
+    if config.Dedup.Enabled {
+        collector.dedup = NewDeduplicator(config.Dedup)
+    }
+    if config.RateLimit.Enabled {
+        limiter, err := NewClientRateLimiter(config.RateLimit)
+        if err != nil {
+            cancel()
+            return nil, err
+        }
+        collector.rateLimiter = limiter
     }
 
-    // Register metrics
-    prometheus.MustRegister(
-        metrics.eventCollectionTime,
-        metrics.batchProcessingTime,
-        metrics.eventBufferSize,
-        metrics.collectionErrors,
-        metrics.eventsCollected,
-    )
+    // Register metrics once; a process may run several collectors
+    registerMetricsOnce.Do(func() {
+        prometheus.MustRegister(
+            metrics.eventCollectionTime,
+            metrics.batchProcessingTime,
+            metrics.eventBufferSize,
+            metrics.collectionErrors,
+            metrics.eventsCollected,
+            metrics.duplicatesDropped,
+            metrics.bufferUtilization,
+            metrics.overflowEvents,
+            metrics.rateLimited,
+        )
+    })
 
     logging.Info("Realtime collector initialized",
         logging.Field("collector_id", collector.collectorID),
         logging.Field("buffer_size", config.BufferSize),
         logging.Field("batch_size", config.BatchSize),
+        logging.Field("overflow_policy", config.OverflowPolicy),
     )
 
     return collector, nil
@@ -149,6 +279,10 @@ func NewRealtimeCollector(processor *event.EventProcessor, producer *producer.Pr
 
 // Start begins the event collection process
 func (c *RealtimeCollector) Start() error {
+    c.acceptMu.Lock()
+    c.started = true
+    c.acceptMu.Unlock()
+
     c.wg.Add(1)
     go c.processBatches()
 
@@ -159,10 +293,57 @@ func (c *RealtimeCollector) Start() error {
     return nil
 }
 
-// Stop gracefully stops the collector
+// Drain stops the collector accepting events and publishes everything already
+// buffered. It returns once the buffer is empty, or with an error if ctx
+// expires first. Draining cannot be undone; call Stop afterwards.
+func (c *RealtimeCollector) Drain(ctx context.Context) error {
+    // Senders blocked under OverflowBlock hold the accept lock until they
+    // give up, so wake them before taking it
+    c.closeIntakeOnce.Do(func() { close(c.intakeClosed) })
+
+    c.acceptMu.Lock()
+    c.draining = true
+    started := c.started
+    c.acceptMu.Unlock()
+
+    c.drainOnce.Do(func() {
+        logging.Info("Draining realtime collector",
+            logging.Field("collector_id", c.collectorID),
+            logging.Field("buffered", len(c.eventBuffer)),
+        )
+        close(c.drainCh)
+
+        // Without a batch loop there is nothing to hand the buffer to
+        if !started {
+            c.flushBuffered(nil)
+            close(c.drained)
+        }
+    })
+
+    select {
+    case <-c.drained:
+        return nil
+    case <-ctx.Done():
+        return errors.WrapError(ctx.Err(), "timed out draining collector", map[string]interface{}{
+            "collector_id": c.collectorID,
+            "buffered":     len(c.eventBuffer),
+        })
+    }
+}
+
+// Stop drains buffered events and then stops the collector
 func (c *RealtimeCollector) Stop() error {
+    drainCtx, cancelDrain := context.WithTimeout(context.Background(), defaultCollectionTimeout)
+    defer cancelDrain()
+    if err := c.Drain(drainCtx); err != nil {
+        logging.Error("Collector drain incomplete",
+            err,
+            logging.Field("collector_id", c.collectorID),
+        )
+    }
+
     c.cancel()
-    
+
     // Wait for processing to complete with timeout
     done := make(chan struct{})
     go func() {
@@ -183,33 +364,207 @@ func (c *RealtimeCollector) Stop() error {
 
 // CollectEvent collects a single security event
 func (c *RealtimeCollector) CollectEvent(ctx context.Context, eventData []byte) error {
+    // Reject before any work so the sender can redeliver to another collector
+    if c.isDraining() {
+        metrics.collectionErrors.WithLabelValues("draining").Inc()
+        return errDraining(c.collectorID)
+    }
+
+    // The collection span is the root of the event's trace through the
+    // pipeline; its context travels with the event in Kafka headers
+    ctx, span := c.tracer.Start(ctx, "collect_event")
+    defer span.End()
+    span.SetAttributes(attribute.String("collector_id", c.collectorID))
+
+    bpmetrics.RecordIngested(bpmetrics.StageIngest, 1)
+
     if len(eventData) == 0 {
         metrics.collectionErrors.WithLabelValues("empty_event").Inc()
+        bpmetrics.RecordLoss(bpmetrics.StageIngest, bpmetrics.LossValidationReject, 1)
         return errors.NewError("E3001", "empty event data", nil)
     }
 
     timer := prometheus.NewTimer(metrics.eventCollectionTime.WithLabelValues("processing"))
     defer timer.ObserveDuration()
 
+    // Normalize payload, recovering malformed JSON where configured
+    eventData, recovered, err := c.deserialize(ctx, eventData)
+    if err != nil {
+        return err
+    }
+    if recovered {
+        metrics.eventsCollected.WithLabelValues("recovered").Inc()
+    }
+
     // Validate event data
     if err := validateEvent(eventData); err != nil {
         metrics.collectionErrors.WithLabelValues("validation_error").Inc()
+        bpmetrics.RecordLoss(bpmetrics.StageIngest, bpmetrics.LossValidationReject, 1)
         return err
     }
 
+    // Reject events from clients over their rate limit
+    if c.rateLimiter != nil {
+        clientID := c.rateLimiter.ClientID(ctx)
+        if !c.rateLimiter.Allow(clientID) {
+            clientLabel := bpmetrics.Guard("blackpoint_collector_rate_limited").Value("client_id", clientID)
+            metrics.rateLimited.WithLabelValues(clientLabel).Inc()
+            bpmetrics.RecordLoss(bpmetrics.StageIngest, bpmetrics.LossBackpressureShed, 1)
+            return errors.NewError("E4029", "client rate limit exceeded", map[string]interface{}{
+                "collector_id": c.collectorID,
+                "client_id":    clientID,
+            })
+        }
+    }
+
+    // Drop retried deliveries of events already collected. Marking the event
+    // before it is buffered claims it against concurrent redeliveries.
+    marked := false
+    if c.dedup != nil {
+        duplicate, err := c.dedup.IsDuplicate(ctx, eventData)
+        if err != nil {
+            // Fail open: a seen-set outage must not stop collection
+            metrics.collectionErrors.WithLabelValues("dedup_error").Inc()
+            logging.Error("Failed to check event for duplicates",
+                err,
+                logging.Field("collector_id", c.collectorID),
+            )
+        } else if duplicate {
+            metrics.duplicatesDropped.Inc()
+            bpmetrics.RecordLoss(bpmetrics.StageIngest, bpmetrics.LossDuplicate, 1)
+            return nil
+        } else {
+            marked = true
+        }
+    }
+
+    buffered, err := c.enqueue(ctx, eventData)
+    if marked && !buffered {
+        // The event was rejected or shed, so its redelivery is not a duplicate.
+        // The caller's context may be what failed the enqueue.
+        if err := c.dedup.Forget(context.WithoutCancel(ctx), eventData); err != nil {
+            metrics.collectionErrors.WithLabelValues("dedup_error").Inc()
+            logging.Error("Failed to release uncollected event from the duplicate window",
+                err,
+                logging.Field("collector_id", c.collectorID),
+            )
+        }
+    }
+    return err
+}
+
+// ReloadRateLimits applies new per-client rate limits without a restart.
+// Rate limiting must have been enabled when the collector was created.
+func (c *RealtimeCollector) ReloadRateLimits(config RateLimitConfig) error {
+    if c.rateLimiter == nil {
+        return errors.NewError("E2001", "rate limiting is not enabled", map[string]interface{}{
+            "collector_id": c.collectorID,
+        })
+    }
+    return c.rateLimiter.Reload(config)
+}
+
+// isDraining reports whether a drain has begun
+func (c *RealtimeCollector) isDraining() bool {
+    c.acceptMu.RLock()
+    defer c.acceptMu.RUnlock()
+    return c.draining
+}
+
+// errDraining is returned for events submitted once a drain has begun
+func errDraining(collectorID string) error {
+    return errors.NewError("E4002", "collector is draining", map[string]interface{}{
+        "collector_id": collectorID,
+    })
+}
+
+// enqueue adds an event to the buffer, applying the overflow policy when it is
+// full, and reports whether the event was buffered. The accept lock is held so
+// that a drain cannot begin mid-send.
+func (c *RealtimeCollector) enqueue(ctx context.Context, eventData []byte) (bool, error) {
+    c.acceptMu.RLock()
+    defer c.acceptMu.RUnlock()
+    if c.draining {
+        metrics.collectionErrors.WithLabelValues("draining").Inc()
+        bpmetrics.RecordLoss(bpmetrics.StageIngest, bpmetrics.LossBackpressureShed, 1)
+        return false, errDraining(c.collectorID)
+    }
+
+    buffered := bufferedEvent{data: eventData, headers: streaming.InjectTraceContext(ctx, nil)}
+
+    if c.overflowPolicy != OverflowBlock {
+        select {
+        case c.eventBuffer <- buffered:
+            c.recordBuffered()
+            return true, nil
+        default:
+        }
+
+        metrics.overflowEvents.WithLabelValues(c.overflowPolicy).Inc()
+        bpmetrics.RecordLoss(bpmetrics.StageIngest, bpmetrics.LossBackpressureShed, 1)
+        if c.overflowPolicy == OverflowDropNewest {
+            return false, nil
+        }
+        return false, errors.NewError("E4002", "event buffer full", map[string]interface{}{
+            "collector_id": c.collectorID,
+            "buffer_size":  cap(c.eventBuffer),
+        })
+    }
+
     // Try to add event to buffer with timeout
     select {
-    case c.eventBuffer <- eventData:
-        metrics.eventsCollected.WithLabelValues("success").Inc()
-        metrics.eventBufferSize.WithLabelValues(c.collectorID).Set(float64(len(c.eventBuffer)))
-        return nil
+    case c.eventBuffer <- buffered:
+        c.recordBuffered()
+        return true, nil
+    case <-c.intakeClosed:
+        metrics.collectionErrors.WithLabelValues("draining").Inc()
+        bpmetrics.RecordLoss(bpmetrics.StageIngest, bpmetrics.LossBackpressureShed, 1)
+        return false, errDraining(c.collectorID)
     case <-ctx.Done():
         metrics.collectionErrors.WithLabelValues("context_cancelled").Inc()
-        return errors.NewError("E4001", "context cancelled", nil)
+        bpmetrics.RecordLoss(bpmetrics.StageIngest, bpmetrics.LossCancelled, 1)
+        return false, errors.NewError("E4001", "context cancelled", nil)
     case <-time.After(defaultCollectionTimeout):
         metrics.collectionErrors.WithLabelValues("buffer_full").Inc()
-        return errors.NewError("E4001", "event buffer full", nil)
+        bpmetrics.RecordLoss(bpmetrics.StageIngest, bpmetrics.LossBackpressureShed, 1)
+        return false, errors.NewError("E4001", "event buffer full", nil)
+    }
+}
+
+// recordBuffered updates collection and buffer metrics after an event is buffered
+func (c *RealtimeCollector) recordBuffered() {
+    collectorLabel := bpmetrics.Guard("blackpoint_collector_buffer").Value("collector_id", c.collectorID)
+    metrics.eventsCollected.WithLabelValues("success").Inc()
+    metrics.eventBufferSize.WithLabelValues(collectorLabel).Set(float64(len(c.eventBuffer)))
+    metrics.bufferUtilization.WithLabelValues(collectorLabel).Set(float64(len(c.eventBuffer)) / float64(cap(c.eventBuffer)))
+}
+
+// deserialize runs the configured deserializer and dead-letters unparseable payloads
+func (c *RealtimeCollector) deserialize(ctx context.Context, eventData []byte) ([]byte, bool, error) {
+    normalized, recovered, err := c.deserializer.Deserialize(eventData)
+    if err == nil {
+        return normalized, recovered, nil
     }
+
+    metrics.collectionErrors.WithLabelValues("unparseable").Inc()
+    lossReason := bpmetrics.LossValidationReject
+    defer func() { bpmetrics.RecordLoss(bpmetrics.StageIngest, lossReason, 1) }()
+
+    if c.deadLetter != nil {
+        if dlErr := c.deadLetter.DeadLetter(ctx, eventData, err); dlErr != nil {
+            logging.Error("Failed to dead-letter unparseable event",
+                dlErr,
+                logging.Field("collector_id", c.collectorID),
+            )
+        } else {
+            metrics.eventsCollected.WithLabelValues("dead_lettered").Inc()
+            lossReason = bpmetrics.LossDeadLetter
+        }
+    }
+
+    return nil, false, errors.WrapError(err, "failed to deserialize event", map[string]interface{}{
+        "collector_id": c.collectorID,
+    })
 }
 
 // processBatches handles batch processing of collected events
@@ -219,7 +574,7 @@ func (c *RealtimeCollector) processBatches() {
     ticker := time.NewTicker(c.flushInterval)
     defer ticker.Stop()
 
-    batch := make([][]byte, 0, c.batchSize)
+    batch := make([]bufferedEvent, 0, c.batchSize)
 
     for {
         select {
@@ -228,42 +583,84 @@ func (c *RealtimeCollector) processBatches() {
                 c.processBatch(batch)
             }
             return
+        case <-c.drainCh:
+            c.flushBuffered(batch)
+            close(c.drained)
+            return
         case event := <-c.eventBuffer:
             batch = append(batch, event)
             if len(batch) >= c.batchSize {
                 c.processBatch(batch)
-                batch = make([][]byte, 0, c.batchSize)
+                batch = make([]bufferedEvent, 0, c.batchSize)
             }
         case <-ticker.C:
             if len(batch) > 0 {
                 c.processBatch(batch)
-                batch = make([][]byte, 0, c.batchSize)
+                batch = make([]bufferedEvent, 0, c.batchSize)
+            }
+        }
+    }
+}
+
+// flushBuffered publishes a pending batch and then every buffered event.
+// Callers ensure no more events are being buffered.
+func (c *RealtimeCollector) flushBuffered(batch []bufferedEvent) {
+    for {
+        select {
+        case event := <-c.eventBuffer:
+            batch = append(batch, event)
+            if len(batch) < c.batchSize {
+                continue
             }
+        default:
         }
+
+        if len(batch) == 0 {
+            return
+        }
+        c.processBatch(batch)
+        batch = make([]bufferedEvent, 0, c.batchSize)
     }
 }
 
 // processBatch processes a batch of events
-func (c *RealtimeCollector) processBatch(events [][]byte) {
-    if len(events) == 0 {
+func (c *RealtimeCollector) processBatch(batch []bufferedEvent) {
+    if len(batch) == 0 {
         return
     }
 
     timer := prometheus.NewTimer(metrics.batchProcessingTime.WithLabelValues("processing"))
     defer timer.ObserveDuration()
 
-    // Process events through Bronze tier
-    if err := c.producer.PublishBatch(c.ctx, events); err != nil {
+    events := make([][]byte, len(batch))
+    headers := make([]map[string]string, len(batch))
+    traced := false
+    for i, buffered := range batch {
+        events[i] = buffered.data
+        headers[i] = buffered.headers
+        traced = traced || len(buffered.headers) > 0
+    }
+
+    // Process events through Bronze tier, keeping each event's trace context
+    var err error
+    if traced {
+        err = c.producer.PublishBatchWithMessageHeaders(c.ctx, events, headers)
+    } else {
+        err = c.producer.PublishBatch(c.ctx, events)
+    }
+    if err != nil {
         logging.Error("Failed to process event batch",
             err,
             logging.Field("batch_size", len(events)),
             logging.Field("collector_id", c.collectorID),
         )
         metrics.collectionErrors.WithLabelValues("batch_processing").Inc()
+        bpmetrics.RecordLoss(bpmetrics.StageIngest, bpmetrics.LossPublishFailure, len(events))
         return
     }
 
     metrics.eventsCollected.WithLabelValues("batch_success").Add(float64(len(events)))
+    bpmetrics.RecordProduced(bpmetrics.StageIngest, len(events))
     logging.Info("Batch processed successfully",
         logging.Field("batch_size", len(events)),
         logging.Field("collector_id", c.collectorID),
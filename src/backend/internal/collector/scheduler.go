@@ -0,0 +1,314 @@
+// Package collector provides polling schedules for batch-mode security platform integrations
+package collector
+
+import (
+    "context"
+    "math/rand"
+    "sync"
+    "time"
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+    bpmetrics "github.com/blackpoint/internal/metrics"
+    "github.com/prometheus/client_golang/prometheus" // v1.16.0
+)
+
+// Default polling settings
+const (
+    defaultPollInterval       = 5 * time.Minute
+    defaultPollJitter         = 30 * time.Second
+    defaultMaxConcurrentPolls = 10
+    minPollInterval           = 10 * time.Second
+    pollMetric                = "blackpoint_collector_poll"
+)
+
+var (
+    pollMetrics = struct {
+        runs     *prometheus.CounterVec
+        duration *prometheus.HistogramVec
+    }{
+        runs: prometheus.NewCounterVec(
+            prometheus.CounterOpts{
+                Name: "blackpoint_collector_poll_runs_total",
+                Help: "Total number of polling collection runs by result",
+            },
+            []string{"platform_type", "result"},
+        ),
+        duration: prometheus.NewHistogramVec(
+            prometheus.HistogramOpts{
+                Name:    "blackpoint_collector_poll_duration_seconds",
+                Help:    "Duration of polling collection runs",
+                Buckets: []float64{1, 5, 15, 30, 60, 120, 300},
+            },
+            []string{"platform_type"},
+        ),
+    }
+)
+
+func init() {
+    prometheus.MustRegister(
+        pollMetrics.runs,
+        pollMetrics.duration,
+    )
+}
+
+// PollSchedule configures how often a polling integration collects
+type PollSchedule struct {
+    // Interval is the base time between the start of consecutive polls
+    Interval time.Duration
+
+    // Jitter adds a random delay in [0, Jitter) to each interval so integrations
+    // sharing a platform do not poll in lockstep
+    Jitter time.Duration
+}
+
+// NextDelay returns the time until the next poll: Interval plus a random
+// jitter in [0, Jitter)
+func (s PollSchedule) NextDelay(random *rand.Rand) time.Duration {
+    return s.Interval + jitter(random, s.Jitter)
+}
+
+// PollFunc performs a single collection run for an integration
+type PollFunc func(ctx context.Context) error
+
+// pollJob tracks a single scheduled integration
+type pollJob struct {
+    integrationID string
+    platformType  string
+    schedule      PollSchedule
+    poll          PollFunc
+    cancel        context.CancelFunc
+}
+
+// PollingScheduler runs polling-mode integrations on per-platform intervals.
+// An integration never overlaps itself: if its previous poll is still running
+// when the next one is due, that run is skipped and recorded as an overrun.
+// In-flight state is kept per integration rather than per job, so a job that
+// replaces another waits for the poll the old job still has in flight.
+type PollingScheduler struct {
+    platformSchedules map[string]PollSchedule
+    defaultSchedule   PollSchedule
+    slots             chan struct{}
+    jobs              map[string]*pollJob
+    inFlight          map[string]bool
+    random            *rand.Rand
+    stopped           bool
+    mu                sync.Mutex
+    wg                sync.WaitGroup
+}
+
+// NewPollingScheduler creates a scheduler allowing at most maxConcurrent polls
+// at once. Integrations of platforms without their own schedule use
+// defaultSchedule; a zero Interval selects 5 minutes with 30 seconds of jitter.
+func NewPollingScheduler(maxConcurrent int, defaultSchedule PollSchedule) *PollingScheduler {
+    if maxConcurrent <= 0 {
+        maxConcurrent = defaultMaxConcurrentPolls
+    }
+    if defaultSchedule.Interval <= 0 {
+        defaultSchedule = PollSchedule{
+            Interval: defaultPollInterval,
+            Jitter:   defaultPollJitter,
+        }
+    }
+
+    return &PollingScheduler{
+        platformSchedules: make(map[string]PollSchedule),
+        defaultSchedule:   defaultSchedule,
+        slots:             make(chan struct{}, maxConcurrent),
+        jobs:              make(map[string]*pollJob),
+        inFlight:          make(map[string]bool),
+        random:            rand.New(rand.NewSource(time.Now().UnixNano())),
+    }
+}
+
+// SetPlatformSchedule sets the schedule used for integrations of the given platform type
+func (s *PollingScheduler) SetPlatformSchedule(platformType string, schedule PollSchedule) error {
+    if schedule.Interval < minPollInterval {
+        return errors.NewError("E2001", "poll interval is below the minimum", map[string]interface{}{
+            "platform_type": platformType,
+            "interval":      schedule.Interval.String(),
+            "minimum":       minPollInterval.String(),
+        })
+    }
+    if schedule.Jitter < 0 || schedule.Jitter >= schedule.Interval {
+        return errors.NewError("E2001", "poll jitter must be non-negative and less than the interval", map[string]interface{}{
+            "platform_type": platformType,
+            "jitter":        schedule.Jitter.String(),
+        })
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.platformSchedules[platformType] = schedule
+    return nil
+}
+
+// Schedule starts polling an integration on its platform's schedule. Scheduling
+// an integration that is already scheduled replaces the existing job. A stopped
+// scheduler accepts no new jobs.
+func (s *PollingScheduler) Schedule(ctx context.Context, integrationID, platformType string, poll PollFunc) error {
+    if integrationID == "" {
+        return errors.NewError("E2001", "integration ID is required", nil)
+    }
+    if poll == nil {
+        return errors.NewError("E2001", "poll function is required", nil)
+    }
+
+    s.mu.Lock()
+    if s.stopped {
+        s.mu.Unlock()
+        return errors.NewError("E4002", "polling scheduler is stopped", map[string]interface{}{
+            "integration_id": integrationID,
+        })
+    }
+    if existing, ok := s.jobs[integrationID]; ok {
+        existing.cancel()
+    }
+
+    schedule, ok := s.platformSchedules[platformType]
+    if !ok {
+        schedule = s.defaultSchedule
+    }
+
+    jobCtx, cancel := context.WithCancel(ctx)
+    job := &pollJob{
+        integrationID: integrationID,
+        platformType:  platformType,
+        schedule:      schedule,
+        poll:          poll,
+        cancel:        cancel,
+    }
+    s.jobs[integrationID] = job
+    s.wg.Add(1)
+    s.mu.Unlock()
+
+    go s.run(jobCtx, job)
+
+    logging.Info("Scheduled polling integration",
+        logging.Field("integration_id", integrationID),
+        logging.Field("platform_type", platformType),
+        logging.Field("interval", schedule.Interval.String()),
+        logging.Field("jitter", schedule.Jitter.String()),
+    )
+    return nil
+}
+
+// Unschedule stops polling an integration; an in-flight poll is cancelled
+func (s *PollingScheduler) Unschedule(integrationID string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if job, ok := s.jobs[integrationID]; ok {
+        job.cancel()
+        delete(s.jobs, integrationID)
+    }
+}
+
+// Stop cancels every job and waits for in-flight polls to return. It can be
+// registered with a shutdown Coordinator; ctx bounds the wait.
+func (s *PollingScheduler) Stop(ctx context.Context) error {
+    s.mu.Lock()
+    s.stopped = true
+    for id, job := range s.jobs {
+        job.cancel()
+        delete(s.jobs, id)
+    }
+    s.mu.Unlock()
+
+    done := make(chan struct{})
+    go func() {
+        s.wg.Wait()
+        close(done)
+    }()
+
+    select {
+    case <-done:
+        return nil
+    case <-ctx.Done():
+        return errors.WrapError(ctx.Err(), "timed out waiting for in-flight polls", nil)
+    }
+}
+
+// run waits out each jittered interval and triggers the job until cancelled
+func (s *PollingScheduler) run(ctx context.Context, job *pollJob) {
+    defer s.wg.Done()
+
+    // Spread the first poll across the jitter window as well
+    timer := time.NewTimer(s.nextDelay(PollSchedule{Jitter: job.schedule.Jitter}))
+    defer timer.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-timer.C:
+            s.trigger(ctx, job)
+            timer.Reset(s.nextDelay(job.schedule))
+        }
+    }
+}
+
+// trigger starts a poll unless one is still in flight for the integration,
+// whether started by this job or by the job it replaced
+func (s *PollingScheduler) trigger(ctx context.Context, job *pollJob) {
+    platformLabel := bpmetrics.Guard(pollMetric).Value("platform_type", job.platformType)
+
+    s.mu.Lock()
+    if s.inFlight[job.integrationID] {
+        s.mu.Unlock()
+        pollMetrics.runs.WithLabelValues(platformLabel, "overrun").Inc()
+        logging.Info("Previous poll still running, skipping scheduled run",
+            logging.Field("integration_id", job.integrationID),
+        )
+        return
+    }
+    s.inFlight[job.integrationID] = true
+    s.wg.Add(1)
+    s.mu.Unlock()
+
+    go func() {
+        defer s.wg.Done()
+        defer func() {
+            s.mu.Lock()
+            delete(s.inFlight, job.integrationID)
+            s.mu.Unlock()
+        }()
+
+        // Wait for a concurrency slot; the integration stays in flight so
+        // queued polls also count towards overrun protection
+        select {
+        case s.slots <- struct{}{}:
+            defer func() { <-s.slots }()
+        case <-ctx.Done():
+            return
+        }
+
+        start := time.Now()
+        err := job.poll(ctx)
+        pollMetrics.duration.WithLabelValues(platformLabel).Observe(time.Since(start).Seconds())
+
+        if err != nil {
+            pollMetrics.runs.WithLabelValues(platformLabel, "error").Inc()
+            logging.Error("Polling collection failed", err,
+                logging.Field("integration_id", job.integrationID),
+            )
+            return
+        }
+        pollMetrics.runs.WithLabelValues(platformLabel, "success").Inc()
+    }()
+}
+
+// nextDelay draws the next delay for schedule from the shared random source
+func (s *PollingScheduler) nextDelay(schedule PollSchedule) time.Duration {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return schedule.NextDelay(s.random)
+}
+
+// jitter returns a random duration in [0, max)
+func jitter(random *rand.Rand, max time.Duration) time.Duration {
+    if max <= 0 {
+        return 0
+    }
+    return time.Duration(random.Int63n(int64(max)))
+}
@@ -0,0 +1,200 @@
+// Package encryption provides deterministic field encryption for equality lookups
+package encryption
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/json"
+    "io"
+    "strings"
+
+    "github.com/google/tink/go/daead/subtle" // v1.7.0
+    "golang.org/x/crypto/hkdf"               // v0.9.0
+    "../../pkg/common/errors"
+)
+
+const (
+    // deterministicFieldPrefix marks fields encrypted with AES-SIV
+    deterministicFieldPrefix = encryptedFieldPrefix + "D:"
+
+    // AES-SIV uses a 512-bit key: half for S2V, half for CTR
+    deterministicKeySize = 64
+
+    deterministicKeyInfo = "blackpoint-deterministic-field:"
+)
+
+// FieldEncryptorOptions configures optional FieldEncryptor behavior
+type FieldEncryptorOptions struct {
+    // DeterministicFields lists field names encrypted with deterministic
+    // AES-SIV instead of randomized AES-GCM, so equal values produce equal
+    // ciphertext and can be matched by equality in downstream storage.
+    //
+    // Deterministic encryption is weaker: anyone who can read the ciphertext
+    // learns which records share a value, can count value frequencies, and
+    // can confirm a guessed value if they obtain its ciphertext. Use it only
+    // for fields that must be searchable. Ciphertext is scoped to the client
+    // and field name, so equality holds only within one client's field and
+    // only within one key version until ReEncrypt migrates older values.
+    DeterministicFields []string
+
+    // KeyStore persists the keyring, including deterministic master keys.
+    // The keyring is loaded from it on construction and saved on every
+    // change; without one, key versions last only as long as the process.
+    // It is required when DeterministicFields is set.
+    KeyStore KeyStore
+
+    // Outage selects how EncryptFields handles KMS unavailability and is
+    // applied through SetOutageHandling on construction. The zero value
+    // fails closed.
+    Outage OutageConfig
+}
+
+// deterministicCipher returns the AES-SIV cipher for a client under a key
+// version. The version's master key is generated once and stored wrapped in
+// the keyring, so replicas sharing a KeyStore produce matching ciphertext.
+func (fe *FieldEncryptor) deterministicCipher(ctx context.Context, kv KeyVersion, clientID string) (*subtle.AESSIV, error) {
+    master, err := fe.deterministicMasterKey(ctx, kv.Version)
+    if err != nil {
+        return nil, err
+    }
+
+    // Derive a per-client key so one client's values never match another's
+    derived := make([]byte, deterministicKeySize)
+    reader := hkdf.New(sha256.New, master, nil, []byte(deterministicKeyInfo+clientID))
+    if _, err := io.ReadFull(reader, derived); err != nil {
+        return nil, errors.NewError("E4001", "Failed to derive deterministic key", nil)
+    }
+
+    siv, err := subtle.NewAESSIV(derived)
+    if err != nil {
+        return nil, errors.NewError("E4001", "Failed to create AES-SIV cipher", nil)
+    }
+    return siv, nil
+}
+
+// deterministicMasterKey returns the deterministic master key of a key
+// version, unwrapped through KMS from the stored keyring. The first process
+// to need it generates it under the version's KMS key and stores it wrapped.
+func (fe *FieldEncryptor) deterministicMasterKey(ctx context.Context, version byte) ([]byte, error) {
+    fe.deterministicMu.RLock()
+    master, ok := fe.deterministicKeys[version]
+    fe.deterministicMu.RUnlock()
+    if ok {
+        return master, nil
+    }
+
+    // Generation is serialized with rotation so a version gets a single key
+    fe.keys.rotateMu.Lock()
+    defer fe.keys.rotateMu.Unlock()
+
+    fe.deterministicMu.RLock()
+    master, ok = fe.deterministicKeys[version]
+    fe.deterministicMu.RUnlock()
+    if ok {
+        return master, nil
+    }
+
+    kv, err := fe.keys.lookup(version)
+    if err != nil {
+        return nil, err
+    }
+
+    // Another replica may already have generated this version's key
+    if kv.DeterministicKey == nil && fe.keyStore != nil {
+        if err := fe.loadKeyring(ctx); err != nil {
+            return nil, err
+        }
+        if kv, err = fe.keys.lookup(version); err != nil {
+            return nil, err
+        }
+    }
+
+    if kv.DeterministicKey == nil {
+        plaintext, wrapped, err := fe.kms.GenerateDeterministicKey(ctx, kv.KeyID, deterministicKeySize)
+        if err != nil {
+            return nil, &kmsError{err: err}
+        }
+        versions := fe.keys.snapshot()
+        for i := range versions {
+            if versions[i].Version == version {
+                versions[i].DeterministicKey = wrapped
+            }
+        }
+        if err := fe.saveKeyring(ctx, versions); err != nil {
+            return nil, err
+        }
+        if err := fe.SetKeyVersions(versions); err != nil {
+            return nil, err
+        }
+        master = plaintext
+    } else {
+        master, err = fe.kms.DecryptDeterministicKey(ctx, kv.KeyID, kv.DeterministicKey)
+        if err != nil {
+            return nil, &kmsError{err: err}
+        }
+    }
+
+    fe.deterministicMu.Lock()
+    fe.deterministicKeys[version] = master
+    fe.deterministicMu.Unlock()
+    return master, nil
+}
+
+// encryptDeterministic encrypts a field value with AES-SIV under the active
+// key version. The field name is authenticated so values cannot be swapped
+// between fields.
+func (fe *FieldEncryptor) encryptDeterministic(ctx context.Context, field string, value interface{}, clientID string) (string, error) {
+    jsonBytes, err := json.Marshal(value)
+    if err != nil {
+        return "", errors.NewError("E3001", "Failed to marshal field value", nil)
+    }
+    if len(jsonBytes) > maxFieldSize {
+        return "", errors.NewError("E3001", "Field value exceeds maximum size", map[string]interface{}{
+            "maxSize":    maxFieldSize,
+            "actualSize": len(jsonBytes),
+        })
+    }
+
+    kv := fe.keys.active()
+    siv, err := fe.deterministicCipher(ctx, kv, clientID)
+    if err != nil {
+        return "", err
+    }
+    ciphertext, err := siv.EncryptDeterministically(jsonBytes, []byte(field))
+    if err != nil {
+        return "", errors.NewError("E4001", "Failed to encrypt field value", nil)
+    }
+
+    encoded := base64.URLEncoding.EncodeToString(append([]byte{kv.Version}, ciphertext...))
+    return deterministicFieldPrefix + encoded, nil
+}
+
+// decryptDeterministic decrypts an AES-SIV field. A client or field name
+// other than the one used for encryption fails with TenantMismatchCode.
+func (fe *FieldEncryptor) decryptDeterministic(ctx context.Context, field, value, clientID string) ([]byte, error) {
+    raw, err := base64.URLEncoding.DecodeString(strings.TrimPrefix(value, deterministicFieldPrefix))
+    if err != nil || len(raw) < 2 {
+        return nil, errors.NewError("E3001", "Failed to decode encrypted value", nil)
+    }
+
+    kv, err := fe.keys.lookup(raw[0])
+    if err != nil {
+        return nil, err
+    }
+    siv, err := fe.deterministicCipher(ctx, kv, clientID)
+    if err != nil {
+        return nil, err
+    }
+
+    plaintext, err := siv.DecryptDeterministically(raw[1:], []byte(field))
+    if err != nil {
+        return nil, tenantMismatchError(clientID)
+    }
+    return plaintext, nil
+}
+
+// isFieldDeterministic reports whether a field uses deterministic encryption
+func (fe *FieldEncryptor) isFieldDeterministic(field string) bool {
+    return fe.deterministicFields[strings.ToLower(field)]
+}
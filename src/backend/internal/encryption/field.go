@@ -41,10 +41,18 @@ type FieldEncryptor struct {
     patternCache  *cache.Cache
     bufferPool    *sync.Pool
     sensitiveFields []string
+    outage        *outageHandler
+    outageMu      sync.RWMutex
+    tenantKeys    *cache.Cache
+    keys          *keyring
+    keyStore      KeyStore
+    deterministicFields map[string]bool
+    deterministicKeys   map[byte][]byte
+    deterministicMu     sync.RWMutex
 }
 
 // NewFieldEncryptor creates a new field encryptor instance with enhanced initialization
-func NewFieldEncryptor(kms *KMSManager, additionalSensitiveFields []string) (*FieldEncryptor, error) {
+func NewFieldEncryptor(kms *KMSManager, additionalSensitiveFields []string, opts ...FieldEncryptorOptions) (*FieldEncryptor, error) {
     if kms == nil {
         return nil, errors.NewError("E4001", "KMS manager cannot be nil", nil)
     }
@@ -54,7 +62,28 @@ func NewFieldEncryptor(kms *KMSManager, additionalSensitiveFields []string) (*Fi
     copy(allPatterns, sensitiveFieldPatterns)
     allPatterns = append(allPatterns, additionalSensitiveFields...)
 
-    return &FieldEncryptor{
+    deterministic := make(map[string]bool)
+    var keyStore KeyStore
+    var outage OutageConfig
+    for _, opt := range opts {
+        for _, field := range opt.DeterministicFields {
+            deterministic[strings.ToLower(field)] = true
+        }
+        if opt.KeyStore != nil {
+            keyStore = opt.KeyStore
+        }
+        if opt.Outage.Mode != "" {
+            outage = opt.Outage
+        }
+    }
+
+    // A per-process master key would give each replica different ciphertext
+    // for equal values and lose every value on restart
+    if len(deterministic) > 0 && keyStore == nil {
+        return nil, errors.NewError("E2001", "Deterministic fields require a KeyStore for the shared master key", nil)
+    }
+
+    fe := &FieldEncryptor{
         kms:            kms,
         patternCache:   cache.New(patternCacheTTL, patternCleanupInterval),
         bufferPool:     &sync.Pool{
@@ -63,7 +92,26 @@ func NewFieldEncryptor(kms *KMSManager, additionalSensitiveFields []string) (*Fi
             },
         },
         sensitiveFields: allPatterns,
-    }, nil
+        tenantKeys:      newTenantKeyCache(),
+        keys:            newKeyring(kms.DefaultKeyID()),
+        keyStore:        keyStore,
+        deterministicFields: deterministic,
+        deterministicKeys:   make(map[byte][]byte),
+    }
+
+    // Start from the persisted keyring so fields written under earlier
+    // versions, by this process or another replica, stay decryptable
+    ctx, cancel := context.WithTimeout(context.Background(), defaultOperationTimeout)
+    defer cancel()
+    if err := fe.loadKeyring(ctx); err != nil {
+        return nil, err
+    }
+
+    if err := fe.SetOutageHandling(outage); err != nil {
+        return nil, err
+    }
+
+    return fe, nil
 }
 
 // isFieldSensitive checks if a field requires encryption based on patterns and caching
@@ -88,8 +136,10 @@ func (fe *FieldEncryptor) isFieldSensitive(fieldName string) (bool, error) {
     return false, nil
 }
 
-// encryptField encrypts a single field value with enhanced validation
-func (fe *FieldEncryptor) encryptField(ctx context.Context, value interface{}) (string, error) {
+// encryptField encrypts a single field value with enhanced validation. Values
+// are sealed with the tenant's data key when one is given and with a fresh
+// envelope under the active key version otherwise.
+func (fe *FieldEncryptor) encryptField(ctx context.Context, value interface{}, key *tenantKey) (string, error) {
     // Validate value size
     jsonBytes, err := json.Marshal(value)
     if err != nil {
@@ -107,26 +157,101 @@ func (fe *FieldEncryptor) encryptField(ctx context.Context, value interface{}) (
     buf := fe.bufferPool.Get().([]byte)
     defer fe.bufferPool.Put(buf)
 
+    if key != nil {
+        return sealTenantField(key, jsonBytes)
+    }
+
     // Encrypt the value
     ctx, cancel := context.WithTimeout(ctx, encryptionTimeout)
     defer cancel()
 
-    encrypted, err := fe.kms.EncryptData(ctx, jsonBytes, "")
+    kv := fe.keys.active()
+    encrypted, err := fe.kms.EncryptData(ctx, jsonBytes, kv.KeyID)
     if err != nil {
-        return "", errors.WrapError(err, "Failed to encrypt field value", nil)
+        return "", &kmsError{err: errors.WrapError(err, "Failed to encrypt field value", nil)}
     }
 
-    // Encode the encrypted value
-    encoded := base64.URLEncoding.EncodeToString(encrypted)
-    return encryptedFieldPrefix + encoded, nil
+    // Encode the key version and encrypted value
+    encoded := base64.URLEncoding.EncodeToString(append([]byte{kv.Version}, encrypted...))
+    return versionedFieldPrefix + encoded, nil
 }
 
-// EncryptFields encrypts sensitive fields in the data map with concurrent processing
+// encryptValue encrypts a sensitive field, deterministically when the field
+// is configured for it
+func (fe *FieldEncryptor) encryptValue(ctx context.Context, field string, value interface{}, key *tenantKey, clientID string) (string, error) {
+    if fe.isFieldDeterministic(field) {
+        return fe.encryptDeterministic(ctx, field, value, clientID)
+    }
+    return fe.encryptField(ctx, value, key)
+}
+
+// decryptField decrypts one encrypted field value. Tenant fields use keys
+// resolved up front; versioned and pre-versioning fields are unwrapped by KMS.
+func (fe *FieldEncryptor) decryptField(ctx context.Context, field, value string, tenantKeys map[string]*tenantKey, clientID string) ([]byte, error) {
+    if strings.HasPrefix(value, tenantFieldPrefix) {
+        return openTenantField(tenantKeys, value)
+    }
+    if strings.HasPrefix(value, deterministicFieldPrefix) {
+        return fe.decryptDeterministic(ctx, field, value, clientID)
+    }
+
+    version := initialKeyVersion
+    encoded := strings.TrimPrefix(value, encryptedFieldPrefix)
+    versioned := strings.HasPrefix(value, versionedFieldPrefix)
+    if versioned {
+        encoded = strings.TrimPrefix(value, versionedFieldPrefix)
+    }
+
+    // Extract and decode encrypted value
+    encrypted, err := base64.URLEncoding.DecodeString(encoded)
+    if err != nil || (versioned && len(encrypted) == 0) {
+        return nil, errors.NewError("E3001", "Failed to decode encrypted value", nil)
+    }
+    if versioned {
+        version, encrypted = encrypted[0], encrypted[1:]
+    }
+    if _, err := fe.keys.lookup(version); err != nil {
+        return nil, err
+    }
+
+    // Decrypt the value
+    return fe.kms.DecryptData(ctx, encrypted)
+}
+
+// EncryptFields encrypts sensitive fields in the data map with concurrent processing.
+// When KMS is unavailable the configured outage mode applies; deferred and
+// quarantined events return ErrEncryptionDeferred or ErrEventQuarantined.
 func (fe *FieldEncryptor) EncryptFields(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+    defer observeDuration(operationEncrypt, time.Now())
+
+    result, err := fe.encryptFields(ctx, data)
+    if err != nil {
+        recordEncryptionError(err)
+        if _, unavailable := err.(*kmsError); unavailable {
+            return nil, fe.handleKMSOutage(ctx, data, err)
+        }
+        return nil, err
+    }
+    return result, nil
+}
+
+// encryptFields encrypts sensitive fields without applying outage handling.
+// Maps carrying a client_id are encrypted with that client's data key.
+func (fe *FieldEncryptor) encryptFields(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
     if data == nil {
         return nil, nil
     }
 
+    // Resolve the tenant key once so fields share a single KMS call
+    var dataKey *tenantKey
+    clientID := tenantClientID(data)
+    if clientID != "" {
+        var err error
+        if dataKey, err = fe.encryptionKey(ctx, clientID); err != nil {
+            return nil, err
+        }
+    }
+
     // Create result map
     result := make(map[string]interface{}, len(data))
     var encryptErr error
@@ -147,14 +272,15 @@ func (fe *FieldEncryptor) EncryptFields(ctx context.Context, data map[string]int
                 return
             }
 
-            if sensitive {
-                encrypted, err := fe.encryptField(ctx, v)
+            if sensitive || fe.isFieldDeterministic(k) {
+                encrypted, err := fe.encryptValue(ctx, k, v, dataKey, clientID)
                 if err != nil {
                     mu.Lock()
                     encryptErr = err
                     mu.Unlock()
                     return
                 }
+                fieldsEncrypted.Inc()
                 mu.Lock()
                 result[k] = encrypted
                 mu.Unlock()
@@ -175,12 +301,32 @@ func (fe *FieldEncryptor) EncryptFields(ctx context.Context, data map[string]int
     return result, nil
 }
 
-// DecryptFields decrypts previously encrypted fields in the data map
+// DecryptFields decrypts previously encrypted fields in the data map. Fields
+// encrypted for a client only decrypt when the map's client_id matches;
+// otherwise an error with TenantMismatchCode is returned.
 func (fe *FieldEncryptor) DecryptFields(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+    defer observeDuration(operationDecrypt, time.Now())
+
+    result, err := fe.decryptFields(ctx, data)
+    if err != nil {
+        recordEncryptionError(err)
+        return nil, err
+    }
+    return result, nil
+}
+
+// decryptFields decrypts fields concurrently once their tenant keys are resolved
+func (fe *FieldEncryptor) decryptFields(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
     if data == nil {
         return nil, nil
     }
 
+    tenantKeys, err := fe.resolveDecryptionKeys(ctx, data)
+    if err != nil {
+        return nil, err
+    }
+    clientID := tenantClientID(data)
+
     result := make(map[string]interface{}, len(data))
     var decryptErr error
     var mu sync.Mutex
@@ -199,18 +345,7 @@ func (fe *FieldEncryptor) DecryptFields(ctx context.Context, data map[string]int
                 return
             }
 
-            // Extract and decode encrypted value
-            encoded := strings.TrimPrefix(strVal, encryptedFieldPrefix)
-            encrypted, err := base64.URLEncoding.DecodeString(encoded)
-            if err != nil {
-                mu.Lock()
-                decryptErr = errors.NewError("E3001", "Failed to decode encrypted value", nil)
-                mu.Unlock()
-                return
-            }
-
-            // Decrypt the value
-            decrypted, err := fe.kms.DecryptData(ctx, encrypted)
+            decrypted, err := fe.decryptField(ctx, k, strVal, tenantKeys, clientID)
             if err != nil {
                 mu.Lock()
                 decryptErr = err
@@ -0,0 +1,75 @@
+// Package encryption provides persistent storage for the field encryption keyring
+package encryption
+
+import (
+    "context"
+    "encoding/json"
+
+    "github.com/go-redis/redis/v8" // v8.11.5
+    "../../pkg/common/errors"
+)
+
+// defaultKeyringKey is the Redis key holding the keyring when none is configured
+const defaultKeyringKey = "encryption:keyring"
+
+// KeyStore persists the keyring so key versions survive restarts and every
+// replica decrypts with the same versions. Stored versions hold only KMS key
+// IDs and KMS-wrapped keys, so the store alone cannot decrypt anything.
+type KeyStore interface {
+    // LoadKeyVersions returns the persisted versions, or none if the
+    // keyring has never been saved
+    LoadKeyVersions(ctx context.Context) ([]KeyVersion, error)
+    // SaveKeyVersions replaces the persisted versions
+    SaveKeyVersions(ctx context.Context, versions []KeyVersion) error
+}
+
+// RedisKeyStore is a KeyStore shared by all replicas using the same Redis.
+// The keyring is written without expiry; the Redis instance must persist data
+// and must not evict it, or retired versions become undecryptable.
+type RedisKeyStore struct {
+    client *redis.Client
+    key    string
+}
+
+// NewRedisKeyStore creates a Redis-backed keyring store; an empty key uses the default
+func NewRedisKeyStore(client *redis.Client, key string) *RedisKeyStore {
+    if key == "" {
+        key = defaultKeyringKey
+    }
+    return &RedisKeyStore{client: client, key: key}
+}
+
+// LoadKeyVersions reads the persisted keyring
+func (s *RedisKeyStore) LoadKeyVersions(ctx context.Context) ([]KeyVersion, error) {
+    raw, err := s.client.Get(ctx, s.key).Bytes()
+    if err == redis.Nil {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, errors.WrapError(err, "Failed to read encryption keyring", map[string]interface{}{
+            "key": s.key,
+        })
+    }
+
+    var versions []KeyVersion
+    if err := json.Unmarshal(raw, &versions); err != nil {
+        return nil, errors.NewError("E4001", "Stored encryption keyring is corrupt", map[string]interface{}{
+            "key": s.key,
+        })
+    }
+    return versions, nil
+}
+
+// SaveKeyVersions writes the keyring
+func (s *RedisKeyStore) SaveKeyVersions(ctx context.Context, versions []KeyVersion) error {
+    raw, err := json.Marshal(versions)
+    if err != nil {
+        return errors.NewError("E4001", "Failed to encode encryption keyring", nil)
+    }
+    if err := s.client.Set(ctx, s.key, raw, 0).Err(); err != nil {
+        return errors.WrapError(err, "Failed to store encryption keyring", map[string]interface{}{
+            "key": s.key,
+        })
+    }
+    return nil
+}
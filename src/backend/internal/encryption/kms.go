@@ -27,16 +27,27 @@ const (
     keyCleanupInterval     = 10 * time.Minute
 )
 
+// deterministicKeyContext binds deterministic master keys to their purpose
+var deterministicKeyContext = map[string]string{"purpose": "deterministic-field-encryption"}
+
+// KMSAPI is the subset of the AWS KMS client used by KMSManager
+type KMSAPI interface {
+    CreateKey(ctx context.Context, params *kms.CreateKeyInput, optFns ...func(*kms.Options)) (*kms.CreateKeyOutput, error)
+    EnableKeyRotation(ctx context.Context, params *kms.EnableKeyRotationInput, optFns ...func(*kms.Options)) (*kms.EnableKeyRotationOutput, error)
+    GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+    Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
 // KMSManager handles AWS KMS operations with enhanced security controls
 type KMSManager struct {
-    kmsClient    *kms.Client
+    kmsClient    KMSAPI
     defaultKeyID string
     operationLock sync.Mutex
     keyCache     *cache.Cache
 }
 
 // NewKMSManager creates a new KMS manager instance with security auditing
-func NewKMSManager(client *kms.Client, defaultKeyID string) (*KMSManager, error) {
+func NewKMSManager(client KMSAPI, defaultKeyID string) (*KMSManager, error) {
     if client == nil {
         return nil, errors.NewError("E4001", "KMS client cannot be nil", nil)
     }
@@ -165,7 +176,9 @@ func (km *KMSManager) generateDataKey(ctx context.Context, keyID string, keySize
         NumberOfBytes: &keySize,
     }
 
+    start := time.Now()
     result, err := km.kmsClient.GenerateDataKey(ctx, input)
+    observeDuration(operationKMSGenerateDataKey, start)
     if err != nil {
         return nil, nil, errors.NewError("E4001", "Failed to generate data key", map[string]interface{}{
             "keyId": keyID,
@@ -202,7 +215,9 @@ func (km *KMSManager) DecryptData(ctx context.Context, encryptedData []byte) ([]
         CiphertextBlob: encryptedKey,
     }
 
+    start := time.Now()
     result, err := km.kmsClient.Decrypt(ctx, input)
+    observeDuration(operationKMSDecrypt, start)
     if err != nil {
         return nil, errors.NewError("E4001", "Failed to decrypt data key", nil)
     }
@@ -233,4 +248,113 @@ func (km *KMSManager) DecryptData(ctx context.Context, encryptedData []byte) ([]
     }
 
     return plaintext, nil
-}
\ No newline at end of file
+}
+
+// GenerateDeterministicKey generates a long-lived data key for deterministic
+// field encryption, returning the plaintext and the wrapped key to persist
+func (km *KMSManager) GenerateDeterministicKey(ctx context.Context, keyID string, size int32) ([]byte, []byte, error) {
+    ctx, cancel := context.WithTimeout(ctx, defaultOperationTimeout)
+    defer cancel()
+
+    input := &kms.GenerateDataKeyInput{
+        KeyId:             &keyID,
+        NumberOfBytes:     &size,
+        EncryptionContext: deterministicKeyContext,
+    }
+
+    start := time.Now()
+    result, err := km.kmsClient.GenerateDataKey(ctx, input)
+    observeDuration(operationKMSGenerateDataKey, start)
+    if err != nil {
+        return nil, nil, errors.WrapError(err, "Failed to generate deterministic data key", map[string]interface{}{
+            "keyId": keyID,
+        })
+    }
+
+    return result.Plaintext, result.CiphertextBlob, nil
+}
+
+// DecryptDeterministicKey unwraps a key generated by GenerateDeterministicKey
+func (km *KMSManager) DecryptDeterministicKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+    ctx, cancel := context.WithTimeout(ctx, defaultOperationTimeout)
+    defer cancel()
+
+    input := &kms.DecryptInput{
+        CiphertextBlob:    wrappedKey,
+        KeyId:             &keyID,
+        EncryptionContext: deterministicKeyContext,
+    }
+
+    start := time.Now()
+    result, err := km.kmsClient.Decrypt(ctx, input)
+    observeDuration(operationKMSDecrypt, start)
+    if err != nil {
+        return nil, errors.WrapError(err, "Failed to decrypt deterministic data key", map[string]interface{}{
+            "keyId": keyID,
+        })
+    }
+
+    return result.Plaintext, nil
+}
+
+// DefaultKeyID returns the KMS key used when no key is specified
+func (km *KMSManager) DefaultKeyID() string {
+    return km.defaultKeyID
+}
+
+// GenerateTenantDataKey generates a data key bound to a client through the
+// KMS encryption context, so the wrapped key can only be unwrapped for the
+// same client_id
+func (km *KMSManager) GenerateTenantDataKey(ctx context.Context, keyID, clientID string) ([]byte, []byte, error) {
+    ctx, cancel := context.WithTimeout(ctx, defaultOperationTimeout)
+    defer cancel()
+
+    if keyID == "" {
+        keyID = km.defaultKeyID
+    }
+
+    keySize := int32(32) // AES-256
+    input := &kms.GenerateDataKeyInput{
+        KeyId:             &keyID,
+        NumberOfBytes:     &keySize,
+        EncryptionContext: map[string]string{"client_id": clientID},
+    }
+
+    start := time.Now()
+    result, err := km.kmsClient.GenerateDataKey(ctx, input)
+    observeDuration(operationKMSGenerateDataKey, start)
+    if err != nil {
+        return nil, nil, errors.WrapError(err, "Failed to generate tenant data key", map[string]interface{}{
+            "client_id": clientID,
+        })
+    }
+
+    return result.Plaintext, result.CiphertextBlob, nil
+}
+
+// DecryptTenantDataKey unwraps a data key generated by GenerateTenantDataKey
+// under keyID. KMS rejects the request when clientID differs from the one
+// the key was generated for.
+func (km *KMSManager) DecryptTenantDataKey(ctx context.Context, keyID string, wrappedKey []byte, clientID string) ([]byte, error) {
+    ctx, cancel := context.WithTimeout(ctx, defaultOperationTimeout)
+    defer cancel()
+
+    input := &kms.DecryptInput{
+        CiphertextBlob:    wrappedKey,
+        EncryptionContext: map[string]string{"client_id": clientID},
+    }
+    if keyID != "" {
+        input.KeyId = &keyID
+    }
+
+    start := time.Now()
+    result, err := km.kmsClient.Decrypt(ctx, input)
+    observeDuration(operationKMSDecrypt, start)
+    if err != nil {
+        return nil, errors.WrapError(err, "Failed to decrypt tenant data key", map[string]interface{}{
+            "client_id": clientID,
+        })
+    }
+
+    return result.Plaintext, nil
+}
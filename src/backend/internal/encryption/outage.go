@@ -0,0 +1,376 @@
+// Package encryption provides configurable handling of KMS outages during field encryption
+package encryption
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "sync"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus" // v1.16.0
+    "../../pkg/common/errors"
+    "../../pkg/common/logging"
+)
+
+// KMS outage handling modes
+const (
+    // OutageModeFailClosed rejects events while KMS is unavailable
+    OutageModeFailClosed = "fail_closed"
+
+    // OutageModeBufferRetry queues events in a bounded buffer and retries
+    // encryption in the background
+    OutageModeBufferRetry = "buffer_retry"
+
+    // OutageModeQuarantine is a break-glass mode that stores events
+    // unencrypted in a restricted quarantine bucket for later encryption
+    OutageModeQuarantine = "quarantine"
+)
+
+const (
+    defaultOutageQueueSize     = 1000
+    defaultOutageRetryInterval = 5 * time.Second
+    quarantineKeyPrefix        = "quarantine/"
+)
+
+// Sentinel errors returned by EncryptFields when an event was not rejected but
+// will be delivered later
+var (
+    ErrEncryptionDeferred = errors.NewError("E2002", "field encryption deferred until KMS recovers", nil)
+    ErrEventQuarantined   = errors.NewError("E2002", "event quarantined unencrypted until KMS recovers", nil)
+)
+
+// KMS outage metrics
+var (
+    kmsOutageEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "blackpoint_encryption_kms_outage_events_total",
+        Help: "Total number of events affected by KMS unavailability by handling outcome",
+    }, []string{"outcome"})
+    kmsOutageQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+        Name: "blackpoint_encryption_kms_outage_queue_depth",
+        Help: "Number of events waiting for KMS to recover",
+    })
+)
+
+func init() {
+    prometheus.MustRegister(kmsOutageEvents)
+    prometheus.MustRegister(kmsOutageQueueDepth)
+}
+
+// QuarantineStore persists quarantined events; *storage.S3Client satisfies it
+type QuarantineStore interface {
+    PutObject(bucket, key string, data []byte) error
+    GetObject(bucket, key string) ([]byte, error)
+    ListObjects(bucket, prefix string) ([]string, error)
+    DeleteObject(bucket, key string) error
+}
+
+// OutageConfig configures how FieldEncryptor behaves while KMS is unavailable.
+// The scalar settings load from service YAML; Deliver and QuarantineStore are
+// supplied by the service when it builds the encryptor.
+type OutageConfig struct {
+    // Mode is one of OutageModeFailClosed (default), OutageModeBufferRetry or OutageModeQuarantine
+    Mode string `yaml:"mode"`
+
+    // QueueSize bounds the number of buffered events in buffer_retry mode
+    QueueSize int `yaml:"queue_size"`
+
+    // RetryInterval is how often buffered events are retried
+    RetryInterval time.Duration `yaml:"retry_interval"`
+
+    // Deliver receives events encrypted after a deferral or quarantine
+    Deliver func(ctx context.Context, encrypted map[string]interface{}) error `yaml:"-"`
+
+    // QuarantineStore and QuarantineBucket locate the quarantine in quarantine mode.
+    // The bucket must be restricted to break-glass access.
+    QuarantineStore  QuarantineStore `yaml:"-"`
+    QuarantineBucket string          `yaml:"quarantine_bucket"`
+}
+
+// kmsError marks encryption failures caused by KMS rather than by the data
+type kmsError struct {
+    err error
+}
+
+func (e *kmsError) Error() string { return e.err.Error() }
+func (e *kmsError) Unwrap() error { return e.err }
+
+// outageHandler buffers or quarantines events while KMS is unavailable
+type outageHandler struct {
+    config OutageConfig
+    queue  chan map[string]interface{}
+    stop   chan struct{}
+    done   chan struct{}
+    seq    uint64
+    mu     sync.Mutex
+}
+
+// SetOutageHandling configures behavior for KMS outages. Any previously
+// started retry worker is stopped; buffered events are kept only when the new
+// configuration also buffers.
+func (fe *FieldEncryptor) SetOutageHandling(config OutageConfig) error {
+    switch config.Mode {
+    case "", OutageModeFailClosed:
+        config.Mode = OutageModeFailClosed
+    case OutageModeBufferRetry:
+        if config.Deliver == nil {
+            return errors.NewError("E2001", "buffer_retry mode requires a delivery function", nil)
+        }
+    case OutageModeQuarantine:
+        if config.Deliver == nil || config.QuarantineStore == nil || config.QuarantineBucket == "" {
+            return errors.NewError("E2001", "quarantine mode requires a delivery function, store and bucket", nil)
+        }
+    default:
+        return errors.NewError("E2001", "unsupported KMS outage mode", map[string]interface{}{
+            "mode": config.Mode,
+        })
+    }
+    if config.QueueSize <= 0 {
+        config.QueueSize = defaultOutageQueueSize
+    }
+    if config.RetryInterval <= 0 {
+        config.RetryInterval = defaultOutageRetryInterval
+    }
+
+    handler := &outageHandler{config: config}
+    if config.Mode == OutageModeBufferRetry {
+        handler.queue = make(chan map[string]interface{}, config.QueueSize)
+        handler.stop = make(chan struct{})
+        handler.done = make(chan struct{})
+    }
+
+    fe.outageMu.Lock()
+    previous := fe.outage
+    fe.outage = handler
+    fe.outageMu.Unlock()
+
+    if previous != nil && previous.queue != nil {
+        previous.close()
+        if handler.queue != nil {
+            handler.absorb(previous.queue)
+        }
+    }
+    if handler.queue != nil {
+        go handler.retryLoop(fe)
+    }
+    return nil
+}
+
+// Close stops the background retry worker. Events still buffered are lost
+// unless the caller has drained them through Deliver.
+func (fe *FieldEncryptor) Close() {
+    fe.outageMu.Lock()
+    handler := fe.outage
+    fe.outage = nil
+    fe.outageMu.Unlock()
+
+    if handler != nil && handler.queue != nil {
+        handler.close()
+    }
+}
+
+// handleKMSOutage applies the configured outage mode to an event that could
+// not be encrypted because KMS failed
+func (fe *FieldEncryptor) handleKMSOutage(ctx context.Context, data map[string]interface{}, cause error) error {
+    fe.outageMu.RLock()
+    handler := fe.outage
+    fe.outageMu.RUnlock()
+
+    if handler == nil || handler.config.Mode == OutageModeFailClosed {
+        kmsOutageEvents.WithLabelValues("rejected").Inc()
+        return cause
+    }
+
+    switch handler.config.Mode {
+    case OutageModeBufferRetry:
+        select {
+        case handler.queue <- data:
+            kmsOutageEvents.WithLabelValues("buffered").Inc()
+            kmsOutageQueueDepth.Set(float64(len(handler.queue)))
+            return ErrEncryptionDeferred
+        default:
+            // A full buffer fails closed so memory stays bounded
+            kmsOutageEvents.WithLabelValues("rejected").Inc()
+            return errors.WrapError(cause, "KMS outage buffer full", map[string]interface{}{
+                "queue_size": handler.config.QueueSize,
+            })
+        }
+
+    case OutageModeQuarantine:
+        key, err := handler.quarantine(data)
+        if err != nil {
+            kmsOutageEvents.WithLabelValues("rejected").Inc()
+            return errors.WrapError(err, "failed to quarantine event during KMS outage", nil)
+        }
+        kmsOutageEvents.WithLabelValues("quarantined").Inc()
+        logging.SecurityAudit("Event stored unencrypted in quarantine during KMS outage", map[string]interface{}{
+            "bucket": handler.config.QuarantineBucket,
+            "key":    key,
+            "cause":  cause.Error(),
+        })
+        return ErrEventQuarantined
+    }
+    return cause
+}
+
+// ReencryptQuarantined encrypts quarantined events and delivers them, removing
+// each from quarantine once delivered. It stops at the first KMS failure.
+func (fe *FieldEncryptor) ReencryptQuarantined(ctx context.Context) (int, error) {
+    fe.outageMu.RLock()
+    handler := fe.outage
+    fe.outageMu.RUnlock()
+
+    if handler == nil || handler.config.QuarantineStore == nil {
+        return 0, errors.NewError("E2001", "quarantine not configured", nil)
+    }
+    store, bucket := handler.config.QuarantineStore, handler.config.QuarantineBucket
+
+    keys, err := store.ListObjects(bucket, quarantineKeyPrefix)
+    if err != nil {
+        return 0, errors.WrapError(err, "failed to list quarantined events", nil)
+    }
+
+    recovered := 0
+    for _, key := range keys {
+        raw, err := store.GetObject(bucket, key)
+        if err != nil {
+            return recovered, errors.WrapError(err, "failed to read quarantined event", map[string]interface{}{
+                "key": key,
+            })
+        }
+
+        var data map[string]interface{}
+        if err := json.Unmarshal(raw, &data); err != nil {
+            return recovered, errors.WrapError(err, "failed to decode quarantined event", map[string]interface{}{
+                "key": key,
+            })
+        }
+
+        encrypted, err := fe.encryptFields(ctx, data)
+        if err != nil {
+            return recovered, err
+        }
+        if err := handler.config.Deliver(ctx, encrypted); err != nil {
+            return recovered, errors.WrapError(err, "failed to deliver re-encrypted event", map[string]interface{}{
+                "key": key,
+            })
+        }
+        if err := store.DeleteObject(bucket, key); err != nil {
+            return recovered, errors.WrapError(err, "failed to remove quarantined event", map[string]interface{}{
+                "key": key,
+            })
+        }
+
+        recovered++
+        kmsOutageEvents.WithLabelValues("recovered").Inc()
+    }
+
+    if recovered > 0 {
+        logging.SecurityAudit("Quarantined events re-encrypted", map[string]interface{}{
+            "bucket": bucket,
+            "count":  recovered,
+        })
+    }
+    return recovered, nil
+}
+
+// quarantine writes an unencrypted event to the quarantine bucket
+func (h *outageHandler) quarantine(data map[string]interface{}) (string, error) {
+    raw, err := json.Marshal(data)
+    if err != nil {
+        return "", errors.NewError("E3001", "Failed to marshal quarantined event", nil)
+    }
+
+    h.mu.Lock()
+    h.seq++
+    key := fmt.Sprintf("%s%d-%d.json", quarantineKeyPrefix, time.Now().UTC().UnixNano(), h.seq)
+    h.mu.Unlock()
+
+    if err := h.config.QuarantineStore.PutObject(h.config.QuarantineBucket, key, raw); err != nil {
+        return "", err
+    }
+    return key, nil
+}
+
+// retryLoop periodically retries buffered events in arrival order. A pass
+// stops at the first KMS failure so a sustained outage is not hammered.
+func (h *outageHandler) retryLoop(fe *FieldEncryptor) {
+    defer close(h.done)
+
+    ticker := time.NewTicker(h.config.RetryInterval)
+    defer ticker.Stop()
+
+    var pending map[string]interface{}
+    for {
+        select {
+        case <-h.stop:
+            // Hand an in-flight event back so a replacing handler can absorb it
+            if pending != nil {
+                select {
+                case h.queue <- pending:
+                default:
+                    kmsOutageEvents.WithLabelValues("rejected").Inc()
+                }
+            }
+            return
+        case <-ticker.C:
+        }
+
+        for {
+            if pending == nil {
+                select {
+                case pending = <-h.queue:
+                default:
+                }
+            }
+            if pending == nil {
+                break
+            }
+
+            ctx, cancel := context.WithTimeout(context.Background(), encryptionTimeout)
+            encrypted, err := fe.encryptFields(ctx, pending)
+            if err == nil {
+                err = h.config.Deliver(ctx, encrypted)
+            }
+            cancel()
+
+            if err != nil {
+                logging.Error("Retry of buffered field encryption failed", err,
+                    logging.Field("queue_depth", len(h.queue)+1),
+                )
+                break
+            }
+
+            pending = nil
+            kmsOutageEvents.WithLabelValues("recovered").Inc()
+        }
+
+        depth := len(h.queue)
+        if pending != nil {
+            depth++
+        }
+        kmsOutageQueueDepth.Set(float64(depth))
+    }
+}
+
+// absorb moves events buffered by a replaced handler into this one
+func (h *outageHandler) absorb(queue chan map[string]interface{}) {
+    for {
+        select {
+        case data := <-queue:
+            select {
+            case h.queue <- data:
+            default:
+                kmsOutageEvents.WithLabelValues("rejected").Inc()
+            }
+        default:
+            return
+        }
+    }
+}
+
+// close stops the retry worker and waits for it to exit
+func (h *outageHandler) close() {
+    close(h.stop)
+    <-h.done
+}
@@ -0,0 +1,304 @@
+// Package encryption provides versioned KMS keys and key rotation for field encryption
+package encryption
+
+import (
+    "context"
+    "encoding/base64"
+    "fmt"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    "../../pkg/common/errors"
+    "../../pkg/common/logging"
+)
+
+const (
+    // versionedFieldPrefix marks fields encrypted with a versioned shared key
+    versionedFieldPrefix = encryptedFieldPrefix + "V:"
+
+    // initialKeyVersion is the version of the KMS manager's default key.
+    // Fields written before versioning are decrypted as this version.
+    initialKeyVersion byte = 1
+
+    // defaultKeyRetention keeps retired keys usable for decryption for a year
+    defaultKeyRetention = 365 * 24 * time.Hour
+)
+
+// KeyVersion is one generation of the KMS key used for field encryption
+type KeyVersion struct {
+    Version   byte
+    KeyID     string
+    CreatedAt time.Time
+    // RetiredAt is when a newer version replaced this one; zero while current
+    RetiredAt time.Time
+    // DeterministicKey is the wrapped master key for deterministic fields,
+    // created on first use
+    DeterministicKey []byte
+}
+
+// keyring tracks the key versions a FieldEncryptor can encrypt and decrypt with
+type keyring struct {
+    mu        sync.RWMutex
+    rotateMu  sync.Mutex
+    versions  map[byte]KeyVersion
+    current   byte
+    retention time.Duration
+}
+
+// newKeyring creates a keyring whose only version is the default KMS key
+func newKeyring(defaultKeyID string) *keyring {
+    return &keyring{
+        versions: map[byte]KeyVersion{
+            initialKeyVersion: {Version: initialKeyVersion, KeyID: defaultKeyID, CreatedAt: time.Now().UTC()},
+        },
+        current:   initialKeyVersion,
+        retention: defaultKeyRetention,
+    }
+}
+
+// active returns the key version used for new writes
+func (r *keyring) active() KeyVersion {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    return r.versions[r.current]
+}
+
+// lookup returns a key version that may still decrypt, rejecting unknown
+// versions and versions retired longer than the retention period
+func (r *keyring) lookup(version byte) (KeyVersion, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    kv, ok := r.versions[version]
+    if !ok {
+        return KeyVersion{}, errors.NewError("E3001", "Unknown encryption key version", map[string]interface{}{
+            "key_version": version,
+        })
+    }
+    if !kv.RetiredAt.IsZero() && time.Since(kv.RetiredAt) > r.retention {
+        return KeyVersion{}, errors.NewError("E3001", "Encryption key version is past its retention period", map[string]interface{}{
+            "key_version": version,
+            "retired_at":  kv.RetiredAt,
+        })
+    }
+    return kv, nil
+}
+
+// snapshot returns every version in version order
+func (r *keyring) snapshot() []KeyVersion {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    versions := make([]KeyVersion, 0, len(r.versions))
+    for _, kv := range r.versions {
+        versions = append(versions, kv)
+    }
+    sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+    return versions
+}
+
+// RotateKey creates a new KMS key and makes it the key for new writes. The
+// previous key is retired but keeps decrypting existing fields until the key
+// retention passes; ReEncrypt upgrades fields before then. With a KeyStore the
+// rotation starts from the persisted keyring and is saved before it applies.
+func (fe *FieldEncryptor) RotateKey(ctx context.Context) (KeyVersion, error) {
+    // Serialize rotations without blocking encryption during the KMS call
+    fe.keys.rotateMu.Lock()
+    defer fe.keys.rotateMu.Unlock()
+
+    // Another replica may have rotated since this one loaded the keyring
+    if err := fe.loadKeyring(ctx); err != nil {
+        return KeyVersion{}, err
+    }
+
+    current := fe.keys.active().Version
+    if current == 255 {
+        return KeyVersion{}, errors.NewError("E4001", "Encryption key versions exhausted", nil)
+    }
+    next := current + 1
+
+    keyID, err := fe.kms.CreateKey(ctx, fmt.Sprintf("BlackPoint field encryption key v%d", next), map[string]string{
+        "purpose":     "field-encryption",
+        "key-version": fmt.Sprintf("%d", next),
+    })
+    if err != nil {
+        return KeyVersion{}, errors.WrapError(err, "Failed to rotate field encryption key", nil)
+    }
+
+    now := time.Now().UTC()
+    versions := fe.keys.snapshot()
+    for i := range versions {
+        if versions[i].Version == current {
+            versions[i].RetiredAt = now
+        }
+    }
+    kv := KeyVersion{Version: next, KeyID: keyID, CreatedAt: now}
+    versions = append(versions, kv)
+
+    if err := fe.saveKeyring(ctx, versions); err != nil {
+        return KeyVersion{}, err
+    }
+    if err := fe.SetKeyVersions(versions); err != nil {
+        return KeyVersion{}, err
+    }
+
+    logging.SecurityAudit("Field encryption key rotated", map[string]interface{}{
+        "key_version":      next,
+        "previous_version": current,
+    })
+    return kv, nil
+}
+
+// loadKeyring replaces the keyring with the persisted one. A store that has
+// never been written is seeded with the current keyring.
+func (fe *FieldEncryptor) loadKeyring(ctx context.Context) error {
+    if fe.keyStore == nil {
+        return nil
+    }
+
+    versions, err := fe.keyStore.LoadKeyVersions(ctx)
+    if err != nil {
+        return errors.WrapError(err, "Failed to load encryption keyring", nil)
+    }
+    if len(versions) == 0 {
+        return fe.saveKeyring(ctx, fe.keys.snapshot())
+    }
+    return fe.SetKeyVersions(versions)
+}
+
+// saveKeyring persists versions when a KeyStore is configured
+func (fe *FieldEncryptor) saveKeyring(ctx context.Context, versions []KeyVersion) error {
+    if fe.keyStore == nil {
+        return nil
+    }
+    if err := fe.keyStore.SaveKeyVersions(ctx, versions); err != nil {
+        return errors.WrapError(err, "Failed to persist encryption keyring", nil)
+    }
+    return nil
+}
+
+// SetKeyRetention sets how long retired keys keep decrypting existing fields
+func (fe *FieldEncryptor) SetKeyRetention(retention time.Duration) error {
+    if retention <= 0 {
+        return errors.NewError("E2001", "Key retention must be positive", nil)
+    }
+    fe.keys.mu.Lock()
+    fe.keys.retention = retention
+    fe.keys.mu.Unlock()
+    return nil
+}
+
+// KeyVersions returns every known key version in version order
+func (fe *FieldEncryptor) KeyVersions() []KeyVersion {
+    return fe.keys.snapshot()
+}
+
+// SetKeyVersions replaces the keyring with persisted versions. Exactly one
+// version must be unretired; it becomes the key for new writes.
+func (fe *FieldEncryptor) SetKeyVersions(versions []KeyVersion) error {
+    ring := make(map[byte]KeyVersion, len(versions))
+    var current []byte
+    for _, kv := range versions {
+        if kv.KeyID == "" {
+            return errors.NewError("E2001", "Key version has no KMS key", map[string]interface{}{
+                "key_version": kv.Version,
+            })
+        }
+        ring[kv.Version] = kv
+        if kv.RetiredAt.IsZero() {
+            current = append(current, kv.Version)
+        }
+    }
+    if len(current) != 1 {
+        return errors.NewError("E2001", "Exactly one key version must be active", map[string]interface{}{
+            "active_versions": len(current),
+        })
+    }
+
+    fe.keys.mu.Lock()
+    fe.keys.versions = ring
+    fe.keys.current = current[0]
+    fe.keys.mu.Unlock()
+    return nil
+}
+
+// ReEncrypt upgrades encrypted fields written with an older key version to
+// the active version, leaving current and plaintext fields untouched. It is
+// meant for background migration jobs that run after RotateKey.
+func (fe *FieldEncryptor) ReEncrypt(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
+    if data == nil {
+        return nil, nil
+    }
+
+    active := fe.keys.active().Version
+    stale := false
+    for _, value := range data {
+        if version, encrypted := fieldKeyVersion(value); encrypted && version != active {
+            stale = true
+            break
+        }
+    }
+    if !stale {
+        return data, nil
+    }
+
+    decrypted, err := fe.DecryptFields(ctx, data)
+    if err != nil {
+        return nil, err
+    }
+
+    var key *tenantKey
+    if clientID := tenantClientID(data); clientID != "" {
+        if key, err = fe.encryptionKey(ctx, clientID); err != nil {
+            return nil, err
+        }
+    }
+
+    result := make(map[string]interface{}, len(data))
+    for k, value := range data {
+        version, encrypted := fieldKeyVersion(value)
+        if !encrypted || version == active {
+            result[k] = value
+            continue
+        }
+        upgraded, err := fe.encryptValue(ctx, k, decrypted[k], key, tenantClientID(data))
+        if err != nil {
+            return nil, err
+        }
+        result[k] = upgraded
+    }
+
+    return result, nil
+}
+
+// fieldKeyVersion returns the key version of an encrypted field value
+func fieldKeyVersion(value interface{}) (byte, bool) {
+    strVal, ok := value.(string)
+    if !ok || !strings.HasPrefix(strVal, encryptedFieldPrefix) {
+        return 0, false
+    }
+
+    var encoded string
+    switch {
+    case strings.HasPrefix(strVal, tenantFieldPrefix):
+        encoded = strings.TrimPrefix(strVal, tenantFieldPrefix)
+    case strings.HasPrefix(strVal, versionedFieldPrefix):
+        encoded = strings.TrimPrefix(strVal, versionedFieldPrefix)
+    case strings.HasPrefix(strVal, deterministicFieldPrefix):
+        encoded = strings.TrimPrefix(strVal, deterministicFieldPrefix)
+    default:
+        return initialKeyVersion, true
+    }
+
+    // The version is the first byte; decoding one base64 block is enough
+    if len(encoded) < 4 {
+        return 0, false
+    }
+    head, err := base64.URLEncoding.DecodeString(encoded[:4])
+    if err != nil || len(head) == 0 {
+        return 0, false
+    }
+    return head[0], true
+}
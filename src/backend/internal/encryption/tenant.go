@@ -0,0 +1,238 @@
+// Package encryption provides per-tenant data keys for field-level encryption
+package encryption
+
+import (
+    "context"
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/rand"
+    "encoding/base64"
+    "encoding/binary"
+    stderrors "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/aws/aws-sdk-go-v2/service/kms/types"
+    "github.com/patrickmn/go-cache" // v2.1.0
+    "../../pkg/common/errors"
+)
+
+const (
+    // tenantFieldPrefix marks fields encrypted with a client-specific data key
+    tenantFieldPrefix = encryptedFieldPrefix + "T:"
+
+    // tenantClientField names the map field that identifies the tenant
+    tenantClientField = "client_id"
+
+    // TenantMismatchCode is returned when a field is decrypted on behalf of a
+    // client other than the one it was encrypted for
+    TenantMismatchCode = "E1006"
+
+    // Unwrapped data keys are cached briefly so an event costs at most one KMS call
+    tenantKeyCacheTTL        = 5 * time.Minute
+    tenantKeyCleanupInterval = time.Minute
+
+    tenantNonceSize = 12
+)
+
+// tenantKey is an unwrapped data key and the client it belongs to
+type tenantKey struct {
+    clientID  string
+    version   byte
+    plaintext []byte
+    wrapped   []byte
+}
+
+// tenantField is a decoded tenant-encrypted field
+type tenantField struct {
+    version    byte
+    wrapped    []byte
+    nonce      []byte
+    ciphertext []byte
+}
+
+// newTenantKeyCache creates the cache of unwrapped tenant data keys
+func newTenantKeyCache() *cache.Cache {
+    return cache.New(tenantKeyCacheTTL, tenantKeyCleanupInterval)
+}
+
+// tenantClientID returns the client a data map belongs to, if any
+func tenantClientID(data map[string]interface{}) string {
+    clientID, _ := data[tenantClientField].(string)
+    return clientID
+}
+
+// encryptionKey returns the client's data key for the active key version,
+// generating one through KMS when none is cached
+func (fe *FieldEncryptor) encryptionKey(ctx context.Context, clientID string) (*tenantKey, error) {
+    kv := fe.keys.active()
+    cacheKey := fmt.Sprintf("enc:%d:%s", kv.Version, clientID)
+    if cached, found := fe.tenantKeys.Get(cacheKey); found {
+        return cached.(*tenantKey), nil
+    }
+
+    plaintext, wrapped, err := fe.kms.GenerateTenantDataKey(ctx, kv.KeyID, clientID)
+    if err != nil {
+        return nil, &kmsError{err: err}
+    }
+
+    key := &tenantKey{clientID: clientID, version: kv.Version, plaintext: plaintext, wrapped: wrapped}
+    fe.tenantKeys.Set(cacheKey, key, cache.DefaultExpiration)
+    fe.tenantKeys.Set("dec:"+string(wrapped), key, cache.DefaultExpiration)
+    return key, nil
+}
+
+// decryptionKey unwraps the data key of an encrypted field for a client. It
+// fails with TenantMismatchCode when the key belongs to another client.
+func (fe *FieldEncryptor) decryptionKey(ctx context.Context, kv KeyVersion, clientID string, wrapped []byte) (*tenantKey, error) {
+    if cached, found := fe.tenantKeys.Get("dec:" + string(wrapped)); found {
+        key := cached.(*tenantKey)
+        if key.clientID != clientID {
+            return nil, tenantMismatchError(clientID)
+        }
+        return key, nil
+    }
+
+    plaintext, err := fe.kms.DecryptTenantDataKey(ctx, kv.KeyID, wrapped, clientID)
+    if err != nil {
+        var invalid *types.InvalidCiphertextException
+        if stderrors.As(err, &invalid) {
+            return nil, tenantMismatchError(clientID)
+        }
+        return nil, err
+    }
+
+    key := &tenantKey{clientID: clientID, version: kv.Version, plaintext: plaintext, wrapped: wrapped}
+    fe.tenantKeys.Set("dec:"+string(wrapped), key, cache.DefaultExpiration)
+    return key, nil
+}
+
+// resolveDecryptionKeys unwraps each distinct data key used by the tenant
+// fields of a map once, before fields are decrypted concurrently. Keys whose
+// version is past retention are refused even when cached.
+func (fe *FieldEncryptor) resolveDecryptionKeys(ctx context.Context, data map[string]interface{}) (map[string]*tenantKey, error) {
+    keys := make(map[string]*tenantKey)
+    clientID := tenantClientID(data)
+
+    for _, value := range data {
+        strVal, ok := value.(string)
+        if !ok || !strings.HasPrefix(strVal, tenantFieldPrefix) {
+            continue
+        }
+        field, err := parseTenantField(strVal)
+        if err != nil {
+            return nil, err
+        }
+        if _, done := keys[string(field.wrapped)]; done {
+            continue
+        }
+        if clientID == "" {
+            return nil, tenantMismatchError(clientID)
+        }
+        kv, err := fe.keys.lookup(field.version)
+        if err != nil {
+            return nil, err
+        }
+
+        key, err := fe.decryptionKey(ctx, kv, clientID, field.wrapped)
+        if err != nil {
+            return nil, err
+        }
+        keys[string(field.wrapped)] = key
+    }
+
+    return keys, nil
+}
+
+// sealTenantField encrypts a value with a tenant data key. The client ID is
+// authenticated as additional data, so a field moved to another client's
+// event fails to decrypt even with the right key.
+func sealTenantField(key *tenantKey, plaintext []byte) (string, error) {
+    defer observeDuration(operationAESSeal, time.Now())
+
+    gcm, err := tenantGCM(key)
+    if err != nil {
+        return "", err
+    }
+
+    nonce := make([]byte, tenantNonceSize)
+    if _, err := rand.Read(nonce); err != nil {
+        return "", errors.NewError("E4001", "Failed to generate nonce", nil)
+    }
+    ciphertext := gcm.Seal(nil, nonce, plaintext, []byte(key.clientID))
+
+    // Key version, wrapped key length, wrapped key, nonce, then ciphertext
+    out := make([]byte, 5+len(key.wrapped)+len(nonce)+len(ciphertext))
+    out[0] = key.version
+    binary.BigEndian.PutUint32(out[1:5], uint32(len(key.wrapped)))
+    copy(out[5:], key.wrapped)
+    copy(out[5+len(key.wrapped):], nonce)
+    copy(out[5+len(key.wrapped)+len(nonce):], ciphertext)
+
+    return tenantFieldPrefix + base64.URLEncoding.EncodeToString(out), nil
+}
+
+// openTenantField decrypts a tenant field with its already unwrapped key
+func openTenantField(keys map[string]*tenantKey, value string) ([]byte, error) {
+    defer observeDuration(operationAESOpen, time.Now())
+
+    field, err := parseTenantField(value)
+    if err != nil {
+        return nil, err
+    }
+    key, ok := keys[string(field.wrapped)]
+    if !ok {
+        return nil, errors.NewError("E4001", "Tenant data key not resolved", nil)
+    }
+
+    gcm, err := tenantGCM(key)
+    if err != nil {
+        return nil, err
+    }
+    plaintext, err := gcm.Open(nil, field.nonce, field.ciphertext, []byte(key.clientID))
+    if err != nil {
+        return nil, tenantMismatchError(key.clientID)
+    }
+    return plaintext, nil
+}
+
+// parseTenantField splits an encoded tenant field into its parts
+func parseTenantField(value string) (tenantField, error) {
+    raw, err := base64.URLEncoding.DecodeString(strings.TrimPrefix(value, tenantFieldPrefix))
+    if err != nil || len(raw) < 5 {
+        return tenantField{}, errors.NewError("E3001", "Failed to decode encrypted value", nil)
+    }
+
+    wrappedLen := int(binary.BigEndian.Uint32(raw[1:5]))
+    if len(raw) < 5+wrappedLen+tenantNonceSize {
+        return tenantField{}, errors.NewError("E3001", "Invalid encrypted data length", nil)
+    }
+
+    return tenantField{
+        version:    raw[0],
+        wrapped:    raw[5 : 5+wrappedLen],
+        nonce:      raw[5+wrappedLen : 5+wrappedLen+tenantNonceSize],
+        ciphertext: raw[5+wrappedLen+tenantNonceSize:],
+    }, nil
+}
+
+// tenantGCM creates the AEAD for a tenant data key
+func tenantGCM(key *tenantKey) (cipher.AEAD, error) {
+    block, err := aes.NewCipher(key.plaintext)
+    if err != nil {
+        return nil, errors.NewError("E4001", "Failed to create cipher", nil)
+    }
+    gcm, err := cipher.NewGCM(block)
+    if err != nil {
+        return nil, errors.NewError("E4001", "Failed to create GCM", nil)
+    }
+    return gcm, nil
+}
+
+// tenantMismatchError reports an attempt to decrypt another client's data
+func tenantMismatchError(clientID string) error {
+    return errors.NewError(TenantMismatchCode, "Encrypted field belongs to a different client", map[string]interface{}{
+        "client_id": clientID,
+    })
+}
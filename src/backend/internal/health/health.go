@@ -0,0 +1,237 @@
+// Package health aggregates the readiness of a service's dependencies for liveness and readiness probes
+package health
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "sync"
+    "time"
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// Default bound on a single dependency check
+const defaultCheckTimeout = 2 * time.Second
+
+// Overall service states reported by readiness checks
+const (
+    // StatusHealthy means every dependency is up
+    StatusHealthy = "healthy"
+
+    // StatusDegraded means a non-critical dependency is down; the service
+    // keeps receiving traffic
+    StatusDegraded = "degraded"
+
+    // StatusUnhealthy means a critical dependency is down; readiness fails
+    StatusUnhealthy = "unhealthy"
+)
+
+// Dependency states
+const (
+    StateUp   = "up"
+    StateDown = "down"
+)
+
+// CheckFunc reports whether a dependency is usable, returning early when ctx is done
+type CheckFunc func(ctx context.Context) error
+
+// Dependency describes one readiness check
+type Dependency struct {
+    Name  string
+    Check CheckFunc
+
+    // Critical dependencies fail readiness when down; others only degrade it
+    Critical bool
+
+    // Timeout bounds the check; the checker default applies when zero
+    Timeout time.Duration
+}
+
+// DependencyStatus is the outcome of one dependency check
+type DependencyStatus struct {
+    State    string        `json:"state"`
+    Critical bool          `json:"critical"`
+    Duration time.Duration `json:"duration"`
+    Error    string        `json:"error,omitempty"`
+}
+
+// Report is the aggregated readiness of all dependencies
+type Report struct {
+    Status       string                      `json:"status"`
+    Dependencies map[string]DependencyStatus `json:"dependencies"`
+    CheckedAt    time.Time                   `json:"checked_at"`
+}
+
+// HealthChecker runs dependency checks concurrently, each under its own
+// timeout, so a slow dependency cannot hang the health endpoint
+type HealthChecker struct {
+    service        string
+    defaultTimeout time.Duration
+    dependencies   []Dependency
+    mu             sync.RWMutex
+}
+
+// NewHealthChecker creates a checker for a service. Checks without their own
+// timeout are bounded by defaultTimeout, or 2s when it is zero.
+func NewHealthChecker(service string, defaultTimeout time.Duration) *HealthChecker {
+    if defaultTimeout <= 0 {
+        defaultTimeout = defaultCheckTimeout
+    }
+    return &HealthChecker{
+        service:        service,
+        defaultTimeout: defaultTimeout,
+    }
+}
+
+// Register adds a dependency to the readiness checks
+func (h *HealthChecker) Register(dep Dependency) error {
+    if dep.Name == "" || dep.Check == nil {
+        return errors.NewError("E2001", "health dependency requires a name and check", map[string]interface{}{
+            "service":    h.service,
+            "dependency": dep.Name,
+        })
+    }
+
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    for _, existing := range h.dependencies {
+        if existing.Name == dep.Name {
+            return errors.NewError("E2001", "health dependency already registered", map[string]interface{}{
+                "service":    h.service,
+                "dependency": dep.Name,
+            })
+        }
+    }
+    if dep.Timeout <= 0 {
+        dep.Timeout = h.defaultTimeout
+    }
+    h.dependencies = append(h.dependencies, dep)
+    return nil
+}
+
+// Check runs every dependency check concurrently and aggregates the results
+func (h *HealthChecker) Check(ctx context.Context) Report {
+    h.mu.RLock()
+    dependencies := make([]Dependency, len(h.dependencies))
+    copy(dependencies, h.dependencies)
+    h.mu.RUnlock()
+
+    statuses := make([]DependencyStatus, len(dependencies))
+    var wg sync.WaitGroup
+    for i, dep := range dependencies {
+        wg.Add(1)
+        go func(i int, dep Dependency) {
+            defer wg.Done()
+            statuses[i] = runCheck(ctx, dep)
+        }(i, dep)
+    }
+    wg.Wait()
+
+    report := Report{
+        Status:       StatusHealthy,
+        Dependencies: make(map[string]DependencyStatus, len(dependencies)),
+        CheckedAt:    time.Now().UTC(),
+    }
+    for i, dep := range dependencies {
+        status := statuses[i]
+        report.Dependencies[dep.Name] = status
+        if status.State == StateUp {
+            continue
+        }
+        if dep.Critical {
+            report.Status = StatusUnhealthy
+        } else if report.Status == StatusHealthy {
+            report.Status = StatusDegraded
+        }
+    }
+    return report
+}
+
+// runCheck runs one check under its timeout. A check that ignores its
+// context is abandoned when the timeout expires and reported down.
+func runCheck(ctx context.Context, dep Dependency) DependencyStatus {
+    ctx, cancel := context.WithTimeout(ctx, dep.Timeout)
+    defer cancel()
+
+    start := time.Now()
+    result := make(chan error, 1)
+    go func() {
+        result <- dep.Check(ctx)
+    }()
+
+    var err error
+    select {
+    case err = <-result:
+    case <-ctx.Done():
+        err = errors.WrapError(ctx.Err(), "health check timed out", map[string]interface{}{
+            "dependency": dep.Name,
+            "timeout":    dep.Timeout.String(),
+        })
+    }
+
+    status := DependencyStatus{
+        State:    StateUp,
+        Critical: dep.Critical,
+        Duration: time.Since(start),
+    }
+    if err != nil {
+        status.State = StateDown
+        status.Error = err.Error()
+    }
+    return status
+}
+
+// LiveHandler serves the liveness probe. It reports only that the process is
+// serving requests; dependency outages must not get a healthy pod restarted.
+func (h *HealthChecker) LiveHandler() http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, map[string]string{
+            "status":  "alive",
+            "service": h.service,
+        })
+    }
+}
+
+// ReadyHandler serves the readiness probe with per-dependency status,
+// answering 503 when a critical dependency is down
+func (h *HealthChecker) ReadyHandler() http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        report := h.Check(r.Context())
+        code := http.StatusOK
+        if report.Status == StatusUnhealthy {
+            code = http.StatusServiceUnavailable
+        }
+        writeJSON(w, code, report)
+    }
+}
+
+// RegisterRoutes serves /health/live and /health/ready on mux. /health
+// answers like /health/live so existing liveness probes keep working.
+func (h *HealthChecker) RegisterRoutes(mux *http.ServeMux) {
+    mux.HandleFunc("/health/live", h.LiveHandler())
+    mux.HandleFunc("/health/ready", h.ReadyHandler())
+    mux.HandleFunc("/health", h.LiveHandler())
+}
+
+// QueueDepthCheck reports a work queue down once its depth reaches limit,
+// which means processing has stalled or cannot keep up
+func QueueDepthCheck(depth func() int, limit int) CheckFunc {
+    return func(ctx context.Context) error {
+        if current := depth(); current >= limit {
+            return errors.NewError("E4002", "queue depth at limit", map[string]interface{}{
+                "depth": current,
+                "limit": limit,
+            })
+        }
+        return nil
+    }
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(code)
+    json.NewEncoder(w).Encode(body)
+}
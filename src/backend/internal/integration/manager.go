@@ -14,6 +14,8 @@ import (
     "../../pkg/common/logging"
     "../../pkg/integration/config"
     "../../pkg/integration/platform"
+    "../collector"
+    "../metrics"
 )
 
 // Prometheus metrics
@@ -58,6 +60,9 @@ type IntegrationManager struct {
     metricsCollector   *prometheus.Collector
     operationTimeout   time.Duration
     tracer            trace.Tracer
+    probeValidator     *IntegrationValidator
+    pollScheduler      *collector.PollingScheduler
+    pollCtx            context.Context
 }
 
 // Integration represents a deployed platform integration instance
@@ -68,6 +73,11 @@ type Integration struct {
     Status        *platform.PlatformStatus
     DeployedAt    time.Time
     LastUpdated   time.Time
+    // ActiveVersion is the blue-green colour currently collecting
+    ActiveVersion string
+    // standby is the other colour: a candidate awaiting cutover or the
+    // version replaced by the last cutover. Nil when only one version exists.
+    standby       *IntegrationVersion
 }
 
 var (
@@ -85,6 +95,7 @@ func GetManager() *IntegrationManager {
             platformRegistry:   registry.GetRegistry(),
             operationTimeout:   defaultTimeout,
             tracer:            otel.Tracer("integration-manager"),
+            probeValidator:     NewIntegrationValidator(),
         }
         
         logging.Info("Integration manager initialized",
@@ -94,17 +105,49 @@ func GetManager() *IntegrationManager {
     return managerInstance
 }
 
+// EnableCacheInvalidation keeps cached validation results consistent across
+// replicas: changes announced by any replica drop this manager's cached
+// results, and this manager announces the integrations it deploys, cuts over
+// and stops. Call it at startup; it stops when ctx is cancelled.
+func (m *IntegrationManager) EnableCacheInvalidation(ctx context.Context, broadcaster ConfigBroadcaster) error {
+    if err := m.probeValidator.SubscribeInvalidations(ctx, broadcaster); err != nil {
+        return errors.WrapError(err, "failed to subscribe to configuration changes", map[string]interface{}{
+            "channel": ConfigChangeChannel,
+        })
+    }
+
+    logging.Info("Integration cache invalidation enabled",
+        "channel", ConfigChangeChannel,
+    )
+    return nil
+}
+
+// EnablePolling schedules batch and hybrid integrations whose platform is a
+// platform.Poller on scheduler. Call it at startup in a long-running service;
+// polls stop when ctx is cancelled or the scheduler is stopped.
+func (m *IntegrationManager) EnablePolling(ctx context.Context, scheduler *collector.PollingScheduler) {
+    m.mutex.Lock()
+    defer m.mutex.Unlock()
+
+    m.pollScheduler = scheduler
+    m.pollCtx = ctx
+    for _, integration := range m.activeIntegrations {
+        m.schedulePolling(integration)
+    }
+}
+
 // DeployIntegration deploys a new integration with enhanced validation and monitoring
 func (m *IntegrationManager) DeployIntegration(ctx context.Context, cfg *config.IntegrationConfig) (string, error) {
     ctx, span := m.tracer.Start(ctx, "DeployIntegration")
     defer span.End()
 
-    timer := prometheus.NewTimer(integrationLatency.WithLabelValues("deploy", cfg.PlatformType))
+    platformType := platformLabel(cfg.PlatformType)
+    timer := prometheus.NewTimer(integrationLatency.WithLabelValues("deploy", platformType))
     defer timer.ObserveDuration()
 
     // Validate integration configuration
     if err := validator.ValidateIntegration(ctx, cfg); err != nil {
-        integrationDeployments.WithLabelValues(cfg.PlatformType, "failed").Inc()
+        integrationDeployments.WithLabelValues(platformType, "failed").Inc()
         return "", errors.WrapError(err, "integration validation failed", map[string]interface{}{
             "platform_type": cfg.PlatformType,
         })
@@ -113,13 +156,13 @@ func (m *IntegrationManager) DeployIntegration(ctx context.Context, cfg *config.
     // Get platform instance
     platform, err := m.platformRegistry.GetPlatform(cfg.PlatformType)
     if err != nil {
-        integrationDeployments.WithLabelValues(cfg.PlatformType, "failed").Inc()
+        integrationDeployments.WithLabelValues(platformType, "failed").Inc()
         return "", errors.WrapError(err, "failed to get platform instance", nil)
     }
 
     // Initialize platform
     if err := platform.Initialize(ctx, cfg); err != nil {
-        integrationDeployments.WithLabelValues(cfg.PlatformType, "failed").Inc()
+        integrationDeployments.WithLabelValues(platformType, "failed").Inc()
         return "", errors.WrapError(err, "platform initialization failed", nil)
     }
 
@@ -130,12 +173,13 @@ func (m *IntegrationManager) DeployIntegration(ctx context.Context, cfg *config.
         Platform:    platform,
         DeployedAt:  time.Now().UTC(),
         LastUpdated: time.Now().UTC(),
+        ActiveVersion: VersionBlue,
     }
 
     // Initialize status
     status, err := platform.GetStatus(ctx)
     if err != nil {
-        integrationDeployments.WithLabelValues(cfg.PlatformType, "failed").Inc()
+        integrationDeployments.WithLabelValues(platformType, "failed").Inc()
         return "", errors.WrapError(err, "failed to get platform status", nil)
     }
     integration.Status = status
@@ -151,13 +195,18 @@ func (m *IntegrationManager) DeployIntegration(ctx context.Context, cfg *config.
         delete(m.activeIntegrations, integration.ID)
         m.mutex.Unlock()
         
-        integrationDeployments.WithLabelValues(cfg.PlatformType, "failed").Inc()
+        integrationDeployments.WithLabelValues(platformType, "failed").Inc()
         return "", errors.WrapError(err, "failed to start data collection", nil)
     }
 
+    m.mutex.Lock()
+    m.schedulePolling(integration)
+    m.mutex.Unlock()
+
     // Update metrics
-    integrationDeployments.WithLabelValues(cfg.PlatformType, "success").Inc()
-    activeIntegrations.WithLabelValues(cfg.PlatformType).Inc()
+    integrationDeployments.WithLabelValues(platformType, "success").Inc()
+    activeIntegrations.WithLabelValues(platformType).Inc()
+    m.probeValidator.AnnounceChange(ctx, cfg.PlatformType)
 
     logging.Info("Integration deployed successfully",
         "integration_id", integration.ID,
@@ -167,6 +216,20 @@ func (m *IntegrationManager) DeployIntegration(ctx context.Context, cfg *config.
     return integration.ID, nil
 }
 
+// ValidationWarnings returns the warning findings for a configuration. They do
+// not block deployment but are reported back to the caller.
+func (m *IntegrationManager) ValidationWarnings(ctx context.Context, cfg *config.IntegrationConfig) []ValidationFinding {
+    result := m.probeValidator.Validate(ctx, cfg, ValidationOptions{})
+
+    var warnings []ValidationFinding
+    for _, finding := range result.Findings {
+        if finding.Severity == SeverityWarning {
+            warnings = append(warnings, finding)
+        }
+    }
+    return warnings
+}
+
 // StopIntegration safely stops and removes an integration
 func (m *IntegrationManager) StopIntegration(ctx context.Context, integrationID string) error {
     ctx, span := m.tracer.Start(ctx, "StopIntegration")
@@ -182,19 +245,24 @@ func (m *IntegrationManager) StopIntegration(ctx context.Context, integrationID
         })
     }
 
-    timer := prometheus.NewTimer(integrationLatency.WithLabelValues("stop", integration.Config.PlatformType))
+    timer := prometheus.NewTimer(integrationLatency.WithLabelValues("stop", platformLabel(integration.Config.PlatformType)))
     defer timer.ObserveDuration()
 
+    if m.pollScheduler != nil {
+        m.pollScheduler.Unschedule(integrationID)
+    }
+
     // Stop data collection
     if err := integration.Platform.StopCollection(ctx); err != nil {
         return errors.WrapError(err, "failed to stop data collection", nil)
     }
 
     // Update metrics
-    activeIntegrations.WithLabelValues(integration.Config.PlatformType).Dec()
+    activeIntegrations.WithLabelValues(platformLabel(integration.Config.PlatformType)).Dec()
 
     // Remove from active integrations
     delete(m.activeIntegrations, integrationID)
+    m.probeValidator.AnnounceChange(ctx, integration.Config.PlatformType)
 
     logging.Info("Integration stopped successfully",
         "integration_id", integrationID,
@@ -219,7 +287,7 @@ func (m *IntegrationManager) GetIntegrationStatus(ctx context.Context, integrati
         })
     }
 
-    timer := prometheus.NewTimer(integrationLatency.WithLabelValues("status", integration.Config.PlatformType))
+    timer := prometheus.NewTimer(integrationLatency.WithLabelValues("status", platformLabel(integration.Config.PlatformType)))
     defer timer.ObserveDuration()
 
     status, err := integration.Platform.GetStatus(ctx)
@@ -233,6 +301,18 @@ func (m *IntegrationManager) GetIntegrationStatus(ctx context.Context, integrati
     return status, nil
 }
 
+// ProbeIntegration checks that the platform in a configuration is reachable
+// with its credentials before the integration is deployed
+func (m *IntegrationManager) ProbeIntegration(ctx context.Context, cfg *config.IntegrationConfig) (*ProbeResult, error) {
+    ctx, span := m.tracer.Start(ctx, "ProbeIntegration")
+    defer span.End()
+
+    timer := prometheus.NewTimer(integrationLatency.WithLabelValues("probe", platformLabel(cfg.PlatformType)))
+    defer timer.ObserveDuration()
+
+    return m.probeValidator.ProbeConnectivity(ctx, cfg)
+}
+
 // ListIntegrations returns a list of all active integrations
 func (m *IntegrationManager) ListIntegrations(ctx context.Context) []*Integration {
     ctx, span := m.tracer.Start(ctx, "ListIntegrations")
@@ -269,7 +349,37 @@ func (m *IntegrationManager) GetMetrics(ctx context.Context) map[string]interfac
     return metrics
 }
 
+// schedulePolling polls integration on the scheduler when it collects in
+// batch or hybrid mode and its platform supports polling, and unschedules it
+// otherwise. Scheduling an integration again replaces its job. The caller
+// must hold m.mutex.
+func (m *IntegrationManager) schedulePolling(integration *Integration) {
+    if m.pollScheduler == nil {
+        return
+    }
+    mode := integration.Config.Collection.Mode
+    poller, ok := integration.Platform.(platform.Poller)
+    if !ok || (mode != "batch" && mode != "hybrid") {
+        // A cutover may leave an integration that no longer polls
+        m.pollScheduler.Unschedule(integration.ID)
+        return
+    }
+
+    if err := m.pollScheduler.Schedule(m.pollCtx, integration.ID, integration.Config.PlatformType, poller.Poll); err != nil {
+        logging.Error("Failed to schedule polling integration", err,
+            "integration_id", integration.ID,
+            "platform_type", integration.Config.PlatformType,
+        )
+    }
+}
+
 // Helper function to generate unique integration ID
 func generateIntegrationID(cfg *config.IntegrationConfig) string {
     return fmt.Sprintf("%s-%s-%d", cfg.PlatformType, cfg.Name, time.Now().UnixNano())
-}
\ No newline at end of file
+}
+
+// platformLabel caps the distinct platform_type values recorded by integration
+// metrics; platform types are caller-supplied and otherwise unbounded
+func platformLabel(platformType string) string {
+    return metrics.Guard("blackpoint_integration").Value("platform_type", platformType)
+}
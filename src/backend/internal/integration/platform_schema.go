@@ -0,0 +1,394 @@
+// Package integration provides JSON Schema validation of platform-specific integration settings
+package integration
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "math"
+    "reflect"
+    "regexp"
+    "sort"
+
+    "../../pkg/integration/config"
+    "../../pkg/common/errors"
+)
+
+// Field path prefix for platform-specific findings
+const platformSpecificField = "platform_specific"
+
+// builtinPlatformSchemas validate platform_specific settings for the supported platforms
+var builtinPlatformSchemas = map[string]string{
+    "okta": `{
+        "type": "object",
+        "required": ["domain"],
+        "properties": {
+            "domain": {"type": "string", "pattern": "^[a-z0-9-]+\\.(okta|oktapreview|okta-emea)\\.com$"},
+            "rate_limit": {"type": "integer", "minimum": 1},
+            "event_types": {"type": "array", "items": {"type": "string", "minLength": 1}}
+        }
+    }`,
+    "azure": `{
+        "type": "object",
+        "required": ["tenant_id"],
+        "properties": {
+            "tenant_id": {"type": "string", "pattern": "^[0-9a-fA-F-]{36}$"},
+            "subscription_id": {"type": "string", "pattern": "^[0-9a-fA-F-]{36}$"},
+            "cloud": {"type": "string", "enum": ["public", "government", "china"]}
+        }
+    }`,
+    "aws": `{
+        "type": "object",
+        "required": ["region"],
+        "properties": {
+            "region": {"type": "string", "pattern": "^[a-z]{2}(-gov)?-[a-z]+-[0-9]$"},
+            "account_id": {"type": "string", "pattern": "^[0-9]{12}$"},
+            "role_arn": {"type": "string", "pattern": "^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$"}
+        }
+    }`,
+    "gcp": `{
+        "type": "object",
+        "required": ["project_id"],
+        "properties": {
+            "project_id": {"type": "string", "pattern": "^[a-z][a-z0-9-]{4,28}[a-z0-9]$"},
+            "organization_id": {"type": "string", "pattern": "^[0-9]+$"}
+        }
+    }`,
+    "crowdstrike": `{
+        "type": "object",
+        "required": ["cloud"],
+        "properties": {
+            "cloud": {"type": "string", "enum": ["us-1", "us-2", "eu-1", "us-gov-1"]},
+            "member_cid": {"type": "string", "minLength": 1}
+        }
+    }`,
+}
+
+// platformSchema is the subset of JSON Schema used for platform settings:
+// type, required, properties, additionalProperties, items, enum, pattern,
+// minLength, minimum and maximum. Schemas using any other validation keyword
+// are rejected rather than silently accepting values the keyword would refuse.
+type platformSchema struct {
+    Type                 string                     `json:"type"`
+    Required             []string                   `json:"required"`
+    Properties           map[string]*platformSchema `json:"properties"`
+    AdditionalProperties *bool                      `json:"additionalProperties"`
+    Items                *platformSchema            `json:"items"`
+    Enum                 []interface{}              `json:"enum"`
+    Pattern              string                     `json:"pattern"`
+    MinLength            *int                       `json:"minLength"`
+    Minimum              *float64                   `json:"minimum"`
+    Maximum              *float64                   `json:"maximum"`
+
+    pattern *regexp.Regexp
+}
+
+// supportedSchemaKeywords are the keywords platformSchema enforces, plus
+// annotations that do not constrain values
+var supportedSchemaKeywords = map[string]bool{
+    "type":                 true,
+    "required":             true,
+    "properties":           true,
+    "additionalProperties": true,
+    "items":                true,
+    "enum":                 true,
+    "pattern":              true,
+    "minLength":            true,
+    "minimum":              true,
+    "maximum":              true,
+
+    "$schema":     true,
+    "$comment":    true,
+    "title":       true,
+    "description": true,
+    "default":     true,
+    "examples":    true,
+}
+
+// schemaViolation is a single schema failure at a field path
+type schemaViolation struct {
+    path    string
+    message string
+}
+
+// RegisterPlatformSchema sets the JSON Schema that platform_specific settings
+// must satisfy for a platform, replacing any built-in schema
+func (v *IntegrationValidator) RegisterPlatformSchema(platformType string, schema json.RawMessage) error {
+    if !isPlatformSupported(platformType) {
+        return errors.NewError("E2001", "cannot register schema for unsupported platform", map[string]interface{}{
+            "platform_type": platformType,
+        })
+    }
+
+    compiled, err := compilePlatformSchema(schema)
+    if err != nil {
+        return errors.WrapError(err, "invalid platform schema", map[string]interface{}{
+            "platform_type": platformType,
+        })
+    }
+
+    v.mu.Lock()
+    v.schemas[platformType] = compiled
+    v.mu.Unlock()
+
+    v.AnnounceChange(context.Background(), platformType)
+    return nil
+}
+
+// validatePlatformSchema checks platform_specific settings against the
+// platform's schema, reporting one finding per offending field
+func (v *IntegrationValidator) validatePlatformSchema(cfg *config.IntegrationConfig) []ValidationFinding {
+    v.mu.RLock()
+    schema, exists := v.schemas[cfg.PlatformType]
+    v.mu.RUnlock()
+    if !exists {
+        return nil
+    }
+
+    settings := cfg.PlatformSpecific
+    if settings == nil {
+        settings = map[string]interface{}{}
+    }
+
+    var findings []ValidationFinding
+    for _, violation := range schema.validate(settings, platformSpecificField) {
+        findings = append(findings, ValidationFinding{
+            Field:    violation.path,
+            Severity: SeverityError,
+            Message:  violation.message,
+            category: "platform_schema",
+            err: errors.NewError("E2001", fmt.Sprintf("%s: %s", violation.path, violation.message), map[string]interface{}{
+                "field":         violation.path,
+                "platform_type": cfg.PlatformType,
+            }),
+        })
+    }
+    return findings
+}
+
+// mustCompilePlatformSchema compiles a built-in schema, panicking if it is invalid
+func mustCompilePlatformSchema(raw string) *platformSchema {
+    schema, err := compilePlatformSchema(json.RawMessage(raw))
+    if err != nil {
+        panic(fmt.Sprintf("invalid built-in platform schema: %v", err))
+    }
+    return schema
+}
+
+// compilePlatformSchema parses a schema and compiles its patterns
+func compilePlatformSchema(raw json.RawMessage) (*platformSchema, error) {
+    var schema platformSchema
+    if err := json.Unmarshal(raw, &schema); err != nil {
+        return nil, errors.WrapError(err, "failed to parse platform schema", nil)
+    }
+    if err := checkSchemaKeywords(raw, ""); err != nil {
+        return nil, err
+    }
+    if err := schema.compile(""); err != nil {
+        return nil, err
+    }
+    return &schema, nil
+}
+
+// checkSchemaKeywords rejects keywords platformSchema does not enforce,
+// throughout the schema
+func checkSchemaKeywords(raw json.RawMessage, path string) error {
+    var keywords map[string]json.RawMessage
+    if err := json.Unmarshal(raw, &keywords); err != nil {
+        return errors.WrapError(err, "failed to parse platform schema", map[string]interface{}{
+            "path": path,
+        })
+    }
+
+    names := make([]string, 0, len(keywords))
+    for name := range keywords {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    for _, name := range names {
+        if !supportedSchemaKeywords[name] {
+            return errors.NewError("E2001", fmt.Sprintf("unsupported schema keyword %q at %q", name, path), map[string]interface{}{
+                "keyword": name,
+                "path":    path,
+            })
+        }
+    }
+
+    if properties, ok := keywords["properties"]; ok {
+        var schemas map[string]json.RawMessage
+        if err := json.Unmarshal(properties, &schemas); err != nil {
+            return errors.WrapError(err, "failed to parse platform schema", map[string]interface{}{
+                "path": path,
+            })
+        }
+        for name, property := range schemas {
+            if err := checkSchemaKeywords(property, joinFieldPath(path, name)); err != nil {
+                return err
+            }
+        }
+    }
+    if items, ok := keywords["items"]; ok {
+        return checkSchemaKeywords(items, path+"[]")
+    }
+    return nil
+}
+
+// compile checks types and compiles patterns throughout the schema
+func (s *platformSchema) compile(path string) error {
+    switch s.Type {
+    case "", "object", "array", "string", "integer", "number", "boolean":
+    default:
+        return errors.NewError("E2001", fmt.Sprintf("unsupported schema type %q at %q", s.Type, path), map[string]interface{}{
+            "type": s.Type,
+            "path": path,
+        })
+    }
+
+    if s.Pattern != "" {
+        pattern, err := regexp.Compile(s.Pattern)
+        if err != nil {
+            return errors.NewError("E2001", fmt.Sprintf("invalid schema pattern at %q", path), map[string]interface{}{
+                "pattern": s.Pattern,
+                "path":    path,
+            })
+        }
+        s.pattern = pattern
+    }
+
+    for name, property := range s.Properties {
+        if err := property.compile(joinFieldPath(path, name)); err != nil {
+            return err
+        }
+    }
+    if s.Items != nil {
+        return s.Items.compile(path + "[]")
+    }
+    return nil
+}
+
+// validate returns the violations of value against the schema
+func (s *platformSchema) validate(value interface{}, path string) []schemaViolation {
+    if s.Type != "" && !matchesSchemaType(s.Type, value) {
+        return []schemaViolation{{path: path, message: fmt.Sprintf("must be of type %s", s.Type)}}
+    }
+
+    var violations []schemaViolation
+
+    if len(s.Enum) > 0 && !inSchemaEnum(s.Enum, value) {
+        violations = append(violations, schemaViolation{path: path, message: fmt.Sprintf("must be one of %v", s.Enum)})
+    }
+
+    switch typed := value.(type) {
+    case string:
+        if s.MinLength != nil && len([]rune(typed)) < *s.MinLength {
+            violations = append(violations, schemaViolation{path: path, message: fmt.Sprintf("must be at least %d characters", *s.MinLength)})
+        }
+        if s.pattern != nil && !s.pattern.MatchString(typed) {
+            violations = append(violations, schemaViolation{path: path, message: fmt.Sprintf("must match pattern %s", s.Pattern)})
+        }
+
+    case map[string]interface{}:
+        for _, name := range s.Required {
+            if _, ok := typed[name]; !ok {
+                violations = append(violations, schemaViolation{path: joinFieldPath(path, name), message: "is required"})
+            }
+        }
+
+        names := make([]string, 0, len(typed))
+        for name := range typed {
+            names = append(names, name)
+        }
+        sort.Strings(names)
+        for _, name := range names {
+            property, known := s.Properties[name]
+            switch {
+            case known:
+                violations = append(violations, property.validate(typed[name], joinFieldPath(path, name))...)
+            case s.AdditionalProperties != nil && !*s.AdditionalProperties:
+                violations = append(violations, schemaViolation{path: joinFieldPath(path, name), message: "is not an allowed field"})
+            }
+        }
+
+    case []interface{}:
+        if s.Items != nil {
+            for i, item := range typed {
+                violations = append(violations, s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+            }
+        }
+
+    default:
+        if number, ok := schemaNumber(value); ok {
+            if s.Minimum != nil && number < *s.Minimum {
+                violations = append(violations, schemaViolation{path: path, message: fmt.Sprintf("must be at least %v", *s.Minimum)})
+            }
+            if s.Maximum != nil && number > *s.Maximum {
+                violations = append(violations, schemaViolation{path: path, message: fmt.Sprintf("must be at most %v", *s.Maximum)})
+            }
+        }
+    }
+
+    return violations
+}
+
+// matchesSchemaType reports whether a decoded YAML or JSON value has the schema type
+func matchesSchemaType(schemaType string, value interface{}) bool {
+    switch schemaType {
+    case "object":
+        _, ok := value.(map[string]interface{})
+        return ok
+    case "array":
+        _, ok := value.([]interface{})
+        return ok
+    case "string":
+        _, ok := value.(string)
+        return ok
+    case "boolean":
+        _, ok := value.(bool)
+        return ok
+    case "number":
+        _, ok := schemaNumber(value)
+        return ok
+    case "integer":
+        number, ok := schemaNumber(value)
+        return ok && number == math.Trunc(number)
+    }
+    return true
+}
+
+// schemaNumber converts numeric values produced by YAML and JSON decoding
+func schemaNumber(value interface{}) (float64, bool) {
+    switch n := value.(type) {
+    case int:
+        return float64(n), true
+    case int64:
+        return float64(n), true
+    case uint64:
+        return float64(n), true
+    case float64:
+        return n, true
+    }
+    return 0, false
+}
+
+// inSchemaEnum reports whether value equals one of the enum values
+func inSchemaEnum(enum []interface{}, value interface{}) bool {
+    for _, allowed := range enum {
+        if reflect.DeepEqual(allowed, value) {
+            return true
+        }
+        if a, ok := schemaNumber(allowed); ok {
+            if b, ok := schemaNumber(value); ok && a == b {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// joinFieldPath appends a field name to a dotted path
+func joinFieldPath(path, name string) string {
+    if path == "" {
+        return name
+    }
+    return path + "." + name
+}
@@ -0,0 +1,333 @@
+// Package integration provides live connectivity probes for platform integrations
+package integration
+
+import (
+    "context"
+    stderrors "errors"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "net"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus" // v1.12.0
+    "golang.org/x/oauth2"                            // v0.8.0
+
+    "../../pkg/integration/config"
+    "../../pkg/common/errors"
+)
+
+// Probe authentication statuses
+const (
+    ProbeAuthenticated = "authenticated"
+    ProbeRejected      = "rejected"
+    ProbeUnreachable   = "unreachable"
+    ProbeFailed        = "failed"
+)
+
+// Probe methods
+const (
+    ProbeMethodToken = "token"
+    ProbeMethodList  = "list"
+)
+
+// probeTimeout bounds a probe; third-party APIs are called once and never retried
+const probeTimeout = 5 * time.Second
+
+// probeLatency records how long each probe took by outcome
+var probeLatency = prometheus.NewHistogramVec(
+    prometheus.HistogramOpts{
+        Name:    "blackpoint_integration_probe_latency_seconds",
+        Help:    "Latency of integration connectivity probes",
+        Buckets: prometheus.ExponentialBuckets(0.05, 2, 8),
+    },
+    []string{"platform_type", "auth_status"},
+)
+
+func init() {
+    prometheus.MustRegister(probeLatency)
+}
+
+// probeEndpoints build a read-only list endpoint per platform from its
+// platform-specific settings. Each requests at most one record.
+var probeEndpoints = map[string]func(settings map[string]interface{}) string{
+    "okta": func(settings map[string]interface{}) string {
+        if domain, _ := settings["domain"].(string); domain != "" {
+            return fmt.Sprintf("https://%s/api/v1/users?limit=1", domain)
+        }
+        return ""
+    },
+    "azure": func(settings map[string]interface{}) string {
+        return "https://management.azure.com/subscriptions?api-version=2020-01-01&$top=1"
+    },
+    "gcp": func(settings map[string]interface{}) string {
+        if project, _ := settings["project_id"].(string); project != "" {
+            return fmt.Sprintf("https://cloudresourcemanager.googleapis.com/v1/projects/%s", project)
+        }
+        return ""
+    },
+    "crowdstrike": func(settings map[string]interface{}) string {
+        hosts := map[string]string{
+            "us-1":     "api.crowdstrike.com",
+            "us-2":     "api.us-2.crowdstrike.com",
+            "eu-1":     "api.eu-1.crowdstrike.com",
+            "us-gov-1": "api.laggar.gcw.crowdstrike.com",
+        }
+        if cloud, _ := settings["cloud"].(string); hosts[cloud] != "" {
+            return fmt.Sprintf("https://%s/sensors/queries/sensors/v1?limit=1", hosts[cloud])
+        }
+        return ""
+    },
+}
+
+// probeHosts allowlists the hosts each platform's probes may call, including
+// token endpoints. Entries starting with '.' match any subdomain.
+var probeHosts = map[string][]string{
+    "okta":  {".okta.com", ".oktapreview.com", ".okta-emea.com"},
+    "azure": {"management.azure.com", "login.microsoftonline.com"},
+    "gcp":   {"cloudresourcemanager.googleapis.com", "oauth2.googleapis.com"},
+    "crowdstrike": {
+        "api.crowdstrike.com",
+        "api.us-2.crowdstrike.com",
+        "api.eu-1.crowdstrike.com",
+        "api.laggar.gcw.crowdstrike.com",
+    },
+}
+
+// ProbeResult is the outcome of a connectivity probe
+type ProbeResult struct {
+    PlatformType string        `json:"platform_type"`
+    Method       string        `json:"method"`
+    Endpoint     string        `json:"endpoint"`
+    AuthStatus   string        `json:"auth_status"`
+    StatusCode   int           `json:"status_code,omitempty"`
+    Latency      time.Duration `json:"latency"`
+    Message      string        `json:"message,omitempty"`
+}
+
+// Authenticated reports whether the platform accepted the credentials
+func (r *ProbeResult) Authenticated() bool {
+    return r.AuthStatus == ProbeAuthenticated
+}
+
+// SetProbeHTTPClient sets the HTTP client used by connectivity probes, for
+// example to route them through an egress proxy
+func (v *IntegrationValidator) SetProbeHTTPClient(client *http.Client) {
+    v.mu.Lock()
+    defer v.mu.Unlock()
+    v.probeClient = client
+}
+
+// ProbeConnectivity makes one lightweight authenticated call to the platform:
+// a token fetch for auth types that support it, otherwise a read-only list
+// request. No data is collected. Unreachable or rejected platforms are
+// reported in the result; an error means the probe could not be attempted.
+// The configuration is validated first and only the platform's allowlisted
+// hosts are called, so a probe cannot be pointed at internal services.
+func (v *IntegrationValidator) ProbeConnectivity(ctx context.Context, cfg *config.IntegrationConfig) (*ProbeResult, error) {
+    v.mu.RLock()
+    opts := v.options
+    v.mu.RUnlock()
+
+    // The probe is the live call; validation must not make another
+    opts.ValidateConnectivity = false
+    if result := v.Validate(ctx, cfg, opts); !result.Valid {
+        return nil, result.Errors[0]
+    }
+
+    authenticator, err := config.NewAuthenticator(cfg.Auth)
+    if err != nil {
+        return nil, errors.WrapError(err, "invalid credentials", map[string]interface{}{
+            "platform_type": cfg.PlatformType,
+            "auth_type":     cfg.Auth.Type,
+        })
+    }
+
+    client := v.probeHTTPClient(authenticator)
+    ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+    defer cancel()
+
+    result := &ProbeResult{PlatformType: cfg.PlatformType}
+    start := time.Now()
+
+    if checker, ok := authenticator.(config.ConnectivityChecker); ok {
+        result.Method = ProbeMethodToken
+        result.Endpoint, _ = cfg.Auth.Credentials["token_url"].(string)
+        if err := checkProbeTarget(cfg.PlatformType, result.Endpoint); err != nil {
+            return nil, err
+        }
+        err := checker.CheckConnectivity(context.WithValue(ctx, oauth2.HTTPClient, client))
+        classifyTokenProbe(result, err)
+    } else {
+        endpoint := ""
+        if build, ok := probeEndpoints[cfg.PlatformType]; ok {
+            endpoint = build(cfg.PlatformSpecific)
+        }
+        if endpoint == "" {
+            return nil, errors.NewError("E2001", fmt.Sprintf("no connectivity probe for %s with %s authentication", cfg.PlatformType, cfg.Auth.Type), map[string]interface{}{
+                "platform_type": cfg.PlatformType,
+                "auth_type":     cfg.Auth.Type,
+            })
+        }
+        if err := checkProbeTarget(cfg.PlatformType, endpoint); err != nil {
+            return nil, err
+        }
+        result.Method = ProbeMethodList
+        result.Endpoint = endpoint
+        if err := probeListEndpoint(ctx, client, authenticator, result); err != nil {
+            return nil, err
+        }
+    }
+
+    result.Latency = time.Since(start)
+    probeLatency.WithLabelValues(platformLabel(cfg.PlatformType), result.AuthStatus).Observe(result.Latency.Seconds())
+    return result, nil
+}
+
+// probeHTTPClient returns the configured probe client, or one that presents
+// transport-level credentials such as mTLS client certificates
+func (v *IntegrationValidator) probeHTTPClient(authenticator config.Authenticator) *http.Client {
+    v.mu.RLock()
+    client := v.probeClient
+    v.mu.RUnlock()
+    if client != nil {
+        return client
+    }
+
+    transport := http.DefaultTransport.(*http.Transport).Clone()
+    transport.DialContext = probeDialContext(&net.Dialer{Timeout: probeTimeout})
+    if transportAuth, ok := authenticator.(config.TransportAuthenticator); ok {
+        // Validated when the authenticator was built
+        transportAuth.ConfigureTransport(transport)
+    }
+    return &http.Client{
+        Transport: transport,
+        Timeout:   probeTimeout,
+        CheckRedirect: func(req *http.Request, via []*http.Request) error {
+            return http.ErrUseLastResponse
+        },
+    }
+}
+
+// checkProbeTarget rejects probe endpoints that are not HTTPS on the default
+// port of a host allowlisted for the platform
+func checkProbeTarget(platformType, endpoint string) error {
+    target, err := url.Parse(endpoint)
+    if err != nil || target.Scheme != "https" || target.Hostname() == "" || (target.Port() != "" && target.Port() != "443") {
+        return errors.NewError("E2001", "probe endpoint must be an https URL", map[string]interface{}{
+            "platform_type": platformType,
+        })
+    }
+
+    host := strings.ToLower(target.Hostname())
+    for _, allowed := range probeHosts[platformType] {
+        if host == allowed || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+            return nil
+        }
+    }
+    return errors.NewError("E2001", "probe endpoint host is not allowed for the platform", map[string]interface{}{
+        "platform_type": platformType,
+        "host":          host,
+    })
+}
+
+// probeDialContext resolves the target host and dials only public addresses,
+// so an allowlisted name that resolves to a loopback, private or link-local
+// address cannot be used to reach internal services
+func probeDialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+    return func(ctx context.Context, network, address string) (net.Conn, error) {
+        host, port, err := net.SplitHostPort(address)
+        if err != nil {
+            return nil, err
+        }
+        addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+        if err != nil {
+            return nil, err
+        }
+        if len(addrs) == 0 {
+            return nil, fmt.Errorf("no addresses for %s", host)
+        }
+        for _, addr := range addrs {
+            if !isPublicAddress(addr.IP) {
+                return nil, errors.NewError("E2001", "probe endpoint resolves to a non-public address", map[string]interface{}{
+                    "host":    host,
+                    "address": addr.IP.String(),
+                })
+            }
+        }
+        // Dial the checked address so the name is not resolved again
+        return dialer.DialContext(ctx, network, net.JoinHostPort(addrs[0].IP.String(), port))
+    }
+}
+
+// isPublicAddress reports whether ip is routable on the public internet
+func isPublicAddress(ip net.IP) bool {
+    return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+        ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// probeListEndpoint sends a single authenticated GET and records the outcome
+func probeListEndpoint(ctx context.Context, client *http.Client, authenticator config.Authenticator, result *ProbeResult) error {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, result.Endpoint, nil)
+    if err != nil {
+        return errors.WrapError(err, "failed to build probe request", map[string]interface{}{
+            "endpoint": result.Endpoint,
+        })
+    }
+    if err := authenticator.Authenticate(req); err != nil {
+        result.AuthStatus = ProbeRejected
+        result.Message = "failed to authenticate probe request"
+        return nil
+    }
+
+    resp, err := client.Do(req)
+    if err != nil {
+        result.AuthStatus = ProbeUnreachable
+        result.Message = "platform did not respond"
+        return nil
+    }
+    defer resp.Body.Close()
+    io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+    result.StatusCode = resp.StatusCode
+    switch {
+    case resp.StatusCode >= 200 && resp.StatusCode < 300:
+        result.AuthStatus = ProbeAuthenticated
+    case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+        result.AuthStatus = ProbeRejected
+        result.Message = "platform rejected the credentials"
+    default:
+        result.AuthStatus = ProbeFailed
+        result.Message = fmt.Sprintf("unexpected response status %d", resp.StatusCode)
+    }
+    return nil
+}
+
+// classifyTokenProbe records the outcome of a token fetch. Identity providers
+// answer bad client credentials with 400 or 401, so both count as rejections.
+func classifyTokenProbe(result *ProbeResult, err error) {
+    if err == nil {
+        result.AuthStatus = ProbeAuthenticated
+        return
+    }
+
+    var retrieveErr *oauth2.RetrieveError
+    if !stderrors.As(err, &retrieveErr) || retrieveErr.Response == nil {
+        result.AuthStatus = ProbeUnreachable
+        result.Message = "token endpoint did not respond"
+        return
+    }
+
+    result.StatusCode = retrieveErr.Response.StatusCode
+    switch result.StatusCode {
+    case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+        result.AuthStatus = ProbeRejected
+        result.Message = "token endpoint rejected the client credentials"
+    default:
+        result.AuthStatus = ProbeFailed
+        result.Message = fmt.Sprintf("unexpected token endpoint status %d", result.StatusCode)
+    }
+}
@@ -177,6 +177,27 @@ func (r *Registry) GetPlatform(platformType string) (platform.Platform, error) {
     return platform, nil
 }
 
+// NewPlatform creates a platform instance that is not cached or shared, for
+// running a second version of an integration alongside the cached instance
+func (r *Registry) NewPlatform(platformType string) (platform.Platform, error) {
+    r.mutex.RLock()
+    factory, exists := r.factories[platformType]
+    r.mutex.RUnlock()
+    if !exists {
+        return nil, errors.NewError("E2001", "platform type not registered", map[string]interface{}{
+            "platform_type": platformType,
+        })
+    }
+
+    platform, err := factory()
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to create platform instance", map[string]interface{}{
+            "platform_type": platformType,
+        })
+    }
+    return platform, nil
+}
+
 // ListPlatforms returns a list of registered platform types
 func (r *Registry) ListPlatforms() []string {
     // Apply rate limiting
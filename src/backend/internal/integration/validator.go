@@ -3,6 +3,8 @@ package integration
 
 import (
     "context"
+    "fmt"
+    "net/http"
     "sync"
     "time"
 
@@ -12,6 +14,7 @@ import (
     "../../pkg/integration/config"
     "../../pkg/integration/platform"
     "../../pkg/common/errors"
+    "../../pkg/common/logging"
 )
 
 // Validation metrics
@@ -53,12 +56,81 @@ type IntegrationValidator struct {
     validator *validator.Validate
     cache    *sync.Map
     rules    map[string]string
+    schemas  map[string]*platformSchema
+    options  ValidationOptions
+    probeClient *http.Client
+    broadcaster ConfigBroadcaster
+    mu       sync.RWMutex
+}
+
+// ConfigChangeChannel is the pub/sub channel replicas announce integration
+// configuration changes on; each message is the affected platform type, or
+// empty for all platforms
+const ConfigChangeChannel = "integration:config_changed"
+
+// ConfigBroadcaster carries configuration change announcements between
+// replicas; the storage package's RedisClient satisfies it
+type ConfigBroadcaster interface {
+    Publish(ctx context.Context, channel string, msg []byte) error
+    Subscribe(ctx context.Context, channel string, handler func([]byte)) error
+}
+
+// Validation finding severities
+const (
+    SeverityError   = "error"
+    SeverityWarning = "warning"
+    SeverityInfo    = "info"
+)
+
+// Recommended batch size range; sizes outside it are valid but inefficient
+const (
+    minRecommendedBatchSize = 100
+    maxRecommendedBatchSize = 5000
+)
+
+// connectivityTimeout bounds the live credential check so validation never
+// waits long on a third-party service
+const connectivityTimeout = 10 * time.Second
+
+// ValidationFinding is a single issue found in an integration configuration
+type ValidationFinding struct {
+    Field    string `json:"field"`
+    Severity string `json:"severity"`
+    Message  string `json:"message"`
+
+    category string
+    err      error
+}
+
+// Error returns the underlying error, building one from the message if needed
+func (f ValidationFinding) Error() error {
+    if f.err != nil {
+        return f.err
+    }
+    return errors.NewError("E2001", f.Message, map[string]interface{}{
+        "field":    f.Field,
+        "severity": f.Severity,
+    })
+}
+
+// ValidationOptions controls which findings block validation
+type ValidationOptions struct {
+    // WarningsAsErrors makes warning findings block validation
+    WarningsAsErrors bool
+
+    // ValidateConnectivity makes one live call with the credentials, when the
+    // auth type supports it, to confirm the issuing service accepts them
+    ValidateConnectivity bool
+
+    // SeverityOverrides changes the severity of findings for specific fields
+    SeverityOverrides map[string]string
 }
 
 // ValidationResult represents the outcome of a validation operation
 type ValidationResult struct {
     Valid    bool
     Errors   []error
+    Findings []ValidationFinding
     Metadata map[string]interface{}
     CacheKey string
 }
@@ -69,6 +141,10 @@ func NewIntegrationValidator() *IntegrationValidator {
         validator: validator.New(),
         cache:    &sync.Map{},
         rules:    make(map[string]string),
+        schemas:  make(map[string]*platformSchema, len(builtinPlatformSchemas)),
+    }
+    for platformType, schema := range builtinPlatformSchemas {
+        v.schemas[platformType] = mustCompilePlatformSchema(schema)
     }
 
     // Register custom validation functions
@@ -79,65 +155,151 @@ func NewIntegrationValidator() *IntegrationValidator {
     return v
 }
 
-// ValidateIntegration performs comprehensive validation of integration configuration
+// SetValidationOptions sets the options used by ValidateIntegration
+func (v *IntegrationValidator) SetValidationOptions(opts ValidationOptions) error {
+    for field, severity := range opts.SeverityOverrides {
+        if !isValidSeverity(severity) {
+            return errors.NewError("E2001", "invalid validation severity override", map[string]interface{}{
+                "field":    field,
+                "severity": severity,
+            })
+        }
+    }
+
+    v.mu.Lock()
+    defer v.mu.Unlock()
+    v.options = opts
+    return nil
+}
+
+// ValidateIntegration performs comprehensive validation of integration
+// configuration, returning the first blocking finding as an error
 func (v *IntegrationValidator) ValidateIntegration(ctx context.Context, cfg *config.IntegrationConfig) error {
-    timer := prometheus.NewTimer(validationDuration.WithLabelValues(cfg.PlatformType, "full"))
+    v.mu.RLock()
+    opts := v.options
+    v.mu.RUnlock()
+
+    result := v.Validate(ctx, cfg, opts)
+    if !result.Valid {
+        return result.Errors[0]
+    }
+    return nil
+}
+
+// Validate runs every check and reports all findings with their severity.
+// Errors always block; warnings block only with WarningsAsErrors.
+func (v *IntegrationValidator) Validate(ctx context.Context, cfg *config.IntegrationConfig, opts ValidationOptions) *ValidationResult {
+    platform := platformLabel(cfg.PlatformType)
+    timer := prometheus.NewTimer(validationDuration.WithLabelValues(platform, "full"))
     defer timer.ObserveDuration()
 
     // Generate cache key
     cacheKey := generateCacheKey(cfg)
 
-    // Check validation cache
-    if result, ok := v.cache.Load(cacheKey); ok {
-        validationCacheHits.WithLabelValues(cfg.PlatformType).Inc()
-        if validResult, ok := result.(ValidationResult); ok && validResult.Valid {
-            return nil
+    // Check validation cache; live connectivity checks are never served from it
+    if cached, ok := v.cache.Load(cacheKey); ok && !opts.ValidateConnectivity {
+        validationCacheHits.WithLabelValues(platform).Inc()
+        if validResult, ok := cached.(ValidationResult); ok && validResult.Valid {
+            return &validResult
         }
     }
 
+    var findings []ValidationFinding
+
     // Basic structure validation
     if err := v.validator.Struct(cfg); err != nil {
-        validationErrors.WithLabelValues(cfg.PlatformType, "structure").Inc()
-        return errors.NewError("E2001", "invalid integration configuration structure", map[string]interface{}{
-            "validation_errors": err.Error(),
-            "platform_type":    cfg.PlatformType,
-        })
+        findings = append(findings, structureFindings(cfg, err)...)
     }
 
     // Platform-specific validation
     if err := v.validatePlatformSpecific(ctx, cfg); err != nil {
-        validationErrors.WithLabelValues(cfg.PlatformType, "platform_specific").Inc()
-        return err
+        findings = append(findings, ValidationFinding{
+            Field: "platform_type", Severity: SeverityError, Message: "invalid platform configuration",
+            category: "platform_specific", err: err,
+        })
+    } else {
+        findings = append(findings, v.validatePlatformSchema(cfg)...)
     }
 
-    // Authentication validation
-    if err := v.validateAuth(cfg.Auth, cfg.PlatformType); err != nil {
-        validationErrors.WithLabelValues(cfg.PlatformType, "auth").Inc()
-        return err
+    // Authentication and data collection validation
+    authFindings := v.validateAuth(cfg.Auth, cfg.PlatformType)
+    findings = append(findings, authFindings...)
+    if opts.ValidateConnectivity && len(authFindings) == 0 {
+        findings = append(findings, v.validateConnectivity(ctx, cfg.Auth, cfg.PlatformType)...)
     }
+    findings = append(findings, v.validateCollection(cfg.Collection, cfg.PlatformType)...)
 
-    // Data collection validation
-    if err := v.validateCollection(cfg.Collection, cfg.PlatformType); err != nil {
-        validationErrors.WithLabelValues(cfg.PlatformType, "collection").Inc()
-        return err
-    }
-
-    // Cache successful validation
-    v.cache.Store(cacheKey, ValidationResult{
+    result := &ValidationResult{
         Valid:    true,
+        CacheKey: cacheKey,
         Metadata: map[string]interface{}{
-            "validated_at": time.Now().UTC(),
+            "validated_at":  time.Now().UTC(),
             "platform_type": cfg.PlatformType,
         },
-        CacheKey: cacheKey,
-    })
+    }
 
-    return nil
+    for i := range findings {
+        if severity, ok := opts.SeverityOverrides[findings[i].Field]; ok {
+            findings[i].Severity = severity
+        }
+        if findings[i].Severity == SeverityError || (opts.WarningsAsErrors && findings[i].Severity == SeverityWarning) {
+            result.Valid = false
+            result.Errors = append(result.Errors, findings[i].Error())
+            validationErrors.WithLabelValues(platform, findings[i].category).Inc()
+        }
+    }
+    result.Findings = findings
+
+    // Cache only clean results, which hold under any validation options
+    if len(findings) == 0 {
+        v.cache.Store(cacheKey, *result)
+    }
+
+    return result
+}
+
+// structureFindings converts struct tag violations into per-field findings
+func structureFindings(cfg *config.IntegrationConfig, err error) []ValidationFinding {
+    fieldErrors, ok := err.(validator.ValidationErrors)
+    if !ok {
+        return []ValidationFinding{{
+            Field: "config", Severity: SeverityError, Message: "invalid integration configuration structure",
+            category: "structure",
+            err: errors.NewError("E2001", "invalid integration configuration structure", map[string]interface{}{
+                "validation_errors": err.Error(),
+                "platform_type":    cfg.PlatformType,
+            }),
+        }}
+    }
+
+    findings := make([]ValidationFinding, 0, len(fieldErrors))
+    for _, fieldErr := range fieldErrors {
+        findings = append(findings, ValidationFinding{
+            Field:    fieldErr.Namespace(),
+            Severity: SeverityError,
+            Message:  "failed " + fieldErr.Tag() + " validation",
+            category: "structure",
+            err: errors.NewError("E2001", "invalid integration configuration structure", map[string]interface{}{
+                "validation_errors": fieldErr.Error(),
+                "platform_type":    cfg.PlatformType,
+            }),
+        })
+    }
+    return findings
+}
+
+// isValidSeverity reports whether a severity level is supported
+func isValidSeverity(severity string) bool {
+    switch severity {
+    case SeverityError, SeverityWarning, SeverityInfo:
+        return true
+    }
+    return false
 }
 
 // validatePlatformSpecific performs platform-specific validation
 func (v *IntegrationValidator) validatePlatformSpecific(ctx context.Context, cfg *config.IntegrationConfig) error {
-    timer := prometheus.NewTimer(validationDuration.WithLabelValues(cfg.PlatformType, "platform_specific"))
+    timer := prometheus.NewTimer(validationDuration.WithLabelValues(platformLabel(cfg.PlatformType), "platform_specific"))
     defer timer.ObserveDuration()
 
     // Validate platform type
@@ -164,54 +326,101 @@ func (v *IntegrationValidator) validatePlatformSpecific(ctx context.Context, cfg
 }
 
 // validateAuth performs enhanced authentication configuration validation
-func (v *IntegrationValidator) validateAuth(auth config.AuthenticationConfig, platformType string) error {
-    timer := prometheus.NewTimer(validationDuration.WithLabelValues(platformType, "auth"))
+func (v *IntegrationValidator) validateAuth(auth config.AuthenticationConfig, platformType string) []ValidationFinding {
+    timer := prometheus.NewTimer(validationDuration.WithLabelValues(platformLabel(platformType), "auth"))
     defer timer.ObserveDuration()
 
+    var findings []ValidationFinding
+
     // Validate auth type compatibility
     if !isAuthTypeSupported(auth.Type, platformType) {
-        return errors.NewError("E2001", "unsupported authentication type for platform", map[string]interface{}{
-            "auth_type": auth.Type,
-            "platform_type": platformType,
+        findings = append(findings, ValidationFinding{
+            Field: "auth.type", Severity: SeverityError, Message: "unsupported authentication type for platform",
+            category: "auth",
+            err: errors.NewError("E2001", "unsupported authentication type for platform", map[string]interface{}{
+                "auth_type": auth.Type,
+                "platform_type": platformType,
+            }),
         })
     }
 
     // Validate credentials
     if err := validateCredentials(auth.Credentials, auth.Type); err != nil {
-        return err
+        findings = append(findings, ValidationFinding{
+            Field: "auth.credentials", Severity: SeverityError, Message: "invalid credentials",
+            category: "auth", err: err,
+        })
     }
 
     // Validate token expiry and renewal
-    if auth.ExpiryTime > 0 {
-        if auth.ExpiryTime < time.Hour {
-            return errors.NewError("E2001", "token expiry time too short", map[string]interface{}{
+    if auth.ExpiryTime > 0 && auth.ExpiryTime < time.Hour {
+        findings = append(findings, ValidationFinding{
+            Field: "auth.expiry_time", Severity: SeverityError, Message: "token expiry time shorter than 1h",
+            category: "auth",
+            err: errors.NewError("E2001", "token expiry time too short", map[string]interface{}{
                 "min_expiry": "1h",
                 "provided_expiry": auth.ExpiryTime,
-            })
-        }
+            }),
+        })
+    }
+
+    return findings
+}
+
+// validateConnectivity confirms the credentials with a single live call. Auth
+// types without a side-effect-free check are reported as info findings.
+func (v *IntegrationValidator) validateConnectivity(ctx context.Context, auth config.AuthenticationConfig, platformType string) []ValidationFinding {
+    timer := prometheus.NewTimer(validationDuration.WithLabelValues(platformLabel(platformType), "connectivity"))
+    defer timer.ObserveDuration()
+
+    authenticator, err := config.NewAuthenticator(auth)
+    if err != nil {
+        return []ValidationFinding{{
+            Field: "auth.credentials", Severity: SeverityError, Message: "invalid credentials",
+            category: "auth", err: err,
+        }}
+    }
+
+    checker, ok := authenticator.(config.ConnectivityChecker)
+    if !ok {
+        return []ValidationFinding{{
+            Field: "auth.credentials", Severity: SeverityInfo,
+            Message:  "connectivity check not supported for " + auth.Type + " authentication",
+            category: "connectivity",
+        }}
     }
 
+    ctx, cancel := context.WithTimeout(ctx, connectivityTimeout)
+    defer cancel()
+    if err := checker.CheckConnectivity(ctx); err != nil {
+        return []ValidationFinding{{
+            Field: "auth.credentials", Severity: SeverityError, Message: "credentials rejected by platform",
+            category: "connectivity", err: err,
+        }}
+    }
     return nil
 }
 
 // validateCollection performs data collection configuration validation
-func (v *IntegrationValidator) validateCollection(collection config.DataCollectionConfig, platformType string) error {
-    timer := prometheus.NewTimer(validationDuration.WithLabelValues(platformType, "collection"))
+func (v *IntegrationValidator) validateCollection(collection config.DataCollectionConfig, platformType string) []ValidationFinding {
+    timer := prometheus.NewTimer(validationDuration.WithLabelValues(platformLabel(platformType), "collection"))
     defer timer.ObserveDuration()
 
     // Validate collection mode
     if !isCollectionModeSupported(collection.Mode, platformType) {
-        return errors.NewError("E2001", "unsupported collection mode for platform", map[string]interface{}{
-            "mode": collection.Mode,
-            "platform_type": platformType,
-        })
+        return []ValidationFinding{{
+            Field: "collection.mode", Severity: SeverityError, Message: "unsupported collection mode for platform",
+            category: "collection",
+            err: errors.NewError("E2001", "unsupported collection mode for platform", map[string]interface{}{
+                "mode": collection.Mode,
+                "platform_type": platformType,
+            }),
+        }}
     }
 
     // Validate batch configuration
     if collection.Mode == "batch" || collection.Mode == "hybrid" {
-        if err := validateBatchConfig(collection); err != nil {
-            return err
-        }
+        return validateBatchConfig(collection)
     }
 
     return nil
@@ -237,12 +446,66 @@ func (v *IntegrationValidator) AddValidationRule(platformType string, rule strin
 
     v.rules[platformType] = rule
     
-    // Clear cache entries for this platform
+    // Clear cache entries for this platform, on every replica
+    v.AnnounceChange(context.Background(), platformType)
+
+    return nil
+}
+
+// InvalidateCache drops cached validation results for a platform, or for all
+// platforms when platformType is empty. Replicas call it when another replica
+// broadcasts an integration configuration change.
+func (v *IntegrationValidator) InvalidateCache(platformType string) {
+    if platformType == "" {
+        v.cache.Range(func(key, value interface{}) bool {
+            v.cache.Delete(key)
+            return true
+        })
+        return
+    }
     v.clearPlatformCache(platformType)
+}
 
+// SubscribeInvalidations drops cached results whenever any replica announces
+// a configuration change, until ctx is cancelled, and makes this validator's
+// own rule and schema changes announce themselves. Announcements missed while
+// the subscriber reconnects are lost, so cached results can be stale until
+// the next change; deployment validation does not rely on the cache alone.
+func (v *IntegrationValidator) SubscribeInvalidations(ctx context.Context, broadcaster ConfigBroadcaster) error {
+    if broadcaster == nil {
+        return errors.NewError("E2001", "config broadcaster is required", nil)
+    }
+    if err := broadcaster.Subscribe(ctx, ConfigChangeChannel, func(msg []byte) {
+        v.InvalidateCache(string(msg))
+    }); err != nil {
+        return err
+    }
+
+    v.mu.Lock()
+    v.broadcaster = broadcaster
+    v.mu.Unlock()
     return nil
 }
 
+// AnnounceChange invalidates cached results for a platform here and on every
+// subscribed replica. A failed announcement is logged; other replicas keep
+// their cached results until the next change reaches them.
+func (v *IntegrationValidator) AnnounceChange(ctx context.Context, platformType string) {
+    v.InvalidateCache(platformType)
+
+    v.mu.RLock()
+    broadcaster := v.broadcaster
+    v.mu.RUnlock()
+    if broadcaster == nil {
+        return
+    }
+    if err := broadcaster.Publish(ctx, ConfigChangeChannel, []byte(platformType)); err != nil {
+        logging.Error("Failed to announce integration configuration change", err,
+            logging.Field("platform_type", platformType),
+        )
+    }
+}
+
 // Helper functions
 
 func generateCacheKey(cfg *config.IntegrationConfig) string {
@@ -264,9 +527,14 @@ func isAuthTypeSupported(authType string, platformType string) bool {
     return true // Placeholder
 }
 
+// validateCredentials builds the authenticator for the auth type so that
+// credentials are checked by the same code that will use them
 func validateCredentials(credentials map[string]interface{}, authType string) error {
-    // Implementation of credential validation
-    return nil // Placeholder
+    _, err := config.NewAuthenticator(config.AuthenticationConfig{
+        Type:        authType,
+        Credentials: credentials,
+    })
+    return err
 }
 
 func isCollectionModeSupported(mode string, platformType string) bool {
@@ -274,9 +542,22 @@ func isCollectionModeSupported(mode string, platformType string) bool {
     return true // Placeholder
 }
 
-func validateBatchConfig(collection config.DataCollectionConfig) error {
-    // Implementation of batch configuration validation
-    return nil // Placeholder
+func validateBatchConfig(collection config.DataCollectionConfig) []ValidationFinding {
+    switch {
+    case collection.BatchSize == 0:
+        return []ValidationFinding{{
+            Field: "collection.batch_size", Severity: SeverityInfo, Message: "batch size not set, platform default applies",
+            category: "collection",
+        }}
+    case collection.BatchSize < minRecommendedBatchSize || collection.BatchSize > maxRecommendedBatchSize:
+        return []ValidationFinding{{
+            Field:    "collection.batch_size",
+            Severity: SeverityWarning,
+            Message:  fmt.Sprintf("batch size %d is outside the recommended range %d-%d", collection.BatchSize, minRecommendedBatchSize, maxRecommendedBatchSize),
+            category: "collection",
+        }}
+    }
+    return nil
 }
 
 func (v *IntegrationValidator) clearPlatformCache(platformType string) {
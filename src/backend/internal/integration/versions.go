@@ -0,0 +1,312 @@
+// Package integration provides blue-green versions of deployed integrations
+package integration
+
+import (
+    "context"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus" // v1.17.0
+
+    "../../pkg/common/errors"
+    "../../pkg/common/logging"
+    "../../pkg/integration/config"
+    "../../pkg/integration/platform"
+)
+
+// Blue-green version colours. An integration is deployed as blue; each
+// blue-green deployment stages the other colour and cuts over to it.
+const (
+    VersionBlue  = "blue"
+    VersionGreen = "green"
+)
+
+// IntegrationVersion is one colour of an integration that is not collecting:
+// a staged candidate or the version a cutover replaced
+type IntegrationVersion struct {
+    Version    string
+    Config     *config.IntegrationConfig
+    Platform   platform.Platform
+    Status     *platform.PlatformStatus
+    DeployedAt time.Time
+}
+
+// VersionInfo describes one version of an integration
+type VersionInfo struct {
+    Version    string                   `json:"version"`
+    Active     bool                     `json:"active"`
+    Status     *platform.PlatformStatus `json:"status,omitempty"`
+    DeployedAt time.Time                `json:"deployed_at"`
+}
+
+// VersionSet lists the versions of an integration and which one is active
+type VersionSet struct {
+    IntegrationID string        `json:"integration_id"`
+    Active        string        `json:"active"`
+    Versions      []VersionInfo `json:"versions"`
+}
+
+// otherVersion returns the colour a blue-green deployment stages next
+func otherVersion(version string) string {
+    if version == VersionBlue {
+        return VersionGreen
+    }
+    return VersionBlue
+}
+
+// ListVersions returns the versions of an integration
+func (m *IntegrationManager) ListVersions(ctx context.Context, integrationID string) (*VersionSet, error) {
+    ctx, span := m.tracer.Start(ctx, "ListVersions")
+    defer span.End()
+
+    m.mutex.RLock()
+    defer m.mutex.RUnlock()
+
+    integration, err := m.lookup(integrationID)
+    if err != nil {
+        return nil, err
+    }
+
+    set := &VersionSet{
+        IntegrationID: integration.ID,
+        Active:        integration.ActiveVersion,
+        Versions: []VersionInfo{{
+            Version:    integration.ActiveVersion,
+            Active:     true,
+            Status:     integration.Status,
+            DeployedAt: integration.DeployedAt,
+        }},
+    }
+    if standby := integration.standby; standby != nil {
+        set.Versions = append(set.Versions, VersionInfo{
+            Version:    standby.Version,
+            Status:     standby.Status,
+            DeployedAt: standby.DeployedAt,
+        })
+    }
+    return set, nil
+}
+
+// DeployVersion stages cfg as the inactive colour of an integration. The
+// candidate runs on its own platform instance and does not collect until
+// Cutover. Staging again replaces an earlier candidate, so a retried request
+// is safe.
+func (m *IntegrationManager) DeployVersion(ctx context.Context, integrationID string, version string, cfg *config.IntegrationConfig) error {
+    ctx, span := m.tracer.Start(ctx, "DeployVersion")
+    defer span.End()
+
+    timer := prometheus.NewTimer(integrationLatency.WithLabelValues("deploy_version", platformLabel(cfg.PlatformType)))
+    defer timer.ObserveDuration()
+
+    if err := validator.ValidateIntegration(ctx, cfg); err != nil {
+        return errors.WrapError(err, "integration validation failed", map[string]interface{}{
+            "platform_type": cfg.PlatformType,
+        })
+    }
+
+    m.mutex.Lock()
+    defer m.mutex.Unlock()
+
+    integration, err := m.lookup(integrationID)
+    if err != nil {
+        return err
+    }
+    if version != otherVersion(integration.ActiveVersion) {
+        return errors.NewError("E2001", "only the inactive version can be deployed", map[string]interface{}{
+            "integration_id": integrationID,
+            "version":        version,
+            "active":         integration.ActiveVersion,
+        })
+    }
+    if cfg.PlatformType != integration.Config.PlatformType {
+        return errors.NewError("E2001", "a version cannot change the platform type", map[string]interface{}{
+            "integration_id": integrationID,
+            "platform_type":  cfg.PlatformType,
+        })
+    }
+
+    // Staged candidates never collect, and a replaced version was stopped by
+    // its cutover, so the old standby is simply dropped
+    integration.standby = nil
+
+    candidate, err := m.platformRegistry.NewPlatform(cfg.PlatformType)
+    if err != nil {
+        return err
+    }
+    if err := candidate.Initialize(ctx, cfg); err != nil {
+        return errors.WrapError(err, "platform initialization failed", map[string]interface{}{
+            "integration_id": integrationID,
+            "version":        version,
+        })
+    }
+    status, err := candidate.GetStatus(ctx)
+    if err != nil {
+        return errors.WrapError(err, "failed to get platform status", nil)
+    }
+
+    integration.standby = &IntegrationVersion{
+        Version:    version,
+        Config:     cfg,
+        Platform:   candidate,
+        Status:     status,
+        DeployedAt: time.Now().UTC(),
+    }
+
+    logging.Info("Integration version staged",
+        "integration_id", integrationID,
+        "version", version,
+    )
+    return nil
+}
+
+// GetVersionStatus retrieves the current status of one version of an integration
+func (m *IntegrationManager) GetVersionStatus(ctx context.Context, integrationID string, version string) (*platform.PlatformStatus, error) {
+    if version == "" {
+        return m.GetIntegrationStatus(ctx, integrationID)
+    }
+
+    m.mutex.RLock()
+    integration, err := m.lookup(integrationID)
+    if err != nil {
+        m.mutex.RUnlock()
+        return nil, err
+    }
+    active := integration.ActiveVersion
+    standby := integration.standby
+    m.mutex.RUnlock()
+
+    if version == active {
+        return m.GetIntegrationStatus(ctx, integrationID)
+    }
+    if standby == nil || standby.Version != version {
+        return nil, errors.NewError("E2001", "integration version not found", map[string]interface{}{
+            "integration_id": integrationID,
+            "version":        version,
+        })
+    }
+
+    status, err := standby.Platform.GetStatus(ctx)
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to get platform status", nil)
+    }
+
+    m.mutex.Lock()
+    standby.Status = status
+    m.mutex.Unlock()
+    return status, nil
+}
+
+// Cutover moves collection to the staged version: it starts the candidate,
+// stops the active version and keeps that as the standby until it is
+// removed. If the candidate fails to start the active version keeps
+// collecting.
+func (m *IntegrationManager) Cutover(ctx context.Context, integrationID string, to string) error {
+    ctx, span := m.tracer.Start(ctx, "Cutover")
+    defer span.End()
+
+    m.mutex.Lock()
+    defer m.mutex.Unlock()
+
+    integration, err := m.lookup(integrationID)
+    if err != nil {
+        return err
+    }
+
+    timer := prometheus.NewTimer(integrationLatency.WithLabelValues("cutover", platformLabel(integration.Config.PlatformType)))
+    defer timer.ObserveDuration()
+
+    candidate := integration.standby
+    if candidate == nil || candidate.Version != to || to == integration.ActiveVersion {
+        return errors.NewError("E2001", "no staged version to cut over to", map[string]interface{}{
+            "integration_id": integrationID,
+            "version":        to,
+            "active":         integration.ActiveVersion,
+        })
+    }
+
+    if err := candidate.Platform.StartCollection(ctx); err != nil {
+        return errors.WrapError(err, "failed to start data collection", map[string]interface{}{
+            "integration_id": integrationID,
+            "version":        to,
+        })
+    }
+
+    previous := &IntegrationVersion{
+        Version:    integration.ActiveVersion,
+        Config:     integration.Config,
+        Platform:   integration.Platform,
+        Status:     integration.Status,
+        DeployedAt: integration.DeployedAt,
+    }
+
+    // The candidate already collects, so a failure to stop the old version
+    // only duplicates events, which downstream deduplication absorbs
+    if err := previous.Platform.StopCollection(ctx); err != nil {
+        logging.Error("Failed to stop replaced integration version", err,
+            "integration_id", integrationID,
+            "version", previous.Version,
+        )
+    }
+
+    integration.Config = candidate.Config
+    integration.Platform = candidate.Platform
+    integration.Status = candidate.Status
+    integration.DeployedAt = candidate.DeployedAt
+    integration.LastUpdated = time.Now().UTC()
+    integration.ActiveVersion = candidate.Version
+    integration.standby = previous
+    m.schedulePolling(integration)
+    m.probeValidator.AnnounceChange(ctx, integration.Config.PlatformType)
+
+    logging.Info("Integration cut over",
+        "integration_id", integrationID,
+        "from", previous.Version,
+        "to", candidate.Version,
+    )
+    return nil
+}
+
+// RemoveVersion discards the inactive version of an integration. The active
+// version is removed only by stopping the integration.
+func (m *IntegrationManager) RemoveVersion(ctx context.Context, integrationID string, version string) error {
+    ctx, span := m.tracer.Start(ctx, "RemoveVersion")
+    defer span.End()
+
+    m.mutex.Lock()
+    defer m.mutex.Unlock()
+
+    integration, err := m.lookup(integrationID)
+    if err != nil {
+        return err
+    }
+    if version == integration.ActiveVersion {
+        return errors.NewError("E2001", "the active version cannot be removed", map[string]interface{}{
+            "integration_id": integrationID,
+            "version":        version,
+        })
+    }
+    if integration.standby == nil || integration.standby.Version != version {
+        return errors.NewError("E2001", "integration version not found", map[string]interface{}{
+            "integration_id": integrationID,
+            "version":        version,
+        })
+    }
+
+    integration.standby = nil
+
+    logging.Info("Integration version removed",
+        "integration_id", integrationID,
+        "version", version,
+    )
+    return nil
+}
+
+// lookup returns an active integration. Callers hold the manager mutex.
+func (m *IntegrationManager) lookup(integrationID string) (*Integration, error) {
+    integration, exists := m.activeIntegrations[integrationID]
+    if !exists {
+        return nil, errors.NewError("E2001", "integration not found", map[string]interface{}{
+            "integration_id": integrationID,
+        })
+    }
+    return integration, nil
+}
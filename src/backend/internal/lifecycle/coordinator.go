@@ -0,0 +1,296 @@
+// Package lifecycle coordinates ordered shutdown of pipeline stages across services
+package lifecycle
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+)
+
+// Stage identifies a shutdown phase. Stages always stop in the order listed so
+// that no component is torn down while an upstream stage can still feed it.
+type Stage int
+
+const (
+    // StageIntake stops consumers and collectors from accepting new events
+    StageIntake Stage = iota
+
+    // StageProcessing drains events already in flight
+    StageProcessing
+
+    // StageProducer flushes and closes outbound producers
+    StageProducer
+
+    // StageStorage closes storage clients and flushes telemetry
+    StageStorage
+)
+
+// shutdownOrder is the fixed order in which stages are stopped
+var shutdownOrder = []Stage{StageIntake, StageProcessing, StageProducer, StageStorage}
+
+// Default per-stage shutdown timeouts
+var defaultStageTimeouts = map[Stage]time.Duration{
+    StageIntake:     10 * time.Second,
+    StageProcessing: 30 * time.Second,
+    StageProducer:   15 * time.Second,
+    StageStorage:    10 * time.Second,
+}
+
+// String returns the stage name used in logs and status reports
+func (s Stage) String() string {
+    switch s {
+    case StageIntake:
+        return "intake"
+    case StageProcessing:
+        return "processing"
+    case StageProducer:
+        return "producer"
+    case StageStorage:
+        return "storage"
+    default:
+        return "unknown"
+    }
+}
+
+// StopFunc stops a component, returning early if the context is cancelled
+type StopFunc func(ctx context.Context) error
+
+// StopperFunc adapts a context-unaware Stop or Close method to a StopFunc
+func StopperFunc(stop func() error) StopFunc {
+    return func(ctx context.Context) error {
+        return stop()
+    }
+}
+
+// ComponentStatus reports the shutdown outcome of a single component
+type ComponentStatus struct {
+    Stage     string        `json:"stage"`
+    Component string        `json:"component"`
+    State     string        `json:"state"`
+    Duration  time.Duration `json:"duration"`
+    Error     string        `json:"error,omitempty"`
+}
+
+// Component shutdown states
+const (
+    StatePending  = "pending"
+    StateStopping = "stopping"
+    StateStopped  = "stopped"
+    StateFailed   = "failed"
+    StateTimedOut = "timed_out"
+)
+
+// component is a registered shutdown participant
+type component struct {
+    stage  Stage
+    name   string
+    stop   StopFunc
+    status ComponentStatus
+}
+
+// Coordinator stops registered components stage by stage with per-stage
+// timeouts. A failing or slow stage is logged and does not prevent later
+// stages from running, so producers are still flushed and storage closed.
+// Stage deadlines are bounded by the context passed to Shutdown, so callers
+// size it with Timeout to leave every stage its full budget.
+type Coordinator struct {
+    service    string
+    timeouts   map[Stage]time.Duration
+    components []*component
+    once       sync.Once
+    mu         sync.RWMutex
+}
+
+// NewCoordinator creates a shutdown coordinator for the named service
+func NewCoordinator(service string) *Coordinator {
+    timeouts := make(map[Stage]time.Duration, len(defaultStageTimeouts))
+    for stage, timeout := range defaultStageTimeouts {
+        timeouts[stage] = timeout
+    }
+
+    return &Coordinator{
+        service:  service,
+        timeouts: timeouts,
+    }
+}
+
+// SetStageTimeout overrides the time a stage is given to stop all of its components
+func (c *Coordinator) SetStageTimeout(stage Stage, timeout time.Duration) {
+    if timeout <= 0 {
+        return
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.timeouts[stage] = timeout
+}
+
+// Timeout returns the sum of the stage timeouts: the longest an ordered
+// shutdown can take when every stage uses its full budget
+func (c *Coordinator) Timeout() time.Duration {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+
+    var total time.Duration
+    for _, stage := range shutdownOrder {
+        total += c.timeouts[stage]
+    }
+    return total
+}
+
+// Register adds a component to a stage. Components within a stage stop in
+// registration order.
+func (c *Coordinator) Register(stage Stage, name string, stop StopFunc) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    c.components = append(c.components, &component{
+        stage: stage,
+        name:  name,
+        stop:  stop,
+        status: ComponentStatus{
+            Stage:     stage.String(),
+            Component: name,
+            State:     StatePending,
+        },
+    })
+}
+
+// Shutdown stops every stage in order. It runs once; later calls return nil.
+// The returned error summarizes the components that failed or timed out.
+func (c *Coordinator) Shutdown(ctx context.Context) error {
+    var err error
+    c.once.Do(func() {
+        err = c.shutdown(ctx)
+    })
+    return err
+}
+
+// Status returns the shutdown status of every registered component
+func (c *Coordinator) Status() []ComponentStatus {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+
+    statuses := make([]ComponentStatus, len(c.components))
+    for i, comp := range c.components {
+        statuses[i] = comp.status
+    }
+    return statuses
+}
+
+// shutdown performs the ordered stop of all stages
+func (c *Coordinator) shutdown(ctx context.Context) error {
+    start := time.Now()
+    logging.Info("Starting ordered shutdown", logging.Field("service", c.service))
+
+    var failed []string
+    for _, stage := range shutdownOrder {
+        failed = append(failed, c.stopStage(ctx, stage)...)
+    }
+
+    logging.Info("Ordered shutdown finished",
+        logging.Field("service", c.service),
+        logging.Field("duration", time.Since(start).String()),
+        logging.Field("failed_components", len(failed)),
+    )
+
+    if len(failed) > 0 {
+        return errors.NewError("E4001", "shutdown did not complete cleanly", map[string]interface{}{
+            "service":           c.service,
+            "failed_components": failed,
+        })
+    }
+    return nil
+}
+
+// stopStage stops the stage's components sequentially within the stage timeout
+// and returns the names of components that did not stop cleanly
+func (c *Coordinator) stopStage(ctx context.Context, stage Stage) []string {
+    c.mu.RLock()
+    timeout := c.timeouts[stage]
+    var members []*component
+    for _, comp := range c.components {
+        if comp.stage == stage {
+            members = append(members, comp)
+        }
+    }
+    c.mu.RUnlock()
+
+    if len(members) == 0 {
+        return nil
+    }
+
+    stageCtx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+
+    logging.Info("Stopping shutdown stage",
+        logging.Field("service", c.service),
+        logging.Field("stage", stage.String()),
+        logging.Field("components", len(members)),
+        logging.Field("timeout", timeout.String()),
+    )
+
+    var failed []string
+    for _, comp := range members {
+        if !c.stopComponent(stageCtx, comp) {
+            failed = append(failed, stage.String()+"/"+comp.name)
+        }
+    }
+    return failed
+}
+
+// stopComponent runs a component's stop function, abandoning it if the stage
+// deadline passes first. It reports whether the component stopped cleanly.
+func (c *Coordinator) stopComponent(ctx context.Context, comp *component) bool {
+    c.setState(comp, StateStopping, 0, nil)
+    start := time.Now()
+
+    done := make(chan error, 1)
+    go func() {
+        done <- comp.stop(ctx)
+    }()
+
+    select {
+    case err := <-done:
+        if err != nil {
+            c.setState(comp, StateFailed, time.Since(start), err)
+            logging.Error("Component failed to stop", err,
+                logging.Field("service", c.service),
+                logging.Field("stage", comp.status.Stage),
+                logging.Field("component", comp.name),
+            )
+            return false
+        }
+        c.setState(comp, StateStopped, time.Since(start), nil)
+        logging.Info("Component stopped",
+            logging.Field("service", c.service),
+            logging.Field("stage", comp.status.Stage),
+            logging.Field("component", comp.name),
+            logging.Field("duration", time.Since(start).String()),
+        )
+        return true
+    case <-ctx.Done():
+        c.setState(comp, StateTimedOut, time.Since(start), ctx.Err())
+        logging.Error("Component stop timed out", ctx.Err(),
+            logging.Field("service", c.service),
+            logging.Field("stage", comp.status.Stage),
+            logging.Field("component", comp.name),
+        )
+        return false
+    }
+}
+
+// setState records a component's shutdown progress
+func (c *Coordinator) setState(comp *component, state string, duration time.Duration, err error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    comp.status.State = state
+    comp.status.Duration = duration
+    if err != nil {
+        comp.status.Error = err.Error()
+    }
+}
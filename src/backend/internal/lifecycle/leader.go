@@ -0,0 +1,301 @@
+// Package lifecycle provides lease-based leader election for singleton components
+package lifecycle
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+)
+
+// Default lease timings
+const (
+    defaultLeaseDuration = 15 * time.Second
+    defaultRenewInterval = 5 * time.Second
+    defaultRetryInterval = 2 * time.Second
+)
+
+var (
+    leaderStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "blackpoint_leader_is_leader",
+        Help: "Whether this replica currently holds the leader lease (1) or is a standby (0)",
+    }, []string{"election"})
+
+    leaderTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "blackpoint_leader_transitions_total",
+        Help: "Total number of leadership acquisitions and losses",
+    }, []string{"election", "transition"})
+)
+
+func init() {
+    prometheus.MustRegister(leaderStatus)
+    prometheus.MustRegister(leaderTransitions)
+}
+
+// LeaseStore holds expiring leases; *storage.RedisClient satisfies it
+type LeaseStore interface {
+    AcquireLease(ctx context.Context, key, holder string, ttl time.Duration) (bool, error)
+    RenewLease(ctx context.Context, key, holder string, ttl time.Duration) (bool, error)
+    ReleaseLease(ctx context.Context, key, holder string) error
+}
+
+// LeaderConfig configures a leader election
+type LeaderConfig struct {
+    // Election names the lease; replicas of one component share it
+    Election string
+
+    // Identity uniquely identifies this replica, e.g. the pod name
+    Identity string
+
+    // LeaseDuration is how long a lease survives without renewal; it bounds failover time
+    LeaseDuration time.Duration
+
+    // RenewInterval is how often the leader renews; keep it well under LeaseDuration
+    RenewInterval time.Duration
+
+    // RetryInterval is how often standbys try to take the lease
+    RetryInterval time.Duration
+
+    // OnElected runs when this replica becomes leader, e.g. to restore
+    // persisted window state. Its context is cancelled on demotion.
+    OnElected func(ctx context.Context) error
+
+    // OnDemoted runs when leadership is lost or released
+    OnDemoted func()
+}
+
+// LeaderElector keeps one active replica of a singleton component. Standbys
+// stay fully initialized and only begin processing once they hold the lease.
+type LeaderElector struct {
+    store    LeaseStore
+    config   LeaderConfig
+    leading  bool
+    cancel   context.CancelFunc
+    done     chan struct{}
+    mu       sync.RWMutex
+}
+
+// NewLeaderElector creates an elector with defaults applied
+func NewLeaderElector(store LeaseStore, config LeaderConfig) (*LeaderElector, error) {
+    if store == nil {
+        return nil, errors.NewError("E2001", "lease store is required", nil)
+    }
+    if config.Election == "" || config.Identity == "" {
+        return nil, errors.NewError("E2001", "election name and identity are required", nil)
+    }
+    if config.LeaseDuration <= 0 {
+        config.LeaseDuration = defaultLeaseDuration
+    }
+    if config.RenewInterval <= 0 {
+        config.RenewInterval = defaultRenewInterval
+    }
+    if config.RetryInterval <= 0 {
+        config.RetryInterval = defaultRetryInterval
+    }
+    if config.RenewInterval >= config.LeaseDuration {
+        return nil, errors.NewError("E2001", "renew interval must be shorter than lease duration", map[string]interface{}{
+            "renew_interval": config.RenewInterval,
+            "lease_duration": config.LeaseDuration,
+        })
+    }
+
+    leaderStatus.WithLabelValues(config.Election).Set(0)
+
+    return &LeaderElector{
+        store:  store,
+        config: config,
+    }, nil
+}
+
+// IsLeader reports whether this replica currently holds the lease
+func (le *LeaderElector) IsLeader() bool {
+    le.mu.RLock()
+    defer le.mu.RUnlock()
+    return le.leading
+}
+
+// Start runs the election in the background until Stop is called
+func (le *LeaderElector) Start(ctx context.Context) {
+    ctx, cancel := context.WithCancel(ctx)
+
+    le.mu.Lock()
+    le.cancel = cancel
+    le.done = make(chan struct{})
+    le.mu.Unlock()
+
+    go le.run(ctx)
+}
+
+// Stop ends the election and releases the lease so a standby takes over
+// without waiting for it to expire. It can be registered with a Coordinator.
+func (le *LeaderElector) Stop(ctx context.Context) error {
+    le.mu.RLock()
+    cancel, done := le.cancel, le.done
+    le.mu.RUnlock()
+    if cancel == nil {
+        return nil
+    }
+
+    cancel()
+    select {
+    case <-done:
+    case <-ctx.Done():
+        return errors.WrapError(ctx.Err(), "timed out stopping leader election", map[string]interface{}{
+            "election": le.config.Election,
+        })
+    }
+
+    return le.store.ReleaseLease(ctx, le.leaseKey(), le.config.Identity)
+}
+
+// run alternates between contending for the lease and renewing it
+func (le *LeaderElector) run(ctx context.Context) {
+    defer close(le.done)
+
+    var leaderCancel context.CancelFunc
+    defer func() {
+        if leaderCancel != nil {
+            le.demote(leaderCancel, "stopped")
+        }
+    }()
+
+    for {
+        interval := le.config.RetryInterval
+
+        if leaderCancel == nil {
+            acquired, err := le.store.AcquireLease(ctx, le.leaseKey(), le.config.Identity, le.config.LeaseDuration)
+            if err != nil && ctx.Err() == nil {
+                logging.Error("Failed to contend for leadership", err,
+                    logging.Field("election", le.config.Election),
+                )
+            }
+            if acquired {
+                leaderCancel = le.promote(ctx)
+                interval = le.config.RenewInterval
+            }
+        } else {
+            // Any doubt about the lease demotes immediately: a brief gap is
+            // preferable to two active replicas double-alerting
+            renewed, err := le.store.RenewLease(ctx, le.leaseKey(), le.config.Identity, le.config.LeaseDuration)
+            if err != nil || !renewed {
+                if err != nil && ctx.Err() == nil {
+                    logging.Error("Failed to renew leader lease", err,
+                        logging.Field("election", le.config.Election),
+                    )
+                }
+                le.demote(leaderCancel, "lost")
+                leaderCancel = nil
+            } else {
+                interval = le.config.RenewInterval
+            }
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-time.After(interval):
+        }
+    }
+}
+
+// promote marks this replica as leader and runs the election hook
+func (le *LeaderElector) promote(ctx context.Context) context.CancelFunc {
+    leaderCtx, cancel := context.WithCancel(ctx)
+
+    le.mu.Lock()
+    le.leading = true
+    le.mu.Unlock()
+
+    leaderStatus.WithLabelValues(le.config.Election).Set(1)
+    leaderTransitions.WithLabelValues(le.config.Election, "acquired").Inc()
+    logging.Info("Acquired leadership",
+        logging.Field("election", le.config.Election),
+        logging.Field("identity", le.config.Identity),
+    )
+
+    if le.config.OnElected != nil {
+        if err := le.config.OnElected(leaderCtx); err != nil {
+            logging.Error("Leader election hook failed", err,
+                logging.Field("election", le.config.Election),
+            )
+        }
+    }
+    return cancel
+}
+
+// demote marks this replica as standby and runs the demotion hook
+func (le *LeaderElector) demote(cancel context.CancelFunc, reason string) {
+    cancel()
+
+    le.mu.Lock()
+    le.leading = false
+    le.mu.Unlock()
+
+    leaderStatus.WithLabelValues(le.config.Election).Set(0)
+    leaderTransitions.WithLabelValues(le.config.Election, reason).Inc()
+    logging.Info("Released leadership",
+        logging.Field("election", le.config.Election),
+        logging.Field("identity", le.config.Identity),
+        logging.Field("reason", reason),
+    )
+
+    if le.config.OnDemoted != nil {
+        le.config.OnDemoted()
+    }
+}
+
+// leaseKey names the lease shared by all replicas of the election
+func (le *LeaderElector) leaseKey() string {
+    return "leader:" + le.config.Election
+}
+
+// LeaderScope binds work to leadership. Its Elected and Demoted methods are
+// installed as a LeaderConfig's OnElected and OnDemoted hooks; work started
+// under a context from Context is cancelled as soon as leadership is lost,
+// so a demoted replica never finishes work the new leader is also doing.
+type LeaderScope struct {
+    leaderCtx context.Context
+    mu        sync.RWMutex
+}
+
+// Elected records the leadership context; it is the OnElected hook
+func (s *LeaderScope) Elected(ctx context.Context) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.leaderCtx = ctx
+    return nil
+}
+
+// Demoted forgets the leadership context, which the elector has already
+// cancelled; it is the OnDemoted hook
+func (s *LeaderScope) Demoted() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.leaderCtx = nil
+}
+
+// Context returns a context derived from ctx that is also cancelled when
+// leadership is lost. It reports false, with a cancelled context, when this
+// replica is not the leader. The returned cancel function must be called once
+// the work is done.
+func (s *LeaderScope) Context(ctx context.Context) (context.Context, context.CancelFunc, bool) {
+    s.mu.RLock()
+    leaderCtx := s.leaderCtx
+    s.mu.RUnlock()
+
+    workCtx, cancel := context.WithCancel(ctx)
+    if leaderCtx == nil || leaderCtx.Err() != nil {
+        cancel()
+        return workCtx, cancel, false
+    }
+
+    stop := context.AfterFunc(leaderCtx, cancel)
+    return workCtx, func() {
+        stop()
+        cancel()
+    }, true
+}
@@ -0,0 +1,151 @@
+// Package lifecycle provides a worker pool that completes in-flight work on shutdown
+package lifecycle
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// Default interval at which idle workers recheck the standby signal
+const defaultStandbyInterval = time.Second
+
+// TaskFunc performs one unit of work, such as fetching and analyzing a batch
+type TaskFunc func(ctx context.Context)
+
+// WorkerPoolConfig configures a WorkerPool
+type WorkerPoolConfig struct {
+    // Workers is the number of tasks run concurrently
+    Workers int
+
+    // Standby reports whether workers should hold off taking work, such as
+    // while another replica holds leadership. It is polled every
+    // StandbyInterval; nil means always active.
+    Standby         func() bool
+    StandbyInterval time.Duration
+}
+
+// WorkerPool runs a task repeatedly on a fixed number of workers. On
+// shutdown, Stop ends intake at once and Wait lets tasks already running
+// finish, so analysis is neither cut off nor waited on longer than needed.
+type WorkerPool struct {
+    config WorkerPoolConfig
+    task   TaskFunc
+
+    // taskCtx is passed to tasks and cancelled only when Wait gives up
+    taskCtx    context.Context
+    cancelTask context.CancelFunc
+
+    stopping chan struct{}
+    stopOnce sync.Once
+    stopped  bool
+    inFlight sync.WaitGroup
+    workers  sync.WaitGroup
+    mu       sync.Mutex
+}
+
+// NewWorkerPool creates a pool running task on config.Workers workers
+func NewWorkerPool(config WorkerPoolConfig, task TaskFunc) (*WorkerPool, error) {
+    if config.Workers <= 0 || task == nil {
+        return nil, errors.NewError("E2001", "worker pool requires a task and at least one worker", map[string]interface{}{
+            "workers": config.Workers,
+        })
+    }
+    if config.StandbyInterval <= 0 {
+        config.StandbyInterval = defaultStandbyInterval
+    }
+
+    return &WorkerPool{
+        config:   config,
+        task:     task,
+        stopping: make(chan struct{}),
+    }, nil
+}
+
+// Start launches the workers. Tasks receive a context derived from ctx.
+func (p *WorkerPool) Start(ctx context.Context) {
+    p.taskCtx, p.cancelTask = context.WithCancel(ctx)
+    for i := 0; i < p.config.Workers; i++ {
+        p.workers.Add(1)
+        go p.run()
+    }
+}
+
+// Stop ends intake: no worker starts a new task once Stop returns. Tasks
+// already running are not interrupted.
+func (p *WorkerPool) Stop() {
+    p.stopOnce.Do(func() {
+        p.mu.Lock()
+        p.stopped = true
+        p.mu.Unlock()
+        close(p.stopping)
+    })
+}
+
+// Wait stops intake and waits for in-flight tasks to finish. If ctx expires
+// first, the tasks' context is cancelled and a timeout error is returned.
+func (p *WorkerPool) Wait(ctx context.Context) error {
+    p.Stop()
+
+    done := make(chan struct{})
+    go func() {
+        p.inFlight.Wait()
+        p.workers.Wait()
+        close(done)
+    }()
+
+    select {
+    case <-done:
+        p.cancel()
+        return nil
+    case <-ctx.Done():
+        p.cancel()
+        return errors.WrapError(ctx.Err(), "in-flight tasks did not finish before the shutdown deadline", map[string]interface{}{
+            "workers": p.config.Workers,
+        })
+    }
+}
+
+// cancel releases the task context if the pool was started
+func (p *WorkerPool) cancel() {
+    if p.cancelTask != nil {
+        p.cancelTask()
+    }
+}
+
+// run takes tasks until the pool stops
+func (p *WorkerPool) run() {
+    defer p.workers.Done()
+
+    for {
+        if p.config.Standby != nil && p.config.Standby() {
+            select {
+            case <-p.stopping:
+                return
+            case <-time.After(p.config.StandbyInterval):
+            }
+            continue
+        }
+
+        if !p.begin() {
+            return
+        }
+        p.task(p.taskCtx)
+        p.inFlight.Done()
+    }
+}
+
+// begin registers a task as in flight unless the pool has stopped. Holding
+// the lock orders every Add before Wait's wait on the in-flight group.
+func (p *WorkerPool) begin() bool {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    if p.stopped {
+        return false
+    }
+    p.inFlight.Add(1)
+    return true
+}
@@ -0,0 +1,109 @@
+// Package metrics provides label cardinality limits for labeled Prometheus metrics
+package metrics
+
+import (
+    "sync"
+
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+    // OverflowLabelValue replaces label values beyond a guard's limit
+    OverflowLabelValue = "other"
+
+    // DefaultMaxLabelValues is the per-label limit used when none is configured
+    DefaultMaxLabelValues = 100
+)
+
+var (
+    // labelValuesDropped counts label values bucketed into OverflowLabelValue
+    labelValuesDropped = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Name: "blackpoint_metrics_label_values_dropped_total",
+            Help: "Total number of metric observations whose label value was replaced by the overflow bucket",
+        },
+        []string{"metric", "label"},
+    )
+
+    // guards holds the shared guard of each metric
+    guards   = make(map[string]*CardinalityGuard)
+    guardsMu sync.Mutex
+)
+
+func init() {
+    prometheus.MustRegister(labelValuesDropped)
+}
+
+// CardinalityGuard caps the number of distinct values each label of a metric
+// may take. The first values seen are kept; later values are reported as
+// OverflowLabelValue so a source of random IDs cannot create unbounded series.
+type CardinalityGuard struct {
+    metric    string
+    maxValues int
+    seen      map[string]map[string]struct{}
+    mu        sync.Mutex
+}
+
+// NewCardinalityGuard creates a guard allowing maxValues distinct values per label
+func NewCardinalityGuard(metric string, maxValues int) *CardinalityGuard {
+    if maxValues <= 0 {
+        maxValues = DefaultMaxLabelValues
+    }
+
+    return &CardinalityGuard{
+        metric:    metric,
+        maxValues: maxValues,
+        seen:      make(map[string]map[string]struct{}),
+    }
+}
+
+// Guard returns the shared guard for a metric, creating it with the default
+// limit on first use so packages can guard labels without coordinating setup
+func Guard(metric string) *CardinalityGuard {
+    guardsMu.Lock()
+    defer guardsMu.Unlock()
+
+    guard, ok := guards[metric]
+    if !ok {
+        guard = NewCardinalityGuard(metric, DefaultMaxLabelValues)
+        guards[metric] = guard
+    }
+    return guard
+}
+
+// SetLabelValueLimit sets the per-label limit of a metric's shared guard.
+// Values already admitted stay admitted if the limit is lowered.
+func SetLabelValueLimit(metric string, maxValues int) {
+    guard := Guard(metric)
+
+    guard.mu.Lock()
+    defer guard.mu.Unlock()
+    if maxValues <= 0 {
+        maxValues = DefaultMaxLabelValues
+    }
+    guard.maxValues = maxValues
+}
+
+// Value returns the value to use for a label, substituting
+// OverflowLabelValue once the label's limit is reached
+func (g *CardinalityGuard) Value(label, value string) string {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+
+    values, ok := g.seen[label]
+    if !ok {
+        values = make(map[string]struct{})
+        g.seen[label] = values
+    }
+
+    if _, admitted := values[value]; admitted {
+        return value
+    }
+    if len(values) >= g.maxValues {
+        labelValuesDropped.WithLabelValues(g.metric, label).Inc()
+        return OverflowLabelValue
+    }
+
+    values[value] = struct{}{}
+    return value
+}
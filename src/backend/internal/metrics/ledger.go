@@ -0,0 +1,440 @@
+// Package metrics provides an event-loss ledger accounting for event disposition across the pipeline
+package metrics
+
+import (
+    "context"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/blackpoint/pkg/common/logging"
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+// Pipeline stages tracked by the ledger
+const (
+    StageIngest    = "ingest"
+    StageNormalize = "normalize"
+    StageAnalyze   = "analyze"
+)
+
+// Reasons an event leaves a stage without being produced
+const (
+    LossValidationReject = "validation_reject"
+    LossDuplicate        = "duplicate"
+    LossDeadLetter       = "dead_letter"
+    LossBackpressureShed = "backpressure_shed"
+    LossTransformFailure = "transform_failure"
+    LossPublishFailure   = "publish_failure"
+    LossAnalysisFailure  = "analysis_failure"
+    LossCancelled        = "cancelled"
+)
+
+// lossReasonOther replaces reasons that are not configured
+const lossReasonOther = "other"
+
+const (
+    // Default interval between logged reconciliation reports
+    defaultLedgerReportInterval = 5 * time.Minute
+
+    // Default window in which a redelivered event is recognized
+    defaultLedgerRetryWindow = time.Hour
+)
+
+// dispositionAlerted keys alerted events apart from their stage disposition
+const dispositionAlerted = "alerted"
+
+var (
+    ledgerEvents = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Name: "blackpoint_pipeline_events_total",
+            Help: "Total number of events by pipeline stage and disposition (ingested, produced, alerted)",
+        },
+        []string{"stage", "disposition"},
+    )
+
+    ledgerLosses = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Name: "blackpoint_pipeline_events_lost_total",
+            Help: "Total number of events dropped by pipeline stage and loss reason",
+        },
+        []string{"stage", "reason"},
+    )
+
+    // defaultLedger accounts for events handled by this process
+    defaultLedger   = NewEventLedger(LedgerConfig{})
+    defaultLedgerMu sync.RWMutex
+)
+
+func init() {
+    prometheus.MustRegister(ledgerEvents)
+    prometheus.MustRegister(ledgerLosses)
+}
+
+// LedgerConfig configures event-loss accounting
+type LedgerConfig struct {
+    // ExtraReasons adds loss reasons beyond the built-in ones; others are recorded as "other"
+    ExtraReasons []string
+
+    // ReportInterval is how often StartReporting logs a reconciliation report
+    ReportInterval time.Duration
+
+    // RetryWindow is how long event IDs passed to the Record*Event methods are
+    // remembered, so events redelivered within it are not counted twice
+    RetryWindow time.Duration
+}
+
+// StageAccount is the disposition of events that entered one stage
+type StageAccount struct {
+    Ingested  uint64            `json:"ingested"`
+    Produced  uint64            `json:"produced"`
+    Lost      map[string]uint64 `json:"lost"`
+    LostTotal uint64            `json:"lost_total"`
+
+    // Unaccounted is ingested minus produced and lost: events still in flight,
+    // or lost at a drop point that is not instrumented
+    Unaccounted int64 `json:"unaccounted"`
+}
+
+// LedgerReport reconciles ingested, produced and alerted counts
+type LedgerReport struct {
+    Since       time.Time               `json:"since"`
+    GeneratedAt time.Time               `json:"generated_at"`
+    Stages      map[string]StageAccount `json:"stages"`
+    Alerted     uint64                  `json:"alerted"`
+}
+
+// EventLedger counts events entering and leaving each pipeline stage. Counts
+// are per process; the Prometheus series reconcile the pipeline as a whole.
+type EventLedger struct {
+    config  LedgerConfig
+    reasons map[string]bool
+    stages  map[string]*StageAccount
+    events  map[string]*eventRecord
+    alerted uint64
+    since   time.Time
+    mu      sync.Mutex
+}
+
+// eventRecord remembers what the ledger counted for one event in one stage
+type eventRecord struct {
+    settled bool
+    seen    time.Time
+}
+
+// NewEventLedger creates an empty ledger
+func NewEventLedger(config LedgerConfig) *EventLedger {
+    if config.ReportInterval <= 0 {
+        config.ReportInterval = defaultLedgerReportInterval
+    }
+    if config.RetryWindow <= 0 {
+        config.RetryWindow = defaultLedgerRetryWindow
+    }
+
+    reasons := map[string]bool{
+        LossValidationReject: true,
+        LossDuplicate:        true,
+        LossDeadLetter:       true,
+        LossBackpressureShed: true,
+        LossTransformFailure: true,
+        LossPublishFailure:   true,
+        LossAnalysisFailure:  true,
+        LossCancelled:        true,
+    }
+    for _, reason := range config.ExtraReasons {
+        reasons[reason] = true
+    }
+
+    return &EventLedger{
+        config:  config,
+        reasons: reasons,
+        stages:  make(map[string]*StageAccount),
+        events:  make(map[string]*eventRecord),
+        since:   time.Now().UTC(),
+    }
+}
+
+// ConfigureLedger replaces the process-wide ledger; counts recorded so far are reset
+func ConfigureLedger(config LedgerConfig) {
+    ledger := NewEventLedger(config)
+
+    defaultLedgerMu.Lock()
+    defer defaultLedgerMu.Unlock()
+    defaultLedger = ledger
+}
+
+// Ledger returns the process-wide ledger
+func Ledger() *EventLedger {
+    defaultLedgerMu.RLock()
+    defer defaultLedgerMu.RUnlock()
+    return defaultLedger
+}
+
+// RecordIngested counts events entering a stage of the process-wide ledger
+func RecordIngested(stage string, count int) {
+    Ledger().RecordIngested(stage, count)
+}
+
+// RecordProduced counts events a stage of the process-wide ledger passed on
+func RecordProduced(stage string, count int) {
+    Ledger().RecordProduced(stage, count)
+}
+
+// RecordLoss counts events a stage of the process-wide ledger dropped
+func RecordLoss(stage, reason string, count int) {
+    Ledger().RecordLoss(stage, reason, count)
+}
+
+// RecordAlerted counts alerts raised, recorded in the process-wide ledger
+func RecordAlerted(count int) {
+    Ledger().RecordAlerted(count)
+}
+
+// RecordIngestedEvent counts an event entering a stage of the process-wide ledger once
+func RecordIngestedEvent(stage, eventID string) {
+    Ledger().RecordIngestedEvent(stage, eventID)
+}
+
+// RecordProducedEvent counts an event a stage of the process-wide ledger delivered, once
+func RecordProducedEvent(stage, eventID string) {
+    Ledger().RecordProducedEvent(stage, eventID)
+}
+
+// RecordLostEvent counts an event a stage of the process-wide ledger dropped, once
+func RecordLostEvent(stage, reason, eventID string) {
+    Ledger().RecordLostEvent(stage, reason, eventID)
+}
+
+// RecordAlertedEvent counts an alert raised for an event in the process-wide ledger, once
+func RecordAlertedEvent(eventID string) {
+    Ledger().RecordAlertedEvent(eventID)
+}
+
+// RecordIngested counts events entering a stage
+func (l *EventLedger) RecordIngested(stage string, count int) {
+    if count <= 0 {
+        return
+    }
+    ledgerEvents.WithLabelValues(stage, "ingested").Add(float64(count))
+
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.account(stage).Ingested += uint64(count)
+}
+
+// RecordProduced counts events a stage passed on to the next one
+func (l *EventLedger) RecordProduced(stage string, count int) {
+    if count <= 0 {
+        return
+    }
+    ledgerEvents.WithLabelValues(stage, "produced").Add(float64(count))
+
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.account(stage).Produced += uint64(count)
+}
+
+// RecordLoss counts events a stage dropped for the given reason
+func (l *EventLedger) RecordLoss(stage, reason string, count int) {
+    if count <= 0 {
+        return
+    }
+
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    if !l.reasons[reason] {
+        reason = lossReasonOther
+    }
+    ledgerLosses.WithLabelValues(stage, reason).Add(float64(count))
+
+    account := l.account(stage)
+    account.Lost[reason] += uint64(count)
+    account.LostTotal += uint64(count)
+}
+
+// RecordAlerted counts alerts raised by analysis
+func (l *EventLedger) RecordAlerted(count int) {
+    if count <= 0 {
+        return
+    }
+    ledgerEvents.WithLabelValues(StageAnalyze, "alerted").Add(float64(count))
+
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.alerted += uint64(count)
+}
+
+// RecordIngestedEvent counts an event entering a stage. An event redelivered
+// within the retry window is not counted again; an empty ID always counts.
+func (l *EventLedger) RecordIngestedEvent(stage, eventID string) {
+    if eventID != "" {
+        l.mu.Lock()
+        _, seen := l.track(stage, eventID)
+        l.mu.Unlock()
+        if seen {
+            return
+        }
+    }
+    l.RecordIngested(stage, 1)
+}
+
+// RecordProducedEvent counts an event a stage delivered to the next one.
+// Callers record it only once delivery is confirmed. Each event settles once
+// per retry window, and one not yet ingested is counted as ingested too.
+func (l *EventLedger) RecordProducedEvent(stage, eventID string) {
+    if eventID == "" {
+        l.RecordProduced(stage, 1)
+        return
+    }
+    ingested, settled := l.settle(stage, eventID)
+    if settled {
+        return
+    }
+    if !ingested {
+        l.RecordIngested(stage, 1)
+    }
+    l.RecordProduced(stage, 1)
+}
+
+// RecordLostEvent counts an event a stage dropped, settling it like
+// RecordProducedEvent
+func (l *EventLedger) RecordLostEvent(stage, reason, eventID string) {
+    if eventID == "" {
+        l.RecordLoss(stage, reason, 1)
+        return
+    }
+    ingested, settled := l.settle(stage, eventID)
+    if settled {
+        return
+    }
+    if !ingested {
+        l.RecordIngested(stage, 1)
+    }
+    l.RecordLoss(stage, reason, 1)
+}
+
+// RecordAlertedEvent counts an alert raised for an event. Alerts for an event
+// redelivered within the retry window are not counted again.
+func (l *EventLedger) RecordAlertedEvent(eventID string) {
+    if eventID != "" {
+        l.mu.Lock()
+        _, seen := l.track(dispositionAlerted, eventID)
+        l.mu.Unlock()
+        if seen {
+            return
+        }
+    }
+    l.RecordAlerted(1)
+}
+
+// Reconcile returns a snapshot of event disposition per stage
+func (l *EventLedger) Reconcile() *LedgerReport {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    report := &LedgerReport{
+        Since:       l.since,
+        GeneratedAt: time.Now().UTC(),
+        Stages:      make(map[string]StageAccount, len(l.stages)),
+        Alerted:     l.alerted,
+    }
+    for stage, account := range l.stages {
+        snapshot := *account
+        snapshot.Lost = make(map[string]uint64, len(account.Lost))
+        for reason, count := range account.Lost {
+            snapshot.Lost[reason] = count
+        }
+        snapshot.Unaccounted = int64(account.Ingested) - int64(account.Produced) - int64(account.LostTotal)
+        report.Stages[stage] = snapshot
+    }
+    return report
+}
+
+// StartReporting logs a reconciliation report on the configured interval
+// until the context is cancelled
+func (l *EventLedger) StartReporting(ctx context.Context) {
+    go func() {
+        ticker := time.NewTicker(l.config.ReportInterval)
+        defer ticker.Stop()
+
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case <-ticker.C:
+                l.logReport()
+            }
+        }
+    }()
+}
+
+// logReport logs one line per stage of the current reconciliation
+func (l *EventLedger) logReport() {
+    report := l.Reconcile()
+
+    stages := make([]string, 0, len(report.Stages))
+    for stage := range report.Stages {
+        stages = append(stages, stage)
+    }
+    sort.Strings(stages)
+
+    for _, stage := range stages {
+        account := report.Stages[stage]
+        logging.Info("Event disposition reconciliation",
+            logging.Field("stage", stage),
+            logging.Field("since", report.Since),
+            logging.Field("ingested", account.Ingested),
+            logging.Field("produced", account.Produced),
+            logging.Field("lost", account.Lost),
+            logging.Field("unaccounted", account.Unaccounted),
+            logging.Field("alerted", report.Alerted),
+        )
+    }
+}
+
+// account returns the stage's account, creating it on first use; callers hold l.mu
+func (l *EventLedger) account(stage string) *StageAccount {
+    account, ok := l.stages[stage]
+    if !ok {
+        account = &StageAccount{Lost: make(map[string]uint64)}
+        l.stages[stage] = account
+    }
+    return account
+}
+
+// track returns the record of an event in a stage and whether it was already
+// tracked within the retry window, periodically forgetting expired records;
+// callers hold l.mu
+func (l *EventLedger) track(stage, eventID string) (*eventRecord, bool) {
+    now := time.Now()
+    key := stage + "/" + eventID
+    if record, ok := l.events[key]; ok && now.Sub(record.seen) < l.config.RetryWindow {
+        return record, true
+    }
+
+    record := &eventRecord{seen: now}
+    l.events[key] = record
+    if len(l.events)%1024 == 0 {
+        for key, tracked := range l.events {
+            if now.Sub(tracked.seen) >= l.config.RetryWindow {
+                delete(l.events, key)
+            }
+        }
+    }
+    return record, false
+}
+
+// settle marks an event's disposition in a stage, reporting whether it had
+// been ingested and whether it was already settled
+func (l *EventLedger) settle(stage, eventID string) (bool, bool) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    record, ingested := l.track(stage, eventID)
+    if record.settled {
+        return ingested, true
+    }
+    record.settled = true
+    return ingested, false
+}
@@ -0,0 +1,223 @@
+// Package normalizer provides back-pressure control driven by downstream consumer lag
+package normalizer
+
+import (
+    "context"
+    "math/rand"
+    "sync"
+    "time"
+
+    "github.com/blackpoint/pkg/common/errors"
+    bpmetrics "github.com/blackpoint/internal/metrics"
+    "github.com/prometheus/client_golang/prometheus"
+    "go.uber.org/zap"
+)
+
+// Throttle modes applied while downstream lag is high
+const (
+    // ThrottleModePause stops consuming input until lag recovers
+    ThrottleModePause = "pause"
+
+    // ThrottleModeSample keeps consuming but admits only a fraction of events
+    ThrottleModeSample = "sample"
+)
+
+// Default back-pressure configuration
+const (
+    defaultLagCheckInterval = 10 * time.Second
+    defaultThrottleSample   = 0.1
+)
+
+var (
+    downstreamLag = prometheus.NewGauge(prometheus.GaugeOpts{
+        Name: "blackpoint_normalizer_downstream_lag",
+        Help: "Consumer group lag of the downstream analyzer as seen by the normalizer",
+    })
+
+    throttleState = prometheus.NewGauge(prometheus.GaugeOpts{
+        Name: "blackpoint_normalizer_throttled",
+        Help: "Whether the normalizer is throttling due to downstream lag (1 = throttled)",
+    })
+
+    throttleTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "blackpoint_normalizer_throttle_transitions_total",
+        Help: "Total number of throttle state transitions",
+    }, []string{"state"})
+)
+
+func init() {
+    prometheus.MustRegister(downstreamLag, throttleState, throttleTransitions)
+}
+
+// LagSource reports the current lag of the downstream consumer group
+type LagSource interface {
+    Lag() (int64, error)
+}
+
+// BackpressureConfig configures lag-driven throttling
+type BackpressureConfig struct {
+    // HighLag engages throttling once downstream lag exceeds it
+    HighLag int64
+
+    // LowLag releases throttling once lag falls to or below it; defaults to half of HighLag
+    LowLag int64
+
+    // Mode selects ThrottleModePause or ThrottleModeSample
+    Mode string
+
+    // SampleRate is the fraction of events admitted in sample mode
+    SampleRate float64
+
+    // CheckInterval controls how often lag is measured
+    CheckInterval time.Duration
+}
+
+// ThrottleState describes the current back-pressure state
+type ThrottleState struct {
+    Throttled bool      `json:"throttled"`
+    Mode      string    `json:"mode"`
+    Lag       int64     `json:"lag"`
+    Since     time.Time `json:"since"`
+    LastCheck time.Time `json:"last_check"`
+}
+
+// BackpressureController throttles normalizer output while the analyzer is behind.
+// Throttling uses hysteresis between LowLag and HighLag to avoid flapping.
+type BackpressureController struct {
+    source LagSource
+    config BackpressureConfig
+    logger *zap.Logger
+    state  ThrottleState
+    random *rand.Rand
+    mu     sync.RWMutex
+}
+
+// NewBackpressureController creates a controller with defaults applied
+func NewBackpressureController(source LagSource, config BackpressureConfig, logger *zap.Logger) (*BackpressureController, error) {
+    if source == nil {
+        return nil, errors.NewError("E2001", "lag source is required", nil)
+    }
+    if config.HighLag <= 0 {
+        return nil, errors.NewError("E2001", "high lag threshold must be positive", nil)
+    }
+    if config.LowLag <= 0 || config.LowLag >= config.HighLag {
+        config.LowLag = config.HighLag / 2
+    }
+    if config.Mode == "" {
+        config.Mode = ThrottleModePause
+    }
+    if config.Mode != ThrottleModePause && config.Mode != ThrottleModeSample {
+        return nil, errors.NewError("E2001", "unsupported throttle mode", map[string]interface{}{
+            "mode": config.Mode,
+        })
+    }
+    if config.SampleRate <= 0 || config.SampleRate > 1 {
+        config.SampleRate = defaultThrottleSample
+    }
+    if config.CheckInterval <= 0 {
+        config.CheckInterval = defaultLagCheckInterval
+    }
+    if logger == nil {
+        logger, _ = zap.NewProduction()
+    }
+
+    return &BackpressureController{
+        source: source,
+        config: config,
+        logger: logger,
+        state:  ThrottleState{Mode: config.Mode},
+        random: rand.New(rand.NewSource(time.Now().UnixNano())),
+    }, nil
+}
+
+// Run measures lag on the configured interval until the context is cancelled
+func (b *BackpressureController) Run(ctx context.Context) {
+    ticker := time.NewTicker(b.config.CheckInterval)
+    defer ticker.Stop()
+
+    b.Check()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            b.Check()
+        }
+    }
+}
+
+// Check measures downstream lag once and updates the throttle state
+func (b *BackpressureController) Check() {
+    lag, err := b.source.Lag()
+    if err != nil {
+        // Keep the previous state; a failed measurement is not evidence of recovery
+        b.logger.Warn("Failed to measure downstream lag", zap.Error(err))
+        return
+    }
+
+    downstreamLag.Set(float64(lag))
+
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    now := time.Now()
+    b.state.Lag = lag
+    b.state.LastCheck = now
+
+    switch {
+    case !b.state.Throttled && lag > b.config.HighLag:
+        b.state.Throttled = true
+        b.state.Since = now
+        throttleState.Set(1)
+        throttleTransitions.WithLabelValues("throttled").Inc()
+        b.logger.Warn("Downstream lag high, throttling normalizer",
+            zap.Int64("lag", lag),
+            zap.Int64("high_lag", b.config.HighLag),
+            zap.String("mode", b.config.Mode),
+        )
+    case b.state.Throttled && lag <= b.config.LowLag:
+        b.state.Throttled = false
+        b.state.Since = now
+        throttleState.Set(0)
+        throttleTransitions.WithLabelValues("released").Inc()
+        b.logger.Info("Downstream lag recovered, releasing throttle",
+            zap.Int64("lag", lag),
+            zap.Int64("low_lag", b.config.LowLag),
+        )
+    }
+}
+
+// Paused reports whether input consumption should be paused. It satisfies
+// streaming.FlowControl so the controller can be attached to a consumer.
+func (b *BackpressureController) Paused() bool {
+    b.mu.RLock()
+    defer b.mu.RUnlock()
+    return b.state.Throttled && b.config.Mode == ThrottleModePause
+}
+
+// Admit reports whether an event should be processed and produced. While
+// throttled in sample mode only SampleRate of events are admitted. Call it
+// before processing: shed events are recorded in the event ledger as ingested
+// and lost, admitted ones are accounted for by the processor.
+func (b *BackpressureController) Admit() bool {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    if !b.state.Throttled || b.config.Mode != ThrottleModeSample {
+        return true
+    }
+    if b.random.Float64() < b.config.SampleRate {
+        return true
+    }
+
+    bpmetrics.RecordIngested(bpmetrics.StageNormalize, 1)
+    bpmetrics.RecordLoss(bpmetrics.StageNormalize, bpmetrics.LossBackpressureShed, 1)
+    return false
+}
+
+// State returns a snapshot of the current throttle state
+func (b *BackpressureController) State() ThrottleState {
+    b.mu.RLock()
+    defer b.mu.RUnlock()
+    return b.state
+}
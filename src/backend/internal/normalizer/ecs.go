@@ -0,0 +1,243 @@
+// Package normalizer provides Elastic Common Schema output for normalized events
+package normalizer
+
+import (
+    "fmt"
+    "net"
+    "sort"
+    "strings"
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// Normalizer output modes
+const (
+    // OutputModeNative keeps the framework's flat normalized field names
+    OutputModeNative = "native"
+
+    // OutputModeECS maps normalized fields to Elastic Common Schema fields
+    OutputModeECS = "ecs"
+)
+
+const (
+    // ecsVersion is the ECS version produced events declare
+    ecsVersion = "8.11.0"
+
+    // defaultECSCustomNamespace holds fields without an ECS equivalent
+    defaultECSCustomNamespace = "blackpoint"
+)
+
+// DefaultECSMapping maps normalized field names to ECS field paths
+var DefaultECSMapping = map[string]string{
+    "event_time": "@timestamp",
+    "event_type": "event.action",
+    "severity":   "event.severity",
+    "outcome":    "event.outcome",
+    "src_ip":     "source.ip",
+    "dst_ip":     "destination.ip",
+    "src_port":   "source.port",
+    "dst_port":   "destination.port",
+    "src_user":   "source.user.name",
+    "dst_user":   "destination.user.name",
+    "user":       "user.name",
+    "hostname":   "host.name",
+    "process":    "process.name",
+    "url":        "url.full",
+    "message":    "message",
+}
+
+// ecsFieldSets are the top-level ECS field sets accepted in compliant events
+var ecsFieldSets = map[string]bool{
+    "@timestamp": true, "agent": true, "client": true, "cloud": true, "container": true,
+    "destination": true, "dns": true, "ecs": true, "error": true, "event": true,
+    "file": true, "group": true, "host": true, "http": true, "labels": true,
+    "log": true, "message": true, "network": true, "observer": true, "organization": true,
+    "process": true, "related": true, "rule": true, "server": true, "service": true,
+    "source": true, "tags": true, "threat": true, "tls": true, "url": true,
+    "user": true, "user_agent": true, "vulnerability": true,
+}
+
+// ECSMapper converts flat normalized fields into nested ECS documents
+type ECSMapper struct {
+    mapping         map[string]string
+    customNamespace string
+}
+
+// NewECSMapper creates a mapper from the default mapping with overrides applied.
+// An override with an empty target removes the default mapping for that field.
+func NewECSMapper(overrides map[string]string) (*ECSMapper, error) {
+    mapping := make(map[string]string, len(DefaultECSMapping)+len(overrides))
+    for field, target := range DefaultECSMapping {
+        mapping[field] = target
+    }
+    for field, target := range overrides {
+        if target == "" {
+            delete(mapping, field)
+            continue
+        }
+        root := strings.SplitN(target, ".", 2)[0]
+        if !ecsFieldSets[root] {
+            return nil, errors.NewError("E2001", "ECS mapping target is not an ECS field", map[string]interface{}{
+                "field":  field,
+                "target": target,
+            })
+        }
+        mapping[field] = target
+    }
+
+    return &ECSMapper{
+        mapping:         mapping,
+        customNamespace: defaultECSCustomNamespace,
+    }, nil
+}
+
+// ToECS builds an ECS document from normalized fields. Fields without an ECS
+// mapping are kept under the custom namespace rather than dropped.
+func (m *ECSMapper) ToECS(data map[string]interface{}) (map[string]interface{}, error) {
+    doc := map[string]interface{}{
+        "ecs": map[string]interface{}{"version": ecsVersion},
+    }
+
+    // Sort fields so conflicting mappings fail deterministically
+    fields := make([]string, 0, len(data))
+    for field := range data {
+        fields = append(fields, field)
+    }
+    sort.Strings(fields)
+
+    for _, field := range fields {
+        target, ok := m.mapping[field]
+        if !ok {
+            target = m.customNamespace + "." + field
+        }
+        if err := setECSPath(doc, target, data[field]); err != nil {
+            return nil, errors.WrapError(err, "failed to map field to ECS", map[string]interface{}{
+                "field":  field,
+                "target": target,
+            })
+        }
+    }
+
+    return doc, nil
+}
+
+// ValidateECS checks that a document is ECS compliant: it declares an ECS
+// version and timestamp, uses only ECS field sets or the custom namespace,
+// and well-known fields carry values of the expected type.
+func (m *ECSMapper) ValidateECS(doc map[string]interface{}) error {
+    var violations []string
+
+    if _, ok := lookupECSPath(doc, "ecs.version"); !ok {
+        violations = append(violations, "missing ecs.version")
+    }
+    if _, ok := doc["@timestamp"]; !ok {
+        violations = append(violations, "missing @timestamp")
+    }
+
+    for key := range doc {
+        if !ecsFieldSets[key] && key != m.customNamespace {
+            violations = append(violations, "unknown top-level field "+key)
+        }
+    }
+
+    for _, path := range []string{"source.ip", "destination.ip", "client.ip", "server.ip", "host.ip"} {
+        if value, ok := lookupECSPath(doc, path); ok {
+            if s, isString := value.(string); !isString || net.ParseIP(s) == nil {
+                violations = append(violations, path+" is not a valid IP address")
+            }
+        }
+    }
+
+    for _, path := range []string{"source.port", "destination.port"} {
+        if value, ok := lookupECSPath(doc, path); ok && !isECSPort(value) {
+            violations = append(violations, path+" is not a valid port")
+        }
+    }
+
+    if value, ok := lookupECSPath(doc, "event.outcome"); ok {
+        switch value {
+        case "success", "failure", "unknown":
+        default:
+            violations = append(violations, "event.outcome must be success, failure or unknown")
+        }
+    }
+
+    if len(violations) > 0 {
+        sort.Strings(violations)
+        return errors.NewError("E3001", "event is not ECS compliant", map[string]interface{}{
+            "violations": violations,
+        })
+    }
+    return nil
+}
+
+// setECSPath assigns a value at a dotted path, creating intermediate objects
+func setECSPath(doc map[string]interface{}, path string, value interface{}) error {
+    // @timestamp and similar top-level names contain no nesting
+    if !strings.Contains(path, ".") {
+        if _, exists := doc[path]; exists {
+            return fmt.Errorf("ECS field %s is already set", path)
+        }
+        doc[path] = value
+        return nil
+    }
+
+    parts := strings.Split(path, ".")
+    current := doc
+    for _, part := range parts[:len(parts)-1] {
+        next, exists := current[part]
+        if !exists {
+            child := make(map[string]interface{})
+            current[part] = child
+            current = child
+            continue
+        }
+        child, ok := next.(map[string]interface{})
+        if !ok {
+            return fmt.Errorf("ECS field %s conflicts with an existing value", part)
+        }
+        current = child
+    }
+
+    leaf := parts[len(parts)-1]
+    if _, exists := current[leaf]; exists {
+        return fmt.Errorf("ECS field %s is already set", path)
+    }
+    current[leaf] = value
+    return nil
+}
+
+// lookupECSPath reads a value at a dotted path
+func lookupECSPath(doc map[string]interface{}, path string) (interface{}, bool) {
+    parts := strings.Split(path, ".")
+    var current interface{} = doc
+    for _, part := range parts {
+        obj, ok := current.(map[string]interface{})
+        if !ok {
+            return nil, false
+        }
+        if current, ok = obj[part]; !ok {
+            return nil, false
+        }
+    }
+    return current, true
+}
+
+// isECSPort reports whether a value is an integer port number
+func isECSPort(value interface{}) bool {
+    var port float64
+    switch v := value.(type) {
+    case int:
+        port = float64(v)
+    case int64:
+        port = float64(v)
+    case float64:
+        if v != float64(int64(v)) {
+            return false
+        }
+        port = v
+    default:
+        return false
+    }
+    return port >= 0 && port <= 65535
+}
@@ -0,0 +1,163 @@
+// Package normalizer provides dependency-ordered enrichment of Silver events
+package normalizer
+
+import (
+    "context"
+    "sort"
+    "strings"
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/silver/schema"
+)
+
+// Enricher adds context to a Silver event, e.g. GeoIP, threat intel or identity
+type Enricher interface {
+    // Name uniquely identifies the enricher within a pipeline
+    Name() string
+
+    // DependsOn lists enrichers whose output this enricher reads
+    DependsOn() []string
+
+    // Enrich adds fields to the event's normalized data
+    Enrich(ctx context.Context, event *schema.SilverEvent) error
+}
+
+// EnrichmentPipeline runs enrichers so each one runs after its dependencies
+type EnrichmentPipeline struct {
+    enrichers    []Enricher
+    dependencies map[string][]string
+}
+
+// NewEnrichmentPipeline orders enrichers topologically by their declared
+// dependencies. Enrichers with no ordering constraint between them run in
+// name order so the sequence is stable across restarts.
+func NewEnrichmentPipeline(enrichers ...Enricher) (*EnrichmentPipeline, error) {
+    byName := make(map[string]Enricher, len(enrichers))
+    for _, enricher := range enrichers {
+        if enricher == nil {
+            return nil, errors.NewError("E2001", "nil enricher", nil)
+        }
+        name := enricher.Name()
+        if _, exists := byName[name]; exists || name == "" {
+            return nil, errors.NewError("E2001", "enricher names must be unique and non-empty", map[string]interface{}{
+                "enricher": name,
+            })
+        }
+        byName[name] = enricher
+    }
+
+    dependencies := make(map[string][]string, len(byName))
+    dependents := make(map[string][]string, len(byName))
+    remaining := make(map[string]int, len(byName))
+    for name, enricher := range byName {
+        deps := enricher.DependsOn()
+        for _, dep := range deps {
+            if _, exists := byName[dep]; !exists {
+                return nil, errors.NewError("E2001", "enricher depends on unknown enricher", map[string]interface{}{
+                    "enricher":   name,
+                    "dependency": dep,
+                })
+            }
+            dependents[dep] = append(dependents[dep], name)
+        }
+        dependencies[name] = deps
+        remaining[name] = len(deps)
+    }
+
+    var ready []string
+    for name, count := range remaining {
+        if count == 0 {
+            ready = append(ready, name)
+        }
+    }
+
+    ordered := make([]Enricher, 0, len(byName))
+    for len(ready) > 0 {
+        sort.Strings(ready)
+        name := ready[0]
+        ready = ready[1:]
+        ordered = append(ordered, byName[name])
+
+        for _, dependent := range dependents[name] {
+            remaining[dependent]--
+            if remaining[dependent] == 0 {
+                ready = append(ready, dependent)
+            }
+        }
+    }
+
+    if len(ordered) < len(byName) {
+        var cycle []string
+        for name, count := range remaining {
+            if count > 0 {
+                cycle = append(cycle, name)
+            }
+        }
+        sort.Strings(cycle)
+        return nil, errors.NewError("E2001", "enricher dependency cycle detected", map[string]interface{}{
+            "enrichers": strings.Join(cycle, ","),
+        })
+    }
+
+    return &EnrichmentPipeline{
+        enrichers:    ordered,
+        dependencies: dependencies,
+    }, nil
+}
+
+// Order returns enricher names in execution order
+func (ep *EnrichmentPipeline) Order() []string {
+    names := make([]string, len(ep.enrichers))
+    for i, enricher := range ep.enrichers {
+        names[i] = enricher.Name()
+    }
+    return names
+}
+
+// Run applies every enricher to the event. When an enricher fails, enrichers
+// that depend on it, directly or transitively, are skipped while independent
+// ones still run; the returned error lists both.
+func (ep *EnrichmentPipeline) Run(ctx context.Context, event *schema.SilverEvent) error {
+    failed := make(map[string]bool)
+    var failures, skipped []string
+    var firstErr error
+
+    for _, enricher := range ep.enrichers {
+        name := enricher.Name()
+
+        blocked := false
+        for _, dep := range ep.dependencies[name] {
+            if failed[dep] {
+                blocked = true
+                break
+            }
+        }
+        if blocked {
+            failed[name] = true
+            skipped = append(skipped, name)
+            continue
+        }
+
+        if err := ctx.Err(); err != nil {
+            return errors.WrapError(err, "enrichment cancelled", map[string]interface{}{
+                "enricher": name,
+            })
+        }
+
+        if err := enricher.Enrich(ctx, event); err != nil {
+            failed[name] = true
+            failures = append(failures, name)
+            if firstErr == nil {
+                firstErr = err
+            }
+        }
+    }
+
+    if firstErr != nil {
+        return errors.WrapError(firstErr, "event enrichment failed", map[string]interface{}{
+            "failed":  strings.Join(failures, ","),
+            "skipped": strings.Join(skipped, ","),
+        })
+    }
+    return nil
+}
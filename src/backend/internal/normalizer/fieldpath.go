@@ -0,0 +1,106 @@
+// Package normalizer provides dot-path access to nested event fields
+package normalizer
+
+import (
+    "strconv"
+    "strings"
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// pathSegment is one step of a field path: an object key or an array index
+type pathSegment struct {
+    key     string
+    index   int
+    isIndex bool
+}
+
+// parseFieldPath parses a dot-path such as "events[0].actor.id"
+func parseFieldPath(path string) ([]pathSegment, error) {
+    var segments []pathSegment
+    for _, part := range strings.Split(path, ".") {
+        name, indexes := part, ""
+        if i := strings.IndexByte(part, '['); i >= 0 {
+            name, indexes = part[:i], part[i:]
+        }
+        if name == "" && indexes == "" {
+            return nil, errors.NewError("E3001", "empty field path segment", map[string]interface{}{
+                "path": path,
+            })
+        }
+        if name != "" {
+            segments = append(segments, pathSegment{key: name})
+        }
+
+        for indexes != "" {
+            end := strings.IndexByte(indexes, ']')
+            if indexes[0] != '[' || end < 0 {
+                return nil, errors.NewError("E3001", "malformed array index in field path", map[string]interface{}{
+                    "path": path,
+                })
+            }
+            index, err := strconv.Atoi(indexes[1:end])
+            if err != nil || index < 0 {
+                return nil, errors.NewError("E3001", "invalid array index in field path", map[string]interface{}{
+                    "path": path,
+                })
+            }
+            segments = append(segments, pathSegment{index: index, isIndex: true})
+            indexes = indexes[end+1:]
+        }
+    }
+    return segments, nil
+}
+
+// lookupFieldPath resolves parsed segments against nested objects and arrays.
+// It reports false when any intermediate value is absent or of the wrong type.
+func lookupFieldPath(data map[string]interface{}, segments []pathSegment) (interface{}, bool) {
+    var current interface{} = data
+    for _, segment := range segments {
+        if segment.isIndex {
+            array, ok := current.([]interface{})
+            if !ok || segment.index >= len(array) {
+                return nil, false
+            }
+            current = array[segment.index]
+            continue
+        }
+
+        object, ok := current.(map[string]interface{})
+        if !ok {
+            return nil, false
+        }
+        if current, ok = object[segment.key]; !ok {
+            return nil, false
+        }
+    }
+    return current, true
+}
+
+// setFieldPath writes value to a flat target, or to a nested object when the
+// target contains dots, creating intermediate objects as needed
+func setFieldPath(data map[string]interface{}, target string, value interface{}) error {
+    keys := strings.Split(target, ".")
+    current := data
+    for _, key := range keys[:len(keys)-1] {
+        next, exists := current[key]
+        if !exists {
+            child := make(map[string]interface{})
+            current[key] = child
+            current = child
+            continue
+        }
+
+        child, ok := next.(map[string]interface{})
+        if !ok {
+            return errors.NewError("E3001", "normalized field conflicts with an existing value", map[string]interface{}{
+                "field": target,
+                "key":   key,
+            })
+        }
+        current = child
+    }
+
+    current[keys[len(keys)-1]] = value
+    return nil
+}
@@ -0,0 +1,138 @@
+// Package normalizer provides per-stage latency tracking for event processing
+package normalizer
+
+import (
+    "math"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+// Processing stages timed by the processor
+const (
+    StageMapping        = "mapping"
+    StageTransformation = "transformation"
+    StageEncryption     = "encryption"
+)
+
+// latencySampleSize bounds the recent samples kept per stage for percentiles
+const latencySampleSize = 1024
+
+var stageLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+    Name:    "blackpoint_normalizer_stage_latency_seconds",
+    Help:    "Time spent in each normalizer processing stage",
+    Buckets: prometheus.ExponentialBuckets(0.0001, 2, 14),
+}, []string{"stage"})
+
+func init() {
+    prometheus.MustRegister(stageLatency)
+}
+
+// StageLatency summarizes the recent timings of one processing stage
+type StageLatency struct {
+    Count uint64
+    Mean  time.Duration
+    P50   time.Duration
+    P95   time.Duration
+    P99   time.Duration
+}
+
+// ProcessorMetrics is a snapshot of processor throughput and stage timings.
+// Percentiles cover the most recent samples of each stage.
+type ProcessorMetrics struct {
+    EventsProcessed  uint64
+    ProcessingErrors uint64
+    Stages           map[string]StageLatency
+}
+
+// latencySamples is a ring of recent timings for one stage
+type latencySamples struct {
+    count   uint64
+    total   time.Duration
+    samples []time.Duration
+    next    int
+}
+
+// stageRecorder records per-stage timings for GetMetrics alongside the
+// Prometheus histogram
+type stageRecorder struct {
+    mu               sync.Mutex
+    eventsProcessed  uint64
+    processingErrors uint64
+    stages           map[string]*latencySamples
+}
+
+func newStageRecorder() *stageRecorder {
+    return &stageRecorder{stages: make(map[string]*latencySamples)}
+}
+
+// observe records one timing for a stage
+func (r *stageRecorder) observe(stage string, d time.Duration) {
+    stageLatency.WithLabelValues(stage).Observe(d.Seconds())
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    s, ok := r.stages[stage]
+    if !ok {
+        s = &latencySamples{samples: make([]time.Duration, 0, latencySampleSize)}
+        r.stages[stage] = s
+    }
+    s.count++
+    s.total += d
+    if len(s.samples) < latencySampleSize {
+        s.samples = append(s.samples, d)
+        return
+    }
+    s.samples[s.next] = d
+    s.next = (s.next + 1) % latencySampleSize
+}
+
+// recordResult counts a processed or failed event
+func (r *stageRecorder) recordResult(err error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if err != nil {
+        r.processingErrors++
+        return
+    }
+    r.eventsProcessed++
+}
+
+// snapshot computes the current metrics
+func (r *stageRecorder) snapshot() *ProcessorMetrics {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    metrics := &ProcessorMetrics{
+        EventsProcessed:  r.eventsProcessed,
+        ProcessingErrors: r.processingErrors,
+        Stages:           make(map[string]StageLatency, len(r.stages)),
+    }
+    for stage, s := range r.stages {
+        sorted := append([]time.Duration(nil), s.samples...)
+        sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+        metrics.Stages[stage] = StageLatency{
+            Count: s.count,
+            Mean:  s.total / time.Duration(s.count),
+            P50:   percentile(sorted, 0.50),
+            P95:   percentile(sorted, 0.95),
+            P99:   percentile(sorted, 0.99),
+        }
+    }
+    return metrics
+}
+
+// percentile returns the nearest-rank percentile of sorted timings
+func percentile(sorted []time.Duration, p float64) time.Duration {
+    if len(sorted) == 0 {
+        return 0
+    }
+    idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+    if idx < 0 {
+        idx = 0
+    }
+    return sorted[idx]
+}
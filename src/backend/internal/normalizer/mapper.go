@@ -45,8 +45,11 @@ var fieldTypeValidations = map[string]string{
 
 // FieldMapper handles field mapping operations with performance optimization
 type FieldMapper struct {
+    mu               sync.RWMutex
     standardMappings map[string]string
     customMappings   map[string]string
+    platformMappings map[string]map[string]string
+    requiredSources  map[string]bool
     requiredFields   []string
     pathCache       sync.Map
     logger         *zap.Logger
@@ -96,16 +99,16 @@ func (fm *FieldMapper) MapEvent(bronzeEvent *schema.BronzeEvent) (*schema.Silver
     }
 
     // Map fields with caching
-    normalizedData, err := fm.mapFields(rawData)
+    normalizedData, err := fm.mapFields(rawData, fm.mappingsFor(bronzeEvent.SourcePlatform))
     if err != nil {
         return nil, err
     }
 
     // Create security context
     securityContext := schema.SecurityContext{
-        Classification: "normalized",
-        Sensitivity:    "medium",
-        Compliance:     []string{"default"},
+        Classification: "INTERNAL",
+        Sensitivity:    "MEDIUM",
+        Compliance:     []string{"DEFAULT"},
         Encryption:     make(map[string]string),
         AccessControl:  make(map[string]string),
     }
@@ -127,28 +130,23 @@ func (fm *FieldMapper) MapEvent(bronzeEvent *schema.BronzeEvent) (*schema.Silver
     return silverEvent, nil
 }
 
-// mapFields performs the actual field mapping with performance optimization
-func (fm *FieldMapper) mapFields(rawData map[string]interface{}) (map[string]interface{}, error) {
+// mapFields performs the actual field mapping with performance optimization.
+// Source fields may be dot-paths into nested objects and arrays; targets
+// containing dots are written as nested objects.
+func (fm *FieldMapper) mapFields(rawData map[string]interface{}, customMappings map[string]string) (map[string]interface{}, error) {
     normalizedData := make(map[string]interface{}, len(rawData))
 
-    // Apply standard mappings first
-    for sourceField, targetField := range fm.standardMappings {
-        if value, exists := rawData[sourceField]; exists {
-            if err := fm.validateField(targetField, value); err != nil {
-                fm.metrics.validationErrors.Inc()
-                return nil, err
-            }
-            normalizedData[targetField] = value
-        }
-    }
-
-    // Apply custom mappings
-    for sourceField, targetField := range fm.customMappings {
-        if value, exists := rawData[sourceField]; exists {
-            // Check cache for complex field paths
-            if cachedValue, ok := fm.pathCache.Load(sourceField); ok {
-                fm.metrics.cacheHits.Inc()
-                normalizedData[targetField] = cachedValue
+    // Apply standard mappings first, then custom mappings
+    for _, mappings := range []map[string]string{fm.standardMappings, customMappings} {
+        for sourceField, targetField := range mappings {
+            value, exists := fm.resolveField(rawData, sourceField)
+            if !exists {
+                if fm.isRequiredSource(sourceField) {
+                    fm.metrics.validationErrors.Inc()
+                    return nil, errors.NewError("E3001", "missing required mapping source", map[string]interface{}{
+                        "field": sourceField,
+                    })
+                }
                 continue
             }
 
@@ -156,8 +154,10 @@ func (fm *FieldMapper) mapFields(rawData map[string]interface{}) (map[string]int
                 fm.metrics.validationErrors.Inc()
                 return nil, err
             }
-            normalizedData[targetField] = value
-            fm.pathCache.Store(sourceField, value)
+            if err := setFieldPath(normalizedData, targetField, value); err != nil {
+                fm.metrics.validationErrors.Inc()
+                return nil, err
+            }
         }
     }
 
@@ -170,6 +170,42 @@ func (fm *FieldMapper) mapFields(rawData map[string]interface{}) (map[string]int
     return normalizedData, nil
 }
 
+// resolveField looks up a source field, preferring a literal top-level key and
+// falling back to dot-path resolution. Parsed paths are cached per source field.
+func (fm *FieldMapper) resolveField(rawData map[string]interface{}, sourceField string) (interface{}, bool) {
+    if value, exists := rawData[sourceField]; exists {
+        return value, true
+    }
+    if !strings.ContainsAny(sourceField, ".[") {
+        return nil, false
+    }
+
+    var segments []pathSegment
+    if cached, ok := fm.pathCache.Load(sourceField); ok {
+        fm.metrics.cacheHits.Inc()
+        segments = cached.([]pathSegment)
+    } else {
+        parsed, err := parseFieldPath(sourceField)
+        if err != nil && fm.logger != nil {
+            fm.logger.Warn("Invalid mapping source path", zap.String("field", sourceField), zap.Error(err))
+        }
+        fm.pathCache.Store(sourceField, parsed)
+        segments = parsed
+    }
+    if segments == nil {
+        return nil, false
+    }
+
+    return lookupFieldPath(rawData, segments)
+}
+
+// isRequiredSource reports whether a mapping source must be present in every event
+func (fm *FieldMapper) isRequiredSource(sourceField string) bool {
+    fm.mu.RLock()
+    defer fm.mu.RUnlock()
+    return fm.requiredSources[sourceField]
+}
+
 // validateField performs type validation for specific fields
 func (fm *FieldMapper) validateField(fieldName string, value interface{}) error {
     if validationType, exists := fieldTypeValidations[fieldName]; exists {
@@ -231,12 +267,77 @@ func (fm *FieldMapper) validateRequiredFields(data map[string]interface{}) error
     return nil
 }
 
+// mappingsFor returns the custom mappings for a source platform, falling back
+// to the platform-independent set
+func (fm *FieldMapper) mappingsFor(platform string) map[string]string {
+    fm.mu.RLock()
+    defer fm.mu.RUnlock()
+
+    if mappings, ok := fm.platformMappings[platform]; ok {
+        return mappings
+    }
+    return fm.customMappings
+}
+
 // AddCustomMapping adds or updates a custom field mapping
 func (fm *FieldMapper) AddCustomMapping(sourceField, targetField string) {
-    fm.customMappings[sourceField] = targetField
+    fm.mu.Lock()
+    defer fm.mu.Unlock()
+
+    // Copy on write so in-flight events keep a consistent view
+    updated := make(map[string]string, len(fm.customMappings)+1)
+    for source, target := range fm.customMappings {
+        updated[source] = target
+    }
+    updated[sourceField] = targetField
+    fm.customMappings = updated
     fm.pathCache.Delete(sourceField) // Clear cache for updated mapping
 }
 
+// SetRequiredMapping marks whether a mapping source must be present. Events
+// missing a required source fail mapping; other absent sources are skipped.
+func (fm *FieldMapper) SetRequiredMapping(sourceField string, required bool) {
+    fm.mu.Lock()
+    defer fm.mu.Unlock()
+
+    updated := make(map[string]bool, len(fm.requiredSources)+1)
+    for source := range fm.requiredSources {
+        updated[source] = true
+    }
+    if required {
+        updated[sourceField] = true
+    } else {
+        delete(updated, sourceField)
+    }
+    fm.requiredSources = updated
+}
+
+// ApplyMappingFile replaces the custom and per-platform mappings with those
+// from a loaded mapping file. Events being mapped keep the previous set.
+func (fm *FieldMapper) ApplyMappingFile(file *MappingFile) {
+    platformMappings := make(map[string]map[string]string, len(file.Platforms))
+    for platform := range file.Platforms {
+        platformMappings[platform] = file.ForPlatform(platform)
+    }
+
+    requiredSources := make(map[string]bool, len(file.Required))
+    for _, sourceField := range file.Required {
+        requiredSources[sourceField] = true
+    }
+
+    fm.mu.Lock()
+    fm.customMappings = file.Mappings
+    fm.platformMappings = platformMappings
+    fm.requiredSources = requiredSources
+    fm.mu.Unlock()
+
+    // Delete entries in place; replacing the map would race with MapEvent
+    fm.pathCache.Range(func(key, _ interface{}) bool {
+        fm.pathCache.Delete(key)
+        return true
+    })
+}
+
 // ClearCache clears the field path cache
 func (fm *FieldMapper) ClearCache() {
     fm.pathCache = sync.Map{}
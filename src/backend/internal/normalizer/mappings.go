@@ -0,0 +1,124 @@
+// Package normalizer provides loading of field mapping definitions from files
+package normalizer
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "gopkg.in/yaml.v3" // v3.0.1
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// MappingFile holds field mapping definitions loaded from YAML or JSON.
+// Mappings apply to every event; Platforms adds or overrides mappings for
+// events from one source platform. Source fields listed in Required must be
+// present in every event; other absent sources are skipped.
+type MappingFile struct {
+    Mappings  map[string]string            `yaml:"mappings" json:"mappings"`
+    Platforms map[string]map[string]string `yaml:"platforms" json:"platforms"`
+    Required  []string                     `yaml:"required" json:"required"`
+}
+
+// LoadMappingsFromFile loads the platform-independent mappings from a YAML or
+// JSON mapping file. Use LoadMappingFile to also get per-platform sets.
+func LoadMappingsFromFile(path string) (map[string]string, error) {
+    file, err := LoadMappingFile(path)
+    if err != nil {
+        return nil, err
+    }
+    return file.Mappings, nil
+}
+
+// LoadMappingFile loads and validates a YAML or JSON mapping file. Files with
+// a .json extension are parsed as JSON, all others as YAML.
+func LoadMappingFile(path string) (*MappingFile, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to read mapping file", map[string]interface{}{
+            "path": path,
+        })
+    }
+
+    var file MappingFile
+    if strings.EqualFold(filepath.Ext(path), ".json") {
+        err = json.Unmarshal(data, &file)
+    } else {
+        err = yaml.Unmarshal(data, &file)
+    }
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to parse mapping file", map[string]interface{}{
+            "path": path,
+        })
+    }
+
+    if file.Mappings == nil {
+        file.Mappings = make(map[string]string)
+    }
+    if err := file.Validate(); err != nil {
+        return nil, errors.WrapError(err, "invalid mapping file", map[string]interface{}{
+            "path": path,
+        })
+    }
+
+    return &file, nil
+}
+
+// Validate checks that no two source fields map to the same normalized field,
+// both in the default set and in each platform set merged over it
+func (f *MappingFile) Validate() error {
+    if err := validateMappingSet("default", f.Mappings); err != nil {
+        return err
+    }
+    for platform := range f.Platforms {
+        if err := validateMappingSet(platform, f.ForPlatform(platform)); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// ForPlatform returns the default mappings with the platform's set applied on top
+func (f *MappingFile) ForPlatform(platform string) map[string]string {
+    merged := make(map[string]string, len(f.Mappings)+len(f.Platforms[platform]))
+    for source, target := range f.Mappings {
+        merged[source] = target
+    }
+    for source, target := range f.Platforms[platform] {
+        merged[source] = target
+    }
+    return merged
+}
+
+// validateMappingSet rejects empty fields and normalized fields with more than one source
+func validateMappingSet(set string, mappings map[string]string) error {
+    sources := make(map[string][]string, len(mappings))
+    for source, target := range mappings {
+        if source == "" || target == "" {
+            return errors.NewError("E2001", fmt.Sprintf("mapping set %q has an empty field name", set), map[string]interface{}{
+                "set":    set,
+                "source": source,
+                "target": target,
+            })
+        }
+        sources[target] = append(sources[target], source)
+    }
+
+    for target, fields := range sources {
+        if len(fields) > 1 {
+            sort.Strings(fields)
+            msg := fmt.Sprintf("mapping set %q maps source fields %s to the same normalized field %q",
+                set, strings.Join(fields, ", "), target)
+            return errors.NewError("E2001", msg, map[string]interface{}{
+                "set":           set,
+                "target":        target,
+                "source_fields": strings.Join(fields, ", "),
+            })
+        }
+    }
+    return nil
+}
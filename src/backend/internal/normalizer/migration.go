@@ -0,0 +1,232 @@
+// Package normalizer provides schema version migration for Bronze event payloads
+package normalizer
+
+import (
+    "encoding/json"
+    "fmt"
+    "strconv"
+    "strings"
+    "sync"
+
+    "github.com/blackpoint/pkg/bronze/schema"
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// MigrationFunc upgrades a decoded payload from one schema version to the next
+type MigrationFunc func(payload map[string]interface{}) (map[string]interface{}, error)
+
+// schemaMigration is a registered single-step upgrade
+type schemaMigration struct {
+    toVersion string
+    fn        MigrationFunc
+}
+
+// SchemaMigrator upgrades events produced under older schemas to a target
+// version by applying registered migrations in sequence
+type SchemaMigrator struct {
+    mu            sync.RWMutex
+    targetVersion string
+    migrations    map[string]schemaMigration
+}
+
+// NewSchemaMigrator creates a migrator that upgrades events to targetVersion
+func NewSchemaMigrator(targetVersion string) (*SchemaMigrator, error) {
+    if _, err := parseSchemaVersion(targetVersion); err != nil {
+        return nil, err
+    }
+    return &SchemaMigrator{
+        targetVersion: targetVersion,
+        migrations:    make(map[string]schemaMigration),
+    }, nil
+}
+
+// Register adds the migration from fromVersion to toVersion. Each version has
+// at most one outgoing migration and migrations may only upgrade.
+func (m *SchemaMigrator) Register(fromVersion, toVersion string, fn MigrationFunc) error {
+    if fn == nil {
+        return errors.NewError("E2001", "nil schema migration", map[string]interface{}{
+            "from_version": fromVersion,
+            "to_version":   toVersion,
+        })
+    }
+    cmp, err := compareSchemaVersions(fromVersion, toVersion)
+    if err != nil {
+        return err
+    }
+    if cmp >= 0 {
+        return errors.NewError("E2001", "schema migrations must upgrade to a newer version", map[string]interface{}{
+            "from_version": fromVersion,
+            "to_version":   toVersion,
+        })
+    }
+
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if existing, ok := m.migrations[fromVersion]; ok {
+        return errors.NewError("E2001", "schema migration already registered", map[string]interface{}{
+            "from_version": fromVersion,
+            "to_version":   existing.toVersion,
+        })
+    }
+    m.migrations[fromVersion] = schemaMigration{toVersion: toVersion, fn: fn}
+    return nil
+}
+
+// Migrate returns the event upgraded to the target version. Events already at
+// the target are returned unchanged. An event without a version predates
+// versioning and is migrated from the oldest supported version, the start of
+// the registered migration chain. The input event is never modified.
+func (m *SchemaMigrator) Migrate(event *schema.BronzeEvent) (*schema.BronzeEvent, error) {
+    fromVersion := event.SchemaVersion
+    if fromVersion == "" {
+        fromVersion = m.OldestVersion()
+    }
+    if fromVersion == m.targetVersion {
+        if event.SchemaVersion == fromVersion {
+            return event, nil
+        }
+        stamped := *event
+        stamped.SchemaVersion = fromVersion
+        return &stamped, nil
+    }
+
+    cmp, err := compareSchemaVersions(fromVersion, m.targetVersion)
+    if err != nil {
+        return nil, err
+    }
+    if cmp > 0 {
+        return nil, errors.NewError("E3001", "cannot downgrade event schema", map[string]interface{}{
+            "event_id":       event.ID,
+            "schema_version": fromVersion,
+            "target_version": m.targetVersion,
+        })
+    }
+
+    var payload map[string]interface{}
+    if err := json.Unmarshal(event.Payload, &payload); err != nil {
+        return nil, errors.WrapError(err, "failed to parse payload for schema migration", map[string]interface{}{
+            "event_id": event.ID,
+        })
+    }
+
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    version := fromVersion
+    for version != m.targetVersion {
+        step, ok := m.migrations[version]
+        if !ok {
+            return nil, errors.NewError("E3001", fmt.Sprintf("no schema migration path from %s to %s", fromVersion, m.targetVersion), map[string]interface{}{
+                "event_id":       event.ID,
+                "schema_version": version,
+                "target_version": m.targetVersion,
+            })
+        }
+        if cmp, _ := compareSchemaVersions(step.toVersion, m.targetVersion); cmp > 0 {
+            return nil, errors.NewError("E3001", fmt.Sprintf("schema migration from %s overshoots target %s", version, m.targetVersion), map[string]interface{}{
+                "event_id":   event.ID,
+                "to_version": step.toVersion,
+            })
+        }
+
+        payload, err = step.fn(payload)
+        if err != nil {
+            return nil, errors.WrapError(err, fmt.Sprintf("schema migration from %s to %s failed", version, step.toVersion), map[string]interface{}{
+                "event_id": event.ID,
+            })
+        }
+        version = step.toVersion
+    }
+
+    data, err := json.Marshal(payload)
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to encode migrated payload", map[string]interface{}{
+            "event_id": event.ID,
+        })
+    }
+
+    migrated := *event
+    migrated.Payload = data
+    migrated.SchemaVersion = version
+    return &migrated, nil
+}
+
+// OldestVersion returns the oldest schema version the migrator can upgrade:
+// the oldest version with a registered migration, or the target version when
+// none are registered
+func (m *SchemaMigrator) OldestVersion() string {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    oldest := m.targetVersion
+    for version := range m.migrations {
+        if cmp, err := compareSchemaVersions(version, oldest); err == nil && cmp < 0 {
+            oldest = version
+        }
+    }
+    return oldest
+}
+
+// RenameField returns a migration that moves a top-level payload field to a
+// new name, leaving payloads without the field unchanged
+func RenameField(from, to string) MigrationFunc {
+    return func(payload map[string]interface{}) (map[string]interface{}, error) {
+        value, ok := payload[from]
+        if !ok {
+            return payload, nil
+        }
+        if _, exists := payload[to]; exists {
+            return nil, errors.NewError("E3001", "renamed field already present", map[string]interface{}{
+                "from": from,
+                "to":   to,
+            })
+        }
+        delete(payload, from)
+        payload[to] = value
+        return payload, nil
+    }
+}
+
+// parseSchemaVersion parses a "major.minor" schema version
+func parseSchemaVersion(version string) ([2]int, error) {
+    var parsed [2]int
+    parts := strings.Split(version, ".")
+    if len(parts) != 2 {
+        return parsed, errors.NewError("E3001", "invalid schema version", map[string]interface{}{
+            "schema_version": version,
+        })
+    }
+    for i, part := range parts {
+        n, err := strconv.Atoi(part)
+        if err != nil || n < 0 {
+            return parsed, errors.NewError("E3001", "invalid schema version", map[string]interface{}{
+                "schema_version": version,
+            })
+        }
+        parsed[i] = n
+    }
+    return parsed, nil
+}
+
+// compareSchemaVersions returns -1, 0 or 1 as a is older than, equal to or
+// newer than b
+func compareSchemaVersions(a, b string) (int, error) {
+    va, err := parseSchemaVersion(a)
+    if err != nil {
+        return 0, err
+    }
+    vb, err := parseSchemaVersion(b)
+    if err != nil {
+        return 0, err
+    }
+    for i := range va {
+        switch {
+        case va[i] < vb[i]:
+            return -1, nil
+        case va[i] > vb[i]:
+            return 1, nil
+        }
+    }
+    return 0, nil
+}
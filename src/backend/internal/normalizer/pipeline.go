@@ -0,0 +1,304 @@
+// Package normalizer provides the streaming Bronze-to-Silver pipeline of the normalizer service
+package normalizer
+
+import (
+    "context"
+    "strconv"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "go.opentelemetry.io/otel/trace"
+    "go.uber.org/zap"
+
+    bpmetrics "github.com/blackpoint/internal/metrics"
+    "github.com/blackpoint/internal/streaming"
+    "github.com/blackpoint/pkg/bronze/schema"
+    "github.com/blackpoint/pkg/common/errors"
+    silver "github.com/blackpoint/pkg/silver/schema"
+)
+
+// DeadLetterMetric names the counter of dead-lettered messages. Its client_id
+// label is capped by the metric's shared cardinality guard, whose limit can be
+// changed with metrics.SetLabelValueLimit.
+const DeadLetterMetric = "blackpoint_normalizer_dead_lettered_total"
+
+// Client label of dead-lettered messages whose client is not known
+const unknownClientLabel = "unknown"
+
+var deadLettered = prometheus.NewCounterVec(prometheus.CounterOpts{
+    Name: DeadLetterMetric,
+    Help: "Total number of messages the normalizer moved to the dead-letter topic by client and stage",
+}, []string{"client_id", "stage"})
+
+func init() {
+    prometheus.MustRegister(deadLettered)
+}
+
+// Admission decides whether a consumed event is normalized; the
+// BackpressureController satisfies it by shedding events while throttled in
+// sample mode
+type Admission interface {
+    Admit() bool
+}
+
+// PipelineConfig configures the consumer-to-processor pipeline
+type PipelineConfig struct {
+    // BatchSize caps the events handed to the processor at once; consumed
+    // batches are split to fit. Defaults to the processor's maximum.
+    BatchSize int
+
+    // Timeout bounds processing and publishing of each processor batch.
+    // Defaults to the processor's timeout.
+    Timeout time.Duration
+
+    // SecurityContext is applied to every event; the processor's default
+    // classification applies when nil
+    SecurityContext *silver.SecurityContext
+
+    // Admission, when set, is consulted for every decoded event before it is
+    // processed; events it rejects are dropped and accounted for by it
+    Admission Admission
+
+    // Processed, Errors and Latency receive event counts and per-batch
+    // latency; nil metrics are skipped
+    Processed prometheus.Counter
+    Errors    prometheus.Counter
+    Latency   prometheus.Observer
+}
+
+// DeadLetterPublisher receives consumed messages the pipeline cannot
+// normalize; the streaming package's Producer satisfies it
+type DeadLetterPublisher interface {
+    PublishWithHeaders(ctx context.Context, event []byte, headers map[string]string) error
+}
+
+// Headers added to dead-lettered messages alongside their original headers
+const (
+    HeaderDeadLetterReason = "dead_letter_reason"
+    HeaderSourceTopic      = "source_topic"
+    HeaderSourcePartition  = "source_partition"
+    HeaderSourceOffset     = "source_offset"
+)
+
+// Pipeline normalizes consumed Bronze messages and publishes the Silver
+// results. Its HandleBatch is installed as the consumer's batch handler, so
+// offsets are committed only once every message of a batch has been
+// published or dead-lettered.
+type Pipeline struct {
+    processor  *Processor
+    publisher  SilverPublisher
+    deadLetter DeadLetterPublisher
+    config     PipelineConfig
+}
+
+// NewPipeline creates a pipeline from processor to publisher
+func NewPipeline(processor *Processor, publisher SilverPublisher, config PipelineConfig) (*Pipeline, error) {
+    if processor == nil || publisher == nil {
+        return nil, errors.NewError("E2001", "pipeline requires a processor and a publisher", nil)
+    }
+    if config.BatchSize < 0 || config.BatchSize > maxBatchSize {
+        return nil, errors.NewError("E2001", "pipeline batch size out of range", map[string]interface{}{
+            "batch_size": config.BatchSize,
+            "max_size":   maxBatchSize,
+        })
+    }
+    if config.BatchSize == 0 {
+        config.BatchSize = maxBatchSize
+    }
+    if config.Timeout <= 0 {
+        config.Timeout = processor.timeout
+    }
+
+    return &Pipeline{
+        processor: processor,
+        publisher: publisher,
+        config:    config,
+    }, nil
+}
+
+// SetDeadLetter configures where messages that cannot be decoded or
+// normalized are sent. Without one such messages fail their batch, so they
+// are redelivered rather than committed unhandled.
+func (p *Pipeline) SetDeadLetter(deadLetter DeadLetterPublisher) {
+    p.deadLetter = deadLetter
+}
+
+// HandleBatch normalizes and publishes one consumed batch. Messages are
+// decoded with the consumer's Serializer, and each event continues the trace
+// its message was published under. Messages that can never be decoded and
+// events that fail normalization are dead-lettered, since redelivery cannot
+// fix them; events shed by the configured Admission are dropped before
+// processing. Any other failure, including a decode error that may be
+// transient such as an unreachable schema registry, returns an error so the
+// batch is retried and not committed; events published before the failure
+// are published again on retry.
+func (p *Pipeline) HandleBatch(ctx context.Context, messages []*streaming.Message) error {
+    decoded := make([]*streaming.Message, 0, len(messages))
+    events := make([]*schema.BronzeEvent, 0, len(messages))
+    for _, msg := range messages {
+        var event schema.BronzeEvent
+        if err := msg.Decode(&event); err != nil {
+            if errors.Classify(err) != errors.Permanent {
+                p.addErrors(1)
+                return errors.WrapError(err, "failed to decode Bronze message", map[string]interface{}{
+                    "topic":     msg.Topic,
+                    "partition": msg.Partition,
+                    "offset":    msg.Offset,
+                })
+            }
+            if err := p.reject(ctx, msg, msg.Header(streaming.HeaderTenant), "decode", err); err != nil {
+                return err
+            }
+            bpmetrics.RecordLostEvent(bpmetrics.StageNormalize, bpmetrics.LossDeadLetter, msg.ID())
+            p.processor.logger.Warn("Dead-lettered undecodable Bronze message",
+                zap.String("topic", msg.Topic),
+                zap.Int32("partition", msg.Partition),
+                zap.Int64("offset", msg.Offset),
+                zap.Error(err),
+            )
+            continue
+        }
+        if p.config.Admission != nil && !p.config.Admission.Admit() {
+            continue
+        }
+        decoded = append(decoded, msg)
+        events = append(events, &event)
+    }
+
+    for start := 0; start < len(events); start += p.config.BatchSize {
+        end := start + p.config.BatchSize
+        if end > len(events) {
+            end = len(events)
+        }
+        if err := p.processBatch(ctx, decoded[start:end], events[start:end]); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// processBatch normalizes and publishes the events decoded from messages
+// within the pipeline timeout
+func (p *Pipeline) processBatch(ctx context.Context, messages []*streaming.Message, events []*schema.BronzeEvent) error {
+    start := time.Now()
+    ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+    defer cancel()
+    if p.config.SecurityContext != nil {
+        ctx = WithSecurityContext(ctx, p.config.SecurityContext)
+    }
+
+    // Each event's span continues its message's trace and is the parent the
+    // Silver message carries on to the analyzer
+    traces := make([]context.Context, len(messages))
+    spans := make([]trace.Span, len(messages))
+    for i, msg := range messages {
+        traces[i], spans[i] = p.processor.tracer.Start(msg.Context(), "normalize_message")
+    }
+    defer func() {
+        for _, span := range spans {
+            span.End()
+        }
+    }()
+
+    processed, eventErrs, err := p.processor.Process(WithEventTraces(ctx, traces), events)
+    if err != nil {
+        p.addErrors(len(events))
+        return errors.WrapError(err, "failed to normalize batch", map[string]interface{}{
+            "batch_size": len(events),
+        })
+    }
+
+    failed := make(map[int]bool, len(eventErrs))
+    for _, eventErr := range eventErrs {
+        if err := p.reject(ctx, messages[eventErr.Index], events[eventErr.Index].ClientID, "normalize", eventErr.Err); err != nil {
+            return err
+        }
+        failed[eventErr.Index] = true
+        p.processor.logger.Warn("Dead-lettered event that failed normalization",
+            zap.String("event_id", eventErr.ID),
+            zap.Error(eventErr.Err),
+        )
+    }
+
+    // Processed events keep input order, so they line up with the messages
+    // that did not fail
+    values := make([]interface{}, 0, len(processed))
+    headers := make([]map[string]string, 0, len(processed))
+    published := make([]string, 0, len(processed))
+    for i := range messages {
+        if failed[i] {
+            continue
+        }
+        values = append(values, processed[len(values)])
+        headers = append(headers, streaming.InjectTraceContext(traces[i], nil))
+        published = append(published, events[i].ID)
+    }
+
+    if len(values) > 0 {
+        if err := p.publisher.PublishValues(ctx, values, headers); err != nil {
+            p.addErrors(len(values))
+            return errors.WrapError(err, "failed to publish Silver events", map[string]interface{}{
+                "batch_size": len(values),
+            })
+        }
+    }
+
+    // Events count as produced only once the producer confirmed delivery;
+    // a redelivered batch counts them once
+    for _, eventID := range published {
+        bpmetrics.RecordProducedEvent(bpmetrics.StageNormalize, eventID)
+    }
+
+    if p.config.Processed != nil {
+        p.config.Processed.Add(float64(len(values)))
+    }
+    if p.config.Latency != nil {
+        p.config.Latency.Observe(time.Since(start).Seconds())
+    }
+    return nil
+}
+
+// reject dead-letters a message that cannot be normalized, counting it as an
+// error. Without a dead-letter publisher the message is not handled and an
+// error is returned so its batch is not committed.
+func (p *Pipeline) reject(ctx context.Context, msg *streaming.Message, clientID, stage string, reason error) error {
+    p.addErrors(1)
+    if p.deadLetter == nil {
+        return errors.WrapError(reason, "no dead-letter publisher for unprocessable message", map[string]interface{}{
+            "topic":     msg.Topic,
+            "partition": msg.Partition,
+            "offset":    msg.Offset,
+        })
+    }
+
+    headers := make(map[string]string, len(msg.Headers)+4)
+    for key, value := range msg.Headers {
+        headers[key] = value
+    }
+    headers[HeaderDeadLetterReason] = reason.Error()
+    headers[HeaderSourceTopic] = msg.Topic
+    headers[HeaderSourcePartition] = strconv.FormatInt(int64(msg.Partition), 10)
+    headers[HeaderSourceOffset] = strconv.FormatInt(msg.Offset, 10)
+
+    if err := p.deadLetter.PublishWithHeaders(ctx, msg.Value, headers); err != nil {
+        return errors.WrapError(err, "failed to dead-letter message", map[string]interface{}{
+            "topic":  msg.Topic,
+            "offset": msg.Offset,
+        })
+    }
+
+    // Client IDs come from event data, so their label values are capped
+    if clientID == "" {
+        clientID = unknownClientLabel
+    }
+    clientLabel := bpmetrics.Guard(DeadLetterMetric).Value("client_id", clientID)
+    deadLettered.WithLabelValues(clientLabel, stage).Inc()
+    return nil
+}
+
+// addErrors counts failed events
+func (p *Pipeline) addErrors(count int) {
+    if p.config.Errors != nil {
+        p.config.Errors.Add(float64(count))
+    }
+}
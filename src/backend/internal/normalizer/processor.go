@@ -3,12 +3,14 @@ package normalizer
 
 import (
     "context"
+    "fmt"
     "sync"
     "time"
 
     "github.com/blackpoint/pkg/bronze/schema"
     "github.com/blackpoint/pkg/silver/schema"
     "github.com/blackpoint/pkg/common/errors"
+    bpmetrics "github.com/blackpoint/internal/metrics"
     "go.opentelemetry.io/otel"
     "go.opentelemetry.io/otel/attribute"
     "go.opentelemetry.io/otel/trace"
@@ -33,9 +35,43 @@ type Processor struct {
     tracer          trace.Tracer
     workerPool      chan struct{}
     metrics         *processorMetrics
+    stages          *stageRecorder
+    ecsMapper       *ECSMapper
+    enrichment      *EnrichmentPipeline
+    migrator        *SchemaMigrator
     mu              sync.RWMutex
 }
 
+// securityContextKey carries a caller-supplied security context for ProcessSingle
+type securityContextKey struct{}
+
+// WithSecurityContext returns a context that makes ProcessSingle apply
+// secCtx to the event instead of the default internal classification
+func WithSecurityContext(ctx context.Context, secCtx *schema.SecurityContext) context.Context {
+    return context.WithValue(ctx, securityContextKey{}, secCtx)
+}
+
+// eventTracesKey carries the trace context of each event of a batch
+type eventTracesKey struct{}
+
+// WithEventTraces returns a context under which Process continues a separate
+// trace for each event: traces[i] is the parent of events[i]'s spans, such
+// as the trace its Kafka message was published under
+func WithEventTraces(ctx context.Context, traces []context.Context) context.Context {
+    return context.WithValue(ctx, eventTracesKey{}, traces)
+}
+
+// defaultSecurityContext is applied when the caller does not supply one
+func defaultSecurityContext() *schema.SecurityContext {
+    return &schema.SecurityContext{
+        Classification: "INTERNAL",
+        Sensitivity:   "MEDIUM",
+        Compliance:    []string{"DEFAULT"},
+        Encryption:    make(map[string]string),
+        AccessControl: make(map[string]string),
+    }
+}
+
 // processorMetrics tracks performance and operational metrics
 type processorMetrics struct {
     eventsProcessed    *zap.Counter
@@ -72,17 +108,44 @@ func NewProcessor(mapper *FieldMapper, transformer *Transformer, timeout time.Du
         tracer:      otel.Tracer("normalizer.processor"),
         workerPool:  make(chan struct{}, workerPoolSize),
         metrics:     metrics,
+        stages:      newStageRecorder(),
     }, nil
 }
 
-// Process handles batch processing of Bronze events with concurrent execution
-func (p *Processor) Process(ctx context.Context, events []*schema.BronzeEvent) ([]*schema.SilverEvent, error) {
+// EventError records why one event of a batch failed to normalize
+type EventError struct {
+    // Index is the event's position in the input batch
+    Index int
+    // ID is the Bronze event ID
+    ID  string
+    Err error
+}
+
+// Error implements the error interface
+func (e EventError) Error() string {
+    return fmt.Sprintf("event %d (%s): %v", e.Index, e.ID, e.Err)
+}
+
+// Unwrap returns the underlying processing error
+func (e EventError) Unwrap() error {
+    return e.Err
+}
+
+// Process handles batch processing of Bronze events with concurrent execution.
+// Normalized events are returned in input order regardless of which worker
+// finishes first. Events that fail are omitted without reordering the rest
+// and reported as EventErrors, also in input order, so callers can commit the
+// good events and route the failures to a dead letter queue. The error is
+// reserved for failures of the batch as a whole.
+func (p *Processor) Process(ctx context.Context, events []*schema.BronzeEvent) ([]*schema.SilverEvent, []EventError, error) {
     if len(events) == 0 {
-        return nil, nil
+        return nil, nil, nil
     }
 
     if len(events) > maxBatchSize {
-        return nil, errors.NewError("E4001", "batch size exceeds maximum", map[string]interface{}{
+        bpmetrics.RecordIngested(bpmetrics.StageNormalize, len(events))
+        bpmetrics.RecordLoss(bpmetrics.StageNormalize, bpmetrics.LossValidationReject, len(events))
+        return nil, nil, errors.NewError("E4001", "batch size exceeds maximum", map[string]interface{}{
             "max_size":     maxBatchSize,
             "actual_size": len(events),
         })
@@ -93,62 +156,140 @@ func (p *Processor) Process(ctx context.Context, events []*schema.BronzeEvent) (
 
     p.metrics.batchSize.Set(float64(len(events)))
 
-    // Create processing channels
-    results := make(chan *schema.SilverEvent, len(events))
-    errs := make(chan error, len(events))
+    traces, _ := ctx.Value(eventTracesKey{}).([]context.Context)
+    if len(traces) != len(events) {
+        traces = nil
+    }
+
+    // Each worker writes only its own index, so no locking is needed
+    results := make([]*schema.SilverEvent, len(events))
+    errs := make([]error, len(events))
     var wg sync.WaitGroup
 
     // Process events concurrently
-    for _, event := range events {
+    for i, event := range events {
         wg.Add(1)
-        go func(evt *schema.BronzeEvent) {
+        go func(idx int, evt *schema.BronzeEvent) {
             defer wg.Done()
 
             // Acquire worker from pool
             p.workerPool <- struct{}{}
             defer func() { <-p.workerPool }()
 
-            silverEvent, err := p.ProcessSingle(ctx, evt)
-            if err != nil {
-                errs <- err
-                return
+            eventCtx := ctx
+            if traces != nil {
+                if parent := trace.SpanContextFromContext(traces[idx]); parent.IsValid() {
+                    eventCtx = trace.ContextWithSpanContext(ctx, parent)
+                }
             }
-            results <- silverEvent
-        }(event)
+            results[idx], errs[idx] = p.ProcessSingle(eventCtx, evt)
+        }(i, event)
     }
 
     // Wait for all processing to complete
     wg.Wait()
-    close(results)
-    close(errs)
 
-    // Collect results and errors
-    var processedEvents []*schema.SilverEvent
-    var processingErrors []error
+    // Collect results and errors in input order
+    processedEvents := make([]*schema.SilverEvent, 0, len(events))
+    var eventErrors []EventError
 
-    for err := range errs {
-        processingErrors = append(processingErrors, err)
+    for i, event := range events {
+        if errs[i] != nil {
+            eventErrors = append(eventErrors, EventError{Index: i, ID: event.ID, Err: errs[i]})
+            continue
+        }
+        processedEvents = append(processedEvents, results[i])
     }
 
-    for result := range results {
-        processedEvents = append(processedEvents, result)
+    span.SetAttributes(
+        attribute.Int("processed_events", len(processedEvents)),
+        attribute.Int("failed_events", len(eventErrors)),
+    )
+    if len(eventErrors) > 0 {
+        p.metrics.processingErrors.Add(float64(len(eventErrors)))
     }
+    p.metrics.eventsProcessed.Add(float64(len(processedEvents)))
+    return processedEvents, eventErrors, nil
+}
 
-    // Handle processing errors
-    if len(processingErrors) > 0 {
-        p.metrics.processingErrors.Add(float64(len(processingErrors)))
-        return processedEvents, errors.NewError("E4001", "batch processing partially failed", map[string]interface{}{
-            "total_events": len(events),
-            "failed_events": len(processingErrors),
-            "first_error": processingErrors[0].Error(),
-        })
+// Drain waits until no events are being processed. Workers are held while
+// draining, so new work blocks until Drain returns.
+func (p *Processor) Drain(ctx context.Context) error {
+    acquired := 0
+    defer func() {
+        for i := 0; i < acquired; i++ {
+            <-p.workerPool
+        }
+    }()
+
+    for acquired < cap(p.workerPool) {
+        select {
+        case p.workerPool <- struct{}{}:
+            acquired++
+        case <-ctx.Done():
+            return errors.WrapError(ctx.Err(), "timed out draining in-flight events", map[string]interface{}{
+                "in_flight": cap(p.workerPool) - acquired,
+            })
+        }
     }
+    return nil
+}
 
-    p.metrics.eventsProcessed.Add(float64(len(processedEvents)))
-    return processedEvents, nil
+// QueueDepth returns how many events are being processed and how many
+// workers the processor has
+func (p *Processor) QueueDepth() (depth, capacity int) {
+    return len(p.workerPool), cap(p.workerPool)
 }
 
-// ProcessSingle handles processing of a single Bronze event with retries
+// GetMetrics returns processed and failed event counts with recent timings
+// of the mapping, transformation and encryption stages
+func (p *Processor) GetMetrics() *ProcessorMetrics {
+    return p.stages.snapshot()
+}
+
+// SetEnrichment configures the enrichers applied to transformed events; nil disables enrichment
+func (p *Processor) SetEnrichment(pipeline *EnrichmentPipeline) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    p.enrichment = pipeline
+}
+
+// SetSchemaMigrator configures the migrator that upgrades events from older
+// schema versions before mapping; nil disables migration
+func (p *Processor) SetSchemaMigrator(migrator *SchemaMigrator) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    p.migrator = migrator
+}
+
+// SetOutputMode selects the field layout of produced Silver events. In ECS
+// mode the mapping overrides are applied on top of DefaultECSMapping.
+func (p *Processor) SetOutputMode(mode string, ecsMapping map[string]string) error {
+    switch mode {
+    case OutputModeNative, "":
+        p.mu.Lock()
+        p.ecsMapper = nil
+        p.mu.Unlock()
+        return nil
+    case OutputModeECS:
+        ecsMapper, err := NewECSMapper(ecsMapping)
+        if err != nil {
+            return err
+        }
+        p.mu.Lock()
+        p.ecsMapper = ecsMapper
+        p.mu.Unlock()
+        return nil
+    default:
+        return errors.NewError("E2001", "unsupported output mode", map[string]interface{}{
+            "mode": mode,
+        })
+    }
+}
+
+// ProcessSingle handles processing of a single Bronze event with retries.
+// Failures settle the event in the ledger; callers record it as produced once
+// the Silver event is delivered.
 func (p *Processor) ProcessSingle(ctx context.Context, event *schema.BronzeEvent) (*schema.SilverEvent, error) {
     ctx, span := p.tracer.Start(ctx, "process_single")
     defer span.End()
@@ -163,7 +304,39 @@ func (p *Processor) ProcessSingle(ctx context.Context, event *schema.BronzeEvent
         p.metrics.processingLatency.Observe(time.Since(startTime).Seconds())
     }()
 
+    bpmetrics.RecordIngestedEvent(bpmetrics.StageNormalize, event.ID)
+
+    // An invalid security context fails the same way on every attempt, so it
+    // is rejected before transformation rather than retried
+    securityContext := defaultSecurityContext()
+    if supplied, ok := ctx.Value(securityContextKey{}).(*schema.SecurityContext); ok {
+        securityContext = supplied
+    }
+    if err := securityContext.Validate(); err != nil {
+        p.metrics.processingErrors.Inc()
+        p.stages.recordResult(err)
+        bpmetrics.RecordLostEvent(bpmetrics.StageNormalize, bpmetrics.LossValidationReject, event.ID)
+        return nil, err
+    }
+
+    // Upgrade events from older schemas before mapping; like the security
+    // context, a failed migration would fail every attempt
+    p.mu.RLock()
+    migrator := p.migrator
+    p.mu.RUnlock()
+    if migrator != nil {
+        migrated, err := migrator.Migrate(event)
+        if err != nil {
+            p.metrics.processingErrors.Inc()
+            p.stages.recordResult(err)
+            bpmetrics.RecordLostEvent(bpmetrics.StageNormalize, bpmetrics.LossValidationReject, event.ID)
+            return nil, err
+        }
+        event = migrated
+    }
+
     var silverEvent *schema.SilverEvent
+    var lossReason string
     var processingErr error
 
     // Retry logic with exponential backoff
@@ -173,7 +346,7 @@ func (p *Processor) ProcessSingle(ctx context.Context, event *schema.BronzeEvent
             time.Sleep(time.Duration(attempt) * retryBackoff)
         }
 
-        silverEvent, processingErr = p.processEventWithTimeout(ctx, event)
+        silverEvent, lossReason, processingErr = p.processEventWithTimeout(ctx, event, securityContext)
         if processingErr == nil {
             break
         }
@@ -187,45 +360,84 @@ func (p *Processor) ProcessSingle(ctx context.Context, event *schema.BronzeEvent
 
     if processingErr != nil {
         p.metrics.processingErrors.Inc()
+        p.stages.recordResult(processingErr)
+        if ctx.Err() != nil {
+            lossReason = bpmetrics.LossCancelled
+        }
+        bpmetrics.RecordLostEvent(bpmetrics.StageNormalize, lossReason, event.ID)
         return nil, errors.WrapError(processingErr, "processing failed after retries", map[string]interface{}{
             "event_id": event.ID,
             "retries": maxRetries,
         })
     }
 
+    p.stages.recordResult(nil)
     return silverEvent, nil
 }
 
-// processEventWithTimeout handles the core event processing with timeout
-func (p *Processor) processEventWithTimeout(ctx context.Context, event *schema.BronzeEvent) (*schema.SilverEvent, error) {
+// processEventWithTimeout handles the core event processing with timeout. On
+// failure it also returns the ledger loss reason for the failed step.
+func (p *Processor) processEventWithTimeout(ctx context.Context, event *schema.BronzeEvent, securityContext *schema.SecurityContext) (*schema.SilverEvent, string, error) {
     ctx, cancel := context.WithTimeout(ctx, p.timeout)
     defer cancel()
 
     // Map fields
+    _, mapSpan := p.tracer.Start(ctx, "map_fields")
+    stageStart := time.Now()
     mappedFields, err := p.mapper.MapEvent(event)
+    mapSpan.End()
     if err != nil {
-        return nil, errors.WrapError(err, "field mapping failed", nil)
+        return nil, bpmetrics.LossTransformFailure, errors.WrapError(err, "field mapping failed", nil)
     }
+    p.stages.observe(StageMapping, time.Since(stageStart))
 
-    // Create security context
-    securityContext := &schema.SecurityContext{
-        Classification: "INTERNAL",
-        Sensitivity:   "MEDIUM",
-        Compliance:    []string{"DEFAULT"},
-        Encryption:    make(map[string]string),
-        AccessControl: make(map[string]string),
+    // Transform event; encryption of sensitive fields is timed on its own
+    _, transformSpan := p.tracer.Start(ctx, "transform_fields")
+    stageStart = time.Now()
+    silverEvent, encryptionTime, err := p.transformer.transformEvent(event, mappedFields.NormalizedData, securityContext)
+    transformSpan.End()
+    if err != nil {
+        return nil, bpmetrics.LossTransformFailure, errors.WrapError(err, "event transformation failed", nil)
+    }
+    p.stages.observe(StageTransformation, time.Since(stageStart)-encryptionTime)
+    if len(silverEvent.EncryptedFields) > 0 {
+        p.stages.observe(StageEncryption, encryptionTime)
     }
 
-    // Transform event
-    silverEvent, err := p.transformer.TransformEvent(event, mappedFields.NormalizedData, securityContext)
-    if err != nil {
-        return nil, errors.WrapError(err, "event transformation failed", nil)
+    p.mu.RLock()
+    enrichment := p.enrichment
+    p.mu.RUnlock()
+
+    // Enrichment is best effort: a failed lookup must not drop the event
+    if enrichment != nil {
+        if err := enrichment.Run(ctx, silverEvent); err != nil {
+            p.logger.Warn("Event enrichment incomplete",
+                zap.String("event_id", silverEvent.EventID),
+                zap.Error(err),
+            )
+        }
     }
 
     // Validate processed event
     if err := silverEvent.Validate(); err != nil {
-        return nil, errors.WrapError(err, "event validation failed", nil)
+        return nil, bpmetrics.LossValidationReject, errors.WrapError(err, "event validation failed", nil)
     }
 
-    return silverEvent, nil
+    p.mu.RLock()
+    ecsMapper := p.ecsMapper
+    p.mu.RUnlock()
+
+    // Re-shape normalized fields as an ECS document when configured
+    if ecsMapper != nil {
+        ecsData, err := ecsMapper.ToECS(silverEvent.NormalizedData)
+        if err != nil {
+            return nil, bpmetrics.LossTransformFailure, errors.WrapError(err, "ECS mapping failed", nil)
+        }
+        if err := ecsMapper.ValidateECS(ecsData); err != nil {
+            return nil, bpmetrics.LossValidationReject, errors.WrapError(err, "ECS validation failed", nil)
+        }
+        silverEvent.NormalizedData = ecsData
+    }
+
+    return silverEvent, "", nil
 }
\ No newline at end of file
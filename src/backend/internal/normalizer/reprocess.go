@@ -0,0 +1,450 @@
+// Package normalizer provides reprocessing of archived Bronze events through the Silver pipeline
+package normalizer
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/url"
+    "os"
+    "path/filepath"
+    "sort"
+    "sync"
+    "time"
+
+    "go.uber.org/zap"
+
+    bpmetrics "github.com/blackpoint/internal/metrics"
+    "github.com/blackpoint/internal/storage"
+    "github.com/blackpoint/pkg/bronze/schema"
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// Default number of archive objects reprocessed concurrently
+const defaultReprocessConcurrency = 4
+
+// Tier partition holding archived Bronze objects when none is configured
+const defaultBronzeArchiveTier = "bronze"
+
+// BronzeArchive lists and reads archived Bronze objects; the storage
+// package's S3Client satisfies it
+type BronzeArchive interface {
+    ListObjects(bucket, prefix string) ([]string, error)
+    GetObject(bucket, key string) ([]byte, error)
+}
+
+// SilverPublisher publishes Silver events; the streaming package's Producer
+// satisfies it. PublishValues encodes events with the producer's Serializer
+// and attaches headers[i], when given, to values[i].
+type SilverPublisher interface {
+    PublishValues(ctx context.Context, values []interface{}, headers []map[string]string) error
+}
+
+// ReprocessCheckpoint persists how far a reprocessing job has got. Save is
+// called with the last archive key below which every object is complete.
+type ReprocessCheckpoint interface {
+    Load(jobID string) (string, error)
+    Save(jobID, key string) error
+}
+
+// ReprocessConfig selects the archived events to reprocess
+type ReprocessConfig struct {
+    // Bucket and Prefix locate the Bronze archive; Prefix is the tier
+    // partition the archive is stored under, "bronze" when empty
+    Bucket string
+    Prefix string
+
+    // ClientID and [From, To) select the events to reprocess
+    ClientID string
+    From     time.Time
+    To       time.Time
+
+    // Concurrency is the number of archive objects processed at once
+    Concurrency int
+
+    // DryRun reports counts without publishing or saving checkpoints
+    DryRun bool
+
+    // JobID names the checkpoint; derived from the selection when empty
+    JobID string
+}
+
+// ReprocessResult summarizes a reprocessing run
+type ReprocessResult struct {
+    JobID          string `json:"job_id"`
+    DryRun         bool   `json:"dry_run"`
+    Objects        int    `json:"objects"`
+    ObjectsResumed int    `json:"objects_skipped_by_checkpoint"`
+    Events         int    `json:"events"`
+    Normalized     int    `json:"normalized"`
+    Failed         int    `json:"failed"`
+    Published      int    `json:"published"`
+    Checkpoint     string `json:"checkpoint,omitempty"`
+}
+
+// Reprocessor feeds archived Bronze events through the current normalization
+// pipeline and back onto the Silver topic
+type Reprocessor struct {
+    archive     BronzeArchive
+    processor   *Processor
+    publisher   SilverPublisher
+    checkpoints ReprocessCheckpoint
+}
+
+// objectResult is the outcome of reprocessing one archive object
+type objectResult struct {
+    index      int
+    events     int
+    normalized int
+    failed     int
+    published  int
+    err        error
+}
+
+// NewReprocessor creates a Reprocessor. The publisher may be nil when only
+// dry runs are made; checkpoints may be nil when runs need not be resumable.
+func NewReprocessor(archive BronzeArchive, processor *Processor, publisher SilverPublisher, checkpoints ReprocessCheckpoint) (*Reprocessor, error) {
+    if archive == nil || processor == nil {
+        return nil, errors.NewError("E2001", "reprocessor requires an archive and a processor", nil)
+    }
+    return &Reprocessor{
+        archive:     archive,
+        processor:   processor,
+        publisher:   publisher,
+        checkpoints: checkpoints,
+    }, nil
+}
+
+// BronzeArchivePrefix returns the archive prefix holding the objects stored
+// on the UTC day containing t, using the storage package's partition layout
+// {prefix}/YYYY/MM/DD/. Objects are partitioned by the time they were
+// archived rather than by client, so events are selected individually.
+func BronzeArchivePrefix(prefix string, t time.Time) string {
+    return storage.PartitionPrefix(prefix, t)
+}
+
+// Run reprocesses the archived events selected by cfg. Objects are processed
+// in key order and the checkpoint only advances past an object once it and
+// every object before it are complete, so a run restarted after a crash
+// resumes where it stopped. Events that fail normalization are counted and
+// skipped; failing to read or publish an object stops the run.
+func (r *Reprocessor) Run(ctx context.Context, cfg ReprocessConfig) (*ReprocessResult, error) {
+    if cfg.Bucket == "" || cfg.ClientID == "" {
+        return nil, errors.NewError("E3001", "reprocessing requires a bucket and client ID", nil)
+    }
+    if !cfg.To.After(cfg.From) {
+        return nil, errors.NewError("E3001", "invalid reprocessing time range", map[string]interface{}{
+            "from": cfg.From,
+            "to":   cfg.To,
+        })
+    }
+    if !cfg.DryRun && r.publisher == nil {
+        return nil, errors.NewError("E2001", "silver publisher not configured", nil)
+    }
+    if cfg.Concurrency <= 0 {
+        cfg.Concurrency = defaultReprocessConcurrency
+    }
+    if cfg.Prefix == "" {
+        cfg.Prefix = defaultBronzeArchiveTier
+    }
+    if cfg.JobID == "" {
+        cfg.JobID = fmt.Sprintf("%s-%d-%d", cfg.ClientID, cfg.From.Unix(), cfg.To.Unix())
+    }
+
+    result := &ReprocessResult{JobID: cfg.JobID, DryRun: cfg.DryRun}
+
+    keys, err := r.listObjects(cfg)
+    if err != nil {
+        return nil, err
+    }
+
+    // Skip objects completed by an earlier run
+    if r.checkpoints != nil {
+        checkpoint, err := r.checkpoints.Load(cfg.JobID)
+        if err != nil {
+            return nil, errors.WrapError(err, "failed to load reprocessing checkpoint", map[string]interface{}{
+                "job_id": cfg.JobID,
+            })
+        }
+        if checkpoint != "" {
+            resumeAt := sort.SearchStrings(keys, checkpoint)
+            if resumeAt < len(keys) && keys[resumeAt] == checkpoint {
+                resumeAt++
+            }
+            result.ObjectsResumed = resumeAt
+            result.Checkpoint = checkpoint
+            keys = keys[resumeAt:]
+        }
+    }
+    result.Objects = len(keys)
+
+    runErr := r.processObjects(ctx, cfg, keys, result)
+
+    r.processor.logger.Info("Bronze reprocessing finished",
+        zap.String("job_id", cfg.JobID),
+        zap.String("client_id", cfg.ClientID),
+        zap.Bool("dry_run", cfg.DryRun),
+        zap.Int("objects", result.Objects),
+        zap.Int("events", result.Events),
+        zap.Int("normalized", result.Normalized),
+        zap.Int("failed", result.Failed),
+        zap.Int("published", result.Published),
+        zap.Error(runErr),
+    )
+
+    return result, runErr
+}
+
+// listObjects returns the sorted archive keys of the days covering the
+// configured range
+func (r *Reprocessor) listObjects(cfg ReprocessConfig) ([]string, error) {
+    var keys []string
+    for day := cfg.From.UTC().Truncate(24 * time.Hour); day.Before(cfg.To); day = day.Add(24 * time.Hour) {
+        prefix := BronzeArchivePrefix(cfg.Prefix, day)
+        hourKeys, err := r.archive.ListObjects(cfg.Bucket, prefix)
+        if err != nil {
+            return nil, errors.WrapError(err, "failed to list archived events", map[string]interface{}{
+                "bucket": cfg.Bucket,
+                "prefix": prefix,
+            })
+        }
+        keys = append(keys, hourKeys...)
+    }
+    sort.Strings(keys)
+    return keys, nil
+}
+
+// processObjects reprocesses keys with bounded concurrency, advancing the
+// checkpoint over the contiguous run of completed objects
+func (r *Reprocessor) processObjects(ctx context.Context, cfg ReprocessConfig, keys []string, result *ReprocessResult) error {
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+
+    jobs := make(chan int)
+    done := make(chan objectResult)
+
+    var wg sync.WaitGroup
+    for i := 0; i < cfg.Concurrency; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for idx := range jobs {
+                res := r.processObject(ctx, cfg, keys[idx])
+                res.index = idx
+                done <- res
+            }
+        }()
+    }
+
+    go func() {
+        defer close(jobs)
+        for idx := range keys {
+            select {
+            case jobs <- idx:
+            case <-ctx.Done():
+                return
+            }
+        }
+    }()
+
+    go func() {
+        wg.Wait()
+        close(done)
+    }()
+
+    completed := make([]bool, len(keys))
+    next := 0
+    var firstErr error
+    for res := range done {
+        result.Events += res.events
+        result.Normalized += res.normalized
+        result.Failed += res.failed
+        result.Published += res.published
+
+        if res.err != nil {
+            if firstErr == nil {
+                firstErr = res.err
+                cancel()
+            }
+            continue
+        }
+        completed[res.index] = true
+
+        advanced := false
+        for next < len(keys) && completed[next] {
+            next++
+            advanced = true
+        }
+        if !advanced || cfg.DryRun || r.checkpoints == nil {
+            continue
+        }
+        if err := r.checkpoints.Save(cfg.JobID, keys[next-1]); err != nil {
+            if firstErr == nil {
+                firstErr = errors.WrapError(err, "failed to save reprocessing checkpoint", map[string]interface{}{
+                    "job_id": cfg.JobID,
+                })
+                cancel()
+            }
+            continue
+        }
+        result.Checkpoint = keys[next-1]
+    }
+
+    if firstErr == nil && next < len(keys) {
+        firstErr = errors.WrapError(ctx.Err(), "bronze reprocessing cancelled", map[string]interface{}{
+            "job_id": cfg.JobID,
+        })
+    }
+    return firstErr
+}
+
+// processObject normalizes the selected events of one archive object and,
+// outside dry runs, publishes them to the Silver topic
+func (r *Reprocessor) processObject(ctx context.Context, cfg ReprocessConfig, key string) objectResult {
+    var res objectResult
+
+    data, err := r.archive.GetObject(cfg.Bucket, key)
+    if err != nil {
+        res.err = err
+        return res
+    }
+    events, err := decodeBronzeObject(data)
+    if err != nil {
+        res.err = errors.WrapError(err, "failed to decode archived events", map[string]interface{}{
+            "key": key,
+        })
+        return res
+    }
+
+    // Daily partitions hold every client and may straddle the range bounds
+    selected := events[:0]
+    for _, event := range events {
+        if event.ClientID != cfg.ClientID || event.Timestamp.Before(cfg.From) || !event.Timestamp.Before(cfg.To) {
+            continue
+        }
+        selected = append(selected, event)
+    }
+    res.events = len(selected)
+
+    for start := 0; start < len(selected); start += maxBatchSize {
+        end := start + maxBatchSize
+        if end > len(selected) {
+            end = len(selected)
+        }
+
+        processed, eventErrs, err := r.processor.Process(ctx, selected[start:end])
+        if err != nil {
+            res.err = errors.WrapError(err, "failed to reprocess archived events", map[string]interface{}{
+                "key": key,
+            })
+            return res
+        }
+        // Events failed by cancellation must not be checkpointed as done
+        if err := ctx.Err(); err != nil {
+            res.err = err
+            return res
+        }
+        res.normalized += len(processed)
+        res.failed += len(eventErrs)
+
+        if cfg.DryRun || len(processed) == 0 {
+            continue
+        }
+
+        // Publish through the producer's Serializer, as the live pipeline does
+        values := make([]interface{}, len(processed))
+        for i, event := range processed {
+            values[i] = event
+        }
+        if err := r.publisher.PublishValues(ctx, values, nil); err != nil {
+            res.err = errors.WrapError(err, "failed to publish reprocessed events", map[string]interface{}{
+                "key": key,
+            })
+            return res
+        }
+        res.published += len(values)
+
+        // Reprocessed events count as produced once delivered
+        failed := make(map[int]bool, len(eventErrs))
+        for _, eventErr := range eventErrs {
+            failed[eventErr.Index] = true
+        }
+        for i, event := range selected[start:end] {
+            if !failed[i] {
+                bpmetrics.RecordProducedEvent(bpmetrics.StageNormalize, event.ID)
+            }
+        }
+    }
+
+    return res
+}
+
+// decodeBronzeObject decodes an archive object holding either a JSON array of
+// Bronze events or newline-delimited events
+func decodeBronzeObject(data []byte) ([]*schema.BronzeEvent, error) {
+    trimmed := bytes.TrimSpace(data)
+    if len(trimmed) > 0 && trimmed[0] == '[' {
+        var events []*schema.BronzeEvent
+        if err := json.Unmarshal(trimmed, &events); err != nil {
+            return nil, err
+        }
+        return events, nil
+    }
+
+    var events []*schema.BronzeEvent
+    decoder := json.NewDecoder(bytes.NewReader(trimmed))
+    for {
+        var event schema.BronzeEvent
+        if err := decoder.Decode(&event); err == io.EOF {
+            return events, nil
+        } else if err != nil {
+            return nil, err
+        }
+        events = append(events, &event)
+    }
+}
+
+// FileCheckpoint stores reprocessing checkpoints as files in a directory
+type FileCheckpoint struct {
+    dir string
+}
+
+// NewFileCheckpoint creates a checkpoint store under dir, creating it if needed
+func NewFileCheckpoint(dir string) (*FileCheckpoint, error) {
+    if err := os.MkdirAll(dir, 0o700); err != nil {
+        return nil, errors.WrapError(err, "failed to create checkpoint directory", map[string]interface{}{
+            "dir": dir,
+        })
+    }
+    return &FileCheckpoint{dir: dir}, nil
+}
+
+// Load returns the saved checkpoint for a job, or "" when there is none
+func (c *FileCheckpoint) Load(jobID string) (string, error) {
+    data, err := os.ReadFile(c.path(jobID))
+    if os.IsNotExist(err) {
+        return "", nil
+    }
+    if err != nil {
+        return "", err
+    }
+    return string(data), nil
+}
+
+// Save replaces a job's checkpoint; the file is renamed into place so a crash
+// mid-write leaves the previous checkpoint intact
+func (c *FileCheckpoint) Save(jobID, key string) error {
+    path := c.path(jobID)
+    tmp := path + ".tmp"
+    if err := os.WriteFile(tmp, []byte(key), 0o600); err != nil {
+        return err
+    }
+    return os.Rename(tmp, path)
+}
+
+// path returns the checkpoint file for a job
+func (c *FileCheckpoint) path(jobID string) string {
+    return filepath.Join(c.dir, url.PathEscape(jobID)+".checkpoint")
+}
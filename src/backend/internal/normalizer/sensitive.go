@@ -0,0 +1,133 @@
+// Package normalizer provides sensitive field detection for event transformation
+package normalizer
+
+import (
+    "regexp"
+    "sort"
+    "strings"
+    "unicode"
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// DefaultSensitivePatterns name the field name patterns encrypted by default.
+// Field names are converted to snake_case before matching, so "accessToken"
+// is matched as "access_token". Each pattern matches whole name segments
+// separated by '_', '.' or '-', so "api_key" and "auth.password" match while
+// "keyboard_layout" does not. Tokens and keys must be the last segment,
+// leaving "token_count" and "api_key_id" in clear.
+var DefaultSensitivePatterns = map[string]string{
+    "password":    `(?i)(^|[_.-])(password|passwd|pwd|passphrase)($|[_.-])`,
+    "secret":      `(?i)(^|[_.-])(client_)?secret($|[_.-])`,
+    "token":       `(?i)(^|[_.-])(access_|refresh_|auth_|id_|session_|bearer_)?tokens?$`,
+    "key":         `(?i)(^key$|(^|[_.-])(api|access|secret|private|encryption|signing|ssh)[_.-]?key$)`,
+    "credentials": `(?i)(^|[_.-])credentials?($|[_.-])`,
+}
+
+// SensitiveFieldConfig selects the fields the transformer encrypts
+type SensitiveFieldConfig struct {
+    // Patterns maps a pattern name to a regular expression matched against
+    // field names. Patterns extend the defaults; a pattern with the name of a
+    // default replaces it.
+    Patterns map[string]string
+
+    // AllowList names fields that are never encrypted, even when a pattern
+    // matches. Names are compared exactly.
+    AllowList []string
+}
+
+// sensitivePattern is a compiled, named field name pattern
+type sensitivePattern struct {
+    name   string
+    regexp *regexp.Regexp
+}
+
+// sensitiveFieldMatcher decides which fields are encrypted
+type sensitiveFieldMatcher struct {
+    patterns []sensitivePattern
+    allowed  map[string]bool
+}
+
+// defaultSensitiveFields is the matcher used until SetSensitiveFields is called
+var defaultSensitiveFields = mustCompileSensitiveFields(SensitiveFieldConfig{})
+
+// compileSensitiveFields compiles the default and configured patterns once
+func compileSensitiveFields(config SensitiveFieldConfig) (*sensitiveFieldMatcher, error) {
+    sources := make(map[string]string, len(DefaultSensitivePatterns)+len(config.Patterns))
+    for name, pattern := range DefaultSensitivePatterns {
+        sources[name] = pattern
+    }
+    for name, pattern := range config.Patterns {
+        sources[name] = pattern
+    }
+
+    names := make([]string, 0, len(sources))
+    for name := range sources {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    matcher := &sensitiveFieldMatcher{allowed: make(map[string]bool, len(config.AllowList))}
+    for _, name := range names {
+        if strings.TrimSpace(sources[name]) == "" {
+            return nil, errors.NewError("E3001", "empty sensitive field pattern: "+name, map[string]interface{}{
+                "pattern": name,
+            })
+        }
+        re, err := regexp.Compile(sources[name])
+        if err != nil {
+            return nil, errors.WrapError(err, "invalid sensitive field pattern: "+name, map[string]interface{}{
+                "pattern": name,
+            })
+        }
+        matcher.patterns = append(matcher.patterns, sensitivePattern{name: name, regexp: re})
+    }
+    for _, field := range config.AllowList {
+        matcher.allowed[field] = true
+    }
+
+    return matcher, nil
+}
+
+// mustCompileSensitiveFields compiles a matcher, panicking on invalid patterns
+func mustCompileSensitiveFields(config SensitiveFieldConfig) *sensitiveFieldMatcher {
+    matcher, err := compileSensitiveFields(config)
+    if err != nil {
+        panic(err)
+    }
+    return matcher
+}
+
+// match returns the name of the first pattern matching a field
+func (m *sensitiveFieldMatcher) match(field string) (string, bool) {
+    if m.allowed[field] {
+        return "", false
+    }
+    name := snakeCase(field)
+    for _, pattern := range m.patterns {
+        if pattern.regexp.MatchString(name) {
+            return pattern.name, true
+        }
+    }
+    return "", false
+}
+
+// snakeCase converts camelCase and PascalCase names to snake_case, keeping
+// acronyms together: "clientSecret" becomes "client_secret" and "APIKey"
+// becomes "api_key". Names already in snake_case are only lowercased.
+func snakeCase(name string) string {
+    runes := []rune(name)
+    var b strings.Builder
+    b.Grow(len(name) + 4)
+    for i, r := range runes {
+        if unicode.IsUpper(r) && i > 0 {
+            prev := runes[i-1]
+            nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+            if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+                b.WriteByte('_')
+            }
+        }
+        b.WriteRune(unicode.ToLower(r))
+    }
+    return b.String()
+}
@@ -3,6 +3,7 @@ package normalizer
 
 import (
     "encoding/json"
+    "sort"
     "sync"
     "time"
 
@@ -23,21 +24,36 @@ const (
     maxConcurrentTransforms = 100
 )
 
-// Sensitive field patterns that require encryption
-var sensitiveFieldPatterns = []string{
-    "password",
-    "key",
-    "token",
-    "secret",
-}
+// Field failure policies applied when a field cannot be transformed
+const (
+    // FailurePolicyFailEvent rejects the whole event
+    FailurePolicyFailEvent = "fail_event"
+
+    // FailurePolicySkipField drops the field and records it in the event's TransformErrors
+    FailurePolicySkipField = "skip_field"
+)
 
 // TransformFunc represents a field transformation function
 type TransformFunc func(interface{}) (interface{}, error)
 
+// TransformPredicate decides from the whole record whether a conditional
+// transformer applies. It must not modify the record.
+type TransformPredicate func(event map[string]interface{}) bool
+
+// conditionalTransformer is a transformer applied only when its predicate holds
+type conditionalTransformer struct {
+    predicate TransformPredicate
+    fn        TransformFunc
+}
+
 // Transformer handles secure event transformation with monitoring
 type Transformer struct {
     timeout          time.Duration
     transformers     map[string]TransformFunc
+    conditionals     map[string][]conditionalTransformer
+    failurePolicies  map[string]string
+    defaultPolicy    string
+    sensitive        *sensitiveFieldMatcher
     transformLimiter chan struct{}
     tracer          trace.Tracer
     mu              sync.RWMutex
@@ -52,6 +68,10 @@ func NewTransformer(timeout time.Duration) *Transformer {
     return &Transformer{
         timeout:          timeout,
         transformers:     make(map[string]TransformFunc),
+        conditionals:     make(map[string][]conditionalTransformer),
+        failurePolicies:  make(map[string]string),
+        defaultPolicy:    FailurePolicyFailEvent,
+        sensitive:        defaultSensitiveFields,
         transformLimiter: make(chan struct{}, maxConcurrentTransforms),
         tracer:          otel.Tracer("normalizer.transformer"),
     }
@@ -59,15 +79,22 @@ func NewTransformer(timeout time.Duration) *Transformer {
 
 // TransformEvent securely transforms a Bronze event into a Silver event
 func (t *Transformer) TransformEvent(bronzeEvent *schema.BronzeEvent, mappedFields map[string]interface{}, secCtx *schema.SecurityContext) (*schema.SilverEvent, error) {
+    silverEvent, _, err := t.transformEvent(bronzeEvent, mappedFields, secCtx)
+    return silverEvent, err
+}
+
+// transformEvent implements TransformEvent, also reporting the time spent
+// encrypting sensitive fields so callers can time it as a separate stage
+func (t *Transformer) transformEvent(bronzeEvent *schema.BronzeEvent, mappedFields map[string]interface{}, secCtx *schema.SecurityContext) (*schema.SilverEvent, time.Duration, error) {
     ctx, span := t.tracer.Start(context.Background(), "transform_event")
     defer span.End()
 
     // Validate inputs
     if bronzeEvent == nil {
-        return nil, errors.NewError("E3001", "nil bronze event", nil)
+        return nil, 0, errors.NewError("E3001", "nil bronze event", nil)
     }
     if mappedFields == nil {
-        return nil, errors.NewError("E3001", "nil mapped fields", nil)
+        return nil, 0, errors.NewError("E3001", "nil mapped fields", nil)
     }
 
     // Apply concurrency limiting
@@ -75,7 +102,7 @@ func (t *Transformer) TransformEvent(bronzeEvent *schema.BronzeEvent, mappedFiel
     case t.transformLimiter <- struct{}{}:
         defer func() { <-t.transformLimiter }()
     default:
-        return nil, errors.NewError("E4002", "transformation capacity exceeded", nil)
+        return nil, 0, errors.NewError("E4002", "transformation capacity exceeded", nil)
     }
 
     // Create transformation context with timeout
@@ -94,10 +121,10 @@ func (t *Transformer) TransformEvent(bronzeEvent *schema.BronzeEvent, mappedFiel
     }
 
     // Transform and validate fields
-    normalizedData, err := t.transformFields(ctx, mappedFields)
+    normalizedData, encryptedFields, transformErrors, encryptionTime, err := t.transformFields(ctx, mappedFields)
     if err != nil {
         span.SetAttributes(attribute.String("error", err.Error()))
-        return nil, err
+        return nil, 0, err
     }
 
     // Create Silver event
@@ -109,18 +136,22 @@ func (t *Transformer) TransformEvent(bronzeEvent *schema.BronzeEvent, mappedFiel
     )
     if err != nil {
         span.SetAttributes(attribute.String("error", err.Error()))
-        return nil, err
+        return nil, 0, err
     }
 
     // Set Bronze event linkage
     if err := silverEvent.FromBronzeEvent(bronzeEvent, normalizedData, *secCtx); err != nil {
-        return nil, err
+        return nil, 0, err
+    }
+    silverEvent.TransformErrors = transformErrors
+    for field, encrypted := range encryptedFields {
+        silverEvent.EncryptedFields[field] = encrypted
     }
 
     // Validate transformed event
     if err := silverEvent.Validate(); err != nil {
         span.SetAttributes(attribute.String("error", err.Error()))
-        return nil, err
+        return nil, 0, err
     }
 
     span.SetAttributes(
@@ -129,7 +160,7 @@ func (t *Transformer) TransformEvent(bronzeEvent *schema.BronzeEvent, mappedFiel
         attribute.String("event_type", silverEvent.EventType),
     )
 
-    return silverEvent, nil
+    return silverEvent, encryptionTime, nil
 }
 
 // RegisterTransformer registers a custom field transformer
@@ -139,66 +170,178 @@ func (t *Transformer) RegisterTransformer(fieldName string, transformer Transfor
     t.transformers[fieldName] = transformer
 }
 
-// transformFields applies registered transformers and security controls
-func (t *Transformer) transformFields(ctx context.Context, fields map[string]interface{}) (map[string]interface{}, error) {
+// RegisterConditionalTransformer registers a transformer for a field that
+// applies only when predicate returns true for the record.
+//
+// Predicates see the record as mapped, before any field is transformed, so
+// the outcome does not depend on the order fields are visited. When several
+// transformers target one field, conditional transformers are tried in
+// registration order and the first whose predicate holds is applied; the
+// transformer from RegisterTransformer applies only if none match.
+func (t *Transformer) RegisterConditionalTransformer(fieldName string, predicate TransformPredicate, fn TransformFunc) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    t.conditionals[fieldName] = append(t.conditionals[fieldName], conditionalTransformer{
+        predicate: predicate,
+        fn:        fn,
+    })
+}
+
+// transformerFor selects the transformer for a field; callers hold t.mu
+func (t *Transformer) transformerFor(fieldName string, record map[string]interface{}) (TransformFunc, bool) {
+    for _, conditional := range t.conditionals[fieldName] {
+        if conditional.predicate(record) {
+            return conditional.fn, true
+        }
+    }
+    transformer, exists := t.transformers[fieldName]
+    return transformer, exists
+}
+
+// SetSensitiveFields compiles the patterns and allow-list that select the
+// fields to encrypt, replacing the current selection
+func (t *Transformer) SetSensitiveFields(config SensitiveFieldConfig) error {
+    matcher, err := compileSensitiveFields(config)
+    if err != nil {
+        return err
+    }
+
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    t.sensitive = matcher
+    return nil
+}
+
+// SetFieldFailurePolicy sets how a transformation failure of one field is handled
+func (t *Transformer) SetFieldFailurePolicy(fieldName string, policy string) error {
+    if err := validateFailurePolicy(policy); err != nil {
+        return err
+    }
+
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    t.failurePolicies[fieldName] = policy
+    return nil
+}
+
+// SetDefaultFailurePolicy sets the policy for fields without their own policy
+func (t *Transformer) SetDefaultFailurePolicy(policy string) error {
+    if err := validateFailurePolicy(policy); err != nil {
+        return err
+    }
+
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    t.defaultPolicy = policy
+    return nil
+}
+
+// validateFailurePolicy checks that a field failure policy is supported
+func validateFailurePolicy(policy string) error {
+    switch policy {
+    case FailurePolicyFailEvent, FailurePolicySkipField:
+        return nil
+    default:
+        return errors.NewError("E3001", "unsupported field failure policy", map[string]interface{}{
+            "policy": policy,
+        })
+    }
+}
+
+// fieldFailurePolicy returns the policy for a field; callers hold t.mu
+func (t *Transformer) fieldFailurePolicy(fieldName string) string {
+    if policy, ok := t.failurePolicies[fieldName]; ok {
+        return policy
+    }
+    return t.defaultPolicy
+}
+
+// transformFields applies registered transformers and security controls.
+// Sensitive fields are returned encrypted and kept out of the normalized data.
+// Transformation and length failures follow the field's failure policy;
+// encryption failures always fail the event. The time spent encrypting is
+// returned separately from the overall transformation time.
+func (t *Transformer) transformFields(ctx context.Context, fields map[string]interface{}) (map[string]interface{}, map[string][]byte, []schema.TransformError, time.Duration, error) {
     normalized := make(map[string]interface{})
+    encryptedFields := make(map[string][]byte)
+    var skipped []schema.TransformError
+    var encryptionTime time.Duration
 
     t.mu.RLock()
     defer t.mu.RUnlock()
 
-    for key, value := range fields {
+    // Visit fields in order so recorded errors are stable
+    keys := make([]string, 0, len(fields))
+    for key := range fields {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+
+    for _, key := range keys {
+        value := fields[key]
+
         // Check context cancellation
         select {
         case <-ctx.Done():
-            return nil, errors.NewError("E4001", "transformation timeout", nil)
+            return nil, nil, nil, 0, errors.NewError("E4001", "transformation timeout", nil)
         default:
         }
 
         // Apply field transformation
         transformed := value
-        if transformer, exists := t.transformers[key]; exists {
+        var fieldErr error
+        if transformer, exists := t.transformerFor(key, fields); exists {
             var err error
             transformed, err = transformer(value)
             if err != nil {
-                return nil, errors.WrapError(err, "field transformation failed", map[string]interface{}{
+                fieldErr = errors.WrapError(err, "field transformation failed", map[string]interface{}{
                     "field": key,
                 })
             }
         }
 
         // Validate field length
-        if str, ok := transformed.(string); ok {
-            if len(str) > maxFieldLength {
-                return nil, errors.NewError("E3001", "field length exceeds maximum", map[string]interface{}{
-                    "field": key,
-                    "max_length": maxFieldLength,
-                })
+        if fieldErr == nil {
+            if str, ok := transformed.(string); ok {
+                if len(str) > maxFieldLength {
+                    fieldErr = errors.NewError("E3001", "field length exceeds maximum", map[string]interface{}{
+                        "field": key,
+                        "max_length": maxFieldLength,
+                    })
+                }
+            }
+        }
+
+        if fieldErr != nil {
+            if t.fieldFailurePolicy(key) != FailurePolicySkipField {
+                return nil, nil, nil, 0, fieldErr
             }
+            skipped = append(skipped, schema.TransformError{
+                Field: key,
+                Error: fieldErr.Error(),
+            })
+            continue
         }
 
         // Handle sensitive fields
-        if isSensitiveField(key) {
+        if pattern, sensitive := t.sensitive.match(key); sensitive {
+            encryptStart := time.Now()
             encrypted, err := encryptSensitiveValue(transformed)
+            encryptionTime += time.Since(encryptStart)
             if err != nil {
-                return nil, err
+                return nil, nil, nil, 0, errors.WrapError(err, "failed to encrypt sensitive field", map[string]interface{}{
+                    "field":   key,
+                    "pattern": pattern,
+                })
             }
-            transformed = encrypted
+            encryptedFields[key] = encrypted
+            continue
         }
 
         normalized[key] = transformed
     }
 
-    return normalized, nil
-}
-
-// isSensitiveField checks if a field requires encryption
-func isSensitiveField(fieldName string) bool {
-    for _, pattern := range sensitiveFieldPatterns {
-        if strings.Contains(strings.ToLower(fieldName), pattern) {
-            return true
-        }
-    }
-    return false
+    return normalized, encryptedFields, skipped, encryptionTime, nil
 }
 
 // encryptSensitiveValue encrypts sensitive field values
@@ -0,0 +1,257 @@
+// Package storage provides attribute indexing for stored Silver events
+package storage
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus" // v1.11.0
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/silver"
+)
+
+const (
+    // Suffix of the metadata object written alongside each stored event
+    attributeSidecarSuffix = ".attrs.json"
+
+    // Default lifetime of Redis attribute index entries, matching Silver retention
+    defaultAttributeIndexTTL = 90 * 24 * time.Hour
+)
+
+// DefaultIndexedAttributes maps indexed attribute names to the normalized
+// fields they are extracted from, in order of preference
+var DefaultIndexedAttributes = map[string][]string{
+    "user":     {"src_user", "user", "dst_user"},
+    "ip":       {"src_ip", "dst_ip"},
+    "action":   {"event_type", "action"},
+    "severity": {"severity"},
+}
+
+var attributeIndexErrors = prometheus.NewCounterVec(
+    prometheus.CounterOpts{
+        Name: "blackpoint_storage_attribute_index_errors_total",
+        Help: "Total number of errors writing or querying the event attribute index",
+    },
+    []string{"stage"},
+)
+
+func init() {
+    prometheus.MustRegister(attributeIndexErrors)
+}
+
+// AttributeIndexConfig configures attribute extraction for stored events
+type AttributeIndexConfig struct {
+    // Attributes maps attribute names to candidate normalized fields; defaults to DefaultIndexedAttributes
+    Attributes map[string][]string
+
+    // WriteSidecar writes the extracted attributes as an object next to each event
+    WriteSidecar bool
+
+    // IndexTTL bounds how long Redis index entries are kept
+    IndexTTL time.Duration
+}
+
+// EventAttributes is the indexed metadata written for a stored event
+type EventAttributes struct {
+    Key        string            `json:"key"`
+    EventID    string            `json:"event_id"`
+    ClientID   string            `json:"client_id"`
+    EventTime  time.Time         `json:"event_time"`
+    Attributes map[string]string `json:"attributes"`
+}
+
+// AttributeIndexer stores Silver events together with their extracted
+// attributes so events can be found by attribute without reading payloads
+type AttributeIndexer struct {
+    s3     *S3Client
+    redis  *RedisClient
+    config AttributeIndexConfig
+}
+
+// NewAttributeIndexer creates an indexer. The Redis client is optional; without
+// it attributes are only written as sidecar objects and FindObjects is unavailable.
+func NewAttributeIndexer(s3Client *S3Client, redisClient *RedisClient, config AttributeIndexConfig) (*AttributeIndexer, error) {
+    if s3Client == nil {
+        return nil, errors.NewError("E2001", "S3 client is required", nil)
+    }
+    if redisClient == nil && !config.WriteSidecar {
+        return nil, errors.NewError("E2001", "attribute index requires redis or sidecar objects", nil)
+    }
+    if len(config.Attributes) == 0 {
+        config.Attributes = DefaultIndexedAttributes
+    }
+    if config.IndexTTL <= 0 {
+        config.IndexTTL = defaultAttributeIndexTTL
+    }
+
+    return &AttributeIndexer{
+        s3:     s3Client,
+        redis:  redisClient,
+        config: config,
+    }, nil
+}
+
+// Extract returns the configured attributes present in an event
+func (ai *AttributeIndexer) Extract(event *silver.SilverEvent) map[string]string {
+    attributes := make(map[string]string, len(ai.config.Attributes))
+    for name, fields := range ai.config.Attributes {
+        for _, field := range fields {
+            value, ok := event.NormalizedData[field]
+            if !ok || value == nil {
+                continue
+            }
+            if s := strings.TrimSpace(fmt.Sprint(value)); s != "" {
+                attributes[name] = s
+                break
+            }
+        }
+    }
+    return attributes
+}
+
+// StoreEvent writes an event and its attribute metadata. The event object is
+// written first so the index never points at a missing object.
+func (ai *AttributeIndexer) StoreEvent(ctx context.Context, bucket, key string, event *silver.SilverEvent) error {
+    data, err := json.Marshal(event)
+    if err != nil {
+        return errors.WrapError(err, "failed to encode event", map[string]interface{}{
+            "key": key,
+        })
+    }
+    if err := ai.s3.PutObject(bucket, key, data); err != nil {
+        return err
+    }
+
+    return ai.IndexObject(ctx, bucket, key, event)
+}
+
+// IndexObject writes attribute metadata for an already stored event
+func (ai *AttributeIndexer) IndexObject(ctx context.Context, bucket, key string, event *silver.SilverEvent) error {
+    metadata := EventAttributes{
+        Key:        key,
+        EventID:    event.EventID,
+        ClientID:   event.ClientID,
+        EventTime:  event.EventTime,
+        Attributes: ai.Extract(event),
+    }
+
+    if ai.config.WriteSidecar {
+        sidecar, err := json.Marshal(metadata)
+        if err != nil {
+            attributeIndexErrors.WithLabelValues("encode").Inc()
+            return errors.WrapError(err, "failed to encode event attributes", map[string]interface{}{
+                "key": key,
+            })
+        }
+        if err := ai.s3.PutObject(bucket, key+attributeSidecarSuffix, sidecar); err != nil {
+            attributeIndexErrors.WithLabelValues("sidecar").Inc()
+            return err
+        }
+    }
+
+    if ai.redis != nil {
+        for name, value := range metadata.Attributes {
+            if err := ai.redis.AddToSet(ctx, attributeIndexKey(bucket, name, value), []string{key}, ai.config.IndexTTL); err != nil {
+                attributeIndexErrors.WithLabelValues("redis").Inc()
+                return err
+            }
+        }
+    }
+
+    return nil
+}
+
+// ReindexObject rewrites attribute metadata after a stored event changed in
+// place. previous holds the attributes extracted before the change; the object
+// is dropped from the index sets of values it no longer carries, so purged
+// values stop matching queries before their index entries expire.
+func (ai *AttributeIndexer) ReindexObject(ctx context.Context, bucket, key string, previous map[string]string, event *silver.SilverEvent) error {
+    current := ai.Extract(event)
+
+    if ai.redis != nil {
+        for name, value := range previous {
+            if next, ok := current[name]; ok && strings.EqualFold(next, value) {
+                continue
+            }
+            if err := ai.redis.RemoveFromSet(ctx, attributeIndexKey(bucket, name, value), []string{key}); err != nil {
+                attributeIndexErrors.WithLabelValues("redis").Inc()
+                return err
+            }
+        }
+    }
+
+    return ai.IndexObject(ctx, bucket, key, event)
+}
+
+// GetAttributes reads the sidecar metadata of a stored event
+func (ai *AttributeIndexer) GetAttributes(bucket, key string) (*EventAttributes, error) {
+    data, err := ai.s3.GetObject(bucket, key+attributeSidecarSuffix)
+    if err != nil {
+        return nil, err
+    }
+
+    var metadata EventAttributes
+    if err := json.Unmarshal(data, &metadata); err != nil {
+        return nil, errors.WrapError(err, "failed to decode event attributes", map[string]interface{}{
+            "key": key,
+        })
+    }
+    return &metadata, nil
+}
+
+// FindObjects returns the keys of stored events matching every attribute
+// filter, in key order
+func (ai *AttributeIndexer) FindObjects(ctx context.Context, bucket string, filters map[string]string) ([]string, error) {
+    if ai.redis == nil {
+        return nil, errors.NewError("E2001", "attribute queries require a redis index", nil)
+    }
+    if len(filters) == 0 {
+        return nil, errors.NewError("E3001", "at least one attribute filter is required", nil)
+    }
+
+    for name := range filters {
+        if _, ok := ai.config.Attributes[name]; !ok {
+            return nil, errors.NewError("E3001", "attribute is not indexed", map[string]interface{}{
+                "attribute": name,
+            })
+        }
+    }
+
+    // Sets are intersected client-side since index keys may live on different cluster slots
+    var matches map[string]bool
+    for name, value := range filters {
+        members, err := ai.redis.SetMembers(ctx, attributeIndexKey(bucket, name, value))
+        if err != nil {
+            attributeIndexErrors.WithLabelValues("query").Inc()
+            return nil, err
+        }
+
+        next := make(map[string]bool, len(members))
+        for _, member := range members {
+            if matches == nil || matches[member] {
+                next[member] = true
+            }
+        }
+        matches = next
+        if len(matches) == 0 {
+            return nil, nil
+        }
+    }
+
+    keys := make([]string, 0, len(matches))
+    for key := range matches {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys, nil
+}
+
+// attributeIndexKey names the Redis set holding object keys for an attribute value
+func attributeIndexKey(bucket, name, value string) string {
+    return fmt.Sprintf("attr:%s:%s:%s", bucket, name, strings.ToLower(value))
+}
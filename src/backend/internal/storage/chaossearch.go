@@ -237,7 +237,13 @@ func validateConfig(config *ChaosSearchConfig) error {
     return nil
 }
 
+// PartitionPrefix returns the key prefix of a tier's objects stored on the
+// UTC day containing timestamp: {tier}/YYYY/MM/DD/
+func PartitionPrefix(tier string, timestamp time.Time) string {
+    return fmt.Sprintf("%s/%s/", tier, timestamp.UTC().Format("2006/01/02"))
+}
+
 // generatePartitionKey generates a secure partition key for data storage
 func generatePartitionKey(tier string, timestamp time.Time) string {
-    return fmt.Sprintf("%s/%s/%d", tier, timestamp.Format("2006/01/02"), timestamp.Unix())
+    return fmt.Sprintf("%s%d", PartitionPrefix(tier, timestamp), timestamp.Unix())
 }
\ No newline at end of file
@@ -32,6 +32,8 @@ type RedisConfig struct {
 	TLSEnabled   bool
 	CertFile     string
 	KeyFile      string
+	// PipelineBatchSize caps the commands SetBatch sends per pipeline; defaults to 1000
+	PipelineBatchSize int
 }
 
 // RedisClient provides thread-safe Redis operations with cluster support
@@ -227,6 +229,164 @@ func (c *RedisClient) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// AddToSet adds members to a set and refreshes the set's TTL
+func (c *RedisClient) AddToSet(ctx context.Context, key string, members []string, ttl time.Duration) error {
+	if key == "" {
+		return common.NewError("E4001", "key is required", nil)
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		values[i] = member
+	}
+
+	var pipe redis.Pipeliner
+	if c.cluster != nil {
+		pipe = c.cluster.TxPipeline()
+	} else {
+		pipe = c.single.TxPipeline()
+	}
+	pipe.SAdd(ctx, key, values...)
+	if ttl > 0 {
+		pipe.Expire(ctx, key, ttl)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return common.WrapError(err, "failed to add set members in redis", map[string]interface{}{
+			"key": key,
+		})
+	}
+
+	return nil
+}
+
+// SetMembers returns the members of a set, or none when the set does not exist
+func (c *RedisClient) SetMembers(ctx context.Context, key string) ([]string, error) {
+	if key == "" {
+		return nil, common.NewError("E4001", "key is required", nil)
+	}
+
+	var members []string
+	var err error
+	if c.cluster != nil {
+		members, err = c.cluster.SMembers(ctx, key).Result()
+	} else {
+		members, err = c.single.SMembers(ctx, key).Result()
+	}
+
+	if err != nil && err != redis.Nil {
+		return nil, common.WrapError(err, "failed to read set members from redis", map[string]interface{}{
+			"key": key,
+		})
+	}
+
+	return members, nil
+}
+
+// RemoveFromSet removes members from a set; Redis deletes the set once it is empty
+func (c *RedisClient) RemoveFromSet(ctx context.Context, key string, members []string) error {
+	if key == "" {
+		return common.NewError("E4001", "key is required", nil)
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		values[i] = member
+	}
+
+	var err error
+	if c.cluster != nil {
+		err = c.cluster.SRem(ctx, key, values...).Err()
+	} else {
+		err = c.single.SRem(ctx, key, values...).Err()
+	}
+
+	if err != nil {
+		return common.WrapError(err, "failed to remove set members in redis", map[string]interface{}{
+			"key": key,
+		})
+	}
+
+	return nil
+}
+
+// Lease scripts only touch a lease while it is still held by the caller
+var (
+	renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// AcquireLease takes a lease if no one holds it, returning whether it was acquired
+func (c *RedisClient) AcquireLease(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	var acquired bool
+	var err error
+	if c.cluster != nil {
+		acquired, err = c.cluster.SetNX(ctx, key, holder, ttl).Result()
+	} else {
+		acquired, err = c.single.SetNX(ctx, key, holder, ttl).Result()
+	}
+
+	if err != nil {
+		return false, common.WrapError(err, "failed to acquire lease in redis", map[string]interface{}{
+			"key": key,
+		})
+	}
+
+	return acquired, nil
+}
+
+// RenewLease extends a lease held by the holder, returning false if it was lost
+func (c *RedisClient) RenewLease(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	var renewed int64
+	var err error
+	if c.cluster != nil {
+		renewed, err = renewLeaseScript.Run(ctx, c.cluster, []string{key}, holder, ttl.Milliseconds()).Int64()
+	} else {
+		renewed, err = renewLeaseScript.Run(ctx, c.single, []string{key}, holder, ttl.Milliseconds()).Int64()
+	}
+
+	if err != nil {
+		return false, common.WrapError(err, "failed to renew lease in redis", map[string]interface{}{
+			"key": key,
+		})
+	}
+
+	return renewed == 1, nil
+}
+
+// ReleaseLease gives up a lease if the holder still owns it
+func (c *RedisClient) ReleaseLease(ctx context.Context, key, holder string) error {
+	var err error
+	if c.cluster != nil {
+		err = releaseLeaseScript.Run(ctx, c.cluster, []string{key}, holder).Err()
+	} else {
+		err = releaseLeaseScript.Run(ctx, c.single, []string{key}, holder).Err()
+	}
+
+	if err != nil && err != redis.Nil {
+		return common.WrapError(err, "failed to release lease in redis", map[string]interface{}{
+			"key": key,
+		})
+	}
+
+	return nil
+}
+
 // Ping verifies Redis connection health
 func (c *RedisClient) Ping(ctx context.Context) error {
 	var err error
@@ -0,0 +1,118 @@
+// Package storage provides pipelined bulk writes for Redis
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/blackpoint/pkg/common"                // v1.0.0
+	"github.com/go-redis/redis/v8"                    // v8.11.5
+	"github.com/prometheus/client_golang/prometheus" // v1.11.0
+)
+
+// Default number of commands flushed per pipeline
+const defaultPipelineBatchSize = 1000
+
+var redisPipelineDuration = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Name:    "blackpoint_redis_pipeline_duration_seconds",
+		Help:    "Duration of Redis pipeline round-trips",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 12),
+	},
+)
+
+func init() {
+	prometheus.MustRegister(redisPipelineDuration)
+}
+
+// BatchSetError reports the keys a SetBatch call failed to write. Keys not
+// listed were written successfully.
+type BatchSetError struct {
+	Failed map[string]error
+}
+
+// Error summarizes the failed keys
+func (e *BatchSetError) Error() string {
+	return fmt.Sprintf("failed to set %d keys in redis", len(e.Failed))
+}
+
+// SetBatch stores many values with the same optional TTL using pipelines,
+// flushing up to PipelineBatchSize commands per round-trip. A failed key
+// does not stop the rest of the batch; failures are returned together as a
+// *BatchSetError.
+func (c *RedisClient) SetBatch(ctx context.Context, items map[string]interface{}, ttl *time.Duration) error {
+	expiration := defaultTTL
+	if ttl != nil {
+		expiration = *ttl
+	}
+
+	batchSize := c.config.PipelineBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultPipelineBatchSize
+	}
+
+	failed := make(map[string]error)
+
+	// Serialize up front so encoding errors never reach the pipeline
+	keys := make([]string, 0, len(items))
+	payloads := make(map[string][]byte, len(items))
+	for key, value := range items {
+		if key == "" {
+			failed[key] = common.NewError("E4001", "key is required", nil)
+			continue
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			failed[key] = common.WrapError(err, "failed to serialize value", nil)
+			continue
+		}
+		keys = append(keys, key)
+		payloads[key] = data
+	}
+	sort.Strings(keys)
+
+	for start := 0; start < len(keys); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			for _, key := range keys[start:] {
+				failed[key] = err
+			}
+			break
+		}
+
+		end := start + batchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		chunk := keys[start:end]
+
+		var pipe redis.Pipeliner
+		if c.cluster != nil {
+			pipe = c.cluster.Pipeline()
+		} else {
+			pipe = c.single.Pipeline()
+		}
+		cmds := make([]*redis.StatusCmd, len(chunk))
+		for i, key := range chunk {
+			cmds[i] = pipe.Set(ctx, key, payloads[key], expiration)
+		}
+
+		// Exec only returns the first failure, so check every command's result
+		timer := prometheus.NewTimer(redisPipelineDuration)
+		pipe.Exec(ctx)
+		timer.ObserveDuration()
+
+		for i, cmd := range cmds {
+			if err := cmd.Err(); err != nil {
+				failed[chunk[i]] = err
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return &BatchSetError{Failed: failed}
+	}
+	return nil
+}
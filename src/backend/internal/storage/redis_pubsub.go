@@ -0,0 +1,132 @@
+// Package storage provides Redis pub/sub for broadcasting events between services
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/blackpoint/pkg/common"                // v1.0.0
+	"github.com/go-redis/redis/v8"                    // v8.11.5
+	"github.com/prometheus/client_golang/prometheus" // v1.11.0
+)
+
+// Subscriber reconnect backoff bounds
+const (
+	minSubscribeBackoff = 100 * time.Millisecond
+	maxSubscribeBackoff = 30 * time.Second
+)
+
+var redisSubscriberReconnects = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "blackpoint_redis_subscriber_reconnects_total",
+		Help: "Total number of Redis subscriber reconnects after connection loss",
+	},
+	[]string{"channel"},
+)
+
+func init() {
+	prometheus.MustRegister(redisSubscriberReconnects)
+}
+
+// Publish broadcasts a message to every current subscriber of a channel.
+// Subscribers that are disconnected when the message is sent never see it.
+func (c *RedisClient) Publish(ctx context.Context, channel string, msg []byte) error {
+	if channel == "" {
+		return common.NewError("E4001", "channel is required", nil)
+	}
+
+	var err error
+	if c.cluster != nil {
+		err = c.cluster.Publish(ctx, channel, msg).Err()
+	} else {
+		err = c.single.Publish(ctx, channel, msg).Err()
+	}
+
+	if err != nil {
+		return common.WrapError(err, "failed to publish message to redis", map[string]interface{}{
+			"channel": channel,
+		})
+	}
+
+	return nil
+}
+
+// Subscribe calls handler for each message published to channel until ctx is
+// cancelled. It returns once the first subscription is established and
+// receives in the background, resubscribing with exponential backoff when the
+// connection is lost.
+//
+// Delivery is at-most-once: messages published while the subscriber is
+// reconnecting are lost, and there is no ordering guarantee across
+// reconnects or publishers. Handlers must be idempotent and should treat a
+// message as a hint to reload state rather than as the state itself.
+// Handlers run on the receive goroutine, so a slow handler delays delivery.
+func (c *RedisClient) Subscribe(ctx context.Context, channel string, handler func([]byte)) error {
+	if channel == "" {
+		return common.NewError("E4001", "channel is required", nil)
+	}
+	if handler == nil {
+		return common.NewError("E4001", "handler is required", nil)
+	}
+
+	pubsub, err := c.subscribe(ctx, channel)
+	if err != nil {
+		return err
+	}
+
+	go c.receive(ctx, channel, pubsub, handler)
+	return nil
+}
+
+// subscribe opens a subscription and waits for the server to confirm it
+func (c *RedisClient) subscribe(ctx context.Context, channel string) (*redis.PubSub, error) {
+	var pubsub *redis.PubSub
+	if c.cluster != nil {
+		pubsub = c.cluster.Subscribe(ctx, channel)
+	} else {
+		pubsub = c.single.Subscribe(ctx, channel)
+	}
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, common.WrapError(err, "failed to subscribe to redis channel", map[string]interface{}{
+			"channel": channel,
+		})
+	}
+
+	return pubsub, nil
+}
+
+// receive delivers messages to handler, resubscribing after connection loss
+func (c *RedisClient) receive(ctx context.Context, channel string, pubsub *redis.PubSub, handler func([]byte)) {
+	backoff := minSubscribeBackoff
+	for {
+		for pubsub != nil {
+			msg, err := pubsub.ReceiveMessage(ctx)
+			if err != nil {
+				pubsub.Close()
+				pubsub = nil
+				break
+			}
+			backoff = minSubscribeBackoff
+			handler([]byte(msg.Payload))
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxSubscribeBackoff {
+			backoff = maxSubscribeBackoff
+		}
+
+		redisSubscriberReconnects.WithLabelValues(channel).Inc()
+		pubsub, _ = c.subscribe(ctx, channel)
+	}
+}
@@ -0,0 +1,196 @@
+// Package storage provides field-level retention purging for stored Silver events
+package storage
+
+import (
+    "context"
+    "encoding/json"
+    "strings"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus" // v1.11.0
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+    "github.com/blackpoint/pkg/silver"
+)
+
+var (
+    fieldsPurged = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Name: "blackpoint_storage_fields_purged_total",
+            Help: "Total number of event fields purged after retention expiry",
+        },
+        []string{"field"},
+    )
+
+    purgeErrors = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Name: "blackpoint_storage_field_purge_errors_total",
+            Help: "Total number of errors during field retention purges",
+        },
+        []string{"stage"},
+    )
+)
+
+func init() {
+    prometheus.MustRegister(fieldsPurged)
+    prometheus.MustRegister(purgeErrors)
+}
+
+// FieldPurgeConfig configures a field-level retention purge
+type FieldPurgeConfig struct {
+    Bucket string
+    Prefix string
+
+    // Policy is applied to stored events that predate field retention metadata
+    Policy silver.RetentionPolicy
+
+    // Attributes is the indexer the events were stored with, if any. Purged
+    // values are then also removed from sidecars and the attribute index.
+    Attributes *AttributeIndexer
+}
+
+// FieldPurgeResult summarizes a purge run
+type FieldPurgeResult struct {
+    ObjectsScanned   int
+    ObjectsRewritten int
+    FieldsPurged     int
+    Errors           int
+}
+
+// FieldPurger redacts expired fields from stored Silver events without deleting the events
+type FieldPurger struct {
+    client *S3Client
+    config FieldPurgeConfig
+}
+
+// NewFieldPurger creates a new FieldPurger
+func NewFieldPurger(client *S3Client, config FieldPurgeConfig) (*FieldPurger, error) {
+    if client == nil {
+        return nil, errors.NewError("E2001", "S3 client is required", nil)
+    }
+    if config.Bucket == "" {
+        return nil, errors.NewError("E2001", "purge bucket is required", nil)
+    }
+    if err := config.Policy.Validate(); err != nil {
+        return nil, err
+    }
+
+    return &FieldPurger{
+        client: client,
+        config: config,
+    }, nil
+}
+
+// Purge scans stored events and rewrites those with expired fields. Individual
+// object failures are counted and skipped so one bad object cannot block erasure
+// of the rest.
+func (p *FieldPurger) Purge(ctx context.Context, now time.Time) (*FieldPurgeResult, error) {
+    keys, err := p.client.ListObjects(p.config.Bucket, p.config.Prefix)
+    if err != nil {
+        purgeErrors.WithLabelValues("list").Inc()
+        return nil, err
+    }
+
+    result := &FieldPurgeResult{}
+    for _, key := range keys {
+        select {
+        case <-ctx.Done():
+            return result, errors.WrapError(ctx.Err(), "field purge cancelled", map[string]interface{}{
+                "objects_scanned": result.ObjectsScanned,
+            })
+        default:
+        }
+
+        // Attribute metadata objects carry no event payload
+        if strings.HasSuffix(key, attributeSidecarSuffix) {
+            continue
+        }
+
+        result.ObjectsScanned++
+        purged, err := p.purgeObject(ctx, key, now)
+        if err != nil {
+            result.Errors++
+            logging.Error("Failed to purge expired fields", err,
+                logging.Field("bucket", p.config.Bucket),
+                logging.Field("key", key),
+            )
+            continue
+        }
+        if purged > 0 {
+            result.ObjectsRewritten++
+            result.FieldsPurged += purged
+        }
+    }
+
+    logging.Info("Field retention purge completed",
+        logging.Field("bucket", p.config.Bucket),
+        logging.Field("objects_scanned", result.ObjectsScanned),
+        logging.Field("objects_rewritten", result.ObjectsRewritten),
+        logging.Field("fields_purged", result.FieldsPurged),
+    )
+
+    return result, nil
+}
+
+// purgeObject removes expired fields from a single stored event and rewrites it
+// together with its attribute metadata
+func (p *FieldPurger) purgeObject(ctx context.Context, key string, now time.Time) (int, error) {
+    data, err := p.client.GetObject(p.config.Bucket, key)
+    if err != nil {
+        purgeErrors.WithLabelValues("read").Inc()
+        return 0, err
+    }
+
+    var event silver.SilverEvent
+    if err := json.Unmarshal(data, &event); err != nil {
+        purgeErrors.WithLabelValues("decode").Inc()
+        return 0, errors.WrapError(err, "failed to decode stored event", map[string]interface{}{
+            "key": key,
+        })
+    }
+
+    if len(p.config.Policy) > 0 {
+        if err := event.ApplyRetentionPolicy(p.config.Policy); err != nil {
+            return 0, err
+        }
+    }
+
+    var attributes map[string]string
+    if p.config.Attributes != nil {
+        attributes = p.config.Attributes.Extract(&event)
+    }
+
+    purged := event.PurgeExpiredFields(now)
+    if len(purged) == 0 {
+        return 0, nil
+    }
+
+    updated, err := json.Marshal(&event)
+    if err != nil {
+        purgeErrors.WithLabelValues("encode").Inc()
+        return 0, errors.WrapError(err, "failed to encode purged event", map[string]interface{}{
+            "key": key,
+        })
+    }
+
+    if err := p.client.PutObject(p.config.Bucket, key, updated); err != nil {
+        purgeErrors.WithLabelValues("write").Inc()
+        return 0, err
+    }
+
+    // The event itself is already redacted, so a failed reindex is reported
+    // as an object error rather than undone
+    if p.config.Attributes != nil {
+        if err := p.config.Attributes.ReindexObject(ctx, p.config.Bucket, key, attributes, &event); err != nil {
+            purgeErrors.WithLabelValues("index").Inc()
+            return 0, err
+        }
+    }
+
+    for _, field := range purged {
+        fieldsPurged.WithLabelValues(field).Inc()
+    }
+
+    return len(purged), nil
+}
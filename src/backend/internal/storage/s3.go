@@ -34,6 +34,33 @@ type S3Config struct {
     RetryConfig      *RetryConfig
     MetricsEnabled   bool
     EncryptionContext map[string]string
+    // MultipartPartSize is the part size of PutObjectStream uploads; defaults to 16MB
+    MultipartPartSize int
+    // MultipartConcurrency is how many parts PutObjectStream uploads at once
+    MultipartConcurrency int
+    // GetObjectsConcurrency is how many objects GetObjects fetches at once; defaults to 8
+    GetObjectsConcurrency int
+    // TransitionRules moves each tier's objects to cheaper storage classes
+    // before they expire, keyed by tier
+    TransitionRules map[string][]TransitionRule
+    // MaxPresignTTL caps the lifetime of presigned URLs; defaults to one hour
+    MaxPresignTTL time.Duration
+}
+
+// TransitionRule moves objects to a storage class a number of days after creation
+type TransitionRule struct {
+    Days         int
+    StorageClass string
+}
+
+// Storage classes accepted in transition rules
+var validTransitionStorageClasses = map[string]bool{
+    "STANDARD_IA":         true,
+    "ONEZONE_IA":          true,
+    "INTELLIGENT_TIERING": true,
+    "GLACIER_IR":          true,
+    "GLACIER":             true,
+    "DEEP_ARCHIVE":        true,
 }
 
 // RetryConfig defines retry behavior for S3 operations
@@ -43,9 +70,25 @@ type RetryConfig struct {
     BackoffMultiplier float64
 }
 
+// S3API is the subset of the S3 service client used by S3Client
+type S3API interface {
+    PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+    GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+    DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+    ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+    HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+    PutBucketEncryption(ctx context.Context, params *s3.PutBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.PutBucketEncryptionOutput, error)
+    PutBucketLifecycleConfiguration(ctx context.Context, params *s3.PutBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketLifecycleConfigurationOutput, error)
+    CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+    UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error)
+    CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+    AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+}
+
 // S3Client handles S3 operations with encryption and lifecycle management
 type S3Client struct {
-    s3Client        *s3.Client
+    s3Client        S3API
+    presigner       S3Presigner
     kmsClient       *kms.Client
     config          *S3Config
     ctx             context.Context
@@ -68,6 +111,10 @@ func NewS3Client(cfg *S3Config) (*S3Client, error) {
         }
     }
 
+    if err := validateTransitionRules(cfg); err != nil {
+        return nil, err
+    }
+
     // Load AWS configuration
     awsCfg, err := config.LoadDefaultConfig(context.Background(),
         config.WithRegion(cfg.Region),
@@ -77,12 +124,16 @@ func NewS3Client(cfg *S3Config) (*S3Client, error) {
         return nil, errors.WrapError(err, "failed to load AWS config", nil)
     }
 
-    // Create clients
-    s3Client := s3.NewFromConfig(awsCfg)
+    // Create clients. S3 operations are retried by withRetry, so the SDK
+    // makes a single attempt rather than multiplying the retries.
+    s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+        o.Retryer = aws.NopRetryer{}
+    })
     kmsClient := kms.NewFromConfig(awsCfg)
 
     client := &S3Client{
         s3Client:  s3Client,
+        presigner: s3.NewPresignClient(s3Client),
         kmsClient: kmsClient,
         config:    cfg,
         ctx:       context.Background(),
@@ -96,11 +147,32 @@ func NewS3Client(cfg *S3Config) (*S3Client, error) {
     return client, nil
 }
 
+// NewS3ClientWithAPI creates an S3 client around an existing S3 API
+// implementation. Bucket access is not validated, so it suits tests and
+// callers that manage bucket setup themselves.
+func NewS3ClientWithAPI(cfg *S3Config, api S3API) *S3Client {
+    if cfg.NetworkTimeout <= 0 {
+        cfg.NetworkTimeout = 30 * time.Second
+    }
+    client := &S3Client{
+        s3Client: api,
+        config:   cfg,
+        ctx:      context.Background(),
+    }
+    if s3Client, ok := api.(*s3.Client); ok {
+        client.presigner = s3.NewPresignClient(s3Client)
+    }
+    return client
+}
+
 // PutObject stores an object in S3 with encryption and compression
 func (c *S3Client) PutObject(bucket, key string, data []byte) error {
-    ctx, cancel := context.WithTimeout(c.ctx, c.config.NetworkTimeout)
-    defer cancel()
+    return c.putObject(bucket, key, data, "")
+}
 
+// putObject uploads an object, sending ifNoneMatch as the If-None-Match
+// precondition when set
+func (c *S3Client) putObject(bucket, key string, data []byte, ifNoneMatch string) error {
     // Compress data if enabled
     var contentEncoding string
     if c.config.EnableCompression {
@@ -126,16 +198,23 @@ func (c *S3Client) PutObject(bucket, key string, data []byte) error {
     }
 
     // Upload object with server-side encryption
-    _, err := c.s3Client.PutObject(ctx, &s3.PutObjectInput{
-        Bucket:               aws.String(bucket),
-        Key:                  aws.String(key),
-        Body:                 bytes.NewReader(data),
-        ContentEncoding:      aws.String(contentEncoding),
-        ServerSideEncryption: aws.String("aws:kms"),
-        SSEKMSKeyId:         aws.String(c.config.KmsKeyAlias),
-        Metadata: map[string]string{
-            "encryption-context": "true",
-        },
+    err := c.withRetry(c.ctx, "put_object", func(ctx context.Context) error {
+        input := &s3.PutObjectInput{
+            Bucket:               aws.String(bucket),
+            Key:                  aws.String(key),
+            Body:                 bytes.NewReader(data),
+            ContentEncoding:      aws.String(contentEncoding),
+            ServerSideEncryption: aws.String("aws:kms"),
+            SSEKMSKeyId:         aws.String(c.config.KmsKeyAlias),
+            Metadata: map[string]string{
+                "encryption-context": "true",
+            },
+        }
+        if ifNoneMatch != "" {
+            input.IfNoneMatch = aws.String(ifNoneMatch)
+        }
+        _, err := c.s3Client.PutObject(ctx, input)
+        return err
     })
 
     if err != nil {
@@ -156,13 +235,39 @@ func (c *S3Client) PutObject(bucket, key string, data []byte) error {
 
 // GetObject retrieves and decrypts an object from S3
 func (c *S3Client) GetObject(bucket, key string) ([]byte, error) {
-    ctx, cancel := context.WithTimeout(c.ctx, c.config.NetworkTimeout)
-    defer cancel()
+    data, err := c.fetchObject(bucket, key)
+    if err != nil {
+        return nil, err
+    }
 
-    // Download object
-    result, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
-        Bucket: aws.String(bucket),
-        Key:    aws.String(key),
+    logging.Info("Successfully retrieved object from S3",
+        zap.String("bucket", bucket),
+        zap.String("key", key),
+        zap.Int("size", len(data)),
+    )
+
+    return data, nil
+}
+
+// fetchObject downloads an object and decompresses it if it was stored gzipped
+func (c *S3Client) fetchObject(bucket, key string) ([]byte, error) {
+    // Download and read the object within one attempt so a dropped
+    // connection mid-body is retried as well
+    var data []byte
+    var contentEncoding string
+    err := c.withRetry(c.ctx, "get_object", func(ctx context.Context) error {
+        result, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+            Bucket: aws.String(bucket),
+            Key:    aws.String(key),
+        })
+        if err != nil {
+            return err
+        }
+        defer result.Body.Close()
+
+        data, err = io.ReadAll(result.Body)
+        contentEncoding = aws.ToString(result.ContentEncoding)
+        return err
     })
     if err != nil {
         return nil, errors.WrapError(err, "failed to download object", map[string]interface{}{
@@ -170,16 +275,9 @@ func (c *S3Client) GetObject(bucket, key string) ([]byte, error) {
             "key":    key,
         })
     }
-    defer result.Body.Close()
-
-    // Read object data
-    data, err := io.ReadAll(result.Body)
-    if err != nil {
-        return nil, errors.WrapError(err, "failed to read object data", nil)
-    }
 
     // Decompress if necessary
-    if aws.ToString(result.ContentEncoding) == "gzip" {
+    if contentEncoding == "gzip" {
         gr, err := gzip.NewReader(bytes.NewReader(data))
         if err != nil {
             return nil, errors.WrapError(err, "failed to create gzip reader", nil)
@@ -192,23 +290,17 @@ func (c *S3Client) GetObject(bucket, key string) ([]byte, error) {
         }
     }
 
-    logging.Info("Successfully retrieved object from S3",
-        zap.String("bucket", bucket),
-        zap.String("key", key),
-        zap.Int("size", len(data)),
-    )
-
     return data, nil
 }
 
 // DeleteObject deletes an object from S3
 func (c *S3Client) DeleteObject(bucket, key string) error {
-    ctx, cancel := context.WithTimeout(c.ctx, c.config.NetworkTimeout)
-    defer cancel()
-
-    _, err := c.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
-        Bucket: aws.String(bucket),
-        Key:    aws.String(key),
+    err := c.withRetry(c.ctx, "delete_object", func(ctx context.Context) error {
+        _, err := c.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+            Bucket: aws.String(bucket),
+            Key:    aws.String(key),
+        })
+        return err
     })
 
     if err != nil {
@@ -226,6 +318,46 @@ func (c *S3Client) DeleteObject(bucket, key string) error {
     return nil
 }
 
+// ListObjects returns the keys stored under a prefix in the given bucket
+func (c *S3Client) ListObjects(bucket, prefix string) ([]string, error) {
+    ctx, cancel := context.WithTimeout(c.ctx, c.config.NetworkTimeout)
+    defer cancel()
+
+    var keys []string
+    paginator := s3.NewListObjectsV2Paginator(c.s3Client, &s3.ListObjectsV2Input{
+        Bucket: aws.String(bucket),
+        Prefix: aws.String(prefix),
+    })
+
+    for paginator.HasMorePages() {
+        page, err := paginator.NextPage(ctx)
+        if err != nil {
+            return nil, errors.WrapError(err, "failed to list objects", map[string]interface{}{
+                "bucket": bucket,
+                "prefix": prefix,
+            })
+        }
+        for _, obj := range page.Contents {
+            keys = append(keys, aws.ToString(obj.Key))
+        }
+    }
+
+    return keys, nil
+}
+
+// Ping verifies the tier buckets are reachable
+func (c *S3Client) Ping(ctx context.Context) error {
+    for _, tier := range []string{"bronze", "silver", "gold"} {
+        bucket := c.config.BucketPrefix + tier
+        if _, err := c.s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)}); err != nil {
+            return errors.WrapError(err, "s3 ping failed", map[string]interface{}{
+                "bucket": bucket,
+            })
+        }
+    }
+    return nil
+}
+
 // validateAccess verifies S3 and KMS access permissions
 func (c *S3Client) validateAccess() error {
     ctx, cancel := context.WithTimeout(c.ctx, c.config.NetworkTimeout)
@@ -289,19 +421,28 @@ func (c *S3Client) configureBucket(bucket string) error {
     // Configure lifecycle rules
     tier := bucket[len(c.config.BucketPrefix):]
     retentionDays := c.config.RetentionPeriods[tier]
-    if retentionDays > 0 {
+    transitions := c.config.TransitionRules[tier]
+    if retentionDays > 0 || len(transitions) > 0 {
+        rule := s3.LifecycleRule{
+            ID:     aws.String(fmt.Sprintf("%s-retention", tier)),
+            Status: aws.String("Enabled"),
+        }
+        if retentionDays > 0 {
+            rule.Expiration = &s3.LifecycleExpiration{
+                Days: aws.Int32(int32(retentionDays)),
+            }
+        }
+        for _, transition := range transitions {
+            rule.Transitions = append(rule.Transitions, s3.Transition{
+                Days:         aws.Int32(int32(transition.Days)),
+                StorageClass: s3.TransitionStorageClass(transition.StorageClass),
+            })
+        }
+
         _, err = c.s3Client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
             Bucket: aws.String(bucket),
             LifecycleConfiguration: &s3.BucketLifecycleConfiguration{
-                Rules: []s3.LifecycleRule{
-                    {
-                        ID:     aws.String(fmt.Sprintf("%s-retention", tier)),
-                        Status: aws.String("Enabled"),
-                        Expiration: &s3.LifecycleExpiration{
-                            Days: aws.Int32(int32(retentionDays)),
-                        },
-                    },
-                },
+                Rules: []s3.LifecycleRule{rule},
             },
         })
         if err != nil {
@@ -313,4 +454,33 @@ func (c *S3Client) configureBucket(bucket string) error {
     }
 
     return nil
-}
\ No newline at end of file
+}
+
+// validateTransitionRules checks that each tier's transitions use a known
+// storage class, happen on strictly increasing days, and complete before the
+// tier's objects expire
+func validateTransitionRules(cfg *S3Config) error {
+    for tier, rules := range cfg.TransitionRules {
+        retentionDays := cfg.RetentionPeriods[tier]
+        previous := 0
+        for i, rule := range rules {
+            details := map[string]interface{}{
+                "tier":          tier,
+                "days":          rule.Days,
+                "storage_class": rule.StorageClass,
+            }
+            if !validTransitionStorageClasses[rule.StorageClass] {
+                return errors.NewError("E2001", "unsupported transition storage class", details)
+            }
+            if rule.Days <= 0 || (i > 0 && rule.Days <= previous) {
+                return errors.NewError("E2001", "transition days must be positive and strictly increasing", details)
+            }
+            if retentionDays > 0 && rule.Days >= retentionDays {
+                details["retention_days"] = retentionDays
+                return errors.NewError("E2001", "transition must happen before expiration", details)
+            }
+            previous = rule.Days
+        }
+    }
+    return nil
+}
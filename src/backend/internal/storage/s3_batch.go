@@ -0,0 +1,99 @@
+// Package storage provides parallel batch retrieval of S3 objects
+package storage
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "sync"
+
+    "github.com/blackpoint/pkg/common/logging"
+)
+
+// Default number of objects GetObjects fetches concurrently
+const defaultGetObjectsConcurrency = 8
+
+// BatchGetError reports the keys a GetObjects call failed to fetch. The
+// objects that were fetched are still returned alongside it.
+type BatchGetError struct {
+    Bucket string
+    Failed map[string]error
+}
+
+// Error summarizes the failed keys
+func (e *BatchGetError) Error() string {
+    keys := make([]string, 0, len(e.Failed))
+    for key := range e.Failed {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+
+    const maxListed = 5
+    listed := keys
+    if len(listed) > maxListed {
+        listed = listed[:maxListed]
+    }
+    msg := fmt.Sprintf("failed to fetch %d objects from bucket %s: %s",
+        len(keys), e.Bucket, strings.Join(listed, ", "))
+    if len(keys) > maxListed {
+        msg += ", ..."
+    }
+    return msg
+}
+
+// GetObjects fetches many objects from one bucket in parallel using a bounded
+// worker pool. Each fetch gets its own NetworkTimeout and retries. A failed
+// key does not fail the batch: the fetched objects are returned together
+// with a *BatchGetError listing the keys that could not be retrieved.
+func (c *S3Client) GetObjects(keys []string, bucket string) (map[string][]byte, error) {
+    concurrency := c.config.GetObjectsConcurrency
+    if concurrency <= 0 {
+        concurrency = defaultGetObjectsConcurrency
+    }
+    if concurrency > len(keys) {
+        concurrency = len(keys)
+    }
+
+    jobs := make(chan string)
+    var (
+        objects = make(map[string][]byte, len(keys))
+        failed  = make(map[string]error)
+        mu      sync.Mutex
+        wg      sync.WaitGroup
+    )
+
+    for i := 0; i < concurrency; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for key := range jobs {
+                data, err := c.fetchObject(bucket, key)
+                mu.Lock()
+                if err != nil {
+                    failed[key] = err
+                } else {
+                    objects[key] = data
+                }
+                mu.Unlock()
+            }
+        }()
+    }
+
+    for _, key := range keys {
+        jobs <- key
+    }
+    close(jobs)
+    wg.Wait()
+
+    logging.Info("Retrieved batch of objects from S3",
+        zap.String("bucket", bucket),
+        zap.Int("requested", len(keys)),
+        zap.Int("retrieved", len(objects)),
+        zap.Int("failed", len(failed)),
+    )
+
+    if len(failed) > 0 {
+        return objects, &BatchGetError{Bucket: bucket, Failed: failed}
+    }
+    return objects, nil
+}
@@ -0,0 +1,92 @@
+// Package storage provides idempotent S3 writes with content-addressed keys
+package storage
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    stderrors "errors"
+    "net/http"
+
+    awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http" // v1.21.0
+    "github.com/aws/smithy-go"                                 // v1.13.3
+    "github.com/prometheus/client_golang/prometheus"           // v1.11.0
+    "go.uber.org/zap"                                          // v1.24.0
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+)
+
+// PutStatus reports whether a conditional put uploaded the object
+type PutStatus int
+
+const (
+    // PutCreated means the object was uploaded
+    PutCreated PutStatus = iota
+    // PutAlreadyExists means an object was already stored under the key and
+    // was left untouched
+    PutAlreadyExists
+)
+
+var s3ConditionalSkips = prometheus.NewCounter(
+    prometheus.CounterOpts{
+        Name: "blackpoint_s3_conditional_put_skipped_total",
+        Help: "Total number of conditional S3 puts skipped because the object already existed",
+    },
+)
+
+func init() {
+    prometheus.MustRegister(s3ConditionalSkips)
+}
+
+// ContentKey returns the content-addressed key for data under prefix: the
+// hex SHA-256 of the uncompressed content
+func ContentKey(prefix string, data []byte) string {
+    sum := sha256.Sum256(data)
+    return prefix + hex.EncodeToString(sum[:])
+}
+
+// PutObjectIfAbsent uploads an object only if nothing is stored under key,
+// using an If-None-Match precondition. An existing object is never
+// overwritten; PutAlreadyExists is returned with a nil error instead.
+func (c *S3Client) PutObjectIfAbsent(bucket, key string, data []byte) (PutStatus, error) {
+    err := c.putObject(bucket, key, data, "*")
+    if err == nil {
+        return PutCreated, nil
+    }
+    if isPreconditionFailed(err) {
+        s3ConditionalSkips.Inc()
+        logging.Info("Object already exists in S3, skipped upload",
+            zap.String("bucket", bucket),
+            zap.String("key", key),
+        )
+        return PutAlreadyExists, nil
+    }
+    return PutCreated, err
+}
+
+// PutObjectContentAddressed stores data under its content-addressed key, so
+// reprocessing identical data neither re-uploads nor duplicates it. It
+// returns the key used and whether the object was newly created.
+func (c *S3Client) PutObjectContentAddressed(bucket, prefix string, data []byte) (string, PutStatus, error) {
+    key := ContentKey(prefix, data)
+    status, err := c.PutObjectIfAbsent(bucket, key, data)
+    if err != nil {
+        return "", status, errors.WrapError(err, "failed to store content-addressed object", map[string]interface{}{
+            "bucket": bucket,
+            "key":    key,
+        })
+    }
+    return key, status, nil
+}
+
+// isPreconditionFailed reports whether S3 rejected a conditional put because
+// the object already exists
+func isPreconditionFailed(err error) bool {
+    var apiErr smithy.APIError
+    if stderrors.As(err, &apiErr) && apiErr.ErrorCode() == "PreconditionFailed" {
+        return true
+    }
+
+    var respErr *awshttp.ResponseError
+    return stderrors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusPreconditionFailed
+}
@@ -0,0 +1,318 @@
+// Package storage provides streaming multipart uploads for large S3 objects
+package storage
+
+import (
+    "bytes"
+    "compress/gzip"
+    "context"
+    "io"
+    "sort"
+    "sync"
+
+    "github.com/aws/aws-sdk-go-v2/aws"        // v1.21.0
+    "github.com/aws/aws-sdk-go-v2/service/s3" // v1.21.0
+    "go.uber.org/zap"                         // v1.24.0
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+)
+
+const (
+    // Default size of each multipart upload part
+    defaultMultipartPartSize = 16 * 1024 * 1024
+
+    // S3 rejects parts other than the last that are smaller than 5MB
+    minMultipartPartSize = 5 * 1024 * 1024
+
+    // Default number of parts uploaded concurrently
+    defaultMultipartConcurrency = 4
+)
+
+// uploadPart is one part of a multipart upload awaiting upload
+type uploadPart struct {
+    number int32
+    data   []byte
+}
+
+// PutObjectStream stores a large object from a stream using S3 multipart
+// upload, applying the same compression and KMS encryption as PutObject.
+// Memory is bounded by part size times concurrency. Objects that fit in a
+// single part are uploaded with one PutObject call. On failure the multipart
+// upload is aborted so no orphaned parts are left behind.
+func (c *S3Client) PutObjectStream(bucket, key string, r io.Reader, size int64) error {
+    partSize, concurrency := c.multipartSettings()
+
+    var contentEncoding string
+    body := r
+    if c.config.EnableCompression {
+        compressed := gzipStream(r)
+        defer compressed.Close()
+        body = compressed
+        contentEncoding = "gzip"
+    }
+
+    // The first part decides between a single upload and a multipart upload
+    first := make([]byte, partSize)
+    n, err := io.ReadFull(body, first)
+    if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+        return errors.WrapError(err, "failed to read object stream", map[string]interface{}{
+            "bucket": bucket,
+            "key":    key,
+        })
+    }
+    if n < len(first) {
+        return c.putSinglePart(bucket, key, first[:n], contentEncoding)
+    }
+
+    ctx, cancel := context.WithCancel(c.ctx)
+    defer cancel()
+
+    createCtx, createCancel := context.WithTimeout(ctx, c.config.NetworkTimeout)
+    upload, err := c.s3Client.CreateMultipartUpload(createCtx, &s3.CreateMultipartUploadInput{
+        Bucket:               aws.String(bucket),
+        Key:                  aws.String(key),
+        ContentEncoding:      aws.String(contentEncoding),
+        ServerSideEncryption: aws.String("aws:kms"),
+        SSEKMSKeyId:         aws.String(c.config.KmsKeyAlias),
+        Metadata: map[string]string{
+            "encryption-context": "true",
+        },
+    })
+    createCancel()
+    if err != nil {
+        return errors.WrapError(err, "failed to create multipart upload", map[string]interface{}{
+            "bucket": bucket,
+            "key":    key,
+        })
+    }
+    uploadID := aws.ToString(upload.UploadId)
+
+    parts, err := c.uploadParts(ctx, bucket, key, uploadID, first, body, partSize, concurrency)
+    if err == nil {
+        err = c.completeMultipartUpload(ctx, bucket, key, uploadID, parts)
+    }
+    if err != nil {
+        cancel()
+        c.abortMultipartUpload(bucket, key, uploadID)
+        return err
+    }
+
+    logging.Info("Successfully uploaded object to S3 in parts",
+        zap.String("bucket", bucket),
+        zap.String("key", key),
+        zap.Int64("size", size),
+        zap.Int("parts", len(parts)),
+    )
+
+    return nil
+}
+
+// uploadParts reads the stream in parts and uploads them with a bounded
+// worker pool, returning the completed parts in part order
+func (c *S3Client) uploadParts(ctx context.Context, bucket, key, uploadID string, first []byte, body io.Reader, partSize, concurrency int) ([]s3.CompletedPart, error) {
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+
+    jobs := make(chan uploadPart)
+    var (
+        completed []s3.CompletedPart
+        firstErr  error
+        mu        sync.Mutex
+        wg        sync.WaitGroup
+    )
+    fail := func(err error) {
+        mu.Lock()
+        defer mu.Unlock()
+        if firstErr == nil {
+            firstErr = err
+            cancel()
+        }
+    }
+
+    for i := 0; i < concurrency; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for part := range jobs {
+                etag, err := c.uploadPartWithRetry(ctx, bucket, key, uploadID, part)
+                if err != nil {
+                    fail(err)
+                    continue
+                }
+                mu.Lock()
+                completed = append(completed, s3.CompletedPart{
+                    ETag:       aws.String(etag),
+                    PartNumber: aws.Int32(part.number),
+                })
+                mu.Unlock()
+            }
+        }()
+    }
+
+    // Parts are read sequentially; sending blocks while all workers are busy
+    data := first
+    for number := int32(1); ; number++ {
+        select {
+        case jobs <- uploadPart{number: number, data: data}:
+        case <-ctx.Done():
+        }
+        if ctx.Err() != nil {
+            break
+        }
+
+        data = make([]byte, partSize)
+        n, err := io.ReadFull(body, data)
+        if err == io.EOF {
+            break
+        }
+        if err != nil && err != io.ErrUnexpectedEOF {
+            fail(errors.WrapError(err, "failed to read object stream", map[string]interface{}{
+                "bucket": bucket,
+                "key":    key,
+            }))
+            break
+        }
+        data = data[:n]
+    }
+    close(jobs)
+    wg.Wait()
+
+    if firstErr != nil {
+        return nil, firstErr
+    }
+    if err := ctx.Err(); err != nil {
+        return nil, errors.WrapError(err, "multipart upload cancelled", nil)
+    }
+
+    sort.Slice(completed, func(i, j int) bool {
+        return aws.ToInt32(completed[i].PartNumber) < aws.ToInt32(completed[j].PartNumber)
+    })
+    return completed, nil
+}
+
+// uploadPartWithRetry uploads one part, retrying per the client's RetryConfig
+func (c *S3Client) uploadPartWithRetry(ctx context.Context, bucket, key, uploadID string, part uploadPart) (string, error) {
+    var etag string
+    err := c.withRetry(ctx, "upload_part", func(ctx context.Context) error {
+        result, err := c.s3Client.UploadPart(ctx, &s3.UploadPartInput{
+            Bucket:     aws.String(bucket),
+            Key:        aws.String(key),
+            UploadId:   aws.String(uploadID),
+            PartNumber: aws.Int32(part.number),
+            Body:       bytes.NewReader(part.data),
+        })
+        if err != nil {
+            return err
+        }
+        etag = aws.ToString(result.ETag)
+        return nil
+    })
+    if err != nil {
+        return "", errors.WrapError(err, "failed to upload part", map[string]interface{}{
+            "bucket":      bucket,
+            "key":         key,
+            "part_number": part.number,
+        })
+    }
+    return etag, nil
+}
+
+// completeMultipartUpload assembles the uploaded parts into the final object
+func (c *S3Client) completeMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []s3.CompletedPart) error {
+    ctx, cancel := context.WithTimeout(ctx, c.config.NetworkTimeout)
+    defer cancel()
+
+    _, err := c.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+        Bucket:   aws.String(bucket),
+        Key:      aws.String(key),
+        UploadId: aws.String(uploadID),
+        MultipartUpload: &s3.CompletedMultipartUpload{
+            Parts: parts,
+        },
+    })
+    if err != nil {
+        return errors.WrapError(err, "failed to complete multipart upload", map[string]interface{}{
+            "bucket": bucket,
+            "key":    key,
+        })
+    }
+    return nil
+}
+
+// abortMultipartUpload discards uploaded parts. It uses a fresh context so
+// the cleanup still runs when the upload failed through cancellation.
+func (c *S3Client) abortMultipartUpload(bucket, key, uploadID string) {
+    ctx, cancel := context.WithTimeout(context.Background(), c.config.NetworkTimeout)
+    defer cancel()
+
+    _, err := c.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+        Bucket:   aws.String(bucket),
+        Key:      aws.String(key),
+        UploadId: aws.String(uploadID),
+    })
+    if err != nil {
+        logging.Error("Failed to abort multipart upload, parts may be orphaned",
+            err,
+            zap.String("bucket", bucket),
+            zap.String("key", key),
+            zap.String("upload_id", uploadID),
+        )
+    }
+}
+
+// putSinglePart uploads an already compressed body with a single request
+func (c *S3Client) putSinglePart(bucket, key string, data []byte, contentEncoding string) error {
+    ctx, cancel := context.WithTimeout(c.ctx, c.config.NetworkTimeout)
+    defer cancel()
+
+    _, err := c.s3Client.PutObject(ctx, &s3.PutObjectInput{
+        Bucket:               aws.String(bucket),
+        Key:                  aws.String(key),
+        Body:                 bytes.NewReader(data),
+        ContentEncoding:      aws.String(contentEncoding),
+        ServerSideEncryption: aws.String("aws:kms"),
+        SSEKMSKeyId:         aws.String(c.config.KmsKeyAlias),
+        Metadata: map[string]string{
+            "encryption-context": "true",
+        },
+    })
+    if err != nil {
+        return errors.WrapError(err, "failed to upload object", map[string]interface{}{
+            "bucket": bucket,
+            "key":    key,
+        })
+    }
+    return nil
+}
+
+// multipartSettings returns the configured part size and concurrency with defaults applied
+func (c *S3Client) multipartSettings() (int, int) {
+    partSize := c.config.MultipartPartSize
+    if partSize <= 0 {
+        partSize = defaultMultipartPartSize
+    }
+    if partSize < minMultipartPartSize {
+        partSize = minMultipartPartSize
+    }
+
+    concurrency := c.config.MultipartConcurrency
+    if concurrency <= 0 {
+        concurrency = defaultMultipartConcurrency
+    }
+    return partSize, concurrency
+}
+
+// gzipStream compresses a stream on the fly without buffering it whole.
+// Closing the returned reader stops the compressing goroutine.
+func gzipStream(r io.Reader) io.ReadCloser {
+    pr, pw := io.Pipe()
+    go func() {
+        gw := gzip.NewWriter(pw)
+        if _, err := io.Copy(gw, r); err != nil {
+            pw.CloseWithError(err)
+            return
+        }
+        pw.CloseWithError(gw.Close())
+    }()
+    return pr
+}
@@ -0,0 +1,145 @@
+// Package storage provides presigned S3 URLs for credential-less transfers
+package storage
+
+import (
+    "context"
+    "time"
+
+    "github.com/aws/aws-sdk-go-v2/aws"                  // v1.21.0
+    v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"     // v1.21.0
+    "github.com/aws/aws-sdk-go-v2/service/s3"           // v1.21.0
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+)
+
+// Presigned URL lifetimes
+const (
+    // defaultMaxPresignTTL caps presigned URLs when S3Config.MaxPresignTTL is unset
+    defaultMaxPresignTTL = 1 * time.Hour
+    // sigV4MaxPresignTTL is the longest lifetime SigV4 allows
+    sigV4MaxPresignTTL = 7 * 24 * time.Hour
+)
+
+// S3Presigner is the subset of the S3 presign client used by S3Client
+type S3Presigner interface {
+    PresignGetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+    PresignPutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+}
+
+// requesterKey carries the identity a presigned URL is issued to
+type requesterKey struct{}
+
+// WithRequester returns a context recording who requested a presigned URL,
+// for the security audit log
+func WithRequester(ctx context.Context, requester string) context.Context {
+    return context.WithValue(ctx, requesterKey{}, requester)
+}
+
+// SetPresigner replaces the presigner used for presigned URLs
+func (c *S3Client) SetPresigner(presigner S3Presigner) {
+    c.presigner = presigner
+}
+
+// PresignGetObject returns a URL that downloads an object without AWS
+// credentials until ttl elapses. Objects encrypted with the configured KMS
+// key are decrypted by S3 as they are served.
+func (c *S3Client) PresignGetObject(bucket, key string, ttl time.Duration) (string, error) {
+    return c.PresignGetObjectWithContext(c.ctx, bucket, key, ttl)
+}
+
+// PresignGetObjectWithContext is PresignGetObject with a request context;
+// the requester set by WithRequester is recorded in the audit log
+func (c *S3Client) PresignGetObjectWithContext(ctx context.Context, bucket, key string, ttl time.Duration) (string, error) {
+    if err := c.checkPresign(bucket, key, ttl); err != nil {
+        return "", err
+    }
+
+    req, err := c.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+        Bucket: aws.String(bucket),
+        Key:    aws.String(key),
+    }, s3.WithPresignExpires(ttl))
+    if err != nil {
+        return "", errors.WrapError(err, "failed to presign object download", map[string]interface{}{
+            "bucket": bucket,
+            "key":    key,
+        })
+    }
+
+    auditPresign(ctx, "get_object", bucket, key, ttl)
+    return req.URL, nil
+}
+
+// PresignPutObject returns a URL that uploads an object without AWS
+// credentials until ttl elapses. The upload is encrypted with the configured
+// KMS key; the uploader must send the x-amz-server-side-encryption and
+// x-amz-server-side-encryption-aws-kms-key-id headers the URL was signed with.
+func (c *S3Client) PresignPutObject(bucket, key string, ttl time.Duration) (string, error) {
+    return c.PresignPutObjectWithContext(c.ctx, bucket, key, ttl)
+}
+
+// PresignPutObjectWithContext is PresignPutObject with a request context;
+// the requester set by WithRequester is recorded in the audit log
+func (c *S3Client) PresignPutObjectWithContext(ctx context.Context, bucket, key string, ttl time.Duration) (string, error) {
+    if err := c.checkPresign(bucket, key, ttl); err != nil {
+        return "", err
+    }
+
+    req, err := c.presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+        Bucket:               aws.String(bucket),
+        Key:                  aws.String(key),
+        ServerSideEncryption: aws.String("aws:kms"),
+        SSEKMSKeyId:          aws.String(c.config.KmsKeyAlias),
+    }, s3.WithPresignExpires(ttl))
+    if err != nil {
+        return "", errors.WrapError(err, "failed to presign object upload", map[string]interface{}{
+            "bucket": bucket,
+            "key":    key,
+        })
+    }
+
+    auditPresign(ctx, "put_object", bucket, key, ttl)
+    return req.URL, nil
+}
+
+// checkPresign validates a presign request against the configured maximum TTL
+func (c *S3Client) checkPresign(bucket, key string, ttl time.Duration) error {
+    if c.presigner == nil {
+        return errors.NewError("E2001", "S3 presigner is not configured", nil)
+    }
+    if bucket == "" || key == "" {
+        return errors.NewError("E3001", "bucket and key are required to presign", nil)
+    }
+
+    maxTTL := c.config.MaxPresignTTL
+    if maxTTL <= 0 {
+        maxTTL = defaultMaxPresignTTL
+    }
+    if maxTTL > sigV4MaxPresignTTL {
+        maxTTL = sigV4MaxPresignTTL
+    }
+    if ttl <= 0 || ttl > maxTTL {
+        return errors.NewError("E3001", "presigned URL lifetime out of range", map[string]interface{}{
+            "ttl":     ttl.String(),
+            "max_ttl": maxTTL.String(),
+        })
+    }
+    return nil
+}
+
+// auditPresign records an issued presigned URL. The URL itself grants access
+// and is never logged.
+func auditPresign(ctx context.Context, operation, bucket, key string, ttl time.Duration) {
+    requester, _ := ctx.Value(requesterKey{}).(string)
+    if requester == "" {
+        requester = "unknown"
+    }
+    logging.SecurityAudit("Issued presigned S3 URL", map[string]interface{}{
+        "operation":  operation,
+        "bucket":     bucket,
+        "key":        key,
+        "requester":  requester,
+        "ttl":        ttl.String(),
+        "expires_at": time.Now().UTC().Add(ttl).Format(time.RFC3339),
+    })
+}
@@ -0,0 +1,90 @@
+// Package storage provides retries with backoff for S3 operations
+package storage
+
+import (
+    "context"
+    "math/rand"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus" // v1.11.0
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// Default retry behavior when S3Config.RetryConfig is unset
+const (
+    defaultS3MaxRetries        = 3
+    defaultS3RetryInterval     = 200 * time.Millisecond
+    defaultS3BackoffMultiplier = 2.0
+    maxS3RetryInterval         = 30 * time.Second
+)
+
+var s3Retries = prometheus.NewCounterVec(
+    prometheus.CounterOpts{
+        Name: "blackpoint_s3_retries_total",
+        Help: "Total number of retried S3 operation attempts",
+    },
+    []string{"operation"},
+)
+
+func init() {
+    prometheus.MustRegister(s3Retries)
+}
+
+// withRetry runs an S3 operation, retrying failures classified as transient
+// (throttling, server errors and timeouts) with exponential backoff and
+// jitter. Permanent and unclassified failures are returned at once. Each attempt gets its own NetworkTimeout;
+// cancelling ctx stops the loop without waiting out the backoff.
+func (c *S3Client) withRetry(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+    maxRetries, interval, multiplier := c.retrySettings()
+
+    var err error
+    for attempt := 0; ; attempt++ {
+        attemptCtx, cancel := context.WithTimeout(ctx, c.config.NetworkTimeout)
+        err = fn(attemptCtx)
+        cancel()
+
+        if err == nil || attempt >= maxRetries || ctx.Err() != nil || errors.Classify(err) != errors.Transient {
+            break
+        }
+
+        // Full jitter spreads retries from many workers hitting the same throttle
+        wait := time.Duration(rand.Int63n(int64(interval) + 1))
+        select {
+        case <-ctx.Done():
+            return errors.WrapError(ctx.Err(), "S3 operation cancelled during retry", map[string]interface{}{
+                "operation": operation,
+                "attempts":  attempt + 1,
+            })
+        case <-time.After(wait):
+        }
+
+        s3Retries.WithLabelValues(operation).Inc()
+        interval = time.Duration(float64(interval) * multiplier)
+        if interval > maxS3RetryInterval {
+            interval = maxS3RetryInterval
+        }
+    }
+
+    return err
+}
+
+// retrySettings returns the configured retry behavior with defaults applied
+func (c *S3Client) retrySettings() (int, time.Duration, float64) {
+    maxRetries := defaultS3MaxRetries
+    interval := defaultS3RetryInterval
+    multiplier := defaultS3BackoffMultiplier
+
+    if rc := c.config.RetryConfig; rc != nil {
+        if rc.MaxRetries >= 0 {
+            maxRetries = rc.MaxRetries
+        }
+        if rc.RetryInterval > 0 {
+            interval = rc.RetryInterval
+        }
+        if rc.BackoffMultiplier >= 1 {
+            multiplier = rc.BackoffMultiplier
+        }
+    }
+    return maxRetries, interval, multiplier
+}
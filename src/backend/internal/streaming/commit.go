@@ -0,0 +1,120 @@
+// Package streaming provides offset commit strategies for Kafka consumers
+package streaming
+
+import (
+    "github.com/confluentinc/confluent-kafka-go/kafka" // v1.9.2
+    "../../pkg/common/errors"
+)
+
+// Offset commit strategies
+const (
+    // CommitAuto lets the client commit consumed offsets in the background.
+    // A crash can lose messages that were consumed but not yet processed.
+    CommitAuto = "auto"
+
+    // CommitManualPerBatch commits a batch only after its handler succeeds, so
+    // a crash before the commit reprocesses the batch (at-least-once)
+    CommitManualPerBatch = "manual-per-batch"
+
+    // CommitManualPerMessage never commits on its own; the handler calls
+    // Consumer.Commit for each message once it is fully processed
+    CommitManualPerMessage = "manual-per-message"
+
+    // CommitTransactional never commits on its own; offsets are committed with
+    // the produced output by Producer.PublishTransactional (exactly-once)
+    CommitTransactional = "transactional"
+)
+
+// Default commit strategy and offset reset policy
+const (
+    defaultCommitStrategy  = CommitManualPerBatch
+    defaultAutoOffsetReset = "earliest"
+)
+
+// applyCommitStrategy validates the strategy and returns a copy of the client
+// configuration with matching commit settings
+func applyCommitStrategy(config *kafka.ConfigMap, options ConsumerOptions) (*kafka.ConfigMap, error) {
+    switch options.CommitStrategy {
+    case CommitAuto, CommitManualPerBatch, CommitManualPerMessage, CommitTransactional:
+    default:
+        return nil, errors.NewError("E2001", "unsupported commit strategy", map[string]interface{}{
+            "commit_strategy": options.CommitStrategy,
+        })
+    }
+    switch options.AutoOffsetReset {
+    case "earliest", "latest", "error":
+    default:
+        return nil, errors.NewError("E2001", "unsupported auto offset reset policy", map[string]interface{}{
+            "auto_offset_reset": options.AutoOffsetReset,
+        })
+    }
+
+    // Copy so the caller's configuration can be reused for other clients
+    consumerConfig := kafka.ConfigMap{}
+    for key, value := range *config {
+        consumerConfig[key] = value
+    }
+    consumerConfig["enable.auto.commit"] = options.CommitStrategy == CommitAuto
+    consumerConfig["auto.offset.reset"] = options.AutoOffsetReset
+    if options.CommitStrategy == CommitTransactional {
+        // Transactional pipelines must not read output of aborted transactions
+        consumerConfig["isolation.level"] = "read_committed"
+    }
+
+    return &consumerConfig, nil
+}
+
+// Commit commits a processed message so it is not redelivered after a restart.
+// Committing a message also commits every earlier message of its partition.
+func (c *Consumer) Commit(msg *Message) error {
+    if c.options.CommitStrategy == CommitAuto {
+        return errors.NewError("E2001", "manual commits are not allowed with the auto commit strategy", nil)
+    }
+    if c.options.CommitStrategy == CommitTransactional {
+        return errors.NewError("E2001", "offsets are committed by the producer transaction with the transactional commit strategy", nil)
+    }
+
+    topic := msg.Topic
+    _, err := c.consumer.CommitOffsets([]kafka.TopicPartition{{
+        Topic:     &topic,
+        Partition: msg.Partition,
+        Offset:    kafka.Offset(msg.Offset + 1),
+    }})
+    if err != nil {
+        return errors.WrapError(err, "failed to commit message offset", map[string]interface{}{
+            "topic":     msg.Topic,
+            "partition": msg.Partition,
+            "offset":    msg.Offset,
+        })
+    }
+    return nil
+}
+
+// commitBatch commits the next offset of every partition in a batch. A batch
+// may span partitions, so committing only its last message would leave the
+// other partitions' progress uncommitted.
+func (c *Consumer) commitBatch(batch []*kafka.Message) error {
+    next := make(map[TopicPartition]kafka.Offset)
+    for _, msg := range batch {
+        if msg.TopicPartition.Topic == nil {
+            continue
+        }
+        tp := TopicPartition{Topic: *msg.TopicPartition.Topic, Partition: msg.TopicPartition.Partition}
+        if offset := msg.TopicPartition.Offset + 1; offset > next[tp] {
+            next[tp] = offset
+        }
+    }
+
+    offsets := make([]kafka.TopicPartition, 0, len(next))
+    for tp, offset := range next {
+        topic := tp.Topic
+        offsets = append(offsets, kafka.TopicPartition{Topic: &topic, Partition: tp.Partition, Offset: offset})
+    }
+
+    if _, err := c.consumer.CommitOffsets(offsets); err != nil {
+        return errors.WrapError(err, "failed to commit batch offsets", map[string]interface{}{
+            "partitions": len(offsets),
+        })
+    }
+    return nil
+}
@@ -159,7 +159,12 @@ func (c *KafkaClient) GetConfig() *kafka.ConfigMap {
     c.mu.RLock()
     defer c.mu.RUnlock()
 
-    configCopy := *c.baseConfig
+    // Copy the entries so producers and consumers can set their own keys
+    // without changing the client's base configuration
+    configCopy := make(kafka.ConfigMap, len(*c.baseConfig))
+    for key, value := range *c.baseConfig {
+        configCopy[key] = value
+    }
     return &configCopy
 }
 
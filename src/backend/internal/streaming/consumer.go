@@ -22,17 +22,82 @@ const (
     retryInterval      = 1 * time.Second
 )
 
+// Default fetch and group membership tuning
+const (
+    defaultMaxPollRecords    = 10000
+    defaultFetchMinBytes     = 1
+    defaultFetchMaxWaitMs    = 500
+    defaultSessionTimeoutMs  = 45000
+    defaultMaxPollIntervalMs = 300000
+    maxPollRecordsLimit      = 10000000
+    maxFetchMinBytes         = 100000000
+    maxFetchMaxWaitMs        = 300000
+    // Bounds of the broker's group.min/max.session.timeout.ms defaults
+    minSessionTimeoutMs      = 6000
+    maxSessionTimeoutMs      = 1800000
+)
+
 // ConsumerOptions defines configuration options for the consumer
 type ConsumerOptions struct {
     BatchSize      int
     CommitInterval time.Duration
     PollTimeout    int
     EnableMetrics  bool
+    // RequireTLS refuses to start unless the config uses SSL or SASL_SSL
+    RequireTLS     bool
+    // MaxMessageAge skips messages older than this age, seeking past them on
+    // assignment. Skipped messages are not handed to the handler but their
+    // offsets are committed with the batch they arrived in.
+    MaxMessageAge  time.Duration
+    // StartFromTime seeks newly assigned partitions to this time when set
+    StartFromTime  time.Time
+    // Serializer decodes messages in Message.Decode; JSON is assumed when nil
+    Serializer     Serializer
+    // CommitStrategy controls when offsets are committed; defaults to manual-per-batch
+    CommitStrategy string
+    // AutoOffsetReset is where a group without committed offsets starts; defaults to earliest
+    AutoOffsetReset string
+    // RevocationTimeout bounds how long a rebalance waits for in-flight
+    // batches of revoked partitions to finish and commit; defaults to 30s
+    RevocationTimeout time.Duration
+    // MaxPollRecords is how many messages per partition the client prefetches
+    // ahead of processing (queued.min.messages); defaults to 10000
+    MaxPollRecords int
+    // FetchMinBytes is the least data a broker returns for a fetch request
+    // (fetch.min.bytes); defaults to 1
+    FetchMinBytes int
+    // FetchMaxWaitMs is how long a broker may wait to fill FetchMinBytes
+    // (fetch.wait.max.ms); defaults to 500
+    FetchMaxWaitMs int
+    // SessionTimeoutMs is how long the group waits for a heartbeat before
+    // evicting this consumer (session.timeout.ms); defaults to 45000.
+    //
+    // Heartbeats come from the client's background thread, so slow batches
+    // do not expire the session; they are bounded by max.poll.interval.ms
+    // instead. A batch handler that retries for longer than
+    // max.poll.interval.ms blocks polling once the buffer fills, the consumer
+    // is evicted, and its partitions are reassigned, so every member rejoins
+    // and the batch is redelivered: a rebalance storm. Keep worst-case batch
+    // processing and RevocationTimeout well below max.poll.interval.ms, and
+    // keep SessionTimeoutMs, which must not exceed it, short enough that a
+    // crashed consumer is replaced quickly.
+    SessionTimeoutMs int
+}
+
+// BatchHandler processes a batch of consumed messages. With the
+// manual-per-batch strategy offsets are committed only when the handler
+// returns nil; a failed batch is retried until it succeeds or the consumer stops.
+type BatchHandler func(ctx context.Context, messages []*Message) error
+
+// FlowControl signals when a consumer should stop fetching to relieve downstream pressure
+type FlowControl interface {
+    Paused() bool
 }
 
 // Consumer represents an enhanced Kafka consumer with performance monitoring
 type Consumer struct {
     consumer       *kafka.Consumer
+    config        *kafka.ConfigMap
     topics        []string
     messages      chan *kafka.Message
     ctx           context.Context
@@ -40,6 +105,11 @@ type Consumer struct {
     monitor       *PerformanceMonitor
     metrics       *MetricsCollector
     options       ConsumerOptions
+    flowControl   FlowControl
+    handler       BatchHandler
+    revocations   chan revocation
+    paused        bool
+    background    sync.WaitGroup
     mu            sync.RWMutex
 }
 
@@ -77,6 +147,44 @@ func NewConsumer(config *kafka.ConfigMap, topics []string, options ConsumerOptio
     if options.PollTimeout == 0 {
         options.PollTimeout = defaultPollTimeout
     }
+    if options.Serializer == nil {
+        options.Serializer = jsonSerializer{}
+    }
+    if options.CommitStrategy == "" {
+        options.CommitStrategy = defaultCommitStrategy
+    }
+    if options.AutoOffsetReset == "" {
+        options.AutoOffsetReset = defaultAutoOffsetReset
+    }
+    if options.RevocationTimeout == 0 {
+        options.RevocationTimeout = defaultRevocationTimeout
+    }
+    if options.MaxPollRecords == 0 {
+        options.MaxPollRecords = defaultMaxPollRecords
+    }
+    if options.FetchMinBytes == 0 {
+        options.FetchMinBytes = defaultFetchMinBytes
+    }
+    if options.FetchMaxWaitMs == 0 {
+        options.FetchMaxWaitMs = defaultFetchMaxWaitMs
+    }
+    if options.SessionTimeoutMs == 0 {
+        options.SessionTimeoutMs = defaultSessionTimeoutMs
+    }
+
+    if options.RequireTLS {
+        if err := verifyTransportSecurity(config, "consumer"); err != nil {
+            return nil, err
+        }
+    }
+
+    config, err := applyCommitStrategy(config, options)
+    if err != nil {
+        return nil, err
+    }
+    if err := applyFetchTuning(config, options); err != nil {
+        return nil, err
+    }
 
     // Create Kafka consumer
     consumer, err := kafka.NewConsumer(config)
@@ -84,18 +192,14 @@ func NewConsumer(config *kafka.ConfigMap, topics []string, options ConsumerOptio
         return nil, errors.WrapError(err, "failed to create Kafka consumer", nil)
     }
 
-    // Subscribe to topics
-    if err := consumer.SubscribeTopics(topics, nil); err != nil {
-        consumer.Close()
-        return nil, errors.WrapError(err, "failed to subscribe to topics", nil)
-    }
-
     ctx, cancel := context.WithCancel(context.Background())
 
     c := &Consumer{
         consumer: consumer,
+        config:   config,
         topics:   topics,
         messages: make(chan *kafka.Message, options.BatchSize*2),
+        revocations: make(chan revocation),
         ctx:      ctx,
         cancel:   cancel,
         options:  options,
@@ -109,6 +213,13 @@ func NewConsumer(config *kafka.ConfigMap, topics []string, options ConsumerOptio
         },
     }
 
+    // Subscribe to topics, finishing in-flight work before partitions are revoked
+    if err := consumer.SubscribeTopics(topics, c.onRebalance); err != nil {
+        cancel()
+        consumer.Close()
+        return nil, errors.WrapError(err, "failed to subscribe to topics", nil)
+    }
+
     logging.Info("Created new Kafka consumer",
         logging.Field("topics", topics),
         logging.Field("batch_size", options.BatchSize),
@@ -117,12 +228,63 @@ func NewConsumer(config *kafka.ConfigMap, topics []string, options ConsumerOptio
     return c, nil
 }
 
+// applyFetchTuning validates the fetch and session options and sets them on
+// the consumer's copy of the configuration
+func applyFetchTuning(config *kafka.ConfigMap, options ConsumerOptions) error {
+    details := map[string]interface{}{
+        "max_poll_records":   options.MaxPollRecords,
+        "fetch_min_bytes":    options.FetchMinBytes,
+        "fetch_max_wait_ms":  options.FetchMaxWaitMs,
+        "session_timeout_ms": options.SessionTimeoutMs,
+    }
+    if options.MaxPollRecords < 1 || options.MaxPollRecords > maxPollRecordsLimit {
+        return errors.NewError("E2001", "max poll records out of range", details)
+    }
+    if options.FetchMinBytes < 1 || options.FetchMinBytes > maxFetchMinBytes {
+        return errors.NewError("E2001", "fetch min bytes out of range", details)
+    }
+    if options.FetchMaxWaitMs < 0 || options.FetchMaxWaitMs > maxFetchMaxWaitMs {
+        return errors.NewError("E2001", "fetch max wait out of range", details)
+    }
+    if options.SessionTimeoutMs < minSessionTimeoutMs || options.SessionTimeoutMs > maxSessionTimeoutMs {
+        return errors.NewError("E2001", "session timeout out of range", details)
+    }
+
+    // The client refuses a session timeout longer than the poll interval
+    pollInterval := defaultMaxPollIntervalMs
+    if value, err := config.Get("max.poll.interval.ms", defaultMaxPollIntervalMs); err == nil {
+        if ms, ok := value.(int); ok {
+            pollInterval = ms
+        }
+    }
+    if options.SessionTimeoutMs > pollInterval {
+        details["max_poll_interval_ms"] = pollInterval
+        return errors.NewError("E2001", "session timeout exceeds max poll interval", details)
+    }
+
+    config.SetKey("queued.min.messages", options.MaxPollRecords)
+    config.SetKey("fetch.min.bytes", options.FetchMinBytes)
+    config.SetKey("fetch.wait.max.ms", options.FetchMaxWaitMs)
+    config.SetKey("session.timeout.ms", options.SessionTimeoutMs)
+    return nil
+}
+
+// Config returns a copy of the configuration the Kafka consumer was created with
+func (c *Consumer) Config() kafka.ConfigMap {
+    config := kafka.ConfigMap{}
+    for key, value := range *c.config {
+        config[key] = value
+    }
+    return config
+}
+
 // Start begins consuming messages with performance monitoring
 func (c *Consumer) Start() error {
     c.mu.Lock()
     defer c.mu.Unlock()
 
     // Start message polling
+    c.background.Add(2)
     go c.pollMessages()
 
     // Start batch processing
@@ -131,6 +293,12 @@ func (c *Consumer) Start() error {
     // Start performance monitoring
     go c.monitorPerformance()
 
+    // Publish per-partition lag
+    if c.options.EnableMetrics {
+        c.background.Add(1)
+        go c.reportLag()
+    }
+
     logging.Info("Started Kafka consumer",
         logging.Field("topics", c.topics),
     )
@@ -145,8 +313,10 @@ func (c *Consumer) Stop() error {
 
     c.cancel()
 
-    // Wait for in-flight messages
-    close(c.messages)
+    // Wait for polling, batch processing and lag reporting to exit. Lag
+    // queries are time-bounded, so this wait cannot stall shutdown. Batches
+    // not yet committed are redelivered after a restart.
+    c.background.Wait()
 
     if err := c.consumer.Close(); err != nil {
         return errors.WrapError(err, "failed to close consumer", nil)
@@ -159,13 +329,31 @@ func (c *Consumer) Stop() error {
     return nil
 }
 
+// SetFlowControl installs a flow control signal that pauses and resumes fetching
+func (c *Consumer) SetFlowControl(fc FlowControl) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.flowControl = fc
+}
+
+// SetHandler installs the handler that receives each consumed batch with decoded headers
+func (c *Consumer) SetHandler(handler BatchHandler) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.handler = handler
+}
+
 // pollMessages continuously polls for new messages
 func (c *Consumer) pollMessages() {
+    defer c.background.Done()
+
     for {
         select {
         case <-c.ctx.Done():
             return
         default:
+            c.applyFlowControl()
+
             msg, err := c.consumer.ReadMessage(time.Duration(c.options.PollTimeout) * time.Millisecond)
             if err != nil {
                 if !err.(kafka.Error).IsTimeout() {
@@ -180,13 +368,62 @@ func (c *Consumer) pollMessages() {
                 continue
             }
 
-            c.messages <- msg
+            select {
+            case c.messages <- msg:
+            case <-c.ctx.Done():
+                return
+            }
         }
     }
 }
 
+// applyFlowControl pauses or resumes the assigned partitions when the flow control
+// state changes. Polling continues while paused so the consumer keeps its group
+// membership instead of exceeding max.poll.interval.ms.
+func (c *Consumer) applyFlowControl() {
+    c.mu.RLock()
+    fc := c.flowControl
+    c.mu.RUnlock()
+
+    if fc == nil {
+        return
+    }
+
+    shouldPause := fc.Paused()
+    if shouldPause == c.paused {
+        return
+    }
+
+    assignment, err := c.consumer.Assignment()
+    if err != nil || len(assignment) == 0 {
+        return
+    }
+
+    if shouldPause {
+        err = c.consumer.Pause(assignment)
+    } else {
+        err = c.consumer.Resume(assignment)
+    }
+    if err != nil {
+        logging.Error("Failed to apply consumer flow control",
+            err,
+            logging.Field("topics", c.topics),
+            logging.Field("pause", shouldPause),
+        )
+        return
+    }
+
+    c.paused = shouldPause
+    logging.Info("Consumer flow control changed",
+        logging.Field("topics", c.topics),
+        logging.Field("paused", shouldPause),
+    )
+}
+
 // processBatches processes messages in batches
 func (c *Consumer) processBatches() {
+    defer c.background.Done()
+
     batch := make([]*kafka.Message, 0, c.options.BatchSize)
     commitTicker := time.NewTicker(c.options.CommitInterval)
     defer commitTicker.Stop()
@@ -202,24 +439,53 @@ func (c *Consumer) processBatches() {
 
             batch = append(batch, msg)
             if len(batch) >= c.options.BatchSize {
-                c.processBatch(batch)
+                if !c.processUntilDone(batch) {
+                    return
+                }
                 batch = make([]*kafka.Message, 0, c.options.BatchSize)
             }
+        case req := <-c.revocations:
+            batch = c.drainRevoked(req, batch)
         case <-commitTicker.C:
             if len(batch) > 0 {
-                c.processBatch(batch)
+                if !c.processUntilDone(batch) {
+                    return
+                }
                 batch = make([]*kafka.Message, 0, c.options.BatchSize)
             }
         }
     }
 }
 
-// processBatch processes a batch of messages
-func (c *Consumer) processBatch(batch []*kafka.Message) {
+// processUntilDone retries a failed batch so later commits never skip past it.
+// It returns false if the consumer stopped first, leaving the batch uncommitted.
+func (c *Consumer) processUntilDone(batch []*kafka.Message) bool {
+    return c.processUntil(c.ctx, batch)
+}
+
+// processUntil retries a failed batch until it succeeds or ctx is done
+func (c *Consumer) processUntil(ctx context.Context, batch []*kafka.Message) bool {
+    live := c.dropStale(batch)
+    for {
+        if err := c.processBatch(batch, live); err == nil || c.options.CommitStrategy == CommitAuto {
+            return true
+        }
+
+        select {
+        case <-ctx.Done():
+            return false
+        case <-time.After(retryInterval):
+        }
+    }
+}
+
+// processBatch hands the live messages of a batch to the handler and commits
+// the whole batch, returning the handler's error
+func (c *Consumer) processBatch(batch, live []*kafka.Message) error {
     start := time.Now()
 
     // Process messages
-    for _, msg := range batch {
+    for _, msg := range live {
         // Track processing time by tier
         tier := determineTier(msg)
         processingTime := time.Since(start)
@@ -229,21 +495,50 @@ func (c *Consumer) processBatch(batch []*kafka.Message) {
         c.monitor.mu.Unlock()
     }
 
-    // Commit offsets
-    if err := c.consumer.CommitMessage(batch[len(batch)-1]); err != nil {
-        logging.Error("Failed to commit offsets",
-            err,
-            logging.Field("batch_size", len(batch)),
-        )
+    // Hand the batch to the processor before committing so failures are redelivered
+    c.mu.RLock()
+    handler := c.handler
+    c.mu.RUnlock()
+
+    if handler != nil && len(live) > 0 {
+        messages := make([]*Message, len(live))
+        for i, msg := range live {
+            messages[i] = newMessage(msg)
+            messages[i].serializer = c.options.Serializer
+            messages[i].ctx = ExtractTraceContext(c.ctx, messages[i].Headers)
+        }
+        if err := handler(c.ctx, messages); err != nil {
+            logging.Error("Batch handler failed, offsets not committed",
+                err,
+                logging.Field("batch_size", len(live)),
+            )
+            c.metrics.mu.Lock()
+            c.metrics.Errors++
+            c.metrics.mu.Unlock()
+            return err
+        }
+    }
+
+    // Commit offsets, including those of skipped stale messages; per-message
+    // commits are made by the handler
+    if c.options.CommitStrategy == CommitManualPerBatch {
+        if err := c.commitBatch(batch); err != nil {
+            logging.Error("Failed to commit offsets",
+                err,
+                logging.Field("batch_size", len(batch)),
+            )
+        }
     }
 
     // Update metrics
     c.metrics.mu.Lock()
-    c.metrics.EventsProcessed += uint64(len(batch))
+    c.metrics.EventsProcessed += uint64(len(live))
     c.metrics.ProcessingTime += time.Since(start)
     c.metrics.BatchSizes = append(c.metrics.BatchSizes, len(batch))
     c.metrics.LastUpdated = time.Now()
     c.metrics.mu.Unlock()
+
+    return nil
 }
 
 // monitorPerformance monitors consumer performance
@@ -326,6 +621,28 @@ func contains(s, substr string) bool {
     return len(s) >= len(substr) && s[len(s)-len(substr):] == substr
 }
 
+// Ping verifies the consumer can reach the brokers by fetching the metadata
+// of its first topic within the deadline of ctx
+func (c *Consumer) Ping(ctx context.Context) error {
+    timeout := time.Duration(c.options.PollTimeout) * time.Millisecond
+    if deadline, ok := ctx.Deadline(); ok {
+        timeout = time.Until(deadline)
+    }
+    if timeout <= 0 {
+        return errors.NewError("E2002", "kafka ping timed out", map[string]interface{}{
+            "topics": c.topics,
+        })
+    }
+
+    topic := c.topics[0]
+    if _, err := c.consumer.GetMetadata(&topic, false, int(timeout.Milliseconds())); err != nil {
+        return errors.WrapError(err, "kafka ping failed", map[string]interface{}{
+            "topics": c.topics,
+        })
+    }
+    return nil
+}
+
 // GetMetrics returns current consumer metrics
 func (c *Consumer) GetMetrics() *MetricsCollector {
     c.metrics.mu.RLock()
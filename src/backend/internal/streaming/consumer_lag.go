@@ -0,0 +1,150 @@
+// Package streaming provides per-partition lag reporting for Kafka consumers
+package streaming
+
+import (
+    "strconv"
+    "time"
+
+    "github.com/confluentinc/confluent-kafka-go/kafka" // v1.9.2
+    "github.com/prometheus/client_golang/prometheus" // v1.16.0
+    "../../pkg/common/errors"
+    "../../pkg/common/logging"
+)
+
+// Interval between lag measurements published as metrics
+const lagReportInterval = 15 * time.Second
+
+var consumerLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+    Name: "blackpoint_consumer_lag",
+    Help: "Messages between the committed offset and the high watermark of each assigned partition",
+}, []string{"group", "topic", "partition"})
+
+func init() {
+    prometheus.MustRegister(consumerLag)
+}
+
+// TopicPartition identifies a single partition of a topic
+type TopicPartition struct {
+    Topic     string
+    Partition int32
+}
+
+// Lag returns the lag of each partition currently assigned to this consumer:
+// the distance between the group's committed offset and the high watermark.
+// The whole query is bounded by one timeout so a slow broker cannot stall callers.
+func (c *Consumer) Lag() (map[TopicPartition]int64, error) {
+    assignment, err := c.consumer.Assignment()
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to fetch partition assignment", map[string]interface{}{
+            "topics": c.topics,
+        })
+    }
+
+    return queryPartitionLag(c.consumer, assignment, defaultLagQueryTimeout)
+}
+
+// queryPartitionLag compares committed offsets with high watermarks. Partitions
+// without a committed offset count from the low watermark.
+func queryPartitionLag(client *kafka.Consumer, partitions []kafka.TopicPartition, timeout time.Duration) (map[TopicPartition]int64, error) {
+    lag := make(map[TopicPartition]int64, len(partitions))
+    if len(partitions) == 0 {
+        return lag, nil
+    }
+
+    deadline := time.Now().Add(timeout)
+    remainingMs := func() (int, error) {
+        remaining := time.Until(deadline)
+        if remaining <= 0 {
+            return 0, errors.NewError("E2002", "lag query timed out", map[string]interface{}{
+                "timeout": timeout,
+            })
+        }
+        return int(remaining / time.Millisecond), nil
+    }
+
+    timeoutMs, err := remainingMs()
+    if err != nil {
+        return nil, err
+    }
+    committed, err := client.Committed(partitions, timeoutMs)
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to fetch committed offsets", nil)
+    }
+
+    for _, tp := range committed {
+        timeoutMs, err := remainingMs()
+        if err != nil {
+            return nil, err
+        }
+        low, high, err := client.QueryWatermarkOffsets(*tp.Topic, tp.Partition, timeoutMs)
+        if err != nil {
+            return nil, errors.WrapError(err, "failed to query watermark offsets", map[string]interface{}{
+                "topic":     *tp.Topic,
+                "partition": tp.Partition,
+            })
+        }
+
+        position := int64(tp.Offset)
+        if position < 0 {
+            position = low
+        }
+        partitionLag := high - position
+        if partitionLag < 0 {
+            partitionLag = 0
+        }
+        lag[TopicPartition{Topic: *tp.Topic, Partition: tp.Partition}] = partitionLag
+    }
+
+    return lag, nil
+}
+
+// reportLag publishes partition lag until the consumer stops. Series of
+// partitions revoked by a rebalance are removed so they do not go stale.
+func (c *Consumer) reportLag() {
+    defer c.background.Done()
+
+    group := c.groupID()
+    reported := make(map[TopicPartition]bool)
+
+    ticker := time.NewTicker(lagReportInterval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-c.ctx.Done():
+            return
+        case <-ticker.C:
+        }
+
+        lag, err := c.Lag()
+        if err != nil {
+            if c.ctx.Err() == nil {
+                logging.Error("Failed to measure consumer lag", err,
+                    logging.Field("topics", c.topics),
+                )
+            }
+            continue
+        }
+
+        for tp := range reported {
+            if _, assigned := lag[tp]; !assigned {
+                consumerLag.DeleteLabelValues(group, tp.Topic, strconv.Itoa(int(tp.Partition)))
+                delete(reported, tp)
+            }
+        }
+        for tp, partitionLag := range lag {
+            consumerLag.WithLabelValues(group, tp.Topic, strconv.Itoa(int(tp.Partition))).Set(float64(partitionLag))
+            reported[tp] = true
+        }
+    }
+}
+
+// groupID returns the consumer group the consumer belongs to
+func (c *Consumer) groupID() string {
+    group, err := c.config.Get("group.id", "")
+    if err != nil {
+        return ""
+    }
+    id, _ := group.(string)
+    return id
+}
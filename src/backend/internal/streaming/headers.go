@@ -0,0 +1,129 @@
+// Package streaming provides metadata header propagation for Kafka messages
+package streaming
+
+import (
+    "context"
+    "sort"
+    "strconv"
+    "time"
+
+    "github.com/confluentinc/confluent-kafka-go/kafka" // v1.9.2
+)
+
+// Well-known metadata headers used for routing and filtering without
+// deserializing payloads
+const (
+    HeaderTenant        = "tenant"
+    HeaderTraceID       = "trace_id"
+    HeaderSchemaVersion = "schema_version"
+    HeaderPriority      = "priority"
+)
+
+// Headers set by the producer itself; callers cannot override them
+const (
+    headerSource = "source"
+    headerBatch  = "batch"
+)
+
+// headerSourceValue identifies messages produced by this framework
+var headerSourceValue = []byte("blackpoint-security")
+
+// Message is a consumed Kafka message with its headers decoded for the processor
+type Message struct {
+    Topic     string
+    Partition int32
+    Offset    int64
+    Key       []byte
+    Value     []byte
+    Headers   map[string]string
+    Timestamp time.Time
+
+    serializer Serializer
+    ctx        context.Context
+}
+
+// Decode deserializes the message value into v with the consumer's Serializer
+func (m *Message) Decode(v interface{}) error {
+    serializer := m.serializer
+    if serializer == nil {
+        serializer = jsonSerializer{}
+    }
+    return serializer.Deserialize(m.Topic, m.Value, v)
+}
+
+// Context returns a context carrying the trace of the producer that
+// published the message, so handler spans continue that trace
+func (m *Message) Context() context.Context {
+    if m.ctx == nil {
+        return context.Background()
+    }
+    return m.ctx
+}
+
+// Header returns the value of a metadata header, or an empty string when absent
+func (m *Message) Header(key string) string {
+    return m.Headers[key]
+}
+
+// ID identifies the message by topic, partition and offset, which stay the
+// same when the message is redelivered
+func (m *Message) ID() string {
+    return m.Topic + "/" + strconv.FormatInt(int64(m.Partition), 10) + "/" + strconv.FormatInt(m.Offset, 10)
+}
+
+// buildHeaders combines the producer's reserved headers with caller metadata.
+// Caller headers are emitted in key order so identical metadata always
+// produces identical messages.
+func buildHeaders(batch bool, metadata map[string]string) []kafka.Header {
+    headers := make([]kafka.Header, 0, len(metadata)+2)
+    headers = append(headers, kafka.Header{Key: headerSource, Value: headerSourceValue})
+    if batch {
+        headers = append(headers, kafka.Header{Key: headerBatch, Value: []byte("true")})
+    }
+
+    keys := make([]string, 0, len(metadata))
+    for key := range metadata {
+        if key == "" || key == headerSource || key == headerBatch {
+            continue
+        }
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+
+    for _, key := range keys {
+        headers = append(headers, kafka.Header{Key: key, Value: []byte(metadata[key])})
+    }
+    return headers
+}
+
+// mergeHeaders returns the shared headers overridden by a message's own
+func mergeHeaders(shared, own map[string]string) map[string]string {
+    merged := make(map[string]string, len(shared)+len(own))
+    for key, value := range shared {
+        merged[key] = value
+    }
+    for key, value := range own {
+        merged[key] = value
+    }
+    return merged
+}
+
+// newMessage converts a Kafka message for delivery to a batch handler. When a
+// header repeats, the last value wins.
+func newMessage(msg *kafka.Message) *Message {
+    m := &Message{
+        Partition: msg.TopicPartition.Partition,
+        Offset:    int64(msg.TopicPartition.Offset),
+        Key:       msg.Key,
+        Value:     msg.Value,
+        Headers:   make(map[string]string, len(msg.Headers)),
+        Timestamp: msg.Timestamp,
+    }
+    if msg.TopicPartition.Topic != nil {
+        m.Topic = *msg.TopicPartition.Topic
+    }
+    for _, header := range msg.Headers {
+        m.Headers[header.Key] = string(header.Value)
+    }
+    return m
+}
@@ -0,0 +1,98 @@
+// Package streaming provides consumer group lag measurement for back-pressure decisions
+package streaming
+
+import (
+    "time"
+
+    "github.com/confluentinc/confluent-kafka-go/kafka" // v1.9.2
+    "../../pkg/common/errors"
+)
+
+// Default timeout for lag metadata and offset queries
+const defaultLagQueryTimeout = 5 * time.Second
+
+// LagMonitor measures the total lag of another service's consumer group. It
+// uses a dedicated client bound to the target group that never subscribes,
+// so querying lag does not trigger a rebalance of the monitored group.
+type LagMonitor struct {
+    client  *kafka.Consumer
+    group   string
+    topics  []string
+    timeout time.Duration
+}
+
+// NewLagMonitor creates a LagMonitor for the given consumer group and topics
+func NewLagMonitor(config *kafka.ConfigMap, group string, topics []string) (*LagMonitor, error) {
+    if group == "" {
+        return nil, errors.NewError("E2001", "consumer group is required for lag monitoring", nil)
+    }
+    if len(topics) == 0 {
+        return nil, errors.NewError("E2001", "no topics specified for lag monitoring", nil)
+    }
+
+    // Copy the base configuration so the caller's group settings are untouched
+    monitorConfig := kafka.ConfigMap{}
+    for key, value := range *config {
+        monitorConfig[key] = value
+    }
+    monitorConfig["group.id"] = group
+    monitorConfig["enable.auto.commit"] = false
+
+    client, err := kafka.NewConsumer(&monitorConfig)
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to create lag monitor client", map[string]interface{}{
+            "group": group,
+        })
+    }
+
+    return &LagMonitor{
+        client:  client,
+        group:   group,
+        topics:  topics,
+        timeout: defaultLagQueryTimeout,
+    }, nil
+}
+
+// Lag returns the total number of messages the group has yet to commit across all partitions
+func (m *LagMonitor) Lag() (int64, error) {
+    timeoutMs := int(m.timeout / time.Millisecond)
+
+    var partitions []kafka.TopicPartition
+    for _, topic := range m.topics {
+        topic := topic
+        metadata, err := m.client.GetMetadata(&topic, false, timeoutMs)
+        if err != nil {
+            return 0, errors.WrapError(err, "failed to fetch topic metadata", map[string]interface{}{
+                "topic": topic,
+            })
+        }
+        for _, partition := range metadata.Topics[topic].Partitions {
+            partitions = append(partitions, kafka.TopicPartition{
+                Topic:     &topic,
+                Partition: partition.ID,
+            })
+        }
+    }
+
+    lag, err := queryPartitionLag(m.client, partitions, m.timeout)
+    if err != nil {
+        return 0, errors.WrapError(err, "failed to measure group lag", map[string]interface{}{
+            "group": m.group,
+        })
+    }
+
+    var total int64
+    for _, partitionLag := range lag {
+        total += partitionLag
+    }
+
+    return total, nil
+}
+
+// Close releases the monitor's Kafka client
+func (m *LagMonitor) Close() error {
+    if err := m.client.Close(); err != nil {
+        return errors.WrapError(err, "failed to close lag monitor", nil)
+    }
+    return nil
+}
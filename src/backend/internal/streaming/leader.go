@@ -0,0 +1,81 @@
+// Package streaming provides leadership-gated consumption for Kafka consumers
+package streaming
+
+import (
+    "context"
+    "sync"
+
+    "../../pkg/common/errors"
+    "../../pkg/common/logging"
+)
+
+// LeaderConsumer runs a Consumer only while this replica holds leadership.
+// Standby replicas stay out of the consumer group, so no partition is ever
+// assigned to a replica that will not process it. Elected and Demoted are
+// installed as leader election hooks; each leadership term gets a fresh
+// consumer because a stopped Consumer cannot be restarted.
+type LeaderConsumer struct {
+    newConsumer func() (*Consumer, error)
+    handler     BatchHandler
+    consumer    *Consumer
+    mu          sync.Mutex
+}
+
+// NewLeaderConsumer creates a LeaderConsumer that builds its consumer with
+// newConsumer and hands every batch to handler
+func NewLeaderConsumer(newConsumer func() (*Consumer, error), handler BatchHandler) (*LeaderConsumer, error) {
+    if newConsumer == nil || handler == nil {
+        return nil, errors.NewError("E2001", "consumer factory and handler are required", nil)
+    }
+    return &LeaderConsumer{newConsumer: newConsumer, handler: handler}, nil
+}
+
+// Elected joins the consumer group and starts consuming; it is the OnElected hook
+func (lc *LeaderConsumer) Elected(ctx context.Context) error {
+    lc.mu.Lock()
+    defer lc.mu.Unlock()
+    if lc.consumer != nil {
+        return nil
+    }
+
+    consumer, err := lc.newConsumer()
+    if err != nil {
+        return errors.WrapError(err, "failed to create leader consumer", nil)
+    }
+    consumer.SetHandler(lc.handler)
+    if err := consumer.Start(); err != nil {
+        consumer.Stop()
+        return errors.WrapError(err, "failed to start leader consumer", nil)
+    }
+    lc.consumer = consumer
+    return nil
+}
+
+// Demoted leaves the consumer group so the new leader is assigned every
+// partition; it is the OnDemoted hook
+func (lc *LeaderConsumer) Demoted() {
+    if err := lc.Stop(); err != nil {
+        logging.Error("Failed to stop consumer after losing leadership", err)
+    }
+}
+
+// Active reports whether a consumer is currently running
+func (lc *LeaderConsumer) Active() bool {
+    lc.mu.Lock()
+    defer lc.mu.Unlock()
+    return lc.consumer != nil
+}
+
+// Stop stops the running consumer, if any. Batches not yet committed are
+// redelivered to the next leader. It can be registered with a Coordinator.
+func (lc *LeaderConsumer) Stop() error {
+    lc.mu.Lock()
+    consumer := lc.consumer
+    lc.consumer = nil
+    lc.mu.Unlock()
+
+    if consumer == nil {
+        return nil
+    }
+    return consumer.Stop()
+}
@@ -0,0 +1,55 @@
+// Package streaming provides key-based partition assignment for Kafka producers
+package streaming
+
+// Partitioner used for keyed messages. murmur2_random hashes keys the same way
+// as the Java client, so every producer maps a key to the same partition;
+// messages without a key are spread randomly.
+const keyedPartitioner = "murmur2_random"
+
+// PartitionForKey returns the partition a non-empty key is produced to on a
+// topic with numPartitions partitions, mirroring the producer's partitioner
+func PartitionForKey(key []byte, numPartitions int32) int32 {
+    if numPartitions <= 0 {
+        return 0
+    }
+    return (murmur2(key) & 0x7fffffff) % numPartitions
+}
+
+// murmur2 is the 32-bit MurmurHash2 variant used by Kafka's default partitioner
+func murmur2(data []byte) int32 {
+    const (
+        seed uint32 = 0x9747b28c
+        m    uint32 = 0x5bd1e995
+        r           = 24
+    )
+
+    length := len(data)
+    h := seed ^ uint32(length)
+
+    for i := 0; i+4 <= length; i += 4 {
+        k := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+        k *= m
+        k ^= k >> r
+        k *= m
+        h *= m
+        h ^= k
+    }
+
+    tail := length &^ 3
+    switch length % 4 {
+    case 3:
+        h ^= uint32(data[tail+2]) << 16
+        fallthrough
+    case 2:
+        h ^= uint32(data[tail+1]) << 8
+        fallthrough
+    case 1:
+        h ^= uint32(data[tail])
+        h *= m
+    }
+
+    h ^= h >> 13
+    h *= m
+    h ^= h >> 15
+    return int32(h)
+}
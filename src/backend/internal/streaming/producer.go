@@ -4,6 +4,7 @@ package streaming
 import (
     "context"
     "sync"
+    "sync/atomic"
     "time"
 
     "github.com/confluentinc/confluent-kafka-go/kafka" // v1.9.2
@@ -21,8 +22,66 @@ const (
     defaultBackoffMax = 2 * time.Second
     defaultCircuitBreakerThreshold = 0.5
     defaultCircuitBreakerTimeout = 30 * time.Second
+    defaultHalfOpenMaxProbes = 1
+    defaultCompression = CompressionSnappy
+    defaultMaxInFlight = 10000
+    deliveryReportBuffer = 1000
 )
 
+// Producer compression codecs
+const (
+    CompressionNone = "none"
+    CompressionSnappy = "snappy"
+    CompressionLZ4 = "lz4"
+    CompressionGzip = "gzip"
+    CompressionZstd = "zstd"
+)
+
+// supportedCompression lists the codecs accepted by ProducerOptions.Compression
+var supportedCompression = []string{
+    CompressionNone,
+    CompressionSnappy,
+    CompressionLZ4,
+    CompressionGzip,
+    CompressionZstd,
+}
+
+// Circuit breaker states
+const (
+    CircuitClosed = "closed"
+    CircuitOpen = "open"
+    CircuitHalfOpen = "half_open"
+)
+
+// circuitStateValues maps breaker states to blackpoint_kafka_circuit_breaker_state values
+var circuitStateValues = map[string]float64{
+    CircuitClosed: 0,
+    CircuitOpen: 1,
+    CircuitHalfOpen: 2,
+}
+
+// permanentKafkaCodes are produce errors caused by the message or the
+// producer's credentials rather than broker health
+var permanentKafkaCodes = map[kafka.ErrorCode]bool{
+    kafka.ErrMsgSizeTooLarge: true,
+    kafka.ErrInvalidMsg: true,
+    kafka.ErrInvalidRecord: true,
+    kafka.ErrRecordListTooLarge: true,
+    kafka.ErrTopicAuthorizationFailed: true,
+    kafka.ErrClusterAuthorizationFailed: true,
+    kafka.ErrTransactionalIDAuthorizationFailed: true,
+    kafka.ErrSaslAuthenticationFailed: true,
+}
+
+var circuitBreakerState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+    Name: "blackpoint_kafka_circuit_breaker_state",
+    Help: "Producer circuit breaker state per topic (0 = closed, 1 = open, 2 = half-open)",
+}, []string{"topic"})
+
+func init() {
+    prometheus.MustRegister(circuitBreakerState)
+}
+
 // ProducerOptions configures the behavior of the Producer
 type ProducerOptions struct {
     DeliveryTimeout time.Duration
@@ -32,27 +91,78 @@ type ProducerOptions struct {
     BackoffMax time.Duration
     CircuitBreakerThreshold float64
     CircuitBreakerTimeout time.Duration
+    // HalfOpenMaxProbes is how many requests may probe a half-open breaker at once
+    HalfOpenMaxProbes int
+    // Serializer encodes values passed to PublishValue; events are JSON encoded when nil
+    Serializer Serializer
+    // RequireTLS refuses to start unless the client uses SSL or SASL_SSL
+    RequireTLS bool
+    // Compression is the codec for produced batches: none, snappy, lz4, gzip
+    // or zstd. Defaults to snappy.
+    Compression string
+    // TransactionalID enables the transactional API; it must be stable across
+    // restarts of the same producer and unique among concurrent producers
+    TransactionalID string
+    // MaxInFlight bounds the asynchronous publishes awaiting delivery;
+    // PublishAsync fails fast once it is reached
+    MaxInFlight int
 }
 
-// CircuitBreaker implements circuit breaking for producer operations
+// CircuitBreaker implements circuit breaking for producer operations. After
+// tripping it stays open for timeout, then half-opens to let a limited number
+// of probes through: a successful probe closes it, a failed one re-opens it.
 type CircuitBreaker struct {
+    topic string
+    state string
     failures uint64
     total uint64
     threshold float64
     timeout time.Duration
     lastTrip time.Time
+    maxProbes int
+    probes int
+    probeStart time.Time
+    now func() time.Time
     mu sync.RWMutex
 }
 
+// CircuitBreakerOptions configures a circuit breaker created with NewCircuitBreaker
+type CircuitBreakerOptions struct {
+    // Threshold is the failure ratio that trips a closed breaker
+    Threshold float64
+    // Timeout is how long a tripped breaker stays open before half-opening
+    Timeout time.Duration
+    // HalfOpenMaxProbes is how many requests may probe a half-open breaker at once
+    HalfOpenMaxProbes int
+    // Now returns the current time; time.Now is used when nil
+    Now func() time.Time
+}
+
 // Producer implements a high-performance Kafka producer with monitoring and circuit breaking
 type Producer struct {
     producer *kafka.Producer
     client *KafkaClient
+    config *kafka.ConfigMap
     topic string
     deliveryTimeout time.Duration
     messagePool *sync.Pool
     circuitBreaker *CircuitBreaker
+    serializer Serializer
+    compression string
+    transactional bool
     metricsRecorder *prometheus.Recorder
+    // Asynchronous publishing state; delivery reports are handled by one goroutine
+    deliveries chan kafka.Event
+    deliveriesDone chan struct{}
+    inFlight atomic.Int64
+    maxInFlight int64
+    closeOnce sync.Once
+}
+
+// asyncDelivery carries a PublishAsync callback through the delivery report
+type asyncDelivery struct {
+    callback func(error)
+    start time.Time
 }
 
 // NewProducer creates a new Producer instance with optimized configuration
@@ -89,17 +199,49 @@ func NewProducer(client *KafkaClient, topic string, opts *ProducerOptions) (*Pro
     if opts.CircuitBreakerTimeout == 0 {
         opts.CircuitBreakerTimeout = defaultCircuitBreakerTimeout
     }
+    if opts.HalfOpenMaxProbes <= 0 {
+        opts.HalfOpenMaxProbes = defaultHalfOpenMaxProbes
+    }
+    if opts.Serializer == nil {
+        opts.Serializer = jsonSerializer{}
+    }
+    if opts.Compression == "" {
+        opts.Compression = defaultCompression
+    }
+    if opts.MaxInFlight < 0 {
+        return nil, errors.NewError("E2001", "max in-flight messages must not be negative", map[string]interface{}{
+            "max_in_flight": opts.MaxInFlight,
+        })
+    }
+    if opts.MaxInFlight == 0 {
+        opts.MaxInFlight = defaultMaxInFlight
+    }
+    if !isSupportedCompression(opts.Compression) {
+        return nil, errors.NewError("E2001", "unsupported producer compression codec", map[string]interface{}{
+            "compression": opts.Compression,
+            "supported": supportedCompression,
+        })
+    }
 
     // Get base configuration from client
     config := client.GetConfig()
+    if opts.RequireTLS {
+        if err := verifyTransportSecurity(config, "producer"); err != nil {
+            return nil, err
+        }
+    }
 
     // Configure producer-specific settings
     config.SetKey("enable.idempotence", true)
-    config.SetKey("compression.type", "snappy")
+    config.SetKey("compression.type", opts.Compression)
     config.SetKey("batch.size", opts.BatchSize)
     config.SetKey("linger.ms", 20)
+    config.SetKey("partitioner", keyedPartitioner)
     config.SetKey("retries", opts.RetryAttempts)
     config.SetKey("delivery.timeout.ms", int(opts.DeliveryTimeout.Milliseconds()))
+    if opts.TransactionalID != "" {
+        config.SetKey("transactional.id", opts.TransactionalID)
+    }
 
     // Create Kafka producer
     producer, err := kafka.NewProducer(config)
@@ -107,6 +249,19 @@ func NewProducer(client *KafkaClient, topic string, opts *ProducerOptions) (*Pro
         return nil, errors.WrapError(err, "failed to create kafka producer", nil)
     }
 
+    // Register the transactional ID, fencing out any previous instance
+    if opts.TransactionalID != "" {
+        initCtx, cancel := context.WithTimeout(context.Background(), opts.DeliveryTimeout)
+        err := producer.InitTransactions(initCtx)
+        cancel()
+        if err != nil {
+            producer.Close()
+            return nil, errors.WrapError(err, "failed to initialize kafka transactions", map[string]interface{}{
+                "transactional_id": opts.TransactionalID,
+            })
+        }
+    }
+
     // Initialize message pool for memory optimization
     messagePool := &sync.Pool{
         New: func() interface{} {
@@ -119,10 +274,7 @@ func NewProducer(client *KafkaClient, topic string, opts *ProducerOptions) (*Pro
     }
 
     // Initialize circuit breaker
-    circuitBreaker := &CircuitBreaker{
-        threshold: opts.CircuitBreakerThreshold,
-        timeout: opts.CircuitBreakerTimeout,
-    }
+    circuitBreaker := newCircuitBreaker(topic, opts.CircuitBreakerThreshold, opts.CircuitBreakerTimeout, opts.HalfOpenMaxProbes)
 
     // Initialize metrics recorder
     metricsRecorder := prometheus.NewRecorder(prometheus.RecorderOpts{
@@ -133,16 +285,25 @@ func NewProducer(client *KafkaClient, topic string, opts *ProducerOptions) (*Pro
     p := &Producer{
         producer: producer,
         client: client,
+        config: config,
         topic: topic,
         deliveryTimeout: opts.DeliveryTimeout,
         messagePool: messagePool,
         circuitBreaker: circuitBreaker,
+        serializer: opts.Serializer,
+        compression: opts.Compression,
+        transactional: opts.TransactionalID != "",
         metricsRecorder: metricsRecorder,
+        deliveries: make(chan kafka.Event, deliveryReportBuffer),
+        deliveriesDone: make(chan struct{}),
+        maxInFlight: int64(opts.MaxInFlight),
     }
+    go p.handleDeliveries()
 
     logging.Info("Kafka producer initialized",
         logging.Field("topic", topic),
         logging.Field("batch_size", opts.BatchSize),
+        logging.Field("compression", opts.Compression),
     )
 
     return p, nil
@@ -150,30 +311,80 @@ func NewProducer(client *KafkaClient, topic string, opts *ProducerOptions) (*Pro
 
 // Publish publishes a single event to Kafka with delivery guarantees
 func (p *Producer) Publish(ctx context.Context, event []byte) error {
-    if err := p.circuitBreaker.Allow(); err != nil {
-        return errors.WrapError(err, "circuit breaker open", nil)
+    return p.PublishWithHeaders(ctx, event, nil)
+}
+
+// PublishWithHeaders publishes a single event with caller metadata such as
+// tenant or trace ID attached as Kafka headers
+func (p *Producer) PublishWithHeaders(ctx context.Context, event []byte, headers map[string]string) error {
+    return p.publish(ctx, nil, event, headers)
+}
+
+// PublishValue serializes an event such as a BronzeEvent or SilverEvent with
+// the configured Serializer and publishes it
+func (p *Producer) PublishValue(ctx context.Context, value interface{}) error {
+    event, err := p.serializer.Serialize(p.topic, value)
+    if err != nil {
+        return err
+    }
+    return p.publish(ctx, nil, event, nil)
+}
+
+// PublishValues serializes events with the configured Serializer and
+// publishes them as one batch. When headers is non-nil, headers[i] is
+// attached to values[i], so each event can carry its own trace context.
+func (p *Producer) PublishValues(ctx context.Context, values []interface{}, headers []map[string]string) error {
+    if headers != nil && len(headers) != len(values) {
+        return errors.NewError("E3001", "each event requires its headers", map[string]interface{}{
+            "headers": len(headers),
+            "events":  len(values),
+        })
+    }
+
+    events := make([][]byte, len(values))
+    for i, value := range values {
+        event, err := p.serializer.Serialize(p.topic, value)
+        if err != nil {
+            return err
+        }
+        events[i] = event
     }
+    return p.publishBatch(ctx, nil, events, nil, headers)
+}
 
+// PublishWithKey publishes a single event with a message key. All events with
+// the same key, e.g. a ClientID, land on the same partition and are consumed
+// in the order they were published; unkeyed events have no ordering guarantee.
+func (p *Producer) PublishWithKey(ctx context.Context, key, event []byte) error {
+    if len(key) == 0 {
+        return errors.NewError("E3001", "message key is required", nil)
+    }
+    return p.publish(ctx, key, event, nil)
+}
+
+// publish produces one event and waits for its delivery
+func (p *Producer) publish(ctx context.Context, key, event []byte, headers map[string]string) error {
     if len(event) == 0 {
         return errors.NewError("E3001", "event data is required", nil)
     }
 
+    if err := p.circuitBreaker.Allow(); err != nil {
+        return errors.WrapError(err, "circuit breaker open", nil)
+    }
+
     startTime := time.Now()
     msg := p.messagePool.Get().(*kafka.Message)
     defer p.messagePool.Put(msg)
 
+    // Pooled messages are reused, so the key is always overwritten
+    msg.Key = key
     msg.Value = event
     msg.Timestamp = time.Now()
-    msg.Headers = []kafka.Header{
-        {
-            Key: "source",
-            Value: []byte("blackpoint-security"),
-        },
-    }
+    msg.Headers = buildHeaders(false, InjectTraceContext(ctx, headers))
 
     deliveryChan := make(chan kafka.Event, 1)
     if err := p.producer.Produce(msg, deliveryChan); err != nil {
-        p.circuitBreaker.RecordFailure()
+        p.recordFailure(err)
         return errors.WrapError(err, "failed to produce message", nil)
     }
 
@@ -183,7 +394,7 @@ func (p *Producer) Publish(ctx context.Context, event []byte) error {
     case ev := <-deliveryChan:
         if e, ok := ev.(*kafka.Message); ok {
             if e.TopicPartition.Error != nil {
-                p.circuitBreaker.RecordFailure()
+                p.recordFailure(e.TopicPartition.Error)
                 return errors.WrapError(e.TopicPartition.Error, "message delivery failed", nil)
             }
             p.circuitBreaker.RecordSuccess()
@@ -197,12 +408,145 @@ func (p *Producer) Publish(ctx context.Context, event []byte) error {
     }
 }
 
-// PublishBatch efficiently publishes multiple events with parallel delivery tracking
-func (p *Producer) PublishBatch(ctx context.Context, events [][]byte) error {
+// PublishAsync enqueues an event without waiting for its delivery, carrying
+// the trace context of ctx in its headers. The callback, if any, runs on the
+// producer's delivery-report goroutine with the delivery result, so it must
+// not block. An error is returned instead of invoking the callback when the
+// event cannot be enqueued, including when MaxInFlight publishes are already
+// awaiting delivery.
+func (p *Producer) PublishAsync(ctx context.Context, event []byte, cb func(error)) error {
+    if len(event) == 0 {
+        return errors.NewError("E3001", "event data is required", nil)
+    }
+
+    if p.inFlight.Add(1) > p.maxInFlight {
+        p.inFlight.Add(-1)
+        return errors.NewError("E4002", "too many in-flight messages", map[string]interface{}{
+            "topic": p.topic,
+            "max_in_flight": p.maxInFlight,
+        })
+    }
+
     if err := p.circuitBreaker.Allow(); err != nil {
+        p.inFlight.Add(-1)
         return errors.WrapError(err, "circuit breaker open", nil)
     }
 
+    msg := p.messagePool.Get().(*kafka.Message)
+    defer p.messagePool.Put(msg)
+
+    msg.Key = nil
+    msg.Value = event
+    msg.Timestamp = time.Now()
+    msg.Headers = buildHeaders(false, InjectTraceContext(ctx, nil))
+    msg.Opaque = &asyncDelivery{callback: cb, start: msg.Timestamp}
+
+    // The produced copy keeps the opaque; the pooled message must not
+    defer func() { msg.Opaque = nil }()
+
+    if err := p.producer.Produce(msg, p.deliveries); err != nil {
+        p.inFlight.Add(-1)
+        p.recordFailure(err)
+        return errors.WrapError(err, "failed to produce message", nil)
+    }
+    return nil
+}
+
+// InFlight returns the number of asynchronous publishes awaiting delivery
+func (p *Producer) InFlight() int {
+    return int(p.inFlight.Load())
+}
+
+// Flush waits until every asynchronous publish has been delivered and its
+// callback has run, or timeout expires. Collectors call it before draining so
+// no accepted event is lost on shutdown.
+func (p *Producer) Flush(timeout time.Duration) error {
+    deadline := time.Now().Add(timeout)
+    p.producer.Flush(int(timeout.Milliseconds()))
+
+    // Delivery reports may still be queued for the callback goroutine
+    for p.inFlight.Load() > 0 {
+        if time.Now().After(deadline) {
+            return errors.NewError("E4001", "flush timeout exceeded", map[string]interface{}{
+                "topic": p.topic,
+                "in_flight": p.inFlight.Load(),
+            })
+        }
+        time.Sleep(time.Millisecond)
+    }
+    return nil
+}
+
+// handleDeliveries reports the delivery of asynchronous publishes until the
+// producer is closed
+func (p *Producer) handleDeliveries() {
+    defer close(p.deliveriesDone)
+
+    for ev := range p.deliveries {
+        msg, ok := ev.(*kafka.Message)
+        if !ok {
+            continue
+        }
+        delivery, ok := msg.Opaque.(*asyncDelivery)
+        if !ok {
+            continue
+        }
+
+        err := msg.TopicPartition.Error
+        if err != nil {
+            p.recordFailure(err)
+            err = errors.WrapError(err, "message delivery failed", nil)
+        } else {
+            p.circuitBreaker.RecordSuccess()
+            p.recordMetrics("async", time.Since(delivery.start), 1)
+        }
+
+        p.inFlight.Add(-1)
+        if delivery.callback != nil {
+            delivery.callback(err)
+        }
+    }
+}
+
+// PublishBatch efficiently publishes multiple events with parallel delivery tracking
+func (p *Producer) PublishBatch(ctx context.Context, events [][]byte) error {
+    return p.PublishBatchWithHeaders(ctx, events, nil)
+}
+
+// PublishBatchWithHeaders publishes multiple events, attaching the same caller
+// metadata headers to every message in the batch
+func (p *Producer) PublishBatchWithHeaders(ctx context.Context, events [][]byte, headers map[string]string) error {
+    return p.publishBatch(ctx, nil, events, headers, nil)
+}
+
+// PublishBatchWithKeys publishes multiple events, keying events[i] with keys[i].
+// Events sharing a key keep their relative batch order on their partition.
+func (p *Producer) PublishBatchWithKeys(ctx context.Context, keys, events [][]byte) error {
+    if len(keys) != len(events) {
+        return errors.NewError("E3001", "each event requires a key", map[string]interface{}{
+            "keys":   len(keys),
+            "events": len(events),
+        })
+    }
+    return p.publishBatch(ctx, keys, events, nil, nil)
+}
+
+// PublishBatchWithMessageHeaders publishes multiple events, attaching
+// headers[i] to events[i]. Events collected under different traces keep
+// their own trace context; events without one inherit the trace of ctx.
+func (p *Producer) PublishBatchWithMessageHeaders(ctx context.Context, events [][]byte, headers []map[string]string) error {
+    if len(headers) != len(events) {
+        return errors.NewError("E3001", "each event requires its headers", map[string]interface{}{
+            "headers": len(headers),
+            "events":  len(events),
+        })
+    }
+    return p.publishBatch(ctx, nil, events, nil, headers)
+}
+
+// publishBatch produces events, keyed when keys is non-nil, and waits for
+// delivery. Per-message headers, when given, override the shared headers.
+func (p *Producer) publishBatch(ctx context.Context, keys, events [][]byte, headers map[string]string, messageHeaders []map[string]string) error {
     if len(events) == 0 {
         return nil
     }
@@ -210,40 +554,54 @@ func (p *Producer) PublishBatch(ctx context.Context, events [][]byte) error {
         return errors.NewError("E3001", "batch size exceeds limit", nil)
     }
 
+    if err := p.circuitBreaker.Allow(); err != nil {
+        return errors.WrapError(err, "circuit breaker open", nil)
+    }
+
     startTime := time.Now()
     var wg sync.WaitGroup
     errChan := make(chan error, len(events))
     deliveryChan := make(chan kafka.Event, len(events))
 
-    for _, event := range events {
+    for i, event := range events {
         if len(event) == 0 {
             continue
         }
 
+        // Reset the key of every pooled message so keys never leak between batches
         msg := p.messagePool.Get().(*kafka.Message)
+        msg.Key = nil
+        if keys != nil {
+            msg.Key = keys[i]
+        }
         msg.Value = event
         msg.Timestamp = time.Now()
-        msg.Headers = []kafka.Header{
-            {
-                Key: "source",
-                Value: []byte("blackpoint-security"),
-            },
-            {
-                Key: "batch",
-                Value: []byte("true"),
-            },
+        metadata := headers
+        if messageHeaders != nil && len(messageHeaders[i]) > 0 {
+            metadata = mergeHeaders(headers, messageHeaders[i])
+        }
+        if !hasTraceContext(metadata) {
+            metadata = InjectTraceContext(ctx, metadata)
         }
+        msg.Headers = buildHeaders(true, metadata)
 
-        wg.Add(1)
-        go func(m *kafka.Message) {
+        produce := func(m *kafka.Message) {
             defer wg.Done()
             defer p.messagePool.Put(m)
 
             if err := p.producer.Produce(m, deliveryChan); err != nil {
                 errChan <- errors.WrapError(err, "failed to produce batch message", nil)
-                p.circuitBreaker.RecordFailure()
+                p.recordFailure(err)
             }
-        }(msg)
+        }
+
+        // Keyed messages are enqueued in batch order to preserve per-key ordering
+        wg.Add(1)
+        if keys != nil {
+            produce(msg)
+        } else {
+            go produce(msg)
+        }
     }
 
     // Wait for all messages to be produced
@@ -277,7 +635,7 @@ func (p *Producer) PublishBatch(ctx context.Context, events [][]byte) error {
         case ev := <-deliveryChan:
             if e, ok := ev.(*kafka.Message); ok {
                 if e.TopicPartition.Error != nil {
-                    p.circuitBreaker.RecordFailure()
+                    p.recordFailure(e.TopicPartition.Error)
                     return errors.WrapError(e.TopicPartition.Error, "batch message delivery failed", nil)
                 }
                 deliveredCount++
@@ -290,65 +648,234 @@ func (p *Producer) PublishBatch(ctx context.Context, events [][]byte) error {
     return nil
 }
 
-// Close gracefully shuts down the producer
+// Compression returns the compression.type the Kafka producer was configured with
+func (p *Producer) Compression() string {
+    codec, err := p.config.Get("compression.type", "")
+    if err != nil {
+        return ""
+    }
+    value, _ := codec.(string)
+    return value
+}
+
+// CircuitState returns the state of the producer's circuit breaker
+func (p *Producer) CircuitState() string {
+    return p.circuitBreaker.State()
+}
+
+// Ping verifies the producer can publish: its circuit is not open and the
+// brokers serving its topic answer a metadata request
+func (p *Producer) Ping(ctx context.Context) error {
+    if p.CircuitState() == CircuitOpen {
+        return errors.NewError("E2002", "kafka producer circuit is open", map[string]interface{}{
+            "topic": p.topic,
+        })
+    }
+
+    timeout := p.deliveryTimeout
+    if deadline, ok := ctx.Deadline(); ok {
+        timeout = time.Until(deadline)
+    }
+    if timeout <= 0 {
+        return errors.NewError("E2002", "kafka ping timed out", map[string]interface{}{
+            "topic": p.topic,
+        })
+    }
+
+    topic := p.topic
+    if _, err := p.producer.GetMetadata(&topic, false, int(timeout.Milliseconds())); err != nil {
+        return errors.WrapError(err, "kafka ping failed", map[string]interface{}{
+            "topic": p.topic,
+        })
+    }
+    return nil
+}
+
+// Close gracefully shuts down the producer. It is safe to call more than
+// once, e.g. from both a lifecycle stage and a deferred cleanup.
 func (p *Producer) Close() error {
-    // Wait for any in-flight deliveries
-    p.producer.Flush(int(p.deliveryTimeout.Milliseconds()))
-    p.producer.Close()
+    p.closeOnce.Do(func() {
+        // Wait for any in-flight deliveries
+        if err := p.Flush(p.deliveryTimeout); err != nil {
+            logging.Error("Closing producer with undelivered messages",
+                err,
+                logging.Field("topic", p.topic),
+            )
+        }
+        p.producer.Close()
+
+        // No delivery reports arrive once the Kafka producer is closed
+        close(p.deliveries)
+        <-p.deliveriesDone
+    })
     return nil
 }
 
+// newCircuitBreaker creates a closed circuit breaker for a topic
+func newCircuitBreaker(topic string, threshold float64, timeout time.Duration, maxProbes int) *CircuitBreaker {
+    return NewCircuitBreaker(topic, CircuitBreakerOptions{
+        Threshold: threshold,
+        Timeout: timeout,
+        HalfOpenMaxProbes: maxProbes,
+    })
+}
+
+// NewCircuitBreaker creates a closed circuit breaker for a topic, using the
+// producer defaults for unset options
+func NewCircuitBreaker(topic string, opts CircuitBreakerOptions) *CircuitBreaker {
+    if opts.Threshold == 0 {
+        opts.Threshold = defaultCircuitBreakerThreshold
+    }
+    if opts.Timeout == 0 {
+        opts.Timeout = defaultCircuitBreakerTimeout
+    }
+    // A half-open breaker that admits no probes could never close again
+    if opts.HalfOpenMaxProbes <= 0 {
+        opts.HalfOpenMaxProbes = defaultHalfOpenMaxProbes
+    }
+    if opts.Now == nil {
+        opts.Now = time.Now
+    }
+    c := &CircuitBreaker{
+        topic: topic,
+        threshold: opts.Threshold,
+        timeout: opts.Timeout,
+        maxProbes: opts.HalfOpenMaxProbes,
+        now: opts.Now,
+    }
+    c.setState(CircuitClosed)
+    return c
+}
+
 // Allow checks if the circuit breaker allows operations
 func (c *CircuitBreaker) Allow() error {
-    c.mu.RLock()
-    defer c.mu.RUnlock()
+    c.mu.Lock()
+    defer c.mu.Unlock()
 
-    if c.lastTrip.IsZero() {
+    switch c.state {
+    case CircuitOpen:
+        if c.now().Sub(c.lastTrip) <= c.timeout {
+            return errors.NewError("E4002", "circuit breaker is open", nil)
+        }
+        c.setState(CircuitHalfOpen)
+        c.probes = 0
+        c.probeStart = c.now()
+    case CircuitHalfOpen:
+        // A probe that never reported back must not wedge the breaker
+        if c.now().Sub(c.probeStart) > c.timeout {
+            c.probes = 0
+            c.probeStart = c.now()
+        }
+    default:
         return nil
     }
 
-    if time.Since(c.lastTrip) > c.timeout {
-        c.mu.RUnlock()
-        c.mu.Lock()
-        c.failures = 0
-        c.total = 0
-        c.lastTrip = time.Time{}
-        c.mu.Unlock()
-        c.mu.RLock()
-        return nil
+    if c.probes >= c.maxProbes {
+        return errors.NewError("E4002", "circuit breaker is half-open, probe in progress", nil)
     }
-
-    return errors.NewError("E4002", "circuit breaker is open", nil)
+    c.probes++
+    return nil
 }
 
-// RecordSuccess records a successful operation
+// RecordSuccess records a successful operation, closing a half-open breaker
 func (c *CircuitBreaker) RecordSuccess() {
     c.mu.Lock()
     defer c.mu.Unlock()
+
+    if c.state == CircuitHalfOpen {
+        c.failures = 0
+        c.total = 0
+        c.lastTrip = time.Time{}
+        c.setState(CircuitClosed)
+        return
+    }
     c.total++
 }
 
-// RecordFailure records a failed operation
+// RecordFailure records a failed operation, re-opening a half-open breaker
 func (c *CircuitBreaker) RecordFailure() {
     c.mu.Lock()
     defer c.mu.Unlock()
+
+    if c.state == CircuitHalfOpen {
+        c.trip()
+        return
+    }
+
     c.failures++
     c.total++
 
-    if c.total > 0 && float64(c.failures)/float64(c.total) >= c.threshold {
-        c.lastTrip = time.Now()
+    if c.state == CircuitClosed && c.total > 0 && float64(c.failures)/float64(c.total) >= c.threshold {
+        c.trip()
     }
 }
 
+// State returns the current breaker state: closed, open or half_open
+func (c *CircuitBreaker) State() string {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    return c.state
+}
+
+// trip opens the breaker for another timeout window; callers hold c.mu
+func (c *CircuitBreaker) trip() {
+    c.lastTrip = c.now()
+    c.probes = 0
+    c.setState(CircuitOpen)
+
+    logging.Info("Producer circuit breaker opened",
+        logging.Field("topic", c.topic),
+        logging.Field("timeout", c.timeout),
+    )
+}
+
+// setState updates the state and its gauge; callers hold c.mu
+func (c *CircuitBreaker) setState(state string) {
+    c.state = state
+    circuitBreakerState.WithLabelValues(c.topic).Set(circuitStateValues[state])
+}
+
+// recordFailure counts a failure against the circuit breaker unless it is
+// permanent. A message the broker will never accept, such as one that is too
+// large or unauthorized, fails fast without tripping the breaker for
+// healthy traffic.
+func (p *Producer) recordFailure(err error) {
+    if classifyProduceError(err) == errors.Permanent {
+        return
+    }
+    p.circuitBreaker.RecordFailure()
+}
+
+// classifyProduceError extends errors.Classify with broker rejections that
+// Kafka reports as non-fatal but that no retry will fix
+func classifyProduceError(err error) errors.ErrorClass {
+    if kafkaErr, ok := err.(kafka.Error); ok && permanentKafkaCodes[kafkaErr.Code()] {
+        return errors.Permanent
+    }
+    return errors.Classify(err)
+}
+
 // recordMetrics records producer performance metrics
 func (p *Producer) recordMetrics(operation string, duration time.Duration, count int) {
     p.metricsRecorder.WithLabelValues(
         "operation", operation,
         "topic", p.topic,
+        "compression", p.compression,
     ).Observe(float64(duration.Milliseconds()))
 
     p.metricsRecorder.WithLabelValues(
         "messages", "count",
         "topic", p.topic,
+        "compression", p.compression,
     ).Add(float64(count))
+}
+
+// isSupportedCompression reports whether codec is a supported compression codec
+func isSupportedCompression(codec string) bool {
+    for _, supported := range supportedCompression {
+        if codec == supported {
+            return true
+        }
+    }
+    return false
 }
\ No newline at end of file
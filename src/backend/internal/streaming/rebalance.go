@@ -0,0 +1,154 @@
+// Package streaming provides graceful partition revocation for Kafka consumers
+package streaming
+
+import (
+    "context"
+    "time"
+
+    "github.com/confluentinc/confluent-kafka-go/kafka" // v1.9.2
+    "github.com/prometheus/client_golang/prometheus" // v1.16.0
+    "../../pkg/common/errors"
+    "../../pkg/common/logging"
+)
+
+// Default bound on how long a revocation waits for in-flight batches
+const defaultRevocationTimeout = 30 * time.Second
+
+var (
+    consumerRebalances = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "blackpoint_consumer_rebalances_total",
+        Help: "Total number of consumer group rebalance events by type (assigned, revoked)",
+    }, []string{"type"})
+
+    revocationDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+        Name:    "blackpoint_consumer_revocation_duration_seconds",
+        Help:    "Time taken to finish in-flight batches and commit before releasing revoked partitions",
+        Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30},
+    })
+)
+
+func init() {
+    prometheus.MustRegister(consumerRebalances, revocationDuration)
+}
+
+// revocation asks the batch loop to finish every message received for the
+// partitions being revoked before they are released
+type revocation struct {
+    partitions []kafka.TopicPartition
+    deadline   time.Time
+    done       chan struct{}
+}
+
+// onRebalance is the consumer's rebalance callback. It runs on the polling
+// goroutine, so no messages are read while a revocation is being handled.
+func (c *Consumer) onRebalance(consumer *kafka.Consumer, ev kafka.Event) error {
+    switch e := ev.(type) {
+    case kafka.AssignedPartitions:
+        consumerRebalances.WithLabelValues("assigned").Inc()
+        if c.options.MaxMessageAge > 0 || !c.options.StartFromTime.IsZero() {
+            return c.seekPastStale(consumer, ev)
+        }
+        return consumer.Assign(e.Partitions)
+
+    case kafka.RevokedPartitions:
+        consumerRebalances.WithLabelValues("revoked").Inc()
+        start := time.Now()
+        c.finishRevoked(consumer, e.Partitions)
+        revocationDuration.Observe(time.Since(start).Seconds())
+        return consumer.Unassign()
+    }
+    return nil
+}
+
+// finishRevoked pauses the revoked partitions and waits until the batch loop
+// has processed and committed everything received from them, so the next
+// owner resumes exactly where this consumer stopped. If that takes longer
+// than the revocation timeout the partitions are released anyway and their
+// unfinished messages are redelivered to the next owner.
+func (c *Consumer) finishRevoked(consumer *kafka.Consumer, partitions []kafka.TopicPartition) {
+    if err := consumer.Pause(partitions); err != nil {
+        logging.Error("Failed to pause revoked partitions",
+            err,
+            logging.Field("topics", c.topics),
+        )
+    }
+
+    req := revocation{
+        partitions: partitions,
+        deadline:   time.Now().Add(c.options.RevocationTimeout),
+        done:       make(chan struct{}),
+    }
+    timeout := time.NewTimer(c.options.RevocationTimeout)
+    defer timeout.Stop()
+
+    select {
+    case c.revocations <- req:
+    case <-c.ctx.Done():
+        return
+    case <-timeout.C:
+        logging.Error("Timed out waiting for in-flight batch before partition revocation",
+            errors.NewError("E4001", "revocation timeout exceeded", nil),
+            logging.Field("topics", c.topics),
+            logging.Field("partitions", len(partitions)),
+        )
+        return
+    }
+
+    select {
+    case <-req.done:
+    case <-c.ctx.Done():
+    case <-timeout.C:
+        logging.Error("Timed out finishing revoked partitions, uncommitted messages will be redelivered",
+            errors.NewError("E4001", "revocation timeout exceeded", nil),
+            logging.Field("topics", c.topics),
+            logging.Field("partitions", len(partitions)),
+        )
+    }
+}
+
+// drainRevoked processes every buffered message and the pending batch before
+// a revocation completes. It returns the messages still pending, which only
+// remain when processing missed the revocation deadline; those from revoked
+// partitions are dropped since their next owner will redeliver them.
+func (c *Consumer) drainRevoked(req revocation, batch []*kafka.Message) []*kafka.Message {
+    defer close(req.done)
+
+    // Polling is blocked in the rebalance callback, so the buffer cannot refill
+    for drained := false; !drained; {
+        select {
+        case msg := <-c.messages:
+            batch = append(batch, msg)
+        default:
+            drained = true
+        }
+    }
+
+    ctx, cancel := context.WithDeadline(c.ctx, req.deadline)
+    defer cancel()
+
+    for len(batch) > 0 {
+        size := c.options.BatchSize
+        if size > len(batch) {
+            size = len(batch)
+        }
+        if !c.processUntil(ctx, batch[:size]) {
+            break
+        }
+        batch = batch[size:]
+    }
+    if len(batch) == 0 {
+        return make([]*kafka.Message, 0, c.options.BatchSize)
+    }
+
+    revoked := make(map[string]bool, len(req.partitions))
+    for _, tp := range req.partitions {
+        revoked[partitionKey(tp)] = true
+    }
+    kept := make([]*kafka.Message, 0, c.options.BatchSize)
+    for _, msg := range batch {
+        if !revoked[partitionKey(msg.TopicPartition)] {
+            kept = append(kept, msg)
+        }
+    }
+    return kept
+}
@@ -0,0 +1,462 @@
+// Package streaming provides schema-registry backed Avro serialization for Kafka messages
+package streaming
+
+import (
+    "bytes"
+    "encoding/binary"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "sync"
+    "time"
+
+    "github.com/linkedin/goavro/v2" // v2.12.0
+    "../../pkg/common/errors"
+    "../../pkg/common/logging"
+)
+
+// Confluent wire format: a zero magic byte followed by a 4-byte big-endian schema ID
+const (
+    wireMagicByte    = 0
+    wireHeaderLength = 5
+)
+
+// Default timeout for schema registry requests
+const defaultRegistryTimeout = 10 * time.Second
+
+// Content type required by the schema registry API
+const registryContentType = "application/vnd.schemaregistry.v1+json"
+
+// Fields marked with this attribute hold nested or free-form JSON encoded as an Avro string
+const jsonFieldAttribute = "blackpoint.json"
+
+// BronzeEventSchema is the Avro schema of schema.BronzeEvent
+const BronzeEventSchema = `{
+    "type": "record",
+    "name": "BronzeEvent",
+    "namespace": "com.blackpoint.bronze",
+    "fields": [
+        {"name": "id", "type": "string"},
+        {"name": "client_id", "type": "string"},
+        {"name": "source_platform", "type": "string"},
+        {"name": "timestamp", "type": "string"},
+        {"name": "payload", "type": "string", "blackpoint.json": true},
+        {"name": "schema_version", "type": "string"},
+        {"name": "security_context", "type": "string", "default": ""},
+        {"name": "audit_metadata", "type": {"type": "map", "values": "string"}, "default": {}}
+    ]
+}`
+
+// SilverEventSchema is the Avro schema of schema.SilverEvent
+const SilverEventSchema = `{
+    "type": "record",
+    "name": "SilverEvent",
+    "namespace": "com.blackpoint.silver",
+    "fields": [
+        {"name": "event_id", "type": "string"},
+        {"name": "client_id", "type": "string"},
+        {"name": "event_type", "type": "string"},
+        {"name": "event_time", "type": "string"},
+        {"name": "normalized_data", "type": "string", "blackpoint.json": true},
+        {"name": "schema_version", "type": "string"},
+        {"name": "bronze_event_id", "type": "string"},
+        {"name": "security_context", "type": "string", "blackpoint.json": true},
+        {"name": "audit_metadata", "type": "string", "blackpoint.json": true},
+        {"name": "encrypted_fields", "type": "string", "blackpoint.json": true, "default": "null"},
+        {"name": "field_retention", "type": "string", "blackpoint.json": true, "default": "null"},
+        {"name": "purged_fields", "type": "string", "blackpoint.json": true, "default": "null"},
+        {"name": "transform_errors", "type": "string", "blackpoint.json": true, "default": "null"}
+    ]
+}`
+
+// Serializer encodes values produced to a topic and decodes consumed messages
+type Serializer interface {
+    Serialize(topic string, value interface{}) ([]byte, error)
+    Deserialize(topic string, data []byte, value interface{}) error
+}
+
+// SchemaRegistryConfig configures the schema registry serializer
+type SchemaRegistryConfig struct {
+    URL      string
+    Username string
+    Password string
+    Timeout  time.Duration
+
+    // Schemas maps each produced topic to its Avro schema. Subjects follow
+    // the topic name strategy: "<topic>-value".
+    Schemas map[string]string
+}
+
+// avroField is the part of an Avro field definition the serializer inspects
+type avroField struct {
+    Name    string          `json:"name"`
+    Default json.RawMessage `json:"default"`
+    JSON    bool            `json:"blackpoint.json"`
+}
+
+// registeredSchema is a parsed schema with its registry ID
+type registeredSchema struct {
+    id     int
+    codec  *goavro.Codec
+    fields []avroField
+}
+
+// SchemaRegistrySerializer encodes events as Avro in the Confluent wire
+// format. Schemas are checked for compatibility and registered at startup,
+// so a breaking schema change fails before anything is produced.
+type SchemaRegistrySerializer struct {
+    config  SchemaRegistryConfig
+    client  *http.Client
+    byTopic map[string]*registeredSchema
+    byID    map[int]*registeredSchema
+    mu      sync.RWMutex
+}
+
+// NewSchemaRegistrySerializer checks each configured schema against the
+// latest registered version of its subject and registers it
+func NewSchemaRegistrySerializer(config SchemaRegistryConfig) (*SchemaRegistrySerializer, error) {
+    if config.URL == "" {
+        return nil, errors.NewError("E2001", "schema registry URL is required", nil)
+    }
+    if config.Timeout <= 0 {
+        config.Timeout = defaultRegistryTimeout
+    }
+
+    s := &SchemaRegistrySerializer{
+        config:  config,
+        client:  &http.Client{Timeout: config.Timeout},
+        byTopic: make(map[string]*registeredSchema),
+        byID:    make(map[int]*registeredSchema),
+    }
+
+    for topic, schema := range config.Schemas {
+        subject := topic + "-value"
+
+        compatible, err := s.checkCompatibility(subject, schema)
+        if err != nil {
+            return nil, err
+        }
+        if !compatible {
+            return nil, errors.NewError("E2001", "schema is incompatible with the registered version", map[string]interface{}{
+                "subject": subject,
+            })
+        }
+
+        id, err := s.register(subject, schema)
+        if err != nil {
+            return nil, err
+        }
+
+        registered, err := newRegisteredSchema(id, schema)
+        if err != nil {
+            return nil, err
+        }
+        s.byTopic[topic] = registered
+        s.byID[id] = registered
+
+        logging.Info("Registered topic schema",
+            logging.Field("subject", subject),
+            logging.Field("schema_id", id),
+        )
+    }
+
+    return s, nil
+}
+
+// Serialize encodes a value with the schema registered for the topic
+func (s *SchemaRegistrySerializer) Serialize(topic string, value interface{}) ([]byte, error) {
+    s.mu.RLock()
+    registered, ok := s.byTopic[topic]
+    s.mu.RUnlock()
+    if !ok {
+        return nil, errors.NewError("E2001", "no schema registered for topic", map[string]interface{}{
+            "topic": topic,
+        })
+    }
+
+    record, err := registered.toNative(value)
+    if err != nil {
+        return nil, err
+    }
+
+    header := make([]byte, wireHeaderLength, wireHeaderLength+256)
+    header[0] = wireMagicByte
+    binary.BigEndian.PutUint32(header[1:], uint32(registered.id))
+
+    data, err := registered.codec.BinaryFromNative(header, record)
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to encode Avro record", map[string]interface{}{
+            "topic": topic,
+        })
+    }
+    return data, nil
+}
+
+// Deserialize decodes a message using the schema named by its embedded ID,
+// fetching schemas written by other producers from the registry
+func (s *SchemaRegistrySerializer) Deserialize(topic string, data []byte, value interface{}) error {
+    if len(data) < wireHeaderLength || data[0] != wireMagicByte {
+        return errors.NewError("E3001", "message is not in schema registry wire format", map[string]interface{}{
+            "topic": topic,
+        })
+    }
+
+    registered, err := s.schemaByID(int(binary.BigEndian.Uint32(data[1:wireHeaderLength])))
+    if err != nil {
+        return err
+    }
+
+    native, _, err := registered.codec.NativeFromBinary(data[wireHeaderLength:])
+    if err != nil {
+        return decodeError(err, "failed to decode Avro record", map[string]interface{}{
+            "topic":     topic,
+            "schema_id": registered.id,
+        })
+    }
+    return registered.fromNative(native, value)
+}
+
+// schemaByID returns a schema from the cache or the registry
+func (s *SchemaRegistrySerializer) schemaByID(id int) (*registeredSchema, error) {
+    s.mu.RLock()
+    registered, ok := s.byID[id]
+    s.mu.RUnlock()
+    if ok {
+        return registered, nil
+    }
+
+    var response struct {
+        Schema string `json:"schema"`
+    }
+    if err := s.do(http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &response); err != nil {
+        return nil, err
+    }
+
+    registered, err := newRegisteredSchema(id, response.Schema)
+    if err != nil {
+        return nil, err
+    }
+
+    s.mu.Lock()
+    s.byID[id] = registered
+    s.mu.Unlock()
+    return registered, nil
+}
+
+// checkCompatibility reports whether a schema may be registered under a
+// subject. A subject with no versions accepts any schema.
+func (s *SchemaRegistrySerializer) checkCompatibility(subject, schema string) (bool, error) {
+    var response struct {
+        IsCompatible bool `json:"is_compatible"`
+    }
+    path := fmt.Sprintf("/compatibility/subjects/%s/versions/latest", url.PathEscape(subject))
+    err := s.do(http.MethodPost, path, map[string]string{"schema": schema}, &response)
+    if registryNotFound(err) {
+        return true, nil
+    }
+    if err != nil {
+        return false, err
+    }
+    return response.IsCompatible, nil
+}
+
+// register registers a schema under a subject, returning its global ID.
+// Registering an existing schema returns the existing ID.
+func (s *SchemaRegistrySerializer) register(subject, schema string) (int, error) {
+    var response struct {
+        ID int `json:"id"`
+    }
+    path := fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject))
+    if err := s.do(http.MethodPost, path, map[string]string{"schema": schema}, &response); err != nil {
+        return 0, err
+    }
+    return response.ID, nil
+}
+
+// registryError is an error response from the schema registry
+type registryError struct {
+    status int
+    body   string
+}
+
+func (e *registryError) Error() string {
+    return fmt.Sprintf("schema registry returned %d: %s", e.status, e.body)
+}
+
+// HTTPStatusCode lets errors.Classify retry registry outages
+func (e *registryError) HTTPStatusCode() int {
+    return e.status
+}
+
+// registryNotFound reports whether err is a registry 404
+func registryNotFound(err error) bool {
+    regErr, ok := err.(*registryError)
+    return ok && regErr.status == http.StatusNotFound
+}
+
+// do sends a registry request and decodes the JSON response
+func (s *SchemaRegistrySerializer) do(method, path string, body interface{}, out interface{}) error {
+    var reader io.Reader
+    if body != nil {
+        encoded, err := json.Marshal(body)
+        if err != nil {
+            return errors.WrapError(err, "failed to encode schema registry request", nil)
+        }
+        reader = bytes.NewReader(encoded)
+    }
+
+    req, err := http.NewRequest(method, s.config.URL+path, reader)
+    if err != nil {
+        return errors.WrapError(err, "failed to create schema registry request", nil)
+    }
+    req.Header.Set("Content-Type", registryContentType)
+    req.Header.Set("Accept", registryContentType)
+    if s.config.Username != "" {
+        req.SetBasicAuth(s.config.Username, s.config.Password)
+    }
+
+    resp, err := s.client.Do(req)
+    if err != nil {
+        return errors.WrapError(err, "schema registry request failed", map[string]interface{}{
+            "path": path,
+        })
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode >= 300 {
+        message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+        return &registryError{status: resp.StatusCode, body: string(message)}
+    }
+    if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+        return errors.WrapError(err, "failed to decode schema registry response", map[string]interface{}{
+            "path": path,
+        })
+    }
+    return nil
+}
+
+// newRegisteredSchema parses an Avro schema and its field attributes
+func newRegisteredSchema(id int, schema string) (*registeredSchema, error) {
+    codec, err := goavro.NewCodec(schema)
+    if err != nil {
+        return nil, errors.WrapError(err, "invalid Avro schema", map[string]interface{}{
+            "schema_id": id,
+        })
+    }
+
+    var definition struct {
+        Fields []avroField `json:"fields"`
+    }
+    if err := json.Unmarshal([]byte(schema), &definition); err != nil {
+        return nil, errors.WrapError(err, "failed to parse Avro schema fields", map[string]interface{}{
+            "schema_id": id,
+        })
+    }
+
+    return &registeredSchema{id: id, codec: codec, fields: definition.Fields}, nil
+}
+
+// toNative converts a value to the native record goavro encodes. The value
+// goes through its JSON form so the schema follows the struct's JSON tags.
+func (r *registeredSchema) toNative(value interface{}) (map[string]interface{}, error) {
+    encoded, err := json.Marshal(value)
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to encode value", nil)
+    }
+    var fields map[string]json.RawMessage
+    if err := json.Unmarshal(encoded, &fields); err != nil {
+        return nil, errors.WrapError(err, "value is not a record", nil)
+    }
+
+    record := make(map[string]interface{}, len(r.fields))
+    for _, field := range r.fields {
+        raw, ok := fields[field.Name]
+        if !ok {
+            if field.Default == nil {
+                return nil, errors.NewError("E3001", "value is missing a required schema field", map[string]interface{}{
+                    "field": field.Name,
+                })
+            }
+            raw = field.Default
+            if field.JSON {
+                // Defaults of JSON fields are already the encoded string
+                var text string
+                if err := json.Unmarshal(raw, &text); err == nil {
+                    record[field.Name] = text
+                    continue
+                }
+            }
+        }
+
+        if field.JSON {
+            record[field.Name] = string(raw)
+            continue
+        }
+        var native interface{}
+        if err := json.Unmarshal(raw, &native); err != nil {
+            return nil, errors.WrapError(err, "failed to decode schema field", map[string]interface{}{
+                "field": field.Name,
+            })
+        }
+        record[field.Name] = native
+    }
+    return record, nil
+}
+
+// fromNative converts a decoded record back into a value through its JSON form
+func (r *registeredSchema) fromNative(native interface{}, value interface{}) error {
+    record, ok := native.(map[string]interface{})
+    if !ok {
+        return errors.NewError("E3001", "decoded Avro value is not a record", nil)
+    }
+
+    for _, field := range r.fields {
+        text, ok := record[field.Name].(string)
+        if field.JSON && ok {
+            record[field.Name] = json.RawMessage(text)
+        }
+    }
+
+    encoded, err := json.Marshal(record)
+    if err != nil {
+        return errors.WrapError(err, "failed to re-encode decoded record", nil)
+    }
+    if err := json.Unmarshal(encoded, value); err != nil {
+        return decodeError(err, "failed to decode record into value", nil)
+    }
+    return nil
+}
+
+// decodeError reports a message that no retry will decode. It carries a
+// validation code so errors.Classify finds it permanent, letting consumers
+// dead-letter the message rather than retry it.
+func decodeError(err error, message string, metadata map[string]interface{}) error {
+    decodeErr := errors.NewError("E3001", message, metadata)
+    decodeErr.Err = err
+    return decodeErr
+}
+
+// jsonSerializer is used when no Serializer is configured, matching the raw
+// JSON events produced before schema registry support
+type jsonSerializer struct{}
+
+func (jsonSerializer) Serialize(topic string, value interface{}) ([]byte, error) {
+    data, err := json.Marshal(value)
+    if err != nil {
+        return nil, errors.WrapError(err, "failed to encode JSON event", map[string]interface{}{
+            "topic": topic,
+        })
+    }
+    return data, nil
+}
+
+func (jsonSerializer) Deserialize(topic string, data []byte, value interface{}) error {
+    if err := json.Unmarshal(data, value); err != nil {
+        return decodeError(err, "failed to decode JSON event", map[string]interface{}{
+            "topic": topic,
+        })
+    }
+    return nil
+}
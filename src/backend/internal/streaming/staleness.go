@@ -0,0 +1,137 @@
+// Package streaming provides stale backlog skipping for Kafka consumers
+package streaming
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/confluentinc/confluent-kafka-go/kafka" // v1.9.2
+    "github.com/prometheus/client_golang/prometheus" // v1.16.0
+    "../../pkg/common/errors"
+    "../../pkg/common/logging"
+)
+
+// Timeout for offset lookups performed during partition assignment
+const staleSeekTimeout = 10 * time.Second
+
+var staleMessagesSkipped = prometheus.NewCounterVec(prometheus.CounterOpts{
+    Name: "blackpoint_consumer_stale_messages_skipped_total",
+    Help: "Total number of consumed messages skipped for exceeding the maximum message age",
+}, []string{"topic"})
+
+func init() {
+    prometheus.MustRegister(staleMessagesSkipped)
+}
+
+// startCutoff returns the oldest timestamp a newly assigned partition should start from
+func (c *Consumer) startCutoff() time.Time {
+    cutoff := c.options.StartFromTime
+    if c.options.MaxMessageAge > 0 {
+        if ageCutoff := time.Now().Add(-c.options.MaxMessageAge); ageCutoff.After(cutoff) {
+            cutoff = ageCutoff
+        }
+    }
+    return cutoff
+}
+
+// seekPastStale handles assignment for the rebalance callback, positioning
+// newly assigned partitions at the first message newer than the start cutoff. A partition
+// whose committed offset is already past that point is left where it is, so
+// the seek only ever skips forward.
+func (c *Consumer) seekPastStale(consumer *kafka.Consumer, ev kafka.Event) error {
+    switch e := ev.(type) {
+    case kafka.AssignedPartitions:
+        cutoff := c.startCutoff()
+        timeoutMs := int(staleSeekTimeout / time.Millisecond)
+
+        lookup := make([]kafka.TopicPartition, len(e.Partitions))
+        for i, tp := range e.Partitions {
+            lookup[i] = tp
+            lookup[i].Offset = kafka.Offset(cutoff.UnixNano() / int64(time.Millisecond))
+        }
+
+        byTime, err := consumer.OffsetsForTimes(lookup, timeoutMs)
+        if err != nil {
+            logging.Error("Failed to look up offsets for stale backlog skip, using committed offsets",
+                err,
+                logging.Field("topics", c.topics),
+            )
+            return consumer.Assign(e.Partitions)
+        }
+
+        committed, err := consumer.Committed(e.Partitions, timeoutMs)
+        if err != nil {
+            return errors.WrapError(err, "failed to fetch committed offsets", nil)
+        }
+        committedAt := make(map[string]kafka.Offset, len(committed))
+        for _, tp := range committed {
+            committedAt[partitionKey(tp)] = tp.Offset
+        }
+
+        assignment := make([]kafka.TopicPartition, len(byTime))
+        for i, tp := range byTime {
+            assignment[i] = tp
+            if current, ok := committedAt[partitionKey(tp)]; ok && current >= 0 && current > tp.Offset && tp.Offset >= 0 {
+                assignment[i].Offset = current
+                continue
+            }
+            if tp.Offset < 0 {
+                // No message newer than the cutoff: start at the end of the partition
+                assignment[i].Offset = kafka.OffsetEnd
+            }
+        }
+
+        logging.Info("Seeking assigned partitions past stale backlog",
+            logging.Field("topics", c.topics),
+            logging.Field("cutoff", cutoff.UTC()),
+            logging.Field("partitions", len(assignment)),
+        )
+        return consumer.Assign(assignment)
+    }
+    return nil
+}
+
+// isStale reports whether a message is older than the configured maximum age
+func (c *Consumer) isStale(msg *kafka.Message) bool {
+    if c.options.MaxMessageAge <= 0 || msg.Timestamp.IsZero() {
+        return false
+    }
+    return time.Since(msg.Timestamp) > c.options.MaxMessageAge
+}
+
+// dropStale returns the messages of batch within the maximum age and records
+// the rest as skipped. Skipped messages stay in the batch so their offsets
+// are committed with it and the backlog is not redelivered after a restart.
+func (c *Consumer) dropStale(batch []*kafka.Message) []*kafka.Message {
+    if c.options.MaxMessageAge <= 0 {
+        return batch
+    }
+
+    live := make([]*kafka.Message, 0, len(batch))
+    for _, msg := range batch {
+        if c.isStale(msg) {
+            c.skipStale(msg)
+            continue
+        }
+        live = append(live, msg)
+    }
+    return live
+}
+
+// skipStale records a skipped message
+func (c *Consumer) skipStale(msg *kafka.Message) {
+    topic := ""
+    if msg.TopicPartition.Topic != nil {
+        topic = *msg.TopicPartition.Topic
+    }
+    staleMessagesSkipped.WithLabelValues(topic).Inc()
+}
+
+// partitionKey identifies a topic partition in lookups
+func partitionKey(tp kafka.TopicPartition) string {
+    topic := ""
+    if tp.Topic != nil {
+        topic = *tp.Topic
+    }
+    return fmt.Sprintf("%s/%d", topic, tp.Partition)
+}
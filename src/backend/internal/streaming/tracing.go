@@ -0,0 +1,47 @@
+// Package streaming provides trace context propagation through Kafka headers
+package streaming
+
+import (
+    "context"
+
+    "go.opentelemetry.io/otel/propagation" // v1.0.0
+    "go.opentelemetry.io/otel/trace"       // v1.0.0
+)
+
+// tracePropagator carries W3C trace context in message headers. It is fixed
+// rather than taken from the global propagator so that services agree on the
+// format whatever their tracing setup.
+var tracePropagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+// InjectTraceContext returns headers with the trace context of the span in
+// ctx added, along with its trace ID under HeaderTraceID. The input map is
+// not modified; it is returned as is when ctx carries no valid span.
+func InjectTraceContext(ctx context.Context, headers map[string]string) map[string]string {
+    spanContext := trace.SpanContextFromContext(ctx)
+    if !spanContext.IsValid() {
+        return headers
+    }
+
+    carrier := make(propagation.MapCarrier, len(headers)+3)
+    for key, value := range headers {
+        carrier[key] = value
+    }
+    tracePropagator.Inject(ctx, carrier)
+    carrier[HeaderTraceID] = spanContext.TraceID().String()
+    return carrier
+}
+
+// ExtractTraceContext returns ctx with the remote span recorded in headers,
+// so spans started from it continue the producer's trace
+func ExtractTraceContext(ctx context.Context, headers map[string]string) context.Context {
+    if len(headers) == 0 {
+        return ctx
+    }
+    return tracePropagator.Extract(ctx, propagation.MapCarrier(headers))
+}
+
+// hasTraceContext reports whether headers already carry a trace context
+func hasTraceContext(headers map[string]string) bool {
+    _, ok := headers["traceparent"]
+    return ok
+}
@@ -0,0 +1,136 @@
+// Package streaming provides transactional exactly-once publishing for Kafka producers
+//
+// Transactions require ProducerOptions.TransactionalID and brokers configured
+// for them:
+//   - transaction.state.log.replication.factor and transaction.state.log.min.isr
+//     must be satisfiable by the cluster (defaults 3 and 2), or
+//     InitTransactions fails
+//   - transaction.max.timeout.ms must be at least the producer's
+//     transaction.timeout.ms (60s by default)
+//   - with ACLs enabled, the producer principal needs Write and Describe on
+//     its TransactionalId and the consumer group needs Read for offset commits
+//
+// Downstream consumers only see committed output when they read with
+// isolation.level=read_committed, which the transactional commit strategy sets.
+package streaming
+
+import (
+    "context"
+
+    "github.com/confluentinc/confluent-kafka-go/kafka" // v1.9.2
+    "../../pkg/common/errors"
+    "../../pkg/common/logging"
+)
+
+// BeginTransaction starts a transaction; messages produced until it is
+// committed or aborted become visible to read-committed consumers atomically
+func (p *Producer) BeginTransaction() error {
+    if !p.transactional {
+        return errors.NewError("E2001", "producer is not transactional", map[string]interface{}{
+            "topic": p.topic,
+        })
+    }
+    if err := p.producer.BeginTransaction(); err != nil {
+        return errors.WrapError(err, "failed to begin kafka transaction", transactionErrorDetails(p.topic, err))
+    }
+    return nil
+}
+
+// CommitTransaction commits the current transaction, retrying retriable
+// failures until ctx is done. A commit that requires an abort is aborted.
+func (p *Producer) CommitTransaction(ctx context.Context) error {
+    for {
+        err := p.producer.CommitTransaction(ctx)
+        if err == nil {
+            return nil
+        }
+
+        if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.IsRetriable() && ctx.Err() == nil {
+            continue
+        }
+        if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.TxnRequiresAbort() {
+            return p.abortAfter(ctx, err, "kafka transaction commit failed")
+        }
+        return errors.WrapError(err, "failed to commit kafka transaction", transactionErrorDetails(p.topic, err))
+    }
+}
+
+// AbortTransaction aborts the current transaction, discarding its messages
+// and offsets
+func (p *Producer) AbortTransaction(ctx context.Context) error {
+    if err := p.producer.AbortTransaction(ctx); err != nil {
+        return errors.WrapError(err, "failed to abort kafka transaction", transactionErrorDetails(p.topic, err))
+    }
+    return nil
+}
+
+// PublishTransactional publishes events and commits the offsets of the
+// consumed batch they were derived from in one transaction, so a
+// consume-transform-produce step takes effect exactly once: after a crash or
+// failure neither the events nor the offsets are committed and the batch is
+// redelivered. The consumer must use the transactional commit strategy.
+func (p *Producer) PublishTransactional(ctx context.Context, consumer *Consumer, batch []*Message, events [][]byte) error {
+    if consumer.options.CommitStrategy != CommitTransactional {
+        return errors.NewError("E2001", "consumer must use the transactional commit strategy", map[string]interface{}{
+            "commit_strategy": consumer.options.CommitStrategy,
+        })
+    }
+
+    if err := p.BeginTransaction(); err != nil {
+        return err
+    }
+
+    if err := p.PublishBatch(ctx, events); err != nil {
+        return p.abortAfter(ctx, err, "failed to publish transactional batch")
+    }
+
+    metadata, err := consumer.consumer.GetConsumerGroupMetadata()
+    if err != nil {
+        return p.abortAfter(ctx, err, "failed to read consumer group metadata")
+    }
+    if err := p.producer.SendOffsetsToTransaction(ctx, transactionOffsets(batch), metadata); err != nil {
+        return p.abortAfter(ctx, err, "failed to add consumer offsets to transaction")
+    }
+
+    return p.CommitTransaction(ctx)
+}
+
+// abortAfter aborts the current transaction following cause and returns
+// cause wrapped with msg
+func (p *Producer) abortAfter(ctx context.Context, cause error, msg string) error {
+    if err := p.AbortTransaction(ctx); err != nil {
+        logging.Error("Failed to abort kafka transaction",
+            err,
+            logging.Field("topic", p.topic),
+        )
+    }
+    return errors.WrapError(cause, msg, transactionErrorDetails(p.topic, cause))
+}
+
+// transactionOffsets returns the next offset of every partition in a batch
+func transactionOffsets(batch []*Message) []kafka.TopicPartition {
+    next := make(map[TopicPartition]kafka.Offset)
+    for _, msg := range batch {
+        tp := TopicPartition{Topic: msg.Topic, Partition: msg.Partition}
+        if offset := kafka.Offset(msg.Offset + 1); offset > next[tp] {
+            next[tp] = offset
+        }
+    }
+
+    offsets := make([]kafka.TopicPartition, 0, len(next))
+    for tp, offset := range next {
+        topic := tp.Topic
+        offsets = append(offsets, kafka.TopicPartition{Topic: &topic, Partition: tp.Partition, Offset: offset})
+    }
+    return offsets
+}
+
+// transactionErrorDetails describes a transaction error; a fatal error means
+// the producer must be closed and recreated
+func transactionErrorDetails(topic string, err error) map[string]interface{} {
+    details := map[string]interface{}{"topic": topic}
+    if kafkaErr, ok := err.(kafka.Error); ok {
+        details["fatal"] = kafkaErr.IsFatal()
+    }
+    return details
+}
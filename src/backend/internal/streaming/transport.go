@@ -0,0 +1,46 @@
+// Package streaming provides transport security verification for Kafka clients
+package streaming
+
+import (
+    "strings"
+
+    "github.com/confluentinc/confluent-kafka-go/kafka" // v1.9.2
+    "../../pkg/common/errors"
+    "../../pkg/common/logging"
+)
+
+// Security protocols that encrypt the broker connection
+var encryptedProtocols = map[string]bool{
+    "SSL":      true,
+    "SASL_SSL": true,
+}
+
+// verifyTransportSecurity refuses configurations whose security.protocol does
+// not encrypt traffic. Refusals are recorded as security audit events so a
+// downgraded deployment is visible even though the client never connects.
+func verifyTransportSecurity(config *kafka.ConfigMap, component string) error {
+    protocol := "PLAINTEXT"
+    if value, err := config.Get("security.protocol", "PLAINTEXT"); err == nil {
+        if s, ok := value.(string); ok && s != "" {
+            protocol = s
+        }
+    }
+
+    if encryptedProtocols[strings.ToUpper(protocol)] {
+        return nil
+    }
+
+    servers, _ := config.Get("bootstrap.servers", "")
+
+    logging.SecurityAudit("Refused unencrypted Kafka connection", map[string]interface{}{
+        "component":         component,
+        "security_protocol": protocol,
+        "bootstrap_servers": servers,
+        "require_tls":       true,
+    })
+
+    return errors.NewError("E2001", "TLS is required but the Kafka security protocol is not encrypted", map[string]interface{}{
+        "component":         component,
+        "security_protocol": protocol,
+    })
+}
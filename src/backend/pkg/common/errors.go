@@ -2,8 +2,11 @@
 package common
 
 import (
+	"context"
 	"fmt"           // v1.21
 	"errors"        // v1.21
+	"net/http"
+	"sort"
 	"sync/atomic"   // v1.21
 	"time"
 	"strings"
@@ -24,21 +27,53 @@ type ErrorCodeInfo struct {
 	Severity ErrorSeverity
 	Category string
 	Description string
+	// HTTPStatus is the status returned when the error reaches an API client
+	HTTPStatus int
+	// Retryable marks transient conditions where repeating the operation may succeed
+	Retryable bool
 }
 
 // Thread-safe error metrics tracking
 var errorMetrics = make(map[string]*atomic.Uint64)
 
-// Predefined error codes with severity and category
+// errorCodes is the catalog of every error code the services may return.
+// NewError only accepts codes registered here.
 var errorCodes = map[string]ErrorCodeInfo{
-	"E1001": {SeverityCritical, "Authentication", "Authentication failure"},
-	"E1002": {SeverityError, "Authorization", "Insufficient permissions"},
-	"E2001": {SeverityError, "Integration", "Integration configuration error"},
-	"E2002": {SeverityWarning, "Integration", "Integration performance degraded"},
-	"E3001": {SeverityError, "Data", "Data validation error"},
-	"E3002": {SeverityCritical, "Data", "Data corruption detected"},
-	"E4001": {SeverityError, "System", "Internal system error"},
-	"E4002": {SeverityWarning, "System", "Resource utilization warning"},
+	"E1001": {SeverityCritical, "Authentication", "Authentication failure", http.StatusUnauthorized, false},
+	"E1002": {SeverityError, "Authorization", "Insufficient permissions", http.StatusForbidden, false},
+	"E1003": {SeverityError, "Data", "Event validation failed", http.StatusBadRequest, false},
+	"E1004": {SeverityCritical, "Security", "Security pattern check failed", http.StatusBadRequest, false},
+	"E1005": {SeverityError, "Compliance", "Compliance check failed", http.StatusUnprocessableEntity, false},
+	"E1006": {SeverityCritical, "Security", "Tenant key mismatch", http.StatusForbidden, false},
+	"E2001": {SeverityError, "Integration", "Integration configuration error", http.StatusBadRequest, false},
+	"E2002": {SeverityWarning, "Integration", "Integration performance degraded", http.StatusServiceUnavailable, true},
+	"E3001": {SeverityError, "Data", "Data validation error", http.StatusBadRequest, false},
+	"E3002": {SeverityCritical, "Data", "Data corruption detected", http.StatusInternalServerError, false},
+	"E4001": {SeverityError, "System", "Internal system error", http.StatusInternalServerError, false},
+	"E4002": {SeverityWarning, "System", "Resource utilization warning", http.StatusServiceUnavailable, true},
+	"E4029": {SeverityWarning, "System", "Rate limit exceeded", http.StatusTooManyRequests, true},
+	"E5001": {SeverityInfo, "Cache", "Cache key not found", http.StatusNotFound, false},
+	"E5002": {SeverityInfo, "Cache", "Cache key expired", http.StatusNotFound, false},
+	"E5003": {SeverityWarning, "Cache", "Cache capacity exceeded", http.StatusServiceUnavailable, true},
+	"E5004": {SeverityError, "Cache", "Invalid cache key", http.StatusBadRequest, false},
+	"E5005": {SeverityError, "Cache", "Invalid cache value", http.StatusBadRequest, false},
+	"E5006": {SeverityWarning, "Cache", "Cache lock timeout", http.StatusServiceUnavailable, true},
+}
+
+// LookupErrorCode returns the catalog entry for a code
+func LookupErrorCode(code string) (ErrorCodeInfo, bool) {
+	info, ok := errorCodes[code]
+	return info, ok
+}
+
+// ErrorCodes returns every registered error code in sorted order
+func ErrorCodes() []string {
+	codes := make([]string, 0, len(errorCodes))
+	for code := range errorCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
 }
 
 // BlackPointError represents an enhanced error type with security and monitoring capabilities
@@ -81,10 +116,24 @@ func (e *BlackPointError) sanitizeMessage(message string) string {
 	return sanitized
 }
 
+// strictErrorCodes makes NewError panic on unregistered codes
+var strictErrorCodes atomic.Bool
+
+// SetStrictErrorCodes controls whether NewError panics on codes missing from
+// the catalog instead of falling back to E4001. Tests enable it to catch
+// unregistered codes; it returns the previous setting.
+func SetStrictErrorCodes(strict bool) bool {
+	return strictErrorCodes.Swap(strict)
+}
+
 // NewError creates a new BlackPointError with code, message, and severity validation
 func NewError(code string, message string, metadata map[string]interface{}) *BlackPointError {
 	codeInfo, exists := errorCodes[code]
 	if !exists {
+		// Unregistered codes are programming errors; fail loudly in strict mode
+		if strictErrorCodes.Load() {
+			panic(fmt.Sprintf("unregistered error code %q", code))
+		}
 		code = "E4001" // Default to internal system error
 		codeInfo = errorCodes[code]
 	}
@@ -125,11 +174,14 @@ func WrapError(err error, message string, context map[string]interface{}) error
 		}
 	}
 
-	// Wrap non-BlackPointError
-	return NewError("E4001", message, context)
+	// Wrap non-BlackPointError, keeping it as the cause for classification
+	wrapped := NewError("E4001", message, context)
+	wrapped.Err = err
+	return wrapped
 }
 
-// IsErrorCode checks if an error has a specific error code with category validation
+// IsErrorCode checks if an error has a specific error code with category
+// validation. Categories compare case-insensitively.
 func IsErrorCode(err error, code string, category string) bool {
 	if err == nil {
 		return false
@@ -145,7 +197,153 @@ func IsErrorCode(err error, code string, category string) bool {
 		return false
 	}
 
-	return bpErr.Code == code && (category == "" || codeInfo.Category == category)
+	return bpErr.Code == code && (category == "" || strings.EqualFold(codeInfo.Category, category))
+}
+
+// IsRetryable reports whether Classify finds err transient. Unknown errors
+// are not retryable.
+func IsRetryable(err error) bool {
+	return Classify(err) == Transient
+}
+
+// ErrorClass is the retry classification of an error
+type ErrorClass int
+
+const (
+	// Unknown errors carry no evidence either way; callers choose a default
+	Unknown ErrorClass = iota
+	// Transient errors may succeed if the operation is repeated
+	Transient
+	// Permanent errors fail the same way on every attempt
+	Permanent
+)
+
+// String returns the class name
+func (c ErrorClass) String() string {
+	switch c {
+	case Transient:
+		return "transient"
+	case Permanent:
+		return "permanent"
+	default:
+		return "unknown"
+	}
+}
+
+// AWS error codes with a known retry classification
+var (
+	transientAWSCodes = map[string]bool{
+		"SlowDown":                   true,
+		"Throttling":                 true,
+		"ThrottlingException":        true,
+		"RequestLimitExceeded":       true,
+		"RequestTimeout":             true,
+		"InternalError":              true,
+		"ServiceUnavailable":         true,
+		"KMSInternalException":       true,
+	}
+	permanentAWSCodes = map[string]bool{
+		"AccessDenied":              true,
+		"AccessDeniedException":     true,
+		"InvalidAccessKeyId":        true,
+		"SignatureDoesNotMatch":     true,
+		"ExpiredToken":              true,
+		"NoSuchBucket":              true,
+		"NoSuchKey":                 true,
+		"InvalidArgument":           true,
+		"InvalidRequest":            true,
+		"ValidationException":       true,
+		"DisabledException":         true,
+		"NotFoundException":         true,
+	}
+)
+
+// Error shapes of the AWS and Kafka clients, matched by method set so this
+// package does not depend on either SDK
+type (
+	// awsAPIError matches smithy.APIError and the typed AWS service errors
+	awsAPIError interface {
+		ErrorCode() string
+	}
+	// httpStatusError matches AWS transport response errors
+	httpStatusError interface {
+		HTTPStatusCode() int
+	}
+	// kafkaError matches kafka.Error
+	kafkaError interface {
+		IsFatal() bool
+		IsRetriable() bool
+		IsTimeout() bool
+	}
+	// timeoutError matches net.Error
+	timeoutError interface {
+		Timeout() bool
+	}
+)
+
+// Classify reports whether err is worth retrying. It inspects, in order, the
+// code of the outermost BlackPointError with a specific code, AWS error codes
+// and HTTP statuses, Kafka error flags and network timeouts anywhere in the
+// wrapped chain. The generic E4001 code defers to the error it wraps.
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return Unknown
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return Permanent
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Transient
+	}
+
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		bpErr, ok := current.(*BlackPointError)
+		if !ok || bpErr.Code == "E4001" {
+			continue
+		}
+		if errorCodes[bpErr.Code].Retryable {
+			return Transient
+		}
+		return Permanent
+	}
+
+	var kafkaErr kafkaError
+	if errors.As(err, &kafkaErr) {
+		switch {
+		case kafkaErr.IsFatal():
+			return Permanent
+		case kafkaErr.IsRetriable(), kafkaErr.IsTimeout():
+			return Transient
+		}
+	}
+
+	var apiErr awsAPIError
+	if errors.As(err, &apiErr) {
+		switch code := apiErr.ErrorCode(); {
+		case transientAWSCodes[code]:
+			return Transient
+		case permanentAWSCodes[code]:
+			return Permanent
+		}
+	}
+
+	var statusErr httpStatusError
+	if errors.As(err, &statusErr) {
+		switch status := statusErr.HTTPStatusCode(); {
+		case status >= http.StatusInternalServerError, status == http.StatusTooManyRequests, status == http.StatusRequestTimeout:
+			return Transient
+		case status >= http.StatusBadRequest:
+			return Permanent
+		}
+	}
+
+	var timeoutErr timeoutError
+	if errors.As(err, &timeoutErr) && timeoutErr.Timeout() {
+		return Transient
+	}
+
+	return Unknown
 }
 
 // ErrorMetrics represents error statistics and trends
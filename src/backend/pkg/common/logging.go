@@ -2,24 +2,39 @@
 package common
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
 
 	"github.com/blackpoint/pkg/common" // Internal errors package
 	"go.uber.org/zap"                  // v1.24.0
 	"go.uber.org/zap/zapcore"          // v1.24.0
+	"golang.org/x/time/rate"
 	"gopkg.in/natefinch/lumberjack.v2" // v2.0.0
 )
 
+// Bound on distinct messages tracked by the sampler; messages beyond it share
+// one sampling budget
+const maxSampledMessages = 4096
+
+// Replacement for the values of sensitive keys
+const redactedValue = "***"
+
 // Global variables for logger management
 var (
 	logger              *zap.Logger
 	logConfig           LogConfig
 	securityAuditEnabled bool
 	sensitiveDataPatterns []string
+	sensitiveKeys      map[string]bool
+	sampler            *logSampler
 	loggerMutex        sync.RWMutex
 )
 
@@ -43,7 +58,20 @@ type LogConfig struct {
 	EnableSecurityAudit bool
 	EnableMonitoring    bool
 	SensitiveDataPatterns []string
+	SensitiveKeys       []string
 	MonitoringSettings   MonitoringConfig
+	Sampling            SamplingConfig
+}
+
+// SamplingConfig limits the volume of info logs per message. Error and
+// security audit logs are never sampled.
+type SamplingConfig struct {
+	// Every writes one in Every info logs of each message when greater than 1
+	Every uint64
+	// PerSecond and Burst pass info logs of each message through a token
+	// bucket; they take precedence over Every when PerSecond is positive
+	PerSecond float64
+	Burst     int
 }
 
 // NewLogConfig creates a new LogConfig with security-aware defaults
@@ -64,6 +92,17 @@ func NewLogConfig() LogConfig {
 			`token=\S+`,
 			`secret=\S+`,
 		},
+		SensitiveKeys: []string{
+			"password",
+			"secret",
+			"client_secret",
+			"token",
+			"access_token",
+			"refresh_token",
+			"api_key",
+			"private_key",
+			"authorization",
+		},
 		MonitoringSettings: MonitoringConfig{
 			MetricsEnabled: true,
 			MetricsPrefix:  "blackpoint_logging",
@@ -96,6 +135,10 @@ func (c *LogConfig) Validate() error {
 		return common.NewError("E4001", "invalid MaxAge value", nil)
 	}
 
+	if c.Sampling.PerSecond < 0 || c.Sampling.Burst < 0 {
+		return common.NewError("E4001", "invalid log sampling rate", nil)
+	}
+
 	// Compile sensitive data patterns
 	for _, pattern := range c.SensitiveDataPatterns {
 		if _, err := regexp.Compile(pattern); err != nil {
@@ -176,6 +219,11 @@ func InitLogger(config LogConfig) error {
 	logConfig = config
 	securityAuditEnabled = config.EnableSecurityAudit
 	sensitiveDataPatterns = config.SensitiveDataPatterns
+	sensitiveKeys = make(map[string]bool, len(config.SensitiveKeys))
+	for _, key := range config.SensitiveKeys {
+		sensitiveKeys[normalizeKey(key)] = true
+	}
+	sampler = newLogSampler(config.Sampling)
 
 	return nil
 }
@@ -185,7 +233,7 @@ func Info(message string, fields ...zap.Field) {
 	loggerMutex.RLock()
 	defer loggerMutex.RUnlock()
 
-	if logger == nil {
+	if logger == nil || !sampler.allow(message) {
 		return
 	}
 
@@ -233,6 +281,89 @@ func Error(message string, err error, fields ...zap.Field) {
 	logger.Error(message, fields...)
 }
 
+// SecurityAudit logs a security-relevant event with its details. Audit logs
+// bypass sampling so the audit trail is always complete.
+func SecurityAudit(message string, details map[string]interface{}) {
+	loggerMutex.RLock()
+	defer loggerMutex.RUnlock()
+
+	if logger == nil || !securityAuditEnabled {
+		return
+	}
+
+	fields := make([]zap.Field, 0, len(details)+2)
+	for key, value := range details {
+		fields = append(fields, zap.Any(key, value))
+	}
+	fields = append(fields,
+		zap.Time("log_time", time.Now().UTC()),
+		zap.String("security_level", "audit"),
+	)
+
+	logger.Info(sanitizeMessage(message), sanitizeFields(fields)...)
+}
+
+// logSampler decides which info logs are written. State is kept per message
+// so a chatty per-event log cannot starve rare ones.
+type logSampler struct {
+	config   SamplingConfig
+	messages sync.Map // message -> *sampleState
+	tracked  atomic.Int64
+}
+
+// sampleState is the sampling state of one message
+type sampleState struct {
+	seen    atomic.Uint64
+	limiter *rate.Limiter
+}
+
+// newLogSampler returns a sampler for config, or nil when sampling is off
+func newLogSampler(config SamplingConfig) *logSampler {
+	if config.PerSecond <= 0 && config.Every <= 1 {
+		return nil
+	}
+	if config.PerSecond > 0 && config.Burst <= 0 {
+		config.Burst = int(config.PerSecond)
+		if config.Burst < 1 {
+			config.Burst = 1
+		}
+	}
+	return &logSampler{config: config}
+}
+
+// allow reports whether an info log of message should be written. A nil
+// sampler allows everything.
+func (s *logSampler) allow(message string) bool {
+	if s == nil {
+		return true
+	}
+	state := s.state(message)
+	if state.limiter != nil {
+		return state.limiter.Allow()
+	}
+	return (state.seen.Add(1)-1)%s.config.Every == 0
+}
+
+// state returns the sampling state of message, creating it on first use
+func (s *logSampler) state(message string) *sampleState {
+	if state, ok := s.messages.Load(message); ok {
+		return state.(*sampleState)
+	}
+	if s.tracked.Load() >= maxSampledMessages {
+		message = ""
+	}
+
+	state := &sampleState{}
+	if s.config.PerSecond > 0 {
+		state.limiter = rate.NewLimiter(rate.Limit(s.config.PerSecond), s.config.Burst)
+	}
+	actual, loaded := s.messages.LoadOrStore(message, state)
+	if !loaded {
+		s.tracked.Add(1)
+	}
+	return actual.(*sampleState)
+}
+
 // sanitizeMessage removes sensitive data from log messages
 func sanitizeMessage(message string) string {
 	for _, pattern := range sensitiveDataPatterns {
@@ -245,14 +376,117 @@ func sanitizeMessage(message string) string {
 	return message
 }
 
-// sanitizeFields removes sensitive data from log fields
+// sanitizeFields removes sensitive data from log fields. Values of sensitive
+// keys are redacted, including keys nested in maps and structs.
 func sanitizeFields(fields []zap.Field) []zap.Field {
 	sanitized := make([]zap.Field, len(fields))
 	for i, field := range fields {
-		if field.Type == zapcore.StringType {
+		switch {
+		case isSensitiveKey(field.Key):
+			field = zap.String(field.Key, redactedValue)
+		case field.Type == zapcore.StringType:
 			field.String = sanitizeMessage(field.String)
+		case field.Type == zapcore.ReflectType:
+			field = zap.Any(field.Key, redactValue(field.Interface))
 		}
 		sanitized[i] = field
 	}
 	return sanitized
+}
+
+// Field creates a log field; sensitive values are redacted when it is logged
+func Field(key string, value interface{}) zap.Field {
+	return zap.Any(key, value)
+}
+
+// normalizeKey folds a key to the snake_case form used in the sensitive key
+// set, so clientSecret, ClientSecret, client-secret and APIKey match
+// client_secret and api_key
+func normalizeKey(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	b.Grow(len(key) + 4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// A word starts at a lower-to-upper change or at the last capital of an acronym
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		if r == '-' {
+			r = '_'
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// isSensitiveKey reports whether values of key must be redacted. A key
+// matches a sensitive key exactly or by suffix, so okta_client_secret matches
+// client_secret.
+func isSensitiveKey(key string) bool {
+	if len(sensitiveKeys) == 0 {
+		return false
+	}
+	key = normalizeKey(key)
+	if sensitiveKeys[key] {
+		return true
+	}
+	for sensitive := range sensitiveKeys {
+		if strings.HasSuffix(key, "_"+sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue returns value with the values of sensitive keys replaced.
+// Structs and other composite values are redacted through their JSON form,
+// which is how they are serialized in the log anyway.
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if isSensitiveKey(key) {
+				redacted[key] = redactedValue
+				continue
+			}
+			redacted[key] = redactValue(item)
+		}
+		return redacted
+	case map[string]string:
+		redacted := make(map[string]string, len(v))
+		for key, item := range v {
+			if isSensitiveKey(key) {
+				item = redactedValue
+			}
+			redacted[key] = item
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redactValue(item)
+		}
+		return redacted
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(value)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return value
+		}
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return value
+		}
+		return redactValue(generic)
+	}
+	return value
 }
\ No newline at end of file
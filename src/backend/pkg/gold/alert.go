@@ -3,6 +3,8 @@ package gold
 
 import (
     "encoding/json"
+    "fmt"
+    "io"
     "time"
     "sync"
     "golang.org/x/time/rate" // v0.1.0
@@ -46,6 +48,17 @@ type StatusHistory struct {
     Metadata    map[string]interface{} `json:"metadata"`
 }
 
+// AuditEntry records an action taken on an alert. Entries appended by the
+// Gold tier are hash-chained; see VerifyAuditChain.
+type AuditEntry struct {
+    Action    string                 `json:"action"`
+    Timestamp time.Time             `json:"timestamp"`
+    Details   map[string]interface{} `json:"details"`
+    // PrevHash is the Hash of the preceding entry, empty for the first
+    PrevHash  string                 `json:"prev_hash,omitempty"`
+    Hash      string                 `json:"hash,omitempty"`
+}
+
 // Alert represents a security alert with enhanced security features
 type Alert struct {
     AlertID          string                 `json:"alert_id"`
@@ -58,11 +71,32 @@ type Alert struct {
     SecurityMetadata *SecurityMetadata      `json:"security_metadata"`
     ComplianceTags   map[string]string      `json:"compliance_tags"`
     EncryptedFields  []string              `json:"encrypted_fields"`
+    Count            int                    `json:"count"`
+    LastSeen         time.Time             `json:"last_seen"`
+    AuditTrail       []AuditEntry          `json:"audit_trail"`
+    // TraceID links the alert to the pipeline trace of the event that raised it
+    TraceID          string                 `json:"trace_id,omitempty"`
     mutex            sync.RWMutex          // Protects concurrent access
 }
 
+// AlertOptions supplies the clock and randomness CreateAlertWithOptions
+// draws on, so alerts can be created reproducibly. Nil fields use the wall
+// clock and a secure random alert ID.
+type AlertOptions struct {
+    // Now returns the alert's creation time
+    Now func() time.Time
+    // Rand supplies the bytes of the alert ID
+    Rand io.Reader
+}
+
 // CreateAlert creates a new security alert from a Gold event with enhanced security controls
 func CreateAlert(event *GoldEvent, ctx *SecurityMetadata) (*Alert, error) {
+    return CreateAlertWithOptions(event, ctx, AlertOptions{})
+}
+
+// CreateAlertWithOptions is CreateAlert with an injected clock and source of
+// randomness for the alert ID, timestamps and audit trail
+func CreateAlertWithOptions(event *GoldEvent, ctx *SecurityMetadata, opts AlertOptions) (*Alert, error) {
     if event == nil || ctx == nil {
         return nil, errors.NewError("E3001", "invalid input parameters", nil)
     }
@@ -73,7 +107,7 @@ func CreateAlert(event *GoldEvent, ctx *SecurityMetadata) (*Alert, error) {
     }
 
     // Generate secure alert ID
-    alertID, err := utils.GenerateUUID()
+    alertID, err := newAlertID(opts.Rand)
     if err != nil {
         return nil, errors.WrapError(err, "failed to generate alert ID", nil)
     }
@@ -95,16 +129,20 @@ func CreateAlert(event *GoldEvent, ctx *SecurityMetadata) (*Alert, error) {
     }
 
     // Create new alert
+    now := time.Now().UTC()
+    if opts.Now != nil {
+        now = opts.Now().UTC()
+    }
     alert := &Alert{
         AlertID:          alertID,
         Status:           "new",
-        CreatedAt:        time.Now().UTC(),
-        UpdatedAt:        time.Now().UTC(),
-        Severity:         event.Severity,
+        CreatedAt:        now,
+        UpdatedAt:        now,
+        Severity:         mapSeverity(event),
         IntelligenceData: intelligenceData,
         History: []StatusHistory{{
             Status:    "new",
-            Timestamp: time.Now().UTC(),
+            Timestamp: now,
             UpdatedBy: ctx.Classification,
             Reason:    "Alert created",
             Metadata:  map[string]interface{}{"source": "automatic"},
@@ -112,6 +150,19 @@ func CreateAlert(event *GoldEvent, ctx *SecurityMetadata) (*Alert, error) {
         SecurityMetadata: ctx,
         ComplianceTags:   generateComplianceTags(event),
         EncryptedFields:  encryptedFields,
+        Count:            1,
+        LastSeen:         now,
+    }
+
+    if err := alert.appendAudit(AuditEntry{
+        Action:    auditActionCreated,
+        Timestamp: now,
+        Details: map[string]interface{}{
+            "severity":       event.Severity,
+            "classification": ctx.Classification,
+        },
+    }); err != nil {
+        return nil, errors.WrapError(err, "failed to record alert creation", nil)
     }
 
     // Validate created alert
@@ -235,6 +286,21 @@ func isSensitiveField(field string) bool {
 }
 
 // generateComplianceTags generates compliance tags based on event data
+// newAlertID returns a random (version 4) UUID read from r, or a secure
+// random UUID when r is nil
+func newAlertID(r io.Reader) (string, error) {
+    if r == nil {
+        return utils.GenerateUUID()
+    }
+    var b [16]byte
+    if _, err := io.ReadFull(r, b[:]); err != nil {
+        return "", err
+    }
+    b[6] = (b[6] & 0x0f) | 0x40
+    b[8] = (b[8] & 0x3f) | 0x80
+    return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
 func generateComplianceTags(event *GoldEvent) map[string]string {
     tags := make(map[string]string)
     if event.ComplianceInfo.Standards != nil {
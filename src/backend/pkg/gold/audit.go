@@ -0,0 +1,113 @@
+// Package gold implements tamper-evident audit trails for Gold tier alerts
+package gold
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "time"
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// Audit trail action recorded when an alert is created
+const auditActionCreated = "created"
+
+// chainedAuditEntry is the hashed content of an audit entry. Timestamps are
+// hashed in UTC so the chain survives a JSON round trip.
+type chainedAuditEntry struct {
+    PrevHash  string                 `json:"prev_hash"`
+    Action    string                 `json:"action"`
+    Timestamp string                 `json:"timestamp"`
+    Details   map[string]interface{} `json:"details"`
+}
+
+// appendAudit chains entry onto the alert's audit trail. Callers hold the
+// alert mutex or own the alert exclusively.
+func (a *Alert) appendAudit(entry AuditEntry) error {
+    if n := len(a.AuditTrail); n > 0 {
+        entry.PrevHash = a.AuditTrail[n-1].Hash
+    } else {
+        entry.PrevHash = ""
+    }
+
+    hash, err := hashAuditEntry(entry)
+    if err != nil {
+        return err
+    }
+    entry.Hash = hash
+    a.AuditTrail = append(a.AuditTrail, entry)
+    return nil
+}
+
+// VerifyAuditChain checks that every entry in a hash-chained audit trail is
+// unmodified and links to the entry before it, which detects entries that
+// were changed, inserted, removed or reordered. Removing the newest entries
+// leaves a valid shorter chain, so callers that must detect truncation
+// compare the last hash against one recorded elsewhere. A trail with no
+// hashes predates chaining and is accepted as is.
+func VerifyAuditChain(trail []AuditEntry) error {
+    if !isChained(trail) {
+        return nil
+    }
+
+    prevHash := ""
+    var prevTimestamp time.Time
+    for i, entry := range trail {
+        if entry.PrevHash != prevHash {
+            return auditChainError("audit entry does not link to the previous entry", i, entry)
+        }
+        if i > 0 && entry.Timestamp.Before(prevTimestamp) {
+            return auditChainError("audit entry is out of order", i, entry)
+        }
+
+        hash, err := hashAuditEntry(entry)
+        if err != nil {
+            return errors.WrapError(err, "failed to hash audit entry", map[string]interface{}{
+                "index": i,
+            })
+        }
+        if entry.Hash != hash {
+            return auditChainError("audit entry hash mismatch", i, entry)
+        }
+
+        prevHash = entry.Hash
+        prevTimestamp = entry.Timestamp
+    }
+    return nil
+}
+
+// isChained reports whether any entry carries a hash. Once one does, every
+// entry must, so stripping hashes from part of a trail is still detected.
+func isChained(trail []AuditEntry) bool {
+    for _, entry := range trail {
+        if entry.Hash != "" || entry.PrevHash != "" {
+            return true
+        }
+    }
+    return false
+}
+
+// hashAuditEntry returns the hex SHA-256 of an entry and its PrevHash. Map
+// keys are marshaled in sorted order, so the encoding is deterministic.
+func hashAuditEntry(entry AuditEntry) (string, error) {
+    data, err := json.Marshal(chainedAuditEntry{
+        PrevHash:  entry.PrevHash,
+        Action:    entry.Action,
+        Timestamp: entry.Timestamp.UTC().Format(time.RFC3339Nano),
+        Details:   entry.Details,
+    })
+    if err != nil {
+        return "", err
+    }
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:]), nil
+}
+
+// auditChainError reports a broken audit chain at index
+func auditChainError(message string, index int, entry AuditEntry) error {
+    return errors.NewError("E3002", message, map[string]interface{}{
+        "index":  index,
+        "action": entry.Action,
+    })
+}
@@ -0,0 +1,197 @@
+// Package gold implements deduplication of repeated alerts for the Gold tier
+package gold
+
+import (
+    "fmt"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+const (
+    // Default window during which repeated alerts are folded into the first one
+    defaultDedupWindow = 15 * time.Minute
+
+    // Audit trail action recorded for each suppressed duplicate
+    auditActionSuppressed = "suppressed"
+)
+
+// Default alert signature: rule ID, affected entity and severity
+var defaultSignatureFields = []string{"rule_id", "entity", "severity"}
+
+// DedupConfig configures alert deduplication
+type DedupConfig struct {
+    // Window is how long the first alert for a signature absorbs repeats; once it
+    // elapses the next repeat is emitted as a new alert
+    Window time.Duration
+
+    // SignatureFields are the alert fields that identify repeats. "severity"
+    // reads the alert severity; other names are intelligence data keys.
+    SignatureFields []string
+}
+
+// dedupEntry tracks the alert emitted for a signature. The entry holds its own
+// copy so folding repeats never mutates an alert the caller already published.
+type dedupEntry struct {
+    alert     *Alert
+    firstSeen time.Time
+}
+
+// AlertDeduplicator folds repeated alerts for the same ongoing condition into
+// the alert first emitted for it
+type AlertDeduplicator struct {
+    config  DedupConfig
+    entries map[string]*dedupEntry
+    mutex   sync.Mutex
+}
+
+// NewAlertDeduplicator creates a deduplicator with defaults applied
+func NewAlertDeduplicator(config DedupConfig) (*AlertDeduplicator, error) {
+    if config.Window < 0 {
+        return nil, errors.NewError("E3001", "dedup window must not be negative", map[string]interface{}{
+            "window": config.Window,
+        })
+    }
+    if config.Window == 0 {
+        config.Window = defaultDedupWindow
+    }
+    if len(config.SignatureFields) == 0 {
+        config.SignatureFields = defaultSignatureFields
+    }
+
+    return &AlertDeduplicator{
+        config:  config,
+        entries: make(map[string]*dedupEntry),
+    }, nil
+}
+
+// Deduplicate returns the alert to publish and whether it is new. A new alert
+// is returned as given. A repeat within the window increments Count and
+// LastSeen on the tracked copy of the first alert, records the suppression in
+// its AuditTrail and returns a snapshot of it; the alert returned for the
+// first occurrence is left untouched.
+func (d *AlertDeduplicator) Deduplicate(alert *Alert, now time.Time) (*Alert, bool) {
+    signature := d.Signature(alert)
+
+    d.mutex.Lock()
+    defer d.mutex.Unlock()
+
+    d.evictExpired(now)
+
+    if entry, exists := d.entries[signature]; exists {
+        tracked := entry.alert
+        tracked.Count++
+        tracked.LastSeen = now
+        tracked.UpdatedAt = now
+        // Details are plain strings and ints, so hashing cannot fail
+        _ = tracked.appendAudit(AuditEntry{
+            Action:    auditActionSuppressed,
+            Timestamp: now,
+            Details: map[string]interface{}{
+                "duplicate_alert_id": alert.AlertID,
+                "signature":          signature,
+                "count":              tracked.Count,
+            },
+        })
+        return cloneAlert(tracked), false
+    }
+
+    alert.mutex.Lock()
+    if alert.Count == 0 {
+        alert.Count = 1
+    }
+    alert.LastSeen = now
+    alert.mutex.Unlock()
+
+    d.entries[signature] = &dedupEntry{alert: cloneAlert(alert), firstSeen: now}
+    return alert, true
+}
+
+// Forget stops tracking alert's signature if alert is the one emitted for it,
+// so the next occurrence is treated as new. Callers use it when publishing the
+// new alert failed and the condition will be detected again on retry.
+func (d *AlertDeduplicator) Forget(alert *Alert) {
+    signature := d.Signature(alert)
+
+    d.mutex.Lock()
+    defer d.mutex.Unlock()
+    if entry, exists := d.entries[signature]; exists && entry.alert.AlertID == alert.AlertID {
+        delete(d.entries, signature)
+    }
+}
+
+// Signature returns the deduplication signature of an alert
+func (d *AlertDeduplicator) Signature(alert *Alert) string {
+    alert.mutex.RLock()
+    defer alert.mutex.RUnlock()
+
+    parts := make([]string, len(d.config.SignatureFields))
+    for i, field := range d.config.SignatureFields {
+        var value interface{}
+        if field == "severity" {
+            value = alert.Severity
+        } else if alert.IntelligenceData != nil {
+            value = alert.IntelligenceData[field]
+        }
+        if value != nil {
+            parts[i] = fmt.Sprint(value)
+        }
+    }
+    return strings.Join(parts, "|")
+}
+
+// ActiveCount returns the number of signatures currently suppressing repeats
+func (d *AlertDeduplicator) ActiveCount() int {
+    d.mutex.Lock()
+    defer d.mutex.Unlock()
+    return len(d.entries)
+}
+
+// evictExpired drops signatures whose window has elapsed
+func (d *AlertDeduplicator) evictExpired(now time.Time) {
+    for signature, entry := range d.entries {
+        if now.Sub(entry.firstSeen) >= d.config.Window {
+            delete(d.entries, signature)
+        }
+    }
+}
+
+// cloneAlert copies an alert deeply enough that appending to or updating the
+// copy leaves the original unchanged
+func cloneAlert(alert *Alert) *Alert {
+    alert.mutex.RLock()
+    defer alert.mutex.RUnlock()
+
+    clone := &Alert{
+        AlertID:          alert.AlertID,
+        Status:           alert.Status,
+        CreatedAt:        alert.CreatedAt,
+        UpdatedAt:        alert.UpdatedAt,
+        Severity:         alert.Severity,
+        History:          append([]StatusHistory(nil), alert.History...),
+        EncryptedFields:  append([]string(nil), alert.EncryptedFields...),
+        Count:            alert.Count,
+        LastSeen:         alert.LastSeen,
+        AuditTrail:       append([]AuditEntry(nil), alert.AuditTrail...),
+        TraceID:          alert.TraceID,
+    }
+    if alert.IntelligenceData != nil {
+        clone.IntelligenceData = make(map[string]interface{}, len(alert.IntelligenceData))
+        for k, v := range alert.IntelligenceData {
+            clone.IntelligenceData[k] = v
+        }
+    }
+    if alert.ComplianceTags != nil {
+        clone.ComplianceTags = make(map[string]string, len(alert.ComplianceTags))
+        for k, v := range alert.ComplianceTags {
+            clone.ComplianceTags[k] = v
+        }
+    }
+    if alert.SecurityMetadata != nil {
+        metadata := *alert.SecurityMetadata
+        clone.SecurityMetadata = &metadata
+    }
+    return clone
+}
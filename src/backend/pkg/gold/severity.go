@@ -0,0 +1,174 @@
+// Package gold implements configurable severity mapping for Gold tier alerts
+package gold
+
+import (
+    "sort"
+    "sync"
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// DefaultSeverityBands match the analyzer's built-in threat levels
+var DefaultSeverityBands = []SeverityBand{
+    {MinScore: 0.8, Severity: "critical"},
+    {MinScore: 0.6, Severity: "high"},
+    {MinScore: 0.4, Severity: "medium"},
+    {MinScore: 0.2, Severity: "low"},
+}
+
+// Installed severity mapper; nil keeps each event's own severity
+var (
+    severityMapper     *SeverityMapper
+    severityMapperLock sync.RWMutex
+)
+
+// SeverityBand maps detection scores at or above MinScore to Severity
+type SeverityBand struct {
+    MinScore float64 `yaml:"min_score" json:"min_score"`
+    Severity string  `yaml:"severity" json:"severity"`
+}
+
+// SeverityMappingConfig configures how alert severity is derived
+type SeverityMappingConfig struct {
+    // Bands map detection scores to severities; DefaultSeverityBands apply
+    // when empty. Scores below every band keep the event's own severity.
+    Bands []SeverityBand `yaml:"bands" json:"bands"`
+
+    // RuleOverrides fix the severity of alerts raised by a rule regardless of
+    // score, keyed by rule ID
+    RuleOverrides map[string]string `yaml:"rule_overrides" json:"rule_overrides"`
+}
+
+// SeverityMapper derives alert severity from detection scores and per-rule
+// overrides so each deployment can tune its severity bands
+type SeverityMapper struct {
+    bands     []SeverityBand
+    overrides map[string]string
+}
+
+// NewSeverityMapper validates config and creates a mapper
+func NewSeverityMapper(config SeverityMappingConfig) (*SeverityMapper, error) {
+    bands := config.Bands
+    if len(bands) == 0 {
+        bands = DefaultSeverityBands
+    }
+
+    sorted := make([]SeverityBand, len(bands))
+    copy(sorted, bands)
+    sort.SliceStable(sorted, func(i, j int) bool {
+        return sorted[i].MinScore > sorted[j].MinScore
+    })
+
+    for i, band := range sorted {
+        if band.MinScore < 0 || band.MinScore > 1 {
+            return nil, errors.NewError("E2001", "severity band score must be between 0 and 1", map[string]interface{}{
+                "min_score": band.MinScore,
+            })
+        }
+        if !isSeverityLevel(band.Severity) {
+            return nil, errors.NewError("E2001", "invalid severity band level", map[string]interface{}{
+                "severity": band.Severity,
+            })
+        }
+        if i > 0 && sorted[i-1].MinScore == band.MinScore {
+            return nil, errors.NewError("E2001", "duplicate severity band score", map[string]interface{}{
+                "min_score": band.MinScore,
+            })
+        }
+    }
+
+    overrides := make(map[string]string, len(config.RuleOverrides))
+    for ruleID, severity := range config.RuleOverrides {
+        if !isSeverityLevel(severity) {
+            return nil, errors.NewError("E2001", "invalid rule severity override", map[string]interface{}{
+                "rule_id":  ruleID,
+                "severity": severity,
+            })
+        }
+        overrides[ruleID] = severity
+    }
+
+    return &SeverityMapper{bands: sorted, overrides: overrides}, nil
+}
+
+// SetSeverityMapper installs the mapper applied by CreateAlert. Passing nil
+// restores each event's own severity.
+func SetSeverityMapper(mapper *SeverityMapper) {
+    severityMapperLock.Lock()
+    defer severityMapperLock.Unlock()
+    severityMapper = mapper
+}
+
+// Map returns the severity for an alert raised by ruleIDs with the given
+// detection score. A rule override wins over the score bands, the most
+// severe one when several rules are overridden; fallback is returned when
+// no override applies and the score is below every band.
+func (m *SeverityMapper) Map(ruleIDs []string, score float64, fallback string) string {
+    override := ""
+    for _, ruleID := range ruleIDs {
+        if severity, ok := m.overrides[ruleID]; ok {
+            if override == "" || severityRank(severity) < severityRank(override) {
+                override = severity
+            }
+        }
+    }
+    if override != "" {
+        return override
+    }
+
+    for _, band := range m.bands {
+        if score >= band.MinScore {
+            return band.Severity
+        }
+    }
+    return fallback
+}
+
+// mapSeverity applies the installed mapper to an event
+func mapSeverity(event *GoldEvent) string {
+    severityMapperLock.RLock()
+    mapper := severityMapper
+    severityMapperLock.RUnlock()
+
+    if mapper == nil {
+        return event.Severity
+    }
+    return mapper.Map(eventRuleIDs(event), event.SecurityMetadata.ConfidenceScore, event.Severity)
+}
+
+// eventRuleIDs returns the detection rules recorded in an event's
+// intelligence data under rule_id or triggered_rules
+func eventRuleIDs(event *GoldEvent) []string {
+    var ruleIDs []string
+    if ruleID, ok := event.IntelligenceData["rule_id"].(string); ok && ruleID != "" {
+        ruleIDs = append(ruleIDs, ruleID)
+    }
+    switch triggered := event.IntelligenceData["triggered_rules"].(type) {
+    case []string:
+        ruleIDs = append(ruleIDs, triggered...)
+    case []interface{}:
+        // Decoded from JSON
+        for _, ruleID := range triggered {
+            if s, ok := ruleID.(string); ok {
+                ruleIDs = append(ruleIDs, s)
+            }
+        }
+    }
+    return ruleIDs
+}
+
+// isSeverityLevel reports whether severity is an allowed level
+func isSeverityLevel(severity string) bool {
+    return severityRank(severity) >= 0
+}
+
+// severityRank returns the position of severity in severityLevels, most
+// severe first, or -1 when it is not a level
+func severityRank(severity string) int {
+    for i, level := range severityLevels {
+        if level == severity {
+            return i
+        }
+    }
+    return -1
+}
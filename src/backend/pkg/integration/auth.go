@@ -0,0 +1,486 @@
+// Package integration provides pluggable authentication for third-party security platform integrations
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/oauth2"                   // v0.8.0
+	"golang.org/x/oauth2/clientcredentials" // v0.8.0
+
+	"../../pkg/common/errors"
+)
+
+// Supported authentication types
+const (
+	AuthTypeOAuth2      = "oauth2"
+	AuthTypeAPIKey      = "apikey"
+	AuthTypeBasic       = "basic"
+	AuthTypeCertificate = "certificate"
+	AuthTypeHMAC        = "hmac"
+	AuthTypeMTLS        = "mtls"
+)
+
+// Minimum API key length; shorter values are almost always truncated or placeholder keys
+const minAPIKeyLength = 16
+
+// Authenticator applies a platform's authentication scheme to outbound requests
+type Authenticator interface {
+	// Type returns the authentication type the authenticator implements
+	Type() string
+
+	// Validate checks that the configured credentials are complete and usable
+	Validate() error
+
+	// Authenticate adds credentials to an outbound request
+	Authenticate(req *http.Request) error
+}
+
+// TransportAuthenticator is implemented by authenticators that authenticate at
+// the transport layer, such as mTLS, rather than per request
+type TransportAuthenticator interface {
+	Authenticator
+
+	// ConfigureTransport installs client credentials on the HTTP transport
+	ConfigureTransport(transport *http.Transport) error
+}
+
+// ConnectivityChecker is implemented by authenticators that can confirm their
+// credentials against the issuing service without collecting any data
+type ConnectivityChecker interface {
+	Authenticator
+
+	// CheckConnectivity makes a single live call to verify the credentials
+	CheckConnectivity(ctx context.Context) error
+}
+
+// authenticatorFactory builds an authenticator from configured credentials
+type authenticatorFactory func(credentials map[string]interface{}) (Authenticator, error)
+
+// authenticatorFactories maps each auth type to its implementation
+var authenticatorFactories = map[string]authenticatorFactory{
+	AuthTypeOAuth2:      newOAuth2Authenticator,
+	AuthTypeAPIKey:      newAPIKeyAuthenticator,
+	AuthTypeBasic:       newBasicAuthenticator,
+	AuthTypeCertificate: newMTLSAuthenticator,
+	AuthTypeHMAC:        newHMACAuthenticator,
+	AuthTypeMTLS:        newMTLSAuthenticator,
+}
+
+// NewAuthenticator creates and validates the authenticator selected by the config's auth type
+func NewAuthenticator(config AuthenticationConfig) (Authenticator, error) {
+	factory, ok := authenticatorFactories[config.Type]
+	if !ok {
+		return nil, errors.NewError("E2001", "unsupported authentication type", map[string]interface{}{
+			"auth_type": config.Type,
+		})
+	}
+
+	auth, err := factory(config.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Validate(); err != nil {
+		return nil, err
+	}
+	return auth, nil
+}
+
+// OAuth2Authenticator authenticates with bearer tokens from the client credentials flow
+type OAuth2Authenticator struct {
+	config *clientcredentials.Config
+	tokens oauth2.TokenSource
+}
+
+func newOAuth2Authenticator(credentials map[string]interface{}) (Authenticator, error) {
+	config := &clientcredentials.Config{
+		ClientID:     credentialString(credentials, "client_id"),
+		ClientSecret: credentialString(credentials, "client_secret"),
+		TokenURL:     credentialString(credentials, "token_url"),
+	}
+	if scopes := credentialString(credentials, "scopes"); scopes != "" {
+		config.Scopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+	}
+
+	return &OAuth2Authenticator{
+		config: config,
+		tokens: oauth2.ReuseTokenSource(nil, config.TokenSource(context.Background())),
+	}, nil
+}
+
+// Type returns the oauth2 auth type
+func (a *OAuth2Authenticator) Type() string { return AuthTypeOAuth2 }
+
+// Validate checks the client credentials configuration
+func (a *OAuth2Authenticator) Validate() error {
+	if err := requireCredentials(AuthTypeOAuth2, map[string]string{
+		"client_id":     a.config.ClientID,
+		"client_secret": a.config.ClientSecret,
+		"token_url":     a.config.TokenURL,
+	}); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(a.config.TokenURL, "https://") {
+		return errors.NewError("E2001", "oauth2 token_url must use https", nil)
+	}
+	if strings.TrimSpace(a.config.ClientID) != a.config.ClientID || strings.TrimSpace(a.config.ClientSecret) != a.config.ClientSecret {
+		return errors.NewError("E2001", "oauth2 client_id and client_secret must not have surrounding whitespace", nil)
+	}
+	return nil
+}
+
+// CheckConnectivity fetches a fresh token, bypassing the cached token source
+func (a *OAuth2Authenticator) CheckConnectivity(ctx context.Context) error {
+	if _, err := a.config.Token(ctx); err != nil {
+		return errors.WrapError(err, "oauth2 token request failed", map[string]interface{}{
+			"token_url": a.config.TokenURL,
+		})
+	}
+	return nil
+}
+
+// Authenticate adds a bearer token, fetching or refreshing it as needed
+func (a *OAuth2Authenticator) Authenticate(req *http.Request) error {
+	token, err := a.tokens.Token()
+	if err != nil {
+		return errors.WrapError(err, "failed to obtain oauth2 token", nil)
+	}
+	token.SetAuthHeader(req)
+	return nil
+}
+
+// APIKeyAuthenticator sends a static API key in a header or query parameter
+type APIKeyAuthenticator struct {
+	key        string
+	headerName string
+	queryParam string
+	prefix     string
+}
+
+func newAPIKeyAuthenticator(credentials map[string]interface{}) (Authenticator, error) {
+	a := &APIKeyAuthenticator{
+		key:        credentialString(credentials, "api_key"),
+		headerName: credentialString(credentials, "header_name"),
+		queryParam: credentialString(credentials, "query_param"),
+		prefix:     credentialString(credentials, "prefix"),
+	}
+	if a.headerName == "" && a.queryParam == "" {
+		a.headerName = "Authorization"
+	}
+	return a, nil
+}
+
+// Type returns the apikey auth type
+func (a *APIKeyAuthenticator) Type() string { return AuthTypeAPIKey }
+
+// Validate checks that a key is configured and plausibly well-formed. Errors
+// never include the key itself.
+func (a *APIKeyAuthenticator) Validate() error {
+	if err := requireCredentials(AuthTypeAPIKey, map[string]string{"api_key": a.key}); err != nil {
+		return err
+	}
+	if len(a.key) < minAPIKeyLength {
+		return errors.NewError("E2001", fmt.Sprintf("apikey api_key must be at least %d characters", minAPIKeyLength), nil)
+	}
+	for _, r := range a.key {
+		if unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return errors.NewError("E2001", "apikey api_key contains whitespace or non-printable characters", nil)
+		}
+	}
+	return nil
+}
+
+// Authenticate adds the API key to the request
+func (a *APIKeyAuthenticator) Authenticate(req *http.Request) error {
+	if a.queryParam != "" {
+		query := req.URL.Query()
+		query.Set(a.queryParam, a.key)
+		req.URL.RawQuery = query.Encode()
+		return nil
+	}
+
+	value := a.key
+	if a.prefix != "" {
+		value = a.prefix + " " + a.key
+	}
+	req.Header.Set(a.headerName, value)
+	return nil
+}
+
+// BasicAuthenticator sends HTTP basic credentials
+type BasicAuthenticator struct {
+	username string
+	password string
+}
+
+func newBasicAuthenticator(credentials map[string]interface{}) (Authenticator, error) {
+	return &BasicAuthenticator{
+		username: credentialString(credentials, "username"),
+		password: credentialString(credentials, "password"),
+	}, nil
+}
+
+// Type returns the basic auth type
+func (a *BasicAuthenticator) Type() string { return AuthTypeBasic }
+
+// Validate checks that both username and password are configured
+func (a *BasicAuthenticator) Validate() error {
+	return requireCredentials(AuthTypeBasic, map[string]string{
+		"username": a.username,
+		"password": a.password,
+	})
+}
+
+// Authenticate adds the basic auth header
+func (a *BasicAuthenticator) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+// HMACAuthenticator signs each request with a shared secret. The signature
+// covers the method, path and query, timestamp and a hash of the body:
+//
+//	METHOD\nPATH?QUERY\nTIMESTAMP\nHEX(HASH(BODY))
+type HMACAuthenticator struct {
+	keyID           string
+	secret          []byte
+	algorithm       string
+	signatureHeader string
+	timestampHeader string
+	keyIDHeader     string
+	now             func() time.Time
+}
+
+func newHMACAuthenticator(credentials map[string]interface{}) (Authenticator, error) {
+	a := &HMACAuthenticator{
+		keyID:           credentialString(credentials, "key_id"),
+		secret:          []byte(credentialString(credentials, "secret")),
+		algorithm:       strings.ToLower(credentialString(credentials, "algorithm")),
+		signatureHeader: credentialString(credentials, "signature_header"),
+		timestampHeader: credentialString(credentials, "timestamp_header"),
+		keyIDHeader:     credentialString(credentials, "key_id_header"),
+		now:             time.Now,
+	}
+	if a.algorithm == "" {
+		a.algorithm = "sha256"
+	}
+	if a.signatureHeader == "" {
+		a.signatureHeader = "X-Signature"
+	}
+	if a.timestampHeader == "" {
+		a.timestampHeader = "X-Timestamp"
+	}
+	if a.keyIDHeader == "" {
+		a.keyIDHeader = "X-Key-Id"
+	}
+	return a, nil
+}
+
+// Type returns the hmac auth type
+func (a *HMACAuthenticator) Type() string { return AuthTypeHMAC }
+
+// Validate checks the key ID, secret and signing algorithm
+func (a *HMACAuthenticator) Validate() error {
+	if err := requireCredentials(AuthTypeHMAC, map[string]string{
+		"key_id": a.keyID,
+		"secret": string(a.secret),
+	}); err != nil {
+		return err
+	}
+	if len(a.secret) < 32 {
+		return errors.NewError("E2001", "hmac secret must be at least 32 bytes", nil)
+	}
+	if a.hashFunc() == nil {
+		return errors.NewError("E2001", "unsupported hmac algorithm", map[string]interface{}{
+			"algorithm": a.algorithm,
+		})
+	}
+	return nil
+}
+
+// Authenticate signs the request and adds the signature headers. The body is
+// read and restored so the request can still be sent.
+func (a *HMACAuthenticator) Authenticate(req *http.Request) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		if err != nil {
+			return errors.WrapError(err, "failed to read request body for signing", nil)
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	newHash := a.hashFunc()
+	bodyHash := newHash()
+	bodyHash.Write(body)
+
+	timestamp := strconv.FormatInt(a.now().Unix(), 10)
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.RequestURI(),
+		timestamp,
+		hex.EncodeToString(bodyHash.Sum(nil)),
+	}, "\n")
+
+	mac := hmac.New(newHash, a.secret)
+	io.WriteString(mac, canonical)
+
+	req.Header.Set(a.keyIDHeader, a.keyID)
+	req.Header.Set(a.timestampHeader, timestamp)
+	req.Header.Set(a.signatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
+
+// hashFunc returns the hash constructor for the configured algorithm
+func (a *HMACAuthenticator) hashFunc() func() hash.Hash {
+	switch a.algorithm {
+	case "sha256":
+		return sha256.New
+	case "sha512":
+		return sha512.New
+	default:
+		return nil
+	}
+}
+
+// MTLSAuthenticator authenticates with a client certificate at the TLS layer
+type MTLSAuthenticator struct {
+	certPath string
+	keyPath  string
+	caPath   string
+}
+
+func newMTLSAuthenticator(credentials map[string]interface{}) (Authenticator, error) {
+	return &MTLSAuthenticator{
+		certPath: credentialString(credentials, "cert_path"),
+		keyPath:  credentialString(credentials, "key_path"),
+		caPath:   credentialString(credentials, "ca_path"),
+	}, nil
+}
+
+// Type returns the mtls auth type
+func (a *MTLSAuthenticator) Type() string { return AuthTypeMTLS }
+
+// Validate checks that the certificate and key exist, form a valid pair and
+// that the certificate is within its validity period
+func (a *MTLSAuthenticator) Validate() error {
+	if err := requireCredentials(AuthTypeMTLS, map[string]string{
+		"cert_path": a.certPath,
+		"key_path":  a.keyPath,
+	}); err != nil {
+		return err
+	}
+	config, err := a.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	leaf, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	if err != nil {
+		return errors.WrapError(err, "failed to parse client certificate", map[string]interface{}{
+			"cert_path": a.certPath,
+		})
+	}
+	now := time.Now()
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return errors.NewError("E2001", "client certificate is expired or not yet valid", map[string]interface{}{
+			"cert_path":  a.certPath,
+			"not_before": leaf.NotBefore,
+			"not_after":  leaf.NotAfter,
+		})
+	}
+	return nil
+}
+
+// Authenticate is a no-op; mTLS credentials are presented by the transport
+func (a *MTLSAuthenticator) Authenticate(req *http.Request) error {
+	return nil
+}
+
+// ConfigureTransport installs the client certificate and optional CA pool
+func (a *MTLSAuthenticator) ConfigureTransport(transport *http.Transport) error {
+	config, err := a.tlsConfig()
+	if err != nil {
+		return err
+	}
+	transport.TLSClientConfig = config
+	return nil
+}
+
+// tlsConfig loads the client key pair and CA bundle
+func (a *MTLSAuthenticator) tlsConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(a.certPath, a.keyPath)
+	if err != nil {
+		return nil, errors.WrapError(err, "failed to load client certificate", map[string]interface{}{
+			"cert_path": a.certPath,
+		})
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if a.caPath != "" {
+		caPEM, err := ioutil.ReadFile(a.caPath)
+		if err != nil {
+			return nil, errors.WrapError(err, "failed to read CA bundle", map[string]interface{}{
+				"ca_path": a.caPath,
+			})
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.NewError("E2001", "CA bundle contains no valid certificates", map[string]interface{}{
+				"ca_path": a.caPath,
+			})
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// credentialString reads a string credential, returning an empty string when absent
+func credentialString(credentials map[string]interface{}, key string) string {
+	if value, ok := credentials[key]; ok && value != nil {
+		if s, ok := value.(string); ok {
+			return s
+		}
+		return fmt.Sprintf("%v", value)
+	}
+	return ""
+}
+
+// requireCredentials reports the first missing credential for an auth type
+func requireCredentials(authType string, values map[string]string) error {
+	fields := make([]string, 0, len(values))
+	for field := range values {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		if values[field] == "" {
+			return errors.NewError("E2001", fmt.Sprintf("missing required %s credential: %s", authType, field), nil)
+		}
+	}
+	return nil
+}
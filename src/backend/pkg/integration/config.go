@@ -16,7 +16,7 @@ import (
 // Global constants for configuration validation
 var (
 	defaultCollectionModes = []string{"realtime", "batch", "hybrid"}
-	defaultAuthTypes      = []string{"oauth2", "apikey", "basic", "certificate"}
+	defaultAuthTypes      = []string{"oauth2", "apikey", "basic", "certificate", "hmac", "mtls"}
 	defaultBatchSizes    = []int{100, 500, 1000, 5000}
 	maxBatchSize        = 10000
 	supportedPlatforms  = []string{"aws", "azure", "gcp", "okta", "crowdstrike"}
@@ -24,7 +24,7 @@ var (
 
 // AuthenticationConfig defines authentication settings for platform integration
 type AuthenticationConfig struct {
-	Type        string                 `yaml:"type" validate:"required,oneof=oauth2 apikey basic certificate"`
+	Type        string                 `yaml:"type" validate:"required,oneof=oauth2 apikey basic certificate hmac mtls"`
 	Credentials map[string]interface{} `yaml:"credentials" validate:"required"`
 	ExpiryTime  time.Duration         `yaml:"expiry_time,omitempty"`
 	Renewable   bool                  `yaml:"renewable,omitempty"`
@@ -167,11 +167,18 @@ func validateAuthConfig(config AuthenticationConfig) error {
 				return errors.NewError("E2001", fmt.Sprintf("missing required basic auth credential: %s", field), nil)
 			}
 		}
-	case "certificate":
+	case "certificate", "mtls":
 		required := []string{"cert_path", "key_path"}
 		for _, field := range required {
 			if _, exists := config.Credentials[field]; !exists {
-				return errors.NewError("E2001", fmt.Sprintf("missing required certificate credential: %s", field), nil)
+				return errors.NewError("E2001", fmt.Sprintf("missing required %s credential: %s", config.Type, field), nil)
+			}
+		}
+	case "hmac":
+		required := []string{"key_id", "secret"}
+		for _, field := range required {
+			if _, exists := config.Credentials[field]; !exists {
+				return errors.NewError("E2001", fmt.Sprintf("missing required hmac credential: %s", field), nil)
 			}
 		}
 	}
@@ -7,6 +7,7 @@ import (
 
     "github.com/prometheus/client_golang/prometheus" // v1.16.0
 
+    bpmetrics "../../internal/metrics"
     "../../pkg/common/errors"
     "../../pkg/integration/config"
 )
@@ -73,6 +74,14 @@ type Platform interface {
     GetStatus(ctx context.Context) (*PlatformStatus, error)
 }
 
+// Poller is implemented by platforms that collect in discrete runs. Batch and
+// hybrid integrations of such platforms are polled on a schedule by the
+// collector service.
+type Poller interface {
+    // Poll performs a single collection run
+    Poll(ctx context.Context) error
+}
+
 // PlatformStatus represents the current state and metrics of a platform integration
 type PlatformStatus struct {
     PlatformType      string                 `json:"platform_type"`
@@ -94,10 +103,11 @@ func (ps *PlatformStatus) UpdateMetrics(metrics map[string]interface{}) error {
     ps.Metrics = metrics
     ps.LastUpdated = time.Now().UTC()
 
-    // Update Prometheus metrics
-    platformEventsProcessed.WithLabelValues(ps.PlatformType, ps.Status).Add(float64(ps.EventsProcessed))
-    platformProcessingLatency.WithLabelValues(ps.PlatformType).Observe(ps.ProcessingLatency)
-    platformErrorRate.WithLabelValues(ps.PlatformType).Set(ps.ErrorRate)
+    // Update Prometheus metrics; platform types are capped like other platform labels
+    platformLabel := bpmetrics.Guard("blackpoint_platform").Value("platform_type", ps.PlatformType)
+    platformEventsProcessed.WithLabelValues(platformLabel, ps.Status).Add(float64(ps.EventsProcessed))
+    platformProcessingLatency.WithLabelValues(platformLabel).Observe(ps.ProcessingLatency)
+    platformErrorRate.WithLabelValues(platformLabel).Set(ps.ErrorRate)
 
     return nil
 }
@@ -0,0 +1,139 @@
+// Package silver provides field-level retention for normalized security events
+package silver
+
+import (
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// RetentionPolicy maps normalized data fields to how long they may be retained.
+// A field may be a dot-path such as "user.email" naming a nested field; slices
+// along the path apply the rest of it to each element, so "devices.serial"
+// covers the serial of every device.
+type RetentionPolicy map[string]time.Duration
+
+// PurgedField records a field removed once its retention expired
+type PurgedField struct {
+    Field     string    `json:"field"`
+    ExpiredAt time.Time `json:"expired_at"`
+    PurgedAt  time.Time `json:"purged_at"`
+}
+
+// Validate checks that every retention period in the policy is positive
+func (p RetentionPolicy) Validate() error {
+    for field, period := range p {
+        if field == "" {
+            return errors.NewError("E3001", "empty field name in retention policy", nil)
+        }
+        if period <= 0 {
+            return errors.NewError("E3001", "invalid field retention period", map[string]interface{}{
+                "field":  field,
+                "period": period.String(),
+            })
+        }
+    }
+    return nil
+}
+
+// ApplyRetentionPolicy records per-field expiry times relative to the event time.
+// Fields already carrying an earlier expiry keep it so erasure deadlines never
+// move later, and fields already purged are not tracked again.
+func (s *SilverEvent) ApplyRetentionPolicy(policy RetentionPolicy) error {
+    if err := policy.Validate(); err != nil {
+        return err
+    }
+
+    if s.FieldRetention == nil {
+        s.FieldRetention = make(map[string]time.Time, len(policy))
+    }
+    purged := make(map[string]bool, len(s.PurgedFields))
+    for _, field := range s.PurgedFields {
+        purged[field.Field] = true
+    }
+
+    for field, period := range policy {
+        if purged[field] {
+            continue
+        }
+        expiresAt := s.EventTime.Add(period)
+        if existing, ok := s.FieldRetention[field]; ok && existing.Before(expiresAt) {
+            continue
+        }
+        s.FieldRetention[field] = expiresAt
+    }
+
+    return nil
+}
+
+// PurgeExpiredFields removes fields whose retention has expired, including
+// nested fields inside maps and slices of the normalized data, leaving the
+// rest of the event intact, and returns the names of the fields purged
+func (s *SilverEvent) PurgeExpiredFields(now time.Time) []string {
+    var purged []string
+
+    for field, expiresAt := range s.FieldRetention {
+        if now.Before(expiresAt) {
+            continue
+        }
+
+        removeField(s.NormalizedData, field)
+        delete(s.EncryptedFields, field)
+        delete(s.FieldRetention, field)
+
+        s.PurgedFields = append(s.PurgedFields, PurgedField{
+            Field:     field,
+            ExpiredAt: expiresAt,
+            PurgedAt:  now.UTC(),
+        })
+        purged = append(purged, field)
+    }
+
+    sort.Strings(purged)
+    return purged
+}
+
+// removeField deletes a field from normalized data. A literal top-level key is
+// removed as is; a dot-path is also followed into nested maps and slices.
+func removeField(data map[string]interface{}, field string) {
+    delete(data, field)
+    if strings.Contains(field, ".") {
+        removePath(data, strings.Split(field, "."))
+    }
+}
+
+// removePath deletes the last key of path from every value the preceding keys
+// reach, descending into each element of any slice met along the way
+func removePath(value interface{}, path []string) {
+    switch v := value.(type) {
+    case map[string]interface{}:
+        if len(path) == 1 {
+            delete(v, path[0])
+            return
+        }
+        if child, ok := v[path[0]]; ok {
+            removePath(child, path[1:])
+        }
+    case []interface{}:
+        for _, element := range v {
+            removePath(element, path)
+        }
+    case []map[string]interface{}:
+        for _, element := range v {
+            removePath(element, path)
+        }
+    }
+}
+
+// NextFieldExpiry returns the earliest pending field expiry, if any
+func (s *SilverEvent) NextFieldExpiry() (time.Time, bool) {
+    var next time.Time
+    for _, expiresAt := range s.FieldRetention {
+        if next.IsZero() || expiresAt.Before(next) {
+            next = expiresAt
+        }
+    }
+    return next, !next.IsZero()
+}
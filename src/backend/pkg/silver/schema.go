@@ -3,6 +3,7 @@ package silver
 
 import (
     "encoding/json"
+    "strings"
     "time"
 
     "github.com/blackpoint/pkg/common/errors"
@@ -34,6 +35,13 @@ var sensitiveFields = []string{
     "auth_tokens",
 }
 
+// Allowed security context values, compared case-insensitively
+var (
+    validClassifications = []string{"PUBLIC", "INTERNAL", "CONFIDENTIAL", "RESTRICTED"}
+    validSensitivities   = []string{"LOW", "MEDIUM", "HIGH", "CRITICAL"}
+    knownComplianceTags  = []string{"DEFAULT", "PCI", "PCI-DSS", "SOC2", "HIPAA", "GDPR", "ISO27001", "NIST", "CCPA"}
+)
+
 // SecurityContext represents the security metadata for an event
 type SecurityContext struct {
     Classification string            `json:"classification"`
@@ -43,6 +51,48 @@ type SecurityContext struct {
     AccessControl  map[string]string `json:"access_control"`
 }
 
+// Validate checks that the classification and sensitivity are set to allowed
+// values and that every compliance tag is known
+func (c *SecurityContext) Validate() error {
+    if c == nil {
+        return errors.NewError("E3001", "missing security context", nil)
+    }
+
+    if c.Classification == "" {
+        return errors.NewError("E3001", "missing security classification", nil)
+    }
+    if !containsFold(validClassifications, c.Classification) {
+        return errors.NewError("E3001", "invalid security classification: "+c.Classification, map[string]interface{}{
+            "field":   "classification",
+            "allowed": validClassifications,
+        })
+    }
+
+    if c.Sensitivity == "" {
+        return errors.NewError("E3001", "missing sensitivity level", nil)
+    }
+    if !containsFold(validSensitivities, c.Sensitivity) {
+        return errors.NewError("E3001", "invalid sensitivity level: "+c.Sensitivity, map[string]interface{}{
+            "field":   "sensitivity",
+            "allowed": validSensitivities,
+        })
+    }
+
+    if len(c.Compliance) == 0 {
+        return errors.NewError("E3001", "missing compliance requirements", nil)
+    }
+    for _, tag := range c.Compliance {
+        if !containsFold(knownComplianceTags, tag) {
+            return errors.NewError("E3001", "unknown compliance tag: "+tag, map[string]interface{}{
+                "field": "compliance",
+                "known": knownComplianceTags,
+            })
+        }
+    }
+
+    return nil
+}
+
 // AuditMetadata represents audit trail information
 type AuditMetadata struct {
     CreatedAt     time.Time `json:"created_at"`
@@ -53,6 +103,12 @@ type AuditMetadata struct {
     SourceEventID string    `json:"source_event_id"`
 }
 
+// TransformError records a field dropped because its transformation failed
+type TransformError struct {
+    Field string `json:"field"`
+    Error string `json:"error"`
+}
+
 // SilverEvent represents a normalized security event with enhanced security features
 type SilverEvent struct {
     EventID        string                 `json:"event_id"`
@@ -65,6 +121,9 @@ type SilverEvent struct {
     SecurityContext SecurityContext        `json:"security_context"`
     AuditMetadata  AuditMetadata         `json:"audit_metadata"`
     EncryptedFields map[string][]byte     `json:"encrypted_fields,omitempty"`
+    FieldRetention map[string]time.Time   `json:"field_retention,omitempty"`
+    PurgedFields   []PurgedField          `json:"purged_fields,omitempty"`
+    TransformErrors []TransformError      `json:"transform_errors,omitempty"`
 }
 
 // NewSilverEvent creates a new SilverEvent with security context
@@ -146,7 +205,7 @@ func (s *SilverEvent) Validate() error {
     }
 
     // Validate security context
-    if err := s.validateSecurityContext(); err != nil {
+    if err := s.SecurityContext.Validate(); err != nil {
         return err
     }
 
@@ -231,17 +290,14 @@ func (s *SilverEvent) validateFieldLengths() error {
     return nil
 }
 
-func (s *SilverEvent) validateSecurityContext() error {
-    if s.SecurityContext.Classification == "" {
-        return errors.NewError("E3001", "missing security classification", nil)
-    }
-    if s.SecurityContext.Sensitivity == "" {
-        return errors.NewError("E3001", "missing sensitivity level", nil)
-    }
-    if len(s.SecurityContext.Compliance) == 0 {
-        return errors.NewError("E3001", "missing compliance requirements", nil)
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+    for _, v := range values {
+        if strings.EqualFold(v, value) {
+            return true
+        }
     }
-    return nil
+    return false
 }
 
 func (s *SilverEvent) encryptSensitiveFields() error {
@@ -4,6 +4,7 @@ package integration
 import (
     "context"
     "encoding/json"
+    "fmt"
     "sync"
     "testing"
     "time"
@@ -145,11 +146,12 @@ func (s *NormalizerTestSuite) TestNormalizerProcessBatch() {
 
     // Process batch
     startTime := time.Now()
-    silverEvents, err := s.processor.Process(s.ctx, bronzeEvents)
+    silverEvents, failures, err := s.processor.Process(s.ctx, bronzeEvents)
     processingTime := time.Since(startTime)
 
     // Validate processing
     require.NoError(s.T(), err, "Batch processing failed")
+    require.Empty(s.T(), failures, "Events failed to normalize")
     require.Len(s.T(), silverEvents, testBatchSize, 
         "Not all events processed")
 
@@ -251,7 +253,10 @@ func (s *NormalizerTestSuite) TestNormalizerConcurrency() {
             }
 
             // Process batch
-            _, err := s.processor.Process(s.ctx, bronzeEvents)
+            _, failures, err := s.processor.Process(s.ctx, bronzeEvents)
+            if err == nil && len(failures) > 0 {
+                err = failures[0]
+            }
             results <- err
         }(i)
     }
@@ -266,6 +271,50 @@ func (s *NormalizerTestSuite) TestNormalizerConcurrency() {
     }
 }
 
+// TestNormalizerProcessBatchPartialFailure tests that one malformed event
+// does not fail the rest of the batch
+func (s *NormalizerTestSuite) TestNormalizerProcessBatchPartialFailure() {
+    const batchSize = 10
+    const malformedIndex = 4
+
+    bronzeEvents := make([]*schema.BronzeEvent, batchSize)
+    for i := 0; i < batchSize; i++ {
+        bronzeEvents[i] = &schema.BronzeEvent{
+            ID:             fmt.Sprintf("partial-event-%d", i),
+            ClientID:       fmt.Sprintf("partial-client-%d", i),
+            SourcePlatform: testSourceTypes[i%len(testSourceTypes)],
+            Timestamp:      time.Now().UTC(),
+            Payload:        json.RawMessage(fmt.Sprintf(`{
+                "source": {"ip": "192.168.1.%d"},
+                "dest": {"ip": "10.0.0.%d"},
+                "timestamp": "%s",
+                "type": "SecurityAlert"
+            }`, i, i, time.Now().UTC().Format(time.RFC3339))),
+            SchemaVersion:  "1.0",
+        }
+    }
+    bronzeEvents[malformedIndex].Payload = json.RawMessage(`{"source": {"ip": `)
+
+    silverEvents, failures, err := s.processor.Process(s.ctx, bronzeEvents)
+    require.NoError(s.T(), err, "A single malformed event must not fail the batch")
+    require.Len(s.T(), silverEvents, batchSize-1, "All well-formed events should normalize")
+    require.Len(s.T(), failures, 1, "The malformed event should be reported")
+
+    assert.Equal(s.T(), malformedIndex, failures[0].Index)
+    assert.Equal(s.T(), bronzeEvents[malformedIndex].ID, failures[0].ID)
+    assert.Error(s.T(), failures[0].Err)
+
+    // Successful results keep their input order
+    expected := 0
+    for _, event := range silverEvents {
+        if expected == malformedIndex {
+            expected++
+        }
+        assert.Equal(s.T(), fmt.Sprintf("partial-client-%d", expected), event.ClientID)
+        expected++
+    }
+}
+
 // TestMain runs the test suite
 func TestMain(m *testing.M) {
     suite.Run(m, new(NormalizerTestSuite))
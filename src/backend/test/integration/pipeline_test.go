@@ -0,0 +1,447 @@
+// Package integration provides integration tests for the BlackPoint Security Integration Framework
+package integration
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/confluentinc/confluent-kafka-go/kafka"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/testutil"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "github.com/blackpoint/internal/normalizer"
+    "github.com/blackpoint/internal/streaming"
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/silver/schema"
+)
+
+// TestNormalizerPipeline drives Bronze messages through the consumer,
+// processor and producer, and verifies the Silver output, metrics and
+// committed offsets
+func TestNormalizerPipeline(t *testing.T) {
+    cluster, err := kafka.NewMockCluster(1)
+    require.NoError(t, err)
+    defer cluster.Close()
+
+    const (
+        bronzeTopic   = "bronze-events"
+        silverTopic   = "silver-events"
+        deadTopic     = "bronze-dead-letter"
+        consumerGroup = "normalizer-pipeline"
+        validEvents   = 3
+    )
+    bootstrap := cluster.BootstrapServers()
+
+    // Seed the Bronze topic with an undecodable message ahead of valid events,
+    // so it has been dead-lettered once every valid event is published
+    seed, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": bootstrap})
+    require.NoError(t, err)
+    values := [][]byte{[]byte(`not a bronze event`)}
+    for i := 0; i < validEvents; i++ {
+        values = append(values, []byte(fmt.Sprintf(`{
+            "id": "pipeline-event-%d",
+            "client_id": "pipeline-client",
+            "source_platform": "okta",
+            "timestamp": "%s",
+            "payload": {"source": {"ip": "192.168.1.%d"}, "type": "SecurityAlert"},
+            "schema_version": "1.0"
+        }`, i, time.Now().UTC().Format(time.RFC3339), i)))
+    }
+    for _, value := range values {
+        topic := bronzeTopic
+        require.NoError(t, seed.Produce(&kafka.Message{
+            TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0},
+            Value:          value,
+        }, nil))
+    }
+    require.Zero(t, seed.Flush(int(testTimeout/time.Millisecond)))
+    seed.Close()
+
+    mapper := normalizer.NewFieldMapper(map[string]string{
+        "source.ip": "src_ip",
+        "type":      "event_type",
+    }, nil)
+    processor, err := normalizer.NewProcessor(mapper, normalizer.NewTransformer(5*time.Second), processingLatencySLA)
+    require.NoError(t, err)
+
+    client, err := streaming.NewKafkaClient(&streaming.KafkaConfig{
+        BootstrapServers: bootstrap,
+        SecurityProtocol: "PLAINTEXT",
+        SaslMechanism:    "PLAIN",
+        SaslUsername:     "test",
+        SaslPassword:     "test",
+    })
+    require.NoError(t, err)
+    defer client.Close()
+    producer, err := streaming.NewProducer(client, silverTopic, nil)
+    require.NoError(t, err)
+    defer producer.Close()
+    deadLetter, err := streaming.NewProducer(client, deadTopic, nil)
+    require.NoError(t, err)
+    defer deadLetter.Close()
+
+    processed := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_pipeline_processed_total"})
+    failed := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_pipeline_errors_total"})
+    var latencyObservations int
+    latency := prometheus.ObserverFunc(func(float64) { latencyObservations++ })
+    pipeline, err := normalizer.NewPipeline(processor, producer, normalizer.PipelineConfig{
+        BatchSize: 2,
+        Timeout:   processingLatencySLA,
+        SecurityContext: &schema.SecurityContext{
+            Classification: "CONFIDENTIAL",
+            Sensitivity:    "HIGH",
+            Compliance:     []string{"SOC2"},
+            Encryption:     make(map[string]string),
+            AccessControl:  make(map[string]string),
+        },
+        Processed: processed,
+        Errors:    failed,
+        Latency:   latency,
+    })
+    require.NoError(t, err)
+    pipeline.SetDeadLetter(deadLetter)
+
+    consumer, err := streaming.NewConsumer(&kafka.ConfigMap{
+        "bootstrap.servers": bootstrap,
+        "group.id":          consumerGroup,
+    }, []string{bronzeTopic}, streaming.ConsumerOptions{
+        BatchSize:       len(values),
+        CommitInterval:  100 * time.Millisecond,
+        CommitStrategy:  streaming.CommitManualPerBatch,
+        AutoOffsetReset: "earliest",
+    })
+    require.NoError(t, err)
+    consumer.SetHandler(pipeline.HandleBatch)
+    require.NoError(t, consumer.Start())
+
+    // Every valid event arrives on the Silver topic with the configured context
+    received := make(map[string]schema.SilverEvent)
+    for _, msg := range readTopic(t, bootstrap, silverTopic, validEvents) {
+        var event schema.SilverEvent
+        require.NoError(t, json.Unmarshal(msg.Value, &event))
+        received[event.BronzeEventID] = event
+    }
+    require.Len(t, received, validEvents, "every valid Bronze event should be published")
+    for i := 0; i < validEvents; i++ {
+        event, ok := received[fmt.Sprintf("pipeline-event-%d", i)]
+        require.True(t, ok, "missing Silver event %d", i)
+        assert.Equal(t, "CONFIDENTIAL", event.SecurityContext.Classification)
+    }
+
+    require.NoError(t, consumer.Stop())
+
+    assert.Equal(t, float64(validEvents), testutil.ToFloat64(processed))
+    assert.Equal(t, float64(1), testutil.ToFloat64(failed), "the undecodable message counts as an error")
+    assert.Positive(t, latencyObservations, "batch latency should be observed")
+
+    // The undecodable message is kept, unchanged, on the dead-letter topic
+    dead := readTopic(t, bootstrap, deadTopic, 1)
+    require.Len(t, dead, 1)
+    assert.Equal(t, values[0], dead[0].Value)
+    assertHeader(t, dead[0], normalizer.HeaderSourceOffset, "0")
+
+    // Offsets are committed past the whole batch, the dead-lettered message included
+    assert.Equal(t, kafka.Offset(len(values)), committedOffset(t, bootstrap, consumerGroup, bronzeTopic))
+}
+
+// readTopic reads up to want messages from the start of topic
+func readTopic(t *testing.T, bootstrap, topic string, want int) []*kafka.Message {
+    t.Helper()
+    reader, err := kafka.NewConsumer(&kafka.ConfigMap{
+        "bootstrap.servers": bootstrap,
+        "group.id":          topic + "-reader",
+        "auto.offset.reset": "earliest",
+    })
+    require.NoError(t, err)
+    defer reader.Close()
+    require.NoError(t, reader.Subscribe(topic, nil))
+
+    var messages []*kafka.Message
+    deadline := time.Now().Add(testTimeout)
+    for len(messages) < want && time.Now().Before(deadline) {
+        msg, err := reader.ReadMessage(time.Second)
+        if err != nil {
+            continue
+        }
+        messages = append(messages, msg)
+    }
+    return messages
+}
+
+// committedOffset returns the offset group has committed on partition 0 of topic
+func committedOffset(t *testing.T, bootstrap, group, topic string) kafka.Offset {
+    t.Helper()
+    committed, err := kafka.NewConsumer(&kafka.ConfigMap{
+        "bootstrap.servers": bootstrap,
+        "group.id":          group,
+    })
+    require.NoError(t, err)
+    defer committed.Close()
+    offsets, err := committed.Committed([]kafka.TopicPartition{{Topic: &topic, Partition: 0}}, int(testTimeout/time.Millisecond))
+    require.NoError(t, err)
+    require.Len(t, offsets, 1)
+    return offsets[0].Offset
+}
+
+// assertHeader checks a Kafka header value
+func assertHeader(t *testing.T, msg *kafka.Message, key, want string) {
+    t.Helper()
+    for _, header := range msg.Headers {
+        if header.Key == key {
+            assert.Equal(t, want, string(header.Value), "header %s", key)
+            return
+        }
+    }
+    t.Errorf("Missing header %s", key)
+}
+
+// envelopeMagic prefixes every envelopeSerializer message
+var envelopeMagic = []byte{0x00, 0xb1}
+
+// envelopeSerializer frames JSON in a binary envelope, standing in for a
+// schema registry serializer: its messages are not valid JSON, so anything
+// that bypasses the configured serializer fails on them
+type envelopeSerializer struct{}
+
+func (envelopeSerializer) Serialize(topic string, value interface{}) ([]byte, error) {
+    data, err := json.Marshal(value)
+    if err != nil {
+        return nil, err
+    }
+    return append(append([]byte{}, envelopeMagic...), data...), nil
+}
+
+func (envelopeSerializer) Deserialize(topic string, data []byte, value interface{}) error {
+    if !bytes.HasPrefix(data, envelopeMagic) {
+        return errors.NewError("E3001", "message is not enveloped", map[string]interface{}{
+            "topic": topic,
+        })
+    }
+    return json.Unmarshal(data[len(envelopeMagic):], value)
+}
+
+// TestNormalizerPipelineSerializer runs the pipeline with a non-JSON
+// serializer and verifies events are decoded and published through it,
+// while messages it cannot decode are dead-lettered before being committed
+func TestNormalizerPipelineSerializer(t *testing.T) {
+    cluster, err := kafka.NewMockCluster(1)
+    require.NoError(t, err)
+    defer cluster.Close()
+
+    const (
+        bronzeTopic   = "bronze-enveloped"
+        silverTopic   = "silver-enveloped"
+        deadTopic     = "bronze-enveloped-dead-letter"
+        consumerGroup = "normalizer-serializer"
+        validEvents   = 2
+    )
+    bootstrap := cluster.BootstrapServers()
+    serializer := envelopeSerializer{}
+
+    // Plain JSON is not in the envelope, so the serializer rejects it
+    var values [][]byte
+    for i := 0; i < validEvents; i++ {
+        value, err := serializer.Serialize(bronzeTopic, map[string]interface{}{
+            "id":              fmt.Sprintf("enveloped-event-%d", i),
+            "client_id":       "pipeline-client",
+            "source_platform": "okta",
+            "timestamp":       time.Now().UTC().Format(time.RFC3339),
+            "payload":         map[string]interface{}{"source": map[string]interface{}{"ip": "10.0.0.1"}, "type": "SecurityAlert"},
+            "schema_version":  "1.0",
+        })
+        require.NoError(t, err)
+        values = append(values, value)
+    }
+    values = append(values, []byte(`{"id": "plain-json"}`))
+
+    seed, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": bootstrap})
+    require.NoError(t, err)
+    for _, value := range values {
+        topic := bronzeTopic
+        require.NoError(t, seed.Produce(&kafka.Message{
+            TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0},
+            Value:          value,
+        }, nil))
+    }
+    require.Zero(t, seed.Flush(int(testTimeout/time.Millisecond)))
+    seed.Close()
+
+    processor, err := normalizer.NewProcessor(normalizer.NewFieldMapper(map[string]string{
+        "source.ip": "src_ip",
+        "type":      "event_type",
+    }, nil), normalizer.NewTransformer(5*time.Second), processingLatencySLA)
+    require.NoError(t, err)
+
+    client, err := streaming.NewKafkaClient(&streaming.KafkaConfig{
+        BootstrapServers: bootstrap,
+        SecurityProtocol: "PLAINTEXT",
+        SaslMechanism:    "PLAIN",
+        SaslUsername:     "test",
+        SaslPassword:     "test",
+    })
+    require.NoError(t, err)
+    defer client.Close()
+    producer, err := streaming.NewProducer(client, silverTopic, &streaming.ProducerOptions{Serializer: serializer})
+    require.NoError(t, err)
+    defer producer.Close()
+    deadLetter, err := streaming.NewProducer(client, deadTopic, nil)
+    require.NoError(t, err)
+    defer deadLetter.Close()
+
+    pipeline, err := normalizer.NewPipeline(processor, producer, normalizer.PipelineConfig{Timeout: processingLatencySLA})
+    require.NoError(t, err)
+    pipeline.SetDeadLetter(deadLetter)
+
+    consumer, err := streaming.NewConsumer(&kafka.ConfigMap{
+        "bootstrap.servers": bootstrap,
+        "group.id":          consumerGroup,
+    }, []string{bronzeTopic}, streaming.ConsumerOptions{
+        BatchSize:       len(values),
+        CommitInterval:  100 * time.Millisecond,
+        CommitStrategy:  streaming.CommitManualPerBatch,
+        AutoOffsetReset: "earliest",
+        Serializer:      serializer,
+    })
+    require.NoError(t, err)
+    consumer.SetHandler(pipeline.HandleBatch)
+    require.NoError(t, consumer.Start())
+
+    // Silver events are written in the envelope
+    silverMessages := readTopic(t, bootstrap, silverTopic, validEvents)
+    require.Len(t, silverMessages, validEvents, "every enveloped Bronze event should be published")
+    for _, msg := range silverMessages {
+        var event schema.SilverEvent
+        require.NoError(t, serializer.Deserialize(silverTopic, msg.Value, &event), "Silver events must use the producer's serializer")
+        assert.Contains(t, event.BronzeEventID, "enveloped-event-")
+    }
+
+    dead := readTopic(t, bootstrap, deadTopic, 1)
+    require.NoError(t, consumer.Stop())
+    require.Len(t, dead, 1)
+    assert.Equal(t, values[validEvents], dead[0].Value)
+
+    assert.Equal(t, kafka.Offset(len(values)), committedOffset(t, bootstrap, consumerGroup, bronzeTopic))
+}
+
+// staticLag reports a fixed downstream lag
+type staticLag struct {
+    lag int64
+}
+
+func (s *staticLag) Lag() (int64, error) {
+    return s.lag, nil
+}
+
+// collectingPublisher keeps the Silver events published to it
+type collectingPublisher struct {
+    mu     sync.Mutex
+    events [][]byte
+}
+
+func (c *collectingPublisher) PublishBatch(ctx context.Context, events [][]byte) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.events = append(c.events, events...)
+    return nil
+}
+
+func (c *collectingPublisher) PublishValues(ctx context.Context, values []interface{}, headers []map[string]string) error {
+    events := make([][]byte, len(values))
+    for i, value := range values {
+        data, err := json.Marshal(value)
+        if err != nil {
+            return err
+        }
+        events[i] = data
+    }
+    return c.PublishBatch(ctx, events)
+}
+
+func (c *collectingPublisher) published() int {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return len(c.events)
+}
+
+// TestNormalizerPipelineBackpressure drives consumed batches through the
+// pipeline while the analyzer lags and verifies sample mode sheds events
+// before processing, then admits everything once lag recovers
+func TestNormalizerPipelineBackpressure(t *testing.T) {
+    const (
+        batches   = 10
+        batchSize = 100
+        total     = batches * batchSize
+    )
+
+    lag := &staticLag{lag: 5000}
+    controller, err := normalizer.NewBackpressureController(lag, normalizer.BackpressureConfig{
+        HighLag:    1000,
+        Mode:       normalizer.ThrottleModeSample,
+        SampleRate: 0.2,
+    }, nil)
+    require.NoError(t, err)
+    controller.Check()
+    require.True(t, controller.State().Throttled)
+
+    processor, err := normalizer.NewProcessor(normalizer.NewFieldMapper(map[string]string{
+        "source.ip": "src_ip",
+        "type":      "event_type",
+    }, nil), normalizer.NewTransformer(5*time.Second), processingLatencySLA)
+    require.NoError(t, err)
+
+    publisher := &collectingPublisher{}
+    processed := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_backpressure_processed_total"})
+    failed := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_backpressure_errors_total"})
+    pipeline, err := normalizer.NewPipeline(processor, publisher, normalizer.PipelineConfig{
+        Timeout:   processingLatencySLA,
+        Admission: controller,
+        Processed: processed,
+        Errors:    failed,
+    })
+    require.NoError(t, err)
+
+    consume := func(offset int) []*streaming.Message {
+        messages := make([]*streaming.Message, batchSize)
+        for i := range messages {
+            messages[i] = &streaming.Message{
+                Topic:  "bronze-events",
+                Offset: int64(offset + i),
+                Value: []byte(fmt.Sprintf(`{
+                    "id": "load-event-%d",
+                    "client_id": "pipeline-client",
+                    "source_platform": "okta",
+                    "timestamp": "%s",
+                    "payload": {"source": {"ip": "10.0.0.1"}, "type": "SecurityAlert"},
+                    "schema_version": "1.0"
+                }`, offset+i, time.Now().UTC().Format(time.RFC3339))),
+            }
+        }
+        return messages
+    }
+
+    for b := 0; b < batches; b++ {
+        require.NoError(t, pipeline.HandleBatch(context.Background(), consume(b*batchSize)))
+    }
+
+    // About SampleRate of the events are admitted; the bounds are far enough
+    // from the expected 200 that a fair sampler never falls outside them
+    admitted := publisher.published()
+    assert.Greater(t, admitted, 0, "sample mode must still admit some events")
+    assert.Less(t, admitted, total/2, "sample mode must shed most events")
+    assert.Equal(t, float64(admitted), testutil.ToFloat64(processed), "only admitted events are processed")
+    assert.Zero(t, testutil.ToFloat64(failed), "shed events are not processing errors")
+
+    // Once lag recovers every event is admitted
+    lag.lag = 0
+    controller.Check()
+    require.False(t, controller.State().Throttled)
+    require.NoError(t, pipeline.HandleBatch(context.Background(), consume(total)))
+    assert.Equal(t, admitted+batchSize, publisher.published())
+}
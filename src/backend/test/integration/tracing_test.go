@@ -0,0 +1,232 @@
+// Package integration provides integration tests for the BlackPoint Security Integration Framework
+package integration
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/json"
+    "fmt"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/confluentinc/confluent-kafka-go/kafka"
+
+    "github.com/blackpoint/internal/analyzer"
+    "github.com/blackpoint/internal/normalizer"
+    "github.com/blackpoint/internal/streaming"
+    "github.com/blackpoint/pkg/gold"
+    "github.com/blackpoint/pkg/silver"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// recordedSpan keeps a span's identity and parent for ancestry assertions.
+// The embedded non-recording span supplies the rest of the interface.
+type recordedSpan struct {
+    trace.Span
+    name        string
+    spanContext trace.SpanContext
+    parent      trace.SpanContext
+}
+
+func (s *recordedSpan) SpanContext() trace.SpanContext {
+    return s.spanContext
+}
+
+// spanRecorder is a minimal tracer provider recording every started span
+type spanRecorder struct {
+    mu    sync.Mutex
+    spans map[trace.SpanID]*recordedSpan
+}
+
+func newSpanRecorder() *spanRecorder {
+    return &spanRecorder{spans: make(map[trace.SpanID]*recordedSpan)}
+}
+
+func (r *spanRecorder) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+    return r
+}
+
+func (r *spanRecorder) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+    parent := trace.SpanContextFromContext(ctx)
+
+    var traceID trace.TraceID
+    if parent.IsValid() {
+        traceID = parent.TraceID()
+    } else {
+        rand.Read(traceID[:])
+    }
+    var spanID trace.SpanID
+    rand.Read(spanID[:])
+
+    span := &recordedSpan{
+        Span: trace.SpanFromContext(context.Background()),
+        name: name,
+        spanContext: trace.NewSpanContext(trace.SpanContextConfig{
+            TraceID:    traceID,
+            SpanID:     spanID,
+            TraceFlags: trace.FlagsSampled,
+        }),
+        parent: parent,
+    }
+
+    r.mu.Lock()
+    r.spans[spanID] = span
+    r.mu.Unlock()
+    return trace.ContextWithSpan(ctx, span), span
+}
+
+// named returns the recorded spans with the given name
+func (r *spanRecorder) named(name string) []*recordedSpan {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    var spans []*recordedSpan
+    for _, span := range r.spans {
+        if span.name == name {
+            spans = append(spans, span)
+        }
+    }
+    return spans
+}
+
+// isAncestor reports whether ancestor is reachable from span through parents
+func (r *spanRecorder) isAncestor(ancestor, span *recordedSpan) bool {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    for current := span; current != nil && current.parent.IsValid(); {
+        if current.parent.SpanID() == ancestor.spanContext.SpanID() {
+            return true
+        }
+        current = r.spans[current.parent.SpanID()]
+    }
+    return false
+}
+
+// alwaysDetect raises an alert for every event
+type alwaysDetect struct{}
+
+func (alwaysDetect) Detect(event *silver.SilverEvent) (bool, float64, map[string]interface{}) {
+    return true, 0.9, map[string]interface{}{"rule_type": "trace_propagation"}
+}
+
+// TestTracePropagationAcrossTiers follows one event from collection through
+// normalization to alert generation. Each Kafka hop runs through the real
+// producer and consumer wrappers and the normalizer and analyzer pipelines.
+func TestTracePropagationAcrossTiers(t *testing.T) {
+    recorder := newSpanRecorder()
+    otel.SetTracerProvider(recorder)
+    defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+    cluster, err := kafka.NewMockCluster(1)
+    require.NoError(t, err)
+    defer cluster.Close()
+
+    const (
+        bronzeTopic = "trace-bronze-events"
+        silverTopic = "trace-silver-events"
+        goldTopic   = "trace-gold-alerts"
+    )
+    bootstrap := cluster.BootstrapServers()
+
+    client, err := streaming.NewKafkaClient(&streaming.KafkaConfig{
+        BootstrapServers: bootstrap,
+        SecurityProtocol: "PLAINTEXT",
+        SaslMechanism:    "PLAIN",
+        SaslUsername:     "test",
+        SaslPassword:     "test",
+    })
+    require.NoError(t, err)
+    defer client.Close()
+    bronzeProducer, err := streaming.NewProducer(client, bronzeTopic, nil)
+    require.NoError(t, err)
+    defer bronzeProducer.Close()
+    silverProducer, err := streaming.NewProducer(client, silverTopic, nil)
+    require.NoError(t, err)
+    defer silverProducer.Close()
+    goldProducer, err := streaming.NewProducer(client, goldTopic, nil)
+    require.NoError(t, err)
+    defer goldProducer.Close()
+
+    // Collection: the collector's span is the root of the event's trace and
+    // the producer carries it in the Bronze message headers
+    collectCtx, collectSpan := otel.Tracer("collector.realtime").Start(context.Background(), "collect_event")
+    require.NoError(t, bronzeProducer.Publish(collectCtx, []byte(fmt.Sprintf(`{
+        "id": "trace-event-1",
+        "client_id": "trace-client",
+        "source_platform": "okta",
+        "timestamp": "%s",
+        "schema_version": "1.0",
+        "payload": {
+            "alert_type": "login_failure",
+            "event_timestamp": "2024-01-20T10:00:00Z",
+            "source_ip": "192.168.1.10",
+            "destination_ip": "10.0.0.5"
+        }
+    }`, time.Now().UTC().Format(time.RFC3339)))))
+    collectSpan.End()
+
+    consumerOptions := streaming.ConsumerOptions{
+        BatchSize:       1,
+        CommitInterval:  100 * time.Millisecond,
+        CommitStrategy:  streaming.CommitManualPerBatch,
+        AutoOffsetReset: "earliest",
+    }
+
+    // Normalization: the consumer extracts the trace and the pipeline
+    // publishes the Silver event under its own span
+    mapper := normalizer.NewFieldMapper(nil, nil)
+    processor, err := normalizer.NewProcessor(mapper, normalizer.NewTransformer(5*time.Second), 5*time.Second)
+    require.NoError(t, err)
+    normalizerPipeline, err := normalizer.NewPipeline(processor, silverProducer, normalizer.PipelineConfig{})
+    require.NoError(t, err)
+    normalizerConsumer, err := streaming.NewConsumer(&kafka.ConfigMap{
+        "bootstrap.servers": bootstrap,
+        "group.id":          "trace-normalizer",
+    }, []string{bronzeTopic}, consumerOptions)
+    require.NoError(t, err)
+    normalizerConsumer.SetHandler(normalizerPipeline.HandleBatch)
+    require.NoError(t, normalizerConsumer.Start())
+    defer normalizerConsumer.Stop()
+
+    // Analysis: the Silver message carries the trace on to detection
+    require.NoError(t, analyzer.RegisterDetectionRule("trace-propagation", alwaysDetect{}))
+    defer analyzer.UnregisterDetectionRule("trace-propagation")
+    analyzerPipeline, err := analyzer.NewPipeline(goldProducer)
+    require.NoError(t, err)
+    analyzerConsumer, err := streaming.NewConsumer(&kafka.ConfigMap{
+        "bootstrap.servers": bootstrap,
+        "group.id":          "trace-analyzer",
+    }, []string{silverTopic}, consumerOptions)
+    require.NoError(t, err)
+    analyzerConsumer.SetHandler(analyzerPipeline.HandleBatch)
+    require.NoError(t, analyzerConsumer.Start())
+    defer analyzerConsumer.Stop()
+
+    goldMessages := readTopic(t, bootstrap, goldTopic, 1)
+    require.Len(t, goldMessages, 1)
+    var alert gold.Alert
+    require.NoError(t, json.Unmarshal(goldMessages[0].Value, &alert))
+
+    collectSpans := recorder.named("collect_event")
+    normalizeSpans := recorder.named("normalize_message")
+    processSpans := recorder.named("process_single")
+    analyzeSpans := recorder.named("analyze_message")
+    detectSpans := recorder.named("detect_threats")
+    require.Len(t, collectSpans, 1)
+    require.Len(t, normalizeSpans, 1)
+    require.Len(t, processSpans, 1)
+    require.Len(t, analyzeSpans, 1)
+    require.Len(t, detectSpans, 1)
+
+    assert.True(t, recorder.isAncestor(collectSpans[0], processSpans[0]), "collection span should be an ancestor of normalization")
+    assert.True(t, recorder.isAncestor(normalizeSpans[0], analyzeSpans[0]), "the Silver message should carry the normalization span")
+    assert.True(t, recorder.isAncestor(analyzeSpans[0], detectSpans[0]), "detection should run under the analysis span")
+    assert.True(t, recorder.isAncestor(collectSpans[0], detectSpans[0]), "collection span should be an ancestor of alert generation")
+
+    traceID := collectSpans[0].spanContext.TraceID().String()
+    assert.Equal(t, traceID, alert.TraceID, "alert should carry the pipeline trace ID")
+    assertHeader(t, goldMessages[0], streaming.HeaderTraceID, traceID)
+}
@@ -0,0 +1,152 @@
+// Package integration provides integration tests for the BlackPoint Security Integration Framework
+package integration
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/blackpoint/internal/streaming"
+    "github.com/confluentinc/confluent-kafka-go/kafka"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// TestTransactionalPublishCrash verifies that Silver events produced by a
+// normalizer that crashes mid-transaction are never visible to a
+// read-committed consumer, and that the retried batch is published exactly once
+func TestTransactionalPublishCrash(t *testing.T) {
+    cluster, err := kafka.NewMockCluster(1)
+    require.NoError(t, err)
+    defer cluster.Close()
+
+    const (
+        bronzeTopic     = "bronze-events"
+        silverTopic     = "silver-events"
+        transactionalID = "normalizer-0"
+    )
+    bootstrap := cluster.BootstrapServers()
+
+    // Seed the Bronze topic
+    seed, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": bootstrap})
+    require.NoError(t, err)
+    for i := 0; i < 3; i++ {
+        topic := bronzeTopic
+        require.NoError(t, seed.Produce(&kafka.Message{
+            TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0},
+            Value:          []byte(fmt.Sprintf(`{"seq":%d}`, i)),
+        }, nil))
+    }
+    require.Zero(t, seed.Flush(int(testTimeout/time.Millisecond)))
+    seed.Close()
+
+    client, err := streaming.NewKafkaClient(&streaming.KafkaConfig{
+        BootstrapServers: bootstrap,
+        SecurityProtocol: "PLAINTEXT",
+        SaslMechanism:    "PLAIN",
+        SaslUsername:     "test",
+        SaslPassword:     "test",
+    })
+    require.NoError(t, err)
+    defer client.Close()
+
+    newProducer := func() (*streaming.Producer, error) {
+        return streaming.NewProducer(client, silverTopic, &streaming.ProducerOptions{
+            TransactionalID: transactionalID,
+        })
+    }
+
+    consumer, err := streaming.NewConsumer(&kafka.ConfigMap{
+        "bootstrap.servers": bootstrap,
+        "group.id":          "normalizer",
+    }, []string{bronzeTopic}, streaming.ConsumerOptions{
+        BatchSize:      3,
+        CommitInterval: 100 * time.Millisecond,
+        CommitStrategy: streaming.CommitTransactional,
+    })
+    require.NoError(t, err)
+
+    var (
+        mu        sync.Mutex
+        attempts  int
+        committed = make(chan struct{})
+    )
+    consumer.SetHandler(func(ctx context.Context, messages []*streaming.Message) error {
+        mu.Lock()
+        defer mu.Unlock()
+        attempts++
+
+        events := make([][]byte, len(messages))
+        for i, msg := range messages {
+            events[i] = []byte(fmt.Sprintf(`{"attempt":%d,"bronze_offset":%d}`, attempts, msg.Offset))
+        }
+
+        // The first attempt crashes after producing part of its output
+        if attempts == 1 {
+            crashed, err := newProducer()
+            if err != nil {
+                return err
+            }
+            defer crashed.Close()
+            if err := crashed.BeginTransaction(); err != nil {
+                return err
+            }
+            if err := crashed.PublishBatch(ctx, events[:2]); err != nil {
+                return err
+            }
+            return fmt.Errorf("simulated crash mid-transaction")
+        }
+
+        // The restarted producer reuses the transactional ID, fencing the
+        // crashed instance and aborting its open transaction
+        producer, err := newProducer()
+        if err != nil {
+            return err
+        }
+        defer producer.Close()
+        if err := producer.PublishTransactional(ctx, consumer, messages, events); err != nil {
+            return err
+        }
+        close(committed)
+        return nil
+    })
+    require.NoError(t, consumer.Start())
+    defer consumer.Stop()
+
+    select {
+    case <-committed:
+    case <-time.After(testTimeout):
+        t.Fatal("Timed out waiting for the transactional batch to commit")
+    }
+
+    // A read-committed consumer sees only the committed attempt's events
+    reader, err := kafka.NewConsumer(&kafka.ConfigMap{
+        "bootstrap.servers": bootstrap,
+        "group.id":          "silver-reader",
+        "auto.offset.reset": "earliest",
+        "isolation.level":   "read_committed",
+    })
+    require.NoError(t, err)
+    defer reader.Close()
+    require.NoError(t, reader.Subscribe(silverTopic, nil))
+
+    var values []string
+    deadline := time.Now().Add(10 * time.Second)
+    for len(values) < 3 && time.Now().Before(deadline) {
+        msg, err := reader.ReadMessage(time.Second)
+        if err != nil {
+            continue
+        }
+        values = append(values, string(msg.Value))
+    }
+    require.Len(t, values, 3, "expected exactly the committed batch")
+    for _, value := range values {
+        assert.Contains(t, value, `"attempt":2`, "events from the aborted transaction must not be visible")
+    }
+
+    // Nothing beyond the committed batch arrives later
+    msg, err := reader.ReadMessage(2 * time.Second)
+    assert.Error(t, err, "unexpected extra Silver event: %v", msg)
+}
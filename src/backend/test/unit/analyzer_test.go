@@ -2,11 +2,23 @@ package analyzer_test
 
 import (
     "context"
+    "encoding/json"
+    "fmt"
+    "math/rand"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
     "sync"
     "testing"
     "time"
 
+    "github.com/alicebob/miniredis/v2"
+    "github.com/go-redis/redis/v8"
+
     "github.com/blackpoint/internal/analyzer"
+    "github.com/blackpoint/internal/metrics"
+    "github.com/blackpoint/internal/streaming"
     "github.com/blackpoint/pkg/silver"
     "github.com/blackpoint/pkg/gold"
     "github.com/blackpoint/pkg/common/errors"
@@ -149,7 +161,8 @@ func TestDetectThreats(t *testing.T) {
     }
 }
 
-// TestCorrelateEvents tests the event correlation functionality
+// TestCorrelateEvents tests that correlation follows the entity and time
+// distribution of the generated events
 func TestCorrelateEvents(t *testing.T) {
     ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
     defer cancel()
@@ -161,45 +174,76 @@ func TestCorrelateEvents(t *testing.T) {
         DataSensitivity: "high",
         ComplianceReqs: []string{"SOC2", "ISO27001"},
     }
-
-    correlator, err := analyzer.NewEventCorrelator(5*time.Minute, secCtx)
-    if err != nil {
-        t.Fatalf("Failed to create correlator: %v", err)
-    }
+    const window = 5 * time.Minute
 
     // Test cases
     tests := []struct {
         name string
-        events []*silver.SilverEvent
+        events testEventConfig
+        minEvents int
         expectedCorrelations int
-        expectedError error
+        expectedEntities int
     }{
         {
+            // A lone event never reaches the per-entity minimum
             name: "Single Event Correlation",
-            events: generateTestEvents(1),
-            expectedCorrelations: 1,
-            expectedError: nil,
+            events: testEventConfig{Count: 1, Entities: 1, Spread: time.Minute},
+            minEvents: 2,
+            expectedCorrelations: 0,
         },
         {
+            // One window; users 0, 1 and 2 each have 2 of the 6 events
             name: "Multiple Event Correlation",
-            events: generateTestEvents(5),
-            expectedCorrelations: 2,
-            expectedError: nil,
+            events: testEventConfig{Count: 6, Entities: 3, Spread: time.Minute},
+            minEvents: 2,
+            expectedCorrelations: 1,
+            expectedEntities: 3,
+        },
+        {
+            // Only the first of 4 entities has 2 of the 5 events
+            name: "Uneven Entity Distribution",
+            events: testEventConfig{Count: 5, Entities: 4, Spread: time.Minute},
+            minEvents: 2,
+            expectedCorrelations: 1,
+            expectedEntities: 1,
         },
         {
+            // Too many entities for any to repeat
+            name: "Distinct Entities Do Not Correlate",
+            events: testEventConfig{Count: 5, Entities: 5, Spread: time.Minute},
+            minEvents: 2,
+            expectedCorrelations: 0,
+        },
+        {
+            // Events 3.6s apart over an hour: each 5 minute window holds 84
+            // events, so 1000 events form 12 windows and every window has at
+            // least 7 events from each of the 10 users
             name: "Performance Test - Concurrent Correlation",
-            events: generateTestEvents(testDataSize),
-            expectedCorrelations: testDataSize / 10,
-            expectedError: nil,
+            events: testEventConfig{Count: testDataSize, Entities: 10, Spread: time.Hour},
+            minEvents: 7,
+            expectedCorrelations: 12,
+            expectedEntities: 10,
         },
     }
 
     for _, tt := range tests {
         t.Run(tt.name, func(t *testing.T) {
+            correlator, err := analyzer.NewEventCorrelator(window, secCtx)
+            if err != nil {
+                t.Fatalf("Failed to create correlator: %v", err)
+            }
+            rule := &entityCorrelationRule{keyField: "user_id", minEvents: tt.minEvents}
+            if err := correlator.RegisterRule("entity_activity", rule); err != nil {
+                t.Fatalf("Failed to register rule: %v", err)
+            }
+
             start := time.Now()
 
             // Process correlations
-            alerts, err := correlator.CorrelateEvents(ctx, tt.events)
+            alerts, err := correlator.CorrelateEvents(ctx, generateEntityEvents(tt.events))
+            if err != nil {
+                t.Fatalf("Correlation failed: %v", err)
+            }
 
             // Validate processing time
             processingTime := time.Since(start)
@@ -207,14 +251,15 @@ func TestCorrelateEvents(t *testing.T) {
                 t.Errorf("Correlation time exceeded maximum: %v > %v", processingTime, maxProcessingLatency)
             }
 
-            // Validate error
-            if (err != nil) != (tt.expectedError != nil) {
-                t.Errorf("Expected error: %v, got: %v", tt.expectedError, err)
-            }
-
             // Validate correlations
-            if alerts != nil && len(alerts) != tt.expectedCorrelations {
-                t.Errorf("Expected %d correlations, got %d", tt.expectedCorrelations, len(alerts))
+            if len(alerts) != tt.expectedCorrelations {
+                t.Fatalf("Expected %d correlations, got %d", tt.expectedCorrelations, len(alerts))
+            }
+            for _, alert := range alerts {
+                entities, _ := alert.IntelligenceData["correlated_entities"].([]string)
+                if len(entities) != tt.expectedEntities {
+                    t.Errorf("Expected %d correlated entities, got %v", tt.expectedEntities, entities)
+                }
             }
         })
     }
@@ -252,18 +297,55 @@ func BenchmarkAnalyzer(b *testing.B) {
 
 // Helper functions
 
-// generateTestEvents creates test security events
+// testEventActions are the actions generated events cycle through
+var testEventActions = []string{"login_attempt", "login_failure", "privilege_change", "file_download"}
+
+// testEventConfig shapes generated events. Event i belongs to entity
+// i % Entities, so entities are evenly interleaved, and events are spaced
+// evenly across Spread, ending at Base.
+type testEventConfig struct {
+    Count    int
+    Entities int
+    Spread   time.Duration
+    Base     time.Time
+}
+
+// generateTestEvents creates test security events for ten entities spread over a minute
 func generateTestEvents(count int) []*silver.SilverEvent {
-    events := make([]*silver.SilverEvent, count)
-    for i := 0; i < count; i++ {
+    return generateEntityEvents(testEventConfig{
+        Count:    count,
+        Entities: 10,
+        Spread:   time.Minute,
+    })
+}
+
+// generateEntityEvents creates chronologically ordered events whose
+// source_ip, user_id, action and timestamp vary by entity and position
+func generateEntityEvents(config testEventConfig) []*silver.SilverEvent {
+    if config.Entities <= 0 {
+        config.Entities = 1
+    }
+    if config.Base.IsZero() {
+        config.Base = time.Now().UTC()
+    }
+    start := config.Base.Add(-config.Spread)
+    var interval time.Duration
+    if config.Count > 0 {
+        interval = config.Spread / time.Duration(config.Count)
+    }
+
+    events := make([]*silver.SilverEvent, config.Count)
+    for i := 0; i < config.Count; i++ {
+        entity := i % config.Entities
         events[i] = &silver.SilverEvent{
             EventID: fmt.Sprintf("test-event-%d", i),
             ClientID: "test-client",
             EventType: "security_alert",
-            EventTime: time.Now().UTC(),
+            EventTime: start.Add(time.Duration(i) * interval),
             NormalizedData: map[string]interface{}{
-                "source_ip": "192.168.1.1",
-                "action": "login_attempt",
+                "source_ip": fmt.Sprintf("10.0.%d.%d", entity/250, entity%250+1),
+                "user_id": fmt.Sprintf("user-%d", entity),
+                "action": testEventActions[(i/config.Entities)%len(testEventActions)],
                 "severity": "high",
             },
             SecurityContext: silver.SecurityContext{
@@ -276,28 +358,1508 @@ func generateTestEvents(count int) []*silver.SilverEvent {
     return events
 }
 
-// validateSecurityControls validates security controls in alerts
-func validateSecurityControls(t *testing.T, alerts []*gold.Alert) {
-    for _, alert := range alerts {
-        // Validate security metadata
-        if alert.SecurityMetadata == nil {
-            t.Error("Missing security metadata in alert")
-            continue
+// entityCorrelationRule raises an alert for a window in which at least one
+// entity has MinEvents events, listing the entities that qualified
+type entityCorrelationRule struct {
+    keyField  string
+    minEvents int
+}
+
+func (r *entityCorrelationRule) Correlate(events []*silver.SilverEvent, secCtx analyzer.SecurityContext) (*gold.Alert, error) {
+    counts := make(map[string]int)
+    for _, event := range events {
+        counts[fmt.Sprint(event.NormalizedData[r.keyField])]++
+    }
+
+    var entities []string
+    for entity, count := range counts {
+        if count >= r.minEvents {
+            entities = append(entities, entity)
         }
+    }
+    if len(entities) == 0 {
+        return nil, nil
+    }
+    return &gold.Alert{
+        Severity: "high",
+        IntelligenceData: map[string]interface{}{
+            "correlated_entities": entities,
+        },
+    }, nil
+}
 
-        // Validate compliance requirements
-        if len(alert.ComplianceTags) == 0 {
-            t.Error("Missing compliance tags in alert")
+func (r *entityCorrelationRule) Validate() error {
+    return nil
+}
+
+// TestAlertSuppression tests root-cause suppression of repeated alerts
+func TestAlertSuppression(t *testing.T) {
+    suppressor := analyzer.NewAlertSuppressor(analyzer.SuppressionConfig{
+        Enabled:  true,
+        Cooldown: time.Minute,
+    })
+
+    now := time.Now()
+    newAlert := func() *gold.Alert {
+        return &gold.Alert{
+            Severity: "high",
+            IntelligenceData: map[string]interface{}{
+                "correlation_key": "host-42",
+            },
         }
+    }
 
-        // Validate encryption of sensitive fields
-        if len(alert.EncryptedFields) == 0 {
-            t.Error("No encrypted fields found in alert")
+    first := newAlert()
+    first.AlertID = "first"
+    admitted, _ := suppressor.Admit("brute_force", first, 10, now)
+    if !admitted {
+        t.Fatal("Expected first alert to be admitted")
+    }
+
+    admitted, stormStarted := suppressor.Admit("brute_force", newAlert(), 5, now.Add(10*time.Second))
+    if admitted {
+        t.Error("Expected duplicate alert within cooldown to be suppressed")
+    }
+    if !stormStarted {
+        t.Error("Expected first suppression to start a storm")
+    }
+
+    admitted, stormStarted = suppressor.Admit("brute_force", newAlert(), 5, now.Add(50*time.Second))
+    if admitted || stormStarted {
+        t.Error("Expected ongoing storm to keep suppressing without a new storm")
+    }
+
+    // The emitted alert is never modified by later suppressions
+    if count := first.IntelligenceData["event_count"]; count != 10 {
+        t.Errorf("Expected admitted alert event count to stay 10, got %v", count)
+    }
+
+    // A different root cause is not suppressed
+    other := newAlert()
+    other.IntelligenceData["correlation_key"] = "host-7"
+    if admitted, _ := suppressor.Admit("brute_force", other, 1, now.Add(30*time.Second)); !admitted {
+        t.Error("Expected alert for a different correlation key to be admitted")
+    }
+    if summaries := suppressor.Expire(now.Add(55 * time.Second)); len(summaries) != 0 {
+        t.Errorf("Expected no summaries within the cooldown, got %d", len(summaries))
+    }
+
+    // The cooldown runs from the admitted alert, not the last suppression
+    summaries := suppressor.Expire(now.Add(70 * time.Second))
+    if len(summaries) != 1 {
+        t.Fatalf("Expected one summary for the ended storm, got %d", len(summaries))
+    }
+    summary := summaries[0].IntelligenceData
+    if summary["record_type"] != "suppression_summary" || summary["suppressed_alert_id"] != "first" {
+        t.Errorf("Expected a summary of the first alert, got %v", summary)
+    }
+    if summary["event_count"] != 20 || summary["suppressed_count"] != 2 {
+        t.Errorf("Expected 20 events over 2 suppressed alerts, got %v", summary)
+    }
+    if admitted, _ := suppressor.Admit("brute_force", newAlert(), 1, now.Add(70*time.Second)); !admitted {
+        t.Error("Expected alert to be admitted after cooldown")
+    }
+
+    // Storms that suppressed nothing end without a summary
+    if summaries := suppressor.Expire(now.Add(5 * time.Minute)); len(summaries) != 0 {
+        t.Errorf("Expected no summaries for storms without suppressions, got %d", len(summaries))
+    }
+}
+
+// TestAlertSuppressionEntityKey tests that alerts without a correlation key
+// are suppressed per entity rather than per rule
+func TestAlertSuppressionEntityKey(t *testing.T) {
+    suppressor := analyzer.NewAlertSuppressor(analyzer.SuppressionConfig{
+        Enabled:  true,
+        Cooldown: time.Minute,
+    })
+
+    now := time.Now()
+    newAlert := func(sourceIP, userID string) *gold.Alert {
+        return &gold.Alert{
+            Severity: "high",
+            IntelligenceData: map[string]interface{}{
+                "source_ip": sourceIP,
+                "user_id":   userID,
+            },
         }
+    }
 
-        // Validate severity calculation
-        if alert.Severity == "" {
-            t.Error("Missing severity in alert")
+    if admitted, _ := suppressor.Admit("brute_force", newAlert("10.0.0.1", "alice"), 1, now); !admitted {
+        t.Fatal("Expected first alert to be admitted")
+    }
+    if admitted, _ := suppressor.Admit("brute_force", newAlert("10.0.0.2", "alice"), 1, now); !admitted {
+        t.Error("Expected alert for a different source IP to be admitted")
+    }
+    if admitted, _ := suppressor.Admit("brute_force", newAlert("10.0.0.1", "bob"), 1, now); !admitted {
+        t.Error("Expected alert for a different user to be admitted")
+    }
+    if admitted, _ := suppressor.Admit("brute_force", newAlert("10.0.0.1", "alice"), 1, now); admitted {
+        t.Error("Expected repeat alert for the same entity to be suppressed")
+    }
+}
+
+// countingCorrelationRule counts Correlate calls and raises a new alert on
+// every call when correlates is set
+type countingCorrelationRule struct {
+    mu         sync.Mutex
+    calls      int
+    correlates bool
+}
+
+func (r *countingCorrelationRule) Correlate(events []*silver.SilverEvent, secCtx analyzer.SecurityContext) (*gold.Alert, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.calls++
+    if !r.correlates {
+        return nil, nil
+    }
+    return &gold.Alert{
+        Severity:         "high",
+        IntelligenceData: map[string]interface{}{"evaluation": r.calls},
+    }, nil
+}
+
+func (r *countingCorrelationRule) Validate() error {
+    return nil
+}
+
+func TestCorrelationResultCache(t *testing.T) {
+    correlator, err := analyzer.NewEventCorrelator(5*time.Minute, analyzer.SecurityContext{ClientID: "test-client"})
+    if err != nil {
+        t.Fatalf("Failed to create correlator: %v", err)
+    }
+    correlator.SetResultCache(analyzer.CorrelationCacheConfig{Enabled: true, TTL: time.Minute})
+
+    rule := &countingCorrelationRule{}
+    if err := correlator.RegisterRule("counting", rule); err != nil {
+        t.Fatalf("Failed to register rule: %v", err)
+    }
+
+    ctx := context.Background()
+    events := generateTestEvents(5)
+    if _, err := correlator.CorrelateEvents(ctx, events); err != nil {
+        t.Fatalf("Correlation failed: %v", err)
+    }
+    if rule.calls != 1 {
+        t.Fatalf("Expected 1 rule evaluation, got %d", rule.calls)
+    }
+
+    // The same event set is served from the cache
+    if _, err := correlator.CorrelateEvents(ctx, events); err != nil {
+        t.Fatalf("Correlation failed: %v", err)
+    }
+    if rule.calls != 1 {
+        t.Errorf("Expected cached result to be reused, got %d evaluations", rule.calls)
+    }
+
+    // A different event set is evaluated
+    if _, err := correlator.CorrelateEvents(ctx, generateTestEvents(6)); err != nil {
+        t.Fatalf("Correlation failed: %v", err)
+    }
+    if rule.calls != 2 {
+        t.Errorf("Expected changed event set to be evaluated, got %d evaluations", rule.calls)
+    }
+}
+
+// TestCorrelationResultCacheSkipsAlerts tests that event sets which raised an
+// alert are never served from the cache, so an emitted alert is not returned
+// again
+func TestCorrelationResultCacheSkipsAlerts(t *testing.T) {
+    correlator, err := analyzer.NewEventCorrelator(5*time.Minute, analyzer.SecurityContext{ClientID: "test-client"})
+    if err != nil {
+        t.Fatalf("Failed to create correlator: %v", err)
+    }
+    correlator.SetResultCache(analyzer.CorrelationCacheConfig{Enabled: true, TTL: time.Minute})
+
+    rule := &countingCorrelationRule{correlates: true}
+    if err := correlator.RegisterRule("alerting", rule); err != nil {
+        t.Fatalf("Failed to register rule: %v", err)
+    }
+
+    ctx := context.Background()
+    events := generateTestEvents(5)
+    first, err := correlator.CorrelateEvents(ctx, events)
+    if err != nil || len(first) != 1 {
+        t.Fatalf("Expected 1 alert, got %d (err %v)", len(first), err)
+    }
+    second, err := correlator.CorrelateEvents(ctx, events)
+    if err != nil || len(second) != 1 {
+        t.Fatalf("Expected 1 alert on re-evaluation, got %d (err %v)", len(second), err)
+    }
+
+    if rule.calls != 2 {
+        t.Errorf("Expected an alerting event set to be evaluated again, got %d evaluations", rule.calls)
+    }
+    if first[0] == second[0] {
+        t.Error("Expected a new alert rather than the one already emitted")
+    }
+}
+
+type groupRecordingRule struct {
+    mu     sync.Mutex
+    groups []int
+}
+
+func (r *groupRecordingRule) Correlate(events []*silver.SilverEvent, secCtx analyzer.SecurityContext) (*gold.Alert, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.groups = append(r.groups, len(events))
+    return nil, nil
+}
+
+func (r *groupRecordingRule) Validate() error {
+    return nil
+}
+
+// TestCorrelationFanOutLimits tests group size and candidate group limits
+func TestCorrelationFanOutLimits(t *testing.T) {
+    // Three one-minute windows holding 2, 3 and 6 events
+    base := time.Now().UTC()
+    var events []*silver.SilverEvent
+    for window, size := range []int{2, 3, 6} {
+        for _, event := range generateTestEvents(size) {
+            event.EventID = fmt.Sprintf("window-%d-%s", window, event.EventID)
+            event.EventTime = base.Add(time.Duration(window) * 10 * time.Minute)
+            events = append(events, event)
+        }
+    }
+
+    tests := []struct {
+        name        string
+        policy      string
+        totalEvents int
+        groups      int
+    }{
+        // Oldest window dropped, largest window capped at 4
+        {name: "truncate", policy: analyzer.FanOutPolicyTruncate, totalEvents: 7, groups: 2},
+        // Oldest two windows merged to 5 then capped at 4
+        {name: "merge", policy: analyzer.FanOutPolicyMerge, totalEvents: 8, groups: 2},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            correlator, err := analyzer.NewEventCorrelator(time.Minute, analyzer.SecurityContext{ClientID: "test-client"})
+            if err != nil {
+                t.Fatalf("Failed to create correlator: %v", err)
+            }
+            if err := correlator.SetFanOutLimits(analyzer.FanOutConfig{
+                MaxGroupSize:       4,
+                MaxCandidateGroups: 2,
+                Policy:             tt.policy,
+            }); err != nil {
+                t.Fatalf("Failed to set fan-out limits: %v", err)
+            }
+
+            rule := &groupRecordingRule{}
+            if err := correlator.RegisterRule("recording", rule); err != nil {
+                t.Fatalf("Failed to register rule: %v", err)
+            }
+            if _, err := correlator.CorrelateEvents(context.Background(), events); err != nil {
+                t.Fatalf("Correlation failed: %v", err)
+            }
+
+            total := 0
+            for _, size := range rule.groups {
+                if size > 4 {
+                    t.Errorf("Group of %d events exceeds the maximum group size", size)
+                }
+                total += size
+            }
+            if len(rule.groups) != tt.groups {
+                t.Errorf("Expected %d candidate groups, got %d", tt.groups, len(rule.groups))
+            }
+            if total != tt.totalEvents {
+                t.Errorf("Expected %d correlated events, got %d", tt.totalEvents, total)
+            }
+        })
+    }
+
+    correlator, err := analyzer.NewEventCorrelator(time.Minute, analyzer.SecurityContext{ClientID: "test-client"})
+    if err != nil {
+        t.Fatalf("Failed to create correlator: %v", err)
+    }
+    if err := correlator.SetFanOutLimits(analyzer.FanOutConfig{Policy: "drop-all"}); err == nil {
+        t.Error("Expected unsupported overflow policy to be rejected")
+    }
+}
+
+// TestThresholdRule tests windowed count detection per key
+func TestThresholdRule(t *testing.T) {
+    rule, err := analyzer.NewThresholdRule(analyzer.ThresholdRuleConfig{
+        Name:      "failed_login_burst",
+        EventType: "auth.failed",
+        KeyField:  "user",
+        Window:    time.Minute,
+        Threshold: 100,
+        Severity:  0.8,
+    })
+    if err != nil {
+        t.Fatalf("Failed to create threshold rule: %v", err)
+    }
+
+    base := time.Now().Add(-time.Hour)
+    newEvent := func(user string, offset time.Duration) *silver.SilverEvent {
+        return &silver.SilverEvent{
+            EventType:      "auth.failed",
+            EventTime:      base.Add(offset),
+            NormalizedData: map[string]interface{}{"user": user},
+        }
+    }
+
+    fired := 0
+    for i := 0; i < 150; i++ {
+        detected, severity, metadata := rule.Detect(newEvent("alice", time.Duration(i)*100*time.Millisecond))
+        if detected {
+            fired++
+            if severity != 0.8 {
+                t.Errorf("Expected severity 0.8, got %v", severity)
+            }
+            if metadata["threshold_value"] != "alice" {
+                t.Errorf("Expected threshold key alice, got %v", metadata["threshold_value"])
+            }
+        }
+    }
+    if fired != 1 {
+        t.Errorf("Expected rule to fire once per crossing, fired %d times", fired)
+    }
+
+    // Counts are tracked independently per key
+    if detected, _, _ := rule.Detect(newEvent("bob", 15*time.Second)); detected {
+        t.Error("Expected single event for a different key not to fire")
+    }
+
+    // Events outside the window are not counted
+    if detected, _, _ := rule.Detect(newEvent("alice", 5*time.Minute)); detected {
+        t.Error("Expected rule not to fire after the window elapsed")
+    }
+}
+
+// recordingSink captures alerts published to a single destination
+type recordingSink struct {
+    published [][]byte
+    down      bool
+}
+
+func (s *recordingSink) Publish(ctx context.Context, event []byte) error {
+    if s.down {
+        return errors.NewError("E4001", "sink unavailable", nil)
+    }
+    s.published = append(s.published, event)
+    return nil
+}
+
+func TestAlertRouting(t *testing.T) {
+    sinks := make(map[string]*recordingSink)
+    router, err := analyzer.NewAlertRouter(analyzer.AlertRoutingConfig{
+        Routes: map[string]string{
+            "client-a": "alerts.client-a",
+            "client-b": "alerts.client-b",
+        },
+        DefaultDestination: "alerts.shared",
+    }, func(destination string) (analyzer.AlertSink, error) {
+        sink := &recordingSink{}
+        sinks[destination] = sink
+        return sink, nil
+    })
+    if err != nil {
+        t.Fatalf("Failed to create alert router: %v", err)
+    }
+
+    ctx := context.Background()
+    for _, clientID := range []string{"client-a", "client-b", "client-b", "client-c"} {
+        if err := router.Route(ctx, clientID, &gold.Alert{AlertID: clientID, Severity: "high"}); err != nil {
+            t.Fatalf("Failed to route alert for %s: %v", clientID, err)
+        }
+    }
+
+    expected := map[string]int{"alerts.client-a": 1, "alerts.client-b": 2, "alerts.shared": 1}
+    for destination, count := range expected {
+        if got := len(sinks[destination].published); got != count {
+            t.Errorf("Expected %d alerts on %s, got %d", count, destination, got)
+        }
+    }
+
+    // Without a default route, alerts for unconfigured clients are rejected
+    isolated, err := analyzer.NewAlertRouter(analyzer.AlertRoutingConfig{
+        Routes: map[string]string{"client-a": "alerts.client-a"},
+    }, func(destination string) (analyzer.AlertSink, error) {
+        return &recordingSink{}, nil
+    })
+    if err != nil {
+        t.Fatalf("Failed to create alert router: %v", err)
+    }
+    if err := isolated.Route(ctx, "client-c", &gold.Alert{AlertID: "c"}); err == nil {
+        t.Error("Expected alert for unrouted client to be rejected")
+    }
+}
+
+// staticAlertHistory returns a fixed set of stored alerts
+type staticAlertHistory []analyzer.StoredAlert
+
+func (h staticAlertHistory) AlertsBetween(ctx context.Context, from, to time.Time) ([]analyzer.StoredAlert, error) {
+    return append([]analyzer.StoredAlert(nil), h...), nil
+}
+
+// TestReplayNotifications tests redelivery of alerts missed during a sink outage
+func TestReplayNotifications(t *testing.T) {
+    sinks := make(map[string]*recordingSink)
+    router, err := analyzer.NewAlertRouter(analyzer.AlertRoutingConfig{
+        Routes: map[string]string{
+            "client-a": "pagerduty.client-a",
+            "client-b": "pagerduty.client-b",
+        },
+    }, func(destination string) (analyzer.AlertSink, error) {
+        sink := &recordingSink{}
+        sinks[destination] = sink
+        return sink, nil
+    })
+    if err != nil {
+        t.Fatalf("Failed to create alert router: %v", err)
+    }
+
+    now := time.Now()
+    missed := &gold.Alert{AlertID: "a-1", CreatedAt: now.Add(-time.Hour)}
+    delivered := &gold.Alert{AlertID: "b-1", CreatedAt: now.Add(-time.Hour)}
+
+    ctx := context.Background()
+    sinks["pagerduty.client-a"].down = true
+    if err := router.Route(ctx, "client-a", missed); err == nil {
+        t.Fatal("Expected delivery to a down sink to fail")
+    }
+    if err := router.Route(ctx, "client-b", delivered); err != nil {
+        t.Fatalf("Failed to route alert: %v", err)
+    }
+
+    router.SetAlertHistory(staticAlertHistory{
+        {ClientID: "client-a", Alert: missed},
+        {ClientID: "client-b", Alert: delivered},
+    })
+    sinks["pagerduty.client-a"].down = false
+
+    result, err := router.ReplayNotifications(ctx, now.Add(-2*time.Hour), now, "")
+    if err != nil {
+        t.Fatalf("Replay failed: %v", err)
+    }
+    if result.Redelivered != 1 || result.Skipped != 1 {
+        t.Errorf("Expected 1 redelivered and 1 skipped, got %d and %d", result.Redelivered, result.Skipped)
+    }
+    if got := len(sinks["pagerduty.client-b"].published); got != 1 {
+        t.Errorf("Expected already-delivered alert not to be re-sent, got %d deliveries", got)
+    }
+
+    // A replay restricted to one destination ignores other clients and dedups
+    result, err = router.ReplayNotifications(ctx, now.Add(-2*time.Hour), now, "pagerduty.client-a")
+    if err != nil {
+        t.Fatalf("Replay failed: %v", err)
+    }
+    if result.Considered != 1 || result.Redelivered != 0 {
+        t.Errorf("Expected only client-a's alert considered and nothing redelivered, got %+v", result)
+    }
+}
+
+// sharedAlertHistory returns its backing slice so replays that reorder or
+// filter in place would corrupt it
+type sharedAlertHistory struct {
+    alerts []analyzer.StoredAlert
+}
+
+func (h *sharedAlertHistory) AlertsBetween(ctx context.Context, from, to time.Time) ([]analyzer.StoredAlert, error) {
+    return h.alerts, nil
+}
+
+// TestReplayLeavesHistoryIntact verifies replay filters into its own slice
+func TestReplayLeavesHistoryIntact(t *testing.T) {
+    router, err := analyzer.NewAlertRouter(analyzer.AlertRoutingConfig{
+        DefaultDestination: "alerts.shared",
+    }, func(destination string) (analyzer.AlertSink, error) {
+        return &recordingSink{}, nil
+    })
+    if err != nil {
+        t.Fatalf("Failed to create alert router: %v", err)
+    }
+
+    now := time.Now()
+    later := &gold.Alert{AlertID: "later", CreatedAt: now.Add(-time.Minute)}
+    earlier := &gold.Alert{AlertID: "earlier", CreatedAt: now.Add(-time.Hour)}
+    history := &sharedAlertHistory{alerts: []analyzer.StoredAlert{
+        {ClientID: "client-a", Alert: nil},
+        {ClientID: "client-a", Alert: later},
+        {ClientID: "client-a", Alert: earlier},
+    }}
+    router.SetAlertHistory(history)
+
+    result, err := router.ReplayNotifications(context.Background(), now.Add(-2*time.Hour), now, "")
+    if err != nil {
+        t.Fatalf("Replay failed: %v", err)
+    }
+    if result.Redelivered != 2 {
+        t.Errorf("Expected 2 alerts redelivered, got %d", result.Redelivered)
+    }
+    if history.alerts[0].Alert != nil || history.alerts[1].Alert != later || history.alerts[2].Alert != earlier {
+        t.Error("Expected replay to leave the history's alerts unchanged")
+    }
+}
+
+// TestReplayFromRedisAlertHistory verifies alerts recorded by one replica
+// while a sink was down can be replayed by another
+func TestReplayFromRedisAlertHistory(t *testing.T) {
+    server := miniredis.RunT(t)
+    newReplica := func(sinks map[string]*recordingSink) *analyzer.AlertRouter {
+        client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+        t.Cleanup(func() { client.Close() })
+
+        router, err := analyzer.NewAlertRouter(analyzer.AlertRoutingConfig{
+            Routes: map[string]string{"client-a": "pagerduty.client-a"},
+        }, func(destination string) (analyzer.AlertSink, error) {
+            sink := &recordingSink{}
+            sinks[destination] = sink
+            return sink, nil
+        })
+        if err != nil {
+            t.Fatalf("Failed to create alert router: %v", err)
         }
+        router.SetAlertHistory(analyzer.NewRedisAlertHistory(client, "", 0))
+        return router
+    }
+
+    ctx := context.Background()
+    now := time.Now()
+    missed := &gold.Alert{AlertID: "a-1", Severity: "high", CreatedAt: now.Add(-time.Hour)}
+    stale := &gold.Alert{AlertID: "a-0", CreatedAt: now.Add(-3 * time.Hour)}
+
+    firstSinks := make(map[string]*recordingSink)
+    first := newReplica(firstSinks)
+    firstSinks["pagerduty.client-a"].down = true
+    for _, alert := range []*gold.Alert{missed, stale} {
+        if err := first.Route(ctx, "client-a", alert); err == nil {
+            t.Fatal("Expected delivery to a down sink to fail")
+        }
+    }
+
+    // A restarted replica replays from the shared history
+    secondSinks := make(map[string]*recordingSink)
+    second := newReplica(secondSinks)
+    result, err := second.ReplayNotifications(ctx, now.Add(-2*time.Hour), now, "pagerduty.client-a")
+    if err != nil {
+        t.Fatalf("Replay failed: %v", err)
+    }
+    if result.Considered != 1 || result.Redelivered != 1 {
+        t.Fatalf("Expected only the alert inside the range redelivered, got %+v", result)
+    }
+
+    published := secondSinks["pagerduty.client-a"].published
+    if len(published) != 1 {
+        t.Fatalf("Expected 1 redelivered alert, got %d", len(published))
+    }
+    var replayed gold.Alert
+    if err := json.Unmarshal(published[0], &replayed); err != nil {
+        t.Fatalf("Failed to decode replayed alert: %v", err)
+    }
+    if replayed.AlertID != missed.AlertID || replayed.Severity != "high" {
+        t.Errorf("Expected alert %s to be replayed intact, got %+v", missed.AlertID, replayed)
+    }
+}
+
+// countingRule counts evaluations without ever detecting a threat
+type countingRule struct {
+    mu        sync.Mutex
+    evaluated int
+}
+
+func (r *countingRule) Detect(event *silver.SilverEvent) (bool, float64, map[string]interface{}) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.evaluated++
+    return false, 0, nil
+}
+
+func TestRuleSampling(t *testing.T) {
+    cheap, expensive := &countingRule{}, &countingRule{}
+    if err := analyzer.RegisterDetectionRule("sampling_cheap", cheap); err != nil {
+        t.Fatalf("Failed to register rule: %v", err)
+    }
+    if err := analyzer.RegisterDetectionRule("sampling_expensive", expensive); err != nil {
+        t.Fatalf("Failed to register rule: %v", err)
+    }
+    defer analyzer.UnregisterDetectionRule("sampling_cheap")
+    defer analyzer.UnregisterDetectionRule("sampling_expensive")
+
+    if err := analyzer.SetRuleSampleRate("sampling_expensive", 0.25); err != nil {
+        t.Fatalf("Failed to set sample rate: %v", err)
+    }
+    if err := analyzer.SetRuleSampleRate("sampling_expensive", 1.5); err == nil {
+        t.Error("Expected sample rate above 1 to be rejected")
+    }
+
+    ctx := context.Background()
+    events := generateTestEvents(testDataSize)
+    for _, event := range events {
+        // Rules registered by other tests may still fire; only evaluation counts matter here
+        analyzer.DetectThreats(ctx, event)
+    }
+
+    if cheap.evaluated != testDataSize {
+        t.Errorf("Expected unsampled rule to evaluate all %d events, got %d", testDataSize, cheap.evaluated)
+    }
+    if expensive.evaluated < testDataSize/8 || expensive.evaluated > testDataSize/2 {
+        t.Errorf("Expected sampled rule to evaluate roughly a quarter of events, got %d", expensive.evaluated)
+    }
+
+    // Sampling decisions are stable for the same event
+    before := expensive.evaluated
+    for i := 0; i < 10; i++ {
+        analyzer.DetectThreats(ctx, events[0])
+    }
+    if delta := expensive.evaluated - before; delta != 0 && delta != 10 {
+        t.Errorf("Expected a consistent sampling decision per event, got %d of 10 evaluations", delta)
+    }
+}
+
+// memoryArchive serves archived Silver events from memory and records the
+// prefixes it was asked to list
+type memoryArchive struct {
+    objects  map[string][]byte
+    prefixes []string
+}
+
+func (a *memoryArchive) ListObjects(bucket, prefix string) ([]string, error) {
+    a.prefixes = append(a.prefixes, prefix)
+    keys := make([]string, 0, len(a.objects))
+    for key := range a.objects {
+        if strings.HasPrefix(key, prefix) {
+            keys = append(keys, key)
+        }
+    }
+    return keys, nil
+}
+
+func (a *memoryArchive) GetObject(bucket, key string) ([]byte, error) {
+    return a.objects[key], nil
+}
+
+type matchAllRule struct{}
+
+func (matchAllRule) Detect(event *silver.SilverEvent) (bool, float64, map[string]interface{}) {
+    return true, 0.7, nil
+}
+
+func TestEvaluateRuleHistorical(t *testing.T) {
+    from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    to := from.Add(48 * time.Hour)
+
+    archive := &memoryArchive{objects: make(map[string][]byte)}
+    for i, event := range generateTestEvents(6) {
+        // Four events fall inside the range, two in the day partition after it
+        event.EventTime = from.Add(time.Duration(i) * 12 * time.Hour)
+        data, err := json.Marshal(event)
+        if err != nil {
+            t.Fatalf("Failed to encode event: %v", err)
+        }
+        key := fmt.Sprintf("silver/%s/%d.json", event.EventTime.Format("2006/01/02"), i)
+        archive.objects[key] = data
+    }
+    archive.objects["silver/2024/01/01/corrupt.json"] = []byte("{not json")
+
+    if err := analyzer.SetHistoricalArchive(archive, "archive-bucket", ""); err != nil {
+        t.Fatalf("Failed to configure archive: %v", err)
+    }
+    if err := analyzer.RegisterDetectionRule("historical_match_all", matchAllRule{}); err != nil {
+        t.Fatalf("Failed to register rule: %v", err)
+    }
+    defer analyzer.UnregisterDetectionRule("historical_match_all")
+
+    result, err := analyzer.EvaluateRuleHistorical(context.Background(), "historical_match_all", from, to)
+    if err != nil {
+        t.Fatalf("Historical evaluation failed: %v", err)
+    }
+
+    if result.EventsScanned != 4 || result.Matches != 4 {
+        t.Errorf("Expected 4 scanned and matched events, got %d scanned, %d matched", result.EventsScanned, result.Matches)
+    }
+    if result.DecodeErrors != 1 {
+        t.Errorf("Expected 1 decode error, got %d", result.DecodeErrors)
+    }
+    if result.EstimatedAlertsPerDay != 2 {
+        t.Errorf("Expected 2 estimated alerts per day, got %v", result.EstimatedAlertsPerDay)
+    }
+    if result.MatchesByThreatLevel["high"] != 4 {
+        t.Errorf("Expected matches at high threat level, got %v", result.MatchesByThreatLevel)
+    }
+    if len(result.SampleMatches) != 4 {
+        t.Errorf("Expected 4 sample matches, got %d", len(result.SampleMatches))
+    }
+
+    // Only the day partitions overlapping [from, to) are listed
+    expectedPrefixes := []string{"silver/2024/01/01/", "silver/2024/01/02/"}
+    if !reflect.DeepEqual(archive.prefixes, expectedPrefixes) {
+        t.Errorf("Expected listed prefixes %v, got %v", expectedPrefixes, archive.prefixes)
+    }
+
+    if _, err := analyzer.EvaluateRuleHistorical(context.Background(), "missing_rule", from, to); err == nil {
+        t.Error("Expected unknown rule to be rejected")
+    }
+}
+
+// validateSecurityControls validates security controls in alerts
+func validateSecurityControls(t *testing.T, alerts []*gold.Alert) {
+    for _, alert := range alerts {
+        // Validate security metadata
+        if alert.SecurityMetadata == nil {
+            t.Error("Missing security metadata in alert")
+            continue
+        }
+
+        // Validate compliance requirements
+        if len(alert.ComplianceTags) == 0 {
+            t.Error("Missing compliance tags in alert")
+        }
+
+        // Validate encryption of sensitive fields
+        if len(alert.EncryptedFields) == 0 {
+            t.Error("No encrypted fields found in alert")
+        }
+
+        // Validate severity calculation
+        if alert.Severity == "" {
+            t.Error("Missing severity in alert")
+        }
+    }
+}
+
+// TestRuleRegistryReload tests atomic replacement and rejection of rule sets
+func TestRuleRegistryReload(t *testing.T) {
+    registry := analyzer.NewRuleRegistry()
+
+    valid := []analyzer.RuleDefinition{
+        {
+            ID:   "brute_force",
+            Type: "threshold",
+            Params: map[string]interface{}{
+                "event_type": "auth_failure",
+                "key_field":  "user",
+                "window":     "5m",
+                "threshold":  10,
+            },
+        },
+        {
+            ID:         "port_scan",
+            Type:       "threshold",
+            SampleRate: 0.5,
+            Params: map[string]interface{}{
+                "key_field": "src_ip",
+                "window":    "1m",
+                "threshold": 100,
+            },
+        },
+    }
+    if err := registry.Load(valid); err != nil {
+        t.Fatalf("Failed to load valid rule set: %v", err)
+    }
+    if registry.Len() != 2 {
+        t.Fatalf("Expected 2 rules loaded, got %d", registry.Len())
+    }
+
+    // One rule with a bad window rejects the whole set
+    invalid := []analyzer.RuleDefinition{
+        valid[0],
+        {
+            ID:     "broken",
+            Type:   "threshold",
+            Params: map[string]interface{}{"key_field": "user", "window": "soon", "threshold": 5},
+        },
+    }
+    if err := registry.Load(invalid); err == nil {
+        t.Fatal("Expected rule set with an invalid rule to be rejected")
+    }
+    if registry.Len() != 2 {
+        t.Errorf("Expected previous rule set to stay active, got %d rules", registry.Len())
+    }
+
+    unknown := []analyzer.RuleDefinition{{ID: "mystery", Type: "no_such_type"}}
+    if err := registry.Load(unknown); err == nil {
+        t.Error("Expected unknown rule type to be rejected")
+    }
+
+    t.Run("LoadFile", func(t *testing.T) {
+        path := filepath.Join(t.TempDir(), "rules.yaml")
+        content := `
+rules:
+  - id: impossible_travel
+    type: threshold
+    params:
+      event_type: login
+      key_field: user
+      window: 10m
+      threshold: 3
+`
+        if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+            t.Fatalf("Failed to write rule file: %v", err)
+        }
+        if err := registry.LoadFile(path); err != nil {
+            t.Fatalf("Failed to load rule file: %v", err)
+        }
+        if registry.Len() != 1 {
+            t.Errorf("Expected reload to replace the full set, got %d rules", registry.Len())
+        }
+    })
+}
+
+// TestRuleReloadDuringDetection tests that detection runs safely while the rule set is replaced
+func TestRuleReloadDuringDetection(t *testing.T) {
+    registry := analyzer.Rules()
+    registry.RegisterCompiler("counting", func(def analyzer.RuleDefinition) (analyzer.DetectionRule, error) {
+        return &countingRule{}, nil
+    })
+    defer registry.Unregister("reload_a")
+    defer registry.Unregister("reload_b")
+
+    ctx := context.Background()
+    events := generateTestEvents(100)
+
+    var wg sync.WaitGroup
+    wg.Add(1)
+    go func() {
+        defer wg.Done()
+        for i := 0; i < 50; i++ {
+            registry.Load([]analyzer.RuleDefinition{
+                {ID: "reload_a", Type: "counting"},
+                {ID: "reload_b", Type: "counting"},
+            })
+        }
+    }()
+    for _, event := range events {
+        analyzer.DetectThreats(ctx, event)
+    }
+    wg.Wait()
+
+    if registry.Len() != 2 {
+        t.Errorf("Expected the reloaded set to be active, got %d rules", registry.Len())
+    }
+}
+
+// TestPerRuleCorrelationWindow tests that rules with different windows correlate independently
+func TestPerRuleCorrelationWindow(t *testing.T) {
+    // Six events ten minutes apart
+    base := time.Now().UTC()
+    events := generateTestEvents(6)
+    for i, event := range events {
+        event.EventTime = base.Add(time.Duration(i) * 10 * time.Minute)
+    }
+
+    correlator, err := analyzer.NewEventCorrelator(time.Minute, analyzer.SecurityContext{ClientID: "test-client"})
+    if err != nil {
+        t.Fatalf("Failed to create correlator: %v", err)
+    }
+
+    bruteForce := &groupRecordingRule{}
+    exfiltration := &groupRecordingRule{}
+    if err := correlator.RegisterRule("brute_force", analyzer.WithWindow(bruteForce, 5*time.Minute)); err != nil {
+        t.Fatalf("Failed to register rule: %v", err)
+    }
+    if err := correlator.RegisterRule("exfiltration", analyzer.WithWindow(exfiltration, 2*time.Hour)); err != nil {
+        t.Fatalf("Failed to register rule: %v", err)
+    }
+    if err := correlator.RegisterRule("invalid", analyzer.WithWindow(&groupRecordingRule{}, 0)); err == nil {
+        t.Error("Expected a zero window to be rejected")
+    }
+
+    if _, err := correlator.CorrelateEvents(context.Background(), events); err != nil {
+        t.Fatalf("Correlation failed: %v", err)
+    }
+
+    if len(bruteForce.groups) != 6 {
+        t.Errorf("Expected 6 groups for the 5m window, got %d", len(bruteForce.groups))
+    }
+    if len(exfiltration.groups) != 1 || exfiltration.groups[0] != 6 {
+        t.Errorf("Expected one group of 6 events for the 2h window, got %v", exfiltration.groups)
+    }
+
+    // A full window evicts its oldest events
+    if err := correlator.SetMaxWindowEvents(4); err != nil {
+        t.Fatalf("Failed to set maximum window events: %v", err)
+    }
+    exfiltration.groups = nil
+    if _, err := correlator.CorrelateEvents(context.Background(), events); err != nil {
+        t.Fatalf("Correlation failed: %v", err)
+    }
+    if len(exfiltration.groups) != 1 || exfiltration.groups[0] != 4 {
+        t.Errorf("Expected one group of 4 events after eviction, got %v", exfiltration.groups)
+    }
+}
+
+// TestAlertDeduplication tests first-alert emission, suppression and re-emission after the window
+func TestAlertDeduplication(t *testing.T) {
+    dedup, err := gold.NewAlertDeduplicator(gold.DedupConfig{Window: 10 * time.Minute})
+    if err != nil {
+        t.Fatalf("Failed to create deduplicator: %v", err)
+    }
+
+    newAlert := func(id string, entity string) *gold.Alert {
+        return &gold.Alert{
+            AlertID:  id,
+            Severity: "high",
+            IntelligenceData: map[string]interface{}{
+                "rule_id": "brute_force",
+                "entity":  entity,
+            },
+        }
+    }
+    base := time.Now().UTC()
+
+    first, isNew := dedup.Deduplicate(newAlert("a-1", "alice"), base)
+    if !isNew || first.AlertID != "a-1" || first.Count != 1 {
+        t.Fatalf("Expected the first alert to be emitted with count 1, got new=%v count=%d", isNew, first.Count)
+    }
+
+    // Repeats within the window update a tracked copy of the first alert,
+    // leaving the already published one untouched
+    var latest *gold.Alert
+    for i := 2; i <= 3; i++ {
+        seen := base.Add(time.Duration(i) * time.Minute)
+        alert, isNew := dedup.Deduplicate(newAlert(fmt.Sprintf("a-%d", i), "alice"), seen)
+        if isNew || alert == first || alert.AlertID != "a-1" {
+            t.Fatalf("Expected repeat %d to be suppressed into a copy of the first alert", i)
+        }
+        if !alert.LastSeen.Equal(seen) {
+            t.Errorf("Expected last seen %v, got %v", seen, alert.LastSeen)
+        }
+        latest = alert
+    }
+    if latest.Count != 3 {
+        t.Errorf("Expected count 3, got %d", latest.Count)
+    }
+    if len(latest.AuditTrail) != 2 || latest.AuditTrail[1].Details["duplicate_alert_id"] != "a-3" {
+        t.Errorf("Expected two suppression audit entries, got %+v", latest.AuditTrail)
+    }
+    if first.Count != 1 || len(first.AuditTrail) != 0 || !first.LastSeen.Equal(base) {
+        t.Errorf("Expected the published alert to be unchanged, got count=%d audit=%d", first.Count, len(first.AuditTrail))
+    }
+
+    // A different entity has its own signature
+    bob, isNew := dedup.Deduplicate(newAlert("b-1", "bob"), base.Add(time.Minute))
+    if !isNew {
+        t.Error("Expected an alert for a different entity to be emitted")
+    }
+
+    // Forgetting an alert whose publish failed lets its retry be emitted
+    dedup.Forget(bob)
+    if _, isNew := dedup.Deduplicate(newAlert("b-2", "bob"), base.Add(2*time.Minute)); !isNew {
+        t.Error("Expected a forgotten signature to be emitted again")
+    }
+
+    // Once the window expires the condition alerts again
+    again, isNew := dedup.Deduplicate(newAlert("a-4", "alice"), base.Add(11*time.Minute))
+    if !isNew || again.AlertID != "a-4" || again.Count != 1 {
+        t.Errorf("Expected re-emission after window expiry, got new=%v id=%s", isNew, again.AlertID)
+    }
+
+    // Signature fields are configurable
+    byRule, err := gold.NewAlertDeduplicator(gold.DedupConfig{SignatureFields: []string{"rule_id"}})
+    if err != nil {
+        t.Fatalf("Failed to create deduplicator: %v", err)
+    }
+    byRule.Deduplicate(newAlert("c-1", "alice"), base)
+    if _, isNew := byRule.Deduplicate(newAlert("c-2", "bob"), base); isNew {
+        t.Error("Expected alerts for the same rule to be deduplicated regardless of entity")
+    }
+}
+
+// recordingAlertPublisher keeps the alerts published through it and fails
+// while failing is set
+type recordingAlertPublisher struct {
+    mu      sync.Mutex
+    alerts  []*gold.Alert
+    failing bool
+}
+
+func (r *recordingAlertPublisher) PublishValues(ctx context.Context, values []interface{}, headers []map[string]string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if r.failing {
+        return errors.NewError("E4002", "broker unavailable", nil)
+    }
+    for _, value := range values {
+        r.alerts = append(r.alerts, value.(*gold.Alert))
+    }
+    return nil
+}
+
+// silverMessages encodes events as consumed Silver messages
+func silverMessages(t *testing.T, events []*silver.SilverEvent) []*streaming.Message {
+    messages := make([]*streaming.Message, len(events))
+    for i, event := range events {
+        data, err := json.Marshal(event)
+        if err != nil {
+            t.Fatalf("Failed to encode event: %v", err)
+        }
+        messages[i] = &streaming.Message{Topic: "silver-events", Offset: int64(i), Value: data}
+    }
+    return messages
+}
+
+// TestPipelineDeduplication tests that the analyzer pipeline publishes one
+// alert per rule and entity, leaves published alerts unchanged and treats
+// alerts of a batch whose publish failed as new on retry
+func TestPipelineDeduplication(t *testing.T) {
+    if err := analyzer.RegisterDetectionRule("pipeline_dedup", matchAllRule{}); err != nil {
+        t.Fatalf("Failed to register rule: %v", err)
+    }
+    defer analyzer.UnregisterDetectionRule("pipeline_dedup")
+
+    newPipeline := func(publisher *recordingAlertPublisher) *analyzer.Pipeline {
+        pipeline, err := analyzer.NewPipeline(publisher)
+        if err != nil {
+            t.Fatalf("Failed to create pipeline: %v", err)
+        }
+        dedup, err := gold.NewAlertDeduplicator(gold.DedupConfig{Window: 10 * time.Minute})
+        if err != nil {
+            t.Fatalf("Failed to create deduplicator: %v", err)
+        }
+        pipeline.SetDeduplicator(dedup)
+        return pipeline
+    }
+    ctx := context.Background()
+
+    publisher := &recordingAlertPublisher{}
+    pipeline := newPipeline(publisher)
+
+    // Four events for two entities raise one alert per entity
+    first := generateEntityEvents(testEventConfig{Count: 4, Entities: 2, Spread: time.Minute})
+    if err := pipeline.HandleBatch(ctx, silverMessages(t, first)); err != nil {
+        t.Fatalf("Failed to handle batch: %v", err)
+    }
+    if len(publisher.alerts) != 2 {
+        t.Fatalf("Expected 2 alerts, got %d", len(publisher.alerts))
+    }
+    entities := make(map[interface{}]bool)
+    for _, alert := range publisher.alerts {
+        if alert.IntelligenceData["rule_id"] == nil || alert.IntelligenceData["entity"] == nil {
+            t.Fatalf("Expected rule_id and entity in intelligence data, got %v", alert.IntelligenceData)
+        }
+        entities[alert.IntelligenceData["entity"]] = true
+    }
+    if len(entities) != 2 {
+        t.Errorf("Expected distinct entities for the two alerts, got %v", entities)
+    }
+    auditLengths := make([]int, len(publisher.alerts))
+    for i, alert := range publisher.alerts {
+        auditLengths[i] = len(alert.AuditTrail)
+    }
+
+    // Repeats are suppressed; only the new entity is published
+    second := generateEntityEvents(testEventConfig{Count: 3, Entities: 3, Spread: time.Minute})
+    if err := pipeline.HandleBatch(ctx, silverMessages(t, second)); err != nil {
+        t.Fatalf("Failed to handle batch: %v", err)
+    }
+    if len(publisher.alerts) != 3 {
+        t.Fatalf("Expected only the new entity's alert to be published, got %d alerts", len(publisher.alerts))
+    }
+    for i, alert := range publisher.alerts[:2] {
+        if alert.Count != 1 || len(alert.AuditTrail) != auditLengths[i] {
+            t.Errorf("Expected published alert %s to be unchanged, got count=%d audit=%d",
+                alert.AlertID, alert.Count, len(alert.AuditTrail))
+        }
+    }
+
+    // A failed publish leaves the batch's alerts to be published on retry
+    failing := &recordingAlertPublisher{failing: true}
+    retried := newPipeline(failing)
+    if err := retried.HandleBatch(ctx, silverMessages(t, first)); err == nil {
+        t.Fatal("Expected the publish failure to be returned")
+    }
+    failing.failing = false
+    if err := retried.HandleBatch(ctx, silverMessages(t, first)); err != nil {
+        t.Fatalf("Failed to handle retried batch: %v", err)
+    }
+    if len(failing.alerts) != 2 {
+        t.Errorf("Expected 2 alerts after the retry, got %d", len(failing.alerts))
+    }
+}
+
+// TestRoutedPipeline tests that the pipeline delivers each alert to its
+// event's client destination, holds alerts of unrouted clients without
+// failing the batch and, after a sink failure, routes only the undelivered
+// alerts again
+func TestRoutedPipeline(t *testing.T) {
+    if err := analyzer.RegisterDetectionRule("pipeline_routing", matchAllRule{}); err != nil {
+        t.Fatalf("Failed to register rule: %v", err)
+    }
+    defer analyzer.UnregisterDetectionRule("pipeline_routing")
+
+    sinks := make(map[string]*recordingSink)
+    router, err := analyzer.NewAlertRouter(analyzer.AlertRoutingConfig{
+        Routes: map[string]string{
+            "client-a": "alerts.client-a",
+            "client-b": "alerts.client-b",
+        },
+    }, func(destination string) (analyzer.AlertSink, error) {
+        sink := &recordingSink{}
+        sinks[destination] = sink
+        return sink, nil
+    })
+    if err != nil {
+        t.Fatalf("Failed to create alert router: %v", err)
+    }
+    pipeline, err := analyzer.NewRoutedPipeline(router)
+    if err != nil {
+        t.Fatalf("Failed to create pipeline: %v", err)
+    }
+    dedup, err := gold.NewAlertDeduplicator(gold.DedupConfig{Window: 10 * time.Minute})
+    if err != nil {
+        t.Fatalf("Failed to create deduplicator: %v", err)
+    }
+    pipeline.SetDeduplicator(dedup)
+
+    events := generateEntityEvents(testEventConfig{Count: 4, Entities: 4, Spread: time.Minute})
+    for i, clientID := range []string{"client-a", "client-a", "client-b", "client-c"} {
+        events[i].ClientID = clientID
+    }
+    ctx := context.Background()
+
+    // client-b's sink is down: the batch fails after client-a's alerts are delivered
+    sinks["alerts.client-b"].down = true
+    if err := pipeline.HandleBatch(ctx, silverMessages(t, events)); err == nil {
+        t.Fatal("Expected the sink failure to be returned")
+    }
+
+    sinks["alerts.client-b"].down = false
+    if err := pipeline.HandleBatch(ctx, silverMessages(t, events)); err != nil {
+        t.Fatalf("Failed to handle retried batch: %v", err)
+    }
+    if got := len(sinks["alerts.client-a"].published); got != 2 {
+        t.Errorf("Expected client-a's 2 alerts delivered once, got %d", got)
+    }
+    if got := len(sinks["alerts.client-b"].published); got != 1 {
+        t.Errorf("Expected 1 alert on alerts.client-b, got %d", got)
+    }
+    for destination, sink := range sinks {
+        for _, payload := range sink.published {
+            var alert gold.Alert
+            if err := json.Unmarshal(payload, &alert); err != nil {
+                t.Fatalf("Failed to decode alert on %s: %v", destination, err)
+            }
+            if alert.AlertID == "" {
+                t.Errorf("Expected a complete alert on %s, got %s", destination, payload)
+            }
+        }
+    }
+}
+
+// TestPipelineLedgerAccounting tests that the analyzer pipeline counts
+// events and alerts only once their alerts are delivered, and only once
+// however often a batch is redelivered
+func TestPipelineLedgerAccounting(t *testing.T) {
+    if err := analyzer.RegisterDetectionRule("pipeline_ledger", matchAllRule{}); err != nil {
+        t.Fatalf("Failed to register rule: %v", err)
+    }
+    defer analyzer.UnregisterDetectionRule("pipeline_ledger")
+
+    metrics.ConfigureLedger(metrics.LedgerConfig{})
+    defer metrics.ConfigureLedger(metrics.LedgerConfig{})
+
+    publisher := &recordingAlertPublisher{failing: true}
+    pipeline, err := analyzer.NewPipeline(publisher)
+    if err != nil {
+        t.Fatalf("Failed to create pipeline: %v", err)
+    }
+    ctx := context.Background()
+    messages := silverMessages(t, generateEntityEvents(testEventConfig{Count: 4, Entities: 4, Spread: time.Minute}))
+
+    if err := pipeline.HandleBatch(ctx, messages); err == nil {
+        t.Fatal("Expected the publish failure to be returned")
+    }
+    report := metrics.Ledger().Reconcile()
+    if account := report.Stages[metrics.StageAnalyze]; account.Produced != 0 || report.Alerted != 0 {
+        t.Fatalf("Expected nothing counted before delivery, got produced=%d alerted=%d", account.Produced, report.Alerted)
+    }
+
+    // The retry delivers, and a redelivery of the committed batch is not counted again
+    publisher.failing = false
+    for i := 0; i < 2; i++ {
+        if err := pipeline.HandleBatch(ctx, messages); err != nil {
+            t.Fatalf("Failed to handle batch: %v", err)
+        }
+    }
+
+    report = metrics.Ledger().Reconcile()
+    account := report.Stages[metrics.StageAnalyze]
+    if account.Ingested != 4 || account.Produced != 4 || account.Unaccounted != 0 {
+        t.Errorf("Expected 4 events ingested and produced once, got %+v", account)
+    }
+    if report.Alerted != 4 {
+        t.Errorf("Expected 4 alerts counted once, got %d", report.Alerted)
+    }
+}
+
+// TestAuditChainVerification tests that hash-chained audit trails detect
+// modified, removed, reordered and inserted entries
+func TestAuditChainVerification(t *testing.T) {
+    dedup, err := gold.NewAlertDeduplicator(gold.DedupConfig{})
+    if err != nil {
+        t.Fatalf("Failed to create deduplicator: %v", err)
+    }
+
+    base := time.Now().UTC()
+    var alert *gold.Alert
+    for i := 0; i < 5; i++ {
+        alert, _ = dedup.Deduplicate(&gold.Alert{
+            AlertID:  fmt.Sprintf("a-%d", i),
+            Severity: "high",
+            IntelligenceData: map[string]interface{}{
+                "rule_id": "brute_force",
+                "entity":  "alice",
+            },
+        }, base.Add(time.Duration(i)*time.Second))
+    }
+    if len(alert.AuditTrail) != 4 {
+        t.Fatalf("Expected four audit entries, got %d", len(alert.AuditTrail))
+    }
+
+    if err := gold.VerifyAuditChain(alert.AuditTrail); err != nil {
+        t.Fatalf("Expected valid chain, got %v", err)
+    }
+
+    // The chain survives storage as JSON
+    data, err := json.Marshal(alert.AuditTrail)
+    if err != nil {
+        t.Fatalf("Failed to marshal audit trail: %v", err)
+    }
+    var stored []gold.AuditEntry
+    if err := json.Unmarshal(data, &stored); err != nil {
+        t.Fatalf("Failed to unmarshal audit trail: %v", err)
+    }
+    if err := gold.VerifyAuditChain(stored); err != nil {
+        t.Errorf("Expected chain to verify after a JSON round trip, got %v", err)
+    }
+
+    // Unchained trails predate hash chaining and are accepted
+    legacy := []gold.AuditEntry{{Action: "created", Timestamp: base}}
+    if err := gold.VerifyAuditChain(legacy); err != nil {
+        t.Errorf("Expected unchained trail to be accepted, got %v", err)
+    }
+
+    tampered := map[string]func([]gold.AuditEntry) []gold.AuditEntry{
+        "modified": func(trail []gold.AuditEntry) []gold.AuditEntry {
+            trail[1].Details = map[string]interface{}{"duplicate_alert_id": "a-9"}
+            return trail
+        },
+        "removed": func(trail []gold.AuditEntry) []gold.AuditEntry {
+            return append(trail[:1], trail[2:]...)
+        },
+        "reordered": func(trail []gold.AuditEntry) []gold.AuditEntry {
+            trail[1], trail[2] = trail[2], trail[1]
+            return trail
+        },
+        "inserted": func(trail []gold.AuditEntry) []gold.AuditEntry {
+            forged := gold.AuditEntry{Action: "resolved", Timestamp: base.Add(1500 * time.Millisecond)}
+            return append(trail[:2], append([]gold.AuditEntry{forged}, trail[2:]...)...)
+        },
+        "first removed": func(trail []gold.AuditEntry) []gold.AuditEntry {
+            return trail[1:]
+        },
+    }
+    for name, tamper := range tampered {
+        trail := tamper(append([]gold.AuditEntry(nil), alert.AuditTrail...))
+        err := gold.VerifyAuditChain(trail)
+        if err == nil {
+            t.Errorf("Expected %s audit trail to fail verification", name)
+            continue
+        }
+        if !errors.IsErrorCode(err, "E3002", "") {
+            t.Errorf("Expected data corruption error for %s trail, got %v", name, err)
+        }
+    }
+}
+
+// TestSeverityMapping tests score band boundaries and fixed-severity rule overrides
+func TestSeverityMapping(t *testing.T) {
+    mapper, err := gold.NewSeverityMapper(gold.SeverityMappingConfig{
+        Bands: []gold.SeverityBand{
+            {MinScore: 0.4, Severity: "medium"},
+            {MinScore: 0.9, Severity: "critical"},
+            {MinScore: 0.7, Severity: "high"},
+        },
+        RuleOverrides: map[string]string{
+            "canary_token":  "critical",
+            "port_scan":     "low",
+            "policy_change": "medium",
+        },
+    })
+    if err != nil {
+        t.Fatalf("Failed to create severity mapper: %v", err)
+    }
+
+    scores := []struct {
+        score    float64
+        expected string
+    }{
+        {1.0, "critical"},
+        {0.9, "critical"},
+        {0.8999, "high"},
+        {0.7, "high"},
+        {0.6999, "medium"},
+        {0.4, "medium"},
+        {0.3999, "info"},
+        {0, "info"},
+    }
+    for _, tc := range scores {
+        if severity := mapper.Map(nil, tc.score, "info"); severity != tc.expected {
+            t.Errorf("Expected score %v to map to %s, got %s", tc.score, tc.expected, severity)
+        }
+    }
+
+    // Overrides win over the score, the most severe one across rules
+    if severity := mapper.Map([]string{"port_scan"}, 0.95, "info"); severity != "low" {
+        t.Errorf("Expected port_scan override to fix severity low, got %s", severity)
+    }
+    if severity := mapper.Map([]string{"port_scan", "canary_token", "policy_change"}, 0.1, "info"); severity != "critical" {
+        t.Errorf("Expected most severe override critical, got %s", severity)
+    }
+    if severity := mapper.Map([]string{"brute_force"}, 0.75, "info"); severity != "high" {
+        t.Errorf("Expected rule without override to use bands, got %s", severity)
+    }
+
+    invalid := map[string]gold.SeverityMappingConfig{
+        "unknown severity":   {Bands: []gold.SeverityBand{{MinScore: 0.5, Severity: "urgent"}}},
+        "score out of range": {Bands: []gold.SeverityBand{{MinScore: 1.5, Severity: "high"}}},
+        "duplicate score":    {Bands: []gold.SeverityBand{{MinScore: 0.5, Severity: "high"}, {MinScore: 0.5, Severity: "low"}}},
+        "unknown override":   {RuleOverrides: map[string]string{"port_scan": "severe"}},
+    }
+    for name, config := range invalid {
+        if _, err := gold.NewSeverityMapper(config); !errors.IsErrorCode(err, "E2001", "") {
+            t.Errorf("Expected configuration error for %s, got %v", name, err)
+        }
+    }
+
+    // Alert creation applies the installed mapper
+    gold.SetSeverityMapper(mapper)
+    defer gold.SetSeverityMapper(nil)
+
+    newEvent := func(score float64, ruleID string) *gold.GoldEvent {
+        return &gold.GoldEvent{
+            Severity: "low",
+            IntelligenceData: map[string]interface{}{
+                "triggered_rules": []string{ruleID},
+            },
+            SecurityMetadata: gold.SecurityMetadata{ConfidenceScore: score},
+        }
+    }
+    secCtx := &gold.SecurityMetadata{Classification: "security_alert"}
+
+    alert, err := gold.CreateAlert(newEvent(0.92, "brute_force"), secCtx)
+    if err != nil {
+        t.Fatalf("Failed to create alert: %v", err)
+    }
+    if alert.Severity != "critical" {
+        t.Errorf("Expected mapped severity critical, got %s", alert.Severity)
+    }
+
+    alert, err = gold.CreateAlert(newEvent(0.92, "port_scan"), secCtx)
+    if err != nil {
+        t.Fatalf("Failed to create alert: %v", err)
+    }
+    if alert.Severity != "low" {
+        t.Errorf("Expected overridden severity low, got %s", alert.Severity)
+    }
+}
+
+// TestCreateAlertWithOptions tests that an injected clock and source of
+// randomness make alert creation reproducible
+func TestCreateAlertWithOptions(t *testing.T) {
+    createdAt := time.Date(2024, 1, 20, 10, 0, 0, 0, time.UTC)
+    secCtx := &gold.SecurityMetadata{Classification: "security_alert"}
+
+    create := func(seed int64) *gold.Alert {
+        alert, err := gold.CreateAlertWithOptions(&gold.GoldEvent{
+            Severity:         "high",
+            IntelligenceData: map[string]interface{}{"rule_id": "brute_force"},
+        }, secCtx, gold.AlertOptions{
+            Now:  func() time.Time { return createdAt },
+            Rand: rand.New(rand.NewSource(seed)),
+        })
+        if err != nil {
+            t.Fatalf("Failed to create alert: %v", err)
+        }
+        return alert
+    }
+
+    first, second := create(42), create(42)
+    if first.AlertID != second.AlertID {
+        t.Errorf("Expected the same alert ID from the same seed, got %s and %s", first.AlertID, second.AlertID)
+    }
+    if !first.CreatedAt.Equal(createdAt) || !first.History[0].Timestamp.Equal(createdAt) {
+        t.Errorf("Expected the injected creation time, got %v", first.CreatedAt)
+    }
+    if first.AuditTrail[0].Hash != second.AuditTrail[0].Hash {
+        t.Error("Expected identical audit trails from the same options")
+    }
+    if err := gold.VerifyAuditChain(first.AuditTrail); err != nil {
+        t.Errorf("Expected a valid audit chain: %v", err)
+    }
+
+    if other := create(7); other.AlertID == first.AlertID {
+        t.Error("Expected a different alert ID from a different seed")
+    }
+}
+
+// fixedScorer returns the same anomaly score and features for every event
+type fixedScorer struct {
+    score    float64
+    features map[string]interface{}
+}
+
+func (s *fixedScorer) Score(event *silver.SilverEvent) (float64, map[string]interface{}) {
+    return s.score, s.features
+}
+
+// TestAnomalyScorer tests threshold-triggered alerts from an anomaly scorer
+func TestAnomalyScorer(t *testing.T) {
+    // Start from an empty rule set so only the scorer can raise alerts
+    if err := analyzer.Rules().Load(nil); err != nil {
+        t.Fatalf("Failed to clear rules: %v", err)
+    }
+    defer analyzer.SetAnomalyScorer(nil, 0)
+
+    if err := analyzer.SetAnomalyScorer(&fixedScorer{}, 1.5); err == nil {
+        t.Error("Expected a threshold above 1 to be rejected")
+    }
+
+    ctx := context.Background()
+    event := generateTestEvents(1)[0]
+    features := map[string]interface{}{"login_rate_zscore": 4.2}
+
+    // Below the threshold no alert is raised
+    if err := analyzer.SetAnomalyScorer(&fixedScorer{score: 0.3, features: features}, 0.7); err != nil {
+        t.Fatalf("Failed to set anomaly scorer: %v", err)
+    }
+    alert, err := analyzer.DetectThreats(ctx, event)
+    if err != nil {
+        t.Fatalf("Detection failed: %v", err)
+    }
+    if alert != nil {
+        t.Error("Expected no alert for a score below the threshold")
+    }
+
+    // Above the threshold the score and features are attached to the alert
+    if err := analyzer.SetAnomalyScorer(&fixedScorer{score: 0.9, features: features}, 0.7); err != nil {
+        t.Fatalf("Failed to set anomaly scorer: %v", err)
+    }
+    alert, err = analyzer.DetectThreats(ctx, event)
+    if err != nil {
+        t.Fatalf("Detection failed: %v", err)
+    }
+    if alert == nil {
+        t.Fatal("Expected an alert for a score above the threshold")
+    }
+    if score := alert.IntelligenceData["anomaly_score"]; score != 0.9 {
+        t.Errorf("Expected anomaly score 0.9, got %v", score)
+    }
+    attached, _ := alert.IntelligenceData["anomaly_features"].(map[string]interface{})
+    if attached["login_rate_zscore"] != 4.2 {
+        t.Errorf("Expected anomaly features on the alert, got %v", alert.IntelligenceData["anomaly_features"])
+    }
+    if alert.Severity != "critical" {
+        t.Errorf("Expected severity derived from the score, got %s", alert.Severity)
     }
 }
\ No newline at end of file
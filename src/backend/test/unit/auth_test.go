@@ -0,0 +1,403 @@
+// Package unit provides unit tests for JWT signing-key rotation, refresh tokens
+// and the device authorization grant
+package unit
+
+import (
+    "context"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/x509"
+    "encoding/json"
+    "encoding/pem"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/alicebob/miniredis/v2"
+    "github.com/go-redis/redis/v8"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    authapi "github.com/blackpoint/api/v1/auth"
+    "github.com/blackpoint/internal/auth"
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// initTestJWTManager writes a fresh key pair to disk and initializes the JWT manager with it
+func initTestJWTManager(t *testing.T) {
+    key, err := rsa.GenerateKey(rand.Reader, 2048)
+    require.NoError(t, err)
+    publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+    require.NoError(t, err)
+
+    dir := t.TempDir()
+    privatePath := filepath.Join(dir, "jwt.key")
+    publicPath := filepath.Join(dir, "jwt.pub")
+    require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{
+        Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key),
+    }), 0600))
+    require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{
+        Type: "PUBLIC KEY", Bytes: publicDER,
+    }), 0644))
+
+    require.NoError(t, auth.InitJWTManager(auth.JWTConfig{
+        PrivateKeyPath:  privatePath,
+        PublicKeyPath:   publicPath,
+        TokenExpiration: time.Hour,
+    }))
+}
+
+// testTokenClaims returns the claims GenerateToken requires
+func testTokenClaims() map[string]interface{} {
+    return map[string]interface{}{
+        "client_id":   testClientID,
+        "permissions": []string{"read:events"},
+        "metadata":    map[string]string{},
+    }
+}
+
+// TestJWTKeyRotation verifies tokens signed before a rotation stay valid
+// while new tokens are signed with the new key
+func TestJWTKeyRotation(t *testing.T) {
+    initTestJWTManager(t)
+
+    before, err := auth.GenerateToken(testTokenClaims())
+    require.NoError(t, err)
+    require.Len(t, auth.JWKS().Keys, 1)
+    oldKID := auth.JWKS().Keys[0].Kid
+
+    newKID, err := auth.RotateSigningKey(nil)
+    require.NoError(t, err)
+    assert.NotEqual(t, oldKID, newKID)
+
+    after, err := auth.GenerateToken(testTokenClaims())
+    require.NoError(t, err)
+
+    // Both tokens verify: the old one through the grace period
+    _, err = auth.ValidateToken(before)
+    assert.NoError(t, err)
+    _, err = auth.ValidateToken(after)
+    assert.NoError(t, err)
+
+    // Both public keys are published for independent validation
+    var kids []string
+    for _, key := range auth.JWKS().Keys {
+        assert.Equal(t, "RSA", key.Kty)
+        assert.Equal(t, "RS256", key.Alg)
+        kids = append(kids, key.Kid)
+    }
+    assert.ElementsMatch(t, []string{oldKID, newKID}, kids)
+
+    // A token with a tampered header is rejected
+    parts := strings.Split(after, ".")
+    _, err = auth.ValidateToken(strings.Join([]string{parts[0] + "x", parts[1], parts[2]}, "."))
+    assert.Error(t, err)
+}
+
+// jwksKIDs returns the kids of a JWKS document
+func jwksKIDs(set auth.JSONWebKeySet) []string {
+    kids := make([]string, 0, len(set.Keys))
+    for _, key := range set.Keys {
+        kids = append(kids, key.Kid)
+    }
+    return kids
+}
+
+// TestJWTKeySharingAcrossReplicas verifies a replica verifies tokens another
+// replica signed before and after a rotation, through the shared key store
+func TestJWTKeySharingAcrossReplicas(t *testing.T) {
+    ctx := context.Background()
+    server := miniredis.RunT(t)
+    client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+    t.Cleanup(func() { client.Close() })
+    t.Cleanup(func() { auth.ShareSigningKeys(ctx, nil) })
+
+    // Replica A signs with its first key, rotates and signs with the next
+    initTestJWTManager(t)
+    require.NoError(t, auth.ShareSigningKeys(ctx, client))
+    beforeRotation, err := auth.GenerateToken(testTokenClaims())
+    require.NoError(t, err)
+    oldKID := auth.JWKS().Keys[0].Kid
+    newKID, err := auth.RotateSigningKey(nil)
+    require.NoError(t, err)
+    afterRotation, err := auth.GenerateToken(testTokenClaims())
+    require.NoError(t, err)
+
+    // The retired key is only shared for its grace period
+    assert.Equal(t, time.Hour, server.TTL("jwt_key:"+oldKID))
+    assert.Zero(t, server.TTL("jwt_key:"+newKID), "the active key must not expire")
+
+    // Replica B has its own key and only the shared store in common
+    router := authapi.NewAuthRouter(newTestOAuthManager(t))
+    require.NoError(t, auth.ShareSigningKeys(ctx, client))
+
+    _, err = auth.ValidateToken(beforeRotation)
+    assert.NoError(t, err, "a token signed before the rotation must verify on another replica")
+    _, err = auth.ValidateToken(afterRotation)
+    assert.NoError(t, err, "a token signed after the rotation must verify on another replica")
+
+    rec := httptest.NewRecorder()
+    router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+    require.Equal(t, http.StatusOK, rec.Code)
+    assert.Equal(t, "application/jwk-set+json", rec.Header().Get("Content-Type"))
+    var served auth.JSONWebKeySet
+    require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+    assert.Len(t, served.Keys, 3)
+    assert.Subset(t, jwksKIDs(served), []string{oldKID, newKID})
+
+    // Once the grace period passes the retired key stops verifying
+    server.FastForward(time.Hour + time.Second)
+    _, err = auth.ValidateToken(beforeRotation)
+    assert.Error(t, err)
+    _, err = auth.ValidateToken(afterRotation)
+    assert.NoError(t, err)
+    assert.NotContains(t, jwksKIDs(auth.JWKS()), oldKID)
+    assert.Len(t, auth.JWKS().Keys, 2)
+
+    rec = httptest.NewRecorder()
+    router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/.well-known/jwks.json", nil))
+    assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+// newTestOAuthManager returns an OAuth manager whose blacklist store is an
+// in-memory Redis server
+func newTestOAuthManager(t *testing.T) *auth.OAuthManager {
+    initTestJWTManager(t)
+
+    server := miniredis.RunT(t)
+    client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+    t.Cleanup(func() { client.Close() })
+    // Later tests must not consult this test's closed blacklist
+    t.Cleanup(func() { auth.SetRevocationCheck(nil) })
+
+    return auth.NewOAuthManagerWithStore(auth.OAuthConfig{
+        SecurityOptions: auth.SecurityConfig{TokenBlacklistTTL: 24 * time.Hour},
+    }, client)
+}
+
+// TestRefreshTokenRotation verifies each refresh issues a new refresh token
+// and a valid access token, and that the old refresh token stops working
+func TestRefreshTokenRotation(t *testing.T) {
+    ctx := context.Background()
+    manager := newTestOAuthManager(t)
+
+    first, err := manager.IssueRefreshToken(ctx, testTokenClaims())
+    require.NoError(t, err)
+
+    access, second, err := manager.RotateRefreshToken(ctx, first)
+    require.NoError(t, err)
+    assert.NotEqual(t, first, second)
+
+    claims, err := auth.ValidateToken(access)
+    require.NoError(t, err)
+    assert.Equal(t, testClientID, claims["client_id"])
+
+    // The rotated token can keep the chain going
+    _, third, err := manager.RotateRefreshToken(ctx, second)
+    require.NoError(t, err)
+    assert.NotEqual(t, second, third)
+
+    // Unknown tokens are rejected without touching any family
+    _, _, err = manager.RotateRefreshToken(ctx, "not-a-refresh-token")
+    assert.True(t, errors.IsErrorCode(err, "E1001", ""))
+    _, _, err = manager.RotateRefreshToken(ctx, third)
+    assert.NoError(t, err)
+}
+
+// TestRefreshTokenReuseRevokesFamily verifies that replaying a rotated
+// refresh token revokes every token in its family
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+    ctx := context.Background()
+    manager := newTestOAuthManager(t)
+
+    first, err := manager.IssueRefreshToken(ctx, testTokenClaims())
+    require.NoError(t, err)
+    access, second, err := manager.RotateRefreshToken(ctx, first)
+    require.NoError(t, err)
+
+    // A separate login is a separate family and must be unaffected
+    other, err := manager.IssueRefreshToken(ctx, testTokenClaims())
+    require.NoError(t, err)
+
+    // Replaying the stolen first token is detected
+    _, _, err = manager.RotateRefreshToken(ctx, first)
+    require.Error(t, err)
+    assert.True(t, errors.IsErrorCode(err, "E1001", ""))
+
+    // The legitimate holder's current refresh token is revoked too
+    _, _, err = manager.RotateRefreshToken(ctx, second)
+    assert.Error(t, err)
+
+    // Access tokens issued from the family are blacklisted
+    revoked, err := manager.IsTokenRevoked(ctx, access)
+    require.NoError(t, err)
+    assert.True(t, revoked)
+
+    _, _, err = manager.RotateRefreshToken(ctx, other)
+    assert.NoError(t, err)
+}
+
+// TestValidateTokenRejectsRevokedTokens verifies tokens blacklisted in the
+// shared store are rejected, as they would be on every other replica
+func TestValidateTokenRejectsRevokedTokens(t *testing.T) {
+    ctx := context.Background()
+    manager := newTestOAuthManager(t)
+
+    first, err := manager.IssueRefreshToken(ctx, testTokenClaims())
+    require.NoError(t, err)
+    access, second, err := manager.RotateRefreshToken(ctx, first)
+    require.NoError(t, err)
+    _, err = auth.ValidateToken(access)
+    require.NoError(t, err)
+
+    // Explicit revocation
+    require.NoError(t, manager.RevokeToken(ctx, access))
+    _, err = auth.ValidateToken(access)
+    require.Error(t, err)
+    assert.True(t, errors.IsErrorCode(err, "E1001", ""))
+
+    // Family revocation after refresh token reuse
+    familyAccess, _, err := manager.RotateRefreshToken(ctx, second)
+    require.NoError(t, err)
+    _, err = auth.ValidateToken(familyAccess)
+    require.NoError(t, err)
+    _, _, err = manager.RotateRefreshToken(ctx, second)
+    require.Error(t, err)
+    _, err = auth.ValidateToken(familyAccess)
+    assert.Error(t, err, "access tokens of a revoked family must be rejected")
+
+    // Without a shared blacklist only local revocations apply
+    auth.SetRevocationCheck(nil)
+    _, err = auth.ValidateToken(familyAccess)
+    assert.NoError(t, err)
+}
+
+// newDeviceFlowRouter serves the auth routes for a manager whose provider
+// answers each device token poll with the next of responses
+func newDeviceFlowRouter(t *testing.T, responses []string) (http.Handler, *miniredis.Miniredis) {
+    var mu sync.Mutex
+    provider := http.NewServeMux()
+    provider.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+        json.NewEncoder(w).Encode(auth.DeviceCodeResponse{
+            DeviceCode:      "device-code-1",
+            UserCode:        "WDJB-MJHT",
+            VerificationURI: "https://idp.example.com/activate",
+            ExpiresIn:       600,
+            Interval:        1,
+        })
+    })
+    provider.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+        assert.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", r.FormValue("grant_type"))
+        mu.Lock()
+        defer mu.Unlock()
+        if !assert.NotEmpty(t, responses, "unexpected device token poll") {
+            http.Error(w, "unexpected poll", http.StatusInternalServerError)
+            return
+        }
+        w.WriteHeader(http.StatusBadRequest)
+        json.NewEncoder(w).Encode(map[string]string{"error": responses[0]})
+        responses = responses[1:]
+    })
+    idp := httptest.NewServer(provider)
+    t.Cleanup(idp.Close)
+
+    server := miniredis.RunT(t)
+    client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+    t.Cleanup(func() { client.Close() })
+
+    manager := auth.NewOAuthManagerWithStore(auth.OAuthConfig{
+        ClientID:        "blackpoint-cli",
+        DeviceAuthURL:   idp.URL + "/device",
+        TokenURL:        idp.URL + "/token",
+        SecurityOptions: auth.SecurityConfig{RateLimitPerMinute: 600},
+    }, client)
+    return authapi.NewAuthRouter(manager), server
+}
+
+// startDeviceLogin requests a device code through the router
+func startDeviceLogin(t *testing.T, router http.Handler) auth.DeviceCodeResponse {
+    rec := httptest.NewRecorder()
+    router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/auth/device/code", nil))
+    require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+    var code auth.DeviceCodeResponse
+    require.NoError(t, json.NewDecoder(rec.Body).Decode(&code))
+    require.Equal(t, "device-code-1", code.DeviceCode)
+    return code
+}
+
+// deviceTokenRequest builds the CLI's device token poll
+func deviceTokenRequest(ctx context.Context, deviceCode string) *http.Request {
+    form := url.Values{"device_code": {deviceCode}}
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/device/token", strings.NewReader(form.Encode()))
+    req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    return req.WithContext(ctx)
+}
+
+// TestPollDeviceToken verifies the routed device flow handles the provider's
+// terminal and back-off responses
+func TestPollDeviceToken(t *testing.T) {
+    t.Run("routes require POST", func(t *testing.T) {
+        router, _ := newDeviceFlowRouter(t, nil)
+        for _, path := range []string{"/api/v1/auth/device/code", "/api/v1/auth/device/token"} {
+            rec := httptest.NewRecorder()
+            router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+            assert.Equal(t, http.StatusMethodNotAllowed, rec.Code, path)
+        }
+    })
+
+    t.Run("access_denied", func(t *testing.T) {
+        router, server := newDeviceFlowRouter(t, []string{"authorization_pending", "access_denied"})
+        code := startDeviceLogin(t, router)
+
+        rec := httptest.NewRecorder()
+        router.ServeHTTP(rec, deviceTokenRequest(context.Background(), code.DeviceCode))
+        assert.Equal(t, http.StatusForbidden, rec.Code)
+        assert.Contains(t, rec.Body.String(), "denied")
+
+        // The device code cannot be polled again
+        assert.False(t, server.Exists("device:"+code.DeviceCode))
+        rec = httptest.NewRecorder()
+        router.ServeHTTP(rec, deviceTokenRequest(context.Background(), code.DeviceCode))
+        assert.Equal(t, http.StatusBadRequest, rec.Code)
+        assert.Contains(t, rec.Body.String(), "Invalid or expired device code")
+    })
+
+    t.Run("expired_token", func(t *testing.T) {
+        router, server := newDeviceFlowRouter(t, []string{"expired_token"})
+        code := startDeviceLogin(t, router)
+
+        rec := httptest.NewRecorder()
+        router.ServeHTTP(rec, deviceTokenRequest(context.Background(), code.DeviceCode))
+        assert.Equal(t, http.StatusBadRequest, rec.Code)
+        assert.Contains(t, rec.Body.String(), "Device code expired")
+        assert.False(t, server.Exists("device:"+code.DeviceCode))
+    })
+
+    t.Run("slow_down lengthens the interval", func(t *testing.T) {
+        router, server := newDeviceFlowRouter(t, []string{"slow_down"})
+        code := startDeviceLogin(t, router)
+
+        // Give up while waiting out the lengthened interval
+        ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+        defer cancel()
+        rec := httptest.NewRecorder()
+        router.ServeHTTP(rec, deviceTokenRequest(ctx, code.DeviceCode))
+        assert.Contains(t, rec.Body.String(), "cancelled")
+
+        // The pending session now polls 5 seconds slower than the provider's 1
+        raw, err := server.Get("device:" + code.DeviceCode)
+        require.NoError(t, err)
+        var session struct {
+            Interval time.Duration `json:"interval"`
+        }
+        require.NoError(t, json.Unmarshal([]byte(raw), &session))
+        assert.Equal(t, 6*time.Second, session.Interval)
+    })
+}
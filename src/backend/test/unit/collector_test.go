@@ -2,11 +2,17 @@ package unit
 
 import (
     "context"
+    "fmt"
+    "math/rand"
     "testing"
     "time"
     "sync"
+    "sync/atomic"
 
+    "github.com/alicebob/miniredis/v2"
+    "github.com/go-redis/redis/v8"
     "github.com/blackpoint/internal/collector"
+    "github.com/blackpoint/pkg/common/errors"
     "github.com/blackpoint/test/pkg/fixtures"
     "github.com/blackpoint/test/pkg/mocks"
     "github.com/stretchr/testify/assert"
@@ -239,6 +245,59 @@ func TestCollector_ValidationRules(t *testing.T) {
     }
 }
 
+// TestLenientDeserializer tests recovery of malformed vendor payloads
+func TestLenientDeserializer(t *testing.T) {
+    deserializer := collector.NewLenientDeserializer(collector.DefaultLenientOptions())
+
+    tests := []struct {
+        name          string
+        payload       string
+        expectError   bool
+        expectRecover bool
+    }{
+        {
+            name:    "Valid JSON passes through",
+            payload: `{"event_type":"login"}`,
+        },
+        {
+            name:          "Trailing commas recovered",
+            payload:       `{"event_type":"login","tags":["a","b",],}`,
+            expectRecover: true,
+        },
+        {
+            name:          "Unquoted keys recovered",
+            payload:       `{event_type: "login", user: {id: 42}}`,
+            expectRecover: true,
+        },
+        {
+            name:          "Single quotes recovered",
+            payload:       `{'event_type': 'login'}`,
+            expectRecover: true,
+        },
+        {
+            name:        "Truncated payload rejected",
+            payload:     `{"event_type": "log`,
+            expectError: true,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            data, recovered, err := deserializer.Deserialize([]byte(tt.payload))
+            if tt.expectError {
+                assert.Error(t, err)
+                return
+            }
+
+            assert.NoError(t, err)
+            assert.Equal(t, tt.expectRecover, recovered)
+            if tt.expectRecover {
+                assert.Contains(t, string(data), `"_blackpoint_recovered":true`)
+            }
+        })
+    }
+}
+
 // TestCollector_ConcurrentProcessing tests concurrent event processing
 func TestCollector_ConcurrentProcessing(t *testing.T) {
     suite := newCollectorTestSuite(t)
@@ -316,4 +375,427 @@ func TestCollector_Shutdown(t *testing.T) {
     eventBytes, _ := event.ToJSON()
     err = suite.collector.CollectEvent(suite.ctx, eventBytes)
     assert.Error(t, err)
-}
\ No newline at end of file
+}
+
+// TestDeduplicator_SameEventTwice tests that a repeated delivery is detected by each seen-set
+func TestDeduplicator_SameEventTwice(t *testing.T) {
+    server := miniredis.RunT(t)
+    client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+    stores := map[string]collector.SeenSet{
+        "lru":   collector.NewLRUSeenSet(10),
+        "redis": collector.NewRedisSeenSet(client, ""),
+    }
+
+    for name, store := range stores {
+        t.Run(name, func(t *testing.T) {
+            dedup := collector.NewDeduplicator(collector.DedupConfig{
+                Enabled: true,
+                IDField: "event_id",
+                TTL:     time.Minute,
+                Store:   store,
+            })
+            ctx := context.Background()
+
+            first := []byte(`{"event_id":"evt-1","event_type":"login"}`)
+            duplicate, err := dedup.IsDuplicate(ctx, first)
+            assert.NoError(t, err)
+            assert.False(t, duplicate, "first delivery must not be a duplicate")
+
+            // A retry carries the same ID even if the payload differs slightly
+            retry := []byte(`{"event_id":"evt-1","event_type":"login","retry":1}`)
+            duplicate, err = dedup.IsDuplicate(ctx, retry)
+            assert.NoError(t, err)
+            assert.True(t, duplicate, "retried delivery must be a duplicate")
+
+            // Without an event ID the content hash is used
+            noID := []byte(`{"event_type":"logout"}`)
+            duplicate, err = dedup.IsDuplicate(ctx, noID)
+            assert.NoError(t, err)
+            assert.False(t, duplicate)
+            duplicate, err = dedup.IsDuplicate(ctx, noID)
+            assert.NoError(t, err)
+            assert.True(t, duplicate)
+
+            // A forgotten event is new again
+            assert.NoError(t, dedup.Forget(ctx, retry))
+            duplicate, err = dedup.IsDuplicate(ctx, first)
+            assert.NoError(t, err)
+            assert.False(t, duplicate)
+        })
+    }
+}
+
+// TestCollector_DedupReleasesUncollectedEvents tests that an event rejected
+// by a full buffer is not remembered, so its redelivery is accepted
+func TestCollector_DedupReleasesUncollectedEvents(t *testing.T) {
+    for _, policy := range []string{collector.OverflowReject, collector.OverflowDropNewest, collector.OverflowBlock} {
+        t.Run(policy, func(t *testing.T) {
+            mockProducer := &mocks.MockProducer{}
+            mockProducer.On("PublishBatch", mock.Anything, mock.Anything).Return(nil)
+
+            store := collector.NewLRUSeenSet(10)
+            col, err := collector.NewRealtimeCollector(nil, mockProducer, collector.CollectorConfig{
+                BufferSize:     1,
+                BatchSize:      testBatchSize,
+                FlushInterval:  time.Second,
+                OverflowPolicy: policy,
+                Dedup: collector.DedupConfig{
+                    Enabled: true,
+                    IDField: "event_id",
+                    TTL:     time.Minute,
+                    Store:   store,
+                },
+            })
+            assert.NoError(t, err, "Failed to create collector")
+
+            // The collector is not started, so the first event fills the buffer
+            ctx := context.Background()
+            assert.NoError(t, col.CollectEvent(ctx, []byte(`{"event_id":"evt-1"}`)))
+
+            blockCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+            defer cancel()
+            col.CollectEvent(blockCtx, []byte(`{"event_id":"evt-2"}`))
+
+            // The buffered event stays claimed; the shed one was released
+            seen, err := store.MarkSeen(ctx, "id:evt-1", time.Minute)
+            assert.NoError(t, err)
+            assert.True(t, seen, "buffered event must remain in the window")
+            seen, err = store.MarkSeen(ctx, "id:evt-2", time.Minute)
+            assert.NoError(t, err)
+            assert.False(t, seen, "uncollected event must be released for redelivery")
+        })
+    }
+}
+
+// TestLRUSeenSet_Eviction tests that the in-memory window is bounded
+func TestLRUSeenSet_Eviction(t *testing.T) {
+    store := collector.NewLRUSeenSet(2)
+    ctx := context.Background()
+
+    for _, key := range []string{"a", "b", "c"} {
+        seen, err := store.MarkSeen(ctx, key, time.Minute)
+        assert.NoError(t, err)
+        assert.False(t, seen)
+    }
+
+    // "a" was evicted when "c" was added
+    seen, _ := store.MarkSeen(ctx, "a", time.Minute)
+    assert.False(t, seen)
+    seen, _ = store.MarkSeen(ctx, "c", time.Minute)
+    assert.True(t, seen)
+}
+
+// newOverflowCollector creates an unstarted collector with a one-event buffer
+func newOverflowCollector(t *testing.T, policy string) *collector.RealtimeCollector {
+    mockProducer := &mocks.MockProducer{}
+    mockProducer.On("PublishBatch", mock.Anything, mock.Anything).Return(nil)
+
+    col, err := collector.NewRealtimeCollector(nil, mockProducer, collector.CollectorConfig{
+        BufferSize:     1,
+        BatchSize:      testBatchSize,
+        FlushInterval:  time.Second,
+        OverflowPolicy: policy,
+    })
+    assert.NoError(t, err, "Failed to create collector")
+
+    // The collector is not started, so the first event fills the buffer
+    assert.NoError(t, col.CollectEvent(context.Background(), fixtures.SamplePayloads.ValidPayload))
+    return col
+}
+
+// TestCollector_OverflowPolicies tests each overflow policy against a saturated buffer
+func TestCollector_OverflowPolicies(t *testing.T) {
+    t.Run("block waits until the context is done", func(t *testing.T) {
+        col := newOverflowCollector(t, collector.OverflowBlock)
+        ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+        defer cancel()
+
+        start := time.Now()
+        err := col.CollectEvent(ctx, fixtures.SamplePayloads.ValidPayload)
+        assert.Error(t, err)
+        assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+    })
+
+    t.Run("block releases senders when a drain begins", func(t *testing.T) {
+        col := newOverflowCollector(t, collector.OverflowBlock)
+
+        blocked := make(chan error, 1)
+        go func() {
+            blocked <- col.CollectEvent(context.Background(), fixtures.SamplePayloads.ValidPayload)
+        }()
+        // Let the sender block on the full buffer
+        time.Sleep(50 * time.Millisecond)
+
+        ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+        defer cancel()
+        start := time.Now()
+        assert.NoError(t, col.Drain(ctx))
+        assert.Less(t, time.Since(start), time.Second)
+
+        select {
+        case err := <-blocked:
+            assert.True(t, errors.IsErrorCode(err, "E4002", ""), "expected E4002, got %v", err)
+        case <-time.After(time.Second):
+            t.Fatal("blocked sender was not released by the drain")
+        }
+    })
+
+    t.Run("drop-newest discards the event without error", func(t *testing.T) {
+        col := newOverflowCollector(t, collector.OverflowDropNewest)
+
+        start := time.Now()
+        err := col.CollectEvent(context.Background(), fixtures.SamplePayloads.ValidPayload)
+        assert.NoError(t, err)
+        assert.Less(t, time.Since(start), time.Second)
+    })
+
+    t.Run("reject returns E4002 without blocking", func(t *testing.T) {
+        col := newOverflowCollector(t, collector.OverflowReject)
+
+        done := make(chan error, 1)
+        go func() {
+            done <- col.CollectEvent(context.Background(), fixtures.SamplePayloads.ValidPayload)
+        }()
+
+        select {
+        case err := <-done:
+            assert.Error(t, err)
+            assert.True(t, errors.IsErrorCode(err, "E4002", ""), "expected E4002, got %v", err)
+        case <-time.After(time.Second):
+            t.Fatal("reject policy blocked the producing goroutine")
+        }
+    })
+}
+// TestCollector_Drain tests that draining publishes buffered events and rejects new ones
+func TestCollector_Drain(t *testing.T) {
+    for _, started := range []bool{false, true} {
+        name := "unstarted collector"
+        if started {
+            name = "running collector"
+        }
+        t.Run(name, func(t *testing.T) {
+            var mu sync.Mutex
+            published := 0
+            mockProducer := &mocks.MockProducer{}
+            mockProducer.On("PublishBatch", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+                mu.Lock()
+                defer mu.Unlock()
+                published += len(args.Get(1).([][]byte))
+            })
+
+            // A long flush interval leaves events buffered until the drain
+            col, err := collector.NewRealtimeCollector(nil, mockProducer, collector.CollectorConfig{
+                BufferSize:    100,
+                BatchSize:     10,
+                FlushInterval: time.Hour,
+            })
+            assert.NoError(t, err, "Failed to create collector")
+            if started {
+                assert.NoError(t, col.Start())
+            }
+
+            for i := 0; i < 25; i++ {
+                assert.NoError(t, col.CollectEvent(context.Background(), fixtures.SamplePayloads.ValidPayload))
+            }
+
+            ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+            defer cancel()
+            assert.NoError(t, col.Drain(ctx))
+
+            mu.Lock()
+            assert.Equal(t, 25, published, "all buffered events should be published")
+            mu.Unlock()
+
+            err = col.CollectEvent(context.Background(), fixtures.SamplePayloads.ValidPayload)
+            assert.True(t, errors.IsErrorCode(err, "E4002", ""), "expected E4002 after drain, got %v", err)
+
+            // Draining again and stopping are safe once drained
+            assert.NoError(t, col.Drain(ctx))
+            assert.NoError(t, col.Stop())
+        })
+    }
+}
+
+func TestCollector_PerClientRateLimit(t *testing.T) {
+    mockProducer := &mocks.MockProducer{}
+    mockProducer.On("PublishBatch", mock.Anything, mock.Anything).Return(nil)
+
+    // A negligible refill rate leaves each client with only its burst
+    col, err := collector.NewRealtimeCollector(nil, mockProducer, collector.CollectorConfig{
+        BufferSize: 1000,
+        RateLimit: collector.RateLimitConfig{
+            Enabled: true,
+            Default: collector.RateLimit{EventsPerSecond: 0.001, Burst: 5},
+        },
+    })
+    assert.NoError(t, err, "Failed to create collector")
+
+    noisy := collector.WithClientID(context.Background(), "client-a")
+    accepted := 0
+    for i := 0; i < 20; i++ {
+        err := col.CollectEvent(noisy, fixtures.SamplePayloads.ValidPayload)
+        if err == nil {
+            accepted++
+            continue
+        }
+        assert.True(t, errors.IsErrorCode(err, "E4029", ""), "expected E4029 when flooding, got %v", err)
+    }
+    assert.Equal(t, 5, accepted, "only the burst should be accepted")
+
+    // Naming another client in the payload does not escape the limit
+    spoofed := []byte(`{"client_id": "client-b", "event_type": "login"}`)
+    err = col.CollectEvent(noisy, spoofed)
+    assert.True(t, errors.IsErrorCode(err, "E4029", ""), "payload client_id must not select the bucket, got %v", err)
+
+    // A second authenticated client keeps its own budget
+    quiet := collector.WithClientID(context.Background(), "client-b")
+    for i := 0; i < 5; i++ {
+        assert.NoError(t, col.CollectEvent(quiet, fixtures.SamplePayloads.ValidPayload), "client-b should not be limited")
+    }
+
+    // Raising client-a's limit applies without a restart
+    assert.NoError(t, col.ReloadRateLimits(collector.RateLimitConfig{
+        Enabled: true,
+        Default: collector.RateLimit{EventsPerSecond: 0.001, Burst: 5},
+        Clients: map[string]collector.RateLimit{
+            "client-a": {EventsPerSecond: 1000, Burst: 100},
+        },
+    }))
+    time.Sleep(10 * time.Millisecond)
+    assert.NoError(t, col.CollectEvent(noisy, fixtures.SamplePayloads.ValidPayload), "client-a should be accepted after reload")
+
+    // Invalid limits are refused and leave the current limits in place
+    err = col.ReloadRateLimits(collector.RateLimitConfig{Enabled: true})
+    assert.True(t, errors.IsErrorCode(err, "E2001", ""), "expected E2001 for zero limits, got %v", err)
+}
+
+// TestClientRateLimiter_IdleEviction tests that buckets of clients that
+// stopped sending are dropped once refilled, bounding the limiter's memory
+func TestClientRateLimiter_IdleEviction(t *testing.T) {
+    limiter, err := collector.NewClientRateLimiter(collector.RateLimitConfig{
+        Enabled:     true,
+        Default:     collector.RateLimit{EventsPerSecond: 1000, Burst: 5},
+        IdleTimeout: 20 * time.Millisecond,
+    })
+    assert.NoError(t, err)
+
+    for i := 0; i < 100; i++ {
+        assert.True(t, limiter.Allow(fmt.Sprintf("client-%d", i)))
+    }
+    assert.Equal(t, 100, limiter.TrackedClients())
+
+    // A drained bucket is kept until it refills, so eviction grants nothing extra
+    slow, err := collector.NewClientRateLimiter(collector.RateLimitConfig{
+        Enabled:     true,
+        Default:     collector.RateLimit{EventsPerSecond: 0.001, Burst: 1},
+        IdleTimeout: 20 * time.Millisecond,
+    })
+    assert.NoError(t, err)
+    assert.True(t, slow.Allow("drained"))
+
+    time.Sleep(50 * time.Millisecond)
+
+    // The next call sweeps every idle, refilled bucket
+    assert.True(t, limiter.Allow("active"))
+    assert.Equal(t, 1, limiter.TrackedClients())
+
+    assert.True(t, slow.Allow("other"))
+    assert.Equal(t, 2, slow.TrackedClients())
+    assert.False(t, slow.Allow("drained"), "an unrefilled bucket must not be reset by eviction")
+}
+
+// TestPollSchedule_JitterBounds tests that every delay falls in
+// [Interval, Interval+Jitter) and that jitter actually varies
+func TestPollSchedule_JitterBounds(t *testing.T) {
+    schedule := collector.PollSchedule{Interval: time.Minute, Jitter: 10 * time.Second}
+    random := rand.New(rand.NewSource(1))
+
+    seen := make(map[time.Duration]bool)
+    for i := 0; i < 1000; i++ {
+        delay := schedule.NextDelay(random)
+        assert.GreaterOrEqual(t, int64(delay), int64(schedule.Interval))
+        assert.Less(t, int64(delay), int64(schedule.Interval+schedule.Jitter))
+        seen[delay] = true
+    }
+    assert.Greater(t, len(seen), 1, "expected jittered delays to differ")
+
+    // Without jitter every poll is exactly one interval apart
+    fixed := collector.PollSchedule{Interval: time.Minute}
+    assert.Equal(t, time.Minute, fixed.NextDelay(random))
+}
+
+// TestPollingScheduler_SkipsOverrun tests that a due poll is skipped while
+// the previous poll of the integration is still running
+func TestPollingScheduler_SkipsOverrun(t *testing.T) {
+    scheduler := collector.NewPollingScheduler(1, collector.PollSchedule{Interval: 10 * time.Millisecond})
+    defer scheduler.Stop(context.Background())
+
+    release := make(chan struct{})
+    var calls int32
+    poll := func(ctx context.Context) error {
+        atomic.AddInt32(&calls, 1)
+        <-release
+        return nil
+    }
+    assert.NoError(t, scheduler.Schedule(context.Background(), "integration-1", "okta", poll))
+
+    // Several intervals pass while the first poll is blocked
+    time.Sleep(100 * time.Millisecond)
+    assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "overlapping polls must be skipped")
+
+    close(release)
+    assert.Eventually(t, func() bool {
+        return atomic.LoadInt32(&calls) > 1
+    }, testTimeout, 5*time.Millisecond, "polling must resume once the slow poll returns")
+}
+
+// TestPollingScheduler_ReplacementWaitsForInFlightPoll tests that a job
+// replacing another does not overlap the poll the old job still has in flight
+func TestPollingScheduler_ReplacementWaitsForInFlightPoll(t *testing.T) {
+    scheduler := collector.NewPollingScheduler(2, collector.PollSchedule{Interval: 10 * time.Millisecond})
+    defer scheduler.Stop(context.Background())
+
+    started := make(chan struct{})
+    release := make(chan struct{})
+    var once sync.Once
+    oldPoll := func(ctx context.Context) error {
+        once.Do(func() { close(started) })
+        // The poll ignores cancellation, like a slow upstream call
+        <-release
+        return nil
+    }
+    assert.NoError(t, scheduler.Schedule(context.Background(), "integration-1", "okta", oldPoll))
+    <-started
+
+    var newCalls int32
+    newPoll := func(ctx context.Context) error {
+        atomic.AddInt32(&newCalls, 1)
+        return nil
+    }
+    assert.NoError(t, scheduler.Schedule(context.Background(), "integration-1", "okta", newPoll))
+
+    time.Sleep(100 * time.Millisecond)
+    assert.Equal(t, int32(0), atomic.LoadInt32(&newCalls), "replacement must wait for the in-flight poll")
+
+    close(release)
+    assert.Eventually(t, func() bool {
+        return atomic.LoadInt32(&newCalls) > 0
+    }, testTimeout, 5*time.Millisecond)
+}
+
+// TestPollingScheduler_RejectsJobsAfterStop tests that Stop is final
+func TestPollingScheduler_RejectsJobsAfterStop(t *testing.T) {
+    scheduler := collector.NewPollingScheduler(1, collector.PollSchedule{})
+    poll := func(ctx context.Context) error { return nil }
+
+    assert.NoError(t, scheduler.Schedule(context.Background(), "integration-1", "okta", poll))
+    assert.NoError(t, scheduler.Stop(context.Background()))
+
+    err := scheduler.Schedule(context.Background(), "integration-2", "okta", poll)
+    assert.True(t, errors.IsErrorCode(err, "E4002", ""), "expected E4002 after stop, got %v", err)
+
+    // Platform schedules below the minimum interval are refused
+    err = scheduler.SetPlatformSchedule("okta", collector.PollSchedule{Interval: time.Second})
+    assert.True(t, errors.IsErrorCode(err, "E2001", ""), "expected E2001, got %v", err)
+}
@@ -0,0 +1,541 @@
+// Package unit provides unit tests for field-level encryption
+package unit
+
+import (
+    "context"
+    "crypto/rand"
+    "strings"
+    "fmt"
+    "sort"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/alicebob/miniredis/v2"
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/service/kms"
+    "github.com/aws/aws-sdk-go-v2/service/kms/types"
+    "github.com/go-redis/redis/v8"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "../../internal/encryption"
+    "../../pkg/common/errors"
+)
+
+// fakeKMS wraps data keys by prefixing them with their encryption context,
+// rejecting unwraps whose context differs like AWS KMS does
+type fakeKMS struct {
+    encryption.KMSAPI
+    generated   int64
+    decrypted   int64
+    created     int64
+    unavailable int32
+}
+
+func (f *fakeKMS) CreateKey(ctx context.Context, params *kms.CreateKeyInput, optFns ...func(*kms.Options)) (*kms.CreateKeyOutput, error) {
+    n := atomic.AddInt64(&f.created, 1)
+    return &kms.CreateKeyOutput{
+        KeyMetadata: &types.KeyMetadata{KeyId: aws.String(fmt.Sprintf("rotated-key-%d", n))},
+    }, nil
+}
+
+func (f *fakeKMS) EnableKeyRotation(ctx context.Context, params *kms.EnableKeyRotationInput, optFns ...func(*kms.Options)) (*kms.EnableKeyRotationOutput, error) {
+    return &kms.EnableKeyRotationOutput{}, nil
+}
+
+func (f *fakeKMS) GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error) {
+    if atomic.LoadInt32(&f.unavailable) == 1 {
+        return nil, &types.KMSInternalException{Message: aws.String("kms unavailable")}
+    }
+    atomic.AddInt64(&f.generated, 1)
+    key := make([]byte, aws.ToInt32(params.NumberOfBytes))
+    if _, err := rand.Read(key); err != nil {
+        return nil, err
+    }
+    wrapped := append([]byte(params.EncryptionContext["client_id"]+"|"), key...)
+    return &kms.GenerateDataKeyOutput{Plaintext: key, CiphertextBlob: wrapped}, nil
+}
+
+func (f *fakeKMS) Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+    atomic.AddInt64(&f.decrypted, 1)
+    prefix := params.EncryptionContext["client_id"] + "|"
+    if !strings.HasPrefix(string(params.CiphertextBlob), prefix) {
+        return nil, &types.InvalidCiphertextException{}
+    }
+    return &kms.DecryptOutput{Plaintext: params.CiphertextBlob[len(prefix):]}, nil
+}
+
+// newTestFieldEncryptor builds a field encryptor backed by a fake KMS
+func newTestFieldEncryptor(t testing.TB) (*encryption.FieldEncryptor, *fakeKMS) {
+    fake := &fakeKMS{}
+    manager, err := encryption.NewKMSManager(fake, "alias/blackpoint-test")
+    require.NoError(t, err)
+    encryptor, err := encryption.NewFieldEncryptor(manager, nil)
+    require.NoError(t, err)
+    return encryptor, fake
+}
+
+// newTestKeyStore returns a keyring store backed by an in-memory Redis server
+func newTestKeyStore(t *testing.T) *encryption.RedisKeyStore {
+    server := miniredis.RunT(t)
+    client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+    t.Cleanup(func() { client.Close() })
+    return encryption.NewRedisKeyStore(client, "")
+}
+
+// tenantEvent returns an event with sensitive fields for a client
+func tenantEvent(clientID string) map[string]interface{} {
+    return map[string]interface{}{
+        "client_id": clientID,
+        "email":     "analyst@example.com",
+        "password":  "hunter2",
+        "api_token": "tok-123",
+        "severity":  "high",
+    }
+}
+
+// TestTenantFieldEncryption verifies fields round-trip for their own client
+// and cannot be decrypted as another client
+func TestTenantFieldEncryption(t *testing.T) {
+    encryptor, fake := newTestFieldEncryptor(t)
+    ctx := context.Background()
+
+    encrypted, err := encryptor.EncryptFields(ctx, tenantEvent(testClientID))
+    require.NoError(t, err)
+    assert.NotEqual(t, "hunter2", encrypted["password"])
+    assert.Equal(t, "high", encrypted["severity"])
+    assert.EqualValues(t, 1, atomic.LoadInt64(&fake.generated), "fields of one event should share a data key")
+
+    decrypted, err := encryptor.DecryptFields(ctx, encrypted)
+    require.NoError(t, err)
+    assert.Equal(t, "hunter2", decrypted["password"])
+
+    // Relabelling the event as another client must not expose its fields
+    encrypted["client_id"] = "test-client-002"
+    _, err = encryptor.DecryptFields(ctx, encrypted)
+    require.Error(t, err)
+    assert.True(t, errors.IsErrorCode(err, encryption.TenantMismatchCode, ""))
+    assert.False(t, errors.IsErrorCode(err, "E1002", ""), "tenant mismatches must not read as permission errors")
+    _, registered := errors.LookupErrorCode(encryption.TenantMismatchCode)
+    assert.True(t, registered)
+
+    // A replica without the cached key gets the same answer from KMS
+    other, _ := newTestFieldEncryptor(t)
+    _, err = other.DecryptFields(ctx, encrypted)
+    require.Error(t, err)
+    assert.True(t, errors.IsErrorCode(err, encryption.TenantMismatchCode, ""))
+}
+
+// gatheredValue returns a counter's value or a histogram's sample count from
+// reg for the metric with the given name and labels
+func gatheredValue(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) float64 {
+    t.Helper()
+
+    families, err := reg.Gather()
+    require.NoError(t, err)
+    for _, family := range families {
+        if family.GetName() != name {
+            continue
+        }
+    metrics:
+        for _, metric := range family.GetMetric() {
+            for _, label := range metric.GetLabel() {
+                if want, ok := labels[label.GetName()]; ok && want != label.GetValue() {
+                    continue metrics
+                }
+            }
+            if histogram := metric.GetHistogram(); histogram != nil {
+                return float64(histogram.GetSampleCount())
+            }
+            return metric.GetCounter().GetValue()
+        }
+    }
+    return 0
+}
+
+// TestFieldEncryptionMetrics verifies an encrypt/decrypt cycle records
+// durations, KMS calls apart from local AES work, field counts and errors
+func TestFieldEncryptionMetrics(t *testing.T) {
+    reg := prometheus.NewRegistry()
+    require.NoError(t, encryption.RegisterMetrics(reg))
+
+    duration := func(operation string) float64 {
+        return gatheredValue(t, reg, "blackpoint_encryption_duration_seconds", map[string]string{"operation": operation})
+    }
+    operations := []string{"encrypt", "decrypt", "kms_generate_data_key", "aes_seal", "aes_open"}
+    before := make(map[string]float64, len(operations))
+    for _, operation := range operations {
+        before[operation] = duration(operation)
+    }
+    fieldsBefore := gatheredValue(t, reg, "blackpoint_fields_encrypted_total", nil)
+    mismatchBefore := gatheredValue(t, reg, "blackpoint_encryption_errors_total", map[string]string{"reason": "tenant_mismatch"})
+
+    encryptor, _ := newTestFieldEncryptor(t)
+    ctx := context.Background()
+
+    encrypted, err := encryptor.EncryptFields(ctx, tenantEvent(testClientID))
+    require.NoError(t, err)
+    _, err = encryptor.DecryptFields(ctx, encrypted)
+    require.NoError(t, err)
+
+    // email, password and api_token are sensitive; one data key covers them
+    assert.Equal(t, 3.0, gatheredValue(t, reg, "blackpoint_fields_encrypted_total", nil)-fieldsBefore)
+    assert.Equal(t, 1.0, duration("encrypt")-before["encrypt"])
+    assert.Equal(t, 1.0, duration("decrypt")-before["decrypt"])
+    assert.Equal(t, 1.0, duration("kms_generate_data_key")-before["kms_generate_data_key"])
+    assert.Equal(t, 3.0, duration("aes_seal")-before["aes_seal"])
+    assert.Equal(t, 3.0, duration("aes_open")-before["aes_open"])
+
+    encrypted["client_id"] = "test-client-002"
+    _, err = encryptor.DecryptFields(ctx, encrypted)
+    require.Error(t, err)
+    assert.Equal(t, 1.0, gatheredValue(t, reg, "blackpoint_encryption_errors_total", map[string]string{"reason": "tenant_mismatch"})-mismatchBefore)
+}
+
+// TestFieldKeyRotation verifies old fields stay readable after rotation until
+// retention passes, and that ReEncrypt upgrades them to the new key
+func TestFieldKeyRotation(t *testing.T) {
+    encryptor, fake := newTestFieldEncryptor(t)
+    ctx := context.Background()
+
+    original, err := encryptor.EncryptFields(ctx, tenantEvent(testClientID))
+    require.NoError(t, err)
+
+    kv, err := encryptor.RotateKey(ctx)
+    require.NoError(t, err)
+    assert.EqualValues(t, 2, kv.Version)
+    assert.Len(t, encryptor.KeyVersions(), 2)
+
+    // Fields written before the rotation still decrypt with the retired key
+    decrypted, err := encryptor.DecryptFields(ctx, original)
+    require.NoError(t, err)
+    assert.Equal(t, "hunter2", decrypted["password"])
+
+    // Re-encryption moves the event to a data key under the new version
+    generated := atomic.LoadInt64(&fake.generated)
+    upgraded, err := encryptor.ReEncrypt(ctx, original)
+    require.NoError(t, err)
+    assert.NotEqual(t, original["password"], upgraded["password"])
+    assert.Equal(t, original["severity"], upgraded["severity"])
+    assert.Equal(t, generated+1, atomic.LoadInt64(&fake.generated))
+
+    // Once retention passes only upgraded fields remain readable
+    require.NoError(t, encryptor.SetKeyRetention(time.Nanosecond))
+    time.Sleep(time.Millisecond)
+    _, err = encryptor.DecryptFields(ctx, original)
+    assert.Error(t, err)
+
+    decrypted, err = encryptor.DecryptFields(ctx, upgraded)
+    require.NoError(t, err)
+    assert.Equal(t, "hunter2", decrypted["password"])
+}
+
+// TestFieldKeyringSurvivesRestart verifies a new encryptor sharing the key
+// store decrypts fields written under every earlier key version
+func TestFieldKeyringSurvivesRestart(t *testing.T) {
+    store := newTestKeyStore(t)
+    ctx := context.Background()
+
+    fake := &fakeKMS{}
+    newEncryptor := func() *encryption.FieldEncryptor {
+        manager, err := encryption.NewKMSManager(fake, "alias/blackpoint-test")
+        require.NoError(t, err)
+        encryptor, err := encryption.NewFieldEncryptor(manager, nil, encryption.FieldEncryptorOptions{
+            DeterministicFields: []string{"email"},
+            KeyStore:            store,
+        })
+        require.NoError(t, err)
+        return encryptor
+    }
+
+    before := newEncryptor()
+    v1, err := before.EncryptFields(ctx, tenantEvent(testClientID))
+    require.NoError(t, err)
+    _, err = before.RotateKey(ctx)
+    require.NoError(t, err)
+    v2, err := before.EncryptFields(ctx, tenantEvent(testClientID))
+    require.NoError(t, err)
+
+    // The restarted process loads both versions and keeps writing with v2
+    after := newEncryptor()
+    versions := after.KeyVersions()
+    require.Len(t, versions, 2)
+    assert.False(t, versions[0].RetiredAt.IsZero())
+    assert.True(t, versions[1].RetiredAt.IsZero())
+
+    for name, event := range map[string]map[string]interface{}{"v1": v1, "v2": v2} {
+        decrypted, err := after.DecryptFields(ctx, event)
+        require.NoError(t, err, name)
+        assert.Equal(t, "hunter2", decrypted["password"], name)
+        assert.Equal(t, "analyst@example.com", decrypted["email"], name)
+    }
+
+    // Deterministic ciphertext matches across the restart
+    again, err := after.EncryptFields(ctx, tenantEvent(testClientID))
+    require.NoError(t, err)
+    assert.Equal(t, v2["email"], again["email"])
+
+    // A rotation on the restarted process continues from the stored keyring
+    kv, err := after.RotateKey(ctx)
+    require.NoError(t, err)
+    assert.EqualValues(t, 3, kv.Version)
+
+    // An encryptor without the store only knows its default key
+    isolated, _ := newTestFieldEncryptor(t)
+    _, err = isolated.DecryptFields(ctx, v2)
+    assert.Error(t, err)
+}
+
+// TestDeterministicFieldEncryption verifies deterministic fields produce the
+// same ciphertext for equal values while randomized fields never repeat
+func TestDeterministicFieldEncryption(t *testing.T) {
+    manager, err := encryption.NewKMSManager(&fakeKMS{}, "alias/blackpoint-test")
+    require.NoError(t, err)
+
+    // Without a shared master key there is nothing to keep equality stable
+    _, err = encryption.NewFieldEncryptor(manager, nil, encryption.FieldEncryptorOptions{
+        DeterministicFields: []string{"email"},
+    })
+    require.Error(t, err)
+
+    encryptor, err := encryption.NewFieldEncryptor(manager, nil, encryption.FieldEncryptorOptions{
+        DeterministicFields: []string{"email"},
+        KeyStore:            newTestKeyStore(t),
+    })
+    require.NoError(t, err)
+    ctx := context.Background()
+
+    first, err := encryptor.EncryptFields(ctx, tenantEvent(testClientID))
+    require.NoError(t, err)
+    second, err := encryptor.EncryptFields(ctx, tenantEvent(testClientID))
+    require.NoError(t, err)
+
+    // Equal values collide so they can be looked up by equality
+    assert.NotEqual(t, "analyst@example.com", first["email"])
+    assert.Equal(t, first["email"], second["email"])
+
+    // Randomized fields still differ on every encryption
+    assert.NotEqual(t, first["password"], second["password"])
+
+    // Different values and different clients do not collide
+    changed := tenantEvent(testClientID)
+    changed["email"] = "responder@example.com"
+    third, err := encryptor.EncryptFields(ctx, changed)
+    require.NoError(t, err)
+    assert.NotEqual(t, first["email"], third["email"])
+
+    otherClient, err := encryptor.EncryptFields(ctx, tenantEvent("test-client-002"))
+    require.NoError(t, err)
+    assert.NotEqual(t, first["email"], otherClient["email"])
+
+    // Deterministic fields round-trip like any other
+    decrypted, err := encryptor.DecryptFields(ctx, first)
+    require.NoError(t, err)
+    assert.Equal(t, "analyst@example.com", decrypted["email"])
+    assert.Equal(t, "hunter2", decrypted["password"])
+}
+
+// BenchmarkEncryptFieldsCachedKey measures encryption once the tenant key is cached
+// memoryQuarantine is an in-memory quarantine store
+type memoryQuarantine struct {
+    mu      sync.Mutex
+    objects map[string][]byte
+}
+
+func newMemoryQuarantine() *memoryQuarantine {
+    return &memoryQuarantine{objects: make(map[string][]byte)}
+}
+
+func (m *memoryQuarantine) PutObject(bucket, key string, data []byte) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.objects[bucket+"/"+key] = data
+    return nil
+}
+
+func (m *memoryQuarantine) GetObject(bucket, key string) ([]byte, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    data, ok := m.objects[bucket+"/"+key]
+    if !ok {
+        return nil, fmt.Errorf("object %s not found", key)
+    }
+    return data, nil
+}
+
+func (m *memoryQuarantine) ListObjects(bucket, prefix string) ([]string, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    var keys []string
+    for name := range m.objects {
+        if key := strings.TrimPrefix(name, bucket+"/"); key != name && strings.HasPrefix(key, prefix) {
+            keys = append(keys, key)
+        }
+    }
+    sort.Strings(keys)
+    return keys, nil
+}
+
+func (m *memoryQuarantine) DeleteObject(bucket, key string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    delete(m.objects, bucket+"/"+key)
+    return nil
+}
+
+// newOutageFieldEncryptor builds a field encryptor whose KMS outage handling
+// comes from its construction options
+func newOutageFieldEncryptor(t *testing.T, outage encryption.OutageConfig) (*encryption.FieldEncryptor, *fakeKMS) {
+    fake := &fakeKMS{}
+    manager, err := encryption.NewKMSManager(fake, "alias/blackpoint-test")
+    require.NoError(t, err)
+    encryptor, err := encryption.NewFieldEncryptor(manager, nil, encryption.FieldEncryptorOptions{
+        Outage: outage,
+    })
+    require.NoError(t, err)
+    t.Cleanup(encryptor.Close)
+    return encryptor, fake
+}
+
+// TestKMSOutageFailClosed verifies events are rejected while KMS is down
+// when no outage mode is configured
+func TestKMSOutageFailClosed(t *testing.T) {
+    encryptor, fake := newOutageFieldEncryptor(t, encryption.OutageConfig{})
+    ctx := context.Background()
+
+    atomic.StoreInt32(&fake.unavailable, 1)
+    _, err := encryptor.EncryptFields(ctx, tenantEvent("outage-client-001"))
+    require.Error(t, err)
+    assert.NotEqual(t, encryption.ErrEncryptionDeferred, err)
+    assert.NotEqual(t, encryption.ErrEventQuarantined, err)
+
+    atomic.StoreInt32(&fake.unavailable, 0)
+    encrypted, err := encryptor.EncryptFields(ctx, tenantEvent("outage-client-001"))
+    require.NoError(t, err)
+    assert.NotEqual(t, "hunter2", encrypted["password"])
+
+    // Unknown modes are rejected at construction
+    manager, err := encryption.NewKMSManager(fake, "alias/blackpoint-test")
+    require.NoError(t, err)
+    _, err = encryption.NewFieldEncryptor(manager, nil, encryption.FieldEncryptorOptions{
+        Outage: encryption.OutageConfig{Mode: "drop"},
+    })
+    require.Error(t, err)
+}
+
+// TestKMSOutageBufferRetry verifies buffered events are encrypted and
+// delivered once KMS recovers, and that a full buffer fails closed
+func TestKMSOutageBufferRetry(t *testing.T) {
+    delivered := make(chan map[string]interface{}, 4)
+    deliver := func(ctx context.Context, encrypted map[string]interface{}) error {
+        delivered <- encrypted
+        return nil
+    }
+
+    // A long retry interval keeps the first event in the buffer
+    encryptor, fake := newOutageFieldEncryptor(t, encryption.OutageConfig{
+        Mode:          encryption.OutageModeBufferRetry,
+        QueueSize:     1,
+        RetryInterval: time.Hour,
+        Deliver:       deliver,
+    })
+    ctx := context.Background()
+
+    atomic.StoreInt32(&fake.unavailable, 1)
+    _, err := encryptor.EncryptFields(ctx, tenantEvent("outage-client-002"))
+    assert.Equal(t, encryption.ErrEncryptionDeferred, err)
+
+    _, err = encryptor.EncryptFields(ctx, tenantEvent("outage-client-003"))
+    require.Error(t, err)
+    assert.NotEqual(t, encryption.ErrEncryptionDeferred, err, "a full buffer must reject events")
+
+    // Reconfiguring keeps the buffered event and retries it promptly
+    require.NoError(t, encryptor.SetOutageHandling(encryption.OutageConfig{
+        Mode:          encryption.OutageModeBufferRetry,
+        QueueSize:     1,
+        RetryInterval: 10 * time.Millisecond,
+        Deliver:       deliver,
+    }))
+    atomic.StoreInt32(&fake.unavailable, 0)
+
+    select {
+    case encrypted := <-delivered:
+        assert.Equal(t, "outage-client-002", encrypted["client_id"])
+        assert.NotEqual(t, "hunter2", encrypted["password"])
+
+        decrypted, err := encryptor.DecryptFields(ctx, encrypted)
+        require.NoError(t, err)
+        assert.Equal(t, "hunter2", decrypted["password"])
+    case <-time.After(5 * time.Second):
+        t.Fatal("buffered event was not delivered after KMS recovered")
+    }
+}
+
+// TestKMSOutageQuarantine verifies events are quarantined unencrypted while
+// KMS is down and re-encrypted out of quarantine once it recovers
+func TestKMSOutageQuarantine(t *testing.T) {
+    store := newMemoryQuarantine()
+    var delivered []map[string]interface{}
+    encryptor, fake := newOutageFieldEncryptor(t, encryption.OutageConfig{
+        Mode:             encryption.OutageModeQuarantine,
+        QuarantineStore:  store,
+        QuarantineBucket: "blackpoint-quarantine",
+        Deliver: func(ctx context.Context, encrypted map[string]interface{}) error {
+            delivered = append(delivered, encrypted)
+            return nil
+        },
+    })
+    ctx := context.Background()
+
+    atomic.StoreInt32(&fake.unavailable, 1)
+    _, err := encryptor.EncryptFields(ctx, tenantEvent("outage-client-004"))
+    assert.Equal(t, encryption.ErrEventQuarantined, err)
+
+    keys, err := store.ListObjects("blackpoint-quarantine", "quarantine/")
+    require.NoError(t, err)
+    require.Len(t, keys, 1)
+
+    // Recovery stops at the first KMS failure and leaves the event in place
+    recovered, err := encryptor.ReencryptQuarantined(ctx)
+    require.Error(t, err)
+    assert.Equal(t, 0, recovered)
+    assert.Empty(t, delivered)
+
+    atomic.StoreInt32(&fake.unavailable, 0)
+    recovered, err = encryptor.ReencryptQuarantined(ctx)
+    require.NoError(t, err)
+    assert.Equal(t, 1, recovered)
+    require.Len(t, delivered, 1)
+    assert.NotEqual(t, "hunter2", delivered[0]["password"])
+
+    keys, err = store.ListObjects("blackpoint-quarantine", "quarantine/")
+    require.NoError(t, err)
+    assert.Empty(t, keys, "recovered events must leave quarantine")
+}
+
+func BenchmarkEncryptFieldsCachedKey(b *testing.B) {
+    encryptor, fake := newTestFieldEncryptor(b)
+    ctx := context.Background()
+    event := tenantEvent(testClientID)
+
+    // Warm the cache so the loop only measures the hit path
+    if _, err := encryptor.EncryptFields(ctx, event); err != nil {
+        b.Fatal(err)
+    }
+
+    b.ReportAllocs()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        if _, err := encryptor.EncryptFields(ctx, event); err != nil {
+            b.Fatal(err)
+        }
+    }
+    b.StopTimer()
+
+    if calls := atomic.LoadInt64(&fake.generated); calls != 1 {
+        b.Fatalf("Expected a single KMS data key request, got %d", calls)
+    }
+}
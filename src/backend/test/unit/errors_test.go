@@ -0,0 +1,304 @@
+// Package unit provides unit tests for the error code catalog
+package unit
+
+import (
+    "context"
+    "fmt"
+    "go/ast"
+    "go/parser"
+    "go/token"
+    "net"
+    "net/http"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strconv"
+    "strings"
+    "testing"
+
+    awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+    "github.com/aws/smithy-go"
+    smithyhttp "github.com/aws/smithy-go/transport/http"
+    "github.com/confluentinc/confluent-kafka-go/kafka"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// useStrictErrorCodes makes NewError panic on unregistered codes for the
+// duration of the test
+func useStrictErrorCodes(t *testing.T) {
+    previous := errors.SetStrictErrorCodes(true)
+    t.Cleanup(func() { errors.SetStrictErrorCodes(previous) })
+}
+
+// errorCodePattern matches error code literals in source
+var errorCodePattern = regexp.MustCompile(`"(E\d{4})"`)
+
+// TestErrorCodesRegistered verifies every error code literal in the backend
+// sources outside tests has a catalog entry
+func TestErrorCodesRegistered(t *testing.T) {
+    root := filepath.Join("..", "..")
+    referenced := make(map[string][]string)
+
+    err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+        if err != nil {
+            return err
+        }
+        // Tests reference unregistered codes on purpose
+        if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+            return nil
+        }
+        data, err := os.ReadFile(path)
+        if err != nil {
+            return err
+        }
+        for _, match := range errorCodePattern.FindAllStringSubmatch(string(data), -1) {
+            referenced[match[1]] = append(referenced[match[1]], path)
+        }
+        return nil
+    })
+    require.NoError(t, err)
+    require.NotEmpty(t, referenced, "expected error codes in the backend sources")
+
+    for code, files := range referenced {
+        info, ok := errors.LookupErrorCode(code)
+        if !assert.True(t, ok, "error code %s referenced in %v is not registered", code, files) {
+            continue
+        }
+        assert.NotEmpty(t, info.Description, "error code %s has no description", code)
+        assert.NotZero(t, info.HTTPStatus, "error code %s has no HTTP status", code)
+    }
+}
+
+// stringValues collects the string literals assigned to each package-level
+// name in a file, including every value of a map literal
+func stringValues(file *ast.File, values map[string][]string) {
+    for _, decl := range file.Decls {
+        gen, ok := decl.(*ast.GenDecl)
+        if !ok || (gen.Tok != token.CONST && gen.Tok != token.VAR) {
+            continue
+        }
+        for _, spec := range gen.Specs {
+            vs := spec.(*ast.ValueSpec)
+            for i, name := range vs.Names {
+                if i >= len(vs.Values) {
+                    continue
+                }
+                switch v := vs.Values[i].(type) {
+                case *ast.BasicLit:
+                    if s, err := strconv.Unquote(v.Value); err == nil && v.Kind == token.STRING {
+                        values[name.Name] = append(values[name.Name], s)
+                    }
+                case *ast.CompositeLit:
+                    for _, elt := range v.Elts {
+                        kv, ok := elt.(*ast.KeyValueExpr)
+                        if !ok {
+                            continue
+                        }
+                        if lit, ok := kv.Value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+                            if s, err := strconv.Unquote(lit.Value); err == nil {
+                                values[name.Name] = append(values[name.Name], s)
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    }
+}
+
+// isNewErrorCall reports whether call invokes the common package's NewError
+func isNewErrorCall(call *ast.CallExpr) bool {
+    switch fn := call.Fun.(type) {
+    case *ast.Ident:
+        return fn.Name == "NewError"
+    case *ast.SelectorExpr:
+        pkg, ok := fn.X.(*ast.Ident)
+        return ok && fn.Sel.Name == "NewError" && (pkg.Name == "errors" || pkg.Name == "common")
+    }
+    return false
+}
+
+// TestNewErrorCallsRegistered resolves the code argument of every NewError
+// call across the repository, whether a literal, a constant or a code map,
+// and verifies each resolves to a catalog entry
+func TestNewErrorCallsRegistered(t *testing.T) {
+    root := filepath.Join("..", "..", "..")
+    fset := token.NewFileSet()
+    files := make(map[string]*ast.File)
+    values := make(map[string]map[string][]string)
+
+    err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+        if err != nil {
+            return err
+        }
+        if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+            return nil
+        }
+        file, err := parser.ParseFile(fset, path, nil, 0)
+        if err != nil {
+            return err
+        }
+        dir := filepath.Dir(path)
+        if values[dir] == nil {
+            values[dir] = make(map[string][]string)
+        }
+        stringValues(file, values[dir])
+        files[path] = file
+        return nil
+    })
+    require.NoError(t, err)
+
+    calls := 0
+    for path, file := range files {
+        ast.Inspect(file, func(n ast.Node) bool {
+            call, ok := n.(*ast.CallExpr)
+            if !ok || !isNewErrorCall(call) || len(call.Args) == 0 {
+                return true
+            }
+            calls++
+            pos := fset.Position(call.Pos())
+
+            var codes []string
+            switch arg := call.Args[0].(type) {
+            case *ast.BasicLit:
+                if code, err := strconv.Unquote(arg.Value); err == nil {
+                    codes = []string{code}
+                }
+            case *ast.Ident:
+                codes = values[filepath.Dir(path)][arg.Name]
+            case *ast.IndexExpr:
+                if m, ok := arg.X.(*ast.Ident); ok {
+                    codes = values[filepath.Dir(path)][m.Name]
+                }
+            }
+            if !assert.NotEmpty(t, codes, "cannot resolve the error code passed to NewError at %s", pos) {
+                return true
+            }
+            for _, code := range codes {
+                _, ok := errors.LookupErrorCode(code)
+                assert.True(t, ok, "error code %q passed to NewError at %s is not registered", code, pos)
+            }
+            return true
+        })
+    }
+    require.NotZero(t, calls, "expected NewError calls in the sources")
+}
+
+// TestUnknownErrorCodePanics verifies unregistered codes panic in strict mode
+// and fall back to the internal error code otherwise
+func TestUnknownErrorCodePanics(t *testing.T) {
+    useStrictErrorCodes(t)
+
+    assert.Panics(t, func() {
+        errors.NewError("E0000", "unregistered", nil)
+    })
+    assert.NotPanics(t, func() {
+        errors.NewError("E3001", "registered", nil)
+    })
+
+    errors.SetStrictErrorCodes(false)
+    err := errors.NewError("E0000", "unregistered", nil)
+    assert.Equal(t, "E4001", err.Code)
+}
+
+// TestErrorCodeCatalog verifies catalog metadata and retryability lookups
+func TestErrorCodeCatalog(t *testing.T) {
+    useStrictErrorCodes(t)
+
+    info, ok := errors.LookupErrorCode("E4029")
+    require.True(t, ok)
+    assert.Equal(t, http.StatusTooManyRequests, info.HTTPStatus)
+
+    tests := []struct {
+        name      string
+        err       error
+        retryable bool
+    }{
+        {"rate limited", errors.NewError("E4029", "too many requests", nil), true},
+        {"resource pressure", errors.NewError("E4002", "queue full", nil), true},
+        {"validation", errors.NewError("E3001", "bad event", nil), false},
+        {"authentication", errors.NewError("E1001", "denied", nil), false},
+        {"wrapped retryable", errors.WrapError(errors.NewError("E4029", "too many requests", nil), "publish failed", nil), true},
+        {"wrapped by fmt", fmt.Errorf("context: %w", errors.NewError("E4002", "queue full", nil)), true},
+        {"plain error", fmt.Errorf("boom"), false},
+        {"nil", nil, false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            assert.Equal(t, tt.retryable, errors.IsRetryable(tt.err))
+        })
+    }
+
+    codes := errors.ErrorCodes()
+    assert.Contains(t, codes, "E4001")
+    assert.IsIncreasing(t, codes)
+
+    // Categories match regardless of case
+    security := errors.NewError("E1004", "pattern check failed", nil)
+    assert.True(t, errors.IsErrorCode(security, "E1004", "security"))
+    assert.True(t, errors.IsErrorCode(security, "E1004", "Security"))
+    assert.False(t, errors.IsErrorCode(security, "E1004", "Data"))
+}
+
+// awsStatusError builds an AWS transport error carrying an HTTP status
+func awsStatusError(status int) error {
+    return &awshttp.ResponseError{
+        ResponseError: &smithyhttp.ResponseError{
+            Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+            Err:      fmt.Errorf("status %d", status),
+        },
+    }
+}
+
+// TestClassifyErrors verifies retry classification of wrapped service errors
+func TestClassifyErrors(t *testing.T) {
+    useStrictErrorCodes(t)
+
+    wrap := func(err error) error {
+        return errors.WrapError(err, "operation failed", map[string]interface{}{"operation": "test"})
+    }
+
+    tests := []struct {
+        name  string
+        err   error
+        class errors.ErrorClass
+    }{
+        // Transient
+        {"rate limited", errors.NewError("E4029", "too many requests", nil), errors.Transient},
+        {"wrapped resource pressure", wrap(errors.NewError("E4002", "queue full", nil)), errors.Transient},
+        {"S3 throttling", wrap(&smithy.GenericAPIError{Code: "SlowDown"}), errors.Transient},
+        {"S3 server error", wrap(awsStatusError(http.StatusServiceUnavailable)), errors.Transient},
+        {"Kafka timeout", fmt.Errorf("produce: %w", kafka.NewError(kafka.ErrTimedOut, "timed out", false)), errors.Transient},
+        {"deadline exceeded", wrap(context.DeadlineExceeded), errors.Transient},
+        {"network timeout", wrap(&net.OpError{Op: "dial", Err: timeoutErr{}}), errors.Transient},
+
+        // Permanent
+        {"authentication denied", errors.NewError("E1001", "denied", nil), errors.Permanent},
+        {"schema invalid", wrap(errors.NewError("E3001", "invalid schema", nil)), errors.Permanent},
+        {"S3 access denied", wrap(&smithy.GenericAPIError{Code: "AccessDenied"}), errors.Permanent},
+        {"S3 client error", wrap(awsStatusError(http.StatusForbidden)), errors.Permanent},
+        {"Kafka fatal", wrap(kafka.NewError(kafka.ErrFenced, "fenced", true)), errors.Permanent},
+        {"cancelled", wrap(context.Canceled), errors.Permanent},
+
+        // Unknown
+        {"plain error", fmt.Errorf("boom"), errors.Unknown},
+        {"generic internal error", errors.NewError("E4001", "internal", nil), errors.Unknown},
+        {"unrecognized AWS code", wrap(&smithy.GenericAPIError{Code: "SomethingNew"}), errors.Unknown},
+        {"nil", nil, errors.Unknown},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            assert.Equal(t, tt.class, errors.Classify(tt.err), "error: %v", tt.err)
+        })
+    }
+}
+
+// timeoutErr is a network error that timed out
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
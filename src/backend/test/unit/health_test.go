@@ -0,0 +1,179 @@
+// Package unit provides unit tests for dependency health aggregation
+package unit
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/alicebob/miniredis/v2"
+    "github.com/aws/aws-sdk-go-v2/service/s3"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "github.com/blackpoint/internal/health"
+    "github.com/blackpoint/internal/storage"
+)
+
+// healthyCheck is a dependency check that always succeeds
+func healthyCheck(ctx context.Context) error {
+    return nil
+}
+
+// failingCheck is a dependency check that always fails
+func failingCheck(ctx context.Context) error {
+    return fmt.Errorf("connection refused")
+}
+
+// hangingCheck is a dependency check that ignores its context and never returns in time
+func hangingCheck(ctx context.Context) error {
+    time.Sleep(time.Minute)
+    return nil
+}
+
+// serveReadiness registers the dependencies and returns the readiness response
+func serveReadiness(t *testing.T, deps ...health.Dependency) (int, health.Report) {
+    t.Helper()
+
+    checker := health.NewHealthChecker("normalizer", 100*time.Millisecond)
+    for _, dep := range deps {
+        require.NoError(t, checker.Register(dep))
+    }
+    mux := http.NewServeMux()
+    checker.RegisterRoutes(mux)
+
+    recorder := httptest.NewRecorder()
+    mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+    var report health.Report
+    require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+    return recorder.Code, report
+}
+
+// TestHealthCheckerStates verifies readiness across healthy, degraded and
+// unhealthy dependency combinations
+func TestHealthCheckerStates(t *testing.T) {
+    t.Run("all healthy", func(t *testing.T) {
+        code, report := serveReadiness(t,
+            health.Dependency{Name: "kafka", Check: healthyCheck, Critical: true},
+            health.Dependency{Name: "redis", Check: healthyCheck},
+            health.Dependency{Name: "s3", Check: healthyCheck},
+        )
+        assert.Equal(t, http.StatusOK, code)
+        assert.Equal(t, health.StatusHealthy, report.Status)
+        assert.Len(t, report.Dependencies, 3)
+        for name, dep := range report.Dependencies {
+            assert.Equal(t, health.StateUp, dep.State, "dependency %s", name)
+        }
+    })
+
+    t.Run("degraded", func(t *testing.T) {
+        code, report := serveReadiness(t,
+            health.Dependency{Name: "kafka", Check: healthyCheck, Critical: true},
+            health.Dependency{Name: "redis", Check: failingCheck},
+        )
+        assert.Equal(t, http.StatusOK, code)
+        assert.Equal(t, health.StatusDegraded, report.Status)
+        assert.Equal(t, health.StateDown, report.Dependencies["redis"].State)
+        assert.Contains(t, report.Dependencies["redis"].Error, "connection refused")
+    })
+
+    t.Run("unhealthy", func(t *testing.T) {
+        code, report := serveReadiness(t,
+            health.Dependency{Name: "kafka", Check: failingCheck, Critical: true},
+            health.Dependency{Name: "redis", Check: failingCheck},
+        )
+        assert.Equal(t, http.StatusServiceUnavailable, code)
+        assert.Equal(t, health.StatusUnhealthy, report.Status)
+        assert.True(t, report.Dependencies["kafka"].Critical)
+    })
+
+    t.Run("slow dependency times out", func(t *testing.T) {
+        start := time.Now()
+        code, report := serveReadiness(t,
+            health.Dependency{Name: "kafka", Check: healthyCheck, Critical: true},
+            health.Dependency{Name: "s3", Check: hangingCheck, Critical: true, Timeout: 50 * time.Millisecond},
+        )
+        assert.Less(t, time.Since(start), time.Second, "a hanging check must not hang the endpoint")
+        assert.Equal(t, http.StatusServiceUnavailable, code)
+        assert.Equal(t, health.StateUp, report.Dependencies["kafka"].State)
+        assert.Contains(t, report.Dependencies["s3"].Error, "timed out")
+    })
+
+    t.Run("processor queue saturated", func(t *testing.T) {
+        code, report := serveReadiness(t,
+            health.Dependency{Name: "kafka", Check: healthyCheck, Critical: true},
+            health.Dependency{Name: "processor_queue", Check: health.QueueDepthCheck(func() int { return 10 }, 10)},
+        )
+        assert.Equal(t, http.StatusOK, code)
+        assert.Equal(t, health.StatusDegraded, report.Status)
+    })
+}
+
+// TestHealthLiveness verifies liveness ignores dependency outages
+func TestHealthLiveness(t *testing.T) {
+    checker := health.NewHealthChecker("normalizer", 0)
+    require.NoError(t, checker.Register(health.Dependency{Name: "kafka", Check: failingCheck, Critical: true}))
+    assert.Error(t, checker.Register(health.Dependency{Name: "kafka", Check: healthyCheck}), "duplicate dependency")
+
+    mux := http.NewServeMux()
+    checker.RegisterRoutes(mux)
+    for _, path := range []string{"/health/live", "/health"} {
+        recorder := httptest.NewRecorder()
+        mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+        assert.Equal(t, http.StatusOK, recorder.Code, path)
+    }
+}
+
+// headBucketS3 answers HeadBucket for the listed buckets only
+type headBucketS3 struct {
+    storage.S3API
+    buckets map[string]bool
+}
+
+func (m *headBucketS3) HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+    if !m.buckets[*params.Bucket] {
+        return nil, fmt.Errorf("bucket %s not found", *params.Bucket)
+    }
+    return &s3.HeadBucketOutput{}, nil
+}
+
+// TestHealthStorageDependencies verifies the Redis and S3 clients report
+// outages through readiness without failing it
+func TestHealthStorageDependencies(t *testing.T) {
+    server := miniredis.RunT(t)
+    redisClient, err := storage.NewRedisClient(&storage.RedisConfig{Addresses: []string{server.Addr()}})
+    require.NoError(t, err)
+    defer redisClient.Close()
+
+    api := &headBucketS3{buckets: map[string]bool{
+        "blackpoint-bronze": true,
+        "blackpoint-silver": true,
+        "blackpoint-gold":   true,
+    }}
+    s3Client := storage.NewS3ClientWithAPI(&storage.S3Config{BucketPrefix: "blackpoint-"}, api)
+
+    deps := []health.Dependency{
+        {Name: "kafka", Check: healthyCheck, Critical: true},
+        {Name: "redis", Check: redisClient.Ping},
+        {Name: "s3", Check: s3Client.Ping},
+    }
+
+    code, report := serveReadiness(t, deps...)
+    assert.Equal(t, http.StatusOK, code)
+    assert.Equal(t, health.StatusHealthy, report.Status)
+
+    server.Close()
+    delete(api.buckets, "blackpoint-gold")
+
+    code, report = serveReadiness(t, deps...)
+    assert.Equal(t, http.StatusOK, code)
+    assert.Equal(t, health.StatusDegraded, report.Status)
+    assert.Equal(t, health.StateDown, report.Dependencies["redis"].State)
+    assert.Equal(t, health.StateDown, report.Dependencies["s3"].State)
+    assert.Contains(t, report.Dependencies["s3"].Error, "s3 ping failed")
+}
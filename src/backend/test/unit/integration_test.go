@@ -0,0 +1,590 @@
+// Package unit provides unit tests for integration configuration and credential validation
+package unit
+
+import (
+    "bytes"
+    "context"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha256"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/hex"
+    "encoding/json"
+    "encoding/pem"
+    "io"
+    "math/big"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/alicebob/miniredis/v2"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "golang.org/x/oauth2"
+
+    "github.com/blackpoint/internal/integration"
+    "github.com/blackpoint/internal/storage"
+    config "github.com/blackpoint/pkg/integration"
+)
+
+// newOktaConfig returns an otherwise valid Okta integration with the given platform settings
+func newOktaConfig(settings map[string]interface{}) *config.IntegrationConfig {
+    return &config.IntegrationConfig{
+        PlatformType: "okta",
+        Name:         "okta-production",
+        Environment:  "production",
+        Auth: config.AuthenticationConfig{
+            Type:        "apikey",
+            Credentials: map[string]interface{}{"api_key": "test-api-key-0123456789"},
+        },
+        Collection:       config.DataCollectionConfig{Mode: "realtime"},
+        PlatformSpecific: settings,
+    }
+}
+
+// findingFields returns the fields of error findings
+func findingFields(result *integration.ValidationResult) []string {
+    var fields []string
+    for _, finding := range result.Findings {
+        if finding.Severity == integration.SeverityError {
+            fields = append(fields, finding.Field)
+        }
+    }
+    return fields
+}
+
+func TestPlatformSchemaRejectsOktaWithoutDomain(t *testing.T) {
+    v := integration.NewIntegrationValidator()
+
+    result := v.Validate(context.Background(), newOktaConfig(map[string]interface{}{"rate_limit": 100}), integration.ValidationOptions{})
+    assert.False(t, result.Valid)
+    assert.Equal(t, []string{"platform_specific.domain"}, findingFields(result))
+
+    result = v.Validate(context.Background(), newOktaConfig(map[string]interface{}{
+        "domain":     "acme.okta.com",
+        "rate_limit": 100,
+    }), integration.ValidationOptions{})
+    assert.True(t, result.Valid, "unexpected findings: %v", result.Findings)
+}
+
+func TestValidationFindingSeverities(t *testing.T) {
+    v := integration.NewIntegrationValidator()
+    settings := map[string]interface{}{"domain": "acme.okta.com", "rate_limit": 100}
+
+    // A token expiry under an hour is an error regardless of options
+    cfg := newOktaConfig(settings)
+    cfg.Auth.ExpiryTime = 30 * time.Minute
+    result := v.Validate(context.Background(), cfg, integration.ValidationOptions{})
+    assert.False(t, result.Valid)
+    assert.Equal(t, []string{"auth.expiry_time"}, findingFields(result))
+
+    // A batch size outside the recommended range is a warning that blocks
+    // only when warnings are treated as errors
+    cfg = newOktaConfig(settings)
+    cfg.Collection = config.DataCollectionConfig{Mode: "batch", BatchSize: 50}
+    result = v.Validate(context.Background(), cfg, integration.ValidationOptions{})
+    assert.True(t, result.Valid, "unexpected findings: %v", result.Findings)
+    require.Len(t, result.Findings, 1)
+    assert.Equal(t, "collection.batch_size", result.Findings[0].Field)
+    assert.Equal(t, integration.SeverityWarning, result.Findings[0].Severity)
+
+    result = v.Validate(context.Background(), cfg, integration.ValidationOptions{WarningsAsErrors: true})
+    assert.False(t, result.Valid)
+    assert.Len(t, result.Errors, 1)
+}
+
+func TestPlatformSchemaNamesNestedFieldPaths(t *testing.T) {
+    v := integration.NewIntegrationValidator()
+
+    result := v.Validate(context.Background(), newOktaConfig(map[string]interface{}{
+        "domain":      "acme.example.com",
+        "rate_limit":  0,
+        "event_types": []interface{}{"user.session.start", ""},
+    }), integration.ValidationOptions{})
+    assert.False(t, result.Valid)
+    assert.ElementsMatch(t, []string{
+        "platform_specific.domain",
+        "platform_specific.event_types[1]",
+        "platform_specific.rate_limit",
+    }, findingFields(result))
+}
+
+func TestRegisterPlatformSchema(t *testing.T) {
+    v := integration.NewIntegrationValidator()
+
+    require.NoError(t, v.RegisterPlatformSchema("okta", json.RawMessage(`{
+        "type": "object",
+        "required": ["domain", "org_id"],
+        "additionalProperties": false,
+        "properties": {
+            "domain": {"type": "string"},
+            "org_id": {"type": "string"}
+        }
+    }`)))
+
+    result := v.Validate(context.Background(), newOktaConfig(map[string]interface{}{
+        "domain": "acme.okta.com",
+        "region": "us",
+    }), integration.ValidationOptions{})
+    assert.ElementsMatch(t, []string{"platform_specific.org_id", "platform_specific.region"}, findingFields(result))
+
+    assert.Error(t, v.RegisterPlatformSchema("okta", json.RawMessage(`{"type": "widget"}`)))
+    assert.Error(t, v.RegisterPlatformSchema("salesforce", json.RawMessage(`{"type": "object"}`)))
+}
+
+func TestRegisterPlatformSchemaRejectsUnsupportedKeywords(t *testing.T) {
+    v := integration.NewIntegrationValidator()
+
+    unsupported := map[string]string{
+        "format":          `{"type": "object", "properties": {"domain": {"type": "string", "format": "hostname"}}}`,
+        "maxLength":       `{"type": "object", "properties": {"domain": {"type": "string", "maxLength": 64}}}`,
+        "oneOf":           `{"type": "object", "oneOf": [{"required": ["domain"]}, {"required": ["org_id"]}]}`,
+        "$ref":            `{"type": "object", "properties": {"domain": {"$ref": "#/definitions/domain"}}}`,
+        "nested in items": `{"type": "object", "properties": {"event_types": {"type": "array", "items": {"type": "string", "maxLength": 32}}}}`,
+    }
+    for name, schema := range unsupported {
+        err := v.RegisterPlatformSchema("okta", json.RawMessage(schema))
+        assert.Error(t, err, name)
+    }
+
+    // The built-in schema is still in force after the rejected registrations
+    result := v.Validate(context.Background(), newOktaConfig(map[string]interface{}{}), integration.ValidationOptions{})
+    assert.Contains(t, findingFields(result), "platform_specific.domain")
+
+    // Annotations do not constrain values and are accepted
+    require.NoError(t, v.RegisterPlatformSchema("okta", json.RawMessage(`{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": "Okta settings",
+        "type": "object",
+        "properties": {
+            "domain": {"type": "string", "description": "Okta org domain", "examples": ["acme.okta.com"]}
+        }
+    }`)))
+}
+
+// writeTestClientCert writes a self-signed client certificate and key valid until notAfter
+func writeTestClientCert(t *testing.T, notAfter time.Time) (string, string) {
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    require.NoError(t, err)
+    template := &x509.Certificate{
+        SerialNumber: big.NewInt(1),
+        Subject:      pkix.Name{CommonName: "blackpoint-collector"},
+        NotBefore:    notAfter.Add(-48 * time.Hour),
+        NotAfter:     notAfter,
+        ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+    }
+    certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+    require.NoError(t, err)
+    keyDER, err := x509.MarshalECPrivateKey(key)
+    require.NoError(t, err)
+
+    dir := t.TempDir()
+    certPath := filepath.Join(dir, "client.crt")
+    keyPath := filepath.Join(dir, "client.key")
+    require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0644))
+    require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+    return certPath, keyPath
+}
+
+// validateAuth validates an otherwise valid Okta config using the given credentials
+func validateAuth(ctx context.Context, auth config.AuthenticationConfig, opts integration.ValidationOptions) *integration.ValidationResult {
+    cfg := newOktaConfig(map[string]interface{}{"domain": "acme.okta.com"})
+    cfg.Auth = auth
+    return integration.NewIntegrationValidator().Validate(ctx, cfg, opts)
+}
+
+// TestCredentialValidation verifies malformed credentials are rejected per auth
+// type without their values leaking into findings or errors
+func TestCredentialValidation(t *testing.T) {
+    validCert, validKey := writeTestClientCert(t, time.Now().Add(24*time.Hour))
+    expiredCert, expiredKey := writeTestClientCert(t, time.Now().Add(-time.Hour))
+
+    tests := []struct {
+        name        string
+        authType    string
+        credentials map[string]interface{}
+        valid       bool
+        secret      string
+    }{
+        {"apikey well-formed", "apikey", map[string]interface{}{"api_key": "0123456789abcdef0123"}, true, ""},
+        {"apikey too short", "apikey", map[string]interface{}{"api_key": "shortkey99"}, false, "shortkey99"},
+        {"apikey with whitespace", "apikey", map[string]interface{}{"api_key": "0123456789 abcdef 0123"}, false, "abcdef"},
+        {"oauth2 well-formed", "oauth2", map[string]interface{}{
+            "client_id": "client-abc", "client_secret": "s3cr3t-value", "token_url": "https://acme.okta.com/oauth2/v1/token",
+        }, true, ""},
+        {"oauth2 missing secret", "oauth2", map[string]interface{}{
+            "client_id": "client-abc", "token_url": "https://acme.okta.com/oauth2/v1/token",
+        }, false, ""},
+        {"oauth2 padded secret", "oauth2", map[string]interface{}{
+            "client_id": "client-abc", "client_secret": " s3cr3t-value ", "token_url": "https://acme.okta.com/oauth2/v1/token",
+        }, false, "s3cr3t-value"},
+        {"basic well-formed", "basic", map[string]interface{}{"username": "svc-blackpoint", "password": "hunter2-long"}, true, ""},
+        {"basic missing password", "basic", map[string]interface{}{"username": "svc-blackpoint"}, false, ""},
+        {"hmac well-formed", "hmac", map[string]interface{}{
+            "key_id": "key-1", "secret": strings.Repeat("k", 32),
+        }, true, ""},
+        {"hmac short secret", "hmac", map[string]interface{}{"key_id": "key-1", "secret": "tooshortsecret"}, false, "tooshortsecret"},
+        {"mtls well-formed", "mtls", map[string]interface{}{"cert_path": validCert, "key_path": validKey}, true, ""},
+        {"mtls unparseable certificate", "mtls", map[string]interface{}{"cert_path": validKey, "key_path": validKey}, false, ""},
+        {"mtls expired certificate", "mtls", map[string]interface{}{"cert_path": expiredCert, "key_path": expiredKey}, false, ""},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            result := validateAuth(context.Background(), config.AuthenticationConfig{
+                Type:        tt.authType,
+                Credentials: tt.credentials,
+            }, integration.ValidationOptions{})
+
+            assert.Equal(t, tt.valid, result.Valid, "findings: %v", result.Findings)
+            if tt.valid {
+                return
+            }
+            assert.Contains(t, findingFields(result), "auth.credentials")
+            if tt.secret != "" {
+                for _, finding := range result.Findings {
+                    assert.NotContains(t, finding.Message, tt.secret)
+                    assert.NotContains(t, finding.Error().Error(), tt.secret)
+                }
+            }
+        })
+    }
+}
+
+// TestHMACAuthenticatorRoundTrip verifies that HMAC credentials in the shape the
+// CLI writes them build an authenticator whose signatures verify
+func TestHMACAuthenticatorRoundTrip(t *testing.T) {
+    secret := strings.Repeat("k", 32)
+    var credentials map[string]interface{}
+    require.NoError(t, json.Unmarshal([]byte(`{"key_id": "key-1", "secret": "`+secret+`"}`), &credentials))
+
+    auth, err := config.NewAuthenticator(config.AuthenticationConfig{Type: "hmac", Credentials: credentials})
+    require.NoError(t, err)
+    require.NoError(t, auth.Validate())
+
+    body := []byte(`{"filter":"eventType eq \"user.session.start\""}`)
+    req := httptest.NewRequest(http.MethodPost, "https://acme.okta.com/api/v1/logs?limit=100", bytes.NewReader(body))
+    require.NoError(t, auth.Authenticate(req))
+    assert.Equal(t, "key-1", req.Header.Get("X-Key-Id"))
+
+    // The body is restored so the signed request can still be sent
+    sent, err := io.ReadAll(req.Body)
+    require.NoError(t, err)
+    assert.Equal(t, body, sent)
+
+    bodyHash := sha256.Sum256(body)
+    mac := hmac.New(sha256.New, []byte(secret))
+    io.WriteString(mac, strings.Join([]string{
+        http.MethodPost,
+        "/api/v1/logs?limit=100",
+        req.Header.Get("X-Timestamp"),
+        hex.EncodeToString(bodyHash[:]),
+    }, "\n"))
+    signature, err := hex.DecodeString(req.Header.Get("X-Signature"))
+    require.NoError(t, err)
+    assert.True(t, hmac.Equal(mac.Sum(nil), signature), "signature does not verify")
+
+    // The CLI's OAuth2 field names are not HMAC credentials
+    auth, err = config.NewAuthenticator(config.AuthenticationConfig{Type: "hmac", Credentials: map[string]interface{}{
+        "client_id": "key-1", "client_secret": secret,
+    }})
+    require.NoError(t, err)
+    assert.Error(t, auth.Validate())
+}
+
+// TestCredentialConnectivity verifies the opt-in live check fetches an oauth2 token
+func TestCredentialConnectivity(t *testing.T) {
+    var requests int
+    server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        if _, secret, _ := r.BasicAuth(); secret != "correct-secret" {
+            w.WriteHeader(http.StatusUnauthorized)
+            w.Write([]byte(`{"error":"invalid_client"}`))
+            return
+        }
+        w.Header().Set("Content-Type", "application/json")
+        w.Write([]byte(`{"access_token":"token","token_type":"bearer","expires_in":3600}`))
+    }))
+    defer server.Close()
+
+    ctx := context.WithValue(context.Background(), oauth2.HTTPClient, server.Client())
+    oauthConfig := func(secret string) config.AuthenticationConfig {
+        return config.AuthenticationConfig{
+            Type: "oauth2",
+            Credentials: map[string]interface{}{
+                "client_id": "client-abc", "client_secret": secret, "token_url": server.URL + "/token",
+            },
+        }
+    }
+
+    // Without the option no call is made
+    result := validateAuth(ctx, oauthConfig("wrong-secret"), integration.ValidationOptions{})
+    assert.True(t, result.Valid)
+    assert.Zero(t, requests)
+
+    opts := integration.ValidationOptions{ValidateConnectivity: true}
+    result = validateAuth(ctx, oauthConfig("wrong-secret"), opts)
+    assert.False(t, result.Valid)
+    assert.Equal(t, []string{"auth.credentials"}, findingFields(result))
+    assert.NotContains(t, result.Errors[0].Error(), "wrong-secret")
+
+    result = validateAuth(ctx, oauthConfig("correct-secret"), opts)
+    assert.True(t, result.Valid, "findings: %v", result.Findings)
+
+    // Auth types without a live check are reported, not rejected
+    result = validateAuth(ctx, config.AuthenticationConfig{
+        Type:        "apikey",
+        Credentials: map[string]interface{}{"api_key": "0123456789abcdef0123"},
+    }, opts)
+    assert.True(t, result.Valid)
+    require.Len(t, result.Findings, 1)
+    assert.Equal(t, integration.SeverityInfo, result.Findings[0].Severity)
+}
+
+// redirectTransport sends every request to a test server regardless of host
+type redirectTransport struct {
+    target *url.URL
+    base   http.RoundTripper
+}
+
+func (r *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+    req = req.Clone(req.Context())
+    req.URL.Scheme = r.target.Scheme
+    req.URL.Host = r.target.Host
+    return r.base.RoundTrip(req)
+}
+
+// TestProbeConnectivity verifies probes report auth status per probe method
+func TestProbeConnectivity(t *testing.T) {
+    var paths []string
+    server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        paths = append(paths, r.URL.Path)
+        switch {
+        case r.URL.Path == "/api/v1/users" && r.Header.Get("Authorization") == "SSWS 0123456789abcdef0123":
+            w.Write([]byte(`[]`))
+        case r.URL.Path == "/oauth2/v1/token":
+            w.WriteHeader(http.StatusBadRequest)
+            w.Write([]byte(`{"error":"invalid_client"}`))
+        default:
+            w.WriteHeader(http.StatusUnauthorized)
+        }
+    }))
+    defer server.Close()
+
+    target, err := url.Parse(server.URL)
+    require.NoError(t, err)
+    v := integration.NewIntegrationValidator()
+    v.SetProbeHTTPClient(&http.Client{Transport: &redirectTransport{target: target, base: server.Client().Transport}})
+
+    apiKey := func(key string) config.AuthenticationConfig {
+        return config.AuthenticationConfig{
+            Type:        "apikey",
+            Credentials: map[string]interface{}{"api_key": key, "prefix": "SSWS"},
+        }
+    }
+
+    // Okta API keys are checked with a single-record user list
+    cfg := newOktaConfig(map[string]interface{}{"domain": "acme.okta.com"})
+    cfg.Auth = apiKey("0123456789abcdef0123")
+    result, err := v.ProbeConnectivity(context.Background(), cfg)
+    require.NoError(t, err)
+    assert.True(t, result.Authenticated())
+    assert.Equal(t, integration.ProbeMethodList, result.Method)
+    assert.Equal(t, "https://acme.okta.com/api/v1/users?limit=1", result.Endpoint)
+    assert.Equal(t, http.StatusOK, result.StatusCode)
+    assert.Positive(t, result.Latency)
+
+    cfg.Auth = apiKey("fedcba9876543210fedc")
+    result, err = v.ProbeConnectivity(context.Background(), cfg)
+    require.NoError(t, err)
+    assert.Equal(t, integration.ProbeRejected, result.AuthStatus)
+    assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+
+    // OAuth2 credentials are checked with a token fetch, without retries
+    paths = nil
+    cfg.Auth = config.AuthenticationConfig{
+        Type: "oauth2",
+        Credentials: map[string]interface{}{
+            "client_id": "client-abc", "client_secret": "wrong-secret", "token_url": "https://acme.okta.com/oauth2/v1/token",
+        },
+    }
+    result, err = v.ProbeConnectivity(context.Background(), cfg)
+    require.NoError(t, err)
+    assert.Equal(t, integration.ProbeMethodToken, result.Method)
+    assert.Equal(t, integration.ProbeRejected, result.AuthStatus)
+    assert.NotContains(t, result.Message, "wrong-secret")
+    assert.LessOrEqual(t, len(paths), 2, "token fetch should not be retried beyond auth style detection")
+
+    // Platforms without a probe for the auth type are reported as errors
+    cfg = newOktaConfig(nil)
+    cfg.PlatformType = "aws"
+    cfg.Auth = apiKey("0123456789abcdef0123")
+    _, err = v.ProbeConnectivity(context.Background(), cfg)
+    assert.Error(t, err)
+}
+
+// TestProbeConnectivityRejectsInternalTargets verifies probes validate the
+// configuration and only call allowlisted platform hosts
+func TestProbeConnectivityRejectsInternalTargets(t *testing.T) {
+    var requests int
+    server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        w.Write([]byte(`[]`))
+    }))
+    defer server.Close()
+
+    target, err := url.Parse(server.URL)
+    require.NoError(t, err)
+    v := integration.NewIntegrationValidator()
+    v.SetProbeHTTPClient(&http.Client{Transport: &redirectTransport{target: target, base: server.Client().Transport}})
+
+    // Settings failing the platform schema are rejected before any request
+    cfg := newOktaConfig(map[string]interface{}{"domain": "169.254.169.254"})
+    _, err = v.ProbeConnectivity(context.Background(), cfg)
+    assert.Error(t, err)
+
+    // Token endpoints must be on a host allowlisted for the platform
+    for _, tokenURL := range []string{
+        "https://169.254.169.254/latest/meta-data",
+        "https://localhost/token",
+        "https://acme.okta.com.attacker.io/token",
+        "http://acme.okta.com/oauth2/v1/token",
+        "https://acme.okta.com:8443/oauth2/v1/token",
+    } {
+        cfg = newOktaConfig(map[string]interface{}{"domain": "acme.okta.com"})
+        cfg.Auth = config.AuthenticationConfig{
+            Type: "oauth2",
+            Credentials: map[string]interface{}{
+                "client_id": "client-abc", "client_secret": "secret-abc", "token_url": tokenURL,
+            },
+        }
+        _, err = v.ProbeConnectivity(context.Background(), cfg)
+        assert.Error(t, err, "token URL %s should be rejected", tokenURL)
+    }
+    assert.Zero(t, requests, "rejected probes must not send requests")
+}
+
+// fakeVersionPlatform records whether it is collecting
+type fakeVersionPlatform struct {
+    collecting bool
+}
+
+func (f *fakeVersionPlatform) Initialize(ctx context.Context, cfg *config.IntegrationConfig) error {
+    return nil
+}
+
+func (f *fakeVersionPlatform) StartCollection(ctx context.Context) error {
+    f.collecting = true
+    return nil
+}
+
+func (f *fakeVersionPlatform) StopCollection(ctx context.Context) error {
+    f.collecting = false
+    return nil
+}
+
+func (f *fakeVersionPlatform) GetStatus(ctx context.Context) (*config.PlatformStatus, error) {
+    return &config.PlatformStatus{PlatformType: "okta", Status: "healthy"}, nil
+}
+
+// TestIntegrationVersionCutover tests that blue-green deployments stage the
+// inactive colour on its own platform instance, move collection on cutover
+// and alternate colours across deployments
+func TestIntegrationVersionCutover(t *testing.T) {
+    var instances []*fakeVersionPlatform
+    require.NoError(t, integration.GetRegistry().RegisterPlatform("okta", func() (config.Platform, error) {
+        p := &fakeVersionPlatform{}
+        instances = append(instances, p)
+        return p, nil
+    }))
+
+    ctx := context.Background()
+    manager := integration.GetManager()
+    cfg := newOktaConfig(map[string]interface{}{"domain": "acme.okta.com"})
+
+    id, err := manager.DeployIntegration(ctx, cfg)
+    require.NoError(t, err)
+    defer manager.StopIntegration(ctx, id)
+
+    versions, err := manager.ListVersions(ctx, id)
+    require.NoError(t, err)
+    assert.Equal(t, integration.VersionBlue, versions.Active)
+    blue := instances[len(instances)-1]
+    assert.True(t, blue.collecting)
+
+    // Only the inactive colour can be staged
+    assert.Error(t, manager.DeployVersion(ctx, id, integration.VersionBlue, cfg))
+    require.NoError(t, manager.DeployVersion(ctx, id, integration.VersionGreen, cfg))
+    green := instances[len(instances)-1]
+    assert.NotSame(t, blue, green, "candidate must not share the active platform instance")
+    assert.False(t, green.collecting, "a staged candidate must not collect before cutover")
+
+    status, err := manager.GetVersionStatus(ctx, id, integration.VersionGreen)
+    require.NoError(t, err)
+    assert.Equal(t, "healthy", status.Status)
+
+    require.NoError(t, manager.Cutover(ctx, id, integration.VersionGreen))
+    assert.True(t, green.collecting)
+    assert.False(t, blue.collecting)
+
+    versions, err = manager.ListVersions(ctx, id)
+    require.NoError(t, err)
+    assert.Equal(t, integration.VersionGreen, versions.Active)
+    assert.Len(t, versions.Versions, 2)
+
+    // The replaced version can be removed; the active one cannot
+    assert.Error(t, manager.RemoveVersion(ctx, id, integration.VersionGreen))
+    require.NoError(t, manager.RemoveVersion(ctx, id, integration.VersionBlue))
+    _, err = manager.GetVersionStatus(ctx, id, integration.VersionBlue)
+    assert.Error(t, err)
+
+    // The next deployment stages blue again
+    assert.Error(t, manager.DeployVersion(ctx, id, integration.VersionGreen, cfg))
+    require.NoError(t, manager.DeployVersion(ctx, id, integration.VersionBlue, cfg))
+    assert.Error(t, manager.Cutover(ctx, id, integration.VersionGreen))
+    require.NoError(t, manager.Cutover(ctx, id, integration.VersionBlue))
+
+    versions, err = manager.ListVersions(ctx, id)
+    require.NoError(t, err)
+    assert.Equal(t, integration.VersionBlue, versions.Active)
+}
+
+// TestValidatorCacheInvalidationAcrossReplicas verifies a change announced by
+// one replica drops the validation results another replica cached
+func TestValidatorCacheInvalidationAcrossReplicas(t *testing.T) {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    server := miniredis.RunT(t)
+    newReplica := func() *integration.IntegrationValidator {
+        client, err := storage.NewRedisClient(&storage.RedisConfig{Addresses: []string{server.Addr()}})
+        require.NoError(t, err)
+        t.Cleanup(func() { client.Close() })
+
+        v := integration.NewIntegrationValidator()
+        require.NoError(t, v.SubscribeInvalidations(ctx, client))
+        return v
+    }
+    replica, other := newReplica(), newReplica()
+
+    valid := newOktaConfig(map[string]interface{}{"domain": "acme.okta.com"})
+    require.True(t, replica.Validate(ctx, valid, integration.ValidationOptions{}).Valid)
+
+    // The same integration changed elsewhere is served from the cache until
+    // the change is announced
+    changed := newOktaConfig(map[string]interface{}{})
+    require.True(t, replica.Validate(ctx, changed, integration.ValidationOptions{}).Valid, "expected a cached result")
+
+    other.AnnounceChange(ctx, "okta")
+    require.Eventually(t, func() bool {
+        return !replica.Validate(ctx, changed, integration.ValidationOptions{}).Valid
+    }, 5*time.Second, 10*time.Millisecond, "the announced change did not invalidate the cache")
+}
@@ -0,0 +1,321 @@
+// Package unit provides unit tests for graceful completion of in-flight work
+package unit
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "github.com/blackpoint/internal/lifecycle"
+)
+
+// TestWorkerPoolShutdown verifies shutdown waits for in-flight analysis up to
+// the deadline and that no new work is taken once intake stops
+func TestWorkerPoolShutdown(t *testing.T) {
+    t.Run("waits for in-flight work", func(t *testing.T) {
+        const taskDuration = 300 * time.Millisecond
+
+        var started, completed atomic.Int64
+        running := make(chan struct{}, 2)
+        pool, err := lifecycle.NewWorkerPool(lifecycle.WorkerPoolConfig{Workers: 2}, func(ctx context.Context) {
+            started.Add(1)
+            running <- struct{}{}
+            time.Sleep(taskDuration)
+            completed.Add(1)
+        })
+        require.NoError(t, err)
+        pool.Start(context.Background())
+
+        <-running
+        <-running
+        stopAt := time.Now()
+
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        require.NoError(t, pool.Wait(ctx))
+
+        assert.Less(t, time.Since(stopAt), taskDuration+time.Second, "shutdown must not wait longer than the work")
+        assert.Equal(t, int64(2), started.Load(), "no task may start after intake stops")
+        assert.Equal(t, started.Load(), completed.Load(), "in-flight tasks must complete")
+    })
+
+    t.Run("times out on stuck work", func(t *testing.T) {
+        cancelled := make(chan struct{})
+        running := make(chan struct{})
+        pool, err := lifecycle.NewWorkerPool(lifecycle.WorkerPoolConfig{Workers: 1}, func(ctx context.Context) {
+            close(running)
+            <-ctx.Done()
+            close(cancelled)
+        })
+        require.NoError(t, err)
+        pool.Start(context.Background())
+        <-running
+
+        ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+        defer cancel()
+        start := time.Now()
+        err = pool.Wait(ctx)
+        require.Error(t, err)
+        assert.ErrorIs(t, err, context.DeadlineExceeded)
+        assert.Less(t, time.Since(start), time.Second, "shutdown must be bounded by its context")
+
+        select {
+        case <-cancelled:
+        case <-time.After(time.Second):
+            t.Fatal("Expected stuck task context to be cancelled after the deadline")
+        }
+    })
+
+    t.Run("standby workers take no work", func(t *testing.T) {
+        var started atomic.Int64
+        pool, err := lifecycle.NewWorkerPool(lifecycle.WorkerPoolConfig{
+            Workers:         2,
+            Standby:         func() bool { return true },
+            StandbyInterval: time.Hour,
+        }, func(ctx context.Context) {
+            started.Add(1)
+        })
+        require.NoError(t, err)
+        pool.Start(context.Background())
+
+        ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+        defer cancel()
+        require.NoError(t, pool.Wait(ctx), "idle standbys must stop without waiting out their poll interval")
+        assert.Zero(t, started.Load())
+    })
+}
+
+// fakeLeaseStore is an in-memory lease store whose renewals can be made to
+// fail or to find the lease taken by another replica
+type fakeLeaseStore struct {
+    mu       sync.Mutex
+    holder   string
+    renewErr error
+    released bool
+}
+
+func (f *fakeLeaseStore) AcquireLease(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if f.holder != "" && f.holder != holder {
+        return false, nil
+    }
+    f.holder = holder
+    return true, nil
+}
+
+func (f *fakeLeaseStore) RenewLease(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if f.renewErr != nil {
+        return false, f.renewErr
+    }
+    return f.holder == holder, nil
+}
+
+func (f *fakeLeaseStore) ReleaseLease(ctx context.Context, key, holder string) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if f.holder == holder {
+        f.holder = ""
+    }
+    f.released = true
+    return nil
+}
+
+// set replaces the lease holder and renewal error
+func (f *fakeLeaseStore) set(holder string, renewErr error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.holder, f.renewErr = holder, renewErr
+}
+
+// newTestElector starts an elector on store with short lease timings, its
+// transitions reported to scope
+func newTestElector(t *testing.T, store *fakeLeaseStore, scope *lifecycle.LeaderScope, identity string) *lifecycle.LeaderElector {
+    t.Helper()
+    elector, err := lifecycle.NewLeaderElector(store, lifecycle.LeaderConfig{
+        Election:      "test-correlator",
+        Identity:      identity,
+        LeaseDuration: 300 * time.Millisecond,
+        RenewInterval: 20 * time.Millisecond,
+        RetryInterval: 20 * time.Millisecond,
+        OnElected:     scope.Elected,
+        OnDemoted:     scope.Demoted,
+    })
+    require.NoError(t, err)
+    elector.Start(context.Background())
+    return elector
+}
+
+// TestLeaderElection verifies leadership is acquired, lost on renewal
+// failure or takeover, and that work bound to it is cancelled on demotion
+func TestLeaderElection(t *testing.T) {
+    t.Run("acquires and releases the lease", func(t *testing.T) {
+        store := &fakeLeaseStore{}
+        scope := &lifecycle.LeaderScope{}
+        elector := newTestElector(t, store, scope, "replica-a")
+
+        require.Eventually(t, elector.IsLeader, time.Second, 10*time.Millisecond)
+        _, release, leading := scope.Context(context.Background())
+        release()
+        assert.True(t, leading, "elected replica should run leader work")
+
+        ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+        defer cancel()
+        require.NoError(t, elector.Stop(ctx))
+        assert.False(t, elector.IsLeader())
+        assert.True(t, store.released, "stopping should release the lease")
+        _, release, leading = scope.Context(context.Background())
+        release()
+        assert.False(t, leading, "stopped replica must not run leader work")
+    })
+
+    t.Run("standby waits for the lease", func(t *testing.T) {
+        store := &fakeLeaseStore{holder: "replica-b"}
+        scope := &lifecycle.LeaderScope{}
+        elector := newTestElector(t, store, scope, "replica-a")
+        defer elector.Stop(context.Background())
+
+        time.Sleep(100 * time.Millisecond)
+        assert.False(t, elector.IsLeader())
+        _, release, leading := scope.Context(context.Background())
+        release()
+        assert.False(t, leading)
+
+        store.set("", nil)
+        require.Eventually(t, elector.IsLeader, time.Second, 10*time.Millisecond)
+    })
+
+    demotions := map[string]func(store *fakeLeaseStore){
+        "renewal failure": func(store *fakeLeaseStore) {
+            store.set("replica-a", fmt.Errorf("lease store unavailable"))
+        },
+        "lease taken over": func(store *fakeLeaseStore) {
+            store.set("replica-b", nil)
+        },
+    }
+    for name, demote := range demotions {
+        t.Run("demotes on "+name, func(t *testing.T) {
+            store := &fakeLeaseStore{}
+            scope := &lifecycle.LeaderScope{}
+            elector := newTestElector(t, store, scope, "replica-a")
+            defer elector.Stop(context.Background())
+            require.Eventually(t, elector.IsLeader, time.Second, 10*time.Millisecond)
+
+            // In-flight work is cancelled on demotion rather than finished
+            workCtx, release, leading := scope.Context(context.Background())
+            defer release()
+            require.True(t, leading)
+
+            demote(store)
+            select {
+            case <-workCtx.Done():
+            case <-time.After(time.Second):
+                t.Fatal("Expected in-flight leader work to be cancelled on demotion")
+            }
+            require.Eventually(t, func() bool { return !elector.IsLeader() }, time.Second, 10*time.Millisecond)
+            _, release, leading = scope.Context(context.Background())
+            release()
+            assert.False(t, leading, "demoted replica must not start leader work")
+        })
+    }
+}
+
+// TestCoordinatorShutdown verifies stages stop in order, a stuck component is
+// abandoned at its stage's timeout and later stages still run with their own
+// budget when the context is sized with Timeout
+func TestCoordinatorShutdown(t *testing.T) {
+    t.Run("stage order", func(t *testing.T) {
+        coordinator := lifecycle.NewCoordinator("test")
+        var mu sync.Mutex
+        var order []string
+        record := func(name string) lifecycle.StopFunc {
+            return func(ctx context.Context) error {
+                mu.Lock()
+                defer mu.Unlock()
+                order = append(order, name)
+                return nil
+            }
+        }
+
+        // Registered out of order; stages still stop intake first, storage last
+        coordinator.Register(lifecycle.StageStorage, "storage", record("storage"))
+        coordinator.Register(lifecycle.StageProducer, "producer", record("producer"))
+        coordinator.Register(lifecycle.StageIntake, "consumer", record("consumer"))
+        coordinator.Register(lifecycle.StageProcessing, "workers", record("workers"))
+        coordinator.Register(lifecycle.StageIntake, "server", record("server"))
+
+        require.NoError(t, coordinator.Shutdown(context.Background()))
+        assert.Equal(t, []string{"consumer", "server", "workers", "producer", "storage"}, order)
+        for _, status := range coordinator.Status() {
+            assert.Equal(t, lifecycle.StateStopped, status.State, status.Component)
+        }
+    })
+
+    t.Run("per-stage timeout", func(t *testing.T) {
+        coordinator := lifecycle.NewCoordinator("test")
+        coordinator.SetStageTimeout(lifecycle.StageProcessing, 50*time.Millisecond)
+
+        stuck := make(chan struct{})
+        defer close(stuck)
+        coordinator.Register(lifecycle.StageProcessing, "stuck", func(ctx context.Context) error {
+            <-stuck
+            return nil
+        })
+
+        start := time.Now()
+        err := coordinator.Shutdown(context.Background())
+        require.Error(t, err)
+        assert.Less(t, time.Since(start), time.Second, "the stuck component must be abandoned at the stage timeout")
+
+        statuses := coordinator.Status()
+        require.Len(t, statuses, 1)
+        assert.Equal(t, lifecycle.StateTimedOut, statuses[0].State)
+    })
+
+    t.Run("flush after slow stage", func(t *testing.T) {
+        coordinator := lifecycle.NewCoordinator("test")
+        coordinator.SetStageTimeout(lifecycle.StageIntake, 50*time.Millisecond)
+        coordinator.SetStageTimeout(lifecycle.StageProcessing, 100*time.Millisecond)
+        coordinator.SetStageTimeout(lifecycle.StageProducer, 100*time.Millisecond)
+        coordinator.SetStageTimeout(lifecycle.StageStorage, 50*time.Millisecond)
+        assert.Equal(t, 300*time.Millisecond, coordinator.Timeout())
+
+        // Processing uses its whole budget, then the producer needs most of its own
+        stuck := make(chan struct{})
+        defer close(stuck)
+        coordinator.Register(lifecycle.StageProcessing, "slow_workers", func(ctx context.Context) error {
+            <-stuck
+            return nil
+        })
+        var flushed int32
+        coordinator.Register(lifecycle.StageProducer, "producer", func(ctx context.Context) error {
+            select {
+            case <-time.After(80 * time.Millisecond):
+                atomic.StoreInt32(&flushed, 1)
+                return nil
+            case <-ctx.Done():
+                return ctx.Err()
+            }
+        })
+
+        ctx, cancel := context.WithTimeout(context.Background(), coordinator.Timeout())
+        defer cancel()
+        require.Error(t, coordinator.Shutdown(ctx))
+
+        assert.Equal(t, int32(1), atomic.LoadInt32(&flushed), "the producer must get its full budget after a slow stage")
+        states := make(map[string]string)
+        for _, status := range coordinator.Status() {
+            states[status.Component] = status.State
+        }
+        assert.Equal(t, lifecycle.StateTimedOut, states["slow_workers"])
+        assert.Equal(t, lifecycle.StateStopped, states["producer"])
+    })
+}
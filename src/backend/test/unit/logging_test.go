@@ -0,0 +1,167 @@
+// Package unit provides unit tests for log sampling and redaction
+package unit
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "strings"
+    "os"
+    "path/filepath"
+    "sync"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "github.com/blackpoint/pkg/common/errors"
+    "github.com/blackpoint/pkg/common/logging"
+    "github.com/blackpoint/pkg/integration"
+)
+
+// countLogMessages counts the JSON log entries in path by message
+func countLogMessages(t *testing.T, path string) map[string]int {
+    t.Helper()
+
+    file, err := os.Open(path)
+    require.NoError(t, err)
+    defer file.Close()
+
+    counts := make(map[string]int)
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        var entry struct {
+            Message string `json:"message"`
+        }
+        if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+            counts[entry.Message]++
+        }
+    }
+    require.NoError(t, scanner.Err())
+    return counts
+}
+
+// TestLogSampling verifies info logs are sampled while audit and error logs
+// are always written
+func TestLogSampling(t *testing.T) {
+    tests := []struct {
+        name     string
+        sampling logging.SamplingConfig
+        min, max int
+    }{
+        {"one in N", logging.SamplingConfig{Every: 10}, 1000, 1000},
+        {"token bucket", logging.SamplingConfig{PerSecond: 1, Burst: 100}, 100, 150},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            config := logging.NewLogConfig()
+            config.OutputPath = filepath.Join(t.TempDir(), "sampling.log")
+            config.EnableSecurityAudit = true
+            config.Sampling = tt.sampling
+            require.NoError(t, logging.InitLogger(config))
+
+            const (
+                infoLogs   = 10000
+                auditLogs  = 200
+                errorLogs  = 100
+                goroutines = 8
+            )
+            var wg sync.WaitGroup
+            for g := 0; g < goroutines; g++ {
+                wg.Add(1)
+                go func(g int) {
+                    defer wg.Done()
+                    for i := g; i < infoLogs; i += goroutines {
+                        logging.Info("event processed", logging.Field("seq", i))
+                    }
+                }(g)
+            }
+            wg.Wait()
+
+            for i := 0; i < auditLogs; i++ {
+                logging.SecurityAudit("credential rotated", map[string]interface{}{"seq": i})
+            }
+            for i := 0; i < errorLogs; i++ {
+                logging.Error("delivery failed", errors.NewError("E4001", fmt.Sprintf("attempt %d", i), nil))
+            }
+
+            counts := countLogMessages(t, config.OutputPath)
+            assert.GreaterOrEqual(t, counts["event processed"], tt.min)
+            assert.LessOrEqual(t, counts["event processed"], tt.max)
+            assert.Equal(t, auditLogs, counts["credential rotated"], "audit logs must not be sampled")
+            assert.Equal(t, errorLogs, counts["delivery failed"], "error logs must not be sampled")
+        })
+    }
+}
+
+// TestLogRedaction verifies secrets in logged auth configs are redacted,
+// including in security audit logs
+func TestLogRedaction(t *testing.T) {
+    const secret = "okta-client-secret-value"
+
+    config := logging.NewLogConfig()
+    config.OutputPath = filepath.Join(t.TempDir(), "redaction.log")
+    config.EnableSecurityAudit = true
+    require.NoError(t, logging.InitLogger(config))
+
+    auth := integration.AuthenticationConfig{
+        Type: "oauth2",
+        Credentials: map[string]interface{}{
+            "client_id":     "okta-client",
+            "client_secret": secret,
+            "token_url":     "https://example.okta.com/oauth2/v1/token",
+        },
+    }
+    logging.Info("integration configured", logging.Field("auth", auth))
+    logging.Info("token refreshed", logging.Field("access_token", secret))
+    logging.SecurityAudit("credentials rotated", map[string]interface{}{
+        "integration":   "okta",
+        "client_secret": secret,
+        "credentials":   auth.Credentials,
+    })
+
+    data, err := os.ReadFile(config.OutputPath)
+    require.NoError(t, err)
+    output := string(data)
+
+    assert.Equal(t, 3, len(strings.Split(strings.TrimSpace(output), "\n")))
+    assert.NotContains(t, output, secret, "client secret must be redacted")
+    assert.Contains(t, output, `"client_secret":"***"`)
+    assert.Contains(t, output, `"access_token":"***"`)
+    assert.Contains(t, output, "okta-client", "non-sensitive fields must be kept")
+}
+
+// TestLogRedactionCamelCaseKeys verifies camelCase and PascalCase keys match
+// their snake_case sensitive keys
+func TestLogRedactionCamelCaseKeys(t *testing.T) {
+    const secret = "okta-client-secret-value"
+
+    config := logging.NewLogConfig()
+    config.OutputPath = filepath.Join(t.TempDir(), "redaction.log")
+    require.NoError(t, logging.InitLogger(config))
+
+    logging.Info("integration configured",
+        logging.Field("clientSecret", secret),
+        logging.Field("OktaAccessToken", secret),
+        logging.Field("credentials", map[string]interface{}{
+            "clientId":     "okta-client",
+            "refreshToken": secret,
+            "APIKey":       secret,
+        }),
+        logging.Field("clientId", "okta-client"),
+        logging.Field("tokenUrl", "https://example.okta.com/oauth2/v1/token"),
+    )
+
+    data, err := os.ReadFile(config.OutputPath)
+    require.NoError(t, err)
+    output := string(data)
+
+    assert.NotContains(t, output, secret, "camelCase secrets must be redacted")
+    assert.Contains(t, output, `"clientSecret":"***"`, "keys are logged as given")
+    assert.Contains(t, output, `"OktaAccessToken":"***"`)
+    assert.Contains(t, output, `"refreshToken":"***"`)
+    assert.Contains(t, output, `"APIKey":"***"`)
+    assert.Contains(t, output, "okta-client")
+    assert.Contains(t, output, "example.okta.com", "keys merely starting with a sensitive word are kept")
+}
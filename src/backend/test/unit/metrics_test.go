@@ -0,0 +1,88 @@
+// Package unit provides unit tests for pipeline metrics and event accounting
+package unit
+
+import (
+    "fmt"
+    "testing"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "../../internal/metrics"
+)
+
+// TestEventLedgerCountsRetriesOnce verifies redelivered events are counted
+// once per stage and that each event settles as produced or lost only once
+func TestEventLedgerCountsRetriesOnce(t *testing.T) {
+    ledger := metrics.NewEventLedger(metrics.LedgerConfig{})
+
+    // A failed event redelivered twice
+    for i := 0; i < 2; i++ {
+        ledger.RecordIngestedEvent(metrics.StageNormalize, "event-1")
+        ledger.RecordLostEvent(metrics.StageNormalize, metrics.LossTransformFailure, "event-1")
+    }
+    ledger.RecordProducedEvent(metrics.StageNormalize, "event-1")
+
+    // A delivered event recorded again after a redelivered batch
+    for i := 0; i < 2; i++ {
+        ledger.RecordIngestedEvent(metrics.StageNormalize, "event-2")
+        ledger.RecordProducedEvent(metrics.StageNormalize, "event-2")
+        ledger.RecordAlertedEvent("event-2")
+    }
+
+    // Produced without a recorded ingestion still balances
+    ledger.RecordProducedEvent(metrics.StageNormalize, "event-3")
+
+    // Events without an ID cannot be recognized and always count
+    ledger.RecordIngestedEvent(metrics.StageNormalize, "")
+    ledger.RecordIngestedEvent(metrics.StageNormalize, "")
+
+    report := ledger.Reconcile()
+    account, ok := report.Stages[metrics.StageNormalize]
+    require.True(t, ok)
+    assert.EqualValues(t, 5, account.Ingested)
+    assert.EqualValues(t, 2, account.Produced)
+    assert.EqualValues(t, 1, account.LostTotal)
+    assert.EqualValues(t, 1, account.Lost[metrics.LossTransformFailure])
+    assert.EqualValues(t, 2, account.Unaccounted)
+    assert.EqualValues(t, 1, report.Alerted)
+}
+
+// TestEventLedgerRetryWindow verifies events are counted again once the
+// retry window has passed
+func TestEventLedgerRetryWindow(t *testing.T) {
+    ledger := metrics.NewEventLedger(metrics.LedgerConfig{RetryWindow: 20 * time.Millisecond})
+
+    ledger.RecordIngestedEvent(metrics.StageIngest, "event-1")
+    ledger.RecordIngestedEvent(metrics.StageIngest, "event-1")
+    time.Sleep(40 * time.Millisecond)
+    ledger.RecordIngestedEvent(metrics.StageIngest, "event-1")
+
+    assert.EqualValues(t, 2, ledger.Reconcile().Stages[metrics.StageIngest].Ingested)
+}
+
+// TestCardinalityGuardOverflow verifies values past a guard's limit are
+// bucketed into the overflow value and counted as dropped
+func TestCardinalityGuardOverflow(t *testing.T) {
+    guard := metrics.NewCardinalityGuard("test_cardinality_overflow", 3)
+
+    for i := 0; i < 3; i++ {
+        value := fmt.Sprintf("client-%d", i)
+        assert.Equal(t, value, guard.Value("client_id", value))
+    }
+    assert.Equal(t, metrics.OverflowLabelValue, guard.Value("client_id", "client-3"))
+    assert.Equal(t, metrics.OverflowLabelValue, guard.Value("client_id", "client-4"))
+
+    // Admitted values keep their series and other labels have their own limit
+    assert.Equal(t, "client-0", guard.Value("client_id", "client-0"))
+    assert.Equal(t, "okta", guard.Value("platform", "okta"))
+
+    reg, ok := prometheus.DefaultGatherer.(*prometheus.Registry)
+    require.True(t, ok)
+    assert.Equal(t, 2.0, gatheredValue(t, reg, "blackpoint_metrics_label_values_dropped_total", map[string]string{
+        "metric": "test_cardinality_overflow",
+        "label":  "client_id",
+    }))
+}
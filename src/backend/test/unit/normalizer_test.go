@@ -5,6 +5,10 @@ import (
     "context"
     "crypto/rand"
     "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
     "sync"
     "testing"
     "time"
@@ -12,9 +16,13 @@ import (
     "github.com/blackpoint/pkg/bronze/schema"
     "github.com/blackpoint/pkg/silver/schema"
     "github.com/blackpoint/pkg/common/errors"
+    "github.com/prometheus/client_golang/prometheus"
+    "../../internal/metrics"
+    "../../internal/normalizer"
     "../../internal/normalizer/processor"
     "../../internal/normalizer/mapper"
     "../../internal/normalizer/transformer"
+    "../../internal/streaming"
     "go.opentelemetry.io/otel"
     "go.opentelemetry.io/otel/trace"
 )
@@ -106,7 +114,7 @@ func TestProcessorSecurityValidation(t *testing.T) {
     for _, tt := range tests {
         t.Run(tt.name, func(t *testing.T) {
             // Process event with security context
-            result, err := p.ProcessSingle(testSecurityContext, tt.event)
+            result, err := p.ProcessSingle(processor.WithSecurityContext(testSecurityContext, tt.securityCtx), tt.event)
 
             // Validate results
             if tt.expectError {
@@ -138,6 +146,75 @@ func TestProcessorSecurityValidation(t *testing.T) {
     }
 }
 
+// TestSecurityContextValidate covers each invalid security context combination
+func TestSecurityContextValidate(t *testing.T) {
+    valid := func() *schema.SecurityContext {
+        return &schema.SecurityContext{
+            Classification: "CONFIDENTIAL",
+            Sensitivity:   "HIGH",
+            Compliance:    []string{"PCI", "SOC2"},
+        }
+    }
+
+    tests := []struct {
+        name      string
+        modify    func(*schema.SecurityContext)
+        errorCode string
+        message   string
+    }{
+        {name: "Valid context", modify: func(c *schema.SecurityContext) {}},
+        {name: "Lowercase values", modify: func(c *schema.SecurityContext) {
+            c.Classification, c.Sensitivity, c.Compliance = "internal", "medium", []string{"hipaa"}
+        }},
+        {name: "PCI-DSS tag", modify: func(c *schema.SecurityContext) { c.Compliance = []string{"PCI-DSS", "SOC2"} }},
+        {name: "Missing classification", modify: func(c *schema.SecurityContext) { c.Classification = "" },
+            errorCode: "E3001", message: "missing security classification"},
+        {name: "Unknown classification", modify: func(c *schema.SecurityContext) { c.Classification = "TOP_SECRET" },
+            errorCode: "E3001", message: "invalid security classification"},
+        {name: "Missing sensitivity", modify: func(c *schema.SecurityContext) { c.Sensitivity = "" },
+            errorCode: "E3001", message: "missing sensitivity level"},
+        {name: "Unknown sensitivity", modify: func(c *schema.SecurityContext) { c.Sensitivity = "EXTREME" },
+            errorCode: "E3001", message: "invalid sensitivity level"},
+        {name: "Missing compliance", modify: func(c *schema.SecurityContext) { c.Compliance = nil },
+            errorCode: "E3001", message: "missing compliance requirements"},
+        {name: "Unknown compliance tag", modify: func(c *schema.SecurityContext) { c.Compliance = []string{"PCI", "FEDRAMP-ISH"} },
+            errorCode: "E3001", message: "unknown compliance tag"},
+        {name: "Classification checked first", modify: func(c *schema.SecurityContext) {
+            c.Classification, c.Sensitivity = "", ""
+        }, errorCode: "E3001", message: "missing security classification"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            secCtx := valid()
+            tt.modify(secCtx)
+            err := secCtx.Validate()
+
+            if tt.errorCode == "" {
+                if err != nil {
+                    t.Errorf("Unexpected error: %v", err)
+                }
+                return
+            }
+            var bpErr *errors.BlackPointError
+            if !errors.As(err, &bpErr) {
+                t.Fatalf("Expected BlackPointError, got %v", err)
+            }
+            if bpErr.Code != tt.errorCode {
+                t.Errorf("Expected error code %s, got %s", tt.errorCode, bpErr.Code)
+            }
+            if !strings.Contains(bpErr.Message, tt.message) {
+                t.Errorf("Expected message containing %q, got %q", tt.message, bpErr.Message)
+            }
+        })
+    }
+
+    var nilCtx *schema.SecurityContext
+    if err := nilCtx.Validate(); err == nil {
+        t.Error("Expected error for nil security context")
+    }
+}
+
 // TestProcessorPerformance benchmarks processing performance
 func TestProcessorPerformance(b *testing.B) {
     // Initialize components
@@ -156,10 +233,13 @@ func TestProcessorPerformance(b *testing.B) {
         b.ResetTimer()
         for i := 0; i < b.N; i++ {
             batch := events[i*testBatchSize : (i+1)*testBatchSize]
-            _, err := p.Process(testSecurityContext, batch)
+            _, failures, err := p.Process(testSecurityContext, batch)
             if err != nil {
                 b.Fatalf("Batch processing failed: %v", err)
             }
+            if len(failures) > 0 {
+                b.Fatalf("Batch processing failed for %d events: %v", len(failures), failures[0])
+            }
         }
     })
 
@@ -375,6 +455,108 @@ func TestTransformationCompliance(t *testing.T) {
     }
 }
 
+// TestSensitiveFieldDetection validates pattern-based field encryption
+func TestSensitiveFieldDetection(t *testing.T) {
+    bronze := &schema.BronzeEvent{ID: "sensitive-1", ClientID: testClientID}
+    secCtx := &schema.SecurityContext{
+        Classification: "INTERNAL",
+        Sensitivity:    "HIGH",
+        Compliance:     []string{"PCI"},
+    }
+    input := map[string]interface{}{
+        "event_type":      "auth_success",
+        "api_key":         "ak-123456",
+        "keyboard_layout": "en-US",
+        "token_count":     3,
+        "employee_ssn":    "123-45-6789",
+    }
+
+    // Default patterns match whole name segments only
+    tr := transformer.NewTransformer(testTimeout)
+    event, err := tr.TransformEvent(bronze, input, secCtx)
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    if _, exists := event.NormalizedData["api_key"]; exists {
+        t.Error("api_key should be removed from normalized data")
+    }
+    if len(event.EncryptedFields["api_key"]) == 0 {
+        t.Error("api_key should be recorded in encrypted fields")
+    }
+    for _, field := range []string{"keyboard_layout", "token_count", "employee_ssn"} {
+        if _, exists := event.NormalizedData[field]; !exists {
+            t.Errorf("%s should not be encrypted by default", field)
+        }
+        if _, exists := event.EncryptedFields[field]; exists {
+            t.Errorf("%s should not be in encrypted fields", field)
+        }
+    }
+
+    // Custom patterns extend the defaults; the allow-list overrides both
+    if err := tr.SetSensitiveFields(transformer.SensitiveFieldConfig{
+        Patterns:  map[string]string{"ssn": `(^|_)ssn$`},
+        AllowList: []string{"api_key"},
+    }); err != nil {
+        t.Fatalf("Failed to set sensitive fields: %v", err)
+    }
+    event, err = tr.TransformEvent(bronze, input, secCtx)
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    if len(event.EncryptedFields["employee_ssn"]) == 0 {
+        t.Error("employee_ssn should match the custom pattern")
+    }
+    if event.NormalizedData["api_key"] != "ak-123456" {
+        t.Error("allow-listed api_key should not be encrypted")
+    }
+
+    if err := tr.SetSensitiveFields(transformer.SensitiveFieldConfig{
+        Patterns: map[string]string{"broken": `(unclosed`},
+    }); err == nil {
+        t.Error("Expected invalid pattern to be rejected")
+    }
+}
+
+// TestSensitiveFieldCamelCase validates camelCase field names are matched by
+// the default patterns after conversion to snake_case
+func TestSensitiveFieldCamelCase(t *testing.T) {
+    bronze := &schema.BronzeEvent{ID: "sensitive-2", ClientID: testClientID}
+    secCtx := &schema.SecurityContext{
+        Classification: "INTERNAL",
+        Sensitivity:    "HIGH",
+        Compliance:     []string{"PCI"},
+    }
+    sensitive := []string{
+        "accessToken", "clientSecret", "userPassword", "sessionToken",
+        "refreshToken", "passwordHash", "ssh_key", "key", "APIKey", "IDToken",
+    }
+    clear := []string{"keyboardLayout", "tokenCount", "apiKeyId", "partitionKey"}
+
+    input := map[string]interface{}{"event_type": "auth_success"}
+    for _, field := range append(append([]string{}, sensitive...), clear...) {
+        input[field] = "value-" + field
+    }
+
+    tr := transformer.NewTransformer(testTimeout)
+    event, err := tr.TransformEvent(bronze, input, secCtx)
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    for _, field := range sensitive {
+        if _, exists := event.NormalizedData[field]; exists {
+            t.Errorf("%s should be removed from normalized data", field)
+        }
+        if len(event.EncryptedFields[field]) == 0 {
+            t.Errorf("%s should be recorded in encrypted fields", field)
+        }
+    }
+    for _, field := range clear {
+        if _, exists := event.EncryptedFields[field]; exists {
+            t.Errorf("%s should not be encrypted", field)
+        }
+    }
+}
+
 // Helper functions
 
 func generateTestEvents(count int) []*schema.BronzeEvent {
@@ -425,4 +607,881 @@ func isEncrypted(value interface{}) bool {
         return len(data) > 0 && data[0] != '{' && data[0] != '['
     }
     return false
-}
\ No newline at end of file
+}
+
+// fakeLagSource returns a configurable lag for back-pressure tests
+type fakeLagSource struct {
+    lag int64
+}
+
+func (f *fakeLagSource) Lag() (int64, error) {
+    return f.lag, nil
+}
+
+// TestBackpressureController validates lag-driven throttling with hysteresis
+func TestBackpressureController(t *testing.T) {
+    source := &fakeLagSource{}
+    controller, err := normalizer.NewBackpressureController(source, normalizer.BackpressureConfig{
+        HighLag: 1000,
+        LowLag:  200,
+        Mode:    normalizer.ThrottleModePause,
+    }, nil)
+    if err != nil {
+        t.Fatalf("Failed to create back-pressure controller: %v", err)
+    }
+
+    steps := []struct {
+        lag          int64
+        expectPaused bool
+    }{
+        {lag: 500, expectPaused: false},
+        {lag: 1500, expectPaused: true},
+        {lag: 600, expectPaused: true}, // Between thresholds keeps throttling
+        {lag: 150, expectPaused: false},
+        {lag: 900, expectPaused: false}, // Between thresholds stays released
+    }
+
+    for _, step := range steps {
+        source.lag = step.lag
+        controller.Check()
+
+        if controller.Paused() != step.expectPaused {
+            t.Errorf("lag %d: expected paused=%v, got %v", step.lag, step.expectPaused, controller.Paused())
+        }
+        if state := controller.State(); state.Lag != step.lag {
+            t.Errorf("Expected state lag %d, got %d", step.lag, state.Lag)
+        }
+    }
+}
+
+func TestECSMapping(t *testing.T) {
+    ecsMapper, err := normalizer.NewECSMapper(map[string]string{
+        "hostname": "observer.hostname",
+    })
+    if err != nil {
+        t.Fatalf("Failed to create ECS mapper: %v", err)
+    }
+
+    doc, err := ecsMapper.ToECS(map[string]interface{}{
+        "event_time": "2024-01-01T00:00:00Z",
+        "event_type": "login",
+        "src_ip":     "10.0.0.1",
+        "dst_port":   443,
+        "hostname":   "gw-01",
+        "tenant_tag": "alpha",
+    })
+    if err != nil {
+        t.Fatalf("Failed to map event to ECS: %v", err)
+    }
+
+    source, ok := doc["source"].(map[string]interface{})
+    if !ok || source["ip"] != "10.0.0.1" {
+        t.Errorf("Expected source.ip to be mapped, got %v", doc["source"])
+    }
+    if observer, ok := doc["observer"].(map[string]interface{}); !ok || observer["hostname"] != "gw-01" {
+        t.Errorf("Expected custom mapping to observer.hostname, got %v", doc["observer"])
+    }
+    if custom, ok := doc["blackpoint"].(map[string]interface{}); !ok || custom["tenant_tag"] != "alpha" {
+        t.Errorf("Expected unmapped field under custom namespace, got %v", doc["blackpoint"])
+    }
+    if err := ecsMapper.ValidateECS(doc); err != nil {
+        t.Errorf("Expected ECS-compliant document, got: %v", err)
+    }
+
+    // Invalid values and missing required fields must fail validation
+    invalid, err := ecsMapper.ToECS(map[string]interface{}{
+        "src_ip": "not-an-ip",
+    })
+    if err != nil {
+        t.Fatalf("Failed to map event to ECS: %v", err)
+    }
+    if err := ecsMapper.ValidateECS(invalid); err == nil {
+        t.Error("Expected ECS validation to fail for invalid IP and missing @timestamp")
+    }
+
+    if _, err := normalizer.NewECSMapper(map[string]string{"src_ip": "not_ecs.ip"}); err == nil {
+        t.Error("Expected mapping to a non-ECS field set to be rejected")
+    }
+}
+
+// TestProcessOrdering verifies batch results follow input order regardless of worker completion order
+func TestProcessOrdering(t *testing.T) {
+    m := mapper.NewFieldMapper(make(map[string]string), nil)
+    tr := transformer.NewTransformer(testTimeout)
+    p, err := processor.NewProcessor(m, tr, testTimeout)
+    if err != nil {
+        t.Fatalf("Failed to create processor: %v", err)
+    }
+
+    events := make([]*schema.BronzeEvent, 50)
+    position := make(map[string]int, len(events))
+    for i := range events {
+        clientID := fmt.Sprintf("order-client-%03d", i)
+        position[clientID] = i
+        events[i] = &schema.BronzeEvent{
+            ID:       fmt.Sprintf("order-event-%03d", i),
+            ClientID: clientID,
+            Payload: json.RawMessage(`{
+                "alert_type": "login",
+                "event_timestamp": "2024-01-20T10:00:00Z",
+                "source_ip": "10.0.0.1",
+                "destination_ip": "10.0.0.2"
+            }`),
+        }
+    }
+
+    for run := 0; run < 5; run++ {
+        results, failed, err := p.Process(context.Background(), events)
+        if err != nil {
+            t.Fatalf("run %d: unexpected error: %v", run, err)
+        }
+        if len(failed) != 0 {
+            t.Fatalf("run %d: unexpected event failures: %v", run, failed)
+        }
+        if len(results) != len(events) {
+            t.Fatalf("run %d: expected %d results, got %d", run, len(events), len(results))
+        }
+        for i, result := range results {
+            idx, ok := position[result.ClientID]
+            if !ok {
+                t.Fatalf("Unexpected client ID %s in results", result.ClientID)
+            }
+            if idx != i {
+                t.Fatalf("run %d: result %d belongs to input %d", run, i, idx)
+            }
+        }
+    }
+}
+
+// TestProcessorStageMetrics verifies each processing stage is timed separately
+func TestProcessorStageMetrics(t *testing.T) {
+    m := mapper.NewFieldMapper(map[string]string{"password": "password"}, nil)
+    tr := transformer.NewTransformer(testTimeout)
+    p, err := processor.NewProcessor(m, tr, testTimeout)
+    if err != nil {
+        t.Fatalf("Failed to create processor: %v", err)
+    }
+
+    // The password field makes the event pass through the encryption stage
+    event := &schema.BronzeEvent{
+        ID:       "stage-event-1",
+        ClientID: testClientID,
+        Payload: json.RawMessage(`{
+            "alert_type": "login",
+            "event_timestamp": "2024-01-20T10:00:00Z",
+            "source_ip": "10.0.0.1",
+            "destination_ip": "10.0.0.2",
+            "password": "hunter2"
+        }`),
+    }
+    if _, err := p.ProcessSingle(context.Background(), event); err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+
+    metrics := p.GetMetrics()
+    if metrics.EventsProcessed != 1 {
+        t.Errorf("Expected 1 processed event, got %d", metrics.EventsProcessed)
+    }
+    for _, stage := range []string{processor.StageMapping, processor.StageTransformation, processor.StageEncryption} {
+        latency, ok := metrics.Stages[stage]
+        if !ok || latency.Count == 0 {
+            t.Errorf("Expected %s stage to be recorded", stage)
+            continue
+        }
+        if latency.P50 > latency.P99 {
+            t.Errorf("%s stage P50 %v exceeds P99 %v", stage, latency.P50, latency.P99)
+        }
+    }
+}
+
+// TestSchemaMigration verifies older events are upgraded before mapping
+func TestSchemaMigration(t *testing.T) {
+    migrator, err := processor.NewSchemaMigrator("1.1")
+    if err != nil {
+        t.Fatalf("Failed to create migrator: %v", err)
+    }
+    // Schema 1.1 renamed the top-level "username" field to "user_name"
+    if err := migrator.Register("1.0", "1.1", processor.RenameField("username", "user_name")); err != nil {
+        t.Fatalf("Failed to register migration: %v", err)
+    }
+    if err := migrator.Register("1.1", "1.0", processor.RenameField("user_name", "username")); err == nil {
+        t.Error("Expected downgrade migration to be rejected")
+    }
+
+    m := mapper.NewFieldMapper(map[string]string{"user_name": "user_name"}, nil)
+    tr := transformer.NewTransformer(testTimeout)
+    p, err := processor.NewProcessor(m, tr, testTimeout)
+    if err != nil {
+        t.Fatalf("Failed to create processor: %v", err)
+    }
+    p.SetSchemaMigrator(migrator)
+
+    newEvent := func(version string) *schema.BronzeEvent {
+        return &schema.BronzeEvent{
+            ID:            "migration-event-" + version,
+            ClientID:      testClientID,
+            SchemaVersion: version,
+            Payload: json.RawMessage(`{
+                "alert_type": "login",
+                "event_timestamp": "2024-01-20T10:00:00Z",
+                "source_ip": "10.0.0.1",
+                "destination_ip": "10.0.0.2",
+                "username": "jdoe"
+            }`),
+        }
+    }
+
+    event := newEvent("1.0")
+    silverEvent, err := p.ProcessSingle(context.Background(), event)
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    if silverEvent.NormalizedData["user_name"] != "jdoe" {
+        t.Errorf("Expected migrated user_name in normalized data, got %v", silverEvent.NormalizedData)
+    }
+    if event.SchemaVersion != "1.0" {
+        t.Error("Migration should not modify the input event")
+    }
+
+    // Events without a version predate versioning and start from the oldest
+    unversioned, err := p.ProcessSingle(context.Background(), newEvent(""))
+    if err != nil {
+        t.Fatalf("Unexpected error for unversioned event: %v", err)
+    }
+    if unversioned.NormalizedData["user_name"] != "jdoe" {
+        t.Errorf("Expected unversioned event to be migrated, got %v", unversioned.NormalizedData)
+    }
+    if oldest := migrator.OldestVersion(); oldest != "1.0" {
+        t.Errorf("Expected oldest version 1.0, got %s", oldest)
+    }
+
+    // Newer and unknown versions have no path to the target
+    for _, version := range []string{"2.0", "0.9", "latest"} {
+        if _, err := p.ProcessSingle(context.Background(), newEvent(version)); err == nil {
+            t.Errorf("Expected schema version %s to be rejected", version)
+        }
+    }
+}
+
+// recordingEnricher records the order enrichers run in
+type recordingEnricher struct {
+    name string
+    deps []string
+    fail bool
+    log  *[]string
+}
+
+func (e *recordingEnricher) Name() string        { return e.name }
+func (e *recordingEnricher) DependsOn() []string { return e.deps }
+
+func (e *recordingEnricher) Enrich(ctx context.Context, event *schema.SilverEvent) error {
+    *e.log = append(*e.log, e.name)
+    if e.fail {
+        return errors.NewError("E4001", "enrichment source unavailable", nil)
+    }
+    return nil
+}
+
+// TestEnrichmentPipeline verifies dependency ordering, cycle detection and failure isolation
+func TestEnrichmentPipeline(t *testing.T) {
+    var ran []string
+    pipeline, err := normalizer.NewEnrichmentPipeline(
+        &recordingEnricher{name: "threat_intel", deps: []string{"identity", "geoip"}, log: &ran},
+        &recordingEnricher{name: "identity", log: &ran},
+        &recordingEnricher{name: "geoip", log: &ran},
+    )
+    if err != nil {
+        t.Fatalf("Failed to create enrichment pipeline: %v", err)
+    }
+
+    expected := []string{"geoip", "identity", "threat_intel"}
+    if order := pipeline.Order(); strings.Join(order, ",") != strings.Join(expected, ",") {
+        t.Errorf("Expected order %v, got %v", expected, order)
+    }
+
+    if _, err := normalizer.NewEnrichmentPipeline(
+        &recordingEnricher{name: "a", deps: []string{"b"}, log: &ran},
+        &recordingEnricher{name: "b", deps: []string{"a"}, log: &ran},
+    ); err == nil {
+        t.Error("Expected dependency cycle to be rejected")
+    }
+
+    if _, err := normalizer.NewEnrichmentPipeline(
+        &recordingEnricher{name: "a", deps: []string{"missing"}, log: &ran},
+    ); err == nil {
+        t.Error("Expected unknown dependency to be rejected")
+    }
+
+    // A failed enricher skips its dependents but not independent enrichers
+    ran = nil
+    pipeline, err = normalizer.NewEnrichmentPipeline(
+        &recordingEnricher{name: "identity", fail: true, log: &ran},
+        &recordingEnricher{name: "threat_intel", deps: []string{"identity"}, log: &ran},
+        &recordingEnricher{name: "geoip", log: &ran},
+    )
+    if err != nil {
+        t.Fatalf("Failed to create enrichment pipeline: %v", err)
+    }
+    if err := pipeline.Run(context.Background(), &schema.SilverEvent{}); err == nil {
+        t.Error("Expected enrichment failure to be reported")
+    }
+    if strings.Join(ran, ",") != "geoip,identity" {
+        t.Errorf("Expected geoip and identity to run and threat_intel to be skipped, got %v", ran)
+    }
+}
+
+// TestTransformFailurePolicy verifies optional fields can fail without dropping the event
+func TestTransformFailurePolicy(t *testing.T) {
+    tr := transformer.NewTransformer(testTimeout)
+    tr.RegisterTransformer("geo_hint", func(v interface{}) (interface{}, error) {
+        return nil, errors.NewError("E3001", "unparseable geo hint", nil)
+    })
+
+    bronze := &schema.BronzeEvent{
+        ID:       "transform-policy-1",
+        ClientID: testClientID,
+    }
+    input := map[string]interface{}{
+        "event_type": "security_alert",
+        "geo_hint":   "??",
+    }
+    secCtx := &schema.SecurityContext{
+        Classification: "INTERNAL",
+        Sensitivity:    "MEDIUM",
+        Compliance:     []string{"DEFAULT"},
+    }
+
+    // Fail-event is the default
+    if _, err := tr.TransformEvent(bronze, input, secCtx); err == nil {
+        t.Error("Expected transform failure to fail the event by default")
+    }
+
+    if err := tr.SetFieldFailurePolicy("geo_hint", transformer.FailurePolicySkipField); err != nil {
+        t.Fatalf("Failed to set failure policy: %v", err)
+    }
+    event, err := tr.TransformEvent(bronze, input, secCtx)
+    if err != nil {
+        t.Fatalf("Expected partial event, got error: %v", err)
+    }
+    if _, exists := event.NormalizedData["geo_hint"]; exists {
+        t.Error("Failed field should be omitted from normalized data")
+    }
+    if len(event.TransformErrors) != 1 || event.TransformErrors[0].Field != "geo_hint" {
+        t.Errorf("Expected geo_hint recorded in transform errors, got %v", event.TransformErrors)
+    }
+
+    if err := tr.SetDefaultFailurePolicy("ignore"); err == nil {
+        t.Error("Expected unsupported failure policy to be rejected")
+    }
+}
+
+// TestLoadMappingsFromFile validates loading of YAML and JSON mapping files
+func TestLoadMappingsFromFile(t *testing.T) {
+    dir := t.TempDir()
+    writeFile := func(name, content string) string {
+        path := filepath.Join(dir, name)
+        if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+            t.Fatalf("Failed to write mapping file: %v", err)
+        }
+        return path
+    }
+
+    t.Run("YAML with platform sets", func(t *testing.T) {
+        path := writeFile("mappings.yaml", `
+mappings:
+  custom_field: normalized_field
+platforms:
+  okta:
+    actor_id: src_user
+`)
+        mappings, err := mapper.LoadMappingsFromFile(path)
+        if err != nil {
+            t.Fatalf("Expected mappings to load, got %v", err)
+        }
+        if mappings["custom_field"] != "normalized_field" {
+            t.Errorf("Expected custom_field mapping, got %v", mappings)
+        }
+
+        file, err := mapper.LoadMappingFile(path)
+        if err != nil {
+            t.Fatalf("Expected mapping file to load, got %v", err)
+        }
+        okta := file.ForPlatform("okta")
+        if okta["actor_id"] != "src_user" || okta["custom_field"] != "normalized_field" {
+            t.Errorf("Expected okta set merged over defaults, got %v", okta)
+        }
+    })
+
+    t.Run("JSON", func(t *testing.T) {
+        path := writeFile("mappings.json", `{"mappings": {"alert_level": "severity"}}`)
+        mappings, err := mapper.LoadMappingsFromFile(path)
+        if err != nil {
+            t.Fatalf("Expected mappings to load, got %v", err)
+        }
+        if mappings["alert_level"] != "severity" {
+            t.Errorf("Expected alert_level mapping, got %v", mappings)
+        }
+    })
+
+    t.Run("Duplicate target rejected", func(t *testing.T) {
+        path := writeFile("duplicate.yaml", `
+mappings:
+  user_name: src_user
+  username: src_user
+`)
+        _, err := mapper.LoadMappingsFromFile(path)
+        if err == nil {
+            t.Fatal("Expected duplicate normalized field to be rejected")
+        }
+        if !strings.Contains(err.Error(), "user_name, username") {
+            t.Errorf("Expected error to name both source fields, got %v", err)
+        }
+    })
+
+    t.Run("Platform set colliding with defaults rejected", func(t *testing.T) {
+        path := writeFile("platform.yaml", `
+mappings:
+  user_name: src_user
+platforms:
+  okta:
+    actor_id: src_user
+`)
+        if _, err := mapper.LoadMappingsFromFile(path); err == nil {
+            t.Fatal("Expected platform mapping colliding with defaults to be rejected")
+        }
+    })
+}
+
+// TestNestedFieldMapping validates dot-path source and target mappings
+func TestNestedFieldMapping(t *testing.T) {
+    m := mapper.NewFieldMapper(map[string]string{
+        "source.ip":             "src_ip",
+        "destination.ip":        "dst_ip",
+        "events[0].actor.id":    "src_user",
+        "events[1].type":        "event_type",
+        "metadata.time":         "event_time",
+        "events[0].target.name": "target.name",
+    }, nil)
+
+    tests := []struct {
+        name        string
+        input       map[string]interface{}
+        expected    map[string]interface{}
+        required    string
+        expectError bool
+    }{
+        {
+            name: "Nested objects and array indexing",
+            input: map[string]interface{}{
+                "source":      map[string]interface{}{"ip": "192.168.1.1"},
+                "destination": map[string]interface{}{"ip": "10.0.0.1"},
+                "metadata":    map[string]interface{}{"time": "2024-01-20T10:00:00Z"},
+                "events": []interface{}{
+                    map[string]interface{}{
+                        "actor":  map[string]interface{}{"id": "alice"},
+                        "target": map[string]interface{}{"name": "db-01"},
+                    },
+                    map[string]interface{}{"type": "login"},
+                },
+            },
+            expected: map[string]interface{}{
+                "src_ip":     "192.168.1.1",
+                "dst_ip":     "10.0.0.1",
+                "src_user":   "alice",
+                "event_type": "login",
+            },
+        },
+        {
+            name: "Absent optional paths are skipped",
+            input: map[string]interface{}{
+                "source":      map[string]interface{}{"ip": "192.168.1.1"},
+                "destination": map[string]interface{}{"ip": "10.0.0.1"},
+                "metadata":    map[string]interface{}{"time": "2024-01-20T10:00:00Z"},
+                "events": []interface{}{
+                    map[string]interface{}{"actor": "not-an-object"},
+                    map[string]interface{}{"type": "login"},
+                },
+            },
+            expected: map[string]interface{}{
+                "src_ip":     "192.168.1.1",
+                "event_type": "login",
+            },
+        },
+        {
+            name: "Absent required path fails",
+            input: map[string]interface{}{
+                "source":      map[string]interface{}{"ip": "192.168.1.1"},
+                "destination": map[string]interface{}{"ip": "10.0.0.1"},
+                "metadata":    map[string]interface{}{"time": "2024-01-20T10:00:00Z"},
+                "events": []interface{}{
+                    map[string]interface{}{"type": "login"},
+                },
+            },
+            required:    "events[1].type",
+            expectError: true,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if tt.required != "" {
+                m.SetRequiredMapping(tt.required, true)
+                defer m.SetRequiredMapping(tt.required, false)
+            }
+
+            result, err := m.MapEvent(&schema.BronzeEvent{
+                ID:       "test-id",
+                ClientID: testClientID,
+                Payload:  mustMarshal(tt.input),
+            })
+
+            if tt.expectError {
+                if err == nil {
+                    t.Error("Expected error but got none")
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("Unexpected error: %v", err)
+            }
+            validateMappedFields(t, result.NormalizedData, tt.expected)
+        })
+    }
+
+    t.Run("Nested target", func(t *testing.T) {
+        result, err := m.MapEvent(&schema.BronzeEvent{
+            ID:       "test-id",
+            ClientID: testClientID,
+            Payload: mustMarshal(map[string]interface{}{
+                "source":      map[string]interface{}{"ip": "192.168.1.1"},
+                "destination": map[string]interface{}{"ip": "10.0.0.1"},
+                "metadata":    map[string]interface{}{"time": "2024-01-20T10:00:00Z"},
+                "events": []interface{}{
+                    map[string]interface{}{"target": map[string]interface{}{"name": "db-01"}},
+                    map[string]interface{}{"type": "login"},
+                },
+            }),
+        })
+        if err != nil {
+            t.Fatalf("Unexpected error: %v", err)
+        }
+        target, ok := result.NormalizedData["target"].(map[string]interface{})
+        if !ok || target["name"] != "db-01" {
+            t.Errorf("Expected nested target.name, got %v", result.NormalizedData["target"])
+        }
+    })
+}
+
+// TestConditionalTransformer validates that conditional transforms fire only when their predicate holds
+func TestConditionalTransformer(t *testing.T) {
+    tr := transformer.NewTransformer(testTimeout)
+
+    isAlert := func(event map[string]interface{}) bool {
+        return event["event_type"] == "security_alert"
+    }
+    tr.RegisterConditionalTransformer("severity", isAlert, func(v interface{}) (interface{}, error) {
+        if s, ok := v.(string); ok {
+            return strings.ToUpper(s), nil
+        }
+        return nil, errors.NewError("E3001", "invalid severity value", nil)
+    })
+    tr.RegisterConditionalTransformer("severity", func(event map[string]interface{}) bool {
+        return true
+    }, func(v interface{}) (interface{}, error) {
+        return "second", nil
+    })
+    tr.RegisterTransformer("status", func(v interface{}) (interface{}, error) {
+        return "unconditional", nil
+    })
+
+    bronze := &schema.BronzeEvent{
+        ID:       "conditional-1",
+        ClientID: testClientID,
+    }
+    secCtx := &schema.SecurityContext{
+        Classification: "INTERNAL",
+        Sensitivity:    "MEDIUM",
+        Compliance:     []string{"DEFAULT"},
+    }
+
+    tests := []struct {
+        name             string
+        input            map[string]interface{}
+        expectedSeverity interface{}
+    }{
+        {
+            name: "Predicate matches, first registered transformer wins",
+            input: map[string]interface{}{
+                "event_type": "security_alert",
+                "severity":   "high",
+                "status":     "open",
+            },
+            expectedSeverity: "HIGH",
+        },
+        {
+            name: "Predicate skips, next matching transformer applies",
+            input: map[string]interface{}{
+                "event_type": "audit_log",
+                "severity":   "high",
+                "status":     "open",
+            },
+            expectedSeverity: "second",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            event, err := tr.TransformEvent(bronze, tt.input, secCtx)
+            if err != nil {
+                t.Fatalf("Unexpected error: %v", err)
+            }
+            if event.NormalizedData["severity"] != tt.expectedSeverity {
+                t.Errorf("Expected severity %v, got %v", tt.expectedSeverity, event.NormalizedData["severity"])
+            }
+            if event.NormalizedData["status"] != "unconditional" {
+                t.Errorf("Expected unconditional transformer to apply, got %v", event.NormalizedData["status"])
+            }
+        })
+    }
+
+    t.Run("No predicate matches", func(t *testing.T) {
+        only := transformer.NewTransformer(testTimeout)
+        only.RegisterConditionalTransformer("severity", isAlert, func(v interface{}) (interface{}, error) {
+            return "transformed", nil
+        })
+
+        event, err := only.TransformEvent(bronze, map[string]interface{}{
+            "event_type": "audit_log",
+            "severity":   "high",
+        }, secCtx)
+        if err != nil {
+            t.Fatalf("Unexpected error: %v", err)
+        }
+        if event.NormalizedData["severity"] != "high" {
+            t.Errorf("Expected severity untouched, got %v", event.NormalizedData["severity"])
+        }
+    })
+}
+
+// mockBronzeArchive serves archived Bronze objects from memory
+type mockBronzeArchive struct {
+    objects map[string][]byte
+    failKey string
+}
+
+func (a *mockBronzeArchive) ListObjects(bucket, prefix string) ([]string, error) {
+    var keys []string
+    for key := range a.objects {
+        if strings.HasPrefix(key, prefix) {
+            keys = append(keys, key)
+        }
+    }
+    return keys, nil
+}
+
+func (a *mockBronzeArchive) GetObject(bucket, key string) ([]byte, error) {
+    if key == a.failKey {
+        return nil, fmt.Errorf("simulated read failure for %s", key)
+    }
+    return a.objects[key], nil
+}
+
+// recordingPublisher records the Silver values published to it
+type recordingPublisher struct {
+    mu     sync.Mutex
+    events []interface{}
+}
+
+func (p *recordingPublisher) PublishValues(ctx context.Context, values []interface{}, headers []map[string]string) error {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    p.events = append(p.events, values...)
+    return nil
+}
+
+func TestReprocessorReplaysArchivedEvents(t *testing.T) {
+    from := time.Date(2024, 1, 20, 10, 0, 0, 0, time.UTC)
+    to := from.Add(2 * time.Hour)
+
+    archivedEvent := func(clientID, id string, at time.Time) []byte {
+        data, err := json.Marshal(&schema.BronzeEvent{
+            ID:             id,
+            ClientID:       clientID,
+            SourcePlatform: "okta",
+            Timestamp:      at,
+            SchemaVersion:  "1.0",
+            Payload: json.RawMessage(`{
+                "alert_type": "login",
+                "event_timestamp": "2024-01-20T10:00:00Z",
+                "source_ip": "10.0.0.1",
+                "destination_ip": "10.0.0.2"
+            }`),
+        })
+        if err != nil {
+            t.Fatalf("Failed to encode archived event: %v", err)
+        }
+        return data
+    }
+
+    day := processor.BronzeArchivePrefix("bronze", from)
+    if day != "bronze/2024/01/20/" {
+        t.Fatalf("Unexpected archive partition %q", day)
+    }
+    archive := &mockBronzeArchive{objects: map[string][]byte{
+        // Newline-delimited and array-encoded objects
+        day + "1705744800": append(append(archivedEvent(testClientID, "e1", from.Add(5*time.Minute)), '\n'), archivedEvent(testClientID, "e2", from.Add(10*time.Minute))...),
+        day + "1705746600": []byte("[" + string(archivedEvent(testClientID, "e3", from.Add(30*time.Minute))) + "]"),
+        // The second event falls after the requested range
+        day + "1705750200": append(append(archivedEvent(testClientID, "e4", from.Add(90*time.Minute)), '\n'), archivedEvent(testClientID, "e5", to.Add(30*time.Minute))...),
+        // Other clients share the partition but are never reprocessed
+        day + "1705753800": archivedEvent("other-client", "e6", from),
+        // Other days are never listed
+        processor.BronzeArchivePrefix("bronze", from.Add(-24*time.Hour)) + "1705658400": archivedEvent(testClientID, "e7", from),
+    }}
+
+    p, err := processor.NewProcessor(mapper.NewFieldMapper(nil, nil), transformer.NewTransformer(testTimeout), testTimeout)
+    if err != nil {
+        t.Fatalf("Failed to create processor: %v", err)
+    }
+    checkpoints, err := processor.NewFileCheckpoint(t.TempDir())
+    if err != nil {
+        t.Fatalf("Failed to create checkpoint store: %v", err)
+    }
+    publisher := &recordingPublisher{}
+    reprocessor, err := processor.NewReprocessor(archive, p, publisher, checkpoints)
+    if err != nil {
+        t.Fatalf("Failed to create reprocessor: %v", err)
+    }
+
+    cfg := processor.ReprocessConfig{
+        Bucket:      "archive",
+        Prefix:      "bronze",
+        ClientID:    testClientID,
+        From:        from,
+        To:          to,
+        Concurrency: 1,
+        JobID:       "reprocess-test",
+    }
+
+    t.Run("Dry run reports counts without writing", func(t *testing.T) {
+        dryRun := cfg
+        dryRun.DryRun = true
+        result, err := reprocessor.Run(context.Background(), dryRun)
+        if err != nil {
+            t.Fatalf("Unexpected error: %v", err)
+        }
+        if result.Objects != 4 || result.Events != 4 || result.Normalized != 4 || result.Published != 0 {
+            t.Errorf("Unexpected dry run result: %+v", result)
+        }
+        if len(publisher.events) != 0 {
+            t.Errorf("Dry run published %d events", len(publisher.events))
+        }
+        if checkpoint, _ := checkpoints.Load(cfg.JobID); checkpoint != "" {
+            t.Errorf("Dry run saved checkpoint %q", checkpoint)
+        }
+    })
+
+    t.Run("Crash leaves a checkpoint", func(t *testing.T) {
+        archive.failKey = day + "1705750200"
+        defer func() { archive.failKey = "" }()
+
+        result, err := reprocessor.Run(context.Background(), cfg)
+        if err == nil {
+            t.Fatal("Expected the failed object to stop the run")
+        }
+        if result.Published != 3 {
+            t.Errorf("Expected 3 events published before the failure, got %d", result.Published)
+        }
+        if checkpoint, _ := checkpoints.Load(cfg.JobID); checkpoint != day+"1705746600" {
+            t.Errorf("Expected checkpoint at the last complete object, got %q", checkpoint)
+        }
+    })
+
+    t.Run("Resume continues from the checkpoint", func(t *testing.T) {
+        result, err := reprocessor.Run(context.Background(), cfg)
+        if err != nil {
+            t.Fatalf("Unexpected error: %v", err)
+        }
+        if result.ObjectsResumed != 2 || result.Objects != 2 || result.Published != 1 {
+            t.Errorf("Unexpected resumed result: %+v", result)
+        }
+        if len(publisher.events) != 4 {
+            t.Errorf("Expected each archived event published once, got %d", len(publisher.events))
+        }
+        // Events are handed to the producer's Serializer, not pre-encoded
+        for _, value := range publisher.events {
+            event, ok := value.(*schema.SilverEvent)
+            if !ok {
+                t.Fatalf("Expected Silver events to be published, got %T", value)
+            }
+            if event.ClientID != testClientID {
+                t.Errorf("Published event of client %q", event.ClientID)
+            }
+        }
+    })
+}
+
+// recordingDeadLetter keeps the messages dead-lettered through it
+type recordingDeadLetter struct {
+    mu       sync.Mutex
+    messages [][]byte
+}
+
+func (d *recordingDeadLetter) PublishWithHeaders(ctx context.Context, event []byte, headers map[string]string) error {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    d.messages = append(d.messages, event)
+    return nil
+}
+
+// TestPipelineDeadLetterCardinality verifies the per-client dead-letter
+// counter buckets clients beyond its label limit into the overflow value
+func TestPipelineDeadLetterCardinality(t *testing.T) {
+    metrics.SetLabelValueLimit(normalizer.DeadLetterMetric, 3)
+    defer metrics.SetLabelValueLimit(normalizer.DeadLetterMetric, metrics.DefaultMaxLabelValues)
+
+    p, err := processor.NewProcessor(mapper.NewFieldMapper(nil, nil), transformer.NewTransformer(testTimeout), testTimeout)
+    if err != nil {
+        t.Fatalf("Failed to create processor: %v", err)
+    }
+    pipeline, err := normalizer.NewPipeline(p, &recordingPublisher{}, normalizer.PipelineConfig{})
+    if err != nil {
+        t.Fatalf("Failed to create pipeline: %v", err)
+    }
+    deadLetter := &recordingDeadLetter{}
+    pipeline.SetDeadLetter(deadLetter)
+
+    // Undecodable messages from more clients than the limit allows
+    messages := make([]*streaming.Message, 5)
+    for i := range messages {
+        messages[i] = &streaming.Message{
+            Topic:   "bronze-events",
+            Offset:  int64(i),
+            Value:   []byte("{not json"),
+            Headers: map[string]string{streaming.HeaderTenant: fmt.Sprintf("cardinality-client-%d", i)},
+        }
+    }
+    if err := pipeline.HandleBatch(context.Background(), messages); err != nil {
+        t.Fatalf("Failed to handle batch: %v", err)
+    }
+    if len(deadLetter.messages) != len(messages) {
+        t.Fatalf("Expected %d dead-lettered messages, got %d", len(messages), len(deadLetter.messages))
+    }
+
+    reg, ok := prometheus.DefaultGatherer.(*prometheus.Registry)
+    if !ok {
+        t.Fatal("Expected the default gatherer to be a registry")
+    }
+    for i := 0; i < 3; i++ {
+        client := fmt.Sprintf("cardinality-client-%d", i)
+        if got := gatheredValue(t, reg, normalizer.DeadLetterMetric, map[string]string{"client_id": client}); got != 1 {
+            t.Errorf("Expected 1 dead-letter for %s, got %v", client, got)
+        }
+    }
+    if got := gatheredValue(t, reg, normalizer.DeadLetterMetric, map[string]string{"client_id": metrics.OverflowLabelValue}); got != 2 {
+        t.Errorf("Expected clients over the limit counted as %q, got %v", metrics.OverflowLabelValue, got)
+    }
+    if got := gatheredValue(t, reg, normalizer.DeadLetterMetric, map[string]string{"client_id": "cardinality-client-4"}); got != 0 {
+        t.Errorf("Expected no series for a client over the limit, got %v", got)
+    }
+}
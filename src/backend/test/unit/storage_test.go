@@ -0,0 +1,609 @@
+// Package unit provides unit tests for S3 storage operations
+package unit
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/url"
+    "sort"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/alicebob/miniredis/v2"
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/credentials"
+    "github.com/aws/aws-sdk-go-v2/service/s3"
+    "github.com/aws/aws-sdk-go-v2/service/s3/types"
+    "github.com/aws/smithy-go"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "github.com/blackpoint/internal/storage"
+    "github.com/blackpoint/pkg/silver"
+)
+
+// flakyS3 fails PutObject with a fixed error until failures runs out
+type flakyS3 struct {
+    storage.S3API
+    failures int
+    err      error
+    calls    int
+}
+
+func (m *flakyS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+    m.calls++
+    if m.calls <= m.failures {
+        return nil, m.err
+    }
+    return &s3.PutObjectOutput{}, nil
+}
+
+// newRetryingS3Client builds a client with fast retries around a mock
+func newRetryingS3Client(api storage.S3API) *storage.S3Client {
+    return storage.NewS3ClientWithAPI(&storage.S3Config{
+        NetworkTimeout: testTimeout,
+        RetryConfig: &storage.RetryConfig{
+            MaxRetries:        3,
+            RetryInterval:     time.Millisecond,
+            BackoffMultiplier: 2,
+        },
+    }, api)
+}
+
+// TestS3RetryTransientFailure verifies throttled requests are retried until they succeed
+func TestS3RetryTransientFailure(t *testing.T) {
+    mockS3 := &flakyS3{
+        failures: 2,
+        err:      &smithy.GenericAPIError{Code: "SlowDown", Message: "reduce your request rate"},
+    }
+    client := newRetryingS3Client(mockS3)
+
+    err := client.PutObject("blackpoint-security-bronze", "events/1.json", []byte(`{"id":1}`))
+    require.NoError(t, err)
+    assert.Equal(t, 3, mockS3.calls, "expected two failed attempts and one success")
+}
+
+// TestS3RetryPermanentFailure verifies access errors are not retried
+func TestS3RetryPermanentFailure(t *testing.T) {
+    mockS3 := &flakyS3{
+        failures: 5,
+        err:      &smithy.GenericAPIError{Code: "AccessDenied", Message: "access denied"},
+    }
+    client := newRetryingS3Client(mockS3)
+
+    err := client.PutObject("blackpoint-security-bronze", "events/1.json", []byte(`{"id":1}`))
+    assert.Error(t, err)
+    assert.Equal(t, 1, mockS3.calls, "non-retryable errors must fail on the first attempt")
+}
+
+// conditionalS3 honors If-None-Match on PutObject against an in-memory key set
+type conditionalS3 struct {
+    storage.S3API
+    existing map[string]bool
+    puts     []*s3.PutObjectInput
+}
+
+func (m *conditionalS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+    m.puts = append(m.puts, params)
+    key := *params.Key
+    if params.IfNoneMatch != nil && *params.IfNoneMatch == "*" && m.existing[key] {
+        return nil, &smithy.GenericAPIError{Code: "PreconditionFailed", Message: "At least one of the pre-conditions you specified did not hold"}
+    }
+    m.existing[key] = true
+    return &s3.PutObjectOutput{}, nil
+}
+
+// TestS3ContentAddressedPut verifies identical content is stored once and a
+// failed precondition is treated as a no-op success
+func TestS3ContentAddressedPut(t *testing.T) {
+    mockS3 := &conditionalS3{existing: make(map[string]bool)}
+    client := newRetryingS3Client(mockS3)
+    data := []byte(`{"alert_id":"gold-1"}`)
+
+    key, status, err := client.PutObjectContentAddressed("blackpoint-security-gold", "archive/", data)
+    require.NoError(t, err)
+    assert.Equal(t, storage.PutCreated, status)
+    assert.Equal(t, storage.ContentKey("archive/", data), key)
+    require.Len(t, mockS3.puts, 1)
+    require.NotNil(t, mockS3.puts[0].IfNoneMatch, "content-addressed puts must be conditional")
+    assert.Equal(t, "*", *mockS3.puts[0].IfNoneMatch)
+
+    // Reprocessing the same content hits the precondition instead of overwriting
+    again, status, err := client.PutObjectContentAddressed("blackpoint-security-gold", "archive/", data)
+    require.NoError(t, err, "an existing object must not be reported as a failure")
+    assert.Equal(t, storage.PutAlreadyExists, status)
+    assert.Equal(t, key, again)
+    assert.Len(t, mockS3.puts, 2, "a failed precondition must not be retried")
+
+    // Different content gets its own key
+    other, status, err := client.PutObjectContentAddressed("blackpoint-security-gold", "archive/", []byte(`{"alert_id":"gold-2"}`))
+    require.NoError(t, err)
+    assert.Equal(t, storage.PutCreated, status)
+    assert.NotEqual(t, key, other)
+}
+
+// newPresigningS3Client builds a client around a real SDK client with static
+// credentials; presigning is local and needs no network access
+func newPresigningS3Client(maxTTL time.Duration) *storage.S3Client {
+    api := s3.New(s3.Options{
+        Region:      "us-west-2",
+        Credentials: credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "test-secret", ""),
+    })
+    return storage.NewS3ClientWithAPI(&storage.S3Config{
+        KmsKeyAlias:   "alias/blackpoint-security",
+        MaxPresignTTL: maxTTL,
+    }, api)
+}
+
+// TestS3PresignedURLs verifies presigned URLs are signed and expire as requested
+func TestS3PresignedURLs(t *testing.T) {
+    client := newPresigningS3Client(time.Hour)
+    ctx := storage.WithRequester(context.Background(), "analyst@example.com")
+
+    rawURL, err := client.PresignGetObjectWithContext(ctx, "blackpoint-security-gold", "archive/alert-1.json", 15*time.Minute)
+    require.NoError(t, err)
+    presigned, err := url.Parse(rawURL)
+    require.NoError(t, err)
+    assert.Equal(t, "https", presigned.Scheme)
+    assert.Contains(t, presigned.Host+presigned.Path, "blackpoint-security-gold")
+    assert.Contains(t, presigned.Path, "archive/alert-1.json")
+
+    query := presigned.Query()
+    assert.Equal(t, "AWS4-HMAC-SHA256", query.Get("X-Amz-Algorithm"))
+    assert.Equal(t, "900", query.Get("X-Amz-Expires"), "URL must expire after the requested TTL")
+    assert.True(t, strings.HasPrefix(query.Get("X-Amz-Credential"), "AKIDEXAMPLE/"))
+    assert.NotEmpty(t, query.Get("X-Amz-Signature"))
+
+    // Uploads are signed with the KMS encryption headers
+    rawURL, err = client.PresignPutObject("blackpoint-security-gold", "archive/alert-2.json", time.Minute)
+    require.NoError(t, err)
+    presigned, err = url.Parse(rawURL)
+    require.NoError(t, err)
+    assert.Equal(t, "60", presigned.Query().Get("X-Amz-Expires"))
+    assert.Contains(t, presigned.Query().Get("X-Amz-SignedHeaders"), "x-amz-server-side-encryption")
+
+    // Lifetimes beyond the configured maximum are refused
+    _, err = client.PresignGetObject("blackpoint-security-gold", "archive/alert-1.json", 2*time.Hour)
+    assert.Error(t, err)
+    _, err = client.PresignGetObject("blackpoint-security-gold", "archive/alert-1.json", 0)
+    assert.Error(t, err)
+
+    // Without a presigner, as with a mock API, presigning fails cleanly
+    _, err = newRetryingS3Client(&flakyS3{}).PresignGetObject("blackpoint-security-gold", "archive/alert-1.json", time.Minute)
+    assert.Error(t, err)
+}
+
+// multipartS3 keeps multipart uploads in memory and fails UploadPart for failPart
+type multipartS3 struct {
+    storage.S3API
+    mu        sync.Mutex
+    failPart  int32
+    parts     map[int32]int
+    completed []int32
+    aborted   []string
+}
+
+func (m *multipartS3) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.parts = make(map[int32]int)
+    return &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-1")}, nil
+}
+
+func (m *multipartS3) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+    number := aws.ToInt32(params.PartNumber)
+    if number == m.failPart {
+        return nil, &smithy.GenericAPIError{Code: "AccessDenied", Message: "access denied"}
+    }
+    data, err := io.ReadAll(params.Body)
+    if err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.parts[number] = len(data)
+    return &s3.UploadPartOutput{ETag: aws.String(fmt.Sprintf("etag-%d", number))}, nil
+}
+
+func (m *multipartS3) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for _, part := range params.MultipartUpload.Parts {
+        m.completed = append(m.completed, aws.ToInt32(part.PartNumber))
+    }
+    return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (m *multipartS3) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.aborted = append(m.aborted, aws.ToString(params.UploadId))
+    m.parts = nil
+    return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+// TestS3PutObjectStream verifies parts are assembled in order and that a
+// failed part aborts the upload instead of completing it
+func TestS3PutObjectStream(t *testing.T) {
+    // 12MB splits into two 5MB parts and a 2MB tail
+    data := bytes.Repeat([]byte("x"), 12*1024*1024)
+
+    t.Run("completes parts in order", func(t *testing.T) {
+        mockS3 := &multipartS3{}
+        err := newRetryingS3Client(mockS3).PutObjectStream("blackpoint-security-bronze", "archive/large.json", bytes.NewReader(data), int64(len(data)))
+        require.NoError(t, err)
+
+        assert.Equal(t, []int32{1, 2, 3}, mockS3.completed)
+        assert.Equal(t, 2*1024*1024, mockS3.parts[3])
+        assert.Empty(t, mockS3.aborted)
+    })
+
+    t.Run("failed part aborts the upload", func(t *testing.T) {
+        mockS3 := &multipartS3{failPart: 2}
+        err := newRetryingS3Client(mockS3).PutObjectStream("blackpoint-security-bronze", "archive/large.json", bytes.NewReader(data), int64(len(data)))
+        require.Error(t, err)
+        assert.Contains(t, err.Error(), "failed to upload part")
+
+        assert.Empty(t, mockS3.completed, "a failed upload must not be completed")
+        assert.Equal(t, []string{"upload-1"}, mockS3.aborted)
+        assert.Empty(t, mockS3.parts, "aborting discards the uploaded parts")
+    })
+}
+
+// slowS3 serves GetObject from memory after a fixed latency
+type slowS3 struct {
+    storage.S3API
+    latency time.Duration
+    objects map[string][]byte
+}
+
+func (m *slowS3) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+    select {
+    case <-time.After(m.latency):
+    case <-ctx.Done():
+        return nil, ctx.Err()
+    }
+    data, ok := m.objects[*params.Key]
+    if !ok {
+        return nil, &smithy.GenericAPIError{Code: "NoSuchKey", Message: "not found"}
+    }
+    return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+// newSlowS3 builds a mock holding count small bronze events
+func newSlowS3(count int) (*slowS3, []string) {
+    mockS3 := &slowS3{latency: time.Millisecond, objects: make(map[string][]byte, count)}
+    keys := make([]string, count)
+    for i := range keys {
+        keys[i] = fmt.Sprintf("bronze/%s/%04d.json", testClientID, i)
+        mockS3.objects[keys[i]] = []byte(fmt.Sprintf(`{"id":%d}`, i))
+    }
+    return mockS3, keys
+}
+
+// TestGetObjectsPartialFailure verifies a missing key does not fail the batch
+func TestGetObjectsPartialFailure(t *testing.T) {
+    mockS3, keys := newSlowS3(20)
+    client := newRetryingS3Client(mockS3)
+
+    objects, err := client.GetObjects(append(keys, "bronze/missing.json"), "blackpoint-security-bronze")
+    require.Error(t, err)
+
+    var batchErr *storage.BatchGetError
+    require.ErrorAs(t, err, &batchErr)
+    assert.Len(t, batchErr.Failed, 1)
+    assert.Contains(t, batchErr.Failed, "bronze/missing.json")
+    assert.Len(t, objects, len(keys))
+    assert.Equal(t, mockS3.objects[keys[3]], objects[keys[3]])
+}
+
+// BenchmarkGetObjects compares serial and parallel retrieval of 500 keys
+func BenchmarkGetObjects(b *testing.B) {
+    mockS3, keys := newSlowS3(500)
+    client := newRetryingS3Client(mockS3)
+    bucket := "blackpoint-security-bronze"
+
+    b.Run("serial", func(b *testing.B) {
+        for i := 0; i < b.N; i++ {
+            for _, key := range keys {
+                if _, err := client.GetObject(bucket, key); err != nil {
+                    b.Fatal(err)
+                }
+            }
+        }
+    })
+
+    b.Run("parallel", func(b *testing.B) {
+        for i := 0; i < b.N; i++ {
+            if _, err := client.GetObjects(keys, bucket); err != nil {
+                b.Fatal(err)
+            }
+        }
+    })
+}
+
+// TestS3TransitionRulesValidation verifies misordered transitions are rejected
+// before any AWS call is made
+func TestS3TransitionRulesValidation(t *testing.T) {
+    newConfig := func(rules []storage.TransitionRule) *storage.S3Config {
+        return &storage.S3Config{
+            Region:           "us-west-2",
+            NetworkTimeout:   testTimeout,
+            RetentionPeriods: map[string]int{"bronze": 30},
+            TransitionRules:  map[string][]storage.TransitionRule{"bronze": rules},
+        }
+    }
+
+    invalid := map[string]struct {
+        rules   []storage.TransitionRule
+        message string
+    }{
+        "not increasing": {
+            rules: []storage.TransitionRule{
+                {Days: 14, StorageClass: "STANDARD_IA"},
+                {Days: 7, StorageClass: "GLACIER"},
+            },
+            message: "transition days must be positive and strictly increasing",
+        },
+        "not positive": {
+            rules:   []storage.TransitionRule{{Days: 0, StorageClass: "GLACIER"}},
+            message: "transition days must be positive and strictly increasing",
+        },
+        "after expiration": {
+            rules:   []storage.TransitionRule{{Days: 30, StorageClass: "GLACIER"}},
+            message: "transition must happen before expiration",
+        },
+        "unknown storage class": {
+            rules:   []storage.TransitionRule{{Days: 7, StorageClass: "TAPE"}},
+            message: "unsupported transition storage class",
+        },
+    }
+    for name, tc := range invalid {
+        _, err := storage.NewS3Client(newConfig(tc.rules))
+        if assert.Error(t, err, name) {
+            assert.Contains(t, err.Error(), tc.message, name)
+        }
+    }
+}
+
+// memoryS3 stores objects in memory and lists them in key order
+type memoryS3 struct {
+    storage.S3API
+    objects map[string][]byte
+}
+
+func (m *memoryS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+    data, err := io.ReadAll(params.Body)
+    if err != nil {
+        return nil, err
+    }
+    m.objects[*params.Key] = data
+    return &s3.PutObjectOutput{}, nil
+}
+
+func (m *memoryS3) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+    data, ok := m.objects[*params.Key]
+    if !ok {
+        return nil, &smithy.GenericAPIError{Code: "NoSuchKey", Message: "the specified key does not exist"}
+    }
+    return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (m *memoryS3) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+    keys := make([]string, 0, len(m.objects))
+    for key := range m.objects {
+        if strings.HasPrefix(key, aws.ToString(params.Prefix)) {
+            keys = append(keys, key)
+        }
+    }
+    sort.Strings(keys)
+
+    output := &s3.ListObjectsV2Output{}
+    for _, key := range keys {
+        output.Contents = append(output.Contents, types.Object{Key: aws.String(key)})
+    }
+    return output, nil
+}
+
+// TestFieldPurgeRemovesIndexedAttributes verifies an expired field is removed
+// from the stored event, its attribute sidecar and the Redis attribute index
+func TestFieldPurgeRemovesIndexedAttributes(t *testing.T) {
+    ctx := context.Background()
+    bucket := "blackpoint-security-silver"
+    api := &memoryS3{objects: make(map[string][]byte)}
+    client := newRetryingS3Client(api)
+
+    server := miniredis.RunT(t)
+    redisClient, err := storage.NewRedisClient(&storage.RedisConfig{Addresses: []string{server.Addr()}})
+    require.NoError(t, err)
+    defer redisClient.Close()
+
+    indexer, err := storage.NewAttributeIndexer(client, redisClient, storage.AttributeIndexConfig{WriteSidecar: true})
+    require.NoError(t, err)
+
+    eventTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    event := &silver.SilverEvent{
+        EventID:   "evt-1",
+        ClientID:  "client-1",
+        EventTime: eventTime,
+        NormalizedData: map[string]interface{}{
+            "src_user":   "alice@example.com",
+            "event_type": "login",
+        },
+    }
+    key := "events/client-1/evt-1.json"
+    require.NoError(t, indexer.StoreEvent(ctx, bucket, key, event))
+
+    keys, err := indexer.FindObjects(ctx, bucket, map[string]string{"user": "alice@example.com"})
+    require.NoError(t, err)
+    require.Equal(t, []string{key}, keys)
+
+    purger, err := storage.NewFieldPurger(client, storage.FieldPurgeConfig{
+        Bucket:     bucket,
+        Prefix:     "events/",
+        Policy:     silver.RetentionPolicy{"src_user": 30 * 24 * time.Hour},
+        Attributes: indexer,
+    })
+    require.NoError(t, err)
+
+    result, err := purger.Purge(ctx, eventTime.Add(31*24*time.Hour))
+    require.NoError(t, err)
+    assert.Equal(t, 1, result.ObjectsScanned, "sidecars must not be scanned as events")
+    assert.Equal(t, 1, result.FieldsPurged)
+    assert.Equal(t, 0, result.Errors)
+
+    metadata, err := indexer.GetAttributes(bucket, key)
+    require.NoError(t, err)
+    assert.NotContains(t, metadata.Attributes, "user", "the sidecar must not keep the purged value")
+    assert.Equal(t, "login", metadata.Attributes["action"])
+    assert.NotContains(t, string(api.objects[key+".attrs.json"]), "alice")
+
+    keys, err = indexer.FindObjects(ctx, bucket, map[string]string{"user": "alice@example.com"})
+    require.NoError(t, err)
+    assert.Empty(t, keys, "the purged value must no longer match")
+
+    keys, err = indexer.FindObjects(ctx, bucket, map[string]string{"action": "login"})
+    require.NoError(t, err)
+    assert.Equal(t, []string{key}, keys, "retained attributes stay indexed")
+}
+
+// nestedRetentionEvent returns an event with expiring fields at the top level,
+// inside a nested object and inside each element of a slice
+func nestedRetentionEvent(eventTime time.Time) *silver.SilverEvent {
+    return &silver.SilverEvent{
+        EventID:   "evt-nested",
+        ClientID:  "client-1",
+        EventTime: eventTime,
+        NormalizedData: map[string]interface{}{
+            "src_ip": "10.0.0.1",
+            "user": map[string]interface{}{
+                "name":  "alice",
+                "email": "alice@example.com",
+            },
+            "devices": []interface{}{
+                map[string]interface{}{"model": "laptop", "serial": "SN-1"},
+                map[string]interface{}{"model": "phone", "serial": "SN-2"},
+            },
+        },
+    }
+}
+
+// TestPurgeExpiredNestedFields verifies dot-path retention fields are purged
+// from nested objects and from every element of slices
+func TestPurgeExpiredNestedFields(t *testing.T) {
+    eventTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    event := nestedRetentionEvent(eventTime)
+    require.NoError(t, event.ApplyRetentionPolicy(silver.RetentionPolicy{
+        "user.email":     time.Hour,
+        "devices.serial": time.Hour,
+        "src_ip":         48 * time.Hour,
+    }))
+
+    purged := event.PurgeExpiredFields(eventTime.Add(2 * time.Hour))
+    assert.Equal(t, []string{"devices.serial", "user.email"}, purged)
+    assert.Len(t, event.PurgedFields, 2)
+
+    user := event.NormalizedData["user"].(map[string]interface{})
+    assert.NotContains(t, user, "email")
+    assert.Equal(t, "alice", user["name"])
+    for _, device := range event.NormalizedData["devices"].([]interface{}) {
+        assert.NotContains(t, device, "serial")
+        assert.Contains(t, device, "model")
+    }
+    assert.Equal(t, "10.0.0.1", event.NormalizedData["src_ip"], "unexpired fields are kept")
+
+    next, ok := event.NextFieldExpiry()
+    require.True(t, ok)
+    assert.Equal(t, eventTime.Add(48*time.Hour), next)
+}
+
+// TestFieldPurgeNestedStoredEvents verifies the purger rewrites stored events
+// whose nested fields expired and leaves events without expired fields alone
+func TestFieldPurgeNestedStoredEvents(t *testing.T) {
+    ctx := context.Background()
+    bucket := "blackpoint-security-silver"
+    api := &memoryS3{objects: make(map[string][]byte)}
+    client := newRetryingS3Client(api)
+
+    eventTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    expired, err := json.Marshal(nestedRetentionEvent(eventTime))
+    require.NoError(t, err)
+    recent, err := json.Marshal(nestedRetentionEvent(eventTime.Add(30 * 24 * time.Hour)))
+    require.NoError(t, err)
+    api.objects["events/client-1/expired.json"] = expired
+    api.objects["events/client-1/recent.json"] = recent
+
+    purger, err := storage.NewFieldPurger(client, storage.FieldPurgeConfig{
+        Bucket: bucket,
+        Prefix: "events/",
+        Policy: silver.RetentionPolicy{"user.email": 7 * 24 * time.Hour, "devices.serial": 7 * 24 * time.Hour},
+    })
+    require.NoError(t, err)
+
+    now := eventTime.Add(8 * 24 * time.Hour)
+    result, err := purger.Purge(ctx, now)
+    require.NoError(t, err)
+    assert.Equal(t, 2, result.ObjectsScanned)
+    assert.Equal(t, 1, result.ObjectsRewritten)
+    assert.Equal(t, 2, result.FieldsPurged)
+    assert.Equal(t, 0, result.Errors)
+
+    stored := string(api.objects["events/client-1/expired.json"])
+    assert.NotContains(t, stored, "alice@example.com")
+    assert.NotContains(t, stored, "SN-1")
+    assert.NotContains(t, stored, "SN-2")
+    assert.Contains(t, stored, "laptop")
+    assert.Equal(t, string(recent), string(api.objects["events/client-1/recent.json"]), "events without expired fields are not rewritten")
+
+    // A second run finds nothing left to purge
+    result, err = purger.Purge(ctx, now)
+    require.NoError(t, err)
+    assert.Equal(t, 0, result.FieldsPurged)
+}
+
+// TestRedisSubscriberReconnects verifies a subscriber resumes delivery after
+// the Redis connection drops and the server comes back
+func TestRedisSubscriberReconnects(t *testing.T) {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    server := miniredis.RunT(t)
+    client, err := storage.NewRedisClient(&storage.RedisConfig{Addresses: []string{server.Addr()}})
+    require.NoError(t, err)
+    defer client.Close()
+
+    received := make(chan string, 16)
+    require.NoError(t, client.Subscribe(ctx, "config", func(msg []byte) {
+        received <- string(msg)
+    }))
+
+    require.NoError(t, client.Publish(ctx, "config", []byte("before")))
+    select {
+    case msg := <-received:
+        assert.Equal(t, "before", msg)
+    case <-time.After(5 * time.Second):
+        t.Fatal("message published before the outage was not delivered")
+    }
+
+    server.Close()
+    require.NoError(t, server.Restart())
+
+    // Messages sent while the subscriber reconnects are lost, so publish
+    // until one arrives
+    require.Eventually(t, func() bool {
+        if err := client.Publish(ctx, "config", []byte("after")); err != nil {
+            return false
+        }
+        select {
+        case msg := <-received:
+            return msg == "after"
+        case <-time.After(50 * time.Millisecond):
+            return false
+        }
+    }, 5*time.Second, 10*time.Millisecond, "subscriber did not resume after the connection was restored")
+}
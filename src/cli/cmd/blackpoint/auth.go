@@ -0,0 +1,90 @@
+// Package blackpoint implements the auth commands of the BlackPoint CLI
+package blackpoint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"blackpoint/cli/internal/auth"
+	"blackpoint/cli/internal/config"
+	"blackpoint/cli/pkg/common/errors"
+)
+
+// defaultCredentialsFile is used when the config does not set a token path
+const defaultCredentialsFile = "credentials.json"
+
+// newAuthCmd creates the auth command group
+func newAuthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage BlackPoint authentication",
+	}
+	cmd.AddCommand(newAuthLoginCmd())
+	return cmd
+}
+
+// newAuthLoginCmd creates the auth login command
+func newAuthLoginCmd() *cobra.Command {
+	var device bool
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Authenticate the CLI",
+		Long: `Authenticate the CLI with BlackPoint.
+
+By default the API key from the configuration or BLACKPOINT_API_KEY is stored.
+With --device, the CLI prints a code to enter in a browser on any device and
+waits for approval, which suits headless hosts without a browser redirect.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAuthLogin(commandContext(cmd), device)
+		},
+	}
+	cmd.Flags().BoolVar(&device, "device", false, "log in with the OAuth device authorization flow")
+	return cmd
+}
+
+// runAuthLogin stores credentials using an API key or the device flow
+func runAuthLogin(ctx context.Context, device bool) error {
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		return err
+	}
+	if cfg.Auth.TokenPath == "" {
+		cfg.Auth.TokenPath = filepath.Join(filepath.Dir(auth.GetDefaultAuthConfigPath()), defaultCredentialsFile)
+	}
+
+	if !device {
+		if cfg.Auth.APIKey == "" {
+			return errors.NewCLIError("E1001", "no API key configured; set BLACKPOINT_API_KEY or use --device", nil)
+		}
+		return auth.SaveCredentials(cfg.Auth)
+	}
+
+	code, err := auth.RequestDeviceCode(ctx, cfg.API.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	// Instructions go to stderr so they never mix with formatted output
+	verificationURL := code.VerificationURI
+	if code.VerificationURIComplete != "" {
+		verificationURL = code.VerificationURIComplete
+	}
+	fmt.Fprintf(os.Stderr, "To log in, open %s and enter the code: %s\n", verificationURL, code.UserCode)
+	fmt.Fprintf(os.Stderr, "Waiting for approval (expires in %d seconds)...\n", code.ExpiresIn)
+
+	tokens, err := auth.WaitForDeviceToken(ctx, cfg.API.Endpoint, code)
+	if err != nil {
+		return err
+	}
+	if err := auth.SaveDeviceTokens(cfg.Auth, tokens.AccessToken, tokens.RefreshToken); err != nil {
+		return errors.WrapError(err, "failed to save device login tokens")
+	}
+
+	fmt.Fprintln(os.Stderr, "Login successful")
+	return nil
+}
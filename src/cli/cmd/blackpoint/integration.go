@@ -0,0 +1,172 @@
+// Package blackpoint implements the integration commands of the BlackPoint CLI
+package blackpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"blackpoint/cli/internal/config"
+	"blackpoint/cli/internal/integration"
+	"blackpoint/cli/pkg/api"
+	"blackpoint/cli/pkg/common/constants"
+	"blackpoint/cli/pkg/common/errors"
+	types "blackpoint/cli/pkg/integration"
+)
+
+// newIntegrationCmd creates the integration command group
+func newIntegrationCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "integration",
+		Short: "Manage security platform integrations",
+	}
+	cmd.AddCommand(newIntegrationStatusCmd())
+	cmd.AddCommand(newIntegrationDeployCmd())
+	cmd.AddCommand(newIntegrationValidateCmd())
+	return cmd
+}
+
+// newIntegrationStatusCmd creates the integration status command
+func newIntegrationStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status <integration-id>...",
+		Short: "Show the deployment status of integrations",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := newIntegrationManager()
+			if err != nil {
+				return err
+			}
+
+			statuses := make(types.DeploymentStatusList, 0, len(args))
+			for _, id := range args {
+				status, err := manager.GetDeploymentStatus(commandContext(cmd), id)
+				if err != nil {
+					return err
+				}
+				statuses = append(statuses, *status)
+			}
+			return renderOutput(statuses)
+		},
+	}
+}
+
+// newIntegrationDeployCmd creates the integration deploy command
+func newIntegrationDeployCmd() *cobra.Command {
+	var (
+		configPath string
+		noRollback bool
+		strategy   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "deploy",
+		Short: "Deploy an integration from a configuration file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			integrationConfig, err := integration.LoadIntegrationConfig(configPath)
+			if err != nil {
+				return err
+			}
+
+			client, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+			deployer, err := integration.NewDeployer(client, constants.DefaultIntegrationTimeout, nil)
+			if err != nil {
+				return err
+			}
+
+			deployErr := deployer.Deploy(commandContext(cmd), integrationConfig, &api.DeploymentOptions{
+				ConfigPath:      configPath,
+				DisableRollback: noRollback,
+				Strategy:        strategy,
+			})
+			status, ok := deployer.DeploymentResult(integrationConfig.ID)
+			if !ok {
+				// Deployment was rejected before it started
+				return deployErr
+			}
+
+			// The result is rendered even on failure so scripts can inspect it
+			if err := renderOutput(types.DeploymentStatusList{status}); err != nil {
+				return err
+			}
+			for _, warning := range status.Warnings {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", warning)
+			}
+			return deployErr
+		},
+	}
+	cmd.Flags().StringVarP(&configPath, "file", "f", "", "integration configuration file")
+	cmd.MarkFlagRequired("file")
+	cmd.Flags().BoolVar(&noRollback, "no-rollback", false, "keep resources of a failed deployment for debugging")
+	cmd.Flags().StringVar(&strategy, "strategy", api.DeploymentStrategyReplace, "deployment strategy (replace, blue-green)")
+	return cmd
+}
+
+// newIntegrationValidateCmd creates the integration validate command
+func newIntegrationValidateCmd() *cobra.Command {
+	var strict, probe bool
+
+	cmd := &cobra.Command{
+		Use:   "validate <config-file>",
+		Short: "Validate an integration configuration file without deploying it",
+		Long: `Validate an integration configuration file locally and report the result of
+the structure, platform-specific, auth and collection checks.
+
+The command exits non-zero when any check fails, including a token expiry
+shorter than one hour. With --strict, warnings also fail validation. With
+--probe, a valid configuration is also checked for connectivity: the platform
+API makes one authenticated call to the target platform and reports latency
+and auth status.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report := integration.BuildValidationReport(args[0], strict)
+			if probe {
+				client, err := newAPIClient()
+				if err != nil {
+					return err
+				}
+				report.Probe(commandContext(cmd), client)
+			}
+			if err := renderOutput(report); err != nil {
+				return err
+			}
+			if !report.Valid {
+				return errors.NewCLIError("E1004", fmt.Sprintf("integration configuration %s is invalid", args[0]), nil)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&strict, "strict", false, "fail validation on warnings")
+	cmd.Flags().BoolVar(&probe, "probe", false, "check the platform is reachable with the configured credentials")
+	return cmd
+}
+
+// newAPIClient creates an API client from the CLI configuration
+func newAPIClient() (*api.APIClient, error) {
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	return api.NewClient(cfg.API.Endpoint, cfg.Auth.APIKey)
+}
+
+// newIntegrationManager creates an integration manager from the CLI configuration
+func newIntegrationManager() (*integration.IntegrationManager, error) {
+	client, err := newAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	return integration.NewIntegrationManager(client, constants.DefaultIntegrationTimeout)
+}
+
+// commandContext returns the command's context, or a background context when unset
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
@@ -0,0 +1,60 @@
+// Package blackpoint implements the monitoring commands of the BlackPoint CLI
+package blackpoint
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"blackpoint/cli/internal/monitor"
+)
+
+// newMonitorCmd creates the monitor command group
+func newMonitorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "monitor",
+		Short: "Monitor the BlackPoint platform",
+	}
+	cmd.AddCommand(newMonitorAlertsCmd())
+	return cmd
+}
+
+// newMonitorAlertsCmd creates the monitor alerts command
+func newMonitorAlertsCmd() *cobra.Command {
+	var (
+		severity  string
+		component string
+		since     time.Duration
+		limit     int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "alerts",
+		Short: "List recent system alerts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			filter := monitor.NewAlertFilter().
+				WithSeverity(severity).
+				WithComponent(component).
+				WithTimeRange(since)
+			if limit > 0 {
+				filter.Limit = limit
+			}
+
+			alerts, err := monitor.GetAlerts(commandContext(cmd), client, filter)
+			if err != nil {
+				return err
+			}
+			return monitor.DisplayAlerts(alerts, outputFormat)
+		},
+	}
+	cmd.Flags().StringVar(&severity, "severity", "", "only show alerts of this severity (critical, high, medium, low, info)")
+	cmd.Flags().StringVar(&component, "component", "", "only show alerts from matching components")
+	cmd.Flags().DurationVar(&since, "since", 24*time.Hour, "how far back to look for alerts (at most 168h)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "maximum number of alerts to show")
+	return cmd
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"blackpoint/cli/internal/output"
 	"blackpoint/cli/pkg/common/constants"
 	"blackpoint/cli/pkg/common/logging"
 	"blackpoint/cli/pkg/common/version"
@@ -39,7 +40,7 @@ Complete documentation is available at https://docs.blackpoint.security`,
 func Execute() error {
 	if err := rootCmd.Execute(); err != nil {
 		// Log error with proper formatting based on output format
-		if outputFormat == "json" {
+		if outputFormat == constants.OutputFormatJSON {
 			fmt.Fprintf(os.Stderr, `{"error": "%s", "code": "%s"}`, err.Error(), "E1000")
 		} else {
 			fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
@@ -56,22 +57,24 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is "+constants.DefaultConfigPath+")")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", constants.DefaultLogLevel, 
 		fmt.Sprintf("set logging level (%s)", strings.Join(constants.ValidLogLevels, ", ")))
-	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", constants.DefaultOutputFormat, 
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", constants.DefaultOutputFormat, 
 		fmt.Sprintf("output format (%s)", strings.Join(constants.ValidOutputFormats, ", ")))
 
 	// Version command
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "version",
 		Short: "Display version information",
-		Run:   runVersion,
+		RunE:  runVersion,
 	})
 
+	rootCmd.AddCommand(newAuthCmd())
+	rootCmd.AddCommand(newIntegrationCmd())
+	rootCmd.AddCommand(newMonitorCmd())
+
 	// Add required subcommands
 	// Note: These would be implemented in separate files
-	// rootCmd.AddCommand(newIntegrateCmd())
 	// rootCmd.AddCommand(newCollectCmd())
 	// rootCmd.AddCommand(newConfigureCmd())
-	// rootCmd.AddCommand(newMonitorCmd())
 
 	// Enable command completion
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
@@ -137,19 +140,41 @@ func initConfig() {
 	}
 }
 
+// versionInfo describes the CLI build for version output
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Headers returns the column headers for version output
+func (v versionInfo) Headers() []string {
+	return []string{"Version", "Commit", "Build Date", "Go Version", "Platform"}
+}
+
+// Rows returns the version information as a single row
+func (v versionInfo) Rows() [][]string {
+	return [][]string{{v.Version, v.Commit, v.BuildDate, v.GoVersion, v.Platform}}
+}
+
 // runVersion displays version information in the configured output format
-func runVersion(cmd *cobra.Command, args []string) {
-	if outputFormat == "json" {
-		fmt.Printf(`{
-  "version": "%s",
-  "commit": "%s",
-  "build_date": "%s",
-  "go_version": "%s",
-  "platform": "%s"
-}`, version.GetVersion(), version.GitCommit, version.BuildDate, version.GoVersion, version.Platform)
-	} else {
-		fmt.Print(version.GetVersionInfo())
-	}
+func runVersion(cmd *cobra.Command, args []string) error {
+	return renderOutput(versionInfo{
+		Version:   version.GetVersion(),
+		Commit:    version.GitCommit,
+		BuildDate: version.BuildDate,
+		GoVersion: version.GoVersion,
+		Platform:  version.Platform,
+	})
+}
+
+// renderOutput writes command results to stdout in the format selected by the
+// global --output flag. List and report commands should use this rather than
+// printing directly so that every command honors the flag consistently.
+func renderOutput(data interface{}) error {
+	return output.Render(os.Stdout, outputFormat, data)
 }
 
 // isValidOutputFormat checks if the provided output format is supported
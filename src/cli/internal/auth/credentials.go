@@ -2,8 +2,8 @@
 package auth
 
 import (
-    "crypto/aes"
     "crypto/rand"
+    "encoding/base64"
     "encoding/json"
     "errors"
     "log"
@@ -24,6 +24,10 @@ var (
     ErrDecryptionFailed    = errors.New("failed to decrypt credentials")
 )
 
+// credentialsKeyFile holds the local key the credentials file is sealed with;
+// it lives next to the credentials file and is created on first save
+const credentialsKeyFile = "credentials.key"
+
 // credentialsFile represents the structure of stored credentials
 type credentialsFile struct {
     APIKey      string `json:"apiKey"`
@@ -194,38 +198,129 @@ func ClearCredentials(config *types.AuthConfig) error {
     return nil
 }
 
+// SaveDeviceTokens stores tokens obtained through device login in the
+// configured credentials file. The access token is the bearer credential the
+// API client sends, so it is stored sealed in the API key slot where
+// LoadCredentials expects it; the refresh token is sealed alongside it.
+func SaveDeviceTokens(config *types.AuthConfig, accessToken, refreshToken string) error {
+    if config == nil || config.TokenPath == "" || accessToken == "" {
+        return ErrInvalidCredentials
+    }
+
+    // The access token must load back as an API key
+    if err := ValidateAPIKey(accessToken); err != nil {
+        return err
+    }
+
+    dir := filepath.Dir(config.TokenPath)
+    if err := os.MkdirAll(dir, constants.ConfigFilePermissions); err != nil {
+        return err
+    }
+
+    encryptedKey, err := encryptCredentials(accessToken, config)
+    if err != nil {
+        return ErrEncryptionFailed
+    }
+    var encryptedRefresh string
+    if refreshToken != "" {
+        if encryptedRefresh, err = encryptCredentials(refreshToken, config); err != nil {
+            return ErrEncryptionFailed
+        }
+    }
+
+    data, err := json.MarshalIndent(credentialsFile{
+        APIKey:       encryptedKey,
+        RefreshToken: encryptedRefresh,
+        Encrypted:    true,
+    }, "", "  ")
+    if err != nil {
+        return err
+    }
+
+    tempFile := config.TokenPath + ".tmp"
+    if err := os.WriteFile(tempFile, data, constants.ConfigFilePermissions); err != nil {
+        return err
+    }
+    if err := os.Rename(tempFile, config.TokenPath); err != nil {
+        os.Remove(tempFile)
+        return err
+    }
+
+    log.Printf("Device login tokens saved to: %s", config.TokenPath)
+
+    return nil
+}
+
 // Helper functions
 
+// encryptCredentials seals data with AES-GCM under the local credentials key
 func encryptCredentials(data string, config *types.AuthConfig) (string, error) {
-    key := make([]byte, 32)
-    if _, err := rand.Read(key); err != nil {
+    key, err := credentialsKey(config, true)
+    if err != nil {
         return "", err
     }
 
-    block, err := aes.NewCipher(key)
+    sealed, err := encryptSensitiveData([]byte(data), key)
     if err != nil {
         return "", err
     }
 
-    ciphertext := make([]byte, len(data))
-    block.Encrypt(ciphertext, []byte(data))
-
-    return string(ciphertext), nil
+    encoded, err := json.Marshal(sealed)
+    if err != nil {
+        return "", err
+    }
+    return base64.StdEncoding.EncodeToString(encoded), nil
 }
 
+// decryptCredentials opens data sealed by encryptCredentials
 func decryptCredentials(data string, config *types.AuthConfig) (string, error) {
-    key := make([]byte, 32)
-    block, err := aes.NewCipher(key)
+    key, err := credentialsKey(config, false)
     if err != nil {
         return "", err
     }
 
-    plaintext := make([]byte, len(data))
-    block.Decrypt(plaintext, []byte(data))
+    encoded, err := base64.StdEncoding.DecodeString(data)
+    if err != nil {
+        return "", err
+    }
+    var sealed encryptedData
+    if err := json.Unmarshal(encoded, &sealed); err != nil {
+        return "", err
+    }
 
+    plaintext, err := decryptSensitiveData(&sealed, key)
+    if err != nil {
+        return "", err
+    }
     return string(plaintext), nil
 }
 
+// credentialsKey reads the local credentials key, generating it when create
+// is set and none exists yet
+func credentialsKey(config *types.AuthConfig, create bool) ([]byte, error) {
+    path := filepath.Join(filepath.Dir(config.TokenPath), credentialsKeyFile)
+
+    key, err := os.ReadFile(path)
+    if err == nil {
+        if len(key) != 32 {
+            return nil, errors.New("invalid credentials key")
+        }
+        return key, nil
+    }
+    if !os.IsNotExist(err) || !create {
+        return nil, err
+    }
+
+    key = make([]byte, 32)
+    if _, err := rand.Read(key); err != nil {
+        return nil, err
+    }
+    if err := os.WriteFile(path, key, constants.ConfigFilePermissions); err != nil {
+        return nil, err
+    }
+    return key, nil
+}
+
 func isValidAPIKeyChar(c rune) bool {
     return (c >= 'a' && c <= 'z') ||
            (c >= 'A' && c <= 'Z') ||
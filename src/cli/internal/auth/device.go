@@ -0,0 +1,98 @@
+// Package auth provides device authorization login for the BlackPoint CLI
+package auth
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "github.com/blackpoint/cli/pkg/common/constants"
+    "github.com/blackpoint/cli/pkg/common/errors"
+)
+
+// Device flow endpoints served by the BlackPoint API
+const (
+    deviceCodePath  = "/api/" + constants.DefaultAPIVersion + "/auth/device/code"
+    deviceTokenPath = "/api/" + constants.DefaultAPIVersion + "/auth/device/token"
+)
+
+// DeviceCode is a pending device authorization the user completes in a browser
+type DeviceCode struct {
+    DeviceCode              string `json:"device_code"`
+    UserCode                string `json:"user_code"`
+    VerificationURI         string `json:"verification_uri"`
+    VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+    ExpiresIn               int    `json:"expires_in"`
+    Interval                int    `json:"interval"`
+}
+
+// DeviceTokens are the tokens issued once a device authorization completes
+type DeviceTokens struct {
+    AccessToken  string    `json:"access_token"`
+    RefreshToken string    `json:"refresh_token"`
+    TokenType    string    `json:"token_type"`
+    Expiry       time.Time `json:"expiry"`
+}
+
+// RequestDeviceCode starts a device authorization against the API endpoint
+func RequestDeviceCode(ctx context.Context, endpoint string) (*DeviceCode, error) {
+    var code DeviceCode
+    if err := postDeviceForm(ctx, endpoint+deviceCodePath, url.Values{}, &code); err != nil {
+        return nil, errors.WrapError(err, "failed to start device login")
+    }
+    if code.DeviceCode == "" || code.UserCode == "" {
+        return nil, errors.NewCLIError("E1001", "invalid device authorization response", nil)
+    }
+    return &code, nil
+}
+
+// WaitForDeviceToken waits until the user approves or denies the device code.
+// The API polls the identity provider on the CLI's behalf, so this is a single
+// request bounded by the device code's lifetime.
+func WaitForDeviceToken(ctx context.Context, endpoint string, code *DeviceCode) (*DeviceTokens, error) {
+    if code == nil {
+        return nil, errors.NewCLIError("E1001", "device code cannot be nil", nil)
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+    defer cancel()
+
+    var tokens DeviceTokens
+    form := url.Values{"device_code": {code.DeviceCode}}
+    if err := postDeviceForm(ctx, endpoint+deviceTokenPath, form, &tokens); err != nil {
+        return nil, errors.WrapError(err, "device login did not complete")
+    }
+    if tokens.AccessToken == "" {
+        return nil, errors.NewCLIError("E1001", "device login returned no access token", nil)
+    }
+    return &tokens, nil
+}
+
+// postDeviceForm posts a form to a device flow endpoint and decodes the JSON reply
+func postDeviceForm(ctx context.Context, target string, form url.Values, result interface{}) error {
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, strings.NewReader(form.Encode()))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    req.Header.Set("Accept", "application/json")
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        var failure struct {
+            Error string `json:"error"`
+        }
+        json.NewDecoder(resp.Body).Decode(&failure)
+        return errors.NewCLIError("E1001", fmt.Sprintf("device login rejected (%d): %s", resp.StatusCode, failure.Error), nil)
+    }
+    return json.NewDecoder(resp.Body).Decode(result)
+}
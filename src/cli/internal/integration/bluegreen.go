@@ -0,0 +1,147 @@
+// Package integration provides blue-green deployment of security platform integrations
+package integration
+
+import (
+    "context"
+    "time"
+
+    "github.com/pkg/errors"
+    "github.com/sirupsen/logrus"
+
+    "../../pkg/integration/types"
+)
+
+// Blue-green version colours. A deployment stages the colour that is not
+// active and cuts over to it, so successive deployments alternate.
+const (
+    versionBlue  = "blue"
+    versionGreen = "green"
+)
+
+// healthyStatus is the status the existing health check reports for a
+// version that is ready to receive traffic
+const healthyStatus = "healthy"
+
+// errDeploymentUnhealthy is returned when a deployed integration or staged
+// version fails its health check
+var errDeploymentUnhealthy = errors.New("deployment failed health check")
+
+// versionSet is the API's listing of an integration's versions
+type versionSet struct {
+    Active string `json:"active"`
+}
+
+// versionID returns the deployment ID used to monitor one version of an integration
+func versionID(integrationID, version string) string {
+    return integrationID + "/versions/" + version
+}
+
+// otherVersion returns the colour staged when version is active
+func otherVersion(version string) string {
+    if version == versionBlue {
+        return versionGreen
+    }
+    return versionBlue
+}
+
+// activeVersion returns the colour of the integration version currently
+// serving. Integrations deployed before any cutover run as blue.
+func (d *Deployer) activeVersion(ctx context.Context, integrationPath string) (string, error) {
+    var versions versionSet
+    if err := d.apiClient.Get(ctx, integrationPath+"/versions", &versions); err != nil {
+        return "", errors.Wrap(err, "failed to get integration versions")
+    }
+    switch versions.Active {
+    case "":
+        return versionBlue, nil
+    case versionBlue, versionGreen:
+        return versions.Active, nil
+    default:
+        return "", errors.Errorf("unknown active version %q", versions.Active)
+    }
+}
+
+// executeBlueGreen stages the integration as the inactive colour alongside
+// the running one, cuts traffic over once the candidate is healthy and
+// decommissions the replaced version. Until the cutover only the candidate
+// is tracked, so a failure rolls it back and leaves the active version serving.
+func (d *Deployer) executeBlueGreen(ctx context.Context, integration *types.Integration, tracker *resourceTracker) error {
+    integrationPath := "/api/v1/integrations/" + integration.ID
+
+    active, err := d.activeVersion(ctx, integrationPath)
+    if err != nil {
+        return err
+    }
+    candidate := otherVersion(active)
+    candidatePath := integrationPath + "/versions/" + candidate
+
+    if err := d.postWithRetry(ctx, candidatePath, integration, nil); err != nil {
+        return errors.Wrapf(err, "failed to stage %s version", candidate)
+    }
+    tracker.track(candidate+" version", candidatePath)
+
+    if err := d.waitForHealthy(ctx, versionID(integration.ID, candidate)); err != nil {
+        return err
+    }
+
+    cutover := map[string]string{"from": active, "to": candidate}
+    if err := d.postWithRetry(ctx, integrationPath+"/cutover", cutover, nil); err != nil {
+        return errors.Wrapf(err, "failed to cut traffic over to %s version", candidate)
+    }
+
+    // The candidate is serving now, so it must never be rolled back from here on
+    tracker.resources = nil
+
+    d.logger.WithFields(logrus.Fields{
+        "integration_id": integration.ID,
+        "from":           active,
+        "to":             candidate,
+    }).Info("Cut traffic over to new version")
+
+    // A replaced version left behind is idle, so failing to remove it does
+    // not fail the deployment
+    if err := d.apiClient.Delete(ctx, integrationPath+"/versions/"+active); err != nil {
+        deploymentErrors.WithLabelValues(
+            integration.PlatformType,
+            integration.Config.Environment,
+            "decommission_error",
+        ).Inc()
+        d.logger.WithError(err).WithFields(logrus.Fields{
+            "integration_id": integration.ID,
+            "version":        active,
+        }).Warn("Failed to decommission replaced version")
+    }
+
+    return nil
+}
+
+// waitForHealthy runs the deployment health check against a version until it
+// reports healthy. A version still starting up is checked again; any other
+// status fails immediately.
+func (d *Deployer) waitForHealthy(ctx context.Context, deploymentID string) error {
+    for {
+        status, err := d.checkDeploymentStatus(ctx, deploymentID)
+        if err != nil {
+            return errors.Wrap(errDeploymentUnhealthy, err.Error())
+        }
+
+        switch status {
+        case healthyStatus:
+            return nil
+        case "pending", "in_progress":
+        default:
+            return errors.Wrapf(errDeploymentUnhealthy, "status %q", status)
+        }
+
+        d.logger.WithFields(logrus.Fields{
+            "deployment_id": deploymentID,
+            "status":        status,
+        }).Debug("Waiting for deployment to become healthy")
+
+        select {
+        case <-ctx.Done():
+            return errors.Wrap(errDeploymentUnhealthy, ctx.Err().Error())
+        case <-time.After(deploymentCheckInterval):
+        }
+    }
+}
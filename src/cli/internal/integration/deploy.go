@@ -44,16 +44,17 @@ var (
 
 // Deployer manages the deployment of security platform integrations
 type Deployer struct {
-    apiClient       *client.APIClient
+    apiClient       DeploymentAPI
     timeout         time.Duration
     maxRetries      int
     logger          *logrus.Logger
     deploymentLock  sync.Mutex
     activeDeployments map[string]*types.DeploymentStatus
+    results         map[string]types.DeploymentStatus
 }
 
 // NewDeployer creates a new deployer instance with the specified configuration
-func NewDeployer(client *client.APIClient, timeout time.Duration, logger *logrus.Logger) (*Deployer, error) {
+func NewDeployer(client DeploymentAPI, timeout time.Duration, logger *logrus.Logger) (*Deployer, error) {
     if client == nil {
         return nil, errors.New("API client is required")
     }
@@ -71,6 +72,7 @@ func NewDeployer(client *client.APIClient, timeout time.Duration, logger *logrus
         maxRetries:       defaultMaxRetries,
         logger:           logger,
         activeDeployments: make(map[string]*types.DeploymentStatus),
+        results:          make(map[string]types.DeploymentStatus),
     }, nil
 }
 
@@ -108,17 +110,37 @@ func (d *Deployer) Deploy(ctx context.Context, integration *types.Integration, o
     // Initialize deployment status
     deploymentID := integration.ID
     status := &types.DeploymentStatus{
-        ID:        deploymentID,
-        StartTime: startTime,
-        Status:    "in_progress",
+        ID:           deploymentID,
+        PlatformType: integration.PlatformType,
+        Environment:  integration.Config.Environment,
+        StartTime:    startTime,
+        Status:       "in_progress",
     }
     d.activeDeployments[deploymentID] = status
+
+    // A blue-green transition monitors both colours too
+    monitorIDs := []string{deploymentID}
+    if options.Strategy == client.DeploymentStrategyBlueGreen {
+        for _, version := range []string{versionBlue, versionGreen} {
+            id := versionID(deploymentID, version)
+            d.activeDeployments[id] = &types.DeploymentStatus{
+                ID:           id,
+                PlatformType: integration.PlatformType,
+                Environment:  integration.Config.Environment,
+                StartTime:    startTime,
+                Status:       "in_progress",
+            }
+            monitorIDs = append(monitorIDs, id)
+        }
+    }
     d.deploymentLock.Unlock()
 
     // Cleanup deployment status when done
     defer func() {
         d.deploymentLock.Lock()
-        delete(d.activeDeployments, deploymentID)
+        for _, id := range monitorIDs {
+            delete(d.activeDeployments, id)
+        }
         d.deploymentLock.Unlock()
     }()
 
@@ -126,11 +148,13 @@ func (d *Deployer) Deploy(ctx context.Context, integration *types.Integration, o
     monitorCtx, monitorCancel := context.WithCancel(deployCtx)
     defer monitorCancel()
     
-    go func() {
-        if err := d.MonitorDeployment(monitorCtx, deploymentID); err != nil {
-            d.logger.WithError(err).Error("Deployment monitoring failed")
-        }
-    }()
+    for _, id := range monitorIDs {
+        go func(id string) {
+            if err := d.MonitorDeployment(monitorCtx, id); err != nil {
+                d.logger.WithError(err).Error("Deployment monitoring failed")
+            }
+        }(id)
+    }
 
     // Execute deployment
     d.logger.WithFields(logrus.Fields{
@@ -139,22 +163,44 @@ func (d *Deployer) Deploy(ctx context.Context, integration *types.Integration, o
         "environment":      integration.Config.Environment,
     }).Info("Starting integration deployment")
 
-    // Deploy integration resources
-    err := d.executeDeployment(deployCtx, integration, options)
+    // Deploy integration resources, tearing down what was created on failure
+    tracker := &resourceTracker{}
+    var err error
+    if options.Strategy == client.DeploymentStrategyBlueGreen {
+        err = d.executeBlueGreen(deployCtx, integration, tracker)
+    } else {
+        err = d.executeDeployment(deployCtx, integration, status, tracker)
+    }
+
     if err != nil {
         status.Status = "failed"
         status.Error = err.Error()
+        errorType := "deployment_error"
+        if errors.Cause(err) == errDeploymentUnhealthy {
+            errorType = "health_check_failed"
+        }
         deploymentErrors.WithLabelValues(
             integration.PlatformType,
             integration.Config.Environment,
-            "deployment_error",
+            errorType,
         ).Inc()
+
+        if !options.DisableRollback && len(tracker.resources) > 0 {
+            if rbErr := d.rollback(integration, tracker); rbErr != nil {
+                status.Status = StatusRollbackFailed
+                status.Error = err.Error() + "; " + rbErr.Error()
+            } else {
+                status.Status = StatusRolledBack
+            }
+        }
+        d.recordResult(status)
         return errors.Wrap(err, "deployment execution failed")
     }
 
     // Update deployment status
     status.Status = "completed"
     status.CompletionTime = time.Now()
+    d.recordResult(status)
     
     deploymentStatus.WithLabelValues(
         integration.PlatformType,
@@ -181,9 +227,23 @@ func (d *Deployer) ValidateDeployment(integration *types.Integration, options *c
         return errors.New("deployment options are required")
     }
 
-    // Load and validate integration configuration
-    if err := config.ValidateConfigFile(options.ConfigPath); err != nil {
-        return errors.Wrap(err, "invalid integration configuration file")
+    switch options.Strategy {
+    case "", client.DeploymentStrategyReplace, client.DeploymentStrategyBlueGreen:
+    default:
+        return errors.Errorf("unsupported deployment strategy %q", options.Strategy)
+    }
+
+    // Apply the security checks a configuration file gets, so integrations
+    // built in memory are held to the same rules
+    if err := validateSecurityConstraints(integration); err != nil {
+        return errors.Wrap(err, "integration failed security validation")
+    }
+
+    // Load and validate the integration configuration file it came from
+    if options.ConfigPath != "" {
+        if err := config.ValidateConfigFile(options.ConfigPath); err != nil {
+            return errors.Wrap(err, "invalid integration configuration file")
+        }
     }
 
     return nil
@@ -220,16 +280,82 @@ func (d *Deployer) MonitorDeployment(ctx context.Context, deploymentID string) e
     }
 }
 
-// executeDeployment performs the actual deployment with retry logic
-func (d *Deployer) executeDeployment(ctx context.Context, integration *types.Integration, options *client.DeploymentOptions) error {
+// DeploymentResult returns the final status of the last finished deployment
+// of an integration
+func (d *Deployer) DeploymentResult(integrationID string) (types.DeploymentStatus, bool) {
+    d.deploymentLock.Lock()
+    defer d.deploymentLock.Unlock()
+
+    status, ok := d.results[integrationID]
+    return status, ok
+}
+
+// recordResult stores the final status of a finished deployment
+func (d *Deployer) recordResult(status *types.DeploymentStatus) {
+    if status.CompletionTime.IsZero() {
+        status.CompletionTime = time.Now()
+    }
+
+    d.deploymentLock.Lock()
+    d.results[status.ID] = *status
+    d.deploymentLock.Unlock()
+}
+
+// executeDeployment deploys the integration through the integrations API and
+// waits for it to report healthy. The created integration is recorded with
+// the tracker as soon as the API accepts it, so a failed health check rolls
+// it back. Validation warnings returned by the API are kept on the status.
+func (d *Deployer) executeDeployment(ctx context.Context, integration *types.Integration, status *types.DeploymentStatus, tracker *resourceTracker) error {
+    var created struct {
+        IntegrationID string `json:"integration_id"`
+        Warnings      []struct {
+            Field   string `json:"field"`
+            Message string `json:"message"`
+        } `json:"warnings"`
+    }
+    if err := d.postWithRetry(ctx, "/api/v1/integrations", integration, &created); err != nil {
+        return errors.Wrap(err, "failed to create integration")
+    }
+
+    for _, warning := range created.Warnings {
+        status.Warnings = append(status.Warnings, warning.Field+": "+warning.Message)
+        d.logger.WithFields(logrus.Fields{
+            "integration_id": integration.ID,
+            "field":          warning.Field,
+        }).Warn(warning.Message)
+    }
+
+    // The API assigns the ID of the integration it deployed
+    deployedID := created.IntegrationID
+    if deployedID == "" {
+        deployedID = integration.ID
+    }
+    tracker.track("integration", "/api/v1/integrations/"+deployedID)
+
+    if deployedID != integration.ID {
+        d.logger.WithFields(logrus.Fields{
+            "integration_id": integration.ID,
+            "deployed_id":    deployedID,
+        }).Info("Integration deployed under API-assigned ID")
+    }
+
+    return d.waitForHealthy(ctx, deployedID)
+}
+
+// postWithRetry posts to the API, retrying transient errors. The response is
+// decoded into result when it is not nil.
+func (d *Deployer) postWithRetry(ctx context.Context, endpoint string, body, result interface{}) error {
     var lastErr error
     for attempt := 0; attempt <= d.maxRetries; attempt++ {
         if attempt > 0 {
-            d.logger.WithField("attempt", attempt).Info("Retrying deployment")
+            d.logger.WithFields(logrus.Fields{
+                "attempt":  attempt,
+                "endpoint": endpoint,
+            }).Info("Retrying deployment")
             time.Sleep(time.Second * time.Duration(attempt))
         }
 
-        err := d.apiClient.Post(ctx, "/api/v1/integrations/deploy", integration, nil)
+        err := d.apiClient.Post(ctx, endpoint, body, result)
         if err == nil {
             return nil
         }
@@ -252,7 +378,7 @@ func (d *Deployer) checkDeploymentStatus(ctx context.Context, deploymentID strin
         Status string `json:"status"`
     }
 
-    err := d.apiClient.Get(ctx, "/api/v1/integrations/status/"+deploymentID, &status)
+    err := d.apiClient.Get(ctx, "/api/v1/integrations/"+deploymentID, &status)
     if err != nil {
         return "", errors.Wrap(err, "failed to get deployment status")
     }
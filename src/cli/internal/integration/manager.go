@@ -147,6 +147,27 @@ func (m *IntegrationManager) GetIntegration(ctx context.Context, integrationID s
     return &result, nil
 }
 
+// GetDeploymentStatus retrieves the latest deployment status of an integration
+func (m *IntegrationManager) GetDeploymentStatus(ctx context.Context, integrationID string) (*types.DeploymentStatus, error) {
+    if integrationID == "" {
+        return nil, errors.NewCLIError("E1004", "Integration ID is required", nil)
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, m.timeout)
+    defer cancel()
+
+    var result types.DeploymentStatus
+    err := m.apiClient.Get(ctx, fmt.Sprintf("/api/v1/integrations/status/%s", integrationID), &result)
+    if err != nil {
+        return nil, errors.WrapError(err, "Failed to retrieve deployment status")
+    }
+    if result.ID == "" {
+        result.ID = integrationID
+    }
+
+    return &result, nil
+}
+
 // ListIntegrations retrieves all integrations with filtering support
 func (m *IntegrationManager) ListIntegrations(ctx context.Context) ([]types.Integration, error) {
     ctx, cancel := context.WithTimeout(ctx, m.timeout)
@@ -0,0 +1,84 @@
+// Package integration provides rollback of partially deployed integrations
+package integration
+
+import (
+    "context"
+    "time"
+
+    "github.com/pkg/errors"
+    "github.com/sirupsen/logrus"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/promauto"
+
+    "../../pkg/integration/types"
+)
+
+// Deployment statuses set when a failed deployment is rolled back
+const (
+    StatusRolledBack     = "rolled_back"
+    StatusRollbackFailed = "rollback_failed"
+)
+
+// rollbackTimeout bounds the teardown of a failed deployment. It is separate
+// from the deploy context, which has usually expired by the time of rollback.
+var rollbackTimeout = 2 * time.Minute
+
+var rollbackTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+    Name: "blackpoint_integration_rollback_total",
+    Help: "Total number of deployment rollbacks by result",
+}, []string{"result"})
+
+// DeploymentAPI is the part of the API client the Deployer uses
+type DeploymentAPI interface {
+    Get(ctx context.Context, endpoint string, result interface{}) error
+    Post(ctx context.Context, endpoint string, body, result interface{}) error
+    Delete(ctx context.Context, endpoint string) error
+}
+
+// deployedResource is a resource created during a deployment
+type deployedResource struct {
+    kind     string
+    endpoint string
+}
+
+// resourceTracker records the resources a deployment created, in order
+type resourceTracker struct {
+    resources []deployedResource
+}
+
+// track records a created resource and the endpoint that deletes it
+func (t *resourceTracker) track(kind, endpoint string) {
+    t.resources = append(t.resources, deployedResource{kind: kind, endpoint: endpoint})
+}
+
+// rollback deletes tracked resources in reverse creation order. Every
+// resource is attempted even if an earlier deletion fails, so a single stuck
+// resource does not leave the rest behind.
+func (d *Deployer) rollback(integration *types.Integration, tracker *resourceTracker) error {
+    ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+    defer cancel()
+
+    var failed []string
+    for i := len(tracker.resources) - 1; i >= 0; i-- {
+        resource := tracker.resources[i]
+        if err := d.apiClient.Delete(ctx, resource.endpoint); err != nil {
+            d.logger.WithError(err).WithFields(logrus.Fields{
+                "integration_id": integration.ID,
+                "resource":       resource.kind,
+            }).Error("Failed to roll back deployment resource")
+            failed = append(failed, resource.kind)
+        }
+    }
+
+    if len(failed) > 0 {
+        rollbackTotal.WithLabelValues(StatusRollbackFailed).Inc()
+        return errors.Errorf("rollback failed for resources: %v", failed)
+    }
+
+    rollbackTotal.WithLabelValues(StatusRolledBack).Inc()
+    d.logger.WithFields(logrus.Fields{
+        "integration_id": integration.ID,
+        "resources":      len(tracker.resources),
+    }).Info("Rolled back failed deployment")
+    return nil
+}
@@ -2,9 +2,11 @@
 package integration
 
 import (
+    "context"
     "encoding/json"
     "fmt"
     "os"
+    "time"
 
     "../../pkg/integration/types"
     "../../pkg/integration/schema"
@@ -14,6 +16,22 @@ import (
 // ValidateIntegrationFile validates an integration configuration file against schema
 // and comprehensive business rules including security policies
 func ValidateIntegrationFile(filePath string) (*types.Integration, error) {
+    integration, err := parseIntegrationFile(filePath)
+    if err != nil {
+        return nil, err
+    }
+
+    // Perform comprehensive validation
+    if err := ValidateIntegrationConfig(integration); err != nil {
+        return nil, err
+    }
+
+    return integration, nil
+}
+
+// parseIntegrationFile reads an integration configuration file and checks it
+// against the schema without applying business rules
+func parseIntegrationFile(filePath string) (*types.Integration, error) {
     // Read and validate file existence and permissions
     fileData, err := os.ReadFile(filePath)
     if err != nil {
@@ -41,12 +59,199 @@ func ValidateIntegrationFile(filePath string) (*types.Integration, error) {
         return nil, errors.NewCLIError("E1004", "Failed to parse integration configuration", err)
     }
 
-    // Perform comprehensive validation
-    if err := ValidateIntegrationConfig(&integration); err != nil {
-        return nil, err
+    return &integration, nil
+}
+
+// Validation check names, in the order checks run
+const (
+    CheckStructure        = "structure"
+    CheckPlatformSpecific = "platform_specific"
+    CheckAuth             = "auth"
+    CheckCollection       = "collection"
+    CheckConnectivity     = "connectivity"
+)
+
+// Validation check results
+const (
+    CheckPassed  = "passed"
+    CheckFailed  = "failed"
+    CheckWarning = "warning"
+    CheckSkipped = "skipped"
+)
+
+// probeEndpoint checks platform connectivity server-side without deploying
+const probeEndpoint = "/api/v1/integrations/probe"
+
+// ProbeAPI is the API used to run connectivity probes
+type ProbeAPI interface {
+    Post(ctx context.Context, endpoint string, body, result interface{}) error
+}
+
+// ProbeResult is the outcome of a connectivity probe run by the platform API
+type ProbeResult struct {
+    PlatformType string        `json:"platform_type"`
+    Method       string        `json:"method"`
+    Endpoint     string        `json:"endpoint"`
+    AuthStatus   string        `json:"auth_status"`
+    StatusCode   int           `json:"status_code,omitempty"`
+    Latency      time.Duration `json:"latency"`
+    Message      string        `json:"message,omitempty"`
+}
+
+// minTokenExpiry is the shortest token expiry the platform accepts
+const minTokenExpiry = time.Hour
+
+// minHMACSecretLength matches the shortest HMAC secret the platform accepts
+const minHMACSecretLength = 32
+
+// ValidationCheck is the outcome of one group of validation checks
+type ValidationCheck struct {
+    Name    string `json:"name"`
+    Result  string `json:"result"`
+    Message string `json:"message,omitempty"`
+}
+
+// ValidationReport summarizes the validation of an integration configuration file
+type ValidationReport struct {
+    File         string            `json:"file"`
+    Name         string            `json:"name,omitempty"`
+    PlatformType string            `json:"platform_type,omitempty"`
+    Valid        bool              `json:"valid"`
+    Strict       bool              `json:"strict"`
+    Checks       []ValidationCheck `json:"checks"`
+}
+
+// Headers returns the column headers for validation report output
+func (r *ValidationReport) Headers() []string {
+    return []string{"Check", "Result", "Message"}
+}
+
+// Rows returns one row per validation check
+func (r *ValidationReport) Rows() [][]string {
+    rows := make([][]string, len(r.Checks))
+    for i, check := range r.Checks {
+        rows[i] = []string{check.Name, check.Result, check.Message}
     }
+    return rows
+}
 
-    return &integration, nil
+// BuildValidationReport validates an integration file and returns a renderable
+// report of every check. Validation failures are captured in the report rather
+// than returned as errors. Warnings fail validation only in strict mode.
+func BuildValidationReport(filePath string, strict bool) *ValidationReport {
+    report := &ValidationReport{File: filePath, Strict: strict}
+
+    integration, err := parseIntegrationFile(filePath)
+    if err == nil {
+        err = integration.Validate()
+    }
+    report.addCheck(CheckStructure, err)
+    if err != nil {
+        for _, name := range []string{CheckPlatformSpecific, CheckAuth, CheckCollection} {
+            report.Checks = append(report.Checks, ValidationCheck{
+                Name: name, Result: CheckSkipped, Message: "structure validation failed",
+            })
+        }
+        report.finalize()
+        return report
+    }
+
+    report.Name = integration.Name
+    report.PlatformType = integration.PlatformType
+
+    report.addCheck(CheckPlatformSpecific, validatePlatformRequirements(integration))
+    report.Checks = append(report.Checks, checkAuth(integration))
+    report.addCheck(CheckCollection, validateResourceLimits(integration))
+
+    report.finalize()
+    return report
+}
+
+// Probe asks the platform API to make one authenticated call to the target
+// platform and records the result as the connectivity check. Configurations
+// that already failed validation are not probed.
+func (r *ValidationReport) Probe(ctx context.Context, api ProbeAPI) {
+    check := ValidationCheck{Name: CheckConnectivity, Result: CheckSkipped, Message: "validation failed"}
+    if r.Valid {
+        check = probeConnectivity(ctx, api, r.File)
+    }
+    r.Checks = append(r.Checks, check)
+    r.finalize()
+}
+
+// probeConnectivity posts the integration file to the probe endpoint
+func probeConnectivity(ctx context.Context, api ProbeAPI, filePath string) ValidationCheck {
+    check := ValidationCheck{Name: CheckConnectivity, Result: CheckFailed}
+
+    integration, err := parseIntegrationFile(filePath)
+    if err != nil {
+        check.Message = err.Error()
+        return check
+    }
+
+    var result ProbeResult
+    if err := api.Post(ctx, probeEndpoint, integration, &result); err != nil {
+        check.Message = err.Error()
+        return check
+    }
+
+    latency := result.Latency.Round(time.Millisecond)
+    if result.AuthStatus != "authenticated" {
+        check.Message = fmt.Sprintf("%s after %s: %s", result.AuthStatus, latency, result.Message)
+        return check
+    }
+    check.Result = CheckPassed
+    check.Message = fmt.Sprintf("authenticated via %s in %s", result.Method, latency)
+    return check
+}
+
+// checkAuth validates credentials, security policies and token expiry
+func checkAuth(integration *types.Integration) ValidationCheck {
+    check := ValidationCheck{Name: CheckAuth, Result: CheckPassed}
+
+    err := validateSecurityPolicies(integration)
+    if err == nil {
+        err = validateCredentials(integration)
+    }
+    if err != nil {
+        check.Result = CheckFailed
+        check.Message = err.Error()
+        return check
+    }
+
+    if integration.Config.Auth.TokenExpiry == "" {
+        return check
+    }
+    expiry, err := time.ParseDuration(integration.Config.Auth.TokenExpiry)
+    switch {
+    case err != nil:
+        check.Result = CheckFailed
+        check.Message = fmt.Sprintf("invalid token expiry %q", integration.Config.Auth.TokenExpiry)
+    case expiry < minTokenExpiry:
+        check.Result = CheckFailed
+        check.Message = fmt.Sprintf("token expiry %s is shorter than %s", expiry, minTokenExpiry)
+    }
+    return check
+}
+
+// addCheck records a check as passed or failed
+func (r *ValidationReport) addCheck(name string, err error) {
+    check := ValidationCheck{Name: name, Result: CheckPassed}
+    if err != nil {
+        check.Result = CheckFailed
+        check.Message = err.Error()
+    }
+    r.Checks = append(r.Checks, check)
+}
+
+// finalize sets Valid from the recorded checks
+func (r *ValidationReport) finalize() {
+    r.Valid = true
+    for _, check := range r.Checks {
+        if check.Result == CheckFailed || check.Result == CheckSkipped || (r.Strict && check.Result == CheckWarning) {
+            r.Valid = false
+        }
+    }
 }
 
 // ValidateIntegrationConfig validates an in-memory integration configuration
@@ -108,11 +313,15 @@ func validateSecurityPolicies(config *types.Integration) error {
         if len(config.Config.Auth.ClientSecret) < constants.MinPasswordLength {
             return errors.NewCLIError("E1004", "Client secret does not meet minimum length requirement", nil)
         }
+    case "hmac":
+        if len(config.Config.Auth.Secret) < minHMACSecretLength {
+            return errors.NewCLIError("E1004", "HMAC secret does not meet minimum length requirement", nil)
+        }
     case "api_key":
         if len(config.Config.Auth.APIKey) < constants.APIKeyMinLength {
             return errors.NewCLIError("E1004", "API key does not meet minimum length requirement", nil)
         }
-    case "certificate":
+    case "certificate", "mtls":
         if err := validateCertificate(config.Config.Auth.CertificatePath); err != nil {
             return errors.WrapError(err, "Certificate validation failed")
         }
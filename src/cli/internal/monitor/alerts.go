@@ -4,6 +4,7 @@ package monitor
 import (
     "context"
     "fmt"
+    "os"
     "sort"
     "strings"
     "time"
@@ -11,7 +12,7 @@ import (
     "github.com/blackpoint/cli/pkg/api/client"
     "github.com/blackpoint/cli/pkg/common/errors"
     "github.com/blackpoint/cli/pkg/monitor/types"
-    "github.com/blackpoint/cli/internal/output/formatter"
+    "github.com/blackpoint/cli/internal/output"
 )
 
 // Default configuration values
@@ -162,15 +163,18 @@ func FilterAlerts(alerts []types.AlertInfo, filter *AlertFilter) []types.AlertIn
     return filtered[start:end]
 }
 
-// DisplayAlerts formats and displays alerts in a color-coded table
-func DisplayAlerts(alerts []types.AlertInfo) error {
-    if len(alerts) == 0 {
-        return nil
-    }
+// AlertTable adapts alerts for tabular rendering
+type AlertTable []types.AlertInfo
+
+// Headers returns the alert table column headers
+func (t AlertTable) Headers() []string {
+    return alertTableHeaders
+}
 
-    // Convert alerts to table rows
-    rows := make([][]string, len(alerts))
-    for i, alert := range alerts {
+// Rows returns one row per alert in header order
+func (t AlertTable) Rows() [][]string {
+    rows := make([][]string, len(t))
+    for i, alert := range t {
         rows[i] = []string{
             alert.ID,
             alert.Severity,
@@ -179,9 +183,18 @@ func DisplayAlerts(alerts []types.AlertInfo) error {
             alert.Timestamp.Local().Format("2006-01-02 15:04:05"),
         }
     }
+    return rows
+}
+
+// DisplayAlerts renders alerts in the requested output format, using a
+// color-coded table for table output
+func DisplayAlerts(alerts []types.AlertInfo, format string) error {
+    if alerts == nil {
+        alerts = []types.AlertInfo{}
+    }
 
     // Configure table formatting options
-    options := formatter.TableOptions{
+    options := output.TableOptions{
         Border:       true,
         CenterAlign: false,
         ColorEnabled: true,
@@ -190,12 +203,8 @@ func DisplayAlerts(alerts []types.AlertInfo) error {
         WrapText:     true,
     }
 
-    // Format and display the table
-    output, err := formatter.FormatTable(alertTableHeaders, rows, options)
-    if err != nil {
-        return errors.WrapError(err, "failed to format alerts table")
+    if err := output.RenderWithOptions(os.Stdout, format, AlertTable(alerts), options); err != nil {
+        return errors.WrapError(err, "failed to render alerts")
     }
-
-    fmt.Println(output)
     return nil
 }
\ No newline at end of file
@@ -0,0 +1,126 @@
+// Package output provides format-aware rendering of command results for the BlackPoint CLI
+package output
+
+import (
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "io"
+
+    "github.com/blackpoint/cli/pkg/common/constants"
+)
+
+// Tabular is implemented by command results that can be rendered as rows
+type Tabular interface {
+    // Headers returns the column headers for the result
+    Headers() []string
+    // Rows returns the result rows in header order
+    Rows() [][]string
+}
+
+// DefaultRenderTableOptions provides the table styling used by list and report commands
+var DefaultRenderTableOptions = TableOptions{
+    Border:         true,
+    CenterAlign:    false,
+    MinColumnWidth: 10,
+    WrapText:       true,
+}
+
+// Render writes data to w in the requested output format using default table styling
+func Render(w io.Writer, format string, data interface{}) error {
+    return RenderWithOptions(w, format, data, DefaultRenderTableOptions)
+}
+
+// RenderWithOptions writes data to w in the requested output format. JSON and
+// YAML output serialize data as-is using its JSON field names; CSV and table
+// output require data to implement Tabular or to be a [][]string whose first
+// row holds the headers.
+func RenderWithOptions(w io.Writer, format string, data interface{}, tableOptions TableOptions) error {
+    if data == nil {
+        return fmt.Errorf("nil data provided")
+    }
+
+    switch format {
+    case constants.OutputFormatJSON:
+        formatted, err := FormatJSON(data, nil)
+        if err != nil {
+            return fmt.Errorf("formatting error: %w", err)
+        }
+        _, err = fmt.Fprintln(w, formatted)
+        return err
+
+    case constants.OutputFormatYAML:
+        generic, err := toGeneric(data)
+        if err != nil {
+            return fmt.Errorf("formatting error: %w", err)
+        }
+        formatted, err := FormatYAML(generic)
+        if err != nil {
+            return fmt.Errorf("formatting error: %w", err)
+        }
+        _, err = fmt.Fprint(w, formatted)
+        return err
+
+    case constants.OutputFormatCSV:
+        headers, rows, err := tabularData(data)
+        if err != nil {
+            return err
+        }
+        writer := csv.NewWriter(w)
+        if err := writer.Write(headers); err != nil {
+            return fmt.Errorf("write error: %w", err)
+        }
+        if err := writer.WriteAll(rows); err != nil {
+            return fmt.Errorf("write error: %w", err)
+        }
+        return nil
+
+    case constants.OutputFormatTable:
+        headers, rows, err := tabularData(data)
+        if err != nil {
+            return err
+        }
+        if len(rows) == 0 {
+            _, err = fmt.Fprintln(w, "No results found")
+            return err
+        }
+        formatted, err := FormatTable(headers, rows, tableOptions)
+        if err != nil {
+            return fmt.Errorf("formatting error: %w", err)
+        }
+        _, err = fmt.Fprintln(w, formatted)
+        return err
+
+    default:
+        return fmt.Errorf("unsupported format: %s", format)
+    }
+}
+
+// tabularData extracts headers and rows from a renderable value
+func tabularData(data interface{}) ([]string, [][]string, error) {
+    switch t := data.(type) {
+    case Tabular:
+        return t.Headers(), t.Rows(), nil
+    case [][]string:
+        if len(t) == 0 {
+            return nil, nil, fmt.Errorf("table data requires a header row")
+        }
+        return t[0], t[1:], nil
+    default:
+        return nil, nil, fmt.Errorf("invalid data type for tabular format: %T", data)
+    }
+}
+
+// toGeneric converts data to maps and slices through its JSON encoding, so
+// YAML output uses the same field names and omissions as JSON output
+func toGeneric(data interface{}) (interface{}, error) {
+    raw, err := json.Marshal(data)
+    if err != nil {
+        return nil, err
+    }
+    var generic interface{}
+    if err := json.Unmarshal(raw, &generic); err != nil {
+        return nil, err
+    }
+    return generic, nil
+}
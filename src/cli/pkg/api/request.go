@@ -45,6 +45,30 @@ type RequestConfig struct {
 	APIKey           string
 }
 
+// Deployment strategies supported by DeploymentOptions.Strategy
+const (
+	// DeploymentStrategyReplace replaces the running integration in place
+	DeploymentStrategyReplace = "replace"
+	// DeploymentStrategyBlueGreen deploys the new version alongside the
+	// running one and cuts over once it is healthy
+	DeploymentStrategyBlueGreen = "blue-green"
+)
+
+// DeploymentOptions configures an integration deployment
+type DeploymentOptions struct {
+	// ConfigPath is the integration configuration file being deployed. When
+	// set, the file is validated again before deployment.
+	ConfigPath string
+
+	// DisableRollback leaves resources from a failed deployment in place
+	// for debugging instead of tearing them down
+	DisableRollback bool
+
+	// Strategy selects how a running integration is updated. Empty means
+	// DeploymentStrategyReplace.
+	Strategy string
+}
+
 // defaultHeaders contains standard headers added to all requests
 var defaultHeaders = map[string]string{
 	"Accept":           "application/json",
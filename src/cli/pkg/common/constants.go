@@ -16,9 +16,32 @@ const (
 	DefaultLogLevel = "info"
 
 	// DefaultOutputFormat specifies the default output format for CLI commands
-	DefaultOutputFormat = "json"
+	DefaultOutputFormat = OutputFormatTable
 )
 
+// Output format constants
+const (
+	// OutputFormatJSON renders command output as indented JSON
+	OutputFormatJSON = "json"
+
+	// OutputFormatCSV renders tabular command output as comma-separated values
+	OutputFormatCSV = "csv"
+
+	// OutputFormatTable renders tabular command output as a bordered table
+	OutputFormatTable = "table"
+
+	// OutputFormatYAML renders command output as YAML
+	OutputFormatYAML = "yaml"
+)
+
+// ValidOutputFormats lists the formats accepted by the global --output flag
+var ValidOutputFormats = []string{
+	OutputFormatTable,
+	OutputFormatJSON,
+	OutputFormatYAML,
+	OutputFormatCSV,
+}
+
 // API and request constants
 const (
 	// DefaultAPIVersion specifies the API version for backend service communication
@@ -103,7 +103,8 @@ func newLogger(level, format string, config *LogConfig) (*Logger, error) {
 		return nil, NewCLIError("E2002", "Invalid log level", nil)
 	}
 
-	var output io.Writer = os.Stdout
+	// Logs go to stderr so stdout carries only command results
+	var output io.Writer = os.Stderr
 	if config.FilePath != "" {
 		file, err := setupLogFile(config.FilePath)
 		if err != nil {
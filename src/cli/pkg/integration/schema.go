@@ -59,7 +59,7 @@ var (
                 "properties": {
                     "type": {
                         "type": "string",
-                        "enum": []string{"oauth2", "api_key", "basic", "certificate"},
+                        "enum": []string{"oauth2", "api_key", "basic", "certificate", "hmac", "mtls"},
                         "description": "Authentication method",
                     },
                     "client_id": {
@@ -72,6 +72,16 @@ var (
                         "minLength": 1,
                         "description": "OAuth2/Basic auth client secret",
                     },
+                    "key_id": {
+                        "type": "string",
+                        "minLength": 1,
+                        "description": "HMAC signing key ID",
+                    },
+                    "secret": {
+                        "type": "string",
+                        "minLength": 32,
+                        "description": "HMAC signing secret",
+                    },
                     "api_key": {
                         "type": "string",
                         "minLength": 32,
@@ -82,6 +92,21 @@ var (
                         "pattern": "^[\\w\\-\\/\\.]+$",
                         "description": "Path to authentication certificate",
                     },
+                    "key_path": {
+                        "type": "string",
+                        "pattern": "^[\\w\\-\\/\\.]+$",
+                        "description": "Path to mTLS client private key",
+                    },
+                    "ca_path": {
+                        "type": "string",
+                        "pattern": "^[\\w\\-\\/\\.]+$",
+                        "description": "Path to CA bundle used to verify the platform",
+                    },
+                    "token_expiry": {
+                        "type": "string",
+                        "pattern": "^([0-9]+(\\.[0-9]+)?(ns|us|ms|s|m|h))+$",
+                        "description": "Lifetime of issued platform tokens, e.g. 1h or 30m",
+                    },
                 },
                 "allOf": [
                     {
@@ -100,6 +125,14 @@ var (
                         "if": {"properties": {"type": {"const": "basic"}}},
                         "then": {"required": ["client_id", "client_secret"]},
                     },
+                    {
+                        "if": {"properties": {"type": {"const": "hmac"}}},
+                        "then": {"required": ["key_id", "secret"]},
+                    },
+                    {
+                        "if": {"properties": {"type": {"const": "mtls"}}},
+                        "then": {"required": ["certificate_path", "key_path"]},
+                    },
                 ],
             },
             "CollectionConfig": {
@@ -14,7 +14,7 @@ var (
 	validEnvironments = []string{"production", "staging", "development"}
 
 	// validAuthTypes defines supported authentication methods
-	validAuthTypes = []string{"oauth2", "api_key", "basic", "certificate"}
+	validAuthTypes = []string{"oauth2", "api_key", "basic", "certificate", "hmac", "mtls"}
 
 	// validCollectionModes defines supported data collection modes
 	validCollectionModes = []string{"realtime", "batch", "hybrid"}
@@ -101,8 +101,15 @@ type AuthConfig struct {
 	Type           string `json:"type" validate:"required"`
 	ClientID       string `json:"client_id,omitempty"`
 	ClientSecret   string `json:"client_secret,omitempty"`
+	// KeyID and Secret are the HMAC signing key, named as the backend expects
+	KeyID          string `json:"key_id,omitempty"`
+	Secret         string `json:"secret,omitempty"`
 	APIKey         string `json:"api_key,omitempty"`
 	CertificatePath string `json:"certificate_path,omitempty"`
+	KeyPath        string `json:"key_path,omitempty"`
+	CAPath         string `json:"ca_path,omitempty"`
+	// TokenExpiry is the lifetime of issued platform tokens as a Go duration
+	TokenExpiry    string `json:"token_expiry,omitempty"`
 }
 
 // Validate performs security-focused validation of authentication configuration
@@ -135,6 +142,14 @@ func (a *AuthConfig) Validate() error {
 		if a.ClientID == "" || a.ClientSecret == "" {
 			return errors.NewCLIError("E1004", "Basic auth requires username and password", nil)
 		}
+	case "hmac":
+		if a.KeyID == "" || a.Secret == "" {
+			return errors.NewCLIError("E1004", "HMAC requires key ID and secret", nil)
+		}
+	case "mtls":
+		if a.CertificatePath == "" || a.KeyPath == "" {
+			return errors.NewCLIError("E1004", "mTLS requires certificate and key paths", nil)
+		}
 	}
 
 	return nil
@@ -175,4 +190,44 @@ func (c *CollectionConfig) Validate() error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+// DeploymentStatus tracks the progress of an integration deployment
+type DeploymentStatus struct {
+	ID             string    `json:"id"`
+	PlatformType   string    `json:"platform_type"`
+	Environment    string    `json:"environment"`
+	Status         string    `json:"status"`
+	Error          string    `json:"error,omitempty"`
+	StartTime      time.Time `json:"start_time"`
+	CompletionTime time.Time `json:"completion_time,omitempty"`
+	Warnings       []string  `json:"warnings,omitempty"`
+}
+
+// DeploymentStatusList is a renderable list of deployment statuses
+type DeploymentStatusList []DeploymentStatus
+
+// Headers returns the column headers for deployment status output
+func (l DeploymentStatusList) Headers() []string {
+	return []string{"ID", "Platform", "Environment", "Status", "Started", "Completed", "Error"}
+}
+
+// Rows returns one row per deployment in header order
+func (l DeploymentStatusList) Rows() [][]string {
+	rows := make([][]string, len(l))
+	for i, status := range l {
+		completed := ""
+		if !status.CompletionTime.IsZero() {
+			completed = status.CompletionTime.Local().Format("2006-01-02 15:04:05")
+		}
+		rows[i] = []string{
+			status.ID,
+			status.PlatformType,
+			status.Environment,
+			status.Status,
+			status.StartTime.Local().Format("2006-01-02 15:04:05"),
+			completed,
+			status.Error,
+		}
+	}
+	return rows
+}
@@ -23,7 +23,7 @@ var (
     allowedEnvironments = []string{"production", "staging", "development", "dr"}
 
     // allowedAuthTypes defines supported authentication methods
-    allowedAuthTypes = []string{"oauth2", "api_key", "basic", "certificate", "jwt", "saml"}
+    allowedAuthTypes = []string{"oauth2", "api_key", "basic", "certificate", "jwt", "saml", "hmac", "mtls"}
 
     // allowedCollectionModes defines valid data collection modes
     allowedCollectionModes = []string{"realtime", "batch", "hybrid"}
@@ -2,11 +2,19 @@
 package integration_test
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
+	"../../internal/integration"
+	"../../pkg/api"
 	"../../pkg/integration/types"
 	"../../pkg/integration/validation"
 	"../../pkg/integration/schema"
@@ -208,6 +216,40 @@ func TestAuthConfigValidation(t *testing.T) {
 	}
 }
 
+// TestHMACAuthConfigFields tests that HMAC credentials are sent under the
+// key_id and secret fields the platform API reads
+func TestHMACAuthConfigFields(t *testing.T) {
+	auth := &types.AuthConfig{
+		Type:   "hmac",
+		KeyID:  "key-1",
+		Secret: strings.Repeat("k", 32),
+	}
+	if err := auth.Validate(); err != nil {
+		t.Fatalf("Expected HMAC config to be valid: %v", err)
+	}
+
+	data, err := json.Marshal(auth)
+	if err != nil {
+		t.Fatalf("Failed to marshal auth config: %v", err)
+	}
+	var sent map[string]interface{}
+	if err := json.Unmarshal(data, &sent); err != nil {
+		t.Fatalf("Failed to unmarshal auth config: %v", err)
+	}
+	if sent["key_id"] != "key-1" || sent["secret"] != auth.Secret {
+		t.Errorf("Expected key_id and secret fields, got %v", sent)
+	}
+	if _, ok := sent["client_secret"]; ok {
+		t.Errorf("Expected no client_secret field, got %v", sent)
+	}
+
+	// OAuth2 field names do not configure an HMAC key
+	oauthNamed := &types.AuthConfig{Type: "hmac", ClientID: "key-1", ClientSecret: auth.Secret}
+	if err := oauthNamed.Validate(); err == nil {
+		t.Error("Expected HMAC config without key_id and secret to be invalid")
+	}
+}
+
 // TestCollectionConfigValidation tests data collection configuration validation
 func TestCollectionConfigValidation(t *testing.T) {
 	// Test valid collection configurations
@@ -341,4 +383,569 @@ func TestSchemaValidation(t *testing.T) {
 			t.Errorf("Expected invalid schema to fail validation: %+v", invalid)
 		}
 	}
-}
\ No newline at end of file
+}
+
+// TestBuildValidationReportUnreadableFile tests that a structure failure skips the remaining checks
+func TestBuildValidationReportUnreadableFile(t *testing.T) {
+	report := integration.BuildValidationReport("testdata/does-not-exist.json", false)
+
+	if report.Valid {
+		t.Fatal("Expected report for missing file to be invalid")
+	}
+	if len(report.Checks) != 4 {
+		t.Fatalf("Expected 4 checks, got %d", len(report.Checks))
+	}
+	if report.Checks[0].Name != integration.CheckStructure || report.Checks[0].Result != integration.CheckFailed {
+		t.Errorf("Expected failed structure check, got %+v", report.Checks[0])
+	}
+	for _, check := range report.Checks[1:] {
+		if check.Result != integration.CheckSkipped {
+			t.Errorf("Expected %s check to be skipped, got %s", check.Name, check.Result)
+		}
+	}
+
+	// The report serializes for CI consumers
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Failed to marshal report: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal report: %v", err)
+	}
+	if decoded["valid"] != false {
+		t.Errorf("Expected valid=false in JSON report, got %v", decoded["valid"])
+	}
+}
+
+// writeIntegrationFile writes v as JSON to a temporary integration file
+func writeIntegrationFile(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to marshal integration: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "integration.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write integration file: %v", err)
+	}
+	return path
+}
+
+// TestBuildValidationReport tests the checks reported for valid,
+// schema-invalid and credential-invalid configuration files
+func TestBuildValidationReport(t *testing.T) {
+	// A platform type of the wrong JSON type fails the schema
+	var schemaInvalid map[string]interface{}
+	data, err := json.Marshal(testValidIntegration)
+	if err != nil {
+		t.Fatalf("Failed to marshal integration: %v", err)
+	}
+	if err := json.Unmarshal(data, &schemaInvalid); err != nil {
+		t.Fatalf("Failed to unmarshal integration: %v", err)
+	}
+	schemaInvalid["platform_type"] = 42
+
+	// A client secret below the minimum length passes the schema but fails the auth check
+	auth := *testValidIntegration.Config.Auth
+	auth.ClientSecret = "short"
+	cfg := *testValidIntegration.Config
+	cfg.Auth = &auth
+	weakCredentials := *testValidIntegration
+	weakCredentials.Config = &cfg
+
+	tests := []struct {
+		name        string
+		config      interface{}
+		valid       bool
+		results     map[string]string
+		failedCheck string
+		message     string
+	}{
+		{
+			name:   "valid",
+			config: testValidIntegration,
+			valid:  true,
+			results: map[string]string{
+				integration.CheckStructure:        integration.CheckPassed,
+				integration.CheckPlatformSpecific: integration.CheckPassed,
+				integration.CheckAuth:             integration.CheckPassed,
+				integration.CheckCollection:       integration.CheckPassed,
+			},
+		},
+		{
+			name:   "schema invalid",
+			config: schemaInvalid,
+			results: map[string]string{
+				integration.CheckStructure:        integration.CheckFailed,
+				integration.CheckPlatformSpecific: integration.CheckSkipped,
+				integration.CheckAuth:             integration.CheckSkipped,
+				integration.CheckCollection:       integration.CheckSkipped,
+			},
+			failedCheck: integration.CheckStructure,
+			message:     "Schema validation failed",
+		},
+		{
+			name:   "credentials invalid",
+			config: &weakCredentials,
+			results: map[string]string{
+				integration.CheckStructure:        integration.CheckPassed,
+				integration.CheckPlatformSpecific: integration.CheckPassed,
+				integration.CheckAuth:             integration.CheckFailed,
+				integration.CheckCollection:       integration.CheckPassed,
+			},
+			failedCheck: integration.CheckAuth,
+			message:     "Client secret does not meet minimum length requirement",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := integration.BuildValidationReport(writeIntegrationFile(t, tt.config), false)
+
+			if report.Valid != tt.valid {
+				t.Errorf("Expected valid=%v, got %+v", tt.valid, report)
+			}
+			if len(report.Checks) != len(tt.results) {
+				t.Fatalf("Expected %d checks, got %d", len(tt.results), len(report.Checks))
+			}
+			for _, check := range report.Checks {
+				if check.Result != tt.results[check.Name] {
+					t.Errorf("Expected %s check %s, got %+v", check.Name, tt.results[check.Name], check)
+				}
+				if check.Name == tt.failedCheck && !strings.Contains(check.Message, tt.message) {
+					t.Errorf("Expected %s message containing %q, got %q", check.Name, tt.message, check.Message)
+				}
+			}
+		})
+	}
+}
+
+// fakeDeploymentAPI records deployment calls, fails posts to one endpoint,
+// reports healthStatus from the deployment health check and active as the
+// serving blue-green version, and answers creates with createdID
+type fakeDeploymentAPI struct {
+	failSuffix   string
+	healthStatus string
+	active       string
+	createdID    string
+	warnings     []map[string]string
+	posts        []string
+	deletes      []string
+}
+
+func (f *fakeDeploymentAPI) Get(ctx context.Context, endpoint string, result interface{}) error {
+	response := map[string]string{"status": f.healthStatus}
+	if strings.HasSuffix(endpoint, "/versions") {
+		response = map[string]string{"active": f.active}
+	}
+	if result == nil {
+		return nil
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+func (f *fakeDeploymentAPI) Post(ctx context.Context, endpoint string, body, result interface{}) error {
+	f.posts = append(f.posts, endpoint)
+	if f.failSuffix != "" && strings.HasSuffix(endpoint, f.failSuffix) {
+		return errors.NewCLIError("E2001", "permission denied", nil)
+	}
+	if result == nil || f.createdID == "" {
+		return nil
+	}
+	data, err := json.Marshal(map[string]interface{}{"integration_id": f.createdID, "warnings": f.warnings})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+func (f *fakeDeploymentAPI) Delete(ctx context.Context, endpoint string) error {
+	f.deletes = append(f.deletes, endpoint)
+	return nil
+}
+
+// newTestDeployIntegration returns a valid realtime integration for deployment tests
+func newTestDeployIntegration() *types.Integration {
+	return &types.Integration{
+		ID:           "550e8400-e29b-41d4-a716-446655440010",
+		Name:         "rollback-test",
+		PlatformType: "aws",
+		Config: &types.IntegrationConfig{
+			Environment: "staging",
+			Auth:        testAuthConfigs["oauth2"],
+			Collection:  testCollectionConfigs["realtime"],
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now().Add(time.Hour),
+	}
+}
+
+// TestDeployCreatesIntegration tests that a deployment creates the
+// integration through the integrations API and checks the created one's health
+func TestDeployCreatesIntegration(t *testing.T) {
+	fake := &fakeDeploymentAPI{healthStatus: "healthy", createdID: "okta-rollback-test-1"}
+	deployer, err := integration.NewDeployer(fake, time.Minute, nil)
+	if err != nil {
+		t.Fatalf("Failed to create deployer: %v", err)
+	}
+
+	if err := deployer.Deploy(context.Background(), newTestDeployIntegration(), &api.DeploymentOptions{}); err != nil {
+		t.Fatalf("Expected deployment to succeed, got %v", err)
+	}
+	if len(fake.posts) != 1 || fake.posts[0] != "/api/v1/integrations" {
+		t.Errorf("Expected a single create request, got %v", fake.posts)
+	}
+	if len(fake.deletes) != 0 {
+		t.Errorf("Expected no deletes, got %v", fake.deletes)
+	}
+}
+
+// TestDeployRecordsValidationWarnings tests that warnings returned by the
+// integrations API are kept on the deployment result without failing it
+func TestDeployRecordsValidationWarnings(t *testing.T) {
+	fake := &fakeDeploymentAPI{
+		healthStatus: "healthy",
+		createdID:    "okta-rollback-test-1",
+		warnings: []map[string]string{
+			{"field": "collection.batch_size", "severity": "warning", "message": "batch size 50 is outside the recommended range 100-5000"},
+		},
+	}
+	deployer, err := integration.NewDeployer(fake, time.Minute, nil)
+	if err != nil {
+		t.Fatalf("Failed to create deployer: %v", err)
+	}
+
+	deployment := newTestDeployIntegration()
+	if err := deployer.Deploy(context.Background(), deployment, &api.DeploymentOptions{}); err != nil {
+		t.Fatalf("Expected deployment with warnings to succeed, got %v", err)
+	}
+
+	status, ok := deployer.DeploymentResult(deployment.ID)
+	if !ok {
+		t.Fatal("Expected a recorded deployment result")
+	}
+	want := "collection.batch_size: batch size 50 is outside the recommended range 100-5000"
+	if len(status.Warnings) != 1 || status.Warnings[0] != want {
+		t.Errorf("Expected warning %q, got %v", want, status.Warnings)
+	}
+}
+
+// TestDeployRollbackOnFailure tests that an integration failing its health check is torn down
+func TestDeployRollbackOnFailure(t *testing.T) {
+	fake := &fakeDeploymentAPI{healthStatus: "unhealthy", createdID: "okta-rollback-test-1"}
+	deployer, err := integration.NewDeployer(fake, time.Minute, nil)
+	if err != nil {
+		t.Fatalf("Failed to create deployer: %v", err)
+	}
+	target := newTestDeployIntegration()
+
+	if err := deployer.Deploy(context.Background(), target, &api.DeploymentOptions{}); err == nil {
+		t.Fatal("Expected deployment to fail")
+	}
+
+	// The integration is torn down under the ID the API assigned
+	if len(fake.deletes) != 1 || fake.deletes[0] != "/api/v1/integrations/okta-rollback-test-1" {
+		t.Fatalf("Expected the created integration to be deleted, got %v", fake.deletes)
+	}
+
+	status, ok := deployer.DeploymentResult(target.ID)
+	if !ok || status.Status != integration.StatusRolledBack {
+		t.Errorf("Expected status %s, got %+v", integration.StatusRolledBack, status)
+	}
+}
+
+// TestDeployDisableRollback tests that DisableRollback leaves created resources in place
+func TestDeployDisableRollback(t *testing.T) {
+	fake := &fakeDeploymentAPI{healthStatus: "unhealthy"}
+	deployer, err := integration.NewDeployer(fake, time.Minute, nil)
+	if err != nil {
+		t.Fatalf("Failed to create deployer: %v", err)
+	}
+	target := newTestDeployIntegration()
+
+	if err := deployer.Deploy(context.Background(), target, &api.DeploymentOptions{DisableRollback: true}); err == nil {
+		t.Fatal("Expected deployment to fail")
+	}
+	if len(fake.deletes) != 0 {
+		t.Errorf("Expected no deletes with rollback disabled, got %v", fake.deletes)
+	}
+
+	status, _ := deployer.DeploymentResult(target.ID)
+	if status.Status != "failed" {
+		t.Errorf("Expected status failed, got %s", status.Status)
+	}
+}
+
+// TestValidateDeploymentWithoutConfigFile tests that an in-memory
+// integration gets the security checks of a configuration file
+func TestValidateDeploymentWithoutConfigFile(t *testing.T) {
+	deployer, err := integration.NewDeployer(&fakeDeploymentAPI{}, time.Minute, nil)
+	if err != nil {
+		t.Fatalf("Failed to create deployer: %v", err)
+	}
+
+	if err := deployer.ValidateDeployment(newTestDeployIntegration(), &api.DeploymentOptions{}); err != nil {
+		t.Errorf("Expected valid integration to pass, got %v", err)
+	}
+
+	insecure := newTestDeployIntegration()
+	insecure.Config.Auth = &types.AuthConfig{Type: "oauth2", ClientID: "test-oauth2-client"}
+	if err := deployer.ValidateDeployment(insecure, &api.DeploymentOptions{}); err == nil {
+		t.Error("Expected an OAuth2 integration without a client secret to be rejected")
+	}
+}
+
+// deploymentErrorCount returns the deployment error counter for an error type
+func deploymentErrorCount(t *testing.T, errorType string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "blackpoint_integration_deployment_errors_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "error_type" && label.GetValue() == errorType {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// TestBlueGreenFailedHealthCheckKeepsBlue tests that an unhealthy green version is torn down without touching blue
+func TestBlueGreenFailedHealthCheckKeepsBlue(t *testing.T) {
+	fake := &fakeDeploymentAPI{healthStatus: "unhealthy"}
+	deployer, err := integration.NewDeployer(fake, time.Minute, nil)
+	if err != nil {
+		t.Fatalf("Failed to create deployer: %v", err)
+	}
+	target := newTestDeployIntegration()
+	before := deploymentErrorCount(t, "health_check_failed")
+
+	err = deployer.Deploy(context.Background(), target, &api.DeploymentOptions{Strategy: api.DeploymentStrategyBlueGreen})
+	if err == nil {
+		t.Fatal("Expected deployment to fail")
+	}
+
+	base := "/api/v1/integrations/" + target.ID
+	for _, endpoint := range fake.posts {
+		if endpoint == base+"/cutover" {
+			t.Error("Expected no cutover after a failed health check")
+		}
+	}
+
+	if len(fake.deletes) != 1 || fake.deletes[0] != base+"/versions/green" {
+		t.Errorf("Expected only the green version to be torn down, got %v", fake.deletes)
+	}
+
+	if after := deploymentErrorCount(t, "health_check_failed"); after != before+1 {
+		t.Errorf("Expected health check error counter to increase by 1, got %v -> %v", before, after)
+	}
+}
+
+// TestBlueGreenCutover tests that a healthy green version takes traffic and blue is decommissioned
+func TestBlueGreenCutover(t *testing.T) {
+	fake := &fakeDeploymentAPI{healthStatus: "healthy"}
+	deployer, err := integration.NewDeployer(fake, time.Minute, nil)
+	if err != nil {
+		t.Fatalf("Failed to create deployer: %v", err)
+	}
+	target := newTestDeployIntegration()
+
+	if err := deployer.Deploy(context.Background(), target, &api.DeploymentOptions{Strategy: api.DeploymentStrategyBlueGreen}); err != nil {
+		t.Fatalf("Expected deployment to succeed, got %v", err)
+	}
+
+	base := "/api/v1/integrations/" + target.ID
+	if last := fake.posts[len(fake.posts)-1]; last != base+"/cutover" {
+		t.Errorf("Expected cutover as the last post, got %s", last)
+	}
+	if fake.posts[0] != base+"/versions/green" {
+		t.Errorf("Expected the green version to be staged, got %s", fake.posts[0])
+	}
+	if len(fake.deletes) != 1 || fake.deletes[0] != base+"/versions/blue" {
+		t.Errorf("Expected only the blue version to be decommissioned, got %v", fake.deletes)
+	}
+}
+
+// TestBlueGreenFailedCutoverRollsBackCandidate tests that a rejected cutover
+// tears down the staged version and leaves the active one in place
+func TestBlueGreenFailedCutoverRollsBackCandidate(t *testing.T) {
+	fake := &fakeDeploymentAPI{healthStatus: "healthy", failSuffix: "/cutover"}
+	deployer, err := integration.NewDeployer(fake, time.Minute, nil)
+	if err != nil {
+		t.Fatalf("Failed to create deployer: %v", err)
+	}
+	target := newTestDeployIntegration()
+
+	if err := deployer.Deploy(context.Background(), target, &api.DeploymentOptions{Strategy: api.DeploymentStrategyBlueGreen}); err == nil {
+		t.Fatal("Expected deployment to fail")
+	}
+
+	base := "/api/v1/integrations/" + target.ID
+	if len(fake.deletes) != 1 || fake.deletes[0] != base+"/versions/green" {
+		t.Errorf("Expected only the staged green version to be torn down, got %v", fake.deletes)
+	}
+}
+
+// TestBlueGreenAlternatesVersions tests that a deployment over an active
+// green version stages blue and decommissions green
+func TestBlueGreenAlternatesVersions(t *testing.T) {
+	fake := &fakeDeploymentAPI{healthStatus: "healthy", active: "green"}
+	deployer, err := integration.NewDeployer(fake, time.Minute, nil)
+	if err != nil {
+		t.Fatalf("Failed to create deployer: %v", err)
+	}
+	target := newTestDeployIntegration()
+
+	if err := deployer.Deploy(context.Background(), target, &api.DeploymentOptions{Strategy: api.DeploymentStrategyBlueGreen}); err != nil {
+		t.Fatalf("Expected deployment to succeed, got %v", err)
+	}
+
+	base := "/api/v1/integrations/" + target.ID
+	wantPosts := []string{base + "/versions/blue", base + "/cutover"}
+	if len(fake.posts) != len(wantPosts) {
+		t.Fatalf("Expected posts %v, got %v", wantPosts, fake.posts)
+	}
+	for i, endpoint := range wantPosts {
+		if fake.posts[i] != endpoint {
+			t.Errorf("Expected post %d to be %s, got %s", i, endpoint, fake.posts[i])
+		}
+	}
+	if len(fake.deletes) != 1 || fake.deletes[0] != base+"/versions/green" {
+		t.Errorf("Expected only the green version to be decommissioned, got %v", fake.deletes)
+	}
+}
+
+// fakeProbeAPI answers connectivity probes with a fixed result
+type fakeProbeAPI struct {
+	result integration.ProbeResult
+	err    error
+	posts  []string
+}
+
+func (f *fakeProbeAPI) Post(ctx context.Context, endpoint string, body, result interface{}) error {
+	f.posts = append(f.posts, endpoint)
+	if f.err != nil {
+		return f.err
+	}
+	data, err := json.Marshal(f.result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+// TestBuildValidationReportShortTokenExpiry tests that a token expiry under an
+// hour fails the auth check rather than warning, even without --strict
+func TestBuildValidationReportShortTokenExpiry(t *testing.T) {
+	auth := *testValidIntegration.Config.Auth
+	auth.TokenExpiry = "30m"
+	cfg := *testValidIntegration.Config
+	cfg.Auth = &auth
+	shortLived := *testValidIntegration
+	shortLived.Config = &cfg
+
+	data, err := json.Marshal(&shortLived)
+	if err != nil {
+		t.Fatalf("Failed to marshal integration: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "integration.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write integration file: %v", err)
+	}
+
+	report := integration.BuildValidationReport(path, false)
+	if report.Valid {
+		t.Fatal("Expected report with a short token expiry to be invalid")
+	}
+	for _, check := range report.Checks {
+		if check.Name != integration.CheckAuth {
+			continue
+		}
+		if check.Result != integration.CheckFailed || !strings.Contains(check.Message, "shorter than 1h0m0s") {
+			t.Errorf("Expected failed auth check for short expiry, got %+v", check)
+		}
+		return
+	}
+	t.Fatal("Expected an auth check in the report")
+}
+
+// TestValidationReportProbe tests that probe results are recorded as the connectivity check
+func TestValidationReportProbe(t *testing.T) {
+	data, err := json.Marshal(testValidIntegration)
+	if err != nil {
+		t.Fatalf("Failed to marshal integration: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "integration.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write integration file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		api     *fakeProbeAPI
+		result  string
+		message string
+	}{
+		{
+			name:    "authenticated",
+			api:     &fakeProbeAPI{result: integration.ProbeResult{Method: "token", AuthStatus: "authenticated", Latency: 230 * time.Millisecond}},
+			result:  integration.CheckPassed,
+			message: "authenticated via token in 230ms",
+		},
+		{
+			name:    "rejected",
+			api:     &fakeProbeAPI{result: integration.ProbeResult{Method: "list", AuthStatus: "rejected", StatusCode: 401, Message: "platform rejected the credentials"}},
+			result:  integration.CheckFailed,
+			message: "rejected",
+		},
+		{
+			name:    "api error",
+			api:     &fakeProbeAPI{err: errors.NewCLIError("E1002", "API unavailable", nil)},
+			result:  integration.CheckFailed,
+			message: "API unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &integration.ValidationReport{File: path, Valid: true}
+			report.Probe(context.Background(), tt.api)
+
+			if len(tt.api.posts) != 1 || tt.api.posts[0] != "/api/v1/integrations/probe" {
+				t.Fatalf("Expected one probe request, got %v", tt.api.posts)
+			}
+			check := report.Checks[len(report.Checks)-1]
+			if check.Name != integration.CheckConnectivity || check.Result != tt.result {
+				t.Errorf("Expected %s connectivity check, got %+v", tt.result, check)
+			}
+			if !strings.Contains(check.Message, tt.message) {
+				t.Errorf("Expected message containing %q, got %q", tt.message, check.Message)
+			}
+			if report.Valid != (tt.result == integration.CheckPassed) {
+				t.Errorf("Expected valid=%v, got %v", tt.result == integration.CheckPassed, report.Valid)
+			}
+		})
+	}
+
+	// Invalid configurations are not probed
+	api := &fakeProbeAPI{}
+	report := integration.BuildValidationReport("testdata/does-not-exist.json", false)
+	report.Probe(context.Background(), api)
+	if len(api.posts) != 0 {
+		t.Errorf("Expected no probe for an invalid configuration, got %v", api.posts)
+	}
+	if check := report.Checks[len(report.Checks)-1]; check.Result != integration.CheckSkipped {
+		t.Errorf("Expected skipped connectivity check, got %+v", check)
+	}
+}
@@ -11,10 +11,13 @@ import (
     "github.com/stretchr/testify/assert"
     "github.com/stretchr/testify/require"
     "golang.org/x/term"
+    "gopkg.in/yaml.v3"
 
+    "github.com/blackpoint/cli/internal/output"
     "github.com/blackpoint/cli/internal/output/formatter"
     "github.com/blackpoint/cli/internal/output/printer"
     "github.com/blackpoint/cli/internal/output/table"
+    types "github.com/blackpoint/cli/pkg/integration"
 )
 
 // Test fixtures
@@ -236,4 +239,93 @@ func TestProgressBar(t *testing.T) {
         err = bar.Set(50)
         assert.NoError(t, err)
     })
-}
\ No newline at end of file
+}
+
+func TestRender(t *testing.T) {
+    t.Run("renders json", func(t *testing.T) {
+        var buf bytes.Buffer
+        require.NoError(t, output.Render(&buf, "json", testData))
+
+        var parsed map[string]interface{}
+        require.NoError(t, json.Unmarshal(buf.Bytes(), &parsed))
+        assert.Equal(t, "test", parsed["string"])
+    })
+
+    t.Run("renders csv with header row", func(t *testing.T) {
+        var buf bytes.Buffer
+        require.NoError(t, output.Render(&buf, "csv", testTableData))
+
+        lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+        require.Len(t, lines, len(testTableData))
+        assert.Equal(t, "ID,Status,Description", string(lines[0]))
+    })
+
+    t.Run("renders table", func(t *testing.T) {
+        var buf bytes.Buffer
+        require.NoError(t, output.Render(&buf, "table", testTableData))
+        assert.Contains(t, buf.String(), "Operation completed")
+    })
+
+    t.Run("rejects non-tabular data for csv", func(t *testing.T) {
+        var buf bytes.Buffer
+        assert.Error(t, output.Render(&buf, "csv", testData))
+    })
+
+    t.Run("rejects unsupported format", func(t *testing.T) {
+        var buf bytes.Buffer
+        assert.Error(t, output.Render(&buf, "xml", testData))
+    })
+}
+
+// testDeploymentStatuses is a representative command result
+var testDeploymentStatuses = types.DeploymentStatusList{
+    {
+        ID:             "a1b2c3d4-0000-4000-8000-000000000001",
+        PlatformType:   "okta",
+        Environment:    "production",
+        Status:         "completed",
+        StartTime:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+        CompletionTime: time.Date(2024, 1, 2, 3, 9, 5, 0, time.UTC),
+    },
+    {
+        ID:           "a1b2c3d4-0000-4000-8000-000000000002",
+        PlatformType: "crowdstrike",
+        Environment:  "staging",
+        Status:       "failed",
+        Error:        "deployment execution failed",
+        StartTime:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+    },
+}
+
+func TestRenderResultFormats(t *testing.T) {
+    t.Run("json", func(t *testing.T) {
+        var buf bytes.Buffer
+        require.NoError(t, output.Render(&buf, "json", testDeploymentStatuses))
+
+        var parsed types.DeploymentStatusList
+        require.NoError(t, json.Unmarshal(buf.Bytes(), &parsed))
+        assert.Equal(t, testDeploymentStatuses[0].ID, parsed[0].ID)
+        assert.Equal(t, "failed", parsed[1].Status)
+        assert.True(t, testDeploymentStatuses[0].CompletionTime.Equal(parsed[0].CompletionTime))
+    })
+
+    t.Run("yaml uses json field names", func(t *testing.T) {
+        var buf bytes.Buffer
+        require.NoError(t, output.Render(&buf, "yaml", testDeploymentStatuses))
+
+        var parsed []map[string]interface{}
+        require.NoError(t, yaml.Unmarshal(buf.Bytes(), &parsed))
+        require.Len(t, parsed, 2)
+        assert.Equal(t, "okta", parsed[0]["platform_type"])
+        assert.Equal(t, "deployment execution failed", parsed[1]["error"])
+        assert.NotContains(t, parsed[0], "error")
+    })
+
+    t.Run("table", func(t *testing.T) {
+        var buf bytes.Buffer
+        require.NoError(t, output.Render(&buf, "table", testDeploymentStatuses))
+        assert.Contains(t, buf.String(), "Platform")
+        assert.Contains(t, buf.String(), "crowdstrike")
+        assert.Contains(t, buf.String(), "deployment execution failed")
+    })
+}
@@ -137,11 +137,12 @@ func testBatchEventProcessing(ctx context.Context, t *testing.T) error {
 
     // Process batch with monitoring
     startTime := time.Now()
-    silverEvents, err := processor.Process(ctx, bronzeEvents)
+    silverEvents, failures, err := processor.Process(ctx, bronzeEvents)
     processingTime := time.Since(startTime)
 
     // Validate batch processing
     require.NoError(t, err)
+    require.Empty(t, failures)
     require.NotNil(t, silverEvents)
     assert.Equal(t, len(bronzeEvents), len(silverEvents))
 
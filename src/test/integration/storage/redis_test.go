@@ -3,6 +3,8 @@ package storage_test
 import (
     "context"
     "encoding/json"
+    "fmt"
+    "sync"
     "testing"
     "time"
 
@@ -72,6 +74,7 @@ func TestRedisIntegration(t *testing.T) {
     suite.AddTestCase(testBasicOperations(t))
     suite.AddTestCase(testClusterMode(t))
     suite.AddTestCase(testPerformance(t))
+    suite.AddTestCase(testBatchConcurrency(t))
     suite.AddTestCase(testSecurityCompliance(t))
     suite.AddTestCase(testMonitoringIntegration(t))
 
@@ -180,6 +183,24 @@ func testClusterMode(t *testing.T) *framework.TestCase {
                 }
             }
 
+            // Test the same writes as a single pipelined batch
+            items := make(map[string]interface{}, testBatchSize)
+            for i := 0; i < testBatchSize; i++ {
+                key := fmt.Sprintf("%scluster_batch_test_%d", testKeyPrefix, i)
+                items[key] = map[string]interface{}{
+                    "index": i,
+                    "data":  make([]byte, testValueSize),
+                }
+            }
+
+            timer := prometheus.NewTimer(redisOperationDuration.WithLabelValues("cluster_set_batch", "success"))
+            err = client.SetBatch(ctx, items, &defaultTTL)
+            timer.ObserveDuration()
+            if err != nil {
+                redisOperationErrors.WithLabelValues("cluster_set_batch", "error").Inc()
+                return err
+            }
+
             return nil
         },
     }
@@ -233,6 +254,56 @@ func testPerformance(t *testing.T) *framework.TestCase {
     }
 }
 
+func testBatchConcurrency(t *testing.T) *framework.TestCase {
+    return &framework.TestCase{
+        Name: "batch_concurrency",
+        Run: func(ctx context.Context) error {
+            client, err := initRedisClient(t)
+            if err != nil {
+                return err
+            }
+            defer client.Close()
+
+            // Workers share one client and write overlapping batches at once
+            perWorker := testBatchSize * 2 / testConcurrency
+            var wg sync.WaitGroup
+            errChan := make(chan error, testConcurrency)
+            for i := 0; i < testConcurrency; i++ {
+                wg.Add(1)
+                go func(workerID int) {
+                    defer wg.Done()
+                    items := make(map[string]interface{}, perWorker)
+                    for j := 0; j < perWorker; j++ {
+                        items[fmt.Sprintf("%sbatch_test_%d_%d", testKeyPrefix, workerID, j)] = j
+                    }
+                    if err := client.SetBatch(ctx, items, &defaultTTL); err != nil {
+                        redisOperationErrors.WithLabelValues("set_batch", "error").Inc()
+                        errChan <- err
+                    }
+                }(i)
+            }
+            wg.Wait()
+            close(errChan)
+            if err := <-errChan; err != nil {
+                return err
+            }
+
+            // Every worker's writes must be readable with the right value
+            for i := 0; i < testConcurrency; i++ {
+                for j := 0; j < perWorker; j++ {
+                    var value int
+                    if err := client.Get(ctx, fmt.Sprintf("%sbatch_test_%d_%d", testKeyPrefix, i, j), &value); err != nil {
+                        return err
+                    }
+                    require.Equal(t, j, value)
+                }
+            }
+
+            return nil
+        },
+    }
+}
+
 func testSecurityCompliance(t *testing.T) *framework.TestCase {
     return &framework.TestCase{
         Name: "security_compliance",
@@ -0,0 +1,146 @@
+// Package framework provides JUnit XML rendering of test reports for CI systems.
+package framework
+
+import (
+    "encoding/xml"
+    "fmt"
+    "io"
+    "sort"
+    "time"
+)
+
+const (
+    junitReportFormat = "junit"
+    junitSuiteName    = "blackpoint_security_integration"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+    XMLName  xml.Name         `xml:"testsuites"`
+    Tests    int              `xml:"tests,attr"`
+    Failures int              `xml:"failures,attr"`
+    Time     string           `xml:"time,attr"`
+    Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the test cases of one reporter run
+type junitTestSuite struct {
+    Name       string          `xml:"name,attr"`
+    Tests      int             `xml:"tests,attr"`
+    Failures   int             `xml:"failures,attr"`
+    Time       string          `xml:"time,attr"`
+    Timestamp  string          `xml:"timestamp,attr"`
+    Properties []junitProperty `xml:"properties>property,omitempty"`
+    TestCases  []junitTestCase `xml:"testcase"`
+}
+
+// junitProperty carries a named score such as accuracy or security validation
+type junitProperty struct {
+    Name  string `xml:"name,attr"`
+    Value string `xml:"value,attr"`
+}
+
+// junitTestCase is a single test result
+type junitTestCase struct {
+    Name       string          `xml:"name,attr"`
+    ClassName  string          `xml:"classname,attr"`
+    Time       string          `xml:"time,attr"`
+    Properties []junitProperty `xml:"properties>property,omitempty"`
+    Failure    *junitFailure   `xml:"failure,omitempty"`
+}
+
+// junitFailure describes why a test case failed
+type junitFailure struct {
+    Message string `xml:"message,attr"`
+    Type    string `xml:"type,attr"`
+    Content string `xml:",chardata"`
+}
+
+// WriteJUnitReport writes the recorded results as JUnit XML for CI systems
+func (tr *TestReporter) WriteJUnitReport(w io.Writer, securityCtx *SecurityContext) error {
+    report, err := tr.GenerateReport(junitReportFormat, securityCtx)
+    if err != nil {
+        return err
+    }
+    _, err = io.WriteString(w, report[junitReportFormat].(string))
+    return err
+}
+
+// renderJUnit converts a generated report to JUnit XML
+func renderJUnit(report map[string]interface{}, testMetrics map[string]interface{}) ([]byte, error) {
+    summary := report["summary"].(map[string]interface{})
+    security := report["security_validation"].(map[string]interface{})
+
+    suite := junitTestSuite{
+        Name:      junitSuiteName,
+        Timestamp: summary["timestamp"].(time.Time).Format(time.RFC3339),
+        Properties: []junitProperty{
+            {Name: "pass_rate", Value: formatScore(summary["pass_rate"])},
+            {Name: "average_accuracy", Value: formatScore(summary["average_accuracy"])},
+            {Name: "security_validation_score", Value: formatScore(security["validation_score"])},
+        },
+    }
+
+    // Sort by name so reports diff cleanly between runs
+    names := make([]string, 0, len(testMetrics))
+    for name := range testMetrics {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    var totalDuration float64
+    for _, name := range names {
+        m := testMetrics[name].(map[string]interface{})
+        duration, _ := m["duration"].(float64)
+        totalDuration += duration
+
+        testCase := junitTestCase{
+            Name:      name,
+            ClassName: junitSuiteName,
+            Time:      formatSeconds(duration),
+        }
+        if accuracy, ok := m["accuracy"].(float64); ok {
+            testCase.Properties = append(testCase.Properties, junitProperty{Name: "accuracy", Value: formatScore(accuracy)})
+        }
+        if score, ok := m["security_score"].(float64); ok {
+            testCase.Properties = append(testCase.Properties, junitProperty{Name: "security_score", Value: formatScore(score)})
+        }
+
+        if passed, _ := m["passed"].(bool); !passed {
+            message := "test failed"
+            if testErr, ok := m["error"].(error); ok && testErr != nil {
+                message = testErr.Error()
+            }
+            testCase.Failure = &junitFailure{Message: message, Type: "failure", Content: message}
+            suite.Failures++
+        }
+
+        suite.TestCases = append(suite.TestCases, testCase)
+    }
+    suite.Tests = len(suite.TestCases)
+    suite.Time = formatSeconds(totalDuration)
+
+    out, err := xml.MarshalIndent(junitTestSuites{
+        Tests:    suite.Tests,
+        Failures: suite.Failures,
+        Time:     suite.Time,
+        Suites:   []junitTestSuite{suite},
+    }, "", "  ")
+    if err != nil {
+        return nil, fmt.Errorf("failed to render JUnit report: %v", err)
+    }
+    return append([]byte(xml.Header), out...), nil
+}
+
+// formatSeconds renders a duration in seconds as JUnit expects
+func formatSeconds(seconds float64) string {
+    return fmt.Sprintf("%.3f", seconds)
+}
+
+// formatScore renders a numeric score property
+func formatScore(value interface{}) string {
+    if score, ok := value.(float64); ok {
+        return fmt.Sprintf("%.2f", score)
+    }
+    return fmt.Sprintf("%v", value)
+}
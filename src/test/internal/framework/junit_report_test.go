@@ -0,0 +1,97 @@
+package framework
+
+import (
+    "bytes"
+    "encoding/xml"
+    "errors"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+// TestGenerateJUnitReport verifies the JUnit report is well-formed and counts failures
+func TestGenerateJUnitReport(t *testing.T) {
+    reporter := &TestReporter{
+        testMetrics: map[string]interface{}{
+            "bronze_ingest": map[string]interface{}{
+                "passed":         true,
+                "duration":       1.5,
+                "accuracy":       99.5,
+                "security_score": 95.0,
+            },
+            "silver_normalize": map[string]interface{}{
+                "passed":   false,
+                "duration": 0.25,
+                "error":    errors.New("field mapping mismatch"),
+            },
+            "gold_alerting": map[string]interface{}{
+                "passed":   true,
+                "duration": 2.0,
+            },
+        },
+        startTime:    time.Now(),
+        metricsMutex: &sync.RWMutex{},
+        validationThresholds: map[string]float64{
+            "accuracy": accuracyThreshold,
+        },
+    }
+    securityCtx := &SecurityContext{}
+
+    var buf bytes.Buffer
+    if err := reporter.WriteJUnitReport(&buf, securityCtx); err != nil {
+        t.Fatalf("failed to write JUnit report: %v", err)
+    }
+    if !strings.HasPrefix(buf.String(), xml.Header) {
+        t.Error("expected an XML declaration")
+    }
+
+    var parsed junitTestSuites
+    if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+        t.Fatalf("JUnit report is not well-formed: %v", err)
+    }
+
+    if parsed.Tests != 3 || parsed.Failures != 1 {
+        t.Errorf("expected 3 tests and 1 failure, got %d tests and %d failures", parsed.Tests, parsed.Failures)
+    }
+    if len(parsed.Suites) != 1 || len(parsed.Suites[0].TestCases) != 3 {
+        t.Fatalf("expected one suite with 3 test cases, got %+v", parsed.Suites)
+    }
+
+    suite := parsed.Suites[0]
+    if suite.Time != "3.750" {
+        t.Errorf("expected suite time 3.750, got %s", suite.Time)
+    }
+    if !hasProperty(suite.Properties, "security_validation_score") {
+        t.Error("expected the security validation score as a suite property")
+    }
+
+    for _, tc := range suite.TestCases {
+        switch tc.Name {
+        case "silver_normalize":
+            if tc.Failure == nil || tc.Failure.Message != "field mapping mismatch" {
+                t.Errorf("expected failure message from the test error, got %+v", tc.Failure)
+            }
+        case "bronze_ingest":
+            if tc.Failure != nil {
+                t.Errorf("expected %s to pass", tc.Name)
+            }
+            if tc.Time != "1.500" || !hasProperty(tc.Properties, "accuracy") {
+                t.Errorf("expected duration and accuracy on %s, got %+v", tc.Name, tc)
+            }
+        default:
+            if tc.Failure != nil {
+                t.Errorf("expected %s to pass", tc.Name)
+            }
+        }
+    }
+}
+
+func hasProperty(properties []junitProperty, name string) bool {
+    for _, p := range properties {
+        if p.Name == name {
+            return true
+        }
+    }
+    return false
+}
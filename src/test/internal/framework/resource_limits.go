@@ -0,0 +1,198 @@
+// Package framework provides resource limit enforcement for parallel test execution.
+package framework
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "runtime"
+    "strconv"
+    "sync"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus" // v1.16.0
+
+    performance_metrics "github.com/blackpoint/security/test/pkg/metrics"
+)
+
+// Resource limit keys accepted in TestSuiteConfig.ResourceLimits
+const (
+    ResourceLimitMaxMemoryPercent = "max_memory_percent" // heap in use as a percentage of memory obtained from the OS
+    ResourceLimitMaxGoroutines    = "max_goroutines"
+    ResourceLimitMaxContainers    = "max_containers"
+
+    // Test case label declaring how many containers the test starts
+    containersLabel = "containers"
+)
+
+const (
+    // Fraction of a limit at which dispatch is throttled
+    resourceThrottleRatio = 0.8
+
+    // Pause between resource checks while dispatch is throttled
+    resourceThrottleInterval = 50 * time.Millisecond
+)
+
+// ErrResourceExhausted marks tests failed because a hard resource limit was exceeded
+var ErrResourceExhausted = errors.New("resource limit exhausted")
+
+var resourceThrottleCount = prometheus.NewCounterVec(
+    prometheus.CounterOpts{
+        Name: "blackpoint_test_resource_throttles_total",
+        Help: "Number of times test dispatch waited for resources",
+    },
+    []string{"resource"},
+)
+
+// resourceLimits holds parsed resource limits; zero means unlimited
+type resourceLimits struct {
+    maxMemoryPercent float64
+    maxGoroutines    int
+    maxContainers    int
+}
+
+// parseResourceLimits reads limits from a suite's ResourceLimits map
+func parseResourceLimits(config map[string]interface{}) (resourceLimits, error) {
+    var limits resourceLimits
+    for key, value := range config {
+        var n float64
+        switch v := value.(type) {
+        case int:
+            n = float64(v)
+        case int64:
+            n = float64(v)
+        case float64:
+            n = v
+        default:
+            return limits, fmt.Errorf("resource limit %s must be a number, got %T", key, value)
+        }
+        if n < 0 {
+            return limits, fmt.Errorf("resource limit %s must not be negative", key)
+        }
+
+        switch key {
+        case ResourceLimitMaxMemoryPercent:
+            limits.maxMemoryPercent = n
+        case ResourceLimitMaxGoroutines:
+            limits.maxGoroutines = int(n)
+        case ResourceLimitMaxContainers:
+            limits.maxContainers = int(n)
+        default:
+            return limits, fmt.Errorf("unknown resource limit: %s", key)
+        }
+    }
+    return limits, nil
+}
+
+// resourceGovernor throttles test dispatch as usage approaches the limits and
+// fails tests that push usage past them
+type resourceGovernor struct {
+    limits     resourceLimits
+    mu         sync.Mutex
+    containers int
+    throttles  int
+}
+
+// newResourceGovernor creates a governor for the given limits
+func newResourceGovernor(limits resourceLimits) *resourceGovernor {
+    return &resourceGovernor{limits: limits}
+}
+
+// acquire waits until there is headroom to dispatch a test needing the given
+// number of containers. It fails with ErrResourceExhausted if the test can
+// never fit or ctx ends while waiting.
+func (g *resourceGovernor) acquire(ctx context.Context, containers int) error {
+    if g.limits.maxContainers > 0 && containers > g.limits.maxContainers {
+        return fmt.Errorf("%w: test needs %d containers, limit is %d",
+            ErrResourceExhausted, containers, g.limits.maxContainers)
+    }
+
+    for {
+        resource := g.pressure(containers)
+        if resource == "" {
+            return nil
+        }
+
+        g.mu.Lock()
+        g.throttles++
+        g.mu.Unlock()
+        resourceThrottleCount.WithLabelValues(resource).Inc()
+
+        select {
+        case <-ctx.Done():
+            return fmt.Errorf("%w: timed out waiting for %s headroom", ErrResourceExhausted, resource)
+        case <-time.After(resourceThrottleInterval):
+        }
+    }
+}
+
+// pressure reserves containers and returns "" when there is headroom, or the
+// name of the resource that is near its limit
+func (g *resourceGovernor) pressure(containers int) string {
+    if g.limits.maxGoroutines > 0 &&
+        float64(runtime.NumGoroutine()) >= float64(g.limits.maxGoroutines)*resourceThrottleRatio {
+        return ResourceLimitMaxGoroutines
+    }
+    if g.limits.maxMemoryPercent > 0 && memoryPercent() >= g.limits.maxMemoryPercent*resourceThrottleRatio {
+        return ResourceLimitMaxMemoryPercent
+    }
+
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if g.limits.maxContainers > 0 && g.containers+containers > g.limits.maxContainers {
+        return ResourceLimitMaxContainers
+    }
+    g.containers += containers
+    return ""
+}
+
+// release returns containers reserved by acquire
+func (g *resourceGovernor) release(containers int) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    g.containers -= containers
+}
+
+// throttleCount returns how many times dispatch has waited for resources
+func (g *resourceGovernor) throttleCount() int {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    return g.throttles
+}
+
+// check reports a hard limit exceeded by the peak usage sampled during a test
+func (g *resourceGovernor) check(usage performance_metrics.ResourceMetrics) error {
+    // The sampler records the goroutine count as its CPU series
+    if g.limits.maxGoroutines > 0 && usage.CPU.Peak > float64(g.limits.maxGoroutines) {
+        return fmt.Errorf("%w: goroutines peaked at %.0f, limit is %d",
+            ErrResourceExhausted, usage.CPU.Peak, g.limits.maxGoroutines)
+    }
+    if g.limits.maxMemoryPercent > 0 && usage.Memory.Peak > g.limits.maxMemoryPercent {
+        return fmt.Errorf("%w: memory peaked at %.1f%%, limit is %.1f%%",
+            ErrResourceExhausted, usage.Memory.Peak, g.limits.maxMemoryPercent)
+    }
+    return nil
+}
+
+// testContainers returns the number of containers a test case declares
+func testContainers(tc *TestCase) int {
+    if tc.config == nil {
+        return 0
+    }
+    n, err := strconv.Atoi(tc.config.Labels[containersLabel])
+    if err != nil || n < 0 {
+        return 0
+    }
+    return n
+}
+
+// memoryPercent samples memory the same way as MeasureResourceUtilization
+func memoryPercent() float64 {
+    var m runtime.MemStats
+    runtime.ReadMemStats(&m)
+    return float64(m.Alloc) / float64(m.Sys) * 100
+}
+
+func init() {
+    prometheus.MustRegister(resourceThrottleCount)
+}
@@ -0,0 +1,72 @@
+package framework
+
+import (
+    "context"
+    "errors"
+    "runtime"
+    "testing"
+    "time"
+
+    performance_metrics "github.com/blackpoint/security/test/pkg/metrics"
+)
+
+// TestResourceGovernorThrottlesGoroutines verifies dispatch waits while goroutines are near the limit
+func TestResourceGovernorThrottlesGoroutines(t *testing.T) {
+    baseline := runtime.NumGoroutine()
+    governor := newResourceGovernor(resourceLimits{maxGoroutines: baseline + 10})
+
+    // Park enough goroutines to cross the throttle threshold
+    stop := make(chan struct{})
+    for i := 0; i < 10; i++ {
+        go func() { <-stop }()
+    }
+
+    acquired := make(chan error, 1)
+    go func() {
+        acquired <- governor.acquire(context.Background(), 0)
+    }()
+
+    select {
+    case <-acquired:
+        t.Fatal("expected dispatch to be throttled near the goroutine limit")
+    case <-time.After(4 * resourceThrottleInterval):
+    }
+    if governor.throttleCount() == 0 {
+        t.Error("expected throttles to be counted")
+    }
+
+    // Dispatch resumes once goroutines drain
+    close(stop)
+    select {
+    case err := <-acquired:
+        if err != nil {
+            t.Errorf("expected dispatch to resume, got %v", err)
+        }
+    case <-time.After(time.Second):
+        t.Fatal("dispatch did not resume after goroutines drained")
+    }
+}
+
+// TestResourceGovernorHardLimits verifies resource exhaustion errors
+func TestResourceGovernorHardLimits(t *testing.T) {
+    governor := newResourceGovernor(resourceLimits{maxGoroutines: 2, maxContainers: 1})
+
+    ctx, cancel := context.WithTimeout(context.Background(), 2*resourceThrottleInterval)
+    defer cancel()
+    if err := governor.acquire(ctx, 0); !errors.Is(err, ErrResourceExhausted) {
+        t.Errorf("expected resource exhaustion while waiting past the deadline, got %v", err)
+    }
+    if err := governor.acquire(context.Background(), 2); !errors.Is(err, ErrResourceExhausted) {
+        t.Errorf("expected resource exhaustion for a test needing too many containers, got %v", err)
+    }
+
+    var usage performance_metrics.ResourceMetrics
+    usage.CPU.Peak = 5
+    if err := governor.check(usage); !errors.Is(err, ErrResourceExhausted) {
+        t.Errorf("expected resource exhaustion for goroutine peak above the limit, got %v", err)
+    }
+
+    if _, err := parseResourceLimits(map[string]interface{}{"max_threads": 4}); err == nil {
+        t.Error("expected an unknown resource limit to be rejected")
+    }
+}
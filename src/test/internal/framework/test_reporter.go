@@ -147,7 +147,8 @@ func (tr *TestReporter) RecordTestResult(testCase *TestCase, passed bool, metric
     return nil
 }
 
-// GenerateReport generates final test execution report with security validation
+// GenerateReport generates final test execution report with security validation.
+// The "junit" format returns the JUnit XML document under the "junit" key.
 func (tr *TestReporter) GenerateReport(format string, securityCtx *SecurityContext) (map[string]interface{}, error) {
     tr.metricsMutex.RLock()
     defer tr.metricsMutex.RUnlock()
@@ -204,6 +205,15 @@ func (tr *TestReporter) GenerateReport(format string, securityCtx *SecurityConte
     switch format {
     case "json":
         return report, nil
+    case junitReportFormat:
+        junit, err := renderJUnit(report, tr.testMetrics)
+        if err != nil {
+            return nil, err
+        }
+        return map[string]interface{}{
+            "summary":         report["summary"],
+            junitReportFormat: string(junit),
+        }, nil
     default:
         return nil, fmt.Errorf("unsupported report format: %s", format)
     }
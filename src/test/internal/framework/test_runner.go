@@ -10,6 +10,8 @@ import (
     "time"
 
     "github.com/prometheus/client_golang/prometheus" // v1.16.0
+
+    performance_metrics "github.com/blackpoint/security/test/pkg/metrics"
 )
 
 // Default configuration values
@@ -55,6 +57,7 @@ type TestRunnerConfig struct {
     SecurityContext  map[string]interface{}
     ValidationConfig map[string]float64
     MonitoringConfig MonitoringConfig
+    ResourceLimits   map[string]interface{}
 }
 
 // MonitoringConfig defines monitoring settings
@@ -79,6 +82,7 @@ type TestRunner struct {
     t               *testing.T
     config          *TestRunnerConfig
     reporter        *TestReporter
+    governor        *resourceGovernor
     wg              sync.WaitGroup
     testQueue       chan *TestCase
     results         map[string]TestResult
@@ -107,6 +111,11 @@ func NewTestRunner(t *testing.T, config *TestRunnerConfig) (*TestRunner, error)
         }
     }
 
+    limits, err := parseResourceLimits(config.ResourceLimits)
+    if err != nil {
+        return nil, fmt.Errorf("invalid resource limits: %v", err)
+    }
+
     // Create context with timeout
     ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
 
@@ -125,6 +134,7 @@ func NewTestRunner(t *testing.T, config *TestRunnerConfig) (*TestRunner, error)
         t:           t,
         config:      config,
         reporter:    reporter,
+        governor:    newResourceGovernor(limits),
         testQueue:   make(chan *TestCase, 1000),
         results:     make(map[string]TestResult),
         monitor:     monitor,
@@ -205,8 +215,17 @@ func (tr *TestRunner) executeTest(tc *TestCase, workerID int) {
     metrics["worker_id"] = workerID
     metrics["start_time"] = startTime
 
+    // Wait for resource headroom before dispatching the test
+    var resourceErr error
+    containers := testContainers(tc)
+    if err := tr.governor.acquire(tr.ctx, containers); err != nil {
+        resourceErr = err
+    } else {
+        defer tr.governor.release(containers)
+    }
+
     // Execute test with retries
-    for attempt := 0; attempt <= maxRetries; attempt++ {
+    for attempt := 0; resourceErr == nil && attempt <= maxRetries; attempt++ {
         if attempt > 0 {
             testRetryCount.WithLabelValues(tc.Name()).Inc()
             time.Sleep(time.Second * time.Duration(attempt))
@@ -222,9 +241,16 @@ func (tr *TestRunner) executeTest(tc *TestCase, workerID int) {
             continue
         }
 
-        // Execute test
-        tc.Run()
-        
+        // Execute test, sampling resource usage against the hard limits
+        usage, _ := performance_metrics.MeasureResourceUtilization(tc.t, tc.Name(), func() error {
+            tc.Run()
+            return nil
+        })
+        if err := tr.governor.check(usage); err != nil {
+            resourceErr = err
+            break
+        }
+
         // Check for test failure
         if !tc.t.Failed() {
             break
@@ -233,6 +259,11 @@ func (tr *TestRunner) executeTest(tc *TestCase, workerID int) {
         lastErr = fmt.Errorf("test execution failed on attempt %d", attempt+1)
     }
 
+    // Resource exhaustion is not retried and fails the test outright
+    if resourceErr != nil {
+        lastErr = resourceErr
+    }
+
     // Record test duration
     duration := time.Since(startTime)
     testExecutionTime.WithLabelValues(
@@ -266,7 +297,11 @@ func (tr *TestRunner) executeTest(tc *TestCase, workerID int) {
     tr.results[tc.Name()] = result
     tr.resultsMutex.Unlock()
 
-    // Report test result
+    // Report test result with the failure cause for the JUnit report
+    metrics["security_score"] = securityScore
+    if lastErr != nil {
+        metrics["error"] = lastErr
+    }
     tr.reporter.RecordTestResult(tc, lastErr == nil, metrics, tr.config.SecurityContext)
 }
 
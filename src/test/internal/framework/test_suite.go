@@ -105,6 +105,7 @@ func NewTestSuite(t *testing.T, name string, config *TestSuiteConfig) *TestSuite
             "validation_enabled": config.SecurityEnabled,
         },
         ValidationConfig: config.ValidationConfig,
+        ResourceLimits:   config.ResourceLimits,
     })
     if err != nil {
         t.Fatalf("Failed to create test runner: %v", err)
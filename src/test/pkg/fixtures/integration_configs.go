@@ -184,7 +184,7 @@ func generateTestAuthConfig(authType string) integration.AuthenticationConfig {
 		return integration.AuthenticationConfig{
 			Type: "apikey",
 			Credentials: map[string]interface{}{
-				"api_key": "test-api-key",
+				"api_key": "test-api-key-0123456789",
 			},
 		}
 	default:
@@ -0,0 +1,179 @@
+// Package generators provides attack chain templates that produce correlated multi-tier scenarios
+package generators
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// Defaults applied to attack chain templates
+const (
+    defaultAttackChainStep   = 30 * time.Second
+    defaultAttackChainWindow = 15 * time.Minute
+)
+
+// AttackStage is one step of an attack chain, producing Count Bronze events
+// and their normalized Silver events
+type AttackStage struct {
+    Name      string
+    Platform  string
+    EventType string
+    Count     int
+    Fields    map[string]interface{}
+}
+
+// AttackChainTemplate describes a multi-stage attack against a single entity.
+// Events are spaced Step apart and must all fall within Window so a correlator
+// using that window groups the whole chain into one alert.
+type AttackChainTemplate struct {
+    Name     string
+    Rule     string
+    Severity string
+    Step     time.Duration
+    Window   time.Duration
+    Stages   []AttackStage
+}
+
+// AttackEntity identifies who the attack chain targets
+type AttackEntity struct {
+    ClientID string
+    User     string
+    SourceIP string
+}
+
+// ExpectedCorrelation is the grouping the analyzer should reproduce for a chain
+type ExpectedCorrelation struct {
+    AlertID        string
+    Rule           string
+    Entity         AttackEntity
+    SilverEventIDs []string
+    FirstEvent     time.Time
+    LastEvent      time.Time
+}
+
+// CorrelatedScenario is a linked scenario generated from an attack chain template
+type CorrelatedScenario struct {
+    *LinkedScenario
+    Correlations []ExpectedCorrelation
+}
+
+// CredentialCompromiseChain models failed logins followed by privilege
+// escalation and access to sensitive data
+var CredentialCompromiseChain = AttackChainTemplate{
+    Name:     "credential_compromise",
+    Rule:     "credential_compromise_chain",
+    Severity: "critical",
+    Step:     defaultAttackChainStep,
+    Window:   defaultAttackChainWindow,
+    Stages: []AttackStage{
+        {Name: "failed_login", Platform: "okta", EventType: "auth.failed", Count: 5,
+            Fields: map[string]interface{}{"outcome": "FAILURE"}},
+        {Name: "successful_login", Platform: "okta", EventType: "auth.success", Count: 1,
+            Fields: map[string]interface{}{"outcome": "SUCCESS"}},
+        {Name: "privilege_escalation", Platform: "okta", EventType: "iam.role_granted", Count: 1,
+            Fields: map[string]interface{}{"role": "super_admin"}},
+        {Name: "data_access", Platform: "aws", EventType: "data.accessed", Count: 3,
+            Fields: map[string]interface{}{"resource": "s3://finance-reports"}},
+    },
+}
+
+// GenerateAttackChain builds a scenario in which every Silver event shares the
+// entity's user and source IP and falls within the template window, and one
+// Gold alert correlates the whole chain. The returned correlations describe the
+// linkage the analyzer is expected to reproduce.
+func (g *EventGenerator) GenerateAttackChain(template AttackChainTemplate, entity AttackEntity) (*CorrelatedScenario, error) {
+    if err := template.validate(); err != nil {
+        return nil, err
+    }
+    if entity.ClientID == "" {
+        entity.ClientID = defaultScenarioClientID
+    }
+
+    builder := NewScenarioBuilder(fmt.Sprintf("%s/%s", template.Name, entity.User)).
+        WithClientID(entity.ClientID).
+        WithBaseTime(defaultScenarioBaseTime, template.step())
+
+    stages := make([]string, 0, len(template.Stages))
+    for _, stage := range template.Stages {
+        stages = append(stages, stage.Name)
+        for i := 0; i < stage.Count; i++ {
+            fields := copyFixtureData(stage.Fields)
+            fields["user"] = entity.User
+            fields["source_ip"] = entity.SourceIP
+            fields["stage"] = stage.Name
+
+            builder.WithBronzeEvent(stage.Platform, fields).
+                ExpectSilver(stage.EventType, fields)
+        }
+    }
+
+    builder.ExpectGoldAlert(template.Severity, map[string]interface{}{
+        "rule":         template.Rule,
+        "attack_chain": template.Name,
+        "stages":       stages,
+        "user":         entity.User,
+        "source_ip":    entity.SourceIP,
+    })
+
+    linked, err := builder.Build()
+    if err != nil {
+        return nil, err
+    }
+
+    silverEvents := linked.SilverEvents
+    alert := linked.GoldAlerts[0]
+    return &CorrelatedScenario{
+        LinkedScenario: linked,
+        Correlations: []ExpectedCorrelation{{
+            AlertID:        alert.AlertID,
+            Rule:           template.Rule,
+            Entity:         entity,
+            SilverEventIDs: linked.AlertSources[alert.AlertID],
+            FirstEvent:     silverEvents[0].EventTime,
+            LastEvent:      silverEvents[len(silverEvents)-1].EventTime,
+        }},
+    }, nil
+}
+
+// step returns the spacing between chain events
+func (t AttackChainTemplate) step() time.Duration {
+    if t.Step <= 0 {
+        return defaultAttackChainStep
+    }
+    return t.Step
+}
+
+// validate checks that the template produces events within its window
+func (t AttackChainTemplate) validate() error {
+    if t.Name == "" || t.Rule == "" || len(t.Stages) == 0 {
+        return errors.NewError("E3001", "attack chain requires a name, rule and stages", map[string]interface{}{
+            "name": t.Name,
+        })
+    }
+
+    events := 0
+    for _, stage := range t.Stages {
+        if stage.Count <= 0 || stage.EventType == "" {
+            return errors.NewError("E3001", "attack stage requires an event type and positive count", map[string]interface{}{
+                "chain": t.Name,
+                "stage": stage.Name,
+            })
+        }
+        events += stage.Count
+    }
+
+    window := t.Window
+    if window <= 0 {
+        window = defaultAttackChainWindow
+    }
+    if span := time.Duration(events-1) * t.step(); span > window {
+        return errors.NewError("E3001", "attack chain spans more than its correlation window", map[string]interface{}{
+            "chain":  t.Name,
+            "span":   span.String(),
+            "window": window.String(),
+        })
+    }
+    return nil
+}
@@ -0,0 +1,55 @@
+package generators
+
+import (
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestGenerateAttackChainCorrelation(t *testing.T) {
+    gen, err := NewEventGenerator(&GeneratorConfig{})
+    require.NoError(t, err)
+
+    entity := AttackEntity{ClientID: "client-001", User: "alice", SourceIP: "203.0.113.10"}
+    scenario, err := gen.GenerateAttackChain(CredentialCompromiseChain, entity)
+    require.NoError(t, err)
+
+    require.Len(t, scenario.SilverEvents, 10)
+    require.Len(t, scenario.GoldAlerts, 1)
+    require.Len(t, scenario.Correlations, 1)
+
+    // Every Silver event shares the entity and falls inside the window
+    correlation := scenario.Correlations[0]
+    for _, event := range scenario.SilverEvents {
+        assert.Equal(t, "alice", event.NormalizedData["user"])
+        assert.Equal(t, "203.0.113.10", event.NormalizedData["source_ip"])
+        assert.Equal(t, "client-001", event.ClientID)
+    }
+    assert.LessOrEqual(t, correlation.LastEvent.Sub(correlation.FirstEvent), CredentialCompromiseChain.Window)
+
+    // The stages appear in chain order
+    assert.Equal(t, "failed_login", scenario.SilverEvents[0].NormalizedData["stage"])
+    assert.Equal(t, "privilege_escalation", scenario.SilverEvents[6].NormalizedData["stage"])
+    assert.Equal(t, "data_access", scenario.SilverEvents[9].NormalizedData["stage"])
+
+    // The alert traces back to every Silver event in the chain
+    alert := scenario.GoldAlerts[0]
+    assert.Equal(t, alert.AlertID, correlation.AlertID)
+    assert.Len(t, correlation.SilverEventIDs, 10)
+    for i, event := range scenario.SilverEvents {
+        assert.Equal(t, event.EventID, correlation.SilverEventIDs[i])
+    }
+    assert.Equal(t, CredentialCompromiseChain.Rule, alert.IntelligenceData["rule"])
+}
+
+func TestGenerateAttackChainRejectsChainsOutsideWindow(t *testing.T) {
+    gen, err := NewEventGenerator(&GeneratorConfig{})
+    require.NoError(t, err)
+
+    template := CredentialCompromiseChain
+    template.Step = 5 * time.Minute
+    _, err = gen.GenerateAttackChain(template, AttackEntity{User: "bob"})
+    assert.Error(t, err)
+}
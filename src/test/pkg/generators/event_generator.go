@@ -4,6 +4,7 @@ package generators
 import (
     "encoding/json"
     "fmt"
+    "math/rand"
     "sync"
     "time"
 
@@ -45,6 +46,10 @@ type GeneratorConfig struct {
     SecurityContext   map[string]interface{}
     ValidationRules   []ValidationRule
     PerformanceParams PerformanceParams
+
+    // Seed makes generation reproducible: generators with the same non-zero
+    // seed produce identical events. Zero keeps time-based randomness.
+    Seed int64
 }
 
 // ValidationRule defines a rule for validating generated events
@@ -71,6 +76,8 @@ type EventGenerator struct {
     metrics        *GeneratorMetrics
     mutex          sync.RWMutex
     complianceRules map[string][]string
+    rng            *rand.Rand // nil unless seeded
+    rngMutex       sync.Mutex
 }
 
 // GeneratorMetrics tracks event generation statistics
@@ -80,6 +87,7 @@ type GeneratorMetrics struct {
     ComplianceErrors   uint64
     ProcessingTime     time.Duration
     BatchesCompleted   uint64
+    EventsDropped      uint64
 }
 
 // ErrorPattern defines patterns for generating test errors
@@ -114,6 +122,9 @@ func NewEventGenerator(config *GeneratorConfig) (*EventGenerator, error) {
         metrics:        &GeneratorMetrics{},
         complianceRules: complianceRules,
     }
+    if config.Seed != 0 {
+        gen.rng = rand.New(rand.NewSource(config.Seed))
+    }
 
     // Initialize event templates
     if err := gen.initializeTemplates(); err != nil {
@@ -125,16 +136,22 @@ func NewEventGenerator(config *GeneratorConfig) (*EventGenerator, error) {
 
 // GenerateEvent generates a single event for the specified tier
 func (g *EventGenerator) GenerateEvent(tier string, eventType string, securityContext map[string]interface{}) (interface{}, error) {
+    return g.generateEvent(tier, eventType, securityContext, g.eventRand())
+}
+
+// generateEvent generates a single event drawing randomness from rng, or from
+// the clock when rng is nil
+func (g *EventGenerator) generateEvent(tier string, eventType string, securityContext map[string]interface{}, rng *rand.Rand) (interface{}, error) {
     g.mutex.Lock()
     defer g.mutex.Unlock()
 
     switch tier {
     case "bronze":
-        return g.generateBronzeEvent(eventType, securityContext)
+        return g.generateBronzeEvent(eventType, securityContext, rng)
     case "silver":
-        return g.generateSilverEvent(eventType, securityContext)
+        return g.generateSilverEvent(eventType, securityContext, rng)
     case "gold":
-        return g.generateGoldEvent(eventType, securityContext)
+        return g.generateGoldEvent(eventType, securityContext, rng)
     default:
         return nil, errors.NewError("E3001", "unsupported tier", nil)
     }
@@ -150,6 +167,13 @@ func (g *EventGenerator) GenerateBatch(tier string, count int) ([]interface{}, e
     errors := make([]error, count)
     var wg sync.WaitGroup
 
+    // Draw per-event randomness up front so seeded batches do not depend on
+    // goroutine scheduling
+    rngs := make([]*rand.Rand, count)
+    for j := range rngs {
+        rngs[j] = g.eventRand()
+    }
+
     // Calculate optimal batch size for parallel processing
     batchSize := g.config.BatchSize
     if batchSize > count {
@@ -167,7 +191,7 @@ func (g *EventGenerator) GenerateBatch(tier string, count int) ([]interface{}, e
             }
 
             for j := start; j < end; j++ {
-                event, err := g.GenerateEvent(tier, g.randomEventType(), g.generateSecurityContext())
+                event, err := g.generateEvent(tier, randomEventType(rngs[j]), g.generateSecurityContext(), rngs[j])
                 results[j] = event
                 errors[j] = err
             }
@@ -186,7 +210,9 @@ func (g *EventGenerator) GenerateBatch(tier string, count int) ([]interface{}, e
     return results, nil
 }
 
-// GenerateTestScenario generates a complete test scenario across all tiers
+// GenerateTestScenario generates a complete test scenario across all tiers.
+// An "attack_chain" AttackChainTemplate (and optional "attack_entity") adds a
+// correlated chain; use GenerateAttackChain directly to get its linkage.
 func (g *EventGenerator) GenerateTestScenario(scenarioConfig map[string]interface{}) (*TestScenario, error) {
     scenario := &TestScenario{
         BronzeEvents: make([]*schema.BronzeEvent, 0),
@@ -226,19 +252,35 @@ func (g *EventGenerator) GenerateTestScenario(scenarioConfig map[string]interfac
         scenario.GoldAlerts = alerts
     }
 
+    // Embed an attack chain whose Gold alert traces back to specific Silver events
+    if template, ok := scenarioConfig["attack_chain"].(AttackChainTemplate); ok {
+        entity, _ := scenarioConfig["attack_entity"].(AttackEntity)
+        chain, err := g.GenerateAttackChain(template, entity)
+        if err != nil {
+            return nil, err
+        }
+        scenario.BronzeEvents = append(scenario.BronzeEvents, chain.BronzeEvents...)
+        scenario.SilverEvents = append(scenario.SilverEvents, chain.SilverEvents...)
+        scenario.GoldAlerts = append(scenario.GoldAlerts, chain.GoldAlerts...)
+    }
+
     return scenario, nil
 }
 
 // Helper functions
 
-func (g *EventGenerator) generateBronzeEvent(eventType string, securityContext map[string]interface{}) (*schema.BronzeEvent, error) {
-    clientID := uuid.New().String()
-    payload := g.generateEventPayload(eventType)
+func (g *EventGenerator) generateBronzeEvent(eventType string, securityContext map[string]interface{}, rng *rand.Rand) (*schema.BronzeEvent, error) {
+    clientID := newEventID(rng)
+    payload := g.generateEventPayload(eventType, rng)
 
     event, err := schema.NewBronzeEvent(clientID, "test_platform", payload)
     if err != nil {
         return nil, err
     }
+    if rng != nil {
+        event.ID = newEventID(rng)
+        event.Timestamp = eventTime(rng)
+    }
 
     // Add security context
     if securityContext != nil {
@@ -248,39 +290,50 @@ func (g *EventGenerator) generateBronzeEvent(eventType string, securityContext m
     return event, nil
 }
 
-func (g *EventGenerator) generateSilverEvent(eventType string, securityContext map[string]interface{}) (*schema.SilverEvent, error) {
-    normalizedData := g.generateNormalizedData(eventType)
+func (g *EventGenerator) generateSilverEvent(eventType string, securityContext map[string]interface{}, rng *rand.Rand) (*schema.SilverEvent, error) {
+    normalizedData := g.generateNormalizedData(eventType, rng)
     secCtx := schema.SecurityContext{
         Classification: "CONFIDENTIAL",
         Sensitivity:    "HIGH",
         Compliance:     []string{"PCI-DSS", "SOC2"},
     }
 
-    event, err := schema.NewSilverEvent(uuid.New().String(), eventType, normalizedData, secCtx)
+    event, err := schema.NewSilverEvent(newEventID(rng), eventType, normalizedData, secCtx)
     if err != nil {
         return nil, err
     }
+    if rng != nil {
+        event.EventID = newEventID(rng)
+        event.EventTime = eventTime(rng)
+        event.AuditMetadata.CreatedAt = event.EventTime
+        event.AuditMetadata.NormalizedAt = event.EventTime
+    }
 
     return event, nil
 }
 
-func (g *EventGenerator) generateGoldEvent(eventType string, securityContext map[string]interface{}) (*alert.Alert, error) {
+func (g *EventGenerator) generateGoldEvent(eventType string, securityContext map[string]interface{}, rng *rand.Rand) (*alert.Alert, error) {
     ctx := &alert.SecurityMetadata{
         Classification: "RESTRICTED",
         DataSensitivity: "HIGH",
     }
 
     event := &schema.GoldEvent{
-        Severity: g.randomSeverity(),
-        IntelligenceData: g.generateIntelligenceData(),
+        Severity: randomSeverity(rng),
+        IntelligenceData: g.generateIntelligenceData(rng),
     }
 
-    alert, err := alert.CreateAlert(event, ctx)
-    if err != nil {
-        return nil, err
+    // Seeded alerts take their ID, timestamps and audit trail from rng
+    var opts alert.AlertOptions
+    if rng != nil {
+        createdAt := eventTime(rng)
+        opts = alert.AlertOptions{
+            Now:  func() time.Time { return createdAt },
+            Rand: rng,
+        }
     }
 
-    return alert, nil
+    return alert.CreateAlertWithOptions(event, ctx, opts)
 }
 
 // TestScenario represents a complete test scenario across all tiers
@@ -290,12 +343,47 @@ type TestScenario struct {
     GoldAlerts   []*alert.Alert
 }
 
-func (g *EventGenerator) randomEventType() string {
-    return supportedEventTypes[time.Now().UnixNano()%int64(len(supportedEventTypes))]
+// eventRand returns the randomness source for one event: a generator derived
+// from the seed when seeded, otherwise nil for time-based randomness
+func (g *EventGenerator) eventRand() *rand.Rand {
+    if g.rng == nil {
+        return nil
+    }
+    g.rngMutex.Lock()
+    defer g.rngMutex.Unlock()
+    return rand.New(rand.NewSource(g.rng.Int63()))
+}
+
+func randomEventType(rng *rand.Rand) string {
+    if rng == nil {
+        return supportedEventTypes[time.Now().UnixNano()%int64(len(supportedEventTypes))]
+    }
+    return supportedEventTypes[rng.Intn(len(supportedEventTypes))]
 }
 
-func (g *EventGenerator) randomSeverity() string {
-    return supportedSeverities[time.Now().UnixNano()%int64(len(supportedSeverities))]
+func randomSeverity(rng *rand.Rand) string {
+    if rng == nil {
+        return supportedSeverities[time.Now().UnixNano()%int64(len(supportedSeverities))]
+    }
+    return supportedSeverities[rng.Intn(len(supportedSeverities))]
+}
+
+// newEventID returns a random UUID, drawn from rng when seeded
+func newEventID(rng *rand.Rand) string {
+    if rng == nil {
+        return uuid.New().String()
+    }
+    id, _ := uuid.NewRandomFromReader(rng)
+    return id.String()
+}
+
+// eventTime returns the current time, or a time within a day of the fixed
+// scenario base time when seeded
+func eventTime(rng *rand.Rand) time.Time {
+    if rng == nil {
+        return time.Now().UTC()
+    }
+    return defaultScenarioBaseTime.Add(time.Duration(rng.Int63n(int64(24 * time.Hour))))
 }
 
 func (g *EventGenerator) generateSecurityContext() map[string]interface{} {
@@ -306,31 +394,40 @@ func (g *EventGenerator) generateSecurityContext() map[string]interface{} {
     }
 }
 
-func (g *EventGenerator) generateEventPayload(eventType string) json.RawMessage {
+func (g *EventGenerator) generateEventPayload(eventType string, rng *rand.Rand) json.RawMessage {
     payload := map[string]interface{}{
         "event_type": eventType,
-        "timestamp":  time.Now().UTC(),
+        "timestamp":  eventTime(rng),
         "source_ip":  "192.168.1.1",
-        "user_id":    uuid.New().String(),
+        "user_id":    newEventID(rng),
     }
     data, _ := json.Marshal(payload)
     return data
 }
 
-func (g *EventGenerator) generateNormalizedData(eventType string) map[string]interface{} {
+func (g *EventGenerator) generateNormalizedData(eventType string, rng *rand.Rand) map[string]interface{} {
     return map[string]interface{}{
         "event_type":    eventType,
-        "normalized_at": time.Now().UTC(),
+        "normalized_at": eventTime(rng),
         "metadata":      map[string]string{"source": "test_generator"},
     }
 }
 
-func (g *EventGenerator) generateIntelligenceData() map[string]interface{} {
+func (g *EventGenerator) generateIntelligenceData(rng *rand.Rand) map[string]interface{} {
     return map[string]interface{}{
-        "threat_score":   0.85,
+        "threat_score":   threatScore(rng),
         "confidence":     0.9,
         "detection_rule": "TEST-001",
+        "detected_at":    eventTime(rng),
+    }
+}
+
+// threatScore returns a score in [0.7, 1.0), drawn from rng when seeded
+func threatScore(rng *rand.Rand) float64 {
+    if rng == nil {
+        return 0.7 + rand.Float64()*0.3
     }
+    return 0.7 + rng.Float64()*0.3
 }
 
 func initializeErrorPatterns() map[string]ErrorPattern {
@@ -351,7 +448,7 @@ func initializeErrorPatterns() map[string]ErrorPattern {
 func (g *EventGenerator) initializeTemplates() error {
     // Initialize templates for each event type
     for _, eventType := range supportedEventTypes {
-        g.templates[eventType] = g.generateEventPayload(eventType)
+        g.templates[eventType] = g.generateEventPayload(eventType, g.eventRand())
     }
     return nil
 }
\ No newline at end of file
@@ -0,0 +1,34 @@
+package generators
+
+import (
+    "encoding/json"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func generateSeededBatch(t *testing.T, seed int64, tier string) []byte {
+    gen, err := NewEventGenerator(&GeneratorConfig{BatchSize: 10, Seed: seed})
+    require.NoError(t, err)
+
+    batch, err := gen.GenerateBatch(tier, 50)
+    require.NoError(t, err)
+
+    data, err := json.Marshal(batch)
+    require.NoError(t, err)
+    return data
+}
+
+func TestEventGeneratorSeedReproducible(t *testing.T) {
+    for _, tier := range []string{"bronze", "silver", "gold"} {
+        t.Run(tier, func(t *testing.T) {
+            first := generateSeededBatch(t, 42, tier)
+            second := generateSeededBatch(t, 42, tier)
+            assert.Equal(t, string(first), string(second))
+
+            other := generateSeededBatch(t, 7, tier)
+            assert.NotEqual(t, string(first), string(other))
+        })
+    }
+}
@@ -0,0 +1,245 @@
+// Package generators provides a deterministic fixture builder for multi-tier test scenarios
+package generators
+
+import (
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "github.com/google/uuid" // v1.3.0
+
+    bronze "github.com/blackpoint/pkg/bronze/schema"
+    silver "github.com/blackpoint/pkg/silver/schema"
+    "github.com/blackpoint/pkg/gold/alert"
+    "github.com/blackpoint/pkg/common/errors"
+)
+
+// Defaults applied to deterministic scenarios
+var (
+    defaultScenarioBaseTime  = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+    defaultScenarioClientID  = "scenario-client"
+    defaultScenarioPlatform  = "test_platform"
+    defaultScenarioEventStep = time.Second
+    scenarioNamespace        = uuid.MustParse("6f1f6b3e-2d4c-4b7e-9a55-3c1d2b8f0a11")
+)
+
+// LinkedScenario is a TestScenario whose fixtures are explicitly linked across tiers
+type LinkedScenario struct {
+    TestScenario
+
+    // SilverSources maps each Silver event ID to the Bronze event it was derived from
+    SilverSources map[string]string
+
+    // AlertSources maps each Gold alert ID to the Silver event IDs it correlates
+    AlertSources map[string][]string
+}
+
+// ScenarioBuilder builds deterministic, linked Bronze/Silver/Gold fixtures.
+// Identifiers are derived from the scenario name and fixture position and
+// timestamps advance from a fixed base time, so repeated builds are identical.
+//
+//  scenario, err := NewScenarioBuilder("brute-force").
+//      WithBronzeEvent("okta", map[string]interface{}{"outcome": "FAILURE"}).
+//      ExpectSilver("auth.failed", map[string]interface{}{"user": "alice"}).
+//      ExpectGoldAlert("high", map[string]interface{}{"rule": "brute_force"}).
+//      Build()
+type ScenarioBuilder struct {
+    name          string
+    clientID      string
+    baseTime      time.Time
+    step          time.Duration
+    scenario      *LinkedScenario
+    pendingSilver []string
+    lastBronze    *bronze.BronzeEvent
+    err           error
+}
+
+// NewScenarioBuilder creates a builder for the named scenario
+func NewScenarioBuilder(name string) *ScenarioBuilder {
+    return &ScenarioBuilder{
+        name:     name,
+        clientID: defaultScenarioClientID,
+        baseTime: defaultScenarioBaseTime,
+        step:     defaultScenarioEventStep,
+        scenario: &LinkedScenario{
+            TestScenario: TestScenario{
+                BronzeEvents: make([]*bronze.BronzeEvent, 0),
+                SilverEvents: make([]*silver.SilverEvent, 0),
+                GoldAlerts:   make([]*alert.Alert, 0),
+            },
+            SilverSources: make(map[string]string),
+            AlertSources:  make(map[string][]string),
+        },
+    }
+}
+
+// WithClientID sets the client ID used for all fixtures
+func (b *ScenarioBuilder) WithClientID(clientID string) *ScenarioBuilder {
+    b.clientID = clientID
+    return b
+}
+
+// WithBaseTime sets the timestamp of the first fixture and the step between fixtures
+func (b *ScenarioBuilder) WithBaseTime(base time.Time, step time.Duration) *ScenarioBuilder {
+    b.baseTime = base.UTC()
+    if step > 0 {
+        b.step = step
+    }
+    return b
+}
+
+// WithBronzeEvent adds a Bronze event with the given source platform and payload
+func (b *ScenarioBuilder) WithBronzeEvent(platform string, payload map[string]interface{}) *ScenarioBuilder {
+    if b.err != nil {
+        return b
+    }
+    if platform == "" {
+        platform = defaultScenarioPlatform
+    }
+
+    data, err := json.Marshal(payload)
+    if err != nil {
+        b.err = errors.NewError("E3001", "invalid bronze payload", map[string]interface{}{
+            "scenario": b.name,
+            "index":    len(b.scenario.BronzeEvents),
+        })
+        return b
+    }
+
+    index := len(b.scenario.BronzeEvents)
+    event := &bronze.BronzeEvent{
+        ID:             b.fixtureID("bronze", index),
+        ClientID:       b.clientID,
+        SourcePlatform: platform,
+        Timestamp:      b.fixtureTime(index),
+        Payload:        data,
+        SchemaVersion:  "1.0",
+        AuditMetadata: map[string]string{
+            "scenario": b.name,
+        },
+    }
+
+    b.scenario.BronzeEvents = append(b.scenario.BronzeEvents, event)
+    b.lastBronze = event
+    return b
+}
+
+// ExpectSilver adds the Silver event expected from normalizing the most recent Bronze event
+func (b *ScenarioBuilder) ExpectSilver(eventType string, normalizedData map[string]interface{}) *ScenarioBuilder {
+    if b.err != nil {
+        return b
+    }
+    if b.lastBronze == nil {
+        b.err = errors.NewError("E3001", "ExpectSilver requires a preceding WithBronzeEvent", map[string]interface{}{
+            "scenario": b.name,
+        })
+        return b
+    }
+
+    index := len(b.scenario.SilverEvents)
+    source := b.lastBronze
+    eventTime := source.Timestamp
+
+    event := &silver.SilverEvent{
+        EventID:        b.fixtureID("silver", index),
+        ClientID:       b.clientID,
+        EventType:      eventType,
+        EventTime:      eventTime,
+        NormalizedData: copyFixtureData(normalizedData),
+        SchemaVersion:  "1.0",
+        BronzeEventID:  source.ID,
+        SecurityContext: silver.SecurityContext{
+            Classification: "CONFIDENTIAL",
+            Sensitivity:    "HIGH",
+            Compliance:     []string{"SOC2"},
+        },
+        AuditMetadata: silver.AuditMetadata{
+            CreatedAt:     eventTime,
+            CreatedBy:     "scenario_builder",
+            NormalizedAt:  eventTime,
+            NormalizedBy:  "scenario_builder",
+            SchemaVersion: "1.0",
+            SourceEventID: source.ID,
+        },
+        EncryptedFields: make(map[string][]byte),
+    }
+
+    b.scenario.SilverEvents = append(b.scenario.SilverEvents, event)
+    b.scenario.SilverSources[event.EventID] = source.ID
+    b.pendingSilver = append(b.pendingSilver, event.EventID)
+    return b
+}
+
+// ExpectGoldAlert adds the Gold alert expected from correlating every Silver event
+// added since the previous alert
+func (b *ScenarioBuilder) ExpectGoldAlert(severity string, intelligence map[string]interface{}) *ScenarioBuilder {
+    if b.err != nil {
+        return b
+    }
+    if len(b.pendingSilver) == 0 {
+        b.err = errors.NewError("E3001", "ExpectGoldAlert requires at least one preceding ExpectSilver", map[string]interface{}{
+            "scenario": b.name,
+        })
+        return b
+    }
+
+    index := len(b.scenario.GoldAlerts)
+    sources := append([]string(nil), b.pendingSilver...)
+    createdAt := b.scenario.SilverEvents[len(b.scenario.SilverEvents)-1].EventTime
+
+    data := copyFixtureData(intelligence)
+    data["source_event_ids"] = sources
+
+    fixture := &alert.Alert{
+        AlertID:          b.fixtureID("gold", index),
+        Status:           "new",
+        CreatedAt:        createdAt,
+        UpdatedAt:        createdAt,
+        Severity:         severity,
+        IntelligenceData: data,
+        SecurityMetadata: &alert.SecurityMetadata{
+            Classification:  "RESTRICTED",
+            DataSensitivity: "HIGH",
+        },
+        ComplianceTags: map[string]string{
+            "scenario": b.name,
+        },
+    }
+
+    b.scenario.GoldAlerts = append(b.scenario.GoldAlerts, fixture)
+    b.scenario.AlertSources[fixture.AlertID] = sources
+    b.pendingSilver = nil
+    return b
+}
+
+// Build returns the assembled scenario or the first error encountered while building
+func (b *ScenarioBuilder) Build() (*LinkedScenario, error) {
+    if b.err != nil {
+        return nil, b.err
+    }
+    if len(b.scenario.BronzeEvents) == 0 {
+        return nil, errors.NewError("E3001", "scenario has no bronze events", map[string]interface{}{
+            "scenario": b.name,
+        })
+    }
+    return b.scenario, nil
+}
+
+// fixtureID derives a stable UUID from the scenario name, tier and position
+func (b *ScenarioBuilder) fixtureID(tier string, index int) string {
+    return uuid.NewSHA1(scenarioNamespace, []byte(fmt.Sprintf("%s/%s/%s/%d", b.name, b.clientID, tier, index))).String()
+}
+
+// fixtureTime returns the timestamp for the fixture at the given position
+func (b *ScenarioBuilder) fixtureTime(index int) time.Time {
+    return b.baseTime.Add(time.Duration(index) * b.step)
+}
+
+// copyFixtureData copies caller-owned maps so later mutation cannot alter fixtures
+func copyFixtureData(data map[string]interface{}) map[string]interface{} {
+    copied := make(map[string]interface{}, len(data)+1)
+    for k, v := range data {
+        copied[k] = v
+    }
+    return copied
+}
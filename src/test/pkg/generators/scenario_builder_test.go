@@ -0,0 +1,64 @@
+package generators
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func buildBruteForceScenario(t *testing.T) *LinkedScenario {
+    scenario, err := NewScenarioBuilder("brute-force").
+        WithClientID("client-001").
+        WithBronzeEvent("okta", map[string]interface{}{"outcome": "FAILURE", "actor": "alice"}).
+        ExpectSilver("auth.failed", map[string]interface{}{"user": "alice"}).
+        WithBronzeEvent("okta", map[string]interface{}{"outcome": "FAILURE", "actor": "alice"}).
+        ExpectSilver("auth.failed", map[string]interface{}{"user": "alice"}).
+        ExpectGoldAlert("high", map[string]interface{}{"rule": "brute_force"}).
+        Build()
+    require.NoError(t, err)
+    return scenario
+}
+
+func TestScenarioBuilderDeterministic(t *testing.T) {
+    first := buildBruteForceScenario(t)
+    second := buildBruteForceScenario(t)
+
+    require.Len(t, first.BronzeEvents, 2)
+    require.Len(t, first.SilverEvents, 2)
+    require.Len(t, first.GoldAlerts, 1)
+
+    for i := range first.BronzeEvents {
+        assert.Equal(t, first.BronzeEvents[i].ID, second.BronzeEvents[i].ID)
+        assert.Equal(t, first.BronzeEvents[i].Timestamp, second.BronzeEvents[i].Timestamp)
+        assert.Equal(t, first.SilverEvents[i].EventID, second.SilverEvents[i].EventID)
+    }
+    assert.Equal(t, first.GoldAlerts[0].AlertID, second.GoldAlerts[0].AlertID)
+}
+
+func TestScenarioBuilderLinksTiers(t *testing.T) {
+    scenario := buildBruteForceScenario(t)
+
+    for i, event := range scenario.SilverEvents {
+        assert.Equal(t, scenario.BronzeEvents[i].ID, event.BronzeEventID)
+        assert.Equal(t, scenario.BronzeEvents[i].ID, scenario.SilverSources[event.EventID])
+    }
+
+    alert := scenario.GoldAlerts[0]
+    assert.Equal(t, []string{scenario.SilverEvents[0].EventID, scenario.SilverEvents[1].EventID},
+        scenario.AlertSources[alert.AlertID])
+    assert.Equal(t, "brute_force", alert.IntelligenceData["rule"])
+}
+
+func TestScenarioBuilderOrderingErrors(t *testing.T) {
+    _, err := NewScenarioBuilder("invalid").
+        ExpectSilver("auth.failed", nil).
+        Build()
+    assert.Error(t, err)
+
+    _, err = NewScenarioBuilder("invalid").
+        WithBronzeEvent("okta", map[string]interface{}{}).
+        ExpectGoldAlert("high", nil).
+        Build()
+    assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package generators
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestStreamRate(t *testing.T) {
+    gen, err := NewEventGenerator(&GeneratorConfig{Seed: 1})
+    require.NoError(t, err)
+
+    const rate = 200
+    ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+    defer cancel()
+
+    events, err := gen.Stream(ctx, "silver", rate)
+    require.NoError(t, err)
+
+    start := time.Now()
+    received := 0
+    for range events {
+        received++
+    }
+    elapsed := time.Since(start)
+
+    // The channel closes on cancellation and the emitted rate stays near the target
+    observed := float64(received) / elapsed.Seconds()
+    assert.InDelta(t, rate, observed, rate*0.15)
+    assert.Zero(t, gen.StreamDropped())
+}
+
+func TestStreamDropsWhenConsumerFallsBehind(t *testing.T) {
+    gen, err := NewEventGenerator(&GeneratorConfig{
+        Seed:              1,
+        PerformanceParams: PerformanceParams{RateLimit: 1000, BufferSize: 1},
+    })
+    require.NoError(t, err)
+
+    ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+    defer cancel()
+
+    events, err := gen.Stream(ctx, "bronze", 0)
+    require.NoError(t, err)
+
+    <-ctx.Done()
+    for range events {
+    }
+    assert.Greater(t, gen.StreamDropped(), uint64(0))
+
+    _, err = gen.Stream(context.Background(), "platinum", 10)
+    assert.Error(t, err)
+}
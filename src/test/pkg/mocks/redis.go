@@ -18,14 +18,14 @@ const (
 	maxMockEntries    = 10000
 )
 
-// Mock Redis error codes
+// Mock Redis error codes, registered in the common error catalog's Cache category
 const (
-	ErrKeyNotFound      = "REDIS001"
-	ErrKeyExpired      = "REDIS002"
-	ErrCapacityExceeded = "REDIS003"
-	ErrInvalidKey      = "REDIS004"
-	ErrInvalidValue    = "REDIS005"
-	ErrLockTimeout     = "REDIS006"
+	ErrKeyNotFound      = "E5001"
+	ErrKeyExpired      = "E5002"
+	ErrCapacityExceeded = "E5003"
+	ErrInvalidKey      = "E5004"
+	ErrInvalidValue    = "E5005"
+	ErrLockTimeout     = "E5006"
 )
 
 // MockOptions configures the behavior of the mock Redis client
@@ -0,0 +1,194 @@
+// Package validation provides streaming alert validation for large accuracy runs
+package validation
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/blackpoint/pkg/gold"
+)
+
+// streamResultBuffer bounds how far validation may run ahead of the reader
+const streamResultBuffer = 64
+
+// AlertResult is the validation of one alert pair in a stream together with
+// the aggregate of every pair validated so far
+type AlertResult struct {
+    // Index is the pair's position in the stream
+    Index   int
+    AlertID string
+
+    // Accuracy, SecurityScore and Passed are unset when Err is non-nil
+    Accuracy      float64
+    SecurityScore float64
+    Passed        bool
+    Err           error
+
+    Aggregate StreamAggregate
+
+    // Final marks the summary sent once both input channels are closed. It
+    // carries no pair; Summary holds the same results ValidateAlertBatch
+    // returns, and Err reports inputs of different lengths.
+    Final   bool
+    Summary map[string]interface{}
+}
+
+// StreamAggregate is the running accuracy of a validation stream
+type StreamAggregate struct {
+    Validated       int     `json:"validated"`
+    Failed          int     `json:"failed"`
+    AverageAccuracy float64 `json:"average_accuracy"`
+    SecurityScore   float64 `json:"security_score"`
+    SuccessRate     float64 `json:"success_rate"`
+}
+
+// accuracyAccumulator aggregates alert validations in constant memory. Failed
+// validations count towards the average accuracy as zero, as in batches.
+type accuracyAccumulator struct {
+    validated     int
+    failed        int
+    totalAccuracy float64
+    securityTotal float64
+}
+
+// add records the result of one ValidateAlert call
+func (a *accuracyAccumulator) add(results map[string]interface{}, err error) {
+    a.validated++
+    if err != nil {
+        a.failed++
+        return
+    }
+    a.totalAccuracy += results["accuracy"].(float64)
+    a.securityTotal += results["security_scores"].(map[string]float64)["overall"]
+}
+
+// aggregate returns the running totals
+func (a *accuracyAccumulator) aggregate() StreamAggregate {
+    agg := StreamAggregate{Validated: a.validated, Failed: a.failed}
+    if a.validated == 0 {
+        return agg
+    }
+    total := float64(a.validated)
+    agg.AverageAccuracy = a.totalAccuracy / total
+    if succeeded := a.validated - a.failed; succeeded > 0 {
+        agg.SecurityScore = a.securityTotal / float64(succeeded)
+    }
+    agg.SuccessRate = float64(a.validated-a.failed) / total * 100
+    return agg
+}
+
+// summary returns the aggregate in the result format of ValidateAlertBatch
+func (a *accuracyAccumulator) summary() map[string]interface{} {
+    agg := a.aggregate()
+    return map[string]interface{}{
+        "average_accuracy":   agg.AverageAccuracy,
+        "security_score":     agg.SecurityScore,
+        "failed_validations": agg.Failed,
+        "success_rate":       agg.SuccessRate,
+    }
+}
+
+// ValidateStream validates alerts pairwise as they arrive on actual and
+// expected, so memory stays bounded however many alerts a run covers. Each
+// pair produces an AlertResult with the running aggregate; once both inputs
+// are closed a Final result with the summary is sent and the channel closes.
+// If one input closes early the other is drained and the Final result
+// reports the mismatch. Cancelling ctx stops validation and closes the
+// channel without a summary.
+func (av *AlertValidator) ValidateStream(ctx context.Context, actual, expected <-chan *gold.Alert) (<-chan AlertResult, error) {
+    if actual == nil || expected == nil {
+        return nil, fmt.Errorf("actual and expected alert streams are required")
+    }
+
+    results := make(chan AlertResult, streamResultBuffer)
+    go func() {
+        defer close(results)
+
+        var acc accuracyAccumulator
+        send := func(result AlertResult) bool {
+            select {
+            case results <- result:
+                return true
+            case <-ctx.Done():
+                return false
+            }
+        }
+
+        for index := 0; ; index++ {
+            actualAlert, actualOK := receiveAlert(ctx, actual)
+            if ctx.Err() != nil {
+                return
+            }
+            expectedAlert, expectedOK := receiveAlert(ctx, expected)
+            if ctx.Err() != nil {
+                return
+            }
+
+            if !actualOK || !expectedOK {
+                final := AlertResult{Index: index, Final: true}
+                if actualOK || expectedOK {
+                    remaining := actual
+                    if expectedOK {
+                        remaining = expected
+                    }
+                    extra := drainAlerts(ctx, remaining)
+                    final.Err = fmt.Errorf("alert stream size mismatch: %d unmatched alerts after %d pairs", extra, index)
+                }
+                final.Aggregate = acc.aggregate()
+                final.Summary = av.streamSummary(&acc)
+                send(final)
+                return
+            }
+
+            alertResults, err := av.ValidateAlert(actualAlert, expectedAlert)
+            acc.add(alertResults, err)
+
+            result := AlertResult{Index: index, Err: err, Aggregate: acc.aggregate()}
+            if actualAlert != nil {
+                result.AlertID = actualAlert.AlertID
+            }
+            if err == nil {
+                result.Accuracy = alertResults["accuracy"].(float64)
+                result.SecurityScore = alertResults["security_scores"].(map[string]float64)["overall"]
+                result.Passed = alertResults["passed"].(bool)
+            }
+            if !send(result) {
+                return
+            }
+        }
+    }()
+
+    return results, nil
+}
+
+// streamSummary builds the final summary with its validation report
+func (av *AlertValidator) streamSummary(acc *accuracyAccumulator) map[string]interface{} {
+    summary := acc.summary()
+    if report, err := av.GenerateValidationReport(summary); err == nil {
+        summary["validation_report"] = report
+    }
+    return summary
+}
+
+// receiveAlert reads the next alert, reporting false once the stream closes
+func receiveAlert(ctx context.Context, alerts <-chan *gold.Alert) (*gold.Alert, bool) {
+    select {
+    case alert, ok := <-alerts:
+        // A nil alert is a pair that fails validation, not the end of the stream
+        return alert, ok
+    case <-ctx.Done():
+        return nil, false
+    }
+}
+
+// drainAlerts counts the alerts left on a stream that outlived the other,
+// the one already read included, so its producer is not left blocked
+func drainAlerts(ctx context.Context, remaining <-chan *gold.Alert) int {
+    count := 1
+    for {
+        if _, ok := receiveAlert(ctx, remaining); !ok {
+            return count
+        }
+        count++
+    }
+}
@@ -4,6 +4,10 @@ package validation
 
 import (
     "fmt"
+    "reflect"
+    "regexp"
+    "sort"
+    "strings"
     "sync"
     "time"
 
@@ -18,6 +22,7 @@ var AlertValidationModes = map[string]string{
     "fuzzy":    "partial_match",
     "weighted": "field_weighted",
     "security": "security_focused",
+    "compliance": "policy_completeness",
 }
 
 // AlertFieldWeights defines importance weights for different alert fields
@@ -37,12 +42,55 @@ var SecurityValidationThresholds = map[string]float64{
     "min_audit_score":      0.7,
 }
 
+// defaultSimilarityThreshold is the normalized similarity at which fuzzy mode
+// treats two strings as matching
+const defaultSimilarityThreshold = 0.8
+
+// CompliancePolicy maps a classification level to the compliance tags every
+// alert of that classification must carry
+type CompliancePolicy map[string][]string
+
+// defaultPolicyKey selects the required tags for classifications without their own entry
+const defaultPolicyKey = "DEFAULT"
+
+// DefaultCompliancePolicy defines the required compliance tags per classification
+var DefaultCompliancePolicy = CompliancePolicy{
+    "RESTRICTED":   {"SOC2", "ISO27001", "GDPR", "PCI-DSS"},
+    "CONFIDENTIAL": {"SOC2", "ISO27001", "GDPR"},
+    "INTERNAL":     {"SOC2"},
+}
+
+// ComplianceTaxonomy maps each recognized compliance framework to a regular
+// expression matching its control identifiers. Tags name a framework, such as
+// "SOC2", or one of its controls as FRAMEWORK:CONTROL, such as "SOC2:CC6.1".
+// Controls are upper-cased before matching, so patterns are written in upper
+// case. An empty pattern accepts only the framework-level tag.
+type ComplianceTaxonomy map[string]string
+
+// DefaultComplianceTaxonomy recognizes the frameworks alerts are tagged with
+var DefaultComplianceTaxonomy = ComplianceTaxonomy{
+    "SOC2":     `^(CC|A|C|PI|P)\d+(\.\d+)*$`,
+    "ISO27001": `^A\.\d+(\.\d+){1,2}$`,
+    "PCI-DSS":  `^\d+(\.\d+){0,3}$`,
+    "HIPAA":    `^164\.\d{3}(\([A-Z0-9]+\))*$`,
+    "GDPR":     `^ART\.?\s?\d+(\(\d+\))*$`,
+}
+
+// complianceFramework is a taxonomy entry with its control pattern compiled
+type complianceFramework struct {
+    controls *regexp.Regexp
+}
+
 // AlertValidator manages enhanced alert validation with security focus
 type AlertValidator struct {
     validationMode     string
     fieldWeights      map[string]float64
     securityThresholds map[string]float64
     metrics           *metrics.AccuracyMetrics
+    compliancePolicy   CompliancePolicy
+    complianceTaxonomy map[string]complianceFramework
+    strictWeighted     bool
+    similarityThreshold float64
     mu               sync.RWMutex
 }
 
@@ -68,14 +116,112 @@ func NewAlertValidator(mode string, weights map[string]float64, securityThreshol
         return nil, fmt.Errorf("failed to initialize accuracy metrics: %v", err)
     }
 
+    taxonomy, err := compileComplianceTaxonomy(DefaultComplianceTaxonomy)
+    if err != nil {
+        return nil, err
+    }
+
     return &AlertValidator{
         validationMode:      mode,
         fieldWeights:       weights,
         securityThresholds: securityThresholds,
         metrics:           metricsInstance,
+        compliancePolicy:   DefaultCompliancePolicy,
+        complianceTaxonomy: taxonomy,
+        similarityThreshold: defaultSimilarityThreshold,
     }, nil
 }
 
+// SetCompliancePolicy replaces the policy used to score compliance tag completeness
+// Classification keys are case-insensitive.
+func (av *AlertValidator) SetCompliancePolicy(policy CompliancePolicy) error {
+    normalized := make(CompliancePolicy, len(policy))
+    for classification, tags := range policy {
+        if len(tags) == 0 {
+            return fmt.Errorf("compliance policy for %s requires at least one tag", classification)
+        }
+        normalized[strings.ToUpper(classification)] = tags
+    }
+
+    av.mu.Lock()
+    defer av.mu.Unlock()
+    av.compliancePolicy = normalized
+    return nil
+}
+
+// SetComplianceTaxonomy replaces the frameworks and control identifiers
+// compliance tags are validated against. Framework names are case-insensitive;
+// pass DefaultComplianceTaxonomy plus new entries to add a framework.
+func (av *AlertValidator) SetComplianceTaxonomy(taxonomy ComplianceTaxonomy) error {
+    compiled, err := compileComplianceTaxonomy(taxonomy)
+    if err != nil {
+        return err
+    }
+
+    av.mu.Lock()
+    defer av.mu.Unlock()
+    av.complianceTaxonomy = compiled
+    return nil
+}
+
+// compileComplianceTaxonomy compiles each framework's control pattern
+func compileComplianceTaxonomy(taxonomy ComplianceTaxonomy) (map[string]complianceFramework, error) {
+    if len(taxonomy) == 0 {
+        return nil, fmt.Errorf("compliance taxonomy requires at least one framework")
+    }
+
+    compiled := make(map[string]complianceFramework, len(taxonomy))
+    for name, pattern := range taxonomy {
+        framework := complianceFramework{}
+        if pattern != "" {
+            re, err := regexp.Compile(pattern)
+            if err != nil {
+                return nil, fmt.Errorf("invalid control pattern for %s: %v", name, err)
+            }
+            framework.controls = re
+        }
+        compiled[normalizeFramework(name)] = framework
+    }
+    return compiled, nil
+}
+
+// SetStrictWeighting makes strict mode weight each exact field match by the
+// validator's field weights instead of counting all fields equally
+func (av *AlertValidator) SetStrictWeighting(enabled bool) {
+    av.mu.Lock()
+    defer av.mu.Unlock()
+    av.strictWeighted = enabled
+}
+
+// SetSimilarityThreshold sets the normalized similarity in (0, 1] at which
+// fuzzy mode treats two string fields as matching
+func (av *AlertValidator) SetSimilarityThreshold(threshold float64) error {
+    if threshold <= 0 || threshold > 1 {
+        return fmt.Errorf("similarity threshold must be in (0, 1], got %v", threshold)
+    }
+
+    av.mu.Lock()
+    defer av.mu.Unlock()
+    av.similarityThreshold = threshold
+    return nil
+}
+
+// ValidateCompliance scores the alert's compliance tags against the policy for
+// its classification and returns the required tags it is missing
+func (av *AlertValidator) ValidateCompliance(alert *gold.Alert) (float64, []string) {
+    av.mu.RLock()
+    defer av.mu.RUnlock()
+    return av.complianceCompleteness(alert)
+}
+
+// ValidateComplianceTags scores tags against the compliance taxonomy and
+// returns the fraction that are recognized along with the unknown tags
+func (av *AlertValidator) ValidateComplianceTags(tags []string) (float64, []string) {
+    av.mu.RLock()
+    defer av.mu.RUnlock()
+    return av.validateComplianceTags(tags)
+}
+
 // ValidateAlert validates a single alert with enhanced security context and compliance checks
 func (av *AlertValidator) ValidateAlert(actualAlert, expectedAlert *gold.Alert) (map[string]interface{}, error) {
     av.mu.Lock()
@@ -103,21 +249,37 @@ func (av *AlertValidator) ValidateAlert(actualAlert, expectedAlert *gold.Alert)
     }
     results["security_scores"] = securityScore
 
+    complianceScore, missingTags := av.complianceCompleteness(actualAlert)
+    results["compliance_score"] = complianceScore
+    results["missing_compliance_tags"] = missingTags
+    tagScore, unknownTags := av.validateComplianceTags(actualAlert.ComplianceTags)
+    results["compliance_tag_score"] = tagScore
+    results["unknown_compliance_tags"] = unknownTags
+
     // Calculate overall accuracy
     var accuracy float64
     switch av.validationMode {
     case "strict":
-        accuracy = av.calculateStrictAccuracy(actualAlert, expectedAlert)
+        var fields map[string]interface{}
+        accuracy, fields = av.calculateStrictAccuracy(actualAlert, expectedAlert)
+        results["field_results"] = fields
     case "weighted":
         accuracy = av.calculateWeightedAccuracy(actualAlert, expectedAlert)
     case "security":
         accuracy = av.calculateSecurityFocusedAccuracy(actualAlert, expectedAlert)
+    case "compliance":
+        accuracy = complianceScore * 100
     default:
-        accuracy = av.calculateFuzzyAccuracy(actualAlert, expectedAlert)
+        var similarity map[string]float64
+        accuracy, similarity = av.calculateFuzzyAccuracy(actualAlert, expectedAlert)
+        results["field_similarity"] = similarity
     }
 
     results["accuracy"] = accuracy
     results["passed"] = accuracy >= av.securityThresholds["min_security_score"]
+    if av.validationMode == "compliance" {
+        results["passed"] = complianceScore >= av.securityThresholds["min_compliance_score"]
+    }
 
     return results, nil
 }
@@ -128,28 +290,11 @@ func (av *AlertValidator) ValidateAlertBatch(actualAlerts, expectedAlerts []*gol
         return nil, fmt.Errorf("alert batch size mismatch")
     }
 
-    results := make(map[string]interface{})
-    var totalAccuracy float64
-    var securityScores []float64
-    failedValidations := 0
-
+    var acc accuracyAccumulator
     for i := range actualAlerts {
-        alertResults, err := av.ValidateAlert(actualAlerts[i], expectedAlerts[i])
-        if err != nil {
-            failedValidations++
-            continue
-        }
-
-        accuracy := alertResults["accuracy"].(float64)
-        totalAccuracy += accuracy
-        securityScores = append(securityScores, alertResults["security_scores"].(map[string]float64)["overall"])
+        acc.add(av.ValidateAlert(actualAlerts[i], expectedAlerts[i]))
     }
-
-    batchSize := float64(len(actualAlerts))
-    results["average_accuracy"] = totalAccuracy / batchSize
-    results["security_score"] = calculateAverageScore(securityScores)
-    results["failed_validations"] = failedValidations
-    results["success_rate"] = (batchSize - float64(failedValidations)) / batchSize * 100
+    results := acc.summary()
 
     // Generate comprehensive validation report
     report, err := av.GenerateValidationReport(results)
@@ -170,9 +315,9 @@ func (av *AlertValidator) ValidateSecurityContext(alert *gold.Alert) (map[string
         scores["metadata"] = validateSecurityMetadata(alert.SecurityMetadata)
     }
 
-    // Validate compliance tags
-    if len(alert.ComplianceTags) > 0 {
-        scores["compliance"] = validateComplianceTags(alert.ComplianceTags)
+    // Validate compliance tags against the policy for the alert's classification
+    if len(alert.ComplianceTags) > 0 || len(av.requiredComplianceTags(alert)) > 0 {
+        scores["compliance"], _ = av.complianceCompleteness(alert)
     }
 
     // Validate audit trail
@@ -204,30 +349,51 @@ func (av *AlertValidator) GenerateValidationReport(results map[string]interface{
 
 // Helper functions
 
-func (av *AlertValidator) calculateStrictAccuracy(actual, expected *gold.Alert) float64 {
-    matches := 0
-    total := 6 // Number of critical fields to compare
+// strictFields lists the fields compared in strict mode, keyed as in AlertFieldWeights
+var strictFields = []string{"status", "severity", "intelligence", "security_context", "compliance", "audit_trail"}
+
+// calculateStrictAccuracy exact-matches each strict field. Matches count equally
+// unless strict weighting is enabled, in which case each counts by its field
+// weight (1 when unweighted). The returned map breaks down each field's match,
+// weight and contribution to the score.
+func (av *AlertValidator) calculateStrictAccuracy(actual, expected *gold.Alert) (float64, map[string]interface{}) {
+    matches := map[string]bool{
+        "status":           actual.Status == expected.Status,
+        "severity":         actual.Severity == expected.Severity,
+        "intelligence":     validateMap(actual.IntelligenceData, expected.IntelligenceData),
+        "security_context": validateMap(actual.SecurityMetadata, expected.SecurityMetadata),
+        "compliance":       validateStringSlice(actual.ComplianceTags, expected.ComplianceTags),
+        "audit_trail":      validateAuditTrail(actual.AuditTrail) >= av.securityThresholds["min_audit_score"],
+    }
+
+    var score, totalWeight float64
+    fields := make(map[string]interface{}, len(strictFields))
+    for _, field := range strictFields {
+        weight := 1.0
+        if av.strictWeighted {
+            if w, ok := av.fieldWeights[field]; ok {
+                weight = w
+            }
+        }
 
-    if actual.Status == expected.Status {
-        matches++
-    }
-    if actual.Severity == expected.Severity {
-        matches++
-    }
-    if validateMap(actual.IntelligenceData, expected.IntelligenceData) {
-        matches++
-    }
-    if validateMap(actual.SecurityMetadata, expected.SecurityMetadata) {
-        matches++
-    }
-    if validateStringSlice(actual.ComplianceTags, expected.ComplianceTags) {
-        matches++
-    }
-    if validateAuditTrail(actual.AuditTrail) >= av.securityThresholds["min_audit_score"] {
-        matches++
+        contribution := 0.0
+        if matches[field] {
+            contribution = weight
+        }
+        score += contribution
+        totalWeight += weight
+
+        fields[field] = map[string]interface{}{
+            "match":        matches[field],
+            "weight":       weight,
+            "contribution": contribution,
+        }
     }
 
-    return float64(matches) / float64(total) * 100
+    if totalWeight == 0 {
+        return 0, fields
+    }
+    return score / totalWeight * 100, fields
 }
 
 func (av *AlertValidator) calculateWeightedAccuracy(actual, expected *gold.Alert) float64 {
@@ -263,6 +429,91 @@ func (av *AlertValidator) calculateSecurityFocusedAccuracy(actual, expected *gol
     return (baseAccuracy*0.4 + securityScores["overall"]*0.6)
 }
 
+// calculateFuzzyAccuracy compares status, severity and every expected
+// intelligence field. String fields match when their normalized Levenshtein
+// similarity reaches the threshold; other fields fall back to equality. It
+// returns the percentage of matching fields and each field's similarity.
+func (av *AlertValidator) calculateFuzzyAccuracy(actual, expected *gold.Alert) (float64, map[string]float64) {
+    similarity := map[string]float64{
+        "status":   stringSimilarity(actual.Status, expected.Status),
+        "severity": stringSimilarity(actual.Severity, expected.Severity),
+    }
+    for field, expectedValue := range expected.IntelligenceData {
+        actualValue, ok := actual.IntelligenceData[field]
+        switch {
+        case !ok:
+            similarity["intelligence."+field] = 0
+        case isString(actualValue) && isString(expectedValue):
+            similarity["intelligence."+field] = stringSimilarity(actualValue.(string), expectedValue.(string))
+        case reflect.DeepEqual(actualValue, expectedValue):
+            similarity["intelligence."+field] = 1
+        default:
+            similarity["intelligence."+field] = 0
+        }
+    }
+
+    matches := 0
+    for _, score := range similarity {
+        if score >= av.similarityThreshold {
+            matches++
+        }
+    }
+    return float64(matches) / float64(len(similarity)) * 100, similarity
+}
+
+func isString(value interface{}) bool {
+    _, ok := value.(string)
+    return ok
+}
+
+// stringSimilarity returns 1 minus the Levenshtein distance over the longer
+// length, comparing case-insensitively with whitespace collapsed
+func stringSimilarity(a, b string) float64 {
+    ra := []rune(strings.ToLower(strings.Join(strings.Fields(a), " ")))
+    rb := []rune(strings.ToLower(strings.Join(strings.Fields(b), " ")))
+
+    longest := len(ra)
+    if len(rb) > longest {
+        longest = len(rb)
+    }
+    if longest == 0 {
+        return 1
+    }
+    return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein computes the edit distance between two rune slices
+func levenshtein(a, b []rune) int {
+    prev := make([]int, len(b)+1)
+    curr := make([]int, len(b)+1)
+    for j := range prev {
+        prev[j] = j
+    }
+
+    for i := 1; i <= len(a); i++ {
+        curr[0] = i
+        for j := 1; j <= len(b); j++ {
+            cost := 1
+            if a[i-1] == b[j-1] {
+                cost = 0
+            }
+            curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+        }
+        prev, curr = curr, prev
+    }
+    return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+    min := values[0]
+    for _, v := range values[1:] {
+        if v < min {
+            min = v
+        }
+    }
+    return min
+}
+
 func validateMap(actual, expected map[string]interface{}) bool {
     if len(actual) != len(expected) {
         return false
@@ -301,17 +552,116 @@ func validateSecurityMetadata(metadata map[string]interface{}) float64 {
     return float64(matches) / float64(len(requiredFields))
 }
 
-func validateComplianceTags(tags []string) float64 {
+// complianceCompleteness returns the fraction of required tags present on the
+// alert, scaled by the fraction of its tags the taxonomy recognizes, and the
+// sorted list of required tags missing. A control tag such as SOC2:CC6.1
+// satisfies its framework. Without a policy entry for the alert's
+// classification, only tag validity is scored.
+func (av *AlertValidator) complianceCompleteness(alert *gold.Alert) (float64, []string) {
+    validity, _ := av.validateComplianceTags(alert.ComplianceTags)
+    required := av.requiredComplianceTags(alert)
+    if len(required) == 0 {
+        return validity, nil
+    }
+
+    present := make(map[string]bool, len(alert.ComplianceTags))
+    for _, tag := range alert.ComplianceTags {
+        framework, _ := splitComplianceTag(tag)
+        present[framework] = true
+    }
+
+    missing := make([]string, 0)
+    for _, tag := range required {
+        if !present[normalizeFramework(tag)] {
+            missing = append(missing, tag)
+        }
+    }
+    sort.Strings(missing)
+
+    completeness := float64(len(required)-len(missing)) / float64(len(required))
+    return completeness * validity, missing
+}
+
+// requiredComplianceTags looks up the policy's required tags for the alert's classification
+func (av *AlertValidator) requiredComplianceTags(alert *gold.Alert) []string {
+    if av.compliancePolicy == nil {
+        return nil
+    }
+
+    classification := ""
+    if alert.SecurityMetadata != nil {
+        classification, _ = alert.SecurityMetadata["classification"].(string)
+    }
+    if tags, ok := av.compliancePolicy[strings.ToUpper(classification)]; ok {
+        return tags
+    }
+    return av.compliancePolicy[defaultPolicyKey]
+}
+
+// validateComplianceTags returns the fraction of tags that name a known
+// framework or one of its controls, and the unknown tags in input order
+func (av *AlertValidator) validateComplianceTags(tags []string) (float64, []string) {
     if len(tags) == 0 {
-        return 0
+        return 0, nil
+    }
+
+    unknown := make([]string, 0)
+    for _, tag := range tags {
+        if !av.isKnownComplianceTag(tag) {
+            unknown = append(unknown, tag)
+        }
     }
-    return 1.0
+    return float64(len(tags)-len(unknown)) / float64(len(tags)), unknown
 }
 
+// isKnownComplianceTag reports whether the taxonomy recognizes a tag
+func (av *AlertValidator) isKnownComplianceTag(tag string) bool {
+    name, control := splitComplianceTag(tag)
+    framework, ok := av.complianceTaxonomy[name]
+    if !ok {
+        return false
+    }
+    if control == "" {
+        return true
+    }
+    return framework.controls != nil && framework.controls.MatchString(control)
+}
+
+// splitComplianceTag splits FRAMEWORK:CONTROL into the normalized framework
+// name and the upper-cased control identifier
+func splitComplianceTag(tag string) (string, string) {
+    name, control, _ := strings.Cut(tag, ":")
+    return normalizeFramework(name), strings.ToUpper(strings.TrimSpace(control))
+}
+
+// complianceFrameworkAliases maps common short names onto taxonomy names
+var complianceFrameworkAliases = map[string]string{
+    "PCI":       "PCI-DSS",
+    "PCIDSS":    "PCI-DSS",
+    "ISO-27001": "ISO27001",
+}
+
+// normalizeFramework folds spelling variants such as "iso 27001" and
+// "pci_dss" onto the taxonomy's framework names
+func normalizeFramework(name string) string {
+    name = strings.ToUpper(strings.TrimSpace(name))
+    name = strings.ReplaceAll(name, " ", "")
+    name = strings.ReplaceAll(name, "_", "-")
+    if alias, ok := complianceFrameworkAliases[name]; ok {
+        return alias
+    }
+    return name
+}
+
+// validateAuditTrail scores the share of complete audit entries. A trail
+// whose hash chain does not verify has been tampered with and scores zero.
 func validateAuditTrail(trail []gold.AuditEntry) float64 {
     if len(trail) == 0 {
         return 0
     }
+    if err := gold.VerifyAuditChain(trail); err != nil {
+        return 0
+    }
     validEntries := 0
     for _, entry := range trail {
         if entry.Action != "" && !entry.Timestamp.IsZero() && entry.Actor != "" {
@@ -341,14 +691,3 @@ func calculateWeightedSecurityScore(scores map[string]float64) float64 {
     }
     return weightedScore / totalWeight
 }
-
-func calculateAverageScore(scores []float64) float64 {
-    if len(scores) == 0 {
-        return 0
-    }
-    var sum float64
-    for _, score := range scores {
-        sum += score
-    }
-    return sum / float64(len(scores))
-}
\ No newline at end of file
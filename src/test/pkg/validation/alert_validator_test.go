@@ -0,0 +1,498 @@
+package validation
+
+import (
+    "context"
+    "fmt"
+    "runtime"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "github.com/blackpoint/pkg/gold"
+)
+
+func buildStrictAlertPair() (*gold.Alert, *gold.Alert) {
+    newAlert := func(severity string) *gold.Alert {
+        return &gold.Alert{
+            AlertID:          "alert-001",
+            Status:           "new",
+            Severity:         severity,
+            IntelligenceData: map[string]interface{}{"rule": "brute_force"},
+            SecurityMetadata: map[string]interface{}{"classification": "INTERNAL"},
+            ComplianceTags:   []string{"SOC2"},
+            AuditTrail: []gold.AuditEntry{
+                {Action: "created", Actor: "analyzer", Timestamp: time.Unix(1700000000, 0)},
+            },
+        }
+    }
+    return newAlert("medium"), newAlert("high")
+}
+
+func TestStrictAccuracyFlatVersusWeighted(t *testing.T) {
+    validator, err := NewAlertValidator("strict", nil, nil)
+    require.NoError(t, err)
+    actual, expected := buildStrictAlertPair()
+
+    // Flat scoring counts the severity mismatch as one of six fields
+    flat, err := validator.ValidateAlert(actual, expected)
+    require.NoError(t, err)
+    assert.InDelta(t, 5.0/6.0*100, flat["accuracy"].(float64), 0.001)
+
+    // Weighted scoring counts severity at 1.0 of a total weight of 5.0
+    validator.SetStrictWeighting(true)
+    weighted, err := validator.ValidateAlert(actual, expected)
+    require.NoError(t, err)
+    assert.InDelta(t, 4.0/5.0*100, weighted["accuracy"].(float64), 0.001)
+    assert.Less(t, weighted["accuracy"].(float64), flat["accuracy"].(float64))
+
+    fields := weighted["field_results"].(map[string]interface{})
+    severity := fields["severity"].(map[string]interface{})
+    assert.Equal(t, false, severity["match"])
+    assert.Equal(t, 1.0, severity["weight"])
+    assert.Equal(t, 0.0, severity["contribution"])
+
+    status := fields["status"].(map[string]interface{})
+    assert.Equal(t, true, status["match"])
+    assert.Equal(t, 0.8, status["contribution"])
+}
+
+func TestStrictAccuracyWeightedLowImportanceMismatch(t *testing.T) {
+    validator, err := NewAlertValidator("strict", nil, nil)
+    require.NoError(t, err)
+    actual, expected := buildStrictAlertPair()
+    actual.Severity = expected.Severity
+    actual.Status = "acknowledged"
+
+    flat, _ := validator.calculateStrictAccuracy(actual, expected)
+
+    // A status mismatch costs less than a severity mismatch once weighted
+    validator.SetStrictWeighting(true)
+    weighted, _ := validator.calculateStrictAccuracy(actual, expected)
+
+    assert.InDelta(t, 5.0/6.0*100, flat, 0.001)
+    assert.InDelta(t, 4.2/5.0*100, weighted, 0.001)
+}
+
+func buildNearMissAlertPair() (*gold.Alert, *gold.Alert) {
+    actual, expected := buildStrictAlertPair()
+    actual.Severity = expected.Severity
+    expected.IntelligenceData = map[string]interface{}{
+        "description": "Multiple failed logins detected for user alice",
+        "notes":       "Source IP matched known brute force range",
+        "attempts":    12,
+    }
+    actual.IntelligenceData = map[string]interface{}{
+        "description": "Multiple failed logins detected for alice",
+        "notes":       "Source IP matches known brute-force range",
+        "attempts":    12,
+    }
+    return actual, expected
+}
+
+func TestFuzzyAccuracyToleratesNearMissWording(t *testing.T) {
+    actual, expected := buildNearMissAlertPair()
+
+    fuzzy, err := NewAlertValidator("fuzzy", nil, nil)
+    require.NoError(t, err)
+    fuzzyResults, err := fuzzy.ValidateAlert(actual, expected)
+    require.NoError(t, err)
+    assert.Equal(t, 100.0, fuzzyResults["accuracy"])
+
+    similarity := fuzzyResults["field_similarity"].(map[string]float64)
+    assert.Less(t, similarity["intelligence.description"], 1.0)
+    assert.GreaterOrEqual(t, similarity["intelligence.description"], defaultSimilarityThreshold)
+    assert.Equal(t, 1.0, similarity["intelligence.attempts"])
+
+    // Strict mode rejects the same wording differences
+    strict, err := NewAlertValidator("strict", nil, nil)
+    require.NoError(t, err)
+    strictResults, err := strict.ValidateAlert(actual, expected)
+    require.NoError(t, err)
+    assert.Less(t, strictResults["accuracy"].(float64), 100.0)
+    fields := strictResults["field_results"].(map[string]interface{})
+    assert.Equal(t, false, fields["intelligence"].(map[string]interface{})["match"])
+}
+
+func TestFuzzyAccuracyThresholdAndNonStringFields(t *testing.T) {
+    actual, expected := buildNearMissAlertPair()
+    actual.IntelligenceData["attempts"] = 11
+
+    validator, err := NewAlertValidator("fuzzy", nil, nil)
+    require.NoError(t, err)
+    require.Error(t, validator.SetSimilarityThreshold(0))
+    require.NoError(t, validator.SetSimilarityThreshold(0.99))
+
+    accuracy, similarity := validator.calculateFuzzyAccuracy(actual, expected)
+    // Only status and severity clear the raised threshold; numbers need equality
+    assert.Equal(t, 0.0, similarity["intelligence.attempts"])
+    assert.InDelta(t, 2.0/5.0*100, accuracy, 0.001)
+}
+
+// syntheticAlertPair builds the i-th pair of a synthetic accuracy run. Every
+// third pair disagrees on severity and every hundredth has mismatched IDs, so
+// the run mixes matches, partial matches and failed validations.
+func syntheticAlertPair(i int) (*gold.Alert, *gold.Alert) {
+    actual, expected := buildStrictAlertPair()
+    actual.AlertID = fmt.Sprintf("alert-%06d", i)
+    expected.AlertID = actual.AlertID
+    if i%3 != 0 {
+        actual.Severity = expected.Severity
+    }
+    if i%100 == 99 {
+        expected.AlertID = "unmatched"
+    }
+    return actual, expected
+}
+
+// streamAlertPairs feeds n synthetic pairs to the returned channels
+func streamAlertPairs(n int) (<-chan *gold.Alert, <-chan *gold.Alert) {
+    actual := make(chan *gold.Alert)
+    expected := make(chan *gold.Alert)
+    go func() {
+        defer close(actual)
+        defer close(expected)
+        for i := 0; i < n; i++ {
+            a, e := syntheticAlertPair(i)
+            actual <- a
+            expected <- e
+        }
+    }()
+    return actual, expected
+}
+
+// heapInUse returns the live heap after a collection
+func heapInUse() uint64 {
+    runtime.GC()
+    var stats runtime.MemStats
+    runtime.ReadMemStats(&stats)
+    return stats.HeapAlloc
+}
+
+func TestValidateStreamBoundedMemory(t *testing.T) {
+    const pairs = 100000
+
+    validator, err := NewAlertValidator("strict", nil, nil)
+    require.NoError(t, err)
+    actual, expected := streamAlertPairs(pairs)
+    results, err := validator.ValidateStream(context.Background(), actual, expected)
+    require.NoError(t, err)
+
+    var baseline, peak uint64
+    var final AlertResult
+    count := 0
+    for result := range results {
+        if result.Final {
+            final = result
+            continue
+        }
+        count++
+        switch {
+        case count == pairs/10:
+            baseline = heapInUse()
+        case count%(pairs/10) == 0:
+            if heap := heapInUse(); heap > peak {
+                peak = heap
+            }
+        }
+    }
+
+    require.Equal(t, pairs, count)
+    require.True(t, final.Final, "expected a final summary before the channel closed")
+    require.NoError(t, final.Err)
+    assert.Equal(t, pairs, final.Aggregate.Validated)
+    assert.Equal(t, pairs/100, final.Aggregate.Failed)
+    assert.InDelta(t, 99.0, final.Aggregate.SuccessRate, 0.001)
+
+    // Memory after 100k pairs stays within a few MB of that after 10k
+    assert.Less(t, int64(peak)-int64(baseline), int64(4<<20), "heap grew from %d to %d bytes", baseline, peak)
+}
+
+func TestValidateStreamMatchesBatch(t *testing.T) {
+    const pairs = 300
+
+    actualAlerts := make([]*gold.Alert, pairs)
+    expectedAlerts := make([]*gold.Alert, pairs)
+    for i := range actualAlerts {
+        actualAlerts[i], expectedAlerts[i] = syntheticAlertPair(i)
+    }
+
+    validator, err := NewAlertValidator("strict", nil, nil)
+    require.NoError(t, err)
+    batch, err := validator.ValidateAlertBatch(actualAlerts, expectedAlerts)
+    require.NoError(t, err)
+
+    actual, expected := streamAlertPairs(pairs)
+    results, err := validator.ValidateStream(context.Background(), actual, expected)
+    require.NoError(t, err)
+
+    var final AlertResult
+    var last StreamAggregate
+    for result := range results {
+        if result.Final {
+            final = result
+            continue
+        }
+        assert.Equal(t, last.Validated+1, result.Aggregate.Validated, "aggregate must advance with every pair")
+        last = result.Aggregate
+    }
+
+    require.True(t, final.Final)
+    for _, key := range []string{"average_accuracy", "security_score", "success_rate"} {
+        assert.InDelta(t, batch[key].(float64), final.Summary[key].(float64), 1e-9, key)
+    }
+    assert.Equal(t, batch["failed_validations"], final.Summary["failed_validations"])
+    assert.Contains(t, final.Summary, "validation_report")
+}
+
+func TestValidateStreamSizeMismatch(t *testing.T) {
+    validator, err := NewAlertValidator("strict", nil, nil)
+    require.NoError(t, err)
+
+    actual := make(chan *gold.Alert, 3)
+    expected := make(chan *gold.Alert, 1)
+    for i := 0; i < 3; i++ {
+        a, e := syntheticAlertPair(i)
+        actual <- a
+        if i == 0 {
+            expected <- e
+        }
+    }
+    close(actual)
+    close(expected)
+
+    results, err := validator.ValidateStream(context.Background(), actual, expected)
+    require.NoError(t, err)
+
+    var final AlertResult
+    for result := range results {
+        final = result
+    }
+    require.True(t, final.Final)
+    require.Error(t, final.Err)
+    assert.Contains(t, final.Err.Error(), "2 unmatched alerts after 1 pairs")
+    assert.Equal(t, 1, final.Aggregate.Validated)
+}
+
+func TestValidateComplianceTags(t *testing.T) {
+    validator, err := NewAlertValidator("strict", nil, nil)
+    require.NoError(t, err)
+
+    tests := []struct {
+        name    string
+        tags    []string
+        score   float64
+        unknown []string
+    }{
+        {"frameworks and controls", []string{"SOC2", "soc2:CC6.1", "ISO 27001:A.9.2.3", "PCI:10.2.1", "HIPAA:164.312(b)", "GDPR:Art. 33"}, 1, []string{}},
+        {"partially valid", []string{"SOC2:CC6.1", "SOC3", "PCI-DSS:ten", "GDPR"}, 0.5, []string{"SOC3", "PCI-DSS:ten"}},
+        {"all invalid", []string{"NIST", "SOC2:XX1", "ISO27001:9.2"}, 0, []string{"NIST", "SOC2:XX1", "ISO27001:9.2"}},
+        {"no tags", nil, 0, nil},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            score, unknown := validator.ValidateComplianceTags(tt.tags)
+            assert.InDelta(t, tt.score, score, 1e-9)
+            assert.Equal(t, tt.unknown, unknown)
+        })
+    }
+}
+
+func TestComplianceScoreRequiresKnownTags(t *testing.T) {
+    validator, err := NewAlertValidator("strict", nil, nil)
+    require.NoError(t, err)
+
+    alert := &gold.Alert{
+        AlertID:          "alert-001",
+        SecurityMetadata: map[string]interface{}{"classification": "CONFIDENTIAL"},
+        ComplianceTags:   []string{"SOC2:CC7.2", "ISO27001:A.12.4.1", "GDPR"},
+    }
+    score, missing := validator.ValidateCompliance(alert)
+    assert.Equal(t, 1.0, score)
+    assert.Empty(t, missing)
+
+    alert.ComplianceTags = append(alert.ComplianceTags, "FEDRAMP")
+    score, missing = validator.ValidateCompliance(alert)
+    assert.InDelta(t, 0.75, score, 1e-9, "unknown tags must lower the score")
+    assert.Empty(t, missing)
+
+    // A taxonomy without FedRAMP controls still accepts the framework tag
+    taxonomy := ComplianceTaxonomy{"FEDRAMP": ""}
+    for name, pattern := range DefaultComplianceTaxonomy {
+        taxonomy[name] = pattern
+    }
+    require.NoError(t, validator.SetComplianceTaxonomy(taxonomy))
+    score, _ = validator.ValidateCompliance(alert)
+    assert.Equal(t, 1.0, score)
+
+    assert.Error(t, validator.SetComplianceTaxonomy(ComplianceTaxonomy{"SOC2": "("}))
+}
+
+func TestComplianceCompletenessIsProportional(t *testing.T) {
+    validator, err := NewAlertValidator("strict", nil, nil)
+    require.NoError(t, err)
+
+    // RESTRICTED alerts require SOC2, ISO27001, GDPR and PCI-DSS
+    tests := []struct {
+        name    string
+        tags    []string
+        score   float64
+        missing []string
+    }{
+        {"all required", []string{"SOC2", "ISO27001", "GDPR", "PCI-DSS"}, 1, []string{}},
+        {"three of four", []string{"GDPR", "SOC2:CC6.1", "ISO27001"}, 0.75, []string{"PCI-DSS"}},
+        {"half", []string{"PCI-DSS", "GDPR"}, 0.5, []string{"ISO27001", "SOC2"}},
+        {"one of four", []string{"PCI-DSS"}, 0.25, []string{"GDPR", "ISO27001", "SOC2"}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            score, missing := validator.ValidateCompliance(&gold.Alert{
+                AlertID:          "alert-001",
+                SecurityMetadata: map[string]interface{}{"classification": "restricted"},
+                ComplianceTags:   tt.tags,
+            })
+            assert.InDelta(t, tt.score, score, 1e-9)
+            assert.Equal(t, tt.missing, missing, "missing tags must be sorted")
+        })
+    }
+}
+
+func TestCompliancePolicyDefaultFallback(t *testing.T) {
+    validator, err := NewAlertValidator("strict", nil, nil)
+    require.NoError(t, err)
+    require.NoError(t, validator.SetCompliancePolicy(CompliancePolicy{
+        "restricted": {"SOC2", "GDPR"},
+        "default":    {"SOC2"},
+    }))
+
+    // Classification keys are matched case-insensitively
+    score, missing := validator.ValidateCompliance(&gold.Alert{
+        SecurityMetadata: map[string]interface{}{"classification": "RESTRICTED"},
+        ComplianceTags:   []string{"SOC2"},
+    })
+    assert.InDelta(t, 0.5, score, 1e-9)
+    assert.Equal(t, []string{"GDPR"}, missing)
+
+    // Classifications without an entry, or alerts without one, use DEFAULT
+    for _, metadata := range []map[string]interface{}{{"classification": "PUBLIC"}, nil} {
+        score, missing = validator.ValidateCompliance(&gold.Alert{
+            SecurityMetadata: metadata,
+            ComplianceTags:   []string{"GDPR"},
+        })
+        assert.Equal(t, 0.0, score)
+        assert.Equal(t, []string{"SOC2"}, missing)
+    }
+
+    // Without a DEFAULT entry only tag validity is scored
+    require.NoError(t, validator.SetCompliancePolicy(CompliancePolicy{"RESTRICTED": {"SOC2"}}))
+    score, missing = validator.ValidateCompliance(&gold.Alert{
+        SecurityMetadata: map[string]interface{}{"classification": "PUBLIC"},
+        ComplianceTags:   []string{"GDPR", "NIST"},
+    })
+    assert.InDelta(t, 0.5, score, 1e-9)
+    assert.Empty(t, missing)
+
+    assert.Error(t, validator.SetCompliancePolicy(CompliancePolicy{"DEFAULT": {}}))
+}
+
+func TestComplianceModeThreshold(t *testing.T) {
+    validator, err := NewAlertValidator("compliance", nil, nil)
+    require.NoError(t, err)
+
+    // Ten required frameworks put the 0.9 threshold at nine tags
+    frameworks := make([]string, 10)
+    taxonomy := ComplianceTaxonomy{}
+    for i := range frameworks {
+        frameworks[i] = fmt.Sprintf("F%d", i)
+        taxonomy[frameworks[i]] = ""
+    }
+    require.NoError(t, validator.SetComplianceTaxonomy(taxonomy))
+    require.NoError(t, validator.SetCompliancePolicy(CompliancePolicy{"DEFAULT": frameworks}))
+
+    tests := []struct {
+        name   string
+        tags   int
+        passed bool
+    }{
+        {"all tags", 10, true},
+        {"at threshold", 9, true},
+        {"below threshold", 8, false},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            alert := &gold.Alert{
+                AlertID:          "alert-001",
+                SecurityMetadata: map[string]interface{}{"classification": "INTERNAL"},
+                ComplianceTags:   frameworks[:tt.tags],
+            }
+            results, err := validator.ValidateAlert(alert, alert)
+            require.NoError(t, err)
+            assert.InDelta(t, float64(tt.tags)*10, results["accuracy"], 1e-9)
+            assert.Equal(t, tt.passed, results["passed"])
+            assert.Len(t, results["missing_compliance_tags"], 10-tt.tags)
+        })
+    }
+}
+
+// chainedAuditTrail returns the hash-chained trail of an alert that absorbed
+// two duplicates
+func chainedAuditTrail(t *testing.T) []gold.AuditEntry {
+    dedup, err := gold.NewAlertDeduplicator(gold.DedupConfig{})
+    require.NoError(t, err)
+
+    base := time.Unix(1700000000, 0).UTC()
+    var alert *gold.Alert
+    for i := 0; i < 3; i++ {
+        alert, _ = dedup.Deduplicate(&gold.Alert{
+            AlertID:          fmt.Sprintf("alert-%03d", i),
+            Severity:         "high",
+            IntelligenceData: map[string]interface{}{"rule_id": "brute_force", "entity": "alice"},
+        }, base.Add(time.Duration(i)*time.Second))
+    }
+    trail := alert.AuditTrail
+    for i := range trail {
+        trail[i].Actor = "analyzer"
+    }
+    return trail
+}
+
+func TestValidateAuditTrailDetectsTampering(t *testing.T) {
+    validator, err := NewAlertValidator("strict", nil, nil)
+    require.NoError(t, err)
+
+    trail := chainedAuditTrail(t)
+    require.Len(t, trail, 2)
+    require.NoError(t, gold.VerifyAuditChain(trail))
+    assert.Equal(t, 1.0, validateAuditTrail(trail))
+
+    tampered := map[string]func([]gold.AuditEntry) []gold.AuditEntry{
+        "modified": func(trail []gold.AuditEntry) []gold.AuditEntry {
+            trail[0].Details = map[string]interface{}{"duplicate_alert_id": "alert-999"}
+            return trail
+        },
+        "reordered": func(trail []gold.AuditEntry) []gold.AuditEntry {
+            trail[0], trail[1] = trail[1], trail[0]
+            return trail
+        },
+        "first removed": func(trail []gold.AuditEntry) []gold.AuditEntry {
+            return trail[1:]
+        },
+    }
+    for name, tamper := range tampered {
+        trail := tamper(append([]gold.AuditEntry(nil), chainedAuditTrail(t)...))
+        assert.Equal(t, 0.0, validateAuditTrail(trail), name)
+
+        scores, err := validator.ValidateSecurityContext(&gold.Alert{AlertID: "alert-000", AuditTrail: trail})
+        require.NoError(t, err)
+        assert.Equal(t, 0.0, scores["audit"], name)
+    }
+
+    // Trails recorded before hash chaining are still scored on completeness
+    assert.Equal(t, 1.0, validateAuditTrail([]gold.AuditEntry{
+        {Action: "created", Actor: "analyzer", Timestamp: time.Unix(1700000000, 0)},
+    }))
+}
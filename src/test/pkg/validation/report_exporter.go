@@ -0,0 +1,252 @@
+// Package validation provides scheduled export of alert validation reports
+// Version: 1.0.0
+package validation
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "path"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus" // v1.12.0
+
+    "github.com/blackpoint/pkg/common/logging"
+    "github.com/blackpoint/pkg/gold"
+    "github.com/blackpoint/backend/internal/storage"
+)
+
+// Default report export configuration
+const (
+    defaultExportInterval   = 30 * 24 * time.Hour
+    defaultExportSampleSize = 1000
+    defaultReportRetention  = 365 * 24 * time.Hour
+    defaultReportPrefix     = "validation-reports"
+    reportKeyTimeFormat     = "20060102T150405Z"
+    reportKeyPrefix         = "validation-report-"
+)
+
+// reportExportErrors counts scheduled exports that failed
+var reportExportErrors = prometheus.NewCounter(
+    prometheus.CounterOpts{
+        Name: "blackpoint_validation_report_export_errors_total",
+        Help: "Total number of scheduled validation report exports that failed",
+    },
+)
+
+func init() {
+    prometheus.MustRegister(reportExportErrors)
+}
+
+// ReportExporterConfig configures scheduled validation report export
+type ReportExporterConfig struct {
+    Bucket     string
+    Prefix     string
+    Interval   time.Duration
+    SampleSize int
+    Retention  time.Duration
+}
+
+// ReportStore defines the storage operations required to archive reports
+type ReportStore interface {
+    PutObject(bucket, key string, data []byte) error
+    ListObjects(bucket, prefix string) ([]string, error)
+    DeleteObject(bucket, key string) error
+}
+
+// Ensure the backend S3 client can be used as a report store
+var _ ReportStore = (*storage.S3Client)(nil)
+
+// AlertSampler provides sampled production alerts paired with their golden expectations
+type AlertSampler interface {
+    // SampleAlerts returns equally sized slices of actual and expected alerts
+    SampleAlerts(ctx context.Context, size int) ([]*gold.Alert, []*gold.Alert, error)
+}
+
+// ReportExporter periodically validates sampled alerts and archives the report
+type ReportExporter struct {
+    validator *AlertValidator
+    sampler   AlertSampler
+    store     ReportStore
+    config    ReportExporterConfig
+    cancel    context.CancelFunc
+    wg        sync.WaitGroup
+    mu        sync.Mutex
+}
+
+// NewReportExporter creates a new ReportExporter with validated configuration
+func NewReportExporter(validator *AlertValidator, sampler AlertSampler, store ReportStore, config ReportExporterConfig) (*ReportExporter, error) {
+    if validator == nil || sampler == nil || store == nil {
+        return nil, fmt.Errorf("validator, sampler and store are required")
+    }
+    if config.Bucket == "" {
+        return nil, fmt.Errorf("report bucket is required")
+    }
+
+    // Apply defaults for unspecified settings
+    if config.Prefix == "" {
+        config.Prefix = defaultReportPrefix
+    }
+    if config.Interval <= 0 {
+        config.Interval = defaultExportInterval
+    }
+    if config.SampleSize <= 0 {
+        config.SampleSize = defaultExportSampleSize
+    }
+    if config.Retention <= 0 {
+        config.Retention = defaultReportRetention
+    }
+    config.Prefix = strings.Trim(config.Prefix, "/")
+
+    return &ReportExporter{
+        validator: validator,
+        sampler:   sampler,
+        store:     store,
+        config:    config,
+    }, nil
+}
+
+// Start begins the scheduled export loop. The first report is exported
+// immediately, then once per configured interval.
+func (re *ReportExporter) Start(ctx context.Context) error {
+    re.mu.Lock()
+    defer re.mu.Unlock()
+
+    if re.cancel != nil {
+        return fmt.Errorf("report exporter already started")
+    }
+
+    ctx, cancel := context.WithCancel(ctx)
+    re.cancel = cancel
+
+    re.wg.Add(1)
+    go re.run(ctx)
+
+    return nil
+}
+
+// Stop stops the scheduled export loop and waits for an in-progress export
+func (re *ReportExporter) Stop() {
+    re.mu.Lock()
+    cancel := re.cancel
+    re.cancel = nil
+    re.mu.Unlock()
+
+    if cancel != nil {
+        cancel()
+    }
+    re.wg.Wait()
+}
+
+// ExportOnce validates a fresh alert sample, writes the report and prunes expired reports
+func (re *ReportExporter) ExportOnce(ctx context.Context) (string, error) {
+    actual, expected, err := re.sampler.SampleAlerts(ctx, re.config.SampleSize)
+    if err != nil {
+        return "", fmt.Errorf("failed to sample alerts: %v", err)
+    }
+    if len(actual) == 0 {
+        return "", fmt.Errorf("no alerts sampled for validation")
+    }
+
+    results, err := re.validator.ValidateAlertBatch(actual, expected)
+    if err != nil {
+        return "", fmt.Errorf("batch validation failed: %v", err)
+    }
+
+    now := time.Now().UTC()
+    results["sample_size"] = len(actual)
+    results["exported_at"] = now
+
+    data, err := json.Marshal(results)
+    if err != nil {
+        return "", fmt.Errorf("failed to marshal validation report: %v", err)
+    }
+
+    key := re.reportKey(now)
+    if err := re.store.PutObject(re.config.Bucket, key, data); err != nil {
+        return "", fmt.Errorf("failed to store validation report: %v", err)
+    }
+
+    if err := re.pruneExpired(now); err != nil {
+        return key, err
+    }
+
+    return key, nil
+}
+
+// run exports on start and then on the configured cadence until cancelled
+func (re *ReportExporter) run(ctx context.Context) {
+    defer re.wg.Done()
+
+    ticker := time.NewTicker(re.config.Interval)
+    defer ticker.Stop()
+
+    re.exportScheduled(ctx)
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            re.exportScheduled(ctx)
+        }
+    }
+}
+
+// exportScheduled runs one scheduled export. Failures are logged and counted
+// and retried on the next tick; a failed export must not stop the schedule.
+func (re *ReportExporter) exportScheduled(ctx context.Context) {
+    key, err := re.ExportOnce(ctx)
+    if err != nil {
+        if ctx.Err() != nil {
+            return
+        }
+        reportExportErrors.Inc()
+        logging.Error("Scheduled validation report export failed", err,
+            logging.Field("bucket", re.config.Bucket),
+            logging.Field("report_key", key),
+        )
+    }
+}
+
+// reportKey builds the object key for a report generated at the given time
+func (re *ReportExporter) reportKey(at time.Time) string {
+    return path.Join(re.config.Prefix, reportKeyPrefix+at.Format(reportKeyTimeFormat)+".json")
+}
+
+// pruneExpired removes reports older than the configured retention
+func (re *ReportExporter) pruneExpired(now time.Time) error {
+    keys, err := re.store.ListObjects(re.config.Bucket, re.config.Prefix+"/")
+    if err != nil {
+        return fmt.Errorf("failed to list validation reports: %v", err)
+    }
+
+    cutoff := now.Add(-re.config.Retention)
+    for _, key := range keys {
+        generatedAt, ok := parseReportKeyTime(key)
+        if !ok || !generatedAt.Before(cutoff) {
+            continue
+        }
+        if err := re.store.DeleteObject(re.config.Bucket, key); err != nil {
+            return fmt.Errorf("failed to delete expired report %s: %v", key, err)
+        }
+    }
+
+    return nil
+}
+
+// parseReportKeyTime extracts the generation time encoded in a report key
+func parseReportKeyTime(key string) (time.Time, bool) {
+    name := path.Base(key)
+    if !strings.HasPrefix(name, reportKeyPrefix) || !strings.HasSuffix(name, ".json") {
+        return time.Time{}, false
+    }
+
+    stamp := strings.TrimSuffix(strings.TrimPrefix(name, reportKeyPrefix), ".json")
+    t, err := time.Parse(reportKeyTimeFormat, stamp)
+    if err != nil {
+        return time.Time{}, false
+    }
+    return t, true
+}
@@ -0,0 +1,162 @@
+package validation
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus/testutil"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "github.com/blackpoint/pkg/gold"
+)
+
+// memoryReportStore keeps archived reports in memory and counts writes
+type memoryReportStore struct {
+    mu      sync.Mutex
+    objects map[string][]byte
+    puts    int
+}
+
+func newMemoryReportStore() *memoryReportStore {
+    return &memoryReportStore{objects: make(map[string][]byte)}
+}
+
+func (s *memoryReportStore) PutObject(bucket, key string, data []byte) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.objects[key] = data
+    s.puts++
+    return nil
+}
+
+func (s *memoryReportStore) ListObjects(bucket, prefix string) ([]string, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    keys := make([]string, 0, len(s.objects))
+    for key := range s.objects {
+        if strings.HasPrefix(key, prefix) {
+            keys = append(keys, key)
+        }
+    }
+    return keys, nil
+}
+
+func (s *memoryReportStore) DeleteObject(bucket, key string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    delete(s.objects, key)
+    return nil
+}
+
+func (s *memoryReportStore) putCount() int {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.puts
+}
+
+// staticAlertSampler returns the same matching alert pair on every call, or err when set
+type staticAlertSampler struct {
+    err error
+}
+
+func (s *staticAlertSampler) SampleAlerts(ctx context.Context, size int) ([]*gold.Alert, []*gold.Alert, error) {
+    if s.err != nil {
+        return nil, nil, s.err
+    }
+    actual, _ := buildStrictAlertPair()
+    expected, _ := buildStrictAlertPair()
+    return []*gold.Alert{actual}, []*gold.Alert{expected}, nil
+}
+
+func newTestReportExporter(t *testing.T, sampler AlertSampler, store ReportStore, interval time.Duration) *ReportExporter {
+    validator, err := NewAlertValidator("strict", nil, nil)
+    require.NoError(t, err)
+    exporter, err := NewReportExporter(validator, sampler, store, ReportExporterConfig{
+        Bucket:   "reports-bucket",
+        Prefix:   "/reports/",
+        Interval: interval,
+    })
+    require.NoError(t, err)
+    return exporter
+}
+
+func TestReportExporterExportsOnStart(t *testing.T) {
+    store := newMemoryReportStore()
+    exporter := newTestReportExporter(t, &staticAlertSampler{}, store, time.Hour)
+
+    require.NoError(t, exporter.Start(context.Background()))
+    defer exporter.Stop()
+
+    // The first report must not wait out a full interval
+    assert.Eventually(t, func() bool { return store.putCount() == 1 }, 2*time.Second, 10*time.Millisecond)
+    assert.Error(t, exporter.Start(context.Background()), "a second Start should be rejected")
+}
+
+func TestReportExporterSchedule(t *testing.T) {
+    store := newMemoryReportStore()
+    exporter := newTestReportExporter(t, &staticAlertSampler{}, store, 20*time.Millisecond)
+
+    require.NoError(t, exporter.Start(context.Background()))
+    assert.Eventually(t, func() bool { return store.putCount() >= 3 }, 2*time.Second, 10*time.Millisecond)
+
+    // No exports run once stopped
+    exporter.Stop()
+    stopped := store.putCount()
+    time.Sleep(60 * time.Millisecond)
+    assert.Equal(t, stopped, store.putCount())
+}
+
+func TestReportExporterOutput(t *testing.T) {
+    store := newMemoryReportStore()
+    now := time.Now().UTC()
+    expiredKey := "reports/" + reportKeyPrefix + now.Add(-2*defaultReportRetention).Format(reportKeyTimeFormat) + ".json"
+    recentKey := "reports/" + reportKeyPrefix + now.Add(-24*time.Hour).Format(reportKeyTimeFormat) + ".json"
+    unrelatedKey := "reports/notes.json"
+    for _, key := range []string{expiredKey, recentKey, unrelatedKey} {
+        require.NoError(t, store.PutObject("reports-bucket", key, []byte("{}")))
+    }
+
+    exporter := newTestReportExporter(t, &staticAlertSampler{}, store, time.Hour)
+    key, err := exporter.ExportOnce(context.Background())
+    require.NoError(t, err)
+
+    assert.True(t, strings.HasPrefix(key, "reports/"+reportKeyPrefix), "unexpected report key %s", key)
+    generatedAt, ok := parseReportKeyTime(key)
+    require.True(t, ok)
+    assert.WithinDuration(t, now, generatedAt, 5*time.Second)
+
+    var report map[string]interface{}
+    require.NoError(t, json.Unmarshal(store.objects[key], &report))
+    assert.Equal(t, float64(1), report["sample_size"])
+    assert.Contains(t, report, "exported_at")
+    assert.Contains(t, report, "validation_report")
+
+    // Reports past retention are pruned; recent reports and other objects stay
+    assert.NotContains(t, store.objects, expiredKey)
+    assert.Contains(t, store.objects, recentKey)
+    assert.Contains(t, store.objects, unrelatedKey)
+}
+
+func TestReportExporterErrorPath(t *testing.T) {
+    store := newMemoryReportStore()
+    exporter := newTestReportExporter(t, &staticAlertSampler{err: fmt.Errorf("sampling unavailable")}, store, 20*time.Millisecond)
+
+    _, err := exporter.ExportOnce(context.Background())
+    require.Error(t, err)
+
+    // Failed scheduled exports are counted and the schedule keeps running
+    before := testutil.ToFloat64(reportExportErrors)
+    require.NoError(t, exporter.Start(context.Background()))
+    defer exporter.Stop()
+
+    assert.Eventually(t, func() bool {
+        return testutil.ToFloat64(reportExportErrors)-before >= 2
+    }, 2*time.Second, 10*time.Millisecond)
+    assert.Zero(t, store.putCount())
+}